@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/migrate"
+	"github.com/pesio-ai/be-ap-vendors/internal/utctime"
+	"github.com/pesio-ai/be-ap-vendors/migrations"
+	"github.com/pesio-ai/be-lib-common/config"
+	"github.com/pesio-ai/be-lib-common/database"
+)
+
+const usage = `Usage: migrate [-dry-run] <command>
+
+Commands:
+  status          Show the schema version and pending migrations
+  up              Apply all pending migrations
+  down [N]        Revert the last N migrations (default 1)
+  force VERSION   Set the schema version without running migrations
+
+Flags:
+  -dry-run  print the migrations up or down would run without running them
+`
+
+// The migrate command applies the embedded migrations to the database
+// configured like the service
+func main() {
+	utctime.UseUTC()
+
+	dryRun := flag.Bool("dry-run", false, "print the migrations that would run without running them")
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	all, err := migrate.Load(migrations.FS)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load migrations: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	db, err := database.New(ctx, database.Config{
+		Host:        cfg.Database.Host,
+		Port:        cfg.Database.Port,
+		User:        cfg.Database.User,
+		Password:    cfg.Database.Password,
+		Database:    cfg.Database.Database,
+		SSLMode:     cfg.Database.SSLMode,
+		MaxConns:    cfg.Database.MaxConns,
+		MinConns:    cfg.Database.MinConns,
+		MaxConnTime: cfg.Database.MaxConnTime,
+		MaxIdleTime: cfg.Database.MaxIdleTime,
+		HealthCheck: cfg.Database.HealthCheck,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	runner := migrate.NewRunner(db.Pool, all)
+	if err := run(ctx, runner, args, *dryRun); err != nil {
+		fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+		db.Close()
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, runner *migrate.Runner, args []string, dryRun bool) error {
+	switch args[0] {
+	case "status":
+		version, dirty, err := runner.Version(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("schema version: %d (binary: %d)", version, runner.Latest())
+		if dirty {
+			fmt.Print(", dirty")
+		}
+		fmt.Println()
+
+		pending, err := runner.Check(ctx)
+		if err != nil {
+			return err
+		}
+		printMigrations("pending", pending)
+		return nil
+
+	case "up":
+		applied, err := runner.Up(ctx, dryRun)
+		verb := "applied"
+		if dryRun {
+			verb = "would apply"
+		}
+		printMigrations(verb, applied)
+		return err
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("down expects a positive number of migrations, got %q", args[1])
+			}
+			steps = n
+		}
+		reverted, err := runner.Down(ctx, steps, dryRun)
+		verb := "reverted"
+		if dryRun {
+			verb = "would revert"
+		}
+		printMigrations(verb, reverted)
+		return err
+
+	case "force":
+		if len(args) != 2 {
+			return fmt.Errorf("force expects a version")
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < 0 || version > runner.Latest() {
+			return fmt.Errorf("force expects a version between 0 and %d, got %q", runner.Latest(), args[1])
+		}
+		if dryRun {
+			fmt.Printf("would set schema version to %d\n", version)
+			return nil
+		}
+		if err := runner.Force(ctx, version); err != nil {
+			return err
+		}
+		fmt.Printf("schema version set to %d\n", version)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown command %q\n\n%s", args[0], usage)
+	}
+}
+
+func printMigrations(verb string, list []migrate.Migration) {
+	if len(list) == 0 {
+		fmt.Printf("%s: none\n", verb)
+		return
+	}
+	fmt.Printf("%s:\n", verb)
+	for _, m := range list {
+		fmt.Printf("  %s\n", m)
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+)
+
+var (
+	nameFirstParts = []string{
+		"Acme", "Blue Ridge", "Summit", "Northwind", "Pioneer", "Harbor", "Granite", "Silver Oak",
+		"Evergreen", "Redwood", "Cascade", "Lakeside", "Ironclad", "Bright", "Keystone", "Meridian",
+		"Prairie", "Atlas", "Beacon", "Coastal", "Frontier", "Golden Gate", "Highland", "Liberty",
+	}
+	nameSecondParts = []string{
+		"Supply", "Logistics", "Industrial", "Consulting", "Electric", "Paper", "Office Products",
+		"Freight", "Software", "Facilities", "Packaging", "Metals", "Staffing", "Print", "Utilities",
+		"Engineering", "Janitorial", "Security", "Marketing", "Catering",
+	}
+	nameSuffixes = []string{"Inc.", "LLC", "Co.", "Corp.", "Group", "Partners", "Ltd."}
+
+	streetNames = []string{
+		"Main St", "Oak Ave", "Maple Dr", "Market St", "Industrial Pkwy", "Commerce Blvd", "Elm St",
+		"Harbor Way", "Lake Rd", "Park Ave", "River Rd", "Cedar Ln", "Mill St", "Airport Rd",
+	}
+	cities = []struct{ city, state, zipPrefix string }{
+		{"Austin", "TX", "787"}, {"Denver", "CO", "802"}, {"Portland", "OR", "972"}, {"Columbus", "OH", "432"},
+		{"Raleigh", "NC", "276"}, {"Madison", "WI", "537"}, {"Phoenix", "AZ", "850"}, {"Boston", "MA", "021"},
+		{"Nashville", "TN", "372"}, {"Sacramento", "CA", "958"}, {"Tampa", "FL", "336"}, {"Minneapolis", "MN", "554"},
+	}
+
+	firstNames = []string{
+		"Alex", "Jordan", "Taylor", "Morgan", "Casey", "Riley", "Jamie", "Avery", "Quinn", "Sam",
+		"Dana", "Robin", "Chris", "Pat", "Drew", "Jesse", "Kai", "Reese", "Skyler", "Rowan",
+	}
+	lastNames = []string{
+		"Smith", "Johnson", "Garcia", "Miller", "Davis", "Martinez", "Lopez", "Wilson", "Anderson",
+		"Thomas", "Moore", "Jackson", "Lee", "Harris", "Clark", "Lewis", "Walker", "Young", "Nguyen", "Patel",
+	}
+	contactTitles = []string{"Account Manager", "Sales Director", "Billing Specialist", "Owner", "Operations Lead", "AR Clerk"}
+
+	vendorTypes = []string{"supplier", "contractor", "service_provider", "consultant", "utility"}
+	// Most seeded vendors are active so that they are usable for invoices
+	vendorStatuses = []string{"active", "active", "active", "active", "active", "inactive", "suspended", "pending_approval"}
+	paymentMethods = []string{"check", "ach", "ach", "wire", "credit_card"}
+	paymentTerms   = []string{"NET30", "NET30", "NET60", "NET90", "2/10N30", "1/10N30", "DUE"}
+	contactTypes   = []string{"billing", "shipping", "technical", "other"}
+)
+
+// faker generates deterministic fake vendor data from a seeded source, so the
+// same seed and entity always produce the same vendors
+type faker struct {
+	rnd *rand.Rand
+}
+
+func newFaker(seed int64, entityID string) *faker {
+	// Mix the entity into the seed so entities get different vendors
+	for _, c := range entityID {
+		seed = seed*31 + int64(c)
+	}
+	return &faker{rnd: rand.New(rand.NewSource(seed))}
+}
+
+func (f *faker) pick(values []string) string {
+	return values[f.rnd.Intn(len(values))]
+}
+
+func (f *faker) chance(percent int) bool {
+	return f.rnd.Intn(100) < percent
+}
+
+func (f *faker) digits(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		b.WriteByte(byte('0' + f.rnd.Intn(10)))
+	}
+	return b.String()
+}
+
+func (f *faker) phone() string {
+	return fmt.Sprintf("+1-%d%s-555-%s", 2+f.rnd.Intn(8), f.digits(2), f.digits(4))
+}
+
+// domain derives a website domain from a company name
+func domain(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String() + ".example.com"
+}
+
+// vendor generates the vendor with the given code
+func (f *faker) vendor(entityID, code string) *repository.Vendor {
+	base := f.pick(nameFirstParts) + " " + f.pick(nameSecondParts)
+	legal := base + " " + f.pick(nameSuffixes)
+	site := domain(base)
+	loc := cities[f.rnd.Intn(len(cities))]
+
+	v := &repository.Vendor{
+		EntityID:      entityID,
+		VendorCode:    code,
+		VendorName:    base,
+		LegalName:     &legal,
+		VendorType:    f.pick(vendorTypes),
+		Status:        f.pick(vendorStatuses),
+		IsTaxExempt:   f.chance(10),
+		Is1099Vendor:  f.chance(30),
+		Email:         ptr("ap@" + site),
+		Phone:         ptr(f.phone()),
+		Website:       ptr("https://www." + site),
+		AddressLine1:  ptr(fmt.Sprintf("%d %s", 100+f.rnd.Intn(9900), f.pick(streetNames))),
+		City:          ptr(loc.city),
+		StateProvince: ptr(loc.state),
+		PostalCode:    ptr(loc.zipPrefix + f.digits(2)),
+		Country:       "US",
+		PaymentTerms:  f.pick(paymentTerms),
+		PaymentMethod: ptr(f.pick(paymentMethods)),
+		Currency:      "USD",
+		Tags:          []string{"seed"},
+		Source:        service.VendorSourceSeed,
+	}
+	if f.chance(20) {
+		v.AddressLine2 = ptr(fmt.Sprintf("Suite %d", 100+f.rnd.Intn(900)))
+	}
+	if f.chance(70) {
+		v.TaxID = ptr(fmt.Sprintf("%s-%s", f.digits(2), f.digits(7)))
+	}
+	if f.chance(40) {
+		// Credit limits between $5,000 and $250,000, in cents
+		limit := int64(5000+f.rnd.Intn(245001)) * 100
+		v.CreditLimit = &limit
+	}
+	if *v.PaymentMethod == "ach" || *v.PaymentMethod == "wire" {
+		v.BankName = ptr(f.pick(nameFirstParts) + " Bank")
+		v.BankAccountNumber = ptr(f.digits(10))
+		v.BankRoutingNumber = ptr("0" + f.digits(8))
+	}
+	return v
+}
+
+// balance returns an outstanding balance for the vendor, or 0
+func (f *faker) balance(v *repository.Vendor) int64 {
+	if v.Status != "active" || !f.chance(50) {
+		return 0
+	}
+	max := int64(50000 * 100)
+	if v.CreditLimit != nil {
+		// Mostly within the limit, occasionally at it
+		max = *v.CreditLimit
+	}
+	return 1 + f.rnd.Int63n(max)
+}
+
+// contacts generates 0-3 contacts of the vendor, the first one primary
+func (f *faker) contacts(v *repository.Vendor) []*repository.VendorContact {
+	n := f.rnd.Intn(4)
+	contacts := make([]*repository.VendorContact, 0, n)
+	site := strings.TrimPrefix(*v.Website, "https://www.")
+	for i := 0; i < n; i++ {
+		first, last := f.pick(firstNames), f.pick(lastNames)
+		c := &repository.VendorContact{
+			ContactType: f.pick(contactTypes),
+			FirstName:   first,
+			LastName:    last,
+			Title:       ptr(f.pick(contactTitles)),
+			Email:       ptr(fmt.Sprintf("%s.%s%d@%s", strings.ToLower(first), strings.ToLower(last), i+1, site)),
+			Phone:       ptr(f.phone()),
+		}
+		if i == 0 {
+			c.ContactType = "primary"
+			c.IsPrimary = true
+		}
+		if f.chance(30) {
+			c.Mobile = ptr(f.phone())
+		}
+		contacts = append(contacts, c)
+	}
+	return contacts
+}
+
+func ptr(s string) *string {
+	return &s
+}
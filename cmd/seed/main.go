@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	svcconfig "github.com/pesio-ai/be-ap-vendors/internal/config"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/utctime"
+	"github.com/pesio-ai/be-lib-common/config"
+	"github.com/pesio-ai/be-lib-common/database"
+	"github.com/pesio-ai/be-lib-common/logger"
+)
+
+// defaultEntityID is the entity seeded when -entity is not given
+const defaultEntityID = "00000000-0000-0000-0000-000000000001"
+
+// vendorTables are emptied by -wipe
+var vendorTables = []string{
+	"vendors",
+	"vendor_contacts",
+	"vendor_documents",
+	"vendor_external_refs",
+	"vendor_audit_log",
+	"vendor_events",
+	"vendor_change_tombstones",
+	"vendor_balance_transactions",
+}
+
+// standardPaymentTerms mirrors the payment terms of migrations/001_initial_schema.sql
+var standardPaymentTerms = []struct {
+	code, description string
+	netDays           int
+	discountPercent   *float64
+	discountDays      *int
+}{
+	{"NET30", "Net 30 days", 30, nil, nil},
+	{"NET60", "Net 60 days", 60, nil, nil},
+	{"NET90", "Net 90 days", 90, nil, nil},
+	{"2/10N30", "2% 10 days, Net 30", 30, floatPtr(2), intPtr(10)},
+	{"1/10N30", "1% 10 days, Net 30", 30, floatPtr(1), intPtr(10)},
+	{"DUE", "Due on receipt", 0, nil, nil},
+	{"COD", "Cash on delivery", 0, nil, nil},
+	{"CIA", "Cash in advance", 0, nil, nil},
+}
+
+// entitySummary counts what was seeded for an entity
+type entitySummary struct {
+	entityID string
+	created  int
+	existing int
+	contacts int
+}
+
+// The seed command fills the database with fake vendors for local development.
+// Vendor codes are derived from the seed, so running it twice with the same
+// seed creates nothing new.
+func main() {
+	utctime.UseUTC()
+
+	entities := flag.String("entity", defaultEntityID, "comma-separated entity IDs to seed")
+	count := flag.Int("count", 25, "number of vendors per entity")
+	seed := flag.Int64("seed", 1, "seed of the generated data; the same seed produces the same vendors")
+	wipe := flag.Bool("wipe", false, "truncate all vendor tables before seeding (refused in production)")
+	flag.Parse()
+
+	if *count < 1 || *count > 10000 {
+		fmt.Fprintln(os.Stderr, "-count must be between 1 and 10000")
+		os.Exit(2)
+	}
+	var entityIDs []string
+	for _, id := range strings.Split(*entities, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			entityIDs = append(entityIDs, id)
+		}
+	}
+	if len(entityIDs) == 0 {
+		fmt.Fprintln(os.Stderr, "-entity must name at least one entity")
+		os.Exit(2)
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+	svcCfg := svcconfig.Load()
+
+	if *wipe && cfg.Service.Environment == "production" {
+		fmt.Fprintln(os.Stderr, "Refusing to -wipe in the production environment")
+		os.Exit(1)
+	}
+
+	// Initialize logger
+	log := logger.New(logger.Config{
+		Level:       os.Getenv("LOG_LEVEL"),
+		Environment: cfg.Service.Environment,
+		ServiceName: cfg.Service.Name + "-seed",
+		Version:     cfg.Service.Version,
+	})
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	// Initialize database
+	db, err := database.New(ctx, database.Config{
+		Host:        cfg.Database.Host,
+		Port:        cfg.Database.Port,
+		User:        cfg.Database.User,
+		Password:    cfg.Database.Password,
+		Database:    cfg.Database.Database,
+		SSLMode:     cfg.Database.SSLMode,
+		MaxConns:    cfg.Database.MaxConns,
+		MinConns:    cfg.Database.MinConns,
+		MaxConnTime: cfg.Database.MaxConnTime,
+		MaxIdleTime: cfg.Database.MaxIdleTime,
+		HealthCheck: cfg.Database.HealthCheck,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to connect to database")
+	}
+	defer db.Close()
+
+	vendorRepo := repository.NewVendorRepository(db, repository.WithQueryTimeouts(repository.QueryTimeouts{
+		Read:  svcCfg.QueryTimeoutRead,
+		Write: svcCfg.QueryTimeoutWrite,
+		Bulk:  svcCfg.QueryTimeoutBulk,
+	}))
+
+	if *wipe {
+		if _, err := db.Pool.Exec(ctx, "TRUNCATE "+strings.Join(vendorTables, ", ")+" CASCADE"); err != nil {
+			log.Fatal().Err(err).Msg("Failed to wipe vendor tables")
+		}
+		log.Info().Strs("tables", vendorTables).Msg("Vendor tables wiped")
+	}
+
+	termsCreated, err := seedPaymentTerms(ctx, db)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to seed payment terms")
+	}
+
+	summaries := make([]entitySummary, 0, len(entityIDs))
+	for _, entityID := range entityIDs {
+		summary, err := seedEntity(ctx, vendorRepo, entityID, *seed, *count)
+		if err != nil {
+			log.Fatal().Err(err).Str("entity_id", entityID).Msg("Failed to seed vendors")
+		}
+		summaries = append(summaries, summary)
+	}
+
+	fmt.Printf("Payment terms: %d created, %d already present\n", termsCreated, len(standardPaymentTerms)-termsCreated)
+	fmt.Printf("%-38s %8s %8s %8s\n", "ENTITY", "CREATED", "EXISTING", "CONTACTS")
+	for _, s := range summaries {
+		fmt.Printf("%-38s %8d %8d %8d\n", s.entityID, s.created, s.existing, s.contacts)
+	}
+}
+
+// seedPaymentTerms inserts the standard payment terms that are missing and
+// returns how many were inserted
+func seedPaymentTerms(ctx context.Context, db *database.DB) (int, error) {
+	created := 0
+	for _, term := range standardPaymentTerms {
+		tag, err := db.Pool.Exec(ctx, `
+			INSERT INTO payment_terms (code, description, net_days, discount_percent, discount_days)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (code) DO NOTHING
+		`, term.code, term.description, term.netDays, term.discountPercent, term.discountDays)
+		if err != nil {
+			return created, err
+		}
+		created += int(tag.RowsAffected())
+	}
+	return created, nil
+}
+
+// seedEntity creates the generated vendors of an entity that do not exist yet.
+// Every vendor is generated even when it exists, so the data of later vendors
+// does not depend on what was already seeded.
+func seedEntity(ctx context.Context, repo *repository.VendorRepository, entityID string, seed int64, count int) (entitySummary, error) {
+	summary := entitySummary{entityID: entityID}
+	f := newFaker(seed, entityID)
+
+	for i := 1; i <= count; i++ {
+		vendor := f.vendor(entityID, fmt.Sprintf("SEED%d-%04d", seed, i))
+		balance := f.balance(vendor)
+		contacts := f.contacts(vendor)
+
+		existing, _ := repo.GetByCode(ctx, vendor.VendorCode, entityID)
+		if existing != nil {
+			summary.existing++
+			continue
+		}
+
+		err := repo.WithTx(ctx, func(tx repository.Store) error {
+			if err := tx.Create(ctx, vendor); err != nil {
+				return err
+			}
+			for _, contact := range contacts {
+				contact.VendorID = vendor.ID
+				if err := tx.AddContact(ctx, contact); err != nil {
+					return err
+				}
+			}
+			if balance > 0 {
+				return tx.UpdateBalance(ctx, vendor.ID, entityID, balance)
+			}
+			return nil
+		})
+		if err != nil {
+			return summary, fmt.Errorf("vendor %s: %w", vendor.VendorCode, err)
+		}
+		summary.created++
+		summary.contacts += len(contacts)
+	}
+
+	return summary, nil
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func intPtr(v int) *int {
+	return &v
+}
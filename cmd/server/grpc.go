@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+
+	svcconfig "github.com/pesio-ai/be-ap-vendors/internal/config"
+	"github.com/pesio-ai/be-lib-common/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// listenGRPC binds the gRPC port. It is called before anything else is
+// started so that an occupied port stops the service right away.
+func listenGRPC(port int) (net.Listener, error) {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if errors.Is(err, syscall.EADDRINUSE) {
+		return nil, fmt.Errorf("gRPC port %d is already in use; stop the other process or set GRPC_PORT", port)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("listen on gRPC port %d: %w", port, err)
+	}
+	return lis, nil
+}
+
+// grpcServerOptions returns the message size, stream and keepalive options of
+// the gRPC server, failing on values that cannot work
+func grpcServerOptions(svcCfg *svcconfig.Config) ([]grpc.ServerOption, error) {
+	switch {
+	case svcCfg.GRPCMaxRecvMsgSize <= 0:
+		return nil, fmt.Errorf("GRPC_MAX_RECV_MSG_BYTES must be positive")
+	case svcCfg.GRPCMaxSendMsgSize <= 0:
+		return nil, fmt.Errorf("GRPC_MAX_SEND_MSG_BYTES must be positive")
+	case svcCfg.GRPCMaxConcurrentStreams <= 0:
+		return nil, fmt.Errorf("GRPC_MAX_CONCURRENT_STREAMS must be positive")
+	case svcCfg.GRPCKeepaliveTime <= 0 || svcCfg.GRPCKeepaliveTimeout <= 0 || svcCfg.GRPCKeepaliveMinTime <= 0:
+		return nil, fmt.Errorf("GRPC_KEEPALIVE_* durations must be positive")
+	}
+
+	return []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(svcCfg.GRPCMaxRecvMsgSize),
+		grpc.MaxSendMsgSize(svcCfg.GRPCMaxSendMsgSize),
+		grpc.MaxConcurrentStreams(uint32(svcCfg.GRPCMaxConcurrentStreams)),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    svcCfg.GRPCKeepaliveTime,
+			Timeout: svcCfg.GRPCKeepaliveTimeout,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             svcCfg.GRPCKeepaliveMinTime,
+			PermitWithoutStream: svcCfg.GRPCKeepalivePermitWithoutStream,
+		}),
+	}, nil
+}
+
+// logGRPCSettings logs the effective gRPC server settings
+func logGRPCSettings(log *logger.Logger, svcCfg *svcconfig.Config) {
+	log.Info().
+		Int("port", svcCfg.GRPCPort).
+		Int("max_recv_msg_bytes", svcCfg.GRPCMaxRecvMsgSize).
+		Int("max_send_msg_bytes", svcCfg.GRPCMaxSendMsgSize).
+		Int("max_concurrent_streams", svcCfg.GRPCMaxConcurrentStreams).
+		Dur("keepalive_time", svcCfg.GRPCKeepaliveTime).
+		Dur("keepalive_timeout", svcCfg.GRPCKeepaliveTimeout).
+		Dur("keepalive_min_time", svcCfg.GRPCKeepaliveMinTime).
+		Bool("keepalive_permit_without_stream", svcCfg.GRPCKeepalivePermitWithoutStream).
+		Msg("gRPC server configured")
+}
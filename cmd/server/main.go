@@ -3,13 +3,30 @@ package main
 import (
 	"context"
 	"fmt"
-	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pesio-ai/be-ap-vendors/internal/address"
+	"github.com/pesio-ai/be-ap-vendors/internal/authz"
+	"github.com/pesio-ai/be-ap-vendors/internal/bankverify"
+	svcconfig "github.com/pesio-ai/be-ap-vendors/internal/config"
+	"github.com/pesio-ai/be-ap-vendors/internal/cors"
+	"github.com/pesio-ai/be-ap-vendors/internal/debounce"
+	"github.com/pesio-ai/be-ap-vendors/internal/docstore"
+	"github.com/pesio-ai/be-ap-vendors/internal/filestore"
+	"github.com/pesio-ai/be-ap-vendors/internal/fx"
+	"github.com/pesio-ai/be-ap-vendors/internal/identity"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository/memory"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-ap-vendors/internal/spend"
+	"github.com/pesio-ai/be-ap-vendors/internal/tinmatch"
+	"github.com/pesio-ai/be-ap-vendors/internal/utctime"
 	"github.com/pesio-ai/be-lib-common/auth"
 	"github.com/pesio-ai/be-lib-common/config"
 	"github.com/pesio-ai/be-lib-common/database"
@@ -22,17 +39,19 @@ import (
 	"github.com/pesio-ai/be-ap-vendors/internal/repository"
 	"github.com/pesio-ai/be-ap-vendors/internal/service"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/reflection"
 )
 
 func main() {
+	utctime.UseUTC()
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
 		os.Exit(1)
 	}
+	svcCfg := svcconfig.Load()
 
 	// Initialize logger
 	log := logger.New(logger.Config{
@@ -48,57 +67,290 @@ func main() {
 		Str("environment", cfg.Service.Environment).
 		Msg("Starting Vendors Service (AP-1)")
 
-	// Create context
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// Bind the gRPC port first so an occupied port fails before any other setup
+	grpcOpts, err := grpcServerOptions(svcCfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid gRPC server configuration")
+	}
+	grpcListener, err := listenGRPC(svcCfg.GRPCPort)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to create gRPC listener")
+	}
+	logGRPCSettings(log, svcCfg)
 
-	// Initialize database
-	db, err := database.New(ctx, database.Config{
-		Host:        cfg.Database.Host,
-		Port:        cfg.Database.Port,
-		User:        cfg.Database.User,
-		Password:    cfg.Database.Password,
-		Database:    cfg.Database.Database,
-		SSLMode:     cfg.Database.SSLMode,
-		MaxConns:    cfg.Database.MaxConns,
-		MinConns:    cfg.Database.MinConns,
-		MaxConnTime: cfg.Database.MaxConnTime,
-		MaxIdleTime: cfg.Database.MaxIdleTime,
-		HealthCheck: cfg.Database.HealthCheck,
-	})
+	// Load TLS material; missing, unreadable or expired files stop the service
+	if cfg.Service.Environment == "production" && (svcCfg.GRPCTLSDisabled || svcCfg.IdentityTLSDisabled) {
+		log.Fatal().Msg("GRPC_TLS_DISABLED and IDENTITY_TLS_DISABLED are not allowed in production")
+	}
+	serverTLS, err := loadServerTLS(svcCfg)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to connect to database")
+		log.Fatal().Err(err).Msg("Failed to load gRPC server TLS certificates")
+	}
+	identityTLS, err := loadIdentityTLS(svcCfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load identity client TLS certificates")
+	}
+	if serverTLS == nil {
+		log.Warn().Msg("GRPC_TLS_DISABLED is set; gRPC server runs in plaintext")
+	} else {
+		log.Info().
+			Time("expires_at", serverTLS.Status().Expiry()).
+			Bool("mtls", svcCfg.GRPCTLSClientCAFile != "").
+			Msg("gRPC server TLS enabled")
+	}
+	if identityTLS == nil {
+		log.Warn().Msg("IDENTITY_TLS_DISABLED is set; identity connection is plaintext")
 	}
-	defer db.Close()
-	log.Info().Msg("Database connection established")
 
-	// Initialize repositories
-	vendorRepo := repository.NewVendorRepository(db)
+	// Create context; cancelling it stops the background processors, which
+	// background waits for before the database is closed
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var background sync.WaitGroup
+
+	reloadCertificates(ctx, log, svcCfg, serverTLS, identityTLS)
+
+	// Initialize storage
+	var (
+		vendorRepo repository.Store
+		db         *database.DB
+		closeDB    func()
+	)
+	switch svcCfg.Storage {
+	case svcconfig.StoragePostgres:
+		vendorRepo, db, closeDB = openPostgres(ctx, &background, log, cfg, svcCfg)
+		checkSchema(ctx, log, db, svcCfg.RunMigrations)
+	case svcconfig.StorageMemory:
+		if cfg.Service.Environment == "production" {
+			log.Fatal().Msg("VENDORS_STORAGE=memory is not allowed in production")
+		}
+		vendorRepo = memory.New()
+		log.Warn().Msg("Using in-memory storage; vendors are lost on restart")
+	default:
+		log.Fatal().Str("storage", svcCfg.Storage).Msg("Invalid VENDORS_STORAGE (expected postgres or memory)")
+	}
 
 	// Initialize services
-	vendorService := service.NewVendorService(vendorRepo, log)
+	if !service.IsValidContactMethodRule(svcCfg.ContactMethodRule) {
+		log.Fatal().Str("contact_method_rule", svcCfg.ContactMethodRule).Msg("Invalid CONTACT_METHOD_RULE (expected off, warn or enforce)")
+	}
+	if !service.IsValidCountryProfileRule(svcCfg.CountryProfileRule) {
+		log.Fatal().Str("country_profile_rule", svcCfg.CountryProfileRule).Msg("Invalid COUNTRY_PROFILE_RULE (expected off, warn or enforce)")
+	}
+	if !service.IsValidBlocklistPolicy(svcCfg.BlocklistPolicy) {
+		log.Fatal().Str("blocklist_policy", svcCfg.BlocklistPolicy).Msg("Invalid BLOCKLIST_POLICY (expected block or warn)")
+	}
+	dataQualityRules, err := service.ParseDataQualityRules(svcCfg.DataQualityRules)
+	if err != nil {
+		log.Fatal().Err(err).Strs("data_quality_rules", svcCfg.DataQualityRules).Msg("Invalid DATA_QUALITY_RULES")
+	}
+	requiredCommunications, err := service.ParseCommunicationTypes(svcCfg.RequiredCommunicationTypes)
+	if err != nil {
+		log.Fatal().Err(err).Strs("required_communication_types", svcCfg.RequiredCommunicationTypes).Msg("Invalid REQUIRED_COMMUNICATION_TYPES")
+	}
+	if svcCfg.DormantAfterMonths < 1 {
+		log.Fatal().Int("dormant_after_months", svcCfg.DormantAfterMonths).Msg("DORMANT_AFTER_MONTHS must be positive")
+	}
+	var addressValidator address.Validator = address.Noop{}
+	if svcCfg.AddressValidationURL != "" {
+		addressValidator = address.NewProviderValidator(svcCfg.AddressValidationURL, svcCfg.AddressValidationAPIKey, svcCfg.AddressValidationTimeout)
+	}
+	var tinMatcher tinmatch.Matcher = tinmatch.Stub{}
+	if svcCfg.TINMatchURL != "" {
+		tinMatcher = tinmatch.NewProviderMatcher(svcCfg.TINMatchURL, svcCfg.TINMatchAPIKey,
+			svcCfg.TINMatchBatchSize, svcCfg.TINMatchCallsPerMinute, svcCfg.TINMatchTimeout)
+	}
+	var bankVerifier bankverify.Verifier = bankverify.Stub{}
+	if svcCfg.BankVerificationURL != "" {
+		bankVerifier = bankverify.NewProviderVerifier(svcCfg.BankVerificationURL, svcCfg.BankVerificationAPIKey, svcCfg.BankVerificationTimeout)
+	}
+	// No exchange rates are configured, so foreign credit limits stay uncompared
+	var fxConverter fx.Converter = fx.Stub{}
+	vendorQuotas, err := service.ParseVendorQuotas(svcCfg.VendorQuotas)
+	if err != nil || svcCfg.VendorQuotaDefault < 0 {
+		log.Fatal().Err(err).Int("vendor_quota_default", svcCfg.VendorQuotaDefault).Msg("Invalid VENDOR_QUOTAS or VENDOR_QUOTA_DEFAULT")
+	}
+	var recentCreates debounce.Store = debounce.NewMemory(svcCfg.CreateDebounceCacheSize)
+	if svcCfg.RedisURL != "" {
+		redisStore, err := debounce.NewRedis(svcCfg.RedisURL, svcCfg.RedisTimeout)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid REDIS_URL")
+		}
+		recentCreates = redisStore
+	}
+	// Vendor spend comes from the payments service; without one it is reported as zero
+	var spendProvider spend.Provider = spend.Stub{}
+	if svcCfg.PaymentsGRPCURL != "" {
+		if svcCfg.PaymentsCallTimeout <= 0 || svcCfg.SpendCacheSize <= 0 {
+			log.Fatal().Msg("PAYMENTS_CALL_TIMEOUT_MS and SPEND_CACHE_SIZE must be positive")
+		}
+		paymentsCreds, err := clientCredentials(identityTLS, svcCfg.PaymentsGRPCURL, svcCfg.PaymentsTLSServerName, "PAYMENTS_GRPC_URL")
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid payments TLS configuration")
+		}
+		paymentsConn, err := grpc.NewClient(svcCfg.PaymentsGRPCURL, paymentsCreds)
+		if err != nil {
+			log.Fatal().Err(err).Str("payments_grpc", svcCfg.PaymentsGRPCURL).Msg("Invalid payments service address")
+		}
+		defer paymentsConn.Close()
+
+		spendProvider = spend.NewGRPCProvider(pb.NewPaymentsServiceClient(paymentsConn), svcCfg.PaymentsCallTimeout)
+		if svcCfg.SpendCacheTTL > 0 {
+			spendProvider = spend.NewCache(spendProvider, svcCfg.SpendCacheTTL, svcCfg.SpendCacheSize)
+		}
+		log.Info().
+			Str("payments_grpc", svcCfg.PaymentsGRPCURL).
+			Bool("tls", identityTLS != nil).
+			Dur("call_timeout", svcCfg.PaymentsCallTimeout).
+			Dur("spend_cache_ttl", svcCfg.SpendCacheTTL).
+			Msg("Payments service client initialized")
+	}
+	// Vendor exports are generated by the worker into a directory shared with it
+	var exportFiles filestore.Store
+	if svcCfg.ExportStorageDir != "" {
+		if exportFiles, err = filestore.NewDir(svcCfg.ExportStorageDir); err != nil {
+			log.Fatal().Err(err).Str("export_storage_dir", svcCfg.ExportStorageDir).Msg("Invalid EXPORT_STORAGE_DIR")
+		}
+	}
+	// Vendor documents are read from object storage, streamed or redirected to
+	var documentStore docstore.Store
+	if svcCfg.DocumentStorageURL != "" {
+		if documentStore, err = docstore.NewHTTP(svcCfg.DocumentStorageURL, []byte(svcCfg.DocumentLinkSigningKey), svcCfg.DocumentStorageTimeout); err != nil {
+			log.Fatal().Err(err).Msg("Invalid DOCUMENT_STORAGE_URL")
+		}
+		log.Info().
+			Str("document_storage_url", svcCfg.DocumentStorageURL).
+			Bool("signed_links", svcCfg.DocumentLinkSigningKey != "").
+			Int64("stream_max_bytes", svcCfg.DocumentStreamMaxBytes).
+			Msg("Vendor document storage configured")
+	}
 
 	// Connect to identity service for authentication
-	identityGrpcAddr := getEnv("IDENTITY_GRPC_URL", "localhost:9080")
-	identityConn, err := grpc.NewClient(identityGrpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	identityGrpcAddr := svcCfg.IdentityGRPCURL
+	identityCreds, err := clientCredentials(identityTLS, identityGrpcAddr, svcCfg.IdentityTLSServerName, "IDENTITY_GRPC_URL")
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid identity TLS configuration")
+	}
+	if svcCfg.IdentityCallTimeout <= 0 || svcCfg.IdentityBreakerThreshold <= 0 || svcCfg.IdentityCacheSize <= 0 {
+		log.Fatal().Msg("IDENTITY_CALL_TIMEOUT_MS, IDENTITY_BREAKER_THRESHOLD and IDENTITY_AUTH_CACHE_SIZE must be positive")
+	}
+	identityGuard := identity.NewGuard(identity.Options{
+		CallTimeout:      svcCfg.IdentityCallTimeout,
+		MaxRetries:       svcCfg.IdentityMaxRetries,
+		RetryBackoff:     svcCfg.IdentityRetryBackoff,
+		BreakerThreshold: svcCfg.IdentityBreakerThreshold,
+		BreakerCooldown:  svcCfg.IdentityBreakerCooldown,
+		CacheTTL:         svcCfg.IdentityCacheTTL,
+		CacheSize:        svcCfg.IdentityCacheSize,
+	})
+
+	// The connection is established lazily on the first call, so an identity
+	// service that is down at startup only fails requests until it is back.
+	// NewClient only fails on an invalid address.
+	identityConn, err := grpc.NewClient(identityGrpcAddr, identityCreds,
+		grpc.WithUnaryInterceptor(identityGuard.UnaryClientInterceptor()),
+	)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to connect to identity service")
+		log.Fatal().Err(err).Str("identity_grpc", identityGrpcAddr).Msg("Invalid identity service address")
 	}
 	defer identityConn.Close()
 
 	identityClient := identitypb.NewIdentityServiceClient(identityConn)
-	log.Info().Str("identity_grpc", identityGrpcAddr).Msg("Identity service client initialized")
+	log.Info().
+		Str("identity_grpc", identityGrpcAddr).
+		Bool("tls", identityTLS != nil).
+		Dur("call_timeout", svcCfg.IdentityCallTimeout).
+		Int("max_retries", svcCfg.IdentityMaxRetries).
+		Int("breaker_threshold", svcCfg.IdentityBreakerThreshold).
+		Dur("breaker_cooldown", svcCfg.IdentityBreakerCooldown).
+		Dur("auth_cache_ttl", svcCfg.IdentityCacheTTL).
+		Msg("Identity service client initialized")
+
+	// created_by, updated_by and activity actors are resolved to users with
+	// the identity service on request
+	if svcCfg.UserCacheSize <= 0 {
+		log.Fatal().Msg("USER_CACHE_SIZE must be positive")
+	}
+	var userResolver identity.UserResolver = identity.NewGRPCUserResolver(identityClient, svcCfg.IdentityCallTimeout)
+	if svcCfg.UserCacheTTL > 0 {
+		userResolver = identity.NewUserCache(userResolver, svcCfg.UserCacheTTL, svcCfg.UserCacheSize)
+	}
+
+	vendorService := service.NewVendorService(vendorRepo, log,
+		service.WithContactMethodRule(svcCfg.ContactMethodRule),
+		service.WithCountryProfileRule(svcCfg.CountryProfileRule),
+		service.WithAddressValidator(addressValidator),
+		service.WithStrictAddressValidation(svcCfg.StrictAddressValidation),
+		service.WithStrictBankValidation(svcCfg.StrictBankValidation),
+		service.WithRequireBankVerification(svcCfg.RequireBankVerification),
+		service.WithBankVerifier(bankVerifier),
+		service.WithEntitySettingsCacheTTL(svcCfg.EntitySettingsCacheTTL),
+		service.WithListTotalEstimates(svcCfg.ListEstimateTotalAbove, svcCfg.ListEntityCountCacheTTL),
+		service.WithVendorCodeReservationTTL(svcCfg.VendorCodeReservationTTL),
+		service.WithDataQualityRules(dataQualityRules),
+		service.WithRequiredCommunications(requiredCommunications),
+		service.WithCurrencyChangeActivityWindow(svcCfg.CurrencyChangeActivityDays),
+		service.WithFXConverter(fxConverter),
+		service.WithBlocklistPolicy(svcCfg.BlocklistPolicy),
+		service.WithQuotaProvider(&service.StaticQuotaProvider{
+			Default: int64(svcCfg.VendorQuotaDefault),
+			Limits:  vendorQuotas,
+		}),
+		service.WithCreateDebounce(recentCreates, svcCfg.CreateDebounceWindow),
+		service.WithBulkDeleteConfirmations(recentCreates, svcCfg.BulkDeleteConfirmationTTL),
+		service.WithApprovalSLA(svcCfg.ApprovalSLA),
+		service.WithDormancy(svcCfg.DormantAfterMonths, svcCfg.DormancyEvents),
+		service.WithSpendProvider(spendProvider),
+		service.WithTINMatcher(tinMatcher),
+		service.WithVendorExports(exportFiles, []byte(svcCfg.ExportLinkSigningKey), svcCfg.ExportLinkTTL, svcCfg.ExportRetention),
+		service.WithUserResolver(userResolver),
+		service.WithDocumentStore(documentStore, svcCfg.DocumentLinkTTL, svcCfg.DocumentStreamMaxBytes),
+	)
 
 	// Setup HTTP handler
-	httpHandler := handler.NewHTTPHandler(vendorService, log)
+	admins := authz.NewAdminPolicy(svcCfg.AdminUserIDs)
+	sections, err := authz.NewSectionPolicy(svcCfg.VendorSectionAccess, service.SnapshotSections, admins)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid VENDOR_SECTION_ACCESS")
+	}
+
+	httpHandler := handler.NewHTTPHandler(vendorService, log, handler.HTTPOptions{
+		StrictQueryParams: svcCfg.StrictQueryParams,
+		AdminToken:        svcCfg.AdminAPIToken,
+		Retention: service.RetentionPolicy{
+			DeletedVendorDays: svcCfg.RetentionDeletedVendorDays,
+			AuditLogDays:      svcCfg.RetentionAuditLogDays,
+		},
+		Sections: sections,
+	})
+
+	// Feed committed vendor changes to WatchVendors streams; without Postgres
+	// watchers poll on every heartbeat instead
+	if db != nil {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			vendorService.RunChangeListener(ctx, repository.NewChangeListener(db))
+		}()
+	}
 
 	// Setup gRPC handler
-	grpcHandler := handler.NewGRPCHandler(vendorService, log)
+	grpcHandler := handler.NewGRPCHandler(vendorService, log, handler.GRPCOptions{
+		WatchHeartbeatInterval: svcCfg.WatchHeartbeatInterval,
+		Admins:                 admins,
+		Sections:               sections,
+	})
 	mux := http.NewServeMux()
 
 	// Health check
 	healthHandler := health.NewHandler("be-ap-vendors", cfg.Service.Version)
 	mux.Handle("/health", healthHandler)
+	ready := &readiness{}
+	if db != nil {
+		ready.pool = db.Pool
+	}
+	mux.Handle("/health/ready", ready)
+	mux.Handle("/health/tls", handler.NewCertHealthHandler(svcCfg.TLSExpiryWarning, serverTLS, identityTLS))
 
 	// Vendor routes
 	mux.HandleFunc("/api/v1/vendors", func(w http.ResponseWriter, r *http.Request) {
@@ -114,11 +366,68 @@ func main() {
 
 	mux.HandleFunc("/api/v1/vendors/get", httpHandler.GetVendor)
 	mux.HandleFunc("/api/v1/vendors/code", httpHandler.GetVendorByCode)
+	mux.HandleFunc("/api/v1/vendors/code-reservations", httpHandler.ReserveVendorCode)
 	mux.HandleFunc("/api/v1/vendors/update", httpHandler.UpdateVendor)
 	mux.HandleFunc("/api/v1/vendors/delete", httpHandler.DeleteVendor)
+	mux.HandleFunc("/api/v1/vendors/bulk-delete", httpHandler.BulkDeleteVendors)
 	mux.HandleFunc("/api/v1/vendors/activate", httpHandler.ActivateVendor)
 	mux.HandleFunc("/api/v1/vendors/deactivate", httpHandler.DeactivateVendor)
+	mux.HandleFunc("/api/v1/vendors/suspend", httpHandler.SuspendVendor)
 	mux.HandleFunc("/api/v1/vendors/validate", httpHandler.ValidateVendor)
+	mux.HandleFunc("/api/v1/vendors/by-external-ref", httpHandler.GetVendorByExternalRef)
+	mux.HandleFunc("/api/v1/vendors/external-refs", httpHandler.VendorExternalRefs)
+	mux.HandleFunc("/api/v1/vendors/changes", httpHandler.ListVendorChanges)
+	mux.HandleFunc("/api/v1/vendors/bank-changes", httpHandler.ListBankChanges)
+	mux.HandleFunc("/api/v1/vendors/find-by-bank-last4", httpHandler.FindVendorsByBankLast4)
+	mux.HandleFunc("/api/v1/vendors/stats", httpHandler.GetVendorStats)
+	mux.HandleFunc("/api/v1/vendors/metrics/growth", httpHandler.GetVendorGrowth)
+	mux.HandleFunc("/api/v1/vendors/reports/geography", httpHandler.GetGeographyReport)
+	mux.HandleFunc("/api/v1/vendors/reports/payment-mix", httpHandler.GetPaymentMixReport)
+	mux.HandleFunc("/api/v1/vendors/approval-queue", httpHandler.ListApprovalQueue)
+	mux.HandleFunc("/api/v1/vendors/approval-queue/count", httpHandler.CountApprovalQueue)
+	mux.HandleFunc("/api/v1/vendors/match", httpHandler.MatchVendors)
+	mux.HandleFunc("/api/v1/vendors/tags", httpHandler.ListTags)
+	mux.HandleFunc("/api/v1/vendors/favorites", httpHandler.ListFavoriteVendors)
+	mux.HandleFunc("/api/v1/vendors/recent", httpHandler.ListRecentVendors)
+	mux.HandleFunc("/api/v1/vendors/scheduled-status-changes", httpHandler.ListScheduledStatusChanges)
+	mux.HandleFunc("/api/v1/vendors/dormant", httpHandler.ListDormantVendors)
+	mux.HandleFunc("/api/v1/vendors/1099-nec", httpHandler.Form1099Report)
+	mux.HandleFunc("/api/v1/vendors/export-jobs", httpHandler.StartVendorExport)
+	mux.HandleFunc("/api/v1/vendors/import-bundle", httpHandler.ImportVendorBundle)
+	mux.HandleFunc("/api/v1/vendors/{id}", httpHandler.GetVendor)
+	mux.HandleFunc("/api/v1/vendors/{id}/contacts/{contact_id}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			httpHandler.GetVendorContact(w, r)
+		case http.MethodDelete:
+			httpHandler.DeleteVendorContact(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/api/v1/vendors/{id}/contacts/{contact_id}/communications", httpHandler.SetContactCommunications)
+	mux.HandleFunc("/api/v1/vendors/{id}/communication-contacts", httpHandler.GetContactsForCommunication)
+	mux.HandleFunc("/api/v1/vendors/{id}/snapshot", httpHandler.GetVendorSnapshot)
+	mux.HandleFunc("/api/v1/vendors/{id}/export", httpHandler.ExportVendorBundle)
+	mux.HandleFunc("/api/v1/vendors/{id}/activity", httpHandler.GetVendorActivity)
+	mux.HandleFunc("/api/v1/vendors/{id}/spend", httpHandler.GetVendorSpend)
+	mux.HandleFunc("/api/v1/vendors/{id}/onboarding-status", httpHandler.GetOnboardingStatus)
+	mux.HandleFunc("/api/v1/vendors/{id}/approve", httpHandler.ApproveVendor)
+	mux.HandleFunc("/api/v1/vendors/{id}/reject", httpHandler.RejectVendor)
+	mux.HandleFunc("/api/v1/vendors/{id}/api-keys", httpHandler.VendorAPIKeys)
+	mux.HandleFunc("/api/v1/vendors/{id}/api-keys/{key_id}/revoke", httpHandler.RevokeVendorAPIKey)
+	mux.HandleFunc("/api/v1/vendors/{id}/aliases", httpHandler.VendorAliases)
+	mux.HandleFunc("/api/v1/vendors/{id}/aliases/{alias_id}", httpHandler.DeleteVendorAlias)
+	mux.HandleFunc("/api/v1/vendors/{id}/favorite", httpHandler.ToggleFavoriteVendor)
+	mux.HandleFunc("/api/v1/vendors/{id}/verify-tin", httpHandler.VerifyVendorTIN)
+	mux.HandleFunc("/api/v1/vendors/{id}/bank-verification", httpHandler.StartBankVerification)
+	mux.HandleFunc("/api/v1/vendors/{id}/bank-verification/confirm", httpHandler.ConfirmBankVerification)
+	mux.HandleFunc("/api/v1/vendors/{id}/scheduled-status-changes", httpHandler.VendorScheduledStatusChanges)
+	mux.HandleFunc("/api/v1/vendors/{id}/scheduled-status-changes/{schedule_id}/cancel", httpHandler.CancelScheduledStatusChange)
+
+	// Supplier portal routes (vendor API keys only)
+	mux.HandleFunc("/api/v1/portal/profile", httpHandler.GetPortalProfile)
+	mux.HandleFunc("/api/v1/portal/spend", httpHandler.GetPortalSpend)
 
 	// Vendor contact routes
 	mux.HandleFunc("/api/v1/vendors/contacts", func(w http.ResponseWriter, r *http.Request) {
@@ -131,20 +440,135 @@ func main() {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
 	})
+	mux.HandleFunc("/api/v1/vendors/contacts/export", httpHandler.ExportContacts)
+	mux.HandleFunc("/api/v1/vendors/contacts/import", httpHandler.ImportContacts)
+	mux.HandleFunc("/api/v1/vendors/contacts/duplicate-emails", httpHandler.ListDuplicateContactEmails)
+	mux.HandleFunc("/api/v1/vendors/data-quality", httpHandler.GetDataQualityReport)
+	mux.HandleFunc("/api/v1/vendors/suspected-test", httpHandler.ListSuspectedTestVendors)
+	mux.HandleFunc("/api/v1/vendors/suspected-test/confirm", httpHandler.ConfirmTestVendors)
+	mux.HandleFunc("/api/v1/vendors/summaries", httpHandler.ListVendorSummaries)
+	mux.HandleFunc("/api/v1/import-templates", httpHandler.ImportTemplates)
+	mux.HandleFunc("/api/v1/import-templates/{id}", httpHandler.ImportTemplate)
+	mux.HandleFunc("/api/v1/vendor-templates", httpHandler.VendorTemplates)
+	mux.HandleFunc("/api/v1/vendor-templates/{id}", httpHandler.VendorTemplate)
 
 	// Payment terms routes
 	mux.HandleFunc("/api/v1/payment-terms", httpHandler.GetPaymentTerms)
+	mux.HandleFunc("/api/v1/payment-terms/{code}/usage", httpHandler.GetPaymentTermUsage)
+
+	// Vendor type routes
+	mux.HandleFunc("/api/v1/vendor-types", httpHandler.ListVendorTypes)
+
+	// Country profile routes
+	mux.HandleFunc("/api/v1/country-profiles", httpHandler.ListCountryProfiles)
 
 	// Vendor balance routes
 	mux.HandleFunc("/api/v1/vendors/balance", httpHandler.UpdateBalance)
 
+	// Internal admin routes (guarded by ADMIN_API_TOKEN)
+	mux.HandleFunc("/api/v1/admin/purge", httpHandler.Purge)
+	mux.HandleFunc("/api/v1/admin/retention", httpHandler.RetentionSettings)
+	mux.HandleFunc("/api/v1/admin/validation-settings", httpHandler.ValidationSettings)
+	mux.HandleFunc("/api/v1/admin/entity-settings", httpHandler.EntitySettings)
+	mux.HandleFunc("/api/v1/admin/approval-policy", httpHandler.ApprovalPolicy)
+	mux.HandleFunc("/api/v1/admin/risk-weights", httpHandler.RiskWeights)
+	mux.HandleFunc("/api/v1/admin/risk-scores/recompute", httpHandler.RecomputeRiskScores)
+	mux.HandleFunc("/api/v1/admin/dormant-vendors/detect", httpHandler.DetectDormantVendors)
+	mux.HandleFunc("/api/v1/admin/balances/reconcile", httpHandler.ReconcileBalances)
+	mux.HandleFunc("/api/v1/admin/tin-matching/run", httpHandler.RunTINMatching)
+	mux.HandleFunc("/api/v1/admin/entity-cleanup", httpHandler.CleanupEntity)
+	mux.HandleFunc("/api/v1/admin/entity-lifecycle", httpHandler.EntityLifecycle)
+	mux.HandleFunc("/api/v1/admin/vendor-types", httpHandler.VendorTypes)
+	mux.HandleFunc("/api/v1/admin/payment-terms/{code}/deactivate", httpHandler.DeactivatePaymentTerm)
+	mux.HandleFunc("/api/v1/admin/normalize-addresses", httpHandler.NormalizeAddresses)
+	mux.HandleFunc("/api/v1/admin/blocklist", httpHandler.Blocklist)
+	mux.HandleFunc("/api/v1/admin/blocklist/matches", httpHandler.BlocklistMatches)
+	mux.HandleFunc("/api/v1/admin/blocklist/{id}", httpHandler.DeleteBlocklistEntry)
+	mux.HandleFunc("/api/v1/admin/tags/rename", httpHandler.RenameTag)
+	mux.HandleFunc("/api/v1/admin/tags/delete", httpHandler.DeleteTag)
+
+	// CORS policy: any origin only by default in development, explicit allowlist elsewhere
+	corsOrigins := svcCfg.CORSAllowedOrigins
+	if len(corsOrigins) == 0 && cfg.Service.Environment == "development" {
+		corsOrigins = []string{"*"}
+	}
+	corsPolicy, err := cors.New(cors.Config{
+		AllowedOrigins:   corsOrigins,
+		AllowCredentials: svcCfg.CORSAllowCredentials,
+		MaxAge:           svcCfg.CORSMaxAge,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid CORS configuration")
+	}
+	if len(corsOrigins) == 0 {
+		log.Warn().Msg("CORS_ALLOWED_ORIGINS is empty; cross-origin browser requests will be blocked")
+	}
+	log.Info().
+		Strs("origins", corsOrigins).
+		Bool("allow_credentials", svcCfg.CORSAllowCredentials).
+		Msg("CORS policy configured")
+
+	if svcCfg.MaxRequestBodyBytes <= 0 || svcCfg.MaxUploadBodyBytes <= 0 {
+		log.Fatal().Msg("MAX_REQUEST_BODY_BYTES and MAX_UPLOAD_BODY_BYTES must be positive")
+	}
+
 	// Apply middleware
-	var h http.Handler = mux
+	reqlogOpts := reqlog.Options{SlowThreshold: svcCfg.SlowRequestThreshold}
+
+	// The by-code route has its own mux: its pattern overlaps every
+	// /api/v1/vendors/{id}/... pattern, which ServeMux rejects as a conflict.
+	// Vendor IDs are UUIDs, so no vendor is shadowed.
+	byCodeMux := http.NewServeMux()
+	byCodeMux.HandleFunc("/api/v1/vendors/by-code/{code}", httpHandler.UpsertVendorByCode)
+
+	// Document downloads have their own mux for the same reason, and shadow
+	// no vendor either.
+	documentsMux := http.NewServeMux()
+	documentsMux.HandleFunc("/api/v1/vendors/documents/{id}/download", httpHandler.DownloadVendorDocument)
+
+	// Export job status and downloads too; starting an export, POST
+	// /api/v1/vendors/export-jobs, stays on the main mux.
+	exportJobsMux := http.NewServeMux()
+	exportJobsMux.HandleFunc("/api/v1/vendors/export-jobs/{id}", httpHandler.GetExportJob)
+	exportJobsMux.HandleFunc("/api/v1/vendors/export-jobs/{id}/download", httpHandler.DownloadExportJob)
+
+	// Create auth interceptor; identity outages surface as Unauthenticated with retry-after.
+	// HTTP requests with an Authorization header are authenticated by it too.
+	authInterceptor := auth.NewInterceptor(identityClient, log)
+	userAuth := identityGuard.WrapAuth(authInterceptor.UnaryServerInterceptor())
+
+	var h http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/api/v1/vendors/by-code/") {
+			byCodeMux.ServeHTTP(w, r)
+			return
+		}
+		if strings.HasPrefix(r.URL.Path, "/api/v1/vendors/documents/") {
+			documentsMux.ServeHTTP(w, r)
+			return
+		}
+		if strings.HasPrefix(r.URL.Path, "/api/v1/vendors/export-jobs/") {
+			exportJobsMux.ServeHTTP(w, r)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+	h = handler.LookupCache(h)
+	h = handler.ReadConsistency(h)
+	h = handler.StrictJSON(svcCfg.StrictJSONBodies)(h)
+	h = handler.UserAuth(userAuth)(h)
+	h = handler.VendorKeyAuth(vendorService, &log.Logger)(h)
+	h = reqlog.Middleware(&log.Logger, reqlogOpts)(h)
+	h = handler.BodyLimit(svcCfg.MaxRequestBodyBytes, map[string]int64{
+		"/api/v1/vendors/contacts/import": svcCfg.MaxUploadBodyBytes,
+		"/api/v1/vendors/1099-nec":        svcCfg.MaxUploadBodyBytes,
+	})(h)
 	h = middleware.RequestID(h)
 	h = middleware.Logger(&log.Logger)(h)
 	h = middleware.Recovery(&log.Logger)(h)
-	h = middleware.CORS([]string{"*"})(h)
+	h = corsPolicy.Handler(h)
 	h = middleware.Timeout(30 * time.Second)(h)
+	httpFlight := newInFlight()
+	h = httpFlight.Middleware(h)
 
 	httpServer := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
@@ -161,66 +585,308 @@ func main() {
 		}
 	}()
 
-	// Setup gRPC server with auth interceptor
-	grpcPort := getEnvInt("GRPC_PORT", 9086) // AP Vendors gRPC port
+	httpServers := []trackedHTTPServer{{srv: httpServer, flight: httpFlight}}
 
-	// Create auth interceptor
-	authInterceptor := auth.NewInterceptor(identityClient, log)
+	// Admin listener for diagnostics and pprof, separate from the public mux
+	if svcCfg.AdminHTTPPort > 0 {
+		if svcCfg.AdminAPIToken == "" {
+			log.Warn().Int("port", svcCfg.AdminHTTPPort).Msg("ADMIN_API_TOKEN is not set; admin listener will reject every request")
+		}
+
+		adminMux := http.NewServeMux()
+		var pool *pgxpool.Pool
+		if db != nil {
+			pool = db.Pool
+		}
+		handler.NewDebugHandler(cfg.Service.Name, cfg.Service.Version, debugConfig(cfg, svcCfg), pool).Register(adminMux)
+
+		var ah http.Handler = adminMux
+		ah = handler.RequireAdminToken(svcCfg.AdminAPIToken)(ah)
+		ah = middleware.RequestID(ah)
+		ah = middleware.Logger(&log.Logger)(ah)
+		ah = middleware.Recovery(&log.Logger)(ah)
+		adminFlight := newInFlight()
+		ah = adminFlight.Middleware(ah)
+
+		// No write timeout: CPU profiles and traces stream for the requested duration
+		adminServer := &http.Server{
+			Addr:        fmt.Sprintf(":%d", svcCfg.AdminHTTPPort),
+			Handler:     ah,
+			ReadTimeout: cfg.Server.ReadTimeout,
+			IdleTimeout: cfg.Server.IdleTimeout,
+		}
+
+		go func() {
+			log.Info().Int("port", svcCfg.AdminHTTPPort).Msg("Starting admin HTTP server")
+			if err := adminServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Error().Err(err).Msg("Admin HTTP server failed")
+			}
+		}()
+		httpServers = append(httpServers, trackedHTTPServer{srv: adminServer, flight: adminFlight})
+	}
+
+	// Calls presenting a vendor API key are authenticated by the key instead
+	authUnary := handler.VendorKeyInterceptor(vendorService, &log.Logger, userAuth)
 
 	// Create gRPC server with auth interceptor
-	grpcServer := grpc.NewServer(
-		grpc.UnaryInterceptor(authInterceptor.UnaryServerInterceptor()),
-	)
+	grpcOpts = append(grpcOpts, grpcServerCredentials(serverTLS)...)
+	grpcFlight := newInFlight()
+	grpcServer := grpc.NewServer(append(grpcOpts,
+		grpc.ChainUnaryInterceptor(
+			grpcFlight.UnaryInterceptor(),
+			authUnary,
+			reqlog.UnaryServerInterceptor(&log.Logger, reqlogOpts),
+			handler.ValidationInterceptor(),
+			handler.ReadConsistencyInterceptor(),
+			handler.LookupCacheInterceptor(),
+		),
+		grpc.ChainStreamInterceptor(
+			grpcFlight.StreamInterceptor(),
+			handler.StreamAuthInterceptor(authUnary),
+			reqlog.StreamServerInterceptor(),
+			handler.ValidationStreamInterceptor(),
+		),
+	)...)
 	pb.RegisterVendorsServiceServer(grpcServer, grpcHandler)
 	reflection.Register(grpcServer)
 
-	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%d", grpcPort))
-	if err != nil {
-		log.Fatal().Err(err).Int("port", grpcPort).Msg("Failed to create gRPC listener")
-	}
-
 	go func() {
-		log.Info().Int("port", grpcPort).Msg("Starting gRPC server")
+		log.Info().Int("port", svcCfg.GRPCPort).Msg("Starting gRPC server")
 		if err := grpcServer.Serve(grpcListener); err != nil {
 			log.Error().Err(err).Msg("gRPC server failed")
 		}
 	}()
 
 	// Graceful shutdown
-	quit := make(chan os.Signal, 1)
+	quit := make(chan os.Signal, 2)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-
-	log.Info().Msg("Shutting down servers...")
+	plan := &shutdownPlan{
+		log:            log,
+		ready:          ready,
+		preStopDelay:   svcCfg.ShutdownPreStopDelay,
+		timeout:        cfg.Server.ShutdownTimeout,
+		httpServers:    httpServers,
+		grpcServer:     grpcServer,
+		grpcFlight:     grpcFlight,
+		stopBackground: cancel,
+		background:     &background,
+		closeDB:        closeDB,
+	}
+	plan.run(quit)
 
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
-	defer shutdownCancel()
+	log.Info().Msg("Shutdown complete")
+}
 
-	// Shutdown HTTP server
-	if err := httpServer.Shutdown(shutdownCtx); err != nil {
-		log.Error().Err(err).Msg("HTTP server shutdown failed")
+// openPostgres connects to the primary database and the optional read replica
+// and returns the repository, the primary and a func closing both. The
+// replica monitor runs until ctx is cancelled and is added to background.
+func openPostgres(ctx context.Context, background *sync.WaitGroup, log *logger.Logger, cfg *config.Config, svcCfg *svcconfig.Config) (*repository.VendorRepository, *database.DB, func()) {
+	db, err := database.New(ctx, database.Config{
+		Host:        cfg.Database.Host,
+		Port:        cfg.Database.Port,
+		User:        cfg.Database.User,
+		Password:    cfg.Database.Password,
+		Database:    cfg.Database.Database,
+		SSLMode:     cfg.Database.SSLMode,
+		MaxConns:    cfg.Database.MaxConns,
+		MinConns:    cfg.Database.MinConns,
+		MaxConnTime: cfg.Database.MaxConnTime,
+		MaxIdleTime: cfg.Database.MaxIdleTime,
+		HealthCheck: cfg.Database.HealthCheck,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to connect to database")
 	}
+	log.Info().Msg("Database connection established")
 
-	// Shutdown gRPC server
-	grpcServer.GracefulStop()
+	// Initialize read replica (optional; reads fall back to the primary without it)
+	var replicaDB *database.DB
+	if svcCfg.ReadReplicaHost != "" {
+		replicaCfg := database.Config{
+			Host:        svcCfg.ReadReplicaHost,
+			Port:        cfg.Database.Port,
+			User:        cfg.Database.User,
+			Password:    cfg.Database.Password,
+			Database:    cfg.Database.Database,
+			SSLMode:     cfg.Database.SSLMode,
+			MaxConns:    cfg.Database.MaxConns,
+			MinConns:    cfg.Database.MinConns,
+			MaxConnTime: cfg.Database.MaxConnTime,
+			MaxIdleTime: cfg.Database.MaxIdleTime,
+			HealthCheck: cfg.Database.HealthCheck,
+		}
+		if svcCfg.ReadReplicaPort != 0 {
+			replicaCfg.Port = svcCfg.ReadReplicaPort
+		}
+		if svcCfg.ReadReplicaUser != "" {
+			replicaCfg.User = svcCfg.ReadReplicaUser
+			replicaCfg.Password = svcCfg.ReadReplicaPassword
+		}
 
-	log.Info().Msg("Servers stopped")
-}
+		replicaDB, err = database.New(ctx, replicaCfg)
+		if err != nil {
+			log.Warn().Err(err).Str("host", svcCfg.ReadReplicaHost).Msg("Failed to connect to read replica; serving reads from the primary")
+			replicaDB = nil
+		} else {
+			log.Info().Str("host", svcCfg.ReadReplicaHost).Msg("Read replica connection established")
+		}
+	}
+
+	vendorRepo := repository.NewVendorRepository(db,
+		repository.WithQueryTimeouts(repository.QueryTimeouts{
+			Read:  svcCfg.QueryTimeoutRead,
+			Write: svcCfg.QueryTimeoutWrite,
+			Bulk:  svcCfg.QueryTimeoutBulk,
+		}),
+		repository.WithReadReplica(replicaDB),
+		repository.WithSlowQueryLog(&log.Logger, svcCfg.SlowQueryThreshold),
+	)
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		vendorRepo.MonitorReplica(ctx, svcCfg.ReadReplicaHealthInterval, func(healthy bool, err error) {
+			if healthy {
+				log.Info().Msg("Read replica healthy again; routing reads to the replica")
+				return
+			}
+			log.Warn().Err(err).Msg("Read replica unhealthy; routing reads to the primary")
+		})
+	}()
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+	return vendorRepo, db, func() {
+		if replicaDB != nil {
+			replicaDB.Close()
+		}
+		db.Close()
 	}
-	return defaultValue
 }
 
-func getEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		var result int
-		_, err := fmt.Sscanf(value, "%d", &result)
-		if err == nil {
-			return result
-		}
+// debugConfig summarizes the effective configuration for /debug/info with
+// secrets redacted
+func debugConfig(cfg *config.Config, svcCfg *svcconfig.Config) map[string]interface{} {
+	return map[string]interface{}{
+		"environment":    cfg.Service.Environment,
+		"storage":        svcCfg.Storage,
+		"run_migrations": svcCfg.RunMigrations,
+		"database": map[string]interface{}{
+			"host":     cfg.Database.Host,
+			"port":     cfg.Database.Port,
+			"user":     cfg.Database.User,
+			"password": handler.RedactSecret(cfg.Database.Password),
+			"replica": map[string]interface{}{
+				"host":     svcCfg.ReadReplicaHost,
+				"port":     svcCfg.ReadReplicaPort,
+				"user":     svcCfg.ReadReplicaUser,
+				"password": handler.RedactSecret(svcCfg.ReadReplicaPassword),
+			},
+			"database":  cfg.Database.Database,
+			"ssl_mode":  cfg.Database.SSLMode,
+			"max_conns": cfg.Database.MaxConns,
+			"min_conns": cfg.Database.MinConns,
+		},
+		"server": map[string]interface{}{
+			"http_port":       cfg.Server.Port,
+			"grpc_port":       svcCfg.GRPCPort,
+			"admin_http_port": svcCfg.AdminHTTPPort,
+		},
+		"http": map[string]interface{}{
+			"max_request_body_bytes": svcCfg.MaxRequestBodyBytes,
+			"max_upload_body_bytes":  svcCfg.MaxUploadBodyBytes,
+		},
+		"grpc": map[string]interface{}{
+			"max_recv_msg_bytes":              svcCfg.GRPCMaxRecvMsgSize,
+			"max_send_msg_bytes":              svcCfg.GRPCMaxSendMsgSize,
+			"max_concurrent_streams":          svcCfg.GRPCMaxConcurrentStreams,
+			"keepalive_time":                  svcCfg.GRPCKeepaliveTime.String(),
+			"keepalive_timeout":               svcCfg.GRPCKeepaliveTimeout.String(),
+			"keepalive_min_time":              svcCfg.GRPCKeepaliveMinTime.String(),
+			"keepalive_permit_without_stream": svcCfg.GRPCKeepalivePermitWithoutStream,
+		},
+		"tls": map[string]interface{}{
+			"grpc_server_disabled":       svcCfg.GRPCTLSDisabled,
+			"grpc_server_cert_file":      svcCfg.GRPCTLSCertFile,
+			"grpc_server_client_ca_file": svcCfg.GRPCTLSClientCAFile,
+			"identity_disabled":          svcCfg.IdentityTLSDisabled,
+			"identity_ca_file":           svcCfg.IdentityTLSCAFile,
+			"identity_cert_file":         svcCfg.IdentityTLSCertFile,
+			"identity_server_name":       svcCfg.IdentityTLSServerName,
+			"reload_interval":            svcCfg.TLSReloadInterval.String(),
+		},
+		"identity": map[string]interface{}{
+			"call_timeout":      svcCfg.IdentityCallTimeout.String(),
+			"max_retries":       svcCfg.IdentityMaxRetries,
+			"retry_backoff":     svcCfg.IdentityRetryBackoff.String(),
+			"breaker_threshold": svcCfg.IdentityBreakerThreshold,
+			"breaker_cooldown":  svcCfg.IdentityBreakerCooldown.String(),
+			"auth_cache_ttl":    svcCfg.IdentityCacheTTL.String(),
+			"auth_cache_size":   svcCfg.IdentityCacheSize,
+		},
+		"identity_grpc_url":             svcCfg.IdentityGRPCURL,
+		"shutdown_pre_stop_delay":       svcCfg.ShutdownPreStopDelay.String(),
+		"strict_query_params":           svcCfg.StrictQueryParams,
+		"strict_json_bodies":            svcCfg.StrictJSONBodies,
+		"watch_heartbeat_interval":      svcCfg.WatchHeartbeatInterval.String(),
+		"admin_user_ids":                len(svcCfg.AdminUserIDs),
+		"vendor_section_access":         len(svcCfg.VendorSectionAccess),
+		"admin_api_token":               handler.RedactSecret(svcCfg.AdminAPIToken),
+		"retention_deleted_vendor_days": svcCfg.RetentionDeletedVendorDays,
+		"retention_audit_log_days":      svcCfg.RetentionAuditLogDays,
+		"contact_method_rule":           svcCfg.ContactMethodRule,
+		"country_profile_rule":          svcCfg.CountryProfileRule,
+		"strict_address_validation":     svcCfg.StrictAddressValidation,
+		"strict_bank_validation":        svcCfg.StrictBankValidation,
+		"require_bank_verification":     svcCfg.RequireBankVerification,
+		"bank_verification_provider":    svcCfg.BankVerificationURL != "",
+		"blocklist_policy":              svcCfg.BlocklistPolicy,
+		"address_validation_provider":   svcCfg.AddressValidationURL != "",
+		"vendor_quota_default":          svcCfg.VendorQuotaDefault,
+		"vendor_quotas":                 len(svcCfg.VendorQuotas),
+		"create_debounce_window":        svcCfg.CreateDebounceWindow.String(),
+		"bulk_delete_confirmation_ttl":  svcCfg.BulkDeleteConfirmationTTL.String(),
+		"approval_sla":                  svcCfg.ApprovalSLA.String(),
+		"risk_recompute_interval":       svcCfg.RiskRecomputeInterval.String(),
+		"payments": map[string]interface{}{
+			"grpc_url":         svcCfg.PaymentsGRPCURL,
+			"tls_server_name":  svcCfg.PaymentsTLSServerName,
+			"call_timeout":     svcCfg.PaymentsCallTimeout.String(),
+			"spend_cache_ttl":  svcCfg.SpendCacheTTL.String(),
+			"spend_cache_size": svcCfg.SpendCacheSize,
+		},
+		"create_debounce_redis":  svcCfg.RedisURL != "",
+		"cors_allowed_origins":   svcCfg.CORSAllowedOrigins,
+		"cors_allow_credentials": svcCfg.CORSAllowCredentials,
+		"cors_max_age":           svcCfg.CORSMaxAge,
+		"slow_request_threshold": svcCfg.SlowRequestThreshold.String(),
+		"slow_query_threshold":   svcCfg.SlowQueryThreshold.String(),
+		"list_totals": map[string]interface{}{
+			"estimate_above":  svcCfg.ListEstimateTotalAbove,
+			"count_cache_ttl": svcCfg.ListEntityCountCacheTTL.String(),
+		},
+		"vendor_code_reservation_ttl":   svcCfg.VendorCodeReservationTTL.String(),
+		"data_quality_rules":            svcCfg.DataQualityRules,
+		"required_communication_types":  svcCfg.RequiredCommunicationTypes,
+		"currency_change_activity_days": svcCfg.CurrencyChangeActivityDays,
+		"query_timeouts": map[string]interface{}{
+			"read":  svcCfg.QueryTimeoutRead.String(),
+			"write": svcCfg.QueryTimeoutWrite.String(),
+			"bulk":  svcCfg.QueryTimeoutBulk.String(),
+		},
+		"exports": map[string]interface{}{
+			"storage_dir":      svcCfg.ExportStorageDir,
+			"link_signing_key": handler.RedactSecret(svcCfg.ExportLinkSigningKey),
+			"link_ttl":         svcCfg.ExportLinkTTL.String(),
+			"retention":        svcCfg.ExportRetention.String(),
+		},
+		"user_cache": map[string]interface{}{
+			"ttl":  svcCfg.UserCacheTTL.String(),
+			"size": svcCfg.UserCacheSize,
+		},
+		"documents": map[string]interface{}{
+			"storage_url":      svcCfg.DocumentStorageURL,
+			"link_signing_key": handler.RedactSecret(svcCfg.DocumentLinkSigningKey),
+			"link_ttl":         svcCfg.DocumentLinkTTL.String(),
+			"stream_max_bytes": svcCfg.DocumentStreamMaxBytes,
+			"storage_timeout":  svcCfg.DocumentStorageTimeout.String(),
+		},
 	}
-	return defaultValue
 }
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/migrate"
+	"github.com/pesio-ai/be-ap-vendors/migrations"
+	"github.com/pesio-ai/be-lib-common/database"
+	"github.com/pesio-ai/be-lib-common/logger"
+)
+
+// checkSchema applies pending migrations when run is set, and refuses to
+// start when the schema is newer than this binary or marked dirty
+func checkSchema(ctx context.Context, log *logger.Logger, db *database.DB, run bool) {
+	all, err := migrate.Load(migrations.FS)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load embedded migrations")
+	}
+	runner := migrate.NewRunner(db.Pool, all)
+
+	if run {
+		applied, err := runner.Up(ctx, false)
+		for _, m := range applied {
+			log.Info().Str("migration", m.String()).Msg("Migration applied")
+		}
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to apply migrations")
+		}
+	}
+
+	pending, err := runner.Check(ctx)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Incompatible database schema")
+	}
+	version, _, _ := runner.Version(ctx)
+	if len(pending) > 0 {
+		log.Warn().
+			Int("schema_version", version).
+			Int("binary_version", runner.Latest()).
+			Int("pending", len(pending)).
+			Msg("Database schema is behind; run cmd/migrate or set RUN_MIGRATIONS=true")
+		return
+	}
+	log.Info().Int("schema_version", version).Msg("Database schema up to date")
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pesio-ai/be-lib-common/logger"
+	"google.golang.org/grpc"
+)
+
+// backgroundStopTimeout bounds the wait for the background processors once
+// the servers have stopped, however long they took
+const backgroundStopTimeout = 5 * time.Second
+
+// readiness serves /health/ready. It fails as soon as shutdown begins, so
+// that load balancers stop routing new requests while in-flight ones drain,
+// and while the database does not answer.
+type readiness struct {
+	draining atomic.Bool
+	pool     *pgxpool.Pool
+}
+
+func (rd *readiness) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	status, code := "ready", http.StatusOK
+	if rd.draining.Load() {
+		status, code = "draining", http.StatusServiceUnavailable
+	} else if rd.pool != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+		defer cancel()
+		if err := rd.pool.Ping(ctx); err != nil {
+			status, code = "unavailable", http.StatusServiceUnavailable
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"status": status})
+}
+
+// inFlight counts the HTTP requests and RPCs being served by route, so that
+// shutdown can report the ones it had to cancel
+type inFlight struct {
+	mu     sync.Mutex
+	routes map[string]int
+}
+
+func newInFlight() *inFlight {
+	return &inFlight{routes: make(map[string]int)}
+}
+
+// track counts a request to route until the returned func is called
+func (f *inFlight) track(route string) func() {
+	f.mu.Lock()
+	f.routes[route]++
+	f.mu.Unlock()
+
+	return func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if f.routes[route]--; f.routes[route] == 0 {
+			delete(f.routes, route)
+		}
+	}
+}
+
+// snapshot returns the routes still being served with their counts, and
+// their total
+func (f *inFlight) snapshot() (map[string]int, int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	routes := make(map[string]int, len(f.routes))
+	total := 0
+	for route, n := range f.routes {
+		routes[route] = n
+		total += n
+	}
+	return routes, total
+}
+
+// Middleware counts the HTTP requests being served
+func (f *inFlight) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer f.track(r.Method + " " + r.URL.Path)()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// UnaryInterceptor counts the unary RPCs being served
+func (f *inFlight) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		defer f.track(info.FullMethod)()
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor counts the streaming RPCs being served
+func (f *inFlight) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		defer f.track(info.FullMethod)()
+		return handler(srv, ss)
+	}
+}
+
+// trackedHTTPServer is an HTTP server with the requests it serves
+type trackedHTTPServer struct {
+	srv    *http.Server
+	flight *inFlight
+}
+
+// shutdownPlan holds what a graceful shutdown stops, in order: readiness
+// fails first, then after preStopDelay the HTTP and gRPC servers drain
+// concurrently within timeout, then the background processors are cancelled
+// and awaited for up to backgroundStopTimeout, and the database is closed last
+type shutdownPlan struct {
+	log          *logger.Logger
+	ready        *readiness
+	preStopDelay time.Duration
+	timeout      time.Duration
+
+	httpServers []trackedHTTPServer
+	grpcServer  *grpc.Server
+	grpcFlight  *inFlight
+
+	// stopBackground cancels the context of the background processors,
+	// which background waits for
+	stopBackground context.CancelFunc
+	background     *sync.WaitGroup
+	closeDB        func()
+}
+
+// run shuts down after the first signal on quit; a second signal during
+// the pre-stop delay skips the rest of it
+func (p *shutdownPlan) run(quit <-chan os.Signal) {
+	sig := <-quit
+	p.ready.draining.Store(true)
+	p.log.Info().Str("signal", sig.String()).Dur("pre_stop_delay", p.preStopDelay).Msg("Shutting down; readiness now failing")
+
+	if p.preStopDelay > 0 {
+		select {
+		case <-time.After(p.preStopDelay):
+		case <-quit:
+			p.log.Warn().Msg("Second signal received; skipping the rest of the pre-stop delay")
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, srv := range p.httpServers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.shutdownHTTP(ctx, srv.srv, srv.flight)
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		p.shutdownGRPC(ctx)
+	}()
+	wg.Wait()
+	p.log.Info().Msg("Servers stopped")
+
+	p.stopBackground()
+	done := make(chan struct{})
+	go func() {
+		p.background.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		p.log.Info().Msg("Background processors stopped")
+	case <-time.After(backgroundStopTimeout):
+		p.log.Warn().Dur("waited", backgroundStopTimeout).Msg("Background processors did not stop; closing the database anyway")
+	}
+
+	if p.closeDB != nil {
+		p.closeDB()
+	}
+}
+
+// shutdownHTTP drains srv until ctx is done, then closes the connections of
+// the requests still being served and logs them
+func (p *shutdownPlan) shutdownHTTP(ctx context.Context, srv *http.Server, flight *inFlight) {
+	err := srv.Shutdown(ctx)
+	if err == nil {
+		return
+	}
+	if ctx.Err() != nil {
+		routes, total := flight.snapshot()
+		p.log.Warn().
+			Str("addr", srv.Addr).
+			Int("in_flight", total).
+			Interface("routes", routes).
+			Msg("HTTP server shutdown timed out; cancelling in-flight requests")
+		srv.Close()
+		return
+	}
+	p.log.Error().Err(err).Str("addr", srv.Addr).Msg("HTTP server shutdown failed")
+}
+
+// shutdownGRPC stops the gRPC server gracefully until ctx is done, then stops
+// it outright and logs the RPCs still being served
+func (p *shutdownPlan) shutdownGRPC(ctx context.Context) {
+	stopped := make(chan struct{})
+	go func() {
+		p.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		routes, total := p.grpcFlight.snapshot()
+		p.log.Warn().
+			Int("in_flight", total).
+			Interface("routes", routes).
+			Msg("gRPC server shutdown timed out; cancelling in-flight RPCs")
+		p.grpcServer.Stop()
+		<-stopped
+	}
+}
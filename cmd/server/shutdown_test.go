@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pesio-ai/be-lib-common/logger"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+)
+
+// shutdownEvents records the steps of a shutdown in the order they happen
+type shutdownEvents struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (e *shutdownEvents) add(event string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, event)
+}
+
+func (e *shutdownEvents) list() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.events)
+}
+
+// index returns the position of event, failing the test when it never happened
+func (e *shutdownEvents) index(t *testing.T, event string) int {
+	t.Helper()
+	events := e.list()
+	i := slices.Index(events, event)
+	if i < 0 {
+		t.Fatalf("event %q never happened: %v", event, events)
+	}
+	return i
+}
+
+// shutdownFixture is a running HTTP and gRPC server pair with a background
+// processor, and the plan shutting them down
+type shutdownFixture struct {
+	plan    *shutdownPlan
+	http    *httptest.Server
+	events  *shutdownEvents
+	logs    *bytes.Buffer
+	entered chan struct{}
+	release chan struct{}
+}
+
+// newShutdownFixture starts the servers. GET /slow blocks until release is
+// closed or the request is cancelled; /health/ready serves the readiness.
+func newShutdownFixture(t *testing.T, preStopDelay, timeout time.Duration) *shutdownFixture {
+	t.Helper()
+	f := &shutdownFixture{
+		events:  &shutdownEvents{},
+		logs:    &bytes.Buffer{},
+		entered: make(chan struct{}, 1),
+		release: make(chan struct{}),
+	}
+	log := &logger.Logger{Logger: zerolog.New(&syncWriter{w: f.logs})}
+	ready := &readiness{}
+
+	flight := newInFlight()
+	mux := http.NewServeMux()
+	mux.Handle("/health/ready", ready)
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		f.entered <- struct{}{}
+		select {
+		case <-f.release:
+			f.events.add("slow request served")
+		case <-r.Context().Done():
+			f.events.add("slow request cancelled")
+		}
+	})
+	f.http = httptest.NewServer(flight.Middleware(mux))
+	t.Cleanup(f.http.Close)
+	f.http.Config.RegisterOnShutdown(func() { f.events.add("http shutdown started") })
+
+	grpcServer := grpc.NewServer()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go func() {
+		grpcServer.Serve(lis)
+		f.events.add("grpc stopped")
+	}()
+
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	background := &sync.WaitGroup{}
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		<-bgCtx.Done()
+		f.events.add("background stopped")
+	}()
+
+	f.plan = &shutdownPlan{
+		log:            log,
+		ready:          ready,
+		preStopDelay:   preStopDelay,
+		timeout:        timeout,
+		httpServers:    []trackedHTTPServer{{srv: f.http.Config, flight: flight}},
+		grpcServer:     grpcServer,
+		grpcFlight:     newInFlight(),
+		stopBackground: stopBackground,
+		background:     background,
+		closeDB:        func() { f.events.add("database closed") },
+	}
+	return f
+}
+
+// run runs the plan until a signal on the returned channel is handled; the
+// done channel closes once shutdown completes
+func (f *shutdownFixture) run() (chan<- os.Signal, <-chan struct{}) {
+	quit := make(chan os.Signal, 2)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f.plan.run(quit)
+	}()
+	return quit, done
+}
+
+// startSlowRequest sends GET /slow and returns once the handler runs; the
+// returned channel yields the response status, or 0 when the request failed
+func (f *shutdownFixture) startSlowRequest(t *testing.T) <-chan int {
+	t.Helper()
+	status := make(chan int, 1)
+	go func() {
+		resp, err := f.http.Client().Get(f.http.URL + "/slow")
+		if err != nil {
+			status <- 0
+			return
+		}
+		resp.Body.Close()
+		status <- resp.StatusCode
+	}()
+	select {
+	case <-f.entered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the slow request never reached its handler")
+	}
+	return status
+}
+
+// syncWriter serializes the writes of the concurrent shutdown steps
+type syncWriter struct {
+	mu sync.Mutex
+	w  *bytes.Buffer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestShutdownOrdering(t *testing.T) {
+	f := newShutdownFixture(t, 200*time.Millisecond, 5*time.Second)
+	status := f.startSlowRequest(t)
+
+	quit, done := f.run()
+	quit <- syscall.SIGTERM
+
+	// Readiness fails at once, while the servers still serve during the
+	// pre-stop delay
+	waitFor(t, "readiness to fail", f.plan.ready.draining.Load)
+	resp, err := f.http.Client().Get(f.http.URL + "/health/ready")
+	if err != nil {
+		t.Fatalf("GET /health/ready during the pre-stop delay: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("readiness status = %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+	if events := f.events.list(); len(events) != 0 {
+		t.Errorf("events during the pre-stop delay = %v, want none", events)
+	}
+
+	// gRPC stops while HTTP is still draining the in-flight request
+	waitFor(t, "the gRPC server to stop", func() bool { return slices.Contains(f.events.list(), "grpc stopped") })
+	if slices.Contains(f.events.list(), "background stopped") {
+		t.Error("background processors stopped while a request was in flight")
+	}
+
+	close(f.release)
+	if got := <-status; got != http.StatusOK {
+		t.Errorf("in-flight request status = %d, want %d", got, http.StatusOK)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown did not complete")
+	}
+
+	served := f.events.index(t, "slow request served")
+	httpStarted := f.events.index(t, "http shutdown started")
+	grpcStopped := f.events.index(t, "grpc stopped")
+	background := f.events.index(t, "background stopped")
+	database := f.events.index(t, "database closed")
+	if grpcStopped > served {
+		t.Errorf("gRPC stopped after the HTTP drain, want both concurrently: %v", f.events.list())
+	}
+	if httpStarted > served || background < served || background < grpcStopped || database < background {
+		t.Errorf("events = %v, want servers drained, then background stopped, then database closed", f.events.list())
+	}
+
+	if _, err := f.http.Client().Get(f.http.URL + "/health/ready"); err == nil {
+		t.Error("the HTTP server still accepts requests after shutdown")
+	}
+	if strings.Contains(f.logs.String(), "timed out") {
+		t.Errorf("logs report a timeout:\n%s", f.logs)
+	}
+}
+
+func TestShutdownTimeoutCancelsInFlight(t *testing.T) {
+	f := newShutdownFixture(t, 0, 100*time.Millisecond)
+	status := f.startSlowRequest(t)
+
+	quit, done := f.run()
+	quit <- syscall.SIGTERM
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown did not complete")
+	}
+	if got := <-status; got == http.StatusOK {
+		t.Error("the in-flight request was served, want it cancelled")
+	}
+
+	logs := f.logs.String()
+	if !strings.Contains(logs, "HTTP server shutdown timed out") {
+		t.Errorf("logs do not report the cancelled requests:\n%s", logs)
+	}
+	if !strings.Contains(logs, `"in_flight":1`) || !strings.Contains(logs, `"GET /slow":1`) {
+		t.Errorf("logs do not list the cancelled request:\n%s", logs)
+	}
+	if f.events.index(t, "database closed") < f.events.index(t, "background stopped") {
+		t.Errorf("events = %v, want the database closed last", f.events.list())
+	}
+}
+
+func TestShutdownSecondSignalSkipsPreStopDelay(t *testing.T) {
+	f := newShutdownFixture(t, time.Hour, 5*time.Second)
+
+	quit, done := f.run()
+	quit <- syscall.SIGTERM
+	waitFor(t, "readiness to fail", f.plan.ready.draining.Load)
+	quit <- syscall.SIGINT
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("a second signal did not cut the pre-stop delay short")
+	}
+	f.events.index(t, "database closed")
+}
+
+func TestInFlight(t *testing.T) {
+	flight := newInFlight()
+	doneA := flight.track("GET /a")
+	doneA2 := flight.track("GET /a")
+	doneB := flight.track("/ap.VendorService/GetVendor")
+
+	routes, total := flight.snapshot()
+	if total != 3 || routes["GET /a"] != 2 || routes["/ap.VendorService/GetVendor"] != 1 {
+		t.Errorf("snapshot() = %v, %d, want 2 GET /a and 1 GetVendor", routes, total)
+	}
+
+	doneA()
+	doneB()
+	routes, total = flight.snapshot()
+	if total != 1 || len(routes) != 1 || routes["GET /a"] != 1 {
+		t.Errorf("snapshot() = %v, %d, want 1 GET /a", routes, total)
+	}
+
+	doneA2()
+	if routes, total = flight.snapshot(); total != 0 || len(routes) != 0 {
+		t.Errorf("snapshot() = %v, %d, want nothing in flight", routes, total)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/certs"
+	svcconfig "github.com/pesio-ai/be-ap-vendors/internal/config"
+	"github.com/pesio-ai/be-lib-common/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// loadServerTLS loads the key pair of the gRPC server, and the client CAs when
+// mTLS is configured. It returns nil when GRPC_TLS_DISABLED is set.
+func loadServerTLS(svcCfg *svcconfig.Config) (*certs.Source, error) {
+	if svcCfg.GRPCTLSDisabled {
+		return nil, nil
+	}
+	if svcCfg.GRPCTLSCertFile == "" || svcCfg.GRPCTLSKeyFile == "" {
+		return nil, fmt.Errorf("GRPC_TLS_CERT_FILE and GRPC_TLS_KEY_FILE are required unless GRPC_TLS_DISABLED=true")
+	}
+
+	return certs.Load(certs.Config{
+		Name:     "grpc_server",
+		CertFile: svcCfg.GRPCTLSCertFile,
+		KeyFile:  svcCfg.GRPCTLSKeyFile,
+		CAFile:   svcCfg.GRPCTLSClientCAFile,
+	})
+}
+
+// loadIdentityTLS loads the pinned CAs and client key pair used for the
+// identity service. It returns nil when IDENTITY_TLS_DISABLED is set.
+func loadIdentityTLS(svcCfg *svcconfig.Config) (*certs.Source, error) {
+	if svcCfg.IdentityTLSDisabled {
+		return nil, nil
+	}
+
+	return certs.Load(certs.Config{
+		Name:     "identity_client",
+		CertFile: svcCfg.IdentityTLSCertFile,
+		KeyFile:  svcCfg.IdentityTLSKeyFile,
+		CAFile:   svcCfg.IdentityTLSCAFile,
+	})
+}
+
+// clientCredentials returns the transport credentials for a connection to
+// another service: TLS verified against serverName or the host of addr, read
+// from the addrVar setting, or plaintext without a source
+func clientCredentials(source *certs.Source, addr, serverName, addrVar string) (grpc.DialOption, error) {
+	if source == nil {
+		return grpc.WithTransportCredentials(insecure.NewCredentials()), nil
+	}
+
+	if serverName == "" {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, fmt.Errorf("derive TLS server name from %s %q: %w", addrVar, addr, err)
+		}
+		serverName = host
+	}
+
+	return grpc.WithTransportCredentials(credentials.NewTLS(source.ClientTLSConfig(serverName))), nil
+}
+
+// grpcServerCredentials returns the transport credentials of the gRPC server,
+// or no option for plaintext
+func grpcServerCredentials(source *certs.Source) []grpc.ServerOption {
+	if source == nil {
+		return nil
+	}
+	return []grpc.ServerOption{grpc.Creds(credentials.NewTLS(source.ServerTLSConfig()))}
+}
+
+// reloadCertificates reloads sources on SIGHUP and, with a positive
+// TLS_RELOAD_INTERVAL_SECONDS, whenever their files change on disk. A failed
+// reload keeps the previous certificates.
+func reloadCertificates(ctx context.Context, log *logger.Logger, svcCfg *svcconfig.Config, sources ...*certs.Source) {
+	var active []*certs.Source
+	for _, source := range sources {
+		if source != nil {
+			active = append(active, source)
+		}
+	}
+	if len(active) == 0 {
+		return
+	}
+
+	logReload := func(source *certs.Source, err error) {
+		st := source.Status()
+		if err != nil {
+			log.Error().Err(err).Str("certificate", st.Name).Msg("Certificate reload failed; keeping previous certificates")
+			return
+		}
+		log.Info().Str("certificate", st.Name).Time("expires_at", st.Expiry()).Msg("Certificates reloaded")
+	}
+
+	if svcCfg.TLSReloadInterval > 0 {
+		for _, source := range active {
+			source := source
+			go source.Watch(ctx, svcCfg.TLSReloadInterval, func(err error) { logReload(source, err) })
+		}
+	}
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		defer signal.Stop(hup)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				for _, source := range active {
+					logReload(source, source.Reload())
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+
+	pb "github.com/pesio-ai/be-lib-proto/gen/go/ap"
+)
+
+// exportPageSize is the page size used to walk all vendors on export
+const exportPageSize = 200
+
+// command is a vendorctl subcommand
+type command struct {
+	// args is the number of positional arguments
+	args int
+	// outputs are the accepted -o values
+	outputs       []string
+	defaultOutput string
+	// flags registers command specific flags
+	flags func(fs *flag.FlagSet)
+	run   func(c *cli, args []string) error
+}
+
+var tableOrJSON = []string{"table", "json"}
+
+// validateOptions are the flags of the validate command
+var validateOptions struct {
+	currency string
+}
+
+// listOptions are the flags of the list command
+var listOptions struct {
+	status, vendorType string
+	activeOnly         bool
+	page, pageSize     int
+}
+
+var commands = map[string]*command{
+	"get": {
+		args: 1, outputs: tableOrJSON, defaultOutput: "table",
+		run: runGet,
+	},
+	"list": {
+		args: 0, outputs: tableOrJSON, defaultOutput: "table",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&listOptions.status, "status", "", "only vendors with this status")
+			fs.StringVar(&listOptions.vendorType, "type", "", "only vendors of this type")
+			fs.BoolVar(&listOptions.activeOnly, "active-only", false, "only active vendors")
+			fs.IntVar(&listOptions.page, "page", 1, "page number")
+			fs.IntVar(&listOptions.pageSize, "page-size", 50, "vendors per page")
+		},
+		run: runList,
+	},
+	"activate": {
+		args: 1, outputs: tableOrJSON, defaultOutput: "table",
+		run: func(c *cli, args []string) error { return runSetStatus(c, args[0], "active") },
+	},
+	"deactivate": {
+		args: 1, outputs: tableOrJSON, defaultOutput: "table",
+		run: func(c *cli, args []string) error { return runSetStatus(c, args[0], "inactive") },
+	},
+	"suspend": {
+		args: 1, outputs: tableOrJSON, defaultOutput: "table",
+		run: func(c *cli, args []string) error { return runSetStatus(c, args[0], "suspended") },
+	},
+	"validate": {
+		args: 1, outputs: tableOrJSON, defaultOutput: "table",
+		flags: func(fs *flag.FlagSet) {
+			fs.StringVar(&validateOptions.currency, "currency", "", "also require the vendor to accept invoices in this currency")
+		},
+		run: runValidate,
+	},
+	"balance adjust": {
+		args: 2, outputs: tableOrJSON, defaultOutput: "table",
+		run: runBalanceAdjust,
+	},
+	"export": {
+		args: 0, outputs: []string{"csv", "json"}, defaultOutput: "csv",
+		run: runExport,
+	},
+}
+
+func (c *cli) getVendor(id string) (*pb.Vendor, error) {
+	ctx, cancel := c.call()
+	defer cancel()
+	return c.client.GetVendor(ctx, &pb.GetVendorRequest{Id: id, EntityId: c.entity})
+}
+
+func runGet(c *cli, args []string) error {
+	vendor, err := c.getVendor(args[0])
+	if err != nil {
+		return err
+	}
+	return c.printVendor(vendor)
+}
+
+func runList(c *cli, args []string) error {
+	if listOptions.page < 1 || listOptions.pageSize < 1 {
+		return usagef("-page and -page-size must be positive")
+	}
+
+	ctx, cancel := c.call()
+	defer cancel()
+	resp, err := c.client.ListVendors(ctx, &pb.ListVendorsRequest{
+		EntityId:   c.entity,
+		Status:     listOptions.status,
+		VendorType: listOptions.vendorType,
+		ActiveOnly: listOptions.activeOnly,
+		Page:       int32(listOptions.page),
+		PageSize:   int32(listOptions.pageSize),
+	})
+	if err != nil {
+		return err
+	}
+	return c.printVendorList(resp)
+}
+
+// runSetStatus moves a vendor to status through the activate, deactivate or
+// suspend RPC
+func runSetStatus(c *cli, id, status string) error {
+	vendor, err := c.getVendor(id)
+	if err != nil {
+		return err
+	}
+
+	if c.dryRun {
+		return c.printResult("dry run: would change status of %s (%s) from %s to %s", vendor.Id, vendor.VendorCode, vendor.Status, status)
+	}
+	if vendor.Status == status {
+		return c.printResult("vendor %s (%s) is already %s", vendor.Id, vendor.VendorCode, status)
+	}
+
+	ctx, cancel := c.call()
+	defer cancel()
+	switch status {
+	case "active":
+		_, err = c.client.ActivateVendor(ctx, &pb.ActivateVendorRequest{Id: id, EntityId: c.entity})
+	case "inactive":
+		_, err = c.client.DeactivateVendor(ctx, &pb.DeactivateVendorRequest{Id: id, EntityId: c.entity})
+	case "suspended":
+		_, err = c.client.SuspendVendor(ctx, &pb.SuspendVendorRequest{Id: id, EntityId: c.entity})
+	}
+	if err != nil {
+		return err
+	}
+	return c.printResult("vendor %s (%s) changed from %s to %s", vendor.Id, vendor.VendorCode, vendor.Status, status)
+}
+
+func runValidate(c *cli, args []string) error {
+	ctx, cancel := c.call()
+	defer cancel()
+	resp, err := c.client.ValidateVendor(ctx, &pb.ValidateVendorRequest{
+		Id:              args[0],
+		EntityId:        c.entity,
+		InvoiceCurrency: validateOptions.currency,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := c.printValidation(args[0], resp); err != nil {
+		return err
+	}
+	if !resp.Valid {
+		return errInvalid
+	}
+	return nil
+}
+
+func runBalanceAdjust(c *cli, args []string) error {
+	amount, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil || amount == 0 {
+		return usagef("amount must be a non-zero integer in minor currency units, got %q", args[1])
+	}
+
+	vendor, err := c.getVendor(args[0])
+	if err != nil {
+		return err
+	}
+
+	if c.dryRun {
+		return c.printResult("dry run: would adjust balance of %s (%s) by %d: %d -> %d %s",
+			vendor.Id, vendor.VendorCode, amount, vendor.CurrentBalance, vendor.CurrentBalance+amount, vendor.Currency)
+	}
+
+	ctx, cancel := c.call()
+	defer cancel()
+	if _, err := c.client.UpdateBalance(ctx, &pb.UpdateBalanceRequest{Id: vendor.Id, EntityId: c.entity, Amount: amount}); err != nil {
+		return err
+	}
+	return c.printResult("balance of %s (%s) adjusted by %d: %d -> %d %s",
+		vendor.Id, vendor.VendorCode, amount, vendor.CurrentBalance, vendor.CurrentBalance+amount, vendor.Currency)
+}
+
+// runExport walks all vendors of the entity page by page
+func runExport(c *cli, args []string) error {
+	var vendors []*pb.Vendor
+	for page := int32(1); ; page++ {
+		ctx, cancel := c.call()
+		resp, err := c.client.ListVendors(ctx, &pb.ListVendorsRequest{
+			EntityId: c.entity,
+			Page:     page,
+			PageSize: exportPageSize,
+		})
+		cancel()
+		if err != nil {
+			return err
+		}
+
+		vendors = append(vendors, resp.Vendors...)
+		if len(resp.Vendors) < exportPageSize || int64(len(vendors)) >= resp.Total {
+			break
+		}
+	}
+
+	if c.output == "json" {
+		return c.writeJSON(vendorViews(vendors))
+	}
+	return c.writeCSV(vendors)
+}
+
+func (c *cli) printResult(format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	if c.output == "json" {
+		return c.writeJSON(map[string]interface{}{"dry_run": c.dryRun, "message": msg})
+	}
+	_, err := fmt.Fprintln(c.out, msg)
+	return err
+}
@@ -0,0 +1,278 @@
+// vendorctl is an operational CLI for the vendors service speaking its gRPC API.
+//
+// The server address and access token are read from VENDORCTL_ADDR and
+// VENDORCTL_TOKEN, the default entity from VENDORCTL_ENTITY_ID. Exit codes:
+// 0 on success, 1 on API or connection errors, 2 on usage errors and 3 when
+// validate reports the vendor as not usable.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/pesio-ai/be-lib-proto/gen/go/ap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	exitOK      = 0
+	exitAPI     = 1
+	exitUsage   = 2
+	exitInvalid = 3
+)
+
+const usage = `Usage: vendorctl <command> [flags] [args]
+
+Commands:
+  get <vendor-id>                     Show a vendor
+  list                                List vendors (-status, -type, -active-only, -page, -page-size)
+  activate <vendor-id>                Activate a vendor
+  deactivate <vendor-id>              Deactivate a vendor
+  suspend <vendor-id>                 Suspend a vendor
+  validate <vendor-id>                Check that a vendor can be used for invoices (-currency)
+  balance adjust <vendor-id> <amount> Adjust the balance by amount, in minor units (may be negative)
+  export                              Write every vendor of the entity as CSV or JSON
+
+Common flags:
+  -entity      entity ID (default $VENDORCTL_ENTITY_ID)
+  -o           output format: table or json (export: csv or json)
+  -dry-run     show what a mutating command would do without doing it
+  -timeout     per-call timeout (default 30s)
+
+Environment:
+  VENDORCTL_ADDR       gRPC address of the vendors service (default localhost:9086)
+  VENDORCTL_TOKEN      access token sent as "authorization: Bearer <token>"
+  VENDORCTL_CA_FILE    CA bundle trusted for the server (default: system roots)
+  VENDORCTL_CERT_FILE  client certificate for servers requiring mTLS
+  VENDORCTL_KEY_FILE   key of VENDORCTL_CERT_FILE
+  VENDORCTL_PLAINTEXT  set to true for servers running with GRPC_TLS_DISABLED
+`
+
+// usageError is returned for invalid command lines
+type usageError struct{ msg string }
+
+func (e *usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...interface{}) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// errInvalid is returned by validate when the vendor is not usable
+var errInvalid = errors.New("vendor is not valid")
+
+// cli holds the state shared by all commands
+type cli struct {
+	client  pb.VendorsServiceClient
+	out     io.Writer
+	token   string
+	timeout time.Duration
+
+	entity string
+	output string
+	dryRun bool
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Fprint(stderr, usage)
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+
+	name, args := args[0], args[1:]
+	if name == "balance" {
+		if len(args) == 0 || args[0] != "adjust" {
+			fmt.Fprintln(stderr, "vendorctl: expected \"balance adjust <vendor-id> <amount>\"")
+			return exitUsage
+		}
+		name, args = "balance adjust", args[1:]
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "vendorctl: unknown command %q\n\n%s", name, usage)
+		return exitUsage
+	}
+
+	c := &cli{out: stdout, token: os.Getenv("VENDORCTL_TOKEN")}
+	fs := flag.NewFlagSet("vendorctl "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&c.entity, "entity", os.Getenv("VENDORCTL_ENTITY_ID"), "entity ID")
+	fs.StringVar(&c.output, "o", cmd.defaultOutput, "output format")
+	fs.BoolVar(&c.dryRun, "dry-run", false, "show what would be done without doing it")
+	fs.DurationVar(&c.timeout, "timeout", 30*time.Second, "per-call timeout")
+	if cmd.flags != nil {
+		cmd.flags(fs)
+	}
+
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	if c.entity == "" {
+		fmt.Fprintln(stderr, "vendorctl: -entity or VENDORCTL_ENTITY_ID is required")
+		return exitUsage
+	}
+	if !contains(cmd.outputs, c.output) {
+		fmt.Fprintf(stderr, "vendorctl: -o must be one of %s\n", strings.Join(cmd.outputs, ", "))
+		return exitUsage
+	}
+	if len(positional) != cmd.args {
+		fmt.Fprintf(stderr, "vendorctl: %s expects %d argument(s)\n\n%s", name, cmd.args, usage)
+		return exitUsage
+	}
+
+	addr := os.Getenv("VENDORCTL_ADDR")
+	if addr == "" {
+		addr = "localhost:9086"
+	}
+	creds, err := transportCredentials(addr)
+	if err != nil {
+		fmt.Fprintf(stderr, "vendorctl: %v\n", err)
+		return exitUsage
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		fmt.Fprintf(stderr, "vendorctl: connect to %s: %v\n", addr, err)
+		return exitAPI
+	}
+	defer conn.Close()
+	c.client = pb.NewVendorsServiceClient(conn)
+
+	err = cmd.run(c, positional)
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errInvalid):
+		return exitInvalid
+	default:
+		var uerr *usageError
+		if errors.As(err, &uerr) {
+			fmt.Fprintf(stderr, "vendorctl: %v\n", err)
+			return exitUsage
+		}
+		if st, ok := status.FromError(err); ok {
+			fmt.Fprintf(stderr, "vendorctl: %s: %s\n", st.Code(), st.Message())
+		} else {
+			fmt.Fprintf(stderr, "vendorctl: %v\n", err)
+		}
+		return exitAPI
+	}
+}
+
+// parseInterspersed parses flags appearing anywhere in args and returns the
+// positional arguments. Negative numbers are positional, so balance
+// adjustments like -500 need no "--".
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var flags, positional []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			positional = append(positional, args[i+1:]...)
+			i = len(args)
+		case !strings.HasPrefix(arg, "-") || arg == "-" || isNumber(arg):
+			positional = append(positional, arg)
+		default:
+			flags = append(flags, arg)
+			name := strings.TrimLeft(arg, "-")
+			if strings.Contains(name, "=") {
+				continue
+			}
+			// Non-boolean flags take the next argument as their value
+			if f := fs.Lookup(name); f != nil && !isBoolFlag(f) && i+1 < len(args) {
+				i++
+				flags = append(flags, args[i])
+			}
+		}
+	}
+	return positional, fs.Parse(flags)
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseInt(s, 10, 64)
+	return err == nil
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// transportCredentials returns plaintext credentials with VENDORCTL_PLAINTEXT,
+// and TLS credentials for the host of addr otherwise
+func transportCredentials(addr string) (credentials.TransportCredentials, error) {
+	if plaintext, _ := strconv.ParseBool(os.Getenv("VENDORCTL_PLAINTEXT")); plaintext {
+		return insecure.NewCredentials(), nil
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VENDORCTL_ADDR %q: %v", addr, err)
+	}
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: host}
+
+	if caFile := os.Getenv("VENDORCTL_CA_FILE"); caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read VENDORCTL_CA_FILE: %v", err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("VENDORCTL_CA_FILE %s contains no certificates", caFile)
+		}
+	}
+
+	certFile, keyFile := os.Getenv("VENDORCTL_CERT_FILE"), os.Getenv("VENDORCTL_KEY_FILE")
+	if certFile != "" || keyFile != "" {
+		pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load VENDORCTL_CERT_FILE and VENDORCTL_KEY_FILE: %v", err)
+		}
+		cfg.Certificates = []tls.Certificate{pair}
+	}
+
+	return credentials.NewTLS(cfg), nil
+}
+
+// call returns a context for one API call carrying the access token
+func (c *cli) call() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	if c.token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+c.token)
+	}
+	return ctx, cancel
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	pb "github.com/pesio-ai/be-lib-proto/gen/go/ap"
+)
+
+// vendorView is the printed form of a vendor. Bank details are left out so
+// that output can be pasted into tickets.
+type vendorView struct {
+	ID             string            `json:"id"`
+	EntityID       string            `json:"entity_id"`
+	VendorCode     string            `json:"vendor_code"`
+	VendorName     string            `json:"vendor_name"`
+	LegalName      string            `json:"legal_name,omitempty"`
+	DBA            string            `json:"doing_business_as,omitempty"`
+	VendorType     string            `json:"vendor_type"`
+	Status         string            `json:"status"`
+	TaxID          string            `json:"tax_id,omitempty"`
+	Is1099Vendor   bool              `json:"is_1099_vendor"`
+	Email          string            `json:"email,omitempty"`
+	RemitEmail     string            `json:"remittance_email,omitempty"`
+	Phone          string            `json:"phone,omitempty"`
+	City           string            `json:"city,omitempty"`
+	StateProvince  string            `json:"state_province,omitempty"`
+	Country        string            `json:"country"`
+	Locale         string            `json:"locale,omitempty"`
+	PaymentTerms   string            `json:"payment_terms"`
+	PaymentMethod  string            `json:"payment_method,omitempty"`
+	Currency       string            `json:"currency"`
+	Accepted       []string          `json:"accepted_currencies,omitempty"`
+	CreditLimit    int64             `json:"credit_limit,omitempty"`
+	LimitCurrency  string            `json:"credit_limit_currency,omitempty"`
+	CurrentBalance int64             `json:"current_balance"`
+	Tags           []string          `json:"tags,omitempty"`
+	ExternalRefs   map[string]string `json:"external_refs,omitempty"`
+	CreatedAt      string            `json:"created_at,omitempty"`
+	UpdatedAt      string            `json:"updated_at,omitempty"`
+}
+
+func newVendorView(v *pb.Vendor) vendorView {
+	view := vendorView{
+		ID:             v.Id,
+		EntityID:       v.EntityId,
+		VendorCode:     v.VendorCode,
+		VendorName:     v.VendorName,
+		LegalName:      v.LegalName,
+		DBA:            v.DoingBusinessAs,
+		VendorType:     v.VendorType,
+		Status:         v.Status,
+		TaxID:          v.TaxId,
+		Is1099Vendor:   v.Is_1099Vendor,
+		Email:          v.Email,
+		RemitEmail:     v.RemittanceEmail,
+		Phone:          v.Phone,
+		City:           v.City,
+		StateProvince:  v.StateProvince,
+		Country:        v.Country,
+		Locale:         v.Locale,
+		PaymentTerms:   v.PaymentTerms,
+		PaymentMethod:  v.PaymentMethod,
+		Currency:       v.Currency,
+		Accepted:       v.AcceptedCurrencies,
+		CreditLimit:    v.CreditLimit,
+		LimitCurrency:  v.CreditLimitCurrency,
+		CurrentBalance: v.CurrentBalance,
+		Tags:           v.Tags,
+		ExternalRefs:   v.ExternalRefs,
+	}
+	if view.LimitCurrency == "" && view.CreditLimit > 0 {
+		view.LimitCurrency = view.Currency
+	}
+	if v.CreatedAt != nil {
+		view.CreatedAt = v.CreatedAt.AsTime().Format(time.RFC3339)
+	}
+	if v.UpdatedAt != nil {
+		view.UpdatedAt = v.UpdatedAt.AsTime().Format(time.RFC3339)
+	}
+	return view
+}
+
+func vendorViews(vendors []*pb.Vendor) []vendorView {
+	views := make([]vendorView, len(vendors))
+	for i, v := range vendors {
+		views[i] = newVendorView(v)
+	}
+	return views
+}
+
+func (c *cli) writeJSON(v interface{}) error {
+	enc := json.NewEncoder(c.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (c *cli) printVendor(v *pb.Vendor) error {
+	view := newVendorView(v)
+	if c.output == "json" {
+		return c.writeJSON(view)
+	}
+
+	tw := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	rows := [][2]string{
+		{"ID", view.ID},
+		{"Code", view.VendorCode},
+		{"Name", view.VendorName},
+		{"Legal name", view.LegalName},
+		{"DBA", view.DBA},
+		{"Type", view.VendorType},
+		{"Status", view.Status},
+		{"Tax ID", view.TaxID},
+		{"1099", strconv.FormatBool(view.Is1099Vendor)},
+		{"Email", view.Email},
+		{"Remittance email", view.RemitEmail},
+		{"Phone", view.Phone},
+		{"Location", strings.Trim(strings.Join([]string{view.City, view.StateProvince, view.Country}, ", "), ", ")},
+		{"Locale", view.Locale},
+		{"Payment terms", view.PaymentTerms},
+		{"Payment method", view.PaymentMethod},
+		{"Credit limit", formatLimit(view.CreditLimit, view.LimitCurrency)},
+		{"Balance", fmt.Sprintf("%d %s", view.CurrentBalance, view.Currency)},
+		{"Accepted currencies", strings.Join(view.Accepted, ", ")},
+		{"Tags", strings.Join(view.Tags, ", ")},
+		{"Updated", view.UpdatedAt},
+	}
+	for system, externalID := range view.ExternalRefs {
+		rows = append(rows, [2]string{"Ref " + system, externalID})
+	}
+	for _, row := range rows {
+		fmt.Fprintf(tw, "%s:\t%s\n", row[0], row[1])
+	}
+	return tw.Flush()
+}
+
+func (c *cli) printVendorList(resp *pb.ListVendorsResponse) error {
+	if c.output == "json" {
+		return c.writeJSON(map[string]interface{}{
+			"vendors":   vendorViews(resp.Vendors),
+			"total":     resp.Total,
+			"page":      resp.Page,
+			"page_size": resp.PageSize,
+		})
+	}
+
+	tw := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tCODE\tNAME\tTYPE\tSTATUS\tBALANCE\tCREDIT LIMIT")
+	for _, v := range resp.Vendors {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			v.Id, v.VendorCode, v.VendorName, v.VendorType, v.Status, v.CurrentBalance, formatLimit(v.CreditLimit, ""))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(c.out, "\npage %d, %d of %d vendors\n", resp.Page, len(resp.Vendors), resp.Total)
+	return err
+}
+
+func (c *cli) printValidation(id string, resp *pb.ValidateVendorResponse) error {
+	if c.output == "json" {
+		return c.writeJSON(map[string]interface{}{"id": id, "valid": resp.Valid, "message": resp.Message, "warnings": resp.Warnings})
+	}
+	var err error
+	if resp.Valid {
+		_, err = fmt.Fprintf(c.out, "vendor %s is valid\n", id)
+	} else {
+		_, err = fmt.Fprintf(c.out, "vendor %s is not valid: %s\n", id, resp.Message)
+	}
+	for _, warning := range resp.Warnings {
+		if err != nil {
+			break
+		}
+		_, err = fmt.Fprintf(c.out, "warning: %s\n", warning)
+	}
+	return err
+}
+
+// csvHeader lists the exported columns
+var csvHeader = []string{
+	"id", "vendor_code", "vendor_name", "legal_name", "vendor_type", "status", "tax_id", "is_1099_vendor",
+	"email", "phone", "city", "state_province", "country", "payment_terms", "payment_method", "currency",
+	"credit_limit", "current_balance", "tags",
+}
+
+func (c *cli) writeCSV(vendors []*pb.Vendor) error {
+	w := csv.NewWriter(c.out)
+	if err := w.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, v := range vendors {
+		creditLimit := ""
+		if v.CreditLimit > 0 {
+			creditLimit = strconv.FormatInt(v.CreditLimit, 10)
+		}
+		if err := w.Write([]string{
+			v.Id, v.VendorCode, v.VendorName, v.LegalName, v.VendorType, v.Status, v.TaxId,
+			strconv.FormatBool(v.Is_1099Vendor), v.Email, v.Phone, v.City, v.StateProvince, v.Country,
+			v.PaymentTerms, v.PaymentMethod, v.Currency, creditLimit,
+			strconv.FormatInt(v.CurrentBalance, 10), strings.Join(v.Tags, ";"),
+		}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func formatLimit(limit int64, currency string) string {
+	if limit <= 0 {
+		return "-"
+	}
+	return strings.TrimSpace(fmt.Sprintf("%d %s", limit, currency))
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pesio-ai/be-lib-common/health"
+)
+
+// healthMux serves the worker's health checks and metrics:
+//
+//	/health        liveness
+//	/health/ready  readiness: the database answers; reports whether this replica leads
+//	/metrics       expvar variables, including the job metrics
+func healthMux(serviceName, version string, pool *pgxpool.Pool) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("/health", health.NewHandler(serviceName, version))
+	mux.HandleFunc("/health/ready", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+		defer cancel()
+
+		status, code := "ready", http.StatusOK
+		if err := pool.Ping(ctx); err != nil {
+			status, code = "unavailable", http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": status,
+			"leader": leading.Value() == 1,
+		})
+	})
+	mux.Handle("/metrics", expvar.Handler())
+	return mux
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"expvar"
+	"time"
+
+	"github.com/pesio-ai/be-lib-common/logger"
+)
+
+// Job metrics, served on the worker's /metrics with the other expvar variables
+var (
+	jobRuns         = expvar.NewMap("worker_job_runs")
+	jobFailures     = expvar.NewMap("worker_job_failures")
+	jobLastSuccess  = expvar.NewMap("worker_job_last_success_unix")
+	jobLastDuration = expvar.NewMap("worker_job_last_duration_ms")
+	leading         = expvar.NewInt("worker_leader")
+	// balanceDrift is the number of vendors whose current_balance differed
+	// from their balance ledger in the last balance_reconcile run
+	balanceDrift = expvar.NewInt("vendor_balance_drift")
+)
+
+// job is a background job run by the leading worker
+type job struct {
+	name string
+	// interval is how often the job runs; 0 disables it
+	interval time.Duration
+	// atStart runs the job as soon as the worker leads instead of after the
+	// first interval
+	atStart bool
+	run     func(ctx context.Context) error
+}
+
+// enabledJobs returns the jobs with an interval
+func enabledJobs(jobs []job) []job {
+	var enabled []job
+	for _, j := range jobs {
+		if j.interval > 0 {
+			enabled = append(enabled, j)
+		}
+	}
+	return enabled
+}
+
+// runJobs runs jobs one at a time, each every interval, until stop is done or
+// ctx is canceled. A job running when stop is done finishes, under ctx.
+func runJobs(ctx, stop context.Context, log *logger.Logger, jobs []job) {
+	if len(jobs) == 0 {
+		select {
+		case <-ctx.Done():
+		case <-stop.Done():
+		}
+		return
+	}
+
+	now := time.Now()
+	next := make([]time.Time, len(jobs))
+	for i, j := range jobs {
+		next[i] = now.Add(j.interval)
+		if j.atStart {
+			next[i] = now
+		}
+	}
+
+	for {
+		due := 0
+		for i := range jobs {
+			if next[i].Before(next[due]) {
+				due = i
+			}
+		}
+
+		timer := time.NewTimer(time.Until(next[due]))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-stop.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		runJob(ctx, log, jobs[due])
+
+		// Runs missed while a job was running are skipped, as with a ticker
+		now := time.Now()
+		for !next[due].After(now) {
+			next[due] = next[due].Add(jobs[due].interval)
+		}
+	}
+}
+
+// runJob runs a job and records its metrics
+func runJob(ctx context.Context, log *logger.Logger, j job) {
+	started := time.Now()
+	err := j.run(ctx)
+
+	jobRuns.Add(j.name, 1)
+	if err != nil {
+		jobFailures.Add(j.name, 1)
+		log.Error().Err(err).Str("job", j.name).Msg("Worker job failed")
+		return
+	}
+	last := new(expvar.Int)
+	last.Set(time.Now().Unix())
+	jobLastSuccess.Set(j.name, last)
+	duration := new(expvar.Int)
+	duration.Set(time.Since(started).Milliseconds())
+	jobLastDuration.Set(j.name, duration)
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	svcconfig "github.com/pesio-ai/be-ap-vendors/internal/config"
+	"github.com/pesio-ai/be-ap-vendors/internal/entityevents"
+	"github.com/pesio-ai/be-ap-vendors/internal/filestore"
+	"github.com/pesio-ai/be-ap-vendors/internal/fx"
+	"github.com/pesio-ai/be-ap-vendors/internal/leader"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+	"github.com/pesio-ai/be-ap-vendors/internal/tinmatch"
+	"github.com/pesio-ai/be-ap-vendors/internal/utctime"
+	"github.com/pesio-ai/be-lib-common/config"
+	"github.com/pesio-ai/be-lib-common/database"
+	"github.com/pesio-ai/be-lib-common/logger"
+)
+
+// leaderLockID is the advisory lock electing the replica that runs the jobs
+const leaderLockID = 7394561209
+
+// The worker runs scheduled background jobs of the vendors service
+func main() {
+	utctime.UseUTC()
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		os.Exit(1)
+	}
+	svcCfg := svcconfig.Load()
+
+	// Initialize logger
+	log := logger.New(logger.Config{
+		Level:       os.Getenv("LOG_LEVEL"),
+		Environment: cfg.Service.Environment,
+		ServiceName: cfg.Service.Name + "-worker",
+		Version:     cfg.Service.Version,
+	})
+
+	log.Info().
+		Str("service", cfg.Service.Name).
+		Str("version", cfg.Service.Version).
+		Dur("purge_interval", svcCfg.PurgeInterval).
+		Bool("purge_dry_run", svcCfg.PurgeDryRun).
+		Dur("approval_sla", svcCfg.ApprovalSLA).
+		Dur("risk_recompute_interval", svcCfg.RiskRecomputeInterval).
+		Msg("Starting Vendors Worker (AP-1)")
+
+	// Cancel on shutdown signals; a job already running gets the shutdown
+	// timeout to finish
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	// Initialize database
+	db, err := database.New(ctx, database.Config{
+		Host:        cfg.Database.Host,
+		Port:        cfg.Database.Port,
+		User:        cfg.Database.User,
+		Password:    cfg.Database.Password,
+		Database:    cfg.Database.Database,
+		SSLMode:     cfg.Database.SSLMode,
+		MaxConns:    cfg.Database.MaxConns,
+		MinConns:    cfg.Database.MinConns,
+		MaxConnTime: cfg.Database.MaxConnTime,
+		MaxIdleTime: cfg.Database.MaxIdleTime,
+		HealthCheck: cfg.Database.HealthCheck,
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to connect to database")
+	}
+	defer db.Close()
+	log.Info().Msg("Database connection established")
+
+	vendorRepo := repository.NewVendorRepository(db,
+		repository.WithQueryTimeouts(repository.QueryTimeouts{
+			Read:  svcCfg.QueryTimeoutRead,
+			Write: svcCfg.QueryTimeoutWrite,
+			Bulk:  svcCfg.QueryTimeoutBulk,
+		}),
+		repository.WithSlowQueryLog(&log.Logger, svcCfg.SlowQueryThreshold),
+	)
+	if !service.IsValidContactMethodRule(svcCfg.ContactMethodRule) {
+		log.Fatal().Str("contact_method_rule", svcCfg.ContactMethodRule).Msg("Invalid CONTACT_METHOD_RULE (expected off, warn or enforce)")
+	}
+	if svcCfg.DormantAfterMonths < 1 {
+		log.Fatal().Int("dormant_after_months", svcCfg.DormantAfterMonths).Msg("DORMANT_AFTER_MONTHS must be positive")
+	}
+	// Without a provider there is nothing to verify TINs with
+	tinMatchInterval := svcCfg.TINMatchInterval
+	var tinMatcher tinmatch.Matcher = tinmatch.Stub{}
+	if svcCfg.TINMatchURL != "" {
+		tinMatcher = tinmatch.NewProviderMatcher(svcCfg.TINMatchURL, svcCfg.TINMatchAPIKey,
+			svcCfg.TINMatchBatchSize, svcCfg.TINMatchCallsPerMinute, svcCfg.TINMatchTimeout)
+	} else {
+		tinMatchInterval = 0
+	}
+	// Without a feed there are no entity lifecycle events to consume
+	entityEventsInterval := svcCfg.EntityEventsPollInterval
+	var entityEvents entityevents.Source
+	if svcCfg.EntityEventsURL != "" {
+		entityEvents = entityevents.NewHTTPSource(svcCfg.EntityEventsURL, svcCfg.EntityEventsAPIKey,
+			svcCfg.EntityEventsBatchSize, svcCfg.EntityEventsTimeout)
+	} else {
+		entityEventsInterval = 0
+	}
+	// Without a directory shared with the server there is nowhere to write exports
+	vendorExportInterval := svcCfg.VendorExportPollInterval
+	var exportFiles filestore.Store
+	if svcCfg.ExportStorageDir != "" {
+		if exportFiles, err = filestore.NewDir(svcCfg.ExportStorageDir); err != nil {
+			log.Fatal().Err(err).Str("export_storage_dir", svcCfg.ExportStorageDir).Msg("Invalid EXPORT_STORAGE_DIR")
+		}
+	} else {
+		vendorExportInterval = 0
+	}
+	// No exchange rates are configured, so foreign credit limits stay uncompared
+	var fxConverter fx.Converter = fx.Stub{}
+	vendorService := service.NewVendorService(vendorRepo, log,
+		service.WithContactMethodRule(svcCfg.ContactMethodRule),
+		service.WithApprovalSLA(svcCfg.ApprovalSLA),
+		service.WithDormancy(svcCfg.DormantAfterMonths, svcCfg.DormancyEvents),
+		service.WithTINMatcher(tinMatcher),
+		service.WithFXConverter(fxConverter),
+		service.WithVendorExports(exportFiles, []byte(svcCfg.ExportLinkSigningKey), svcCfg.ExportLinkTTL, svcCfg.ExportRetention),
+	)
+
+	purgeOpts := service.PurgeOptions{
+		Defaults: service.RetentionPolicy{
+			DeletedVendorDays: svcCfg.RetentionDeletedVendorDays,
+			AuditLogDays:      svcCfg.RetentionAuditLogDays,
+		},
+		BatchSize: svcCfg.PurgeBatchSize,
+		DryRun:    svcCfg.PurgeDryRun,
+	}
+
+	// Without an SLA there is nothing to check
+	slaCheckInterval := svcCfg.ApprovalSLACheckInterval
+	if svcCfg.ApprovalSLA <= 0 {
+		slaCheckInterval = 0
+	}
+
+	// Every job is disabled by a zero interval
+	jobs := []job{
+		{
+			name:     "purge",
+			interval: svcCfg.PurgeInterval,
+			atStart:  true,
+			run: func(ctx context.Context) error {
+				started := time.Now()
+				report, err := vendorService.Purge(ctx, purgeOpts)
+				if err != nil {
+					return err
+				}
+				log.Info().
+					Interface("report", report).
+					Dur("duration", time.Since(started)).
+					Msg("Purge run finished")
+				return nil
+			},
+		},
+		{
+			name:     "approval_sla",
+			interval: slaCheckInterval,
+			atStart:  true,
+			run: func(ctx context.Context) error {
+				breaches, err := vendorService.CheckApprovalSLA(ctx)
+				if err != nil {
+					return err
+				}
+				log.Debug().Int("breaches", breaches).Msg("Approval SLA check finished")
+				return nil
+			},
+		},
+		{
+			name:     "risk_recompute",
+			interval: svcCfg.RiskRecomputeInterval,
+			run: func(ctx context.Context) error {
+				started := time.Now()
+				report, err := vendorService.RecomputeRiskScores(ctx, "")
+				if err != nil {
+					return err
+				}
+				log.Info().
+					Interface("report", report).
+					Dur("duration", time.Since(started)).
+					Msg("Risk score recompute finished")
+				return nil
+			},
+		},
+		{
+			name:     "status_schedule",
+			interval: svcCfg.StatusScheduleInterval,
+			atStart:  true,
+			run: func(ctx context.Context) error {
+				report, err := vendorService.ApplyDueStatusChanges(ctx)
+				if err != nil {
+					return err
+				}
+				log.Info().
+					Int("applied", report.Applied).
+					Int("failed", report.Failed).
+					Msg("Scheduled status changes finished")
+				return nil
+			},
+		},
+		{
+			name:     "dormancy",
+			interval: svcCfg.DormancyCheckInterval,
+			run: func(ctx context.Context) error {
+				started := time.Now()
+				report, err := vendorService.DetectDormantVendors(ctx, "")
+				if err != nil {
+					return err
+				}
+				log.Info().
+					Interface("report", report).
+					Dur("duration", time.Since(started)).
+					Msg("Dormant vendor detection finished")
+				return nil
+			},
+		},
+		{
+			name:     "balance_reconcile",
+			interval: svcCfg.BalanceReconcileInterval,
+			run: func(ctx context.Context) error {
+				report, err := vendorService.ReconcileBalances(ctx, "", false)
+				if err != nil {
+					return err
+				}
+				balanceDrift.Set(int64(len(report.Discrepancies)))
+				return nil
+			},
+		},
+		{
+			name:     "tin_matching",
+			interval: tinMatchInterval,
+			run: func(ctx context.Context) error {
+				started := time.Now()
+				report, err := vendorService.RunTINMatching(ctx, "")
+				if err != nil {
+					return err
+				}
+				log.Info().
+					Interface("report", report).
+					Dur("duration", time.Since(started)).
+					Msg("TIN matching finished")
+				return nil
+			},
+		},
+		{
+			name:     "entity_events",
+			interval: entityEventsInterval,
+			atStart:  true,
+			run: func(ctx context.Context) error {
+				applied, err := vendorService.ConsumeEntityEvents(ctx, entityEvents)
+				if err != nil {
+					return err
+				}
+				if applied > 0 {
+					log.Info().Int("applied", applied).Msg("Entity lifecycle events consumed")
+				}
+				return nil
+			},
+		},
+		{
+			name:     "code_reservations",
+			interval: svcCfg.CodeReservationSweepInterval,
+			run: func(ctx context.Context) error {
+				_, err := vendorService.ReleaseExpiredCodeReservations(ctx)
+				return err
+			},
+		},
+		{
+			name:     "vendor_exports",
+			interval: vendorExportInterval,
+			atStart:  true,
+			run: func(ctx context.Context) error {
+				finished, err := vendorService.RunVendorExports(ctx)
+				if err != nil {
+					return err
+				}
+				if finished > 0 {
+					log.Info().Int("finished", finished).Msg("Vendor exports generated")
+				}
+				return nil
+			},
+		},
+	}
+	jobs = enabledJobs(jobs)
+	jobNames := make([]string, len(jobs))
+	for i, j := range jobs {
+		jobNames[i] = j.name
+	}
+	log.Info().Strs("jobs", jobNames).Msg("Worker jobs enabled")
+
+	// Health and metrics listener
+	var httpServer *http.Server
+	if svcCfg.WorkerHTTPPort > 0 {
+		httpServer = &http.Server{
+			Addr:         fmt.Sprintf(":%d", svcCfg.WorkerHTTPPort),
+			Handler:      healthMux(cfg.Service.Name+"-worker", cfg.Service.Version, db.Pool),
+			ReadTimeout:  cfg.Server.ReadTimeout,
+			WriteTimeout: cfg.Server.WriteTimeout,
+			IdleTimeout:  cfg.Server.IdleTimeout,
+		}
+		go func() {
+			log.Info().Int("port", svcCfg.WorkerHTTPPort).Msg("Starting worker HTTP server")
+			if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Error().Err(err).Msg("Worker HTTP server failed")
+			}
+		}()
+	}
+
+	// Only the replica holding the leadership lock runs jobs; the others
+	// stand by and take over once it stops or loses its database session
+	elector := leader.New(db.Pool, leaderLockID, svcCfg.WorkerLeaderCheckInterval)
+	for ctx.Err() == nil {
+		lease, err := elector.TryAcquire(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("Leader election failed")
+		}
+		if lease != nil {
+			log.Info().Msg("Worker leading; running jobs")
+			leading.Set(1)
+			lead(ctx, lease, log, jobs, cfg.Server.ShutdownTimeout)
+			leading.Set(0)
+			if ctx.Err() == nil {
+				log.Warn().Err(context.Cause(lease.Context())).Msg("Worker no longer leading; standing by")
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(svcCfg.WorkerLeaderCheckInterval):
+		}
+	}
+
+	if httpServer != nil {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer shutdownCancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			log.Error().Err(err).Msg("Worker HTTP server shutdown failed")
+		}
+	}
+	log.Info().Msg("Worker stopped")
+}
+
+// lead runs jobs while lease is held, until stop is done. The job running at
+// shutdown gets up to shutdownTimeout to finish; losing the lease cancels it
+// as soon as the lease notices.
+func lead(stop context.Context, lease *leader.Lease, log *logger.Logger, jobs []job, shutdownTimeout time.Duration) {
+	defer lease.Release()
+
+	ctx, cancel := context.WithCancel(lease.Context())
+	defer cancel()
+	afterStop := context.AfterFunc(stop, func() {
+		time.AfterFunc(shutdownTimeout, cancel)
+	})
+	defer afterStop()
+
+	runJobs(ctx, stop, log, jobs)
+}
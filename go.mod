@@ -6,6 +6,9 @@ require (
 	github.com/jackc/pgx/v5 v5.8.0
 	github.com/pesio-ai/be-lib-common v0.0.0-00010101000000-000000000000
 	github.com/pesio-ai/be-lib-proto v0.0.0-20260124164652-9c290ae7759a
+	github.com/rs/zerolog v1.34.0
+	golang.org/x/text v0.31.0
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20251029180050-ab9386a59fda
 	google.golang.org/grpc v1.78.0
 	google.golang.org/protobuf v1.36.11
 )
@@ -17,12 +20,9 @@ require (
 	github.com/jackc/puddle/v2 v2.2.2 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.19 // indirect
-	github.com/rs/zerolog v1.34.0 // indirect
 	golang.org/x/net v0.47.0 // indirect
 	golang.org/x/sync v0.18.0 // indirect
 	golang.org/x/sys v0.38.0 // indirect
-	golang.org/x/text v0.31.0 // indirect
-	google.golang.org/genproto/googleapis/rpc v0.0.0-20251029180050-ab9386a59fda // indirect
 )
 
 replace github.com/pesio-ai/be-lib-proto => ../be-lib-proto
@@ -0,0 +1,148 @@
+// Package address validates vendor mailing addresses. Check applies the
+// built-in postal code and state/province rules; a Validator adds checks by an
+// address verification provider.
+package address
+
+import (
+	"bufio"
+	"context"
+	"embed"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Address is a vendor mailing address
+type Address struct {
+	Line1         string `json:"address_line1"`
+	Line2         string `json:"address_line2"`
+	City          string `json:"city"`
+	StateProvince string `json:"state_province"`
+	PostalCode    string `json:"postal_code"`
+	// Country is the ISO 3166-1 alpha-2 code
+	Country string `json:"country"`
+}
+
+// IsEmpty reports whether no address field is set besides the country
+func (a Address) IsEmpty() bool {
+	return a.Line1 == "" && a.Line2 == "" && a.City == "" && a.StateProvince == "" && a.PostalCode == ""
+}
+
+// Issue is a problem found with one field of an address
+type Issue struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Validator verifies addresses beyond the built-in format checks
+type Validator interface {
+	// Validate returns the problems found with addr. An error means the address
+	// could not be verified, not that it is invalid.
+	Validate(ctx context.Context, addr Address) ([]Issue, error)
+}
+
+// Noop is the default Validator, finding no problems
+type Noop struct{}
+
+// Validate returns no issues
+func (Noop) Validate(ctx context.Context, addr Address) ([]Issue, error) {
+	return nil, nil
+}
+
+// postalCodes are the postal code formats of countries with a known format,
+// matched against the upper-cased code
+var postalCodes = map[string]struct {
+	pattern *regexp.Regexp
+	example string
+}{
+	"US": {regexp.MustCompile(`^\d{5}(-\d{4})?$`), "12345 or 12345-6789"},
+	"CA": {regexp.MustCompile(`^[ABCEGHJ-NPRSTVXY]\d[ABCEGHJ-NPRSTV-Z] ?\d[ABCEGHJ-NPRSTV-Z]\d$`), "A1A 1A1"},
+	"GB": {regexp.MustCompile(`^(GIR ?0AA|[A-PR-UWYZ]([0-9]{1,2}|[A-HK-Y][0-9][0-9ABEHMNPRV-Y]?|[0-9][A-HJKPS-UW]) ?[0-9][ABD-HJLNP-UW-Z]{2})$`), "SW1A 1AA"},
+	"DE": {regexp.MustCompile(`^\d{5}$`), "12345"},
+	"FR": {regexp.MustCompile(`^\d{5}$`), "75008"},
+	"IT": {regexp.MustCompile(`^\d{5}$`), "00144"},
+	"ES": {regexp.MustCompile(`^\d{5}$`), "28013"},
+	"MX": {regexp.MustCompile(`^\d{5}$`), "06600"},
+	"NL": {regexp.MustCompile(`^\d{4} ?[A-Z]{2}$`), "1012 AB"},
+	"BE": {regexp.MustCompile(`^\d{4}$`), "1000"},
+	"CH": {regexp.MustCompile(`^\d{4}$`), "8001"},
+	"AT": {regexp.MustCompile(`^\d{4}$`), "1010"},
+	"AU": {regexp.MustCompile(`^\d{4}$`), "2000"},
+	"SE": {regexp.MustCompile(`^\d{3} ?\d{2}$`), "114 55"},
+	"IN": {regexp.MustCompile(`^\d{6}$`), "110001"},
+	"JP": {regexp.MustCompile(`^\d{3}-?\d{4}$`), "100-0001"},
+	"BR": {regexp.MustCompile(`^\d{5}-?\d{3}$`), "01310-100"},
+}
+
+//go:embed us_states.txt ca_provinces.txt
+var regionFiles embed.FS
+
+// regions maps the countries whose states or provinces are checked to their
+// accepted codes and names, upper-cased, and the code each stands for
+var regions = map[string]map[string]string{
+	"US": loadRegions("us_states.txt"),
+	"CA": loadRegions("ca_provinces.txt"),
+}
+
+// loadRegions reads a list of "CODE Name" lines
+func loadRegions(name string) map[string]string {
+	data, err := regionFiles.ReadFile(name)
+	if err != nil {
+		panic(fmt.Sprintf("address: read %s: %v", name, err))
+	}
+
+	known := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		code, regionName, _ := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		if code == "" {
+			continue
+		}
+		known[strings.ToUpper(code)] = code
+		known[strings.ToUpper(regionName)] = code
+	}
+	return known
+}
+
+// NormalizeCountry returns the canonical form of an ISO country code: trimmed
+// and upper-cased
+func NormalizeCountry(country string) string {
+	return strings.ToUpper(strings.TrimSpace(country))
+}
+
+// NormalizeRegion returns the canonical form of a state or province. US states
+// and Canadian provinces given by code or name become their two-letter code;
+// unknown ones and the regions of other countries are only trimmed, with runs
+// of spaces collapsed.
+func NormalizeRegion(country, region string) string {
+	region = strings.Join(strings.Fields(region), " ")
+	if code, ok := regions[NormalizeCountry(country)][strings.ToUpper(region)]; ok {
+		return code
+	}
+	return region
+}
+
+// Check applies the built-in rules: the postal code format of the country and,
+// for the US and Canada, the state or province. Empty fields are not checked.
+func Check(addr Address) []Issue {
+	var issues []Issue
+	country := NormalizeCountry(addr.Country)
+
+	postalCode := strings.ToUpper(strings.TrimSpace(addr.PostalCode))
+	if format, ok := postalCodes[country]; ok && postalCode != "" && !format.pattern.MatchString(postalCode) {
+		issues = append(issues, Issue{
+			Field:   "postal_code",
+			Message: fmt.Sprintf("postal code %q is not valid for %s (expected e.g. %s)", addr.PostalCode, country, format.example),
+		})
+	}
+
+	state := strings.ToUpper(strings.TrimSpace(addr.StateProvince))
+	if known, ok := regions[country]; ok && state != "" && known[state] == "" {
+		issues = append(issues, Issue{
+			Field:   "state_province",
+			Message: fmt.Sprintf("%q is not a state or province of %s", addr.StateProvince, country),
+		})
+	}
+
+	return issues
+}
@@ -0,0 +1,73 @@
+package address
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ProviderValidator verifies addresses with an address verification provider
+// over HTTP. The address is POSTed as JSON and the provider answers with
+//
+//	{"valid": false, "issues": [{"field": "postal_code", "message": "..."}]}
+type ProviderValidator struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+// NewProviderValidator creates a validator calling url, sending apiKey as a
+// bearer token when set. Every call is bounded by timeout.
+func NewProviderValidator(url, apiKey string, timeout time.Duration) *ProviderValidator {
+	return &ProviderValidator{
+		url:    url,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+type providerResponse struct {
+	Valid  bool    `json:"valid"`
+	Issues []Issue `json:"issues"`
+}
+
+// Validate asks the provider to verify addr
+func (p *ProviderValidator) Validate(ctx context.Context, addr Address) ([]Issue, error) {
+	body, err := json.Marshal(addr)
+	if err != nil {
+		return nil, fmt.Errorf("encode address: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("build address validation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("call address validation provider: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("address validation provider returned %s", resp.Status)
+	}
+
+	var result providerResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode address validation response: %w", err)
+	}
+	if !result.Valid && len(result.Issues) == 0 {
+		return []Issue{{Field: "address_line1", Message: "address could not be verified"}}, nil
+	}
+	return result.Issues, nil
+}
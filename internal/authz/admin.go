@@ -0,0 +1,31 @@
+// Package authz holds authorization policies that go beyond the entity scoping
+// enforced by the auth interceptor.
+package authz
+
+import "strings"
+
+// AdminPolicy decides which authenticated users may call admin-only operations.
+// Admins are configured as a static allowlist of identity user IDs.
+type AdminPolicy struct {
+	userIDs map[string]struct{}
+}
+
+// NewAdminPolicy creates an admin policy from a list of user IDs
+func NewAdminPolicy(userIDs []string) *AdminPolicy {
+	p := &AdminPolicy{userIDs: make(map[string]struct{})}
+	for _, id := range userIDs {
+		if id = strings.TrimSpace(id); id != "" {
+			p.userIDs[id] = struct{}{}
+		}
+	}
+	return p
+}
+
+// IsAdmin reports whether the user is an admin. A nil policy has no admins.
+func (p *AdminPolicy) IsAdmin(userID string) bool {
+	if p == nil || userID == "" {
+		return false
+	}
+	_, ok := p.userIDs[userID]
+	return ok
+}
@@ -0,0 +1,57 @@
+package authz
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SectionPolicy restricts sections of vendor data, such as bank details, to
+// allowlisted users. Sections without an allowlist are visible to everyone.
+type SectionPolicy struct {
+	users  map[string]map[string]struct{}
+	admins *AdminPolicy
+}
+
+// NewSectionPolicy creates a section policy from "section:user_id|user_id"
+// rules. Sections must be one of known; admins may see every section.
+func NewSectionPolicy(rules, known []string, admins *AdminPolicy) (*SectionPolicy, error) {
+	valid := make(map[string]bool, len(known))
+	for _, section := range known {
+		valid[section] = true
+	}
+
+	p := &SectionPolicy{users: make(map[string]map[string]struct{}), admins: admins}
+	for _, rule := range rules {
+		section, ids, ok := strings.Cut(rule, ":")
+		section = strings.TrimSpace(section)
+		if !ok || !valid[section] {
+			return nil, fmt.Errorf("invalid section rule %q (expected section:user_id|user_id with section one of %s)", rule, strings.Join(known, ", "))
+		}
+
+		users := p.users[section]
+		if users == nil {
+			users = make(map[string]struct{})
+			p.users[section] = users
+		}
+		for _, id := range strings.Split(ids, "|") {
+			if id = strings.TrimSpace(id); id != "" {
+				users[id] = struct{}{}
+			}
+		}
+	}
+	return p, nil
+}
+
+// Allows reports whether the user may see section. A nil policy allows every
+// section.
+func (p *SectionPolicy) Allows(section, userID string) bool {
+	if p == nil {
+		return true
+	}
+	users, restricted := p.users[section]
+	if !restricted || p.admins.IsAdmin(userID) {
+		return true
+	}
+	_, ok := users[userID]
+	return userID != "" && ok
+}
@@ -0,0 +1,35 @@
+package authz
+
+import (
+	"context"
+	"slices"
+)
+
+// VendorKey is the caller of a request authenticated with a vendor API key. It
+// may only access the data of its vendor, within its scopes.
+type VendorKey struct {
+	KeyID    string
+	VendorID string
+	EntityID string
+	Scopes   []string
+}
+
+// HasScope reports whether the key grants scope
+func (k *VendorKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+type vendorKeyKey struct{}
+
+// WithVendorKey returns ctx carrying the vendor key the request was
+// authenticated with
+func WithVendorKey(ctx context.Context, key *VendorKey) context.Context {
+	return context.WithValue(ctx, vendorKeyKey{}, key)
+}
+
+// VendorKeyFromContext returns the vendor key of a request authenticated with
+// one
+func VendorKeyFromContext(ctx context.Context) (*VendorKey, bool) {
+	key, ok := ctx.Value(vendorKeyKey{}).(*VendorKey)
+	return key, ok
+}
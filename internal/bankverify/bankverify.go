@@ -0,0 +1,80 @@
+// Package bankverify verifies vendor bank accounts before their first
+// payment, by micro-deposits or instantly through a provider. A Verifier runs
+// the verification; Stub leaves every account unverified and
+// ProviderVerifier calls a bank verification provider.
+package bankverify
+
+import "context"
+
+// Verification statuses of a bank account
+const (
+	StatusUnverified = "unverified"
+	// StatusPending means the verification was started and awaits its
+	// confirmation, e.g. the amounts of the micro-deposits
+	StatusPending  = "pending"
+	StatusVerified = "verified"
+	StatusFailed   = "failed"
+)
+
+// Verification methods
+const (
+	// MethodMicroDeposits sends small deposits the vendor confirms
+	MethodMicroDeposits = "micro_deposits"
+	// MethodInstant verifies the account with the provider at once
+	MethodInstant = "instant"
+)
+
+// IsValidStatus reports whether status is a known verification status
+func IsValidStatus(status string) bool {
+	switch status {
+	case StatusUnverified, StatusPending, StatusVerified, StatusFailed:
+		return true
+	}
+	return false
+}
+
+// IsValidMethod reports whether method is a known verification method
+func IsValidMethod(method string) bool {
+	return method == MethodMicroDeposits || method == MethodInstant
+}
+
+// Account is a bank account to verify
+type Account struct {
+	AccountNumber string `json:"account_number,omitempty"`
+	RoutingNumber string `json:"routing_number,omitempty"`
+	IBAN          string `json:"iban,omitempty"`
+	SwiftCode     string `json:"swift_code,omitempty"`
+	Country       string `json:"country"`
+}
+
+// Attempt is a started verification. Reference identifies it at the verifier
+// when it is confirmed.
+type Attempt struct {
+	Status    string `json:"status"`
+	Method    string `json:"method"`
+	Reference string `json:"reference,omitempty"`
+}
+
+// Verifier verifies bank accounts
+type Verifier interface {
+	// Start begins verifying account with method. Instant verifications
+	// return their final status; micro-deposits return a pending attempt.
+	Start(ctx context.Context, account Account, method string) (*Attempt, error)
+	// Confirm completes the pending verification identified by reference
+	// with the amounts, in cents, the vendor received, returning its status
+	Confirm(ctx context.Context, reference string, amounts []int64) (string, error)
+}
+
+// Stub is the default Verifier, used when no provider is configured. It
+// verifies nothing: every account stays unverified.
+type Stub struct{}
+
+// Start returns an unverified attempt
+func (Stub) Start(ctx context.Context, account Account, method string) (*Attempt, error) {
+	return &Attempt{Status: StatusUnverified, Method: method}, nil
+}
+
+// Confirm returns unverified
+func (Stub) Confirm(ctx context.Context, reference string, amounts []int64) (string, error) {
+	return StatusUnverified, nil
+}
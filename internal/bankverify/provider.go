@@ -0,0 +1,103 @@
+package bankverify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ProviderVerifier verifies accounts with a bank verification provider over
+// HTTP. A verification is started by POSTing to {url}/verifications
+//
+//	{"account": {"account_number": "...", "routing_number": "...", "country": "US"}, "method": "micro_deposits"}
+//
+// which answers with the attempt:
+//
+//	{"reference": "ver_123", "status": "pending", "method": "micro_deposits"}
+//
+// and confirmed by POSTing {"amounts": [32, 45]} to
+// {url}/verifications/{reference}/confirm, which answers {"status": "verified"}.
+type ProviderVerifier struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+// NewProviderVerifier creates a verifier calling baseURL, sending apiKey as a
+// bearer token when set, each call bounded by timeout
+func NewProviderVerifier(baseURL, apiKey string, timeout time.Duration) *ProviderVerifier {
+	return &ProviderVerifier{
+		url:    strings.TrimRight(baseURL, "/"),
+		apiKey: apiKey,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Start begins a verification at the provider
+func (p *ProviderVerifier) Start(ctx context.Context, account Account, method string) (*Attempt, error) {
+	var attempt Attempt
+	body := map[string]interface{}{"account": account, "method": method}
+	if err := p.call(ctx, p.url+"/verifications", body, &attempt); err != nil {
+		return nil, err
+	}
+	if !IsValidStatus(attempt.Status) {
+		return nil, fmt.Errorf("bank verification provider returned unknown status %q", attempt.Status)
+	}
+	if attempt.Method == "" {
+		attempt.Method = method
+	}
+	return &attempt, nil
+}
+
+// Confirm completes a pending verification at the provider
+func (p *ProviderVerifier) Confirm(ctx context.Context, reference string, amounts []int64) (string, error) {
+	var result struct {
+		Status string `json:"status"`
+	}
+	endpoint := p.url + "/verifications/" + url.PathEscape(reference) + "/confirm"
+	if err := p.call(ctx, endpoint, map[string]interface{}{"amounts": amounts}, &result); err != nil {
+		return "", err
+	}
+	if !IsValidStatus(result.Status) {
+		return "", fmt.Errorf("bank verification provider returned unknown status %q", result.Status)
+	}
+	return result.Status, nil
+}
+
+// call POSTs body to endpoint and decodes the answer into out
+func (p *ProviderVerifier) call(ctx context.Context, endpoint string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("encode bank verification request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("build bank verification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("call bank verification provider: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("bank verification provider returned %s", resp.Status)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out); err != nil {
+		return fmt.Errorf("decode bank verification response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+// Package certs loads TLS certificates and CA bundles from disk, keeps them
+// reloadable at runtime and builds server and client TLS configurations that
+// always use the most recently loaded files.
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Config names the files of a Source. CertFile and KeyFile are a key pair and
+// must be set together; CAFile is a PEM bundle of trusted CA certificates.
+type Config struct {
+	// Name identifies the source in logs and health output
+	Name     string
+	CertFile string
+	KeyFile  string
+	CAFile   string
+}
+
+// Source holds the current key pair and CA pool loaded from a Config
+type Source struct {
+	cfg Config
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	leaf     *x509.Certificate
+	pool     *x509.CertPool
+	caExpiry time.Time
+	modTimes map[string]time.Time
+}
+
+// Status describes the loaded certificates of a Source
+type Status struct {
+	Name string `json:"name"`
+	// Subject and NotAfter describe the key pair; empty without one
+	Subject  string     `json:"subject,omitempty"`
+	NotAfter *time.Time `json:"not_after,omitempty"`
+	// CANotAfter is the earliest expiry among the CA certificates
+	CANotAfter *time.Time `json:"ca_not_after,omitempty"`
+}
+
+// Expiry returns the earliest expiry of the loaded certificates
+func (st Status) Expiry() time.Time {
+	switch {
+	case st.NotAfter == nil && st.CANotAfter == nil:
+		return time.Time{}
+	case st.NotAfter == nil:
+		return *st.CANotAfter
+	case st.CANotAfter == nil || st.NotAfter.Before(*st.CANotAfter):
+		return *st.NotAfter
+	default:
+		return *st.CANotAfter
+	}
+}
+
+// Load reads the files of cfg. It fails when a file is missing or unreadable,
+// or when a certificate is expired or not yet valid.
+func Load(cfg Config) (*Source, error) {
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		return nil, fmt.Errorf("%s: certificate and key file must be set together", cfg.Name)
+	}
+
+	s := &Source{cfg: cfg}
+	if err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload reads the files again. On failure the previously loaded
+// certificates stay in use.
+func (s *Source) Reload() error {
+	now := time.Now()
+	modTimes := make(map[string]time.Time)
+
+	var (
+		cert *tls.Certificate
+		leaf *x509.Certificate
+	)
+	if s.cfg.CertFile != "" {
+		pair, err := tls.LoadX509KeyPair(s.cfg.CertFile, s.cfg.KeyFile)
+		if err != nil {
+			return fmt.Errorf("%s: load key pair: %w", s.cfg.Name, err)
+		}
+		leaf, err = x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			return fmt.Errorf("%s: parse certificate %s: %w", s.cfg.Name, s.cfg.CertFile, err)
+		}
+		if err := checkValidity(leaf, now); err != nil {
+			return fmt.Errorf("%s: certificate %s %w", s.cfg.Name, s.cfg.CertFile, err)
+		}
+		pair.Leaf = leaf
+		cert = &pair
+
+		for _, file := range []string{s.cfg.CertFile, s.cfg.KeyFile} {
+			if info, err := os.Stat(file); err == nil {
+				modTimes[file] = info.ModTime()
+			}
+		}
+	}
+
+	var (
+		pool     *x509.CertPool
+		caExpiry time.Time
+	)
+	if s.cfg.CAFile != "" {
+		var err error
+		pool, caExpiry, err = loadCAFile(s.cfg.CAFile, now)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.cfg.Name, err)
+		}
+		if info, err := os.Stat(s.cfg.CAFile); err == nil {
+			modTimes[s.cfg.CAFile] = info.ModTime()
+		}
+	}
+
+	s.mu.Lock()
+	s.cert, s.leaf = cert, leaf
+	s.pool, s.caExpiry = pool, caExpiry
+	s.modTimes = modTimes
+	s.mu.Unlock()
+
+	return nil
+}
+
+// loadCAFile parses a PEM bundle of CA certificates and returns the pool and
+// the earliest expiry
+func loadCAFile(file string, now time.Time) (*x509.CertPool, time.Time, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("read CA file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	var expiry time.Time
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		ca, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("parse CA file %s: %w", file, err)
+		}
+		if err := checkValidity(ca, now); err != nil {
+			return nil, time.Time{}, fmt.Errorf("CA certificate %q in %s %w", ca.Subject.CommonName, file, err)
+		}
+		pool.AddCert(ca)
+		if expiry.IsZero() || ca.NotAfter.Before(expiry) {
+			expiry = ca.NotAfter
+		}
+	}
+	if expiry.IsZero() {
+		return nil, time.Time{}, fmt.Errorf("CA file %s contains no certificates", file)
+	}
+
+	return pool, expiry, nil
+}
+
+func checkValidity(cert *x509.Certificate, now time.Time) error {
+	if now.After(cert.NotAfter) {
+		return fmt.Errorf("expired at %s", cert.NotAfter.UTC().Format(time.RFC3339))
+	}
+	if now.Before(cert.NotBefore) {
+		return fmt.Errorf("is not valid before %s", cert.NotBefore.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Status describes the currently loaded certificates
+func (s *Source) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	st := Status{Name: s.cfg.Name}
+	if s.leaf != nil {
+		notAfter := s.leaf.NotAfter
+		st.Subject = s.leaf.Subject.String()
+		st.NotAfter = &notAfter
+	}
+	if s.pool != nil {
+		caExpiry := s.caExpiry
+		st.CANotAfter = &caExpiry
+	}
+	return st
+}
+
+// changed reports whether any loaded file was modified since it was read
+func (s *Source) changed() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for file, modTime := range s.modTimes {
+		info, err := os.Stat(file)
+		if err != nil || !info.ModTime().Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch reloads the source whenever one of its files changes on disk, checking
+// every interval until ctx is done. onReload is called with the result of
+// every reload attempt.
+func (s *Source) Watch(ctx context.Context, interval time.Duration, onReload func(err error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if s.changed() {
+				onReload(s.Reload())
+			}
+		}
+	}
+}
+
+func (s *Source) certificate() (*tls.Certificate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.cert == nil {
+		return nil, fmt.Errorf("%s: no certificate configured", s.cfg.Name)
+	}
+	return s.cert, nil
+}
+
+func (s *Source) caPool() *x509.CertPool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pool
+}
+
+// ServerTLSConfig returns a server configuration presenting the current key
+// pair. With a CA file, clients must present a certificate signed by it (mTLS).
+func (s *Source) ServerTLSConfig() *tls.Config {
+	base := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return s.certificate()
+		},
+	}
+	if s.cfg.CAFile == "" {
+		return base
+	}
+
+	// Resolved per handshake so reloaded client CAs apply to new connections
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		cfg := base.Clone()
+		cfg.GetConfigForClient = nil
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		cfg.ClientCAs = s.caPool()
+		return cfg, nil
+	}
+	return base
+}
+
+// ClientTLSConfig returns a client configuration for serverName. Without a CA
+// file the system roots are trusted; with one, only the pinned CAs are. The
+// current key pair, if any, is presented as client certificate.
+func (s *Source) ClientTLSConfig(serverName string) *tls.Config {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+	}
+	if s.cfg.CertFile != "" {
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return s.certificate()
+		}
+	}
+	if s.cfg.CAFile == "" {
+		return cfg
+	}
+
+	// The standard verification only sees the roots present when the config
+	// was built, so pinned roots are verified here against the current pool
+	cfg.InsecureSkipVerify = true
+	cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return fmt.Errorf("%s: server presented no certificate", s.cfg.Name)
+		}
+		intermediates := x509.NewCertPool()
+		for _, cert := range cs.PeerCertificates[1:] {
+			intermediates.AddCert(cert)
+		}
+		_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+			DNSName:       serverName,
+			Roots:         s.caPool(),
+			Intermediates: intermediates,
+		})
+		return err
+	}
+	return cfg
+}
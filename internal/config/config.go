@@ -0,0 +1,477 @@
+// Package config loads vendors-service specific settings that are not part of
+// the shared be-lib-common configuration.
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Storage backends selectable with VENDORS_STORAGE
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
+// Config holds service specific settings
+type Config struct {
+	// Storage is the vendor storage backend: postgres, or memory for local
+	// development without a database
+	Storage string
+	// RunMigrations applies pending database migrations at startup
+	RunMigrations bool
+	// IdentityGRPCURL is the address of the identity service used for authentication
+	IdentityGRPCURL string
+	// IdentityCallTimeout bounds every attempt of an identity call
+	IdentityCallTimeout time.Duration
+	// IdentityMaxRetries is the number of retries of identity calls failing with Unavailable
+	IdentityMaxRetries int
+	// IdentityRetryBackoff is the delay before the first retry, doubled on each retry
+	IdentityRetryBackoff time.Duration
+	// IdentityBreakerThreshold is the number of consecutive failed identity calls
+	// after which calls are short-circuited
+	IdentityBreakerThreshold int
+	// IdentityBreakerCooldown is how long identity calls are short-circuited before a probe
+	IdentityBreakerCooldown time.Duration
+	// IdentityCacheTTL is how long successful token validations are reused; 0 disables caching
+	IdentityCacheTTL time.Duration
+	// IdentityCacheSize bounds the number of cached token validations
+	IdentityCacheSize int
+	// GRPCPort is the port the gRPC server listens on
+	GRPCPort int
+	// GRPCMaxRecvMsgSize and GRPCMaxSendMsgSize bound gRPC message sizes, in bytes
+	GRPCMaxRecvMsgSize int
+	GRPCMaxSendMsgSize int
+	// GRPCMaxConcurrentStreams limits concurrent streams per client connection
+	GRPCMaxConcurrentStreams int
+	// GRPCKeepaliveTime is how long a connection may be idle before the server pings it
+	GRPCKeepaliveTime time.Duration
+	// GRPCKeepaliveTimeout is how long the server waits for a ping ack before closing
+	GRPCKeepaliveTimeout time.Duration
+	// GRPCKeepaliveMinTime is the minimum interval allowed between client pings;
+	// clients pinging more often are disconnected
+	GRPCKeepaliveMinTime time.Duration
+	// GRPCKeepalivePermitWithoutStream allows client pings without active streams
+	GRPCKeepalivePermitWithoutStream bool
+	// GRPCTLSDisabled serves gRPC in plaintext, for local development only
+	GRPCTLSDisabled bool
+	// GRPCTLSCertFile and GRPCTLSKeyFile are the PEM key pair of the gRPC server
+	GRPCTLSCertFile string
+	GRPCTLSKeyFile  string
+	// GRPCTLSClientCAFile requires client certificates signed by these CAs (mTLS)
+	GRPCTLSClientCAFile string
+	// IdentityTLSDisabled connects to the identity service in plaintext
+	IdentityTLSDisabled bool
+	// IdentityTLSCAFile pins the CAs trusted for the identity service; empty
+	// trusts the system roots
+	IdentityTLSCAFile string
+	// IdentityTLSCertFile and IdentityTLSKeyFile are an optional client key pair
+	// presented to the identity service
+	IdentityTLSCertFile string
+	IdentityTLSKeyFile  string
+	// IdentityTLSServerName overrides the host name verified on the identity certificate
+	IdentityTLSServerName string
+	// TLSReloadInterval is how often certificate files are checked for changes; 0
+	// reloads only on SIGHUP
+	TLSReloadInterval time.Duration
+	// TLSExpiryWarning reports certificates expiring within it as expiring on /health/tls
+	TLSExpiryWarning time.Duration
+	// ShutdownPreStopDelay is how long shutdown waits, with readiness already
+	// failing, before the servers stop accepting requests
+	ShutdownPreStopDelay time.Duration
+	// StrictQueryParams rejects HTTP requests carrying unrecognized query parameters
+	StrictQueryParams bool
+	// StrictJSONBodies rejects HTTP request bodies carrying unknown fields,
+	// unless the request sets X-Lenient-JSON
+	StrictJSONBodies bool
+	// WatchHeartbeatInterval is how often WatchVendors streams send heartbeats
+	WatchHeartbeatInterval time.Duration
+	// AdminUserIDs are the identity user IDs allowed to call admin-only RPCs
+	AdminUserIDs []string
+	// VendorSectionAccess restricts vendor snapshot sections to users, as
+	// "section:user_id|user_id" rules
+	VendorSectionAccess []string
+	// AdminAPIToken guards the internal HTTP admin endpoints; empty disables them
+	AdminAPIToken string
+	// RetentionDeletedVendorDays is the default retention of soft-deleted vendors
+	RetentionDeletedVendorDays int
+	// RetentionAuditLogDays is the default retention of audit log rows
+	RetentionAuditLogDays int
+	// PurgeInterval is how often the worker runs the purge; 0 disables it
+	PurgeInterval time.Duration
+	// PurgeBatchSize is the number of rows deleted per purge statement
+	PurgeBatchSize int
+	// PurgeDryRun makes the worker only report what would be purged
+	PurgeDryRun bool
+	// WorkerHTTPPort is the port of the worker's health and metrics listener; 0 disables it
+	WorkerHTTPPort int
+	// WorkerLeaderCheckInterval is how often a standby worker tries to become
+	// the leader, and the leader checks it still is
+	WorkerLeaderCheckInterval time.Duration
+	// ContactMethodRule is the default reachable contact method rule (off, warn or enforce)
+	ContactMethodRule string
+	// CountryProfileRule is how vendor writes apply country profiles (off, warn or enforce)
+	CountryProfileRule string
+	// StrictAddressValidation rejects invalid vendor addresses instead of warning,
+	// for entities without their own setting
+	StrictAddressValidation bool
+	// StrictBankValidation rejects invalid IBAN and SWIFT codes instead of
+	// warning, for entities without their own setting
+	StrictBankValidation bool
+	// RequireBankVerification fails the validation of ACH and wire vendors
+	// with unverified bank accounts instead of warning, for entities without
+	// their own setting
+	RequireBankVerification bool
+	// EntitySettingsCacheTTL is how long the resolved validation switches of an
+	// entity are cached; 0 disables the cache
+	EntitySettingsCacheTTL time.Duration
+	// ListEstimateTotalAbove is the live vendor count above which vendor list
+	// totals are estimated unless the request asks otherwise; 0 always counts
+	ListEstimateTotalAbove int
+	// ListEntityCountCacheTTL is how long the live vendor count deciding
+	// between counting and estimating is reused per entity
+	ListEntityCountCacheTTL time.Duration
+	// VendorCodeReservationTTL is how long a reserved vendor code is held for
+	// the vendor to be created
+	VendorCodeReservationTTL time.Duration
+	// CurrencyChangeActivityDays is how far back balance ledger activity
+	// blocks currency changes of vendors; 0 only checks the balance
+	CurrencyChangeActivityDays int
+	// RequiredCommunicationTypes are the communication types (purchase_orders,
+	// remittance, statements) whose last recipient among the contacts of a
+	// vendor cannot be removed; empty requires none
+	RequiredCommunicationTypes []string
+	// DataQualityRules are the data quality rules evaluated by the data
+	// quality report and vendor validation; empty enables all of them
+	DataQualityRules []string
+	// BlocklistPolicy is what happens to vendors on the organization blocklist
+	// (block or warn)
+	BlocklistPolicy string
+	// AddressValidationURL is the endpoint of the address verification provider;
+	// empty applies only the built-in checks
+	AddressValidationURL string
+	// AddressValidationAPIKey is sent to the provider as a bearer token
+	AddressValidationAPIKey string
+	// AddressValidationTimeout bounds every call to the provider
+	AddressValidationTimeout time.Duration
+	// TINMatchURL is the endpoint of the TIN matching provider; empty leaves
+	// every vendor unverified
+	TINMatchURL string
+	// TINMatchAPIKey is sent to the provider as a bearer token
+	TINMatchAPIKey string
+	// TINMatchTimeout bounds every call to the provider
+	TINMatchTimeout time.Duration
+	// TINMatchBatchSize is how many TIN and name combinations are sent per call
+	TINMatchBatchSize int
+	// TINMatchCallsPerMinute caps the calls to the provider, which throttles
+	// aggressively
+	TINMatchCallsPerMinute int
+	// BankVerificationURL is the base URL of the bank verification provider;
+	// empty leaves every bank account unverified
+	BankVerificationURL string
+	// BankVerificationAPIKey is sent to the provider as a bearer token
+	BankVerificationAPIKey string
+	// BankVerificationTimeout bounds every call to the provider
+	BankVerificationTimeout time.Duration
+	// VendorQuotaDefault caps the live vendors of entities without their own
+	// quota; 0 means unlimited
+	VendorQuotaDefault int
+	// VendorQuotas are per-entity vendor quotas as "entity_id:limit" pairs
+	VendorQuotas []string
+	// CreateDebounceWindow is how long an identical create is rejected after a
+	// vendor was created; 0 disables the check
+	CreateDebounceWindow time.Duration
+	// CreateDebounceCacheSize bounds the recent creates remembered in process
+	CreateDebounceCacheSize int
+	// BulkDeleteConfirmationTTL is how long the confirmation token of a bulk
+	// delete dry run stays valid
+	BulkDeleteConfirmationTTL time.Duration
+	// ApprovalSLA is how long vendors may await approval before they are
+	// reported overdue; 0 disables the SLA
+	ApprovalSLA time.Duration
+	// ApprovalSLACheckInterval is how often the worker looks for overdue
+	// approvals; 0 disables the check
+	ApprovalSLACheckInterval time.Duration
+	// RiskRecomputeInterval is how often the worker rescores all vendors, so
+	// that time-based risk factors lapse; 0 disables the job
+	RiskRecomputeInterval time.Duration
+	// StatusScheduleInterval is how often the worker applies due scheduled
+	// status changes; 0 disables the job
+	StatusScheduleInterval time.Duration
+	// DormantAfterMonths is how long vendors go without balance ledger
+	// activity or updates before they are flagged dormant
+	DormantAfterMonths int
+	// DormancyEvents publishes vendor.flagged_dormant for newly flagged vendors
+	DormancyEvents bool
+	// DormancyCheckInterval is how often the worker flags dormant vendors; 0
+	// disables the job
+	DormancyCheckInterval time.Duration
+	// BalanceReconcileInterval is how often the worker reports vendors whose
+	// current_balance drifted from their balance ledger; 0 disables the job
+	BalanceReconcileInterval time.Duration
+	// TINMatchInterval is how often the worker verifies the TINs of 1099
+	// vendors; 0, or no TINMatchURL, disables the job
+	TINMatchInterval time.Duration
+	// EntityEventsURL is the platform event feed the worker reads entity
+	// lifecycle events from; empty disables the consumer
+	EntityEventsURL string
+	// EntityEventsAPIKey is sent to the event feed as a bearer token
+	EntityEventsAPIKey string
+	// EntityEventsTimeout bounds every poll of the event feed
+	EntityEventsTimeout time.Duration
+	// EntityEventsBatchSize is how many events are read per poll
+	EntityEventsBatchSize int
+	// EntityEventsPollInterval is how often the worker reads entity lifecycle
+	// events; 0, or no EntityEventsURL, disables the consumer
+	EntityEventsPollInterval time.Duration
+	// CodeReservationSweepInterval is how often the worker releases expired
+	// vendor code reservations; 0 disables the job
+	CodeReservationSweepInterval time.Duration
+	// ExportStorageDir is the directory vendor export files are written to,
+	// shared by the server and the worker; empty disables vendor exports
+	ExportStorageDir string
+	// ExportLinkSigningKey signs export download links; it must be the same on
+	// every server replica. Empty uses a random key per process.
+	ExportLinkSigningKey string
+	// ExportLinkTTL is how long a download link stays valid
+	ExportLinkTTL time.Duration
+	// ExportRetention is how long finished export jobs and their files are kept
+	ExportRetention time.Duration
+	// VendorExportPollInterval is how often the worker looks for queued
+	// vendor exports; 0, or no ExportStorageDir, disables the job
+	VendorExportPollInterval time.Duration
+	// PaymentsGRPCURL is the address of the payments service providing vendor
+	// spend; empty reports no spend
+	PaymentsGRPCURL string
+	// PaymentsTLSServerName overrides the host name verified on the payments certificate
+	PaymentsTLSServerName string
+	// PaymentsCallTimeout bounds every call to the payments service
+	PaymentsCallTimeout time.Duration
+	// SpendCacheTTL is how long vendor spend is reused; 0 disables caching
+	SpendCacheTTL time.Duration
+	// SpendCacheSize bounds the number of cached spend summaries
+	SpendCacheSize int
+	// MaxRequestBodyBytes bounds HTTP request bodies
+	MaxRequestBodyBytes int64
+	// MaxUploadBodyBytes bounds the bodies of upload routes such as the contact
+	// import; it defaults to GRPC_MAX_RECV_MSG_BYTES' default so both APIs
+	// accept the same sizes
+	MaxUploadBodyBytes int64
+	// RedisURL shares recent creates between replicas; empty keeps them in process
+	RedisURL string
+	// RedisTimeout bounds every Redis command
+	RedisTimeout time.Duration
+	// CORSAllowedOrigins are the allowed browser origins; exact, "https://*.domain" or "*"
+	CORSAllowedOrigins []string
+	// CORSAllowCredentials allows credentialed cross-origin requests
+	CORSAllowCredentials bool
+	// CORSMaxAge is how long browsers may cache preflight results, in seconds
+	CORSMaxAge int
+	// AdminHTTPPort is the port of the admin listener serving /debug; 0 disables it
+	AdminHTTPPort int
+	// SlowRequestThreshold logs slower requests at Warn with repository timings
+	SlowRequestThreshold time.Duration
+	// SlowQueryThreshold logs slower repository statements at Warn; 0 disables it
+	SlowQueryThreshold time.Duration
+	// QueryTimeoutRead bounds read-only statements
+	QueryTimeoutRead time.Duration
+	// QueryTimeoutWrite bounds statements that modify data
+	QueryTimeoutWrite time.Duration
+	// QueryTimeoutBulk bounds statements of bulk operations such as purges
+	QueryTimeoutBulk time.Duration
+	// ReadReplicaHost is the host of the read replica; empty sends all reads to the primary
+	ReadReplicaHost string
+	// ReadReplicaPort is the port of the read replica; 0 uses the primary's port
+	ReadReplicaPort int
+	// ReadReplicaUser and ReadReplicaPassword default to the primary's credentials
+	ReadReplicaUser     string
+	ReadReplicaPassword string
+	// ReadReplicaHealthInterval is how often the replica is pinged
+	ReadReplicaHealthInterval time.Duration
+	// UserCacheTTL is how long users resolved with the identity service are
+	// reused; 0 disables caching
+	UserCacheTTL time.Duration
+	// UserCacheSize bounds the number of cached users
+	UserCacheSize int
+	// DocumentStorageURL is the base URL of the object storage holding vendor
+	// documents; empty disables document downloads
+	DocumentStorageURL string
+	// DocumentLinkSigningKey signs the storage links documents are redirected
+	// to; empty streams every document through the service
+	DocumentLinkSigningKey string
+	// DocumentLinkTTL is how long a signed document link stays valid
+	DocumentLinkTTL time.Duration
+	// DocumentStreamMaxBytes is the size up to which documents are streamed
+	// rather than redirected to a signed link
+	DocumentStreamMaxBytes int64
+	// DocumentStorageTimeout bounds the wait for the storage's response headers
+	DocumentStorageTimeout time.Duration
+}
+
+// Load reads service specific settings from the environment
+func Load() *Config {
+	return &Config{
+		Storage:                          getEnv("VENDORS_STORAGE", StoragePostgres),
+		RunMigrations:                    getEnvBool("RUN_MIGRATIONS", false),
+		IdentityGRPCURL:                  getEnv("IDENTITY_GRPC_URL", "localhost:9080"),
+		IdentityCallTimeout:              time.Duration(getEnvInt("IDENTITY_CALL_TIMEOUT_MS", 2000)) * time.Millisecond,
+		IdentityMaxRetries:               getEnvInt("IDENTITY_MAX_RETRIES", 2),
+		IdentityRetryBackoff:             time.Duration(getEnvInt("IDENTITY_RETRY_BACKOFF_MS", 100)) * time.Millisecond,
+		IdentityBreakerThreshold:         getEnvInt("IDENTITY_BREAKER_THRESHOLD", 5),
+		IdentityBreakerCooldown:          time.Duration(getEnvInt("IDENTITY_BREAKER_COOLDOWN_SECONDS", 30)) * time.Second,
+		IdentityCacheTTL:                 time.Duration(getEnvInt("IDENTITY_AUTH_CACHE_TTL_SECONDS", 30)) * time.Second,
+		IdentityCacheSize:                getEnvInt("IDENTITY_AUTH_CACHE_SIZE", 10000),
+		GRPCPort:                         getEnvInt("GRPC_PORT", 9086), // AP Vendors gRPC port
+		GRPCMaxRecvMsgSize:               getEnvInt("GRPC_MAX_RECV_MSG_BYTES", 4<<20),
+		GRPCMaxSendMsgSize:               getEnvInt("GRPC_MAX_SEND_MSG_BYTES", 16<<20),
+		GRPCMaxConcurrentStreams:         getEnvInt("GRPC_MAX_CONCURRENT_STREAMS", 250),
+		GRPCKeepaliveTime:                time.Duration(getEnvInt("GRPC_KEEPALIVE_TIME_SECONDS", 120)) * time.Second,
+		GRPCKeepaliveTimeout:             time.Duration(getEnvInt("GRPC_KEEPALIVE_TIMEOUT_SECONDS", 20)) * time.Second,
+		GRPCKeepaliveMinTime:             time.Duration(getEnvInt("GRPC_KEEPALIVE_MIN_TIME_SECONDS", 30)) * time.Second,
+		GRPCKeepalivePermitWithoutStream: getEnvBool("GRPC_KEEPALIVE_PERMIT_WITHOUT_STREAM", true),
+		GRPCTLSDisabled:                  getEnvBool("GRPC_TLS_DISABLED", false),
+		GRPCTLSCertFile:                  getEnv("GRPC_TLS_CERT_FILE", ""),
+		GRPCTLSKeyFile:                   getEnv("GRPC_TLS_KEY_FILE", ""),
+		GRPCTLSClientCAFile:              getEnv("GRPC_TLS_CLIENT_CA_FILE", ""),
+		IdentityTLSDisabled:              getEnvBool("IDENTITY_TLS_DISABLED", false),
+		IdentityTLSCAFile:                getEnv("IDENTITY_TLS_CA_FILE", ""),
+		IdentityTLSCertFile:              getEnv("IDENTITY_TLS_CERT_FILE", ""),
+		IdentityTLSKeyFile:               getEnv("IDENTITY_TLS_KEY_FILE", ""),
+		IdentityTLSServerName:            getEnv("IDENTITY_TLS_SERVER_NAME", ""),
+		TLSReloadInterval:                time.Duration(getEnvInt("TLS_RELOAD_INTERVAL_SECONDS", 60)) * time.Second,
+		TLSExpiryWarning:                 time.Duration(getEnvInt("TLS_EXPIRY_WARNING_DAYS", 14)) * 24 * time.Hour,
+		ShutdownPreStopDelay:             time.Duration(getEnvInt("SHUTDOWN_PRE_STOP_DELAY_SECONDS", 5)) * time.Second,
+		StrictQueryParams:                getEnvBool("STRICT_QUERY_PARAMS", false),
+		StrictJSONBodies:                 getEnvBool("STRICT_JSON_BODIES", true),
+		WatchHeartbeatInterval:           time.Duration(getEnvInt("WATCH_HEARTBEAT_SECONDS", 15)) * time.Second,
+		AdminUserIDs:                     getEnvList("ADMIN_USER_IDS"),
+		VendorSectionAccess:              getEnvList("VENDOR_SECTION_ACCESS"),
+		AdminAPIToken:                    getEnv("ADMIN_API_TOKEN", ""),
+		RetentionDeletedVendorDays:       getEnvInt("RETENTION_DELETED_VENDOR_DAYS", 365),
+		RetentionAuditLogDays:            getEnvInt("RETENTION_AUDIT_LOG_DAYS", 730),
+		PurgeInterval:                    time.Duration(getEnvInt("PURGE_INTERVAL_MINUTES", 1440)) * time.Minute,
+		PurgeBatchSize:                   getEnvInt("PURGE_BATCH_SIZE", 500),
+		PurgeDryRun:                      getEnvBool("PURGE_DRY_RUN", false),
+		WorkerHTTPPort:                   getEnvInt("WORKER_HTTP_PORT", 8090),
+		WorkerLeaderCheckInterval:        time.Duration(getEnvInt("WORKER_LEADER_CHECK_SECONDS", 15)) * time.Second,
+		ContactMethodRule:                getEnv("CONTACT_METHOD_RULE", "warn"),
+		CountryProfileRule:               getEnv("COUNTRY_PROFILE_RULE", "warn"),
+		StrictAddressValidation:          getEnvBool("STRICT_ADDRESS_VALIDATION", false),
+		StrictBankValidation:             getEnvBool("STRICT_BANK_VALIDATION", false),
+		RequireBankVerification:          getEnvBool("REQUIRE_BANK_VERIFICATION", false),
+		EntitySettingsCacheTTL:           time.Duration(getEnvInt("ENTITY_SETTINGS_CACHE_SECONDS", 30)) * time.Second,
+		ListEstimateTotalAbove:           getEnvInt("LIST_ESTIMATE_TOTAL_ABOVE", 100000),
+		ListEntityCountCacheTTL:          time.Duration(getEnvInt("LIST_ENTITY_COUNT_CACHE_SECONDS", 300)) * time.Second,
+		VendorCodeReservationTTL:         time.Duration(getEnvInt("VENDOR_CODE_RESERVATION_MINUTES", 30)) * time.Minute,
+		DataQualityRules:                 getEnvList("DATA_QUALITY_RULES"),
+		RequiredCommunicationTypes:       getEnvList("REQUIRED_COMMUNICATION_TYPES"),
+		CurrencyChangeActivityDays:       getEnvInt("CURRENCY_CHANGE_ACTIVITY_DAYS", 90),
+		BlocklistPolicy:                  getEnv("BLOCKLIST_POLICY", "block"),
+		AddressValidationURL:             getEnv("ADDRESS_VALIDATION_URL", ""),
+		AddressValidationAPIKey:          getEnv("ADDRESS_VALIDATION_API_KEY", ""),
+		AddressValidationTimeout:         time.Duration(getEnvInt("ADDRESS_VALIDATION_TIMEOUT_MS", 2000)) * time.Millisecond,
+		TINMatchURL:                      getEnv("TIN_MATCH_URL", ""),
+		TINMatchAPIKey:                   getEnv("TIN_MATCH_API_KEY", ""),
+		TINMatchTimeout:                  time.Duration(getEnvInt("TIN_MATCH_TIMEOUT_MS", 10000)) * time.Millisecond,
+		TINMatchBatchSize:                getEnvInt("TIN_MATCH_BATCH_SIZE", 25),
+		TINMatchCallsPerMinute:           getEnvInt("TIN_MATCH_CALLS_PER_MINUTE", 10),
+		BankVerificationURL:              getEnv("BANK_VERIFICATION_URL", ""),
+		BankVerificationAPIKey:           getEnv("BANK_VERIFICATION_API_KEY", ""),
+		BankVerificationTimeout:          time.Duration(getEnvInt("BANK_VERIFICATION_TIMEOUT_MS", 10000)) * time.Millisecond,
+		VendorQuotaDefault:               getEnvInt("VENDOR_QUOTA_DEFAULT", 0),
+		VendorQuotas:                     getEnvList("VENDOR_QUOTAS"),
+		CreateDebounceWindow:             time.Duration(getEnvInt("CREATE_DEBOUNCE_SECONDS", 10)) * time.Second,
+		CreateDebounceCacheSize:          getEnvInt("CREATE_DEBOUNCE_CACHE_SIZE", 10000),
+		BulkDeleteConfirmationTTL:        time.Duration(getEnvInt("BULK_DELETE_CONFIRMATION_SECONDS", 600)) * time.Second,
+		ApprovalSLA:                      time.Duration(getEnvInt("APPROVAL_SLA_HOURS", 48)) * time.Hour,
+		ApprovalSLACheckInterval:         time.Duration(getEnvInt("APPROVAL_SLA_CHECK_MINUTES", 15)) * time.Minute,
+		RiskRecomputeInterval:            time.Duration(getEnvInt("RISK_RECOMPUTE_INTERVAL_MINUTES", 1440)) * time.Minute,
+		StatusScheduleInterval:           time.Duration(getEnvInt("STATUS_SCHEDULE_INTERVAL_MINUTES", 15)) * time.Minute,
+		DormantAfterMonths:               getEnvInt("DORMANT_AFTER_MONTHS", 24),
+		DormancyEvents:                   getEnvBool("DORMANCY_EVENTS", false),
+		DormancyCheckInterval:            time.Duration(getEnvInt("DORMANCY_CHECK_INTERVAL_MINUTES", 1440)) * time.Minute,
+		BalanceReconcileInterval:         time.Duration(getEnvInt("BALANCE_RECONCILE_INTERVAL_MINUTES", 10080)) * time.Minute,
+		TINMatchInterval:                 time.Duration(getEnvInt("TIN_MATCH_INTERVAL_MINUTES", 1440)) * time.Minute,
+		EntityEventsURL:                  getEnv("ENTITY_EVENTS_URL", ""),
+		EntityEventsAPIKey:               getEnv("ENTITY_EVENTS_API_KEY", ""),
+		EntityEventsTimeout:              time.Duration(getEnvInt("ENTITY_EVENTS_TIMEOUT_MS", 10000)) * time.Millisecond,
+		EntityEventsBatchSize:            getEnvInt("ENTITY_EVENTS_BATCH_SIZE", 100),
+		EntityEventsPollInterval:         time.Duration(getEnvInt("ENTITY_EVENTS_POLL_SECONDS", 30)) * time.Second,
+		CodeReservationSweepInterval:     time.Duration(getEnvInt("CODE_RESERVATION_SWEEP_MINUTES", 15)) * time.Minute,
+		ExportStorageDir:                 getEnv("EXPORT_STORAGE_DIR", ""),
+		ExportLinkSigningKey:             getEnv("EXPORT_LINK_SIGNING_KEY", ""),
+		ExportLinkTTL:                    time.Duration(getEnvInt("EXPORT_LINK_TTL_SECONDS", 900)) * time.Second,
+		ExportRetention:                  time.Duration(getEnvInt("EXPORT_RETENTION_HOURS", 24)) * time.Hour,
+		VendorExportPollInterval:         time.Duration(getEnvInt("VENDOR_EXPORT_POLL_SECONDS", 5)) * time.Second,
+		PaymentsGRPCURL:                  getEnv("PAYMENTS_GRPC_URL", ""),
+		PaymentsTLSServerName:            getEnv("PAYMENTS_TLS_SERVER_NAME", ""),
+		PaymentsCallTimeout:              time.Duration(getEnvInt("PAYMENTS_CALL_TIMEOUT_MS", 2000)) * time.Millisecond,
+		SpendCacheTTL:                    time.Duration(getEnvInt("SPEND_CACHE_TTL_SECONDS", 60)) * time.Second,
+		SpendCacheSize:                   getEnvInt("SPEND_CACHE_SIZE", 10000),
+		MaxRequestBodyBytes:              int64(getEnvInt("MAX_REQUEST_BODY_BYTES", 64<<10)),
+		MaxUploadBodyBytes:               int64(getEnvInt("MAX_UPLOAD_BODY_BYTES", 4<<20)),
+		RedisURL:                         getEnv("REDIS_URL", ""),
+		RedisTimeout:                     time.Duration(getEnvInt("REDIS_TIMEOUT_MS", 500)) * time.Millisecond,
+		CORSAllowedOrigins:               getEnvList("CORS_ALLOWED_ORIGINS"),
+		CORSAllowCredentials:             getEnvBool("CORS_ALLOW_CREDENTIALS", false),
+		CORSMaxAge:                       getEnvInt("CORS_MAX_AGE", 600),
+		AdminHTTPPort:                    getEnvInt("ADMIN_HTTP_PORT", 0),
+		SlowRequestThreshold:             time.Duration(getEnvInt("SLOW_REQUEST_MS", 1000)) * time.Millisecond,
+		SlowQueryThreshold:               time.Duration(getEnvInt("SLOW_QUERY_MS", 200)) * time.Millisecond,
+		QueryTimeoutRead:                 time.Duration(getEnvInt("QUERY_TIMEOUT_READ_MS", 2000)) * time.Millisecond,
+		QueryTimeoutWrite:                time.Duration(getEnvInt("QUERY_TIMEOUT_WRITE_MS", 5000)) * time.Millisecond,
+		QueryTimeoutBulk:                 time.Duration(getEnvInt("QUERY_TIMEOUT_BULK_MS", 30000)) * time.Millisecond,
+		ReadReplicaHost:                  getEnv("DB_REPLICA_HOST", ""),
+		ReadReplicaPort:                  getEnvInt("DB_REPLICA_PORT", 0),
+		ReadReplicaUser:                  getEnv("DB_REPLICA_USER", ""),
+		ReadReplicaPassword:              getEnv("DB_REPLICA_PASSWORD", ""),
+		ReadReplicaHealthInterval:        time.Duration(getEnvInt("DB_REPLICA_HEALTH_SECONDS", 5)) * time.Second,
+		UserCacheTTL:                     time.Duration(getEnvInt("USER_CACHE_TTL_SECONDS", 300)) * time.Second,
+		UserCacheSize:                    getEnvInt("USER_CACHE_SIZE", 10000),
+		DocumentStorageURL:               getEnv("DOCUMENT_STORAGE_URL", ""),
+		DocumentLinkSigningKey:           getEnv("DOCUMENT_LINK_SIGNING_KEY", ""),
+		DocumentLinkTTL:                  time.Duration(getEnvInt("DOCUMENT_LINK_TTL_SECONDS", 300)) * time.Second,
+		DocumentStreamMaxBytes:           int64(getEnvInt("DOCUMENT_STREAM_MAX_BYTES", 1<<20)),
+		DocumentStorageTimeout:           time.Duration(getEnvInt("DOCUMENT_STORAGE_TIMEOUT_MS", 5000)) * time.Millisecond,
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		var result int
+		_, err := fmt.Sscanf(value, "%d", &result)
+		if err == nil {
+			return result
+		}
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if result, err := strconv.ParseBool(value); err == nil {
+			return result
+		}
+	}
+	return defaultValue
+}
+
+func getEnvList(key string) []string {
+	var result []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
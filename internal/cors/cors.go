@@ -0,0 +1,176 @@
+// Package cors implements the CORS policy of the HTTP API with origin
+// allowlists, wildcard subdomain patterns and validated credential support.
+package cors
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Config configures the CORS policy
+type Config struct {
+	// AllowedOrigins are exact origins ("https://app.pesio.ai"), wildcard
+	// subdomain patterns ("https://*.pesio.ai") or "*" for any origin
+	AllowedOrigins []string
+	// AllowCredentials allows cookies and Authorization headers on cross-origin
+	// requests; it cannot be combined with "*"
+	AllowCredentials bool
+	// MaxAge is how long preflight results may be cached, in seconds
+	MaxAge int
+}
+
+const (
+	allowedMethods = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+	allowedHeaders = "Authorization, Content-Type, If-Match, If-None-Match, If-Modified-Since, X-Request-ID, X-Vendor-API-Key"
+	exposedHeaders = "ETag, Last-Modified, Location, X-Request-ID"
+)
+
+// originPattern is a parsed allowlist entry
+type originPattern struct {
+	scheme string
+	host   string // exact host[:port], or the suffix after "*." for wildcards
+	any    bool
+	suffix bool
+}
+
+func (p originPattern) matches(scheme, host string) bool {
+	if p.any {
+		return true
+	}
+	if scheme != p.scheme {
+		return false
+	}
+	if p.suffix {
+		return strings.HasSuffix(host, "."+p.host)
+	}
+	return host == p.host
+}
+
+// Policy is a validated CORS policy
+type Policy struct {
+	patterns         []originPattern
+	allowAny         bool
+	allowCredentials bool
+	maxAge           int
+}
+
+// New validates cfg and builds a policy. Unsafe or malformed configurations are
+// rejected instead of being served with a weaker policy.
+func New(cfg Config) (*Policy, error) {
+	p := &Policy{allowCredentials: cfg.AllowCredentials, maxAge: cfg.MaxAge}
+
+	for _, raw := range cfg.AllowedOrigins {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		if raw == "*" {
+			if cfg.AllowCredentials {
+				return nil, fmt.Errorf(`CORS origin "*" cannot be combined with allow-credentials`)
+			}
+			p.allowAny = true
+			p.patterns = append(p.patterns, originPattern{any: true})
+			continue
+		}
+
+		pattern, err := parseOrigin(raw)
+		if err != nil {
+			return nil, err
+		}
+		p.patterns = append(p.patterns, pattern)
+	}
+
+	return p, nil
+}
+
+// parseOrigin parses "scheme://host[:port]" where host may start with "*."
+func parseOrigin(raw string) (originPattern, error) {
+	scheme, host, ok := strings.Cut(strings.ToLower(raw), "://")
+	if !ok || (scheme != "http" && scheme != "https") {
+		return originPattern{}, fmt.Errorf("invalid CORS origin %q: must be http(s)://host[:port]", raw)
+	}
+	if host == "" || strings.ContainsAny(host, "/?#@ ") {
+		return originPattern{}, fmt.Errorf("invalid CORS origin %q: must not contain a path, query or credentials", raw)
+	}
+
+	pattern := originPattern{scheme: scheme, host: host}
+	if strings.HasPrefix(host, "*.") {
+		pattern.suffix = true
+		pattern.host = strings.TrimPrefix(host, "*.")
+	}
+
+	// The remaining host must be a concrete domain, e.g. reject "https://*" or "https://*.com"
+	if strings.Contains(pattern.host, "*") || (pattern.suffix && !strings.Contains(pattern.host, ".")) {
+		return originPattern{}, fmt.Errorf("invalid CORS origin %q: wildcards are only allowed as the leftmost label of a domain", raw)
+	}
+	if _, err := url.Parse(scheme + "://" + pattern.host); err != nil {
+		return originPattern{}, fmt.Errorf("invalid CORS origin %q: %v", raw, err)
+	}
+
+	return pattern, nil
+}
+
+// Allowed reports whether a request Origin is allowed
+func (p *Policy) Allowed(origin string) bool {
+	scheme, host, ok := strings.Cut(strings.ToLower(origin), "://")
+	if !ok {
+		return false
+	}
+	for _, pattern := range p.patterns {
+		if pattern.matches(scheme, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler returns middleware applying the policy. Preflight requests from
+// disallowed origins are rejected with 403; other requests from disallowed
+// origins are served without CORS headers, so browsers block the response.
+func (p *Policy) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+		w.Header().Add("Vary", "Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !p.Allowed(origin) {
+			if preflight {
+				http.Error(w, "CORS origin not allowed", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if p.allowAny && !p.allowCredentials {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		if p.allowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			w.Header().Set("Access-Control-Allow-Methods", allowedMethods)
+			w.Header().Set("Access-Control-Allow-Headers", allowedHeaders)
+			if p.maxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(p.maxAge))
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set("Access-Control-Expose-Headers", exposedHeaders)
+		next.ServeHTTP(w, r)
+	})
+}
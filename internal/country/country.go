@@ -0,0 +1,185 @@
+// Package country describes what a complete vendor looks like in each country.
+// A Profile names the fields vendors of a country must have, may have and must
+// not have, and the formats their values follow. Profiles are read from
+// profiles.json, so covering another country only takes a new entry there;
+// countries without one get the "default" profile.
+package country
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Fields are the vendor fields a profile may name: the text fields of a
+// vendor, by their JSON key and column
+var Fields = []string{
+	"legal_name", "doing_business_as", "tax_id", "email", "remittance_email", "phone", "fax", "website",
+	"address_line1", "address_line2", "city", "state_province", "postal_code",
+}
+
+// DefaultProfile names the profile of countries without their own
+const DefaultProfile = "default"
+
+// Format is the format of a field's value, matched against the value
+// upper-cased and without spaces
+type Format struct {
+	Pattern string `json:"pattern"`
+	// Example is a valid value shown in messages
+	Example string `json:"example"`
+	// Description names the kind of value, e.g. "VAT number"
+	Description string `json:"description,omitempty"`
+
+	re *regexp.Regexp
+}
+
+// Profile is what vendors of a country must, may and must not have. Fields a
+// profile does not name are optional.
+type Profile struct {
+	// Country is the ISO 3166-1 alpha-2 code, or DefaultProfile
+	Country   string             `json:"country"`
+	Required  []string           `json:"required,omitempty"`
+	Optional  []string           `json:"optional,omitempty"`
+	Forbidden []string           `json:"forbidden,omitempty"`
+	Formats   map[string]*Format `json:"formats,omitempty"`
+}
+
+// Issue is a field of a vendor that does not meet its country's profile
+type Issue struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+//go:embed profiles.json
+var profilesFile embed.FS
+
+// profiles are the profiles by country, including DefaultProfile
+var profiles = loadProfiles("profiles.json")
+
+// loadProfiles reads and checks the profiles of a file. The file ships with
+// the service, so a broken one panics.
+func loadProfiles(name string) map[string]*Profile {
+	data, err := profilesFile.ReadFile(name)
+	if err != nil {
+		panic(fmt.Sprintf("country: read %s: %v", name, err))
+	}
+	loaded := make(map[string]*Profile)
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		panic(fmt.Sprintf("country: parse %s: %v", name, err))
+	}
+	if loaded[DefaultProfile] == nil {
+		panic(fmt.Sprintf("country: %s has no %q profile", name, DefaultProfile))
+	}
+
+	known := make(map[string]bool, len(Fields))
+	for _, field := range Fields {
+		known[field] = true
+	}
+	for code, profile := range loaded {
+		if code != DefaultProfile && (len(code) != 2 || strings.ToUpper(code) != code) {
+			panic(fmt.Sprintf("country: %s: %q is not an upper-case ISO country code", name, code))
+		}
+		profile.Country = code
+
+		listed := make(map[string]string)
+		for rule, fields := range map[string][]string{"required": profile.Required, "optional": profile.Optional, "forbidden": profile.Forbidden} {
+			for _, field := range fields {
+				if !known[field] {
+					panic(fmt.Sprintf("country: %s: %s: unknown field %q", name, code, field))
+				}
+				if other, ok := listed[field]; ok {
+					panic(fmt.Sprintf("country: %s: %s: %q is both %s and %s", name, code, field, other, rule))
+				}
+				listed[field] = rule
+			}
+		}
+		for field, format := range profile.Formats {
+			if !known[field] || listed[field] == "forbidden" {
+				panic(fmt.Sprintf("country: %s: %s: format of unknown or forbidden field %q", name, code, field))
+			}
+			if format.re, err = regexp.Compile(format.Pattern); err != nil {
+				panic(fmt.Sprintf("country: %s: %s: format of %s: %v", name, code, field, err))
+			}
+		}
+	}
+	return loaded
+}
+
+// Lookup returns the profile of a country, or the default profile
+func Lookup(country string) *Profile {
+	if profile, ok := profiles[strings.ToUpper(strings.TrimSpace(country))]; ok {
+		return profile
+	}
+	return profiles[DefaultProfile]
+}
+
+// Profiles returns every profile, the default one first and the others by
+// country code
+func Profiles() []*Profile {
+	list := make([]*Profile, 0, len(profiles))
+	for _, profile := range profiles {
+		list = append(list, profile)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if (list[i].Country == DefaultProfile) != (list[j].Country == DefaultProfile) {
+			return list[i].Country == DefaultProfile
+		}
+		return list[i].Country < list[j].Country
+	})
+	return list
+}
+
+// NormalizeValue returns a value as formats match it: trimmed, upper-cased and
+// without spaces
+func NormalizeValue(value string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(value), " ", ""))
+}
+
+// Check returns the fields of a vendor that do not meet the profile. value
+// returns the value of a field, empty when unset.
+func (p *Profile) Check(value func(field string) string) []Issue {
+	var issues []Issue
+	for _, field := range p.Required {
+		if strings.TrimSpace(value(field)) == "" {
+			issues = append(issues, Issue{Field: field, Message: fmt.Sprintf("%s is required for vendors in %s", field, p.name())})
+		}
+	}
+	for _, field := range p.Forbidden {
+		if strings.TrimSpace(value(field)) != "" {
+			issues = append(issues, Issue{Field: field, Message: fmt.Sprintf("%s is not used for vendors in %s and must be empty", field, p.name())})
+		}
+	}
+
+	fields := make([]string, 0, len(p.Formats))
+	for field := range p.Formats {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		format := p.Formats[field]
+		normalized := NormalizeValue(value(field))
+		if normalized == "" || format.re.MatchString(normalized) {
+			continue
+		}
+		kind := format.Description
+		if kind == "" {
+			kind = field
+		}
+		issues = append(issues, Issue{
+			Field:   field,
+			Message: fmt.Sprintf("%q is not a valid %s for vendors in %s (expected e.g. %s)", value(field), kind, p.name(), format.Example),
+		})
+	}
+	return issues
+}
+
+// name is how messages refer to the countries of the profile
+func (p *Profile) name() string {
+	if p.Country == DefaultProfile {
+		return "any country"
+	}
+	return p.Country
+}
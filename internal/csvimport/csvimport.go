@@ -0,0 +1,214 @@
+// Package csvimport reads spreadsheet uploads: CSV files with a header row
+// naming the columns, as exported by the service and by common spreadsheet
+// tools.
+package csvimport
+
+import (
+	"bufio"
+	"encoding/csv"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Row is a data row of an upload
+type Row struct {
+	// Line is the line of the row in the file, the header being line 1
+	Line   int
+	values map[string]string
+}
+
+// Get returns the trimmed value of column, or "" when the file has no such column
+func (r Row) Get(column string) string {
+	return r.values[column]
+}
+
+// ErrTooManyRows is returned by Read for files with more than maxRows rows
+var ErrTooManyRows = stderrors.New("too many rows")
+
+// Value transforms a Mapping can apply to a column
+const (
+	TransformStripLeadingZeros = "strip_leading_zeros"
+	TransformUppercase         = "uppercase"
+	TransformLowercase         = "lowercase"
+)
+
+// transforms are the value transforms by name
+var transforms = map[string]func(string) string{
+	TransformStripLeadingZeros: func(s string) string {
+		if trimmed := strings.TrimLeft(s, "0"); trimmed != "" || s == "" {
+			return trimmed
+		}
+		return "0"
+	},
+	TransformUppercase: strings.ToUpper,
+	TransformLowercase: strings.ToLower,
+}
+
+// IsTransform reports whether name is a known value transform
+func IsTransform(name string) bool {
+	_, ok := transforms[name]
+	return ok
+}
+
+// Mapping adapts an upload whose header does not use the column names, such
+// as an ERP export, to the columns of an import
+type Mapping struct {
+	// Columns maps source header names to columns; source columns it does not
+	// name are ignored
+	Columns map[string]string
+	// Defaults are the values of columns the file leaves out or blank
+	Defaults map[string]string
+	// Transforms are applied to the values of columns, in order
+	Transforms map[string][]string
+}
+
+// HeaderName normalizes a header name the way Read matches it: lowercase, with
+// spaces treated as underscores
+func HeaderName(raw string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(raw)), " ", "_")
+}
+
+// Read parses a CSV upload. Header names are matched case-insensitively with
+// spaces treated as underscores; the header must name every required column and
+// only known columns. Blank rows are skipped.
+func Read(src io.Reader, columns, required []string, maxRows int) ([]Row, error) {
+	return ReadMapped(src, nil, columns, required, maxRows)
+}
+
+// ReadMapped is Read for an upload whose header is translated by m; without m
+// it is Read. Required columns may be missing from the file when m has a
+// default for them. The mapping is expected to name known columns only.
+func ReadMapped(src io.Reader, m *Mapping, columns, required []string, maxRows int) ([]Row, error) {
+	r := csv.NewReader(stripBOM(src))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+
+	known := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		known[column] = true
+	}
+	var sources map[string]string
+	if m != nil {
+		sources = make(map[string]string, len(m.Columns))
+		for source, column := range m.Columns {
+			sources[HeaderName(source)] = column
+		}
+	}
+
+	names := make([]string, len(header))
+	seen := make(map[string]bool, len(header))
+	var unknown []string
+	for i, raw := range header {
+		name := HeaderName(raw)
+		if m != nil {
+			// Source columns the mapping leaves out are ignored
+			if name = sources[name]; name == "" {
+				continue
+			}
+		}
+		switch {
+		case !known[name]:
+			unknown = append(unknown, raw)
+		case seen[name]:
+			return nil, fmt.Errorf("column %s appears more than once", name)
+		}
+		names[i] = name
+		seen[name] = true
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown columns: %s (expected %s)", strings.Join(unknown, ", "), strings.Join(columns, ", "))
+	}
+
+	var missing []string
+	for _, column := range required {
+		if !seen[column] && (m == nil || m.Defaults[column] == "") {
+			missing = append(missing, column)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("missing required columns: %s", strings.Join(missing, ", "))
+	}
+
+	var rows []Row
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		line, _ := r.FieldPos(0)
+
+		values := make(map[string]string, len(names))
+		blank := true
+		for i, name := range names {
+			if name == "" {
+				continue
+			}
+			var value string
+			if i < len(record) {
+				value = strings.TrimSpace(record[i])
+			}
+			values[name] = value
+			if value != "" {
+				blank = false
+			}
+		}
+		if blank {
+			continue
+		}
+		if m != nil {
+			m.apply(values)
+		}
+
+		if len(rows) == maxRows {
+			return nil, fmt.Errorf("%w: at most %d rows can be imported at once", ErrTooManyRows, maxRows)
+		}
+		rows = append(rows, Row{Line: line, values: values})
+	}
+
+	return rows, nil
+}
+
+// apply fills in the defaults of blank columns and transforms the values of a row
+func (m *Mapping) apply(values map[string]string) {
+	for column, value := range m.Defaults {
+		if values[column] == "" {
+			values[column] = value
+		}
+	}
+	for column, names := range m.Transforms {
+		value, ok := values[column]
+		if !ok {
+			continue
+		}
+		for _, name := range names {
+			if transform := transforms[name]; transform != nil {
+				value = transform(value)
+			}
+		}
+		values[column] = value
+	}
+}
+
+// stripBOM drops the byte order mark spreadsheet tools put in front of UTF-8 exports
+func stripBOM(src io.Reader) io.Reader {
+	br := bufio.NewReader(src)
+	if bom, err := br.Peek(3); err == nil && string(bom) == "\xef\xbb\xbf" {
+		br.Discard(3)
+	}
+	return br
+}
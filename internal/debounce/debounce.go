@@ -0,0 +1,125 @@
+// Package debounce remembers recently seen keys for a short time, so that
+// repeated submissions of the same request can be recognized. Memory keeps the
+// keys in process; Redis shares them between replicas.
+package debounce
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Store remembers keys with a value until their TTL expires
+type Store interface {
+	// Claim stores value under key unless the key is already present. It
+	// returns claimed=false and the present value when it is.
+	Claim(ctx context.Context, key, value string, ttl time.Duration) (existing string, claimed bool, err error)
+	// Set stores value under key, replacing any present value
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	// Delete forgets key
+	Delete(ctx context.Context, key string) error
+	// Take returns the value of key and forgets it, so that only one caller
+	// gets it. ok is false when the key is absent or expired.
+	Take(ctx context.Context, key string) (value string, ok bool, err error)
+}
+
+var _ Store = (*Memory)(nil)
+
+// Memory is an in-process Store holding at most size keys, evicting the least
+// recently stored ones first
+type Memory struct {
+	size int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type memoryEntry struct {
+	key       string
+	value     string
+	expiresAt time.Time
+}
+
+// NewMemory creates an in-process store holding at most size keys
+func NewMemory(size int) *Memory {
+	return &Memory{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// Claim stores value under key unless an unexpired value is present
+func (m *Memory) Claim(ctx context.Context, key, value string, ttl time.Duration) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if elem, ok := m.entries[key]; ok {
+		entry := elem.Value.(*memoryEntry)
+		if time.Now().Before(entry.expiresAt) {
+			return entry.value, false, nil
+		}
+	}
+	m.put(key, value, ttl)
+	return "", true, nil
+}
+
+// Set stores value under key
+func (m *Memory) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.put(key, value, ttl)
+	return nil
+}
+
+// Delete forgets key
+func (m *Memory) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if elem, ok := m.entries[key]; ok {
+		m.order.Remove(elem)
+		delete(m.entries, key)
+	}
+	return nil
+}
+
+// Take returns the unexpired value of key and forgets it
+func (m *Memory) Take(ctx context.Context, key string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, ok := m.entries[key]
+	if !ok {
+		return "", false, nil
+	}
+	m.order.Remove(elem)
+	delete(m.entries, key)
+
+	entry := elem.Value.(*memoryEntry)
+	if !time.Now().Before(entry.expiresAt) {
+		return "", false, nil
+	}
+	return entry.value, true, nil
+}
+
+// put stores an entry as the most recent one, evicting the oldest entries
+// beyond size. Callers hold m.mu.
+func (m *Memory) put(key, value string, ttl time.Duration) {
+	entry := &memoryEntry{key: key, value: value, expiresAt: time.Now().Add(ttl)}
+	if elem, ok := m.entries[key]; ok {
+		elem.Value = entry
+		m.order.MoveToFront(elem)
+		return
+	}
+
+	m.entries[key] = m.order.PushFront(entry)
+	for m.order.Len() > m.size {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryEntry).key)
+	}
+}
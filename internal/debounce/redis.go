@@ -0,0 +1,219 @@
+package debounce
+
+import (
+	"bufio"
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxIdleConns bounds the connections kept open between commands
+const maxIdleConns = 4
+
+// errNil is the reply to commands finding no value
+var errNil = stderrors.New("redis: nil")
+
+var _ Store = (*Redis)(nil)
+
+// Redis is a Store shared between replicas, kept in a Redis server. It speaks
+// the few commands it needs over the Redis protocol directly.
+type Redis struct {
+	addr     string
+	password string
+	db       int
+	timeout  time.Duration
+	idle     chan *redisConn
+}
+
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// NewRedis creates a store for a redis://[:password@]host:port[/db] URL. Every
+// command is bounded by timeout.
+func NewRedis(rawURL string, timeout time.Duration) (*Redis, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "redis" || u.Host == "" {
+		return nil, fmt.Errorf("invalid redis URL (expected redis://[:password@]host:port[/db])")
+	}
+
+	r := &Redis{
+		addr:    u.Host,
+		timeout: timeout,
+		idle:    make(chan *redisConn, maxIdleConns),
+	}
+	if !strings.Contains(u.Host, ":") {
+		r.addr = u.Host + ":6379"
+	}
+	if password, ok := u.User.Password(); ok {
+		r.password = password
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		if r.db, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid redis database %q", db)
+		}
+	}
+	return r, nil
+}
+
+// Claim stores value under key with SET NX, reading the present value when the
+// key exists
+func (r *Redis) Claim(ctx context.Context, key, value string, ttl time.Duration) (string, bool, error) {
+	_, err := r.do(ctx, "SET", key, value, "NX", "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	if err == nil {
+		return "", true, nil
+	}
+	if err != errNil {
+		return "", false, err
+	}
+
+	existing, err := r.do(ctx, "GET", key)
+	if err == errNil {
+		// Expired in between; the next attempt claims it
+		return "", false, nil
+	}
+	return existing, false, err
+}
+
+// Set stores value under key
+func (r *Redis) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	_, err := r.do(ctx, "SET", key, value, "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	return err
+}
+
+// Delete forgets key
+func (r *Redis) Delete(ctx context.Context, key string) error {
+	_, err := r.do(ctx, "DEL", key)
+	return err
+}
+
+// Take reads and deletes key with GETDEL (Redis 6.2 or later)
+func (r *Redis) Take(ctx context.Context, key string) (string, bool, error) {
+	value, err := r.do(ctx, "GETDEL", key)
+	if err == errNil {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// do runs a command on an idle or new connection and returns its reply.
+// Connections failing with I/O errors are dropped.
+func (r *Redis) do(ctx context.Context, args ...string) (string, error) {
+	c, err := r.conn(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	deadline := time.Now().Add(r.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	c.conn.SetDeadline(deadline)
+
+	reply, err := c.command(args...)
+	var replyErr redisError
+	if err != nil && err != errNil && !stderrors.As(err, &replyErr) {
+		c.conn.Close()
+		return "", fmt.Errorf("redis %s: %w", args[0], err)
+	}
+
+	select {
+	case r.idle <- c:
+	default:
+		c.conn.Close()
+	}
+	return reply, err
+}
+
+// conn returns an idle connection or dials a new one
+func (r *Redis) conn(ctx context.Context) (*redisConn, error) {
+	select {
+	case c := <-r.idle:
+		return c, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: r.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", r.addr)
+	if err != nil {
+		return nil, fmt.Errorf("redis connect: %w", err)
+	}
+	c := &redisConn{conn: conn, r: bufio.NewReader(conn)}
+	conn.SetDeadline(time.Now().Add(r.timeout))
+
+	if r.password != "" {
+		if _, err := c.command("AUTH", r.password); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("redis auth: %w", err)
+		}
+	}
+	if r.db != 0 {
+		if _, err := c.command("SELECT", strconv.Itoa(r.db)); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("redis select: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// redisError is an error reply of the server
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// command writes a command as an array of bulk strings and reads the reply
+func (c *redisConn) command(args ...string) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := c.conn.Write([]byte(b.String())); err != nil {
+		return "", err
+	}
+	return c.reply()
+}
+
+// reply reads a simple string, error, integer or bulk string reply
+func (c *redisConn) reply() (string, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return "", fmt.Errorf("empty reply")
+	}
+
+	switch line[0] {
+	case '+', ':':
+		return line[1:], nil
+	case '-':
+		return "", redisError(line[1:])
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return "", fmt.Errorf("invalid bulk length %q", line[1:])
+		}
+		if n < 0 {
+			return "", errNil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return "", err
+		}
+		return string(buf[:n]), nil
+	default:
+		return "", fmt.Errorf("unexpected reply %q", line)
+	}
+}
@@ -0,0 +1,185 @@
+// Package docstore reads the files of vendor documents from the storage
+// holding them. HTTP serves them from an HTTP object storage endpoint, such as
+// an S3-compatible gateway; other backends only need to implement Store.
+package docstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned by Open for a document without a file
+	ErrNotFound = errors.New("document file not found")
+	// ErrSigningUnsupported is returned by SignURL when the store cannot sign
+	// links; its documents can only be streamed
+	ErrSigningUnsupported = errors.New("document store cannot sign links")
+	// ErrRangeNotSatisfiable is returned by Open for a byte range outside the
+	// file
+	ErrRangeNotSatisfiable = errors.New("requested range not satisfiable")
+)
+
+// Object is an opened document file. Body holds the requested range when
+// ContentRange is set, otherwise the whole file.
+type Object struct {
+	Body        io.ReadCloser
+	ContentType string
+	// ContentLength is the size of Body, -1 when unknown
+	ContentLength int64
+	// ContentRange is the Content-Range of a partial read, e.g.
+	// "bytes 0-1023/4096"
+	ContentRange string
+}
+
+// Store holds the files of vendor documents by their document_url
+type Store interface {
+	// SignURL returns a link downloading the file of documentURL without
+	// credentials until expires, or ErrSigningUnsupported
+	SignURL(ctx context.Context, documentURL string, expires time.Time) (string, error)
+	// Open reads the file of documentURL, or the byteRange of it when set (a
+	// Range header value such as "bytes=0-1023")
+	Open(ctx context.Context, documentURL, byteRange string) (*Object, error)
+}
+
+var _ Store = (*HTTP)(nil)
+
+// HTTP is a Store reading documents from an HTTP object storage endpoint.
+// Document URLs are resolved under the base URL: "s3://bucket/key" maps to
+// {base}/bucket/key, relative keys to {base}/key, and absolute URLs must
+// already point under the base, so that a document row cannot make the
+// service fetch arbitrary hosts.
+//
+// Links are signed by appending expires (a Unix timestamp) and signature, the
+// hex HMAC-SHA256 of "{path}\n{expires}" under the signing key, which the
+// storage endpoint verifies.
+type HTTP struct {
+	base       *url.URL
+	signingKey []byte
+	client     *http.Client
+}
+
+// NewHTTP creates a store reading documents under baseURL. Without a signing
+// key documents are only streamed; timeout bounds the wait for response
+// headers, not the transfer of the file.
+func NewHTTP(baseURL string, signingKey []byte, timeout time.Duration) (*HTTP, error) {
+	base, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, fmt.Errorf("document storage URL must be an absolute http(s) URL, got %q", baseURL)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = timeout
+	return &HTTP{
+		base:       base,
+		signingKey: signingKey,
+		client:     &http.Client{Transport: transport},
+	}, nil
+}
+
+// resolve returns the storage URL of a document URL
+func (h *HTTP) resolve(documentURL string) (*url.URL, error) {
+	u, err := url.Parse(documentURL)
+	if err != nil || documentURL == "" {
+		return nil, fmt.Errorf("invalid document URL %q", documentURL)
+	}
+
+	var key string
+	switch u.Scheme {
+	case "s3":
+		key = u.Host + "/" + strings.TrimPrefix(u.Path, "/")
+	case "":
+		if u.Host != "" {
+			return nil, fmt.Errorf("invalid document URL %q", documentURL)
+		}
+		key = strings.TrimPrefix(u.Path, "/")
+	case "http", "https":
+		prefix := strings.TrimRight(h.base.Path, "/") + "/"
+		if u.Scheme != h.base.Scheme || u.Host != h.base.Host || !strings.HasPrefix(u.Path, prefix) {
+			return nil, fmt.Errorf("document URL %q is outside the document storage", documentURL)
+		}
+		key = strings.TrimPrefix(u.Path, prefix)
+	default:
+		return nil, fmt.Errorf("unsupported document URL scheme %q", u.Scheme)
+	}
+
+	clean := path.Clean("/" + key)
+	if key == "" || clean == "/" || clean != "/"+key {
+		return nil, fmt.Errorf("invalid document key %q", key)
+	}
+	resolved := *h.base
+	resolved.Path = strings.TrimRight(h.base.Path, "/") + clean
+	resolved.RawQuery = ""
+	return &resolved, nil
+}
+
+// SignURL signs the storage URL of a document
+func (h *HTTP) SignURL(ctx context.Context, documentURL string, expires time.Time) (string, error) {
+	if len(h.signingKey) == 0 {
+		return "", ErrSigningUnsupported
+	}
+	u, err := h.resolve(documentURL)
+	if err != nil {
+		return "", err
+	}
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	mac := hmac.New(sha256.New, h.signingKey)
+	mac.Write([]byte(u.Path + "\n" + exp))
+
+	q := url.Values{}
+	q.Set("expires", exp)
+	q.Set("signature", hex.EncodeToString(mac.Sum(nil)))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// Open fetches a document, passing byteRange on as the Range header
+func (h *HTTP) Open(ctx context.Context, documentURL, byteRange string) (*Object, error) {
+	u, err := h.resolve(documentURL)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if byteRange != "" {
+		req.Header.Set("Range", byteRange)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch document: %w", err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusPartialContent:
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	case http.StatusRequestedRangeNotSatisfiable:
+		resp.Body.Close()
+		return nil, ErrRangeNotSatisfiable
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("fetch document: storage answered %s", resp.Status)
+	}
+
+	obj := &Object{
+		Body:          resp.Body,
+		ContentType:   resp.Header.Get("Content-Type"),
+		ContentLength: resp.ContentLength,
+	}
+	if resp.StatusCode == http.StatusPartialContent {
+		obj.ContentRange = resp.Header.Get("Content-Range")
+	}
+	return obj, nil
+}
@@ -0,0 +1,32 @@
+// Package entityevents reads entity lifecycle events from the platform event
+// bus. A Source returns the events after a cursor; HTTPSource polls the
+// platform's event feed.
+package entityevents
+
+import (
+	"context"
+	"time"
+)
+
+// Entity lifecycle event types handled by the service
+const (
+	TypeDeleted     = "entity.deleted"
+	TypeSuspended   = "entity.suspended"
+	TypeReactivated = "entity.reactivated"
+)
+
+// Event is an entity lifecycle event
+type Event struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	EntityID   string    `json:"entity_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Source reads entity lifecycle events in order
+type Source interface {
+	// Poll returns the events after cursor and the cursor to continue from.
+	// An empty cursor reads from the start of the stream; no events and an
+	// unchanged cursor mean the consumer is up to date.
+	Poll(ctx context.Context, cursor string) ([]Event, string, error)
+}
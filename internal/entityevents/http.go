@@ -0,0 +1,86 @@
+package entityevents
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// HTTPSource polls the platform event feed over HTTP. It GETs
+//
+//	<url>?types=entity.deleted,entity.suspended,entity.reactivated&cursor=<cursor>&limit=<limit>
+//
+// and the feed answers with the events after the cursor, oldest first:
+//
+//	{"events": [{"id": "...", "type": "entity.deleted", "entity_id": "..."}], "next_cursor": "..."}
+type HTTPSource struct {
+	url    string
+	apiKey string
+	limit  int
+	client *http.Client
+}
+
+// NewHTTPSource creates a source polling url, sending apiKey as a bearer
+// token when set and asking for at most limit events a poll
+func NewHTTPSource(url, apiKey string, limit int, timeout time.Duration) *HTTPSource {
+	return &HTTPSource{
+		url:    url,
+		apiKey: apiKey,
+		limit:  max(limit, 1),
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+type feedResponse struct {
+	Events     []Event `json:"events"`
+	NextCursor string  `json:"next_cursor"`
+}
+
+// Poll returns the events after cursor
+func (h *HTTPSource) Poll(ctx context.Context, cursor string) ([]Event, string, error) {
+	u, err := url.Parse(h.url)
+	if err != nil {
+		return nil, cursor, fmt.Errorf("parse entity event feed URL: %w", err)
+	}
+	q := u.Query()
+	q.Set("types", TypeDeleted+","+TypeSuspended+","+TypeReactivated)
+	q.Set("limit", strconv.Itoa(h.limit))
+	if cursor != "" {
+		q.Set("cursor", cursor)
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, cursor, fmt.Errorf("build entity event feed request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if h.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+h.apiKey)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, cursor, fmt.Errorf("call entity event feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		return nil, cursor, fmt.Errorf("entity event feed returned %s", resp.Status)
+	}
+
+	var result feedResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(&result); err != nil {
+		return nil, cursor, fmt.Errorf("decode entity event feed response: %w", err)
+	}
+	if result.NextCursor == "" {
+		result.NextCursor = cursor
+	}
+	return result.Events, result.NextCursor, nil
+}
@@ -0,0 +1,149 @@
+// Package filestore stores the files generated by background exports. Dir
+// keeps them in a directory, which the server and the worker must share;
+// Memory keeps them in process. Other backends, such as object storage, only
+// need to implement Store.
+package filestore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound is returned by Open for a key that holds no file
+var ErrNotFound = errors.New("file not found")
+
+// Store holds files by key. Keys are slash-separated paths such as
+// "vendor-exports/{job}/part-00001.csv".
+type Store interface {
+	// Put stores data under key, replacing any file there
+	Put(ctx context.Context, key string, data []byte) error
+	// Open returns the file stored under key, or ErrNotFound
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// DeletePrefix removes every file whose key starts with prefix + "/"
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+var (
+	_ Store = (*Dir)(nil)
+	_ Store = (*Memory)(nil)
+)
+
+// Dir is a Store keeping files under a root directory
+type Dir struct {
+	root string
+}
+
+// NewDir creates a store keeping files under root, which is created if missing
+func NewDir(root string) (*Dir, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("create file store directory: %w", err)
+	}
+	return &Dir{root: root}, nil
+}
+
+// path returns the file path of key, rejecting keys leaving the root
+func (d *Dir) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid file key %q", key)
+	}
+	return filepath.Join(d.root, clean), nil
+}
+
+// Put writes data to a temporary file renamed over key, so that readers never
+// see a partial file
+func (d *Dir) Put(ctx context.Context, key string, data []byte) error {
+	path, err := d.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Open opens the file of key
+func (d *Dir) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := d.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+// DeletePrefix removes the directory of prefix
+func (d *Dir) DeletePrefix(ctx context.Context, prefix string) error {
+	path, err := d.path(prefix)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(path)
+}
+
+// Memory is an in-process Store
+type Memory struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+// NewMemory creates an in-process store
+func NewMemory() *Memory {
+	return &Memory{files: make(map[string][]byte)}
+}
+
+// Put stores a copy of data under key
+func (m *Memory) Put(ctx context.Context, key string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[key] = append([]byte(nil), data...)
+	return nil
+}
+
+// Open returns the file of key
+func (m *Memory) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.files[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// DeletePrefix removes the files under prefix
+func (m *Memory) DeletePrefix(ctx context.Context, prefix string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.files {
+		if strings.HasPrefix(key, prefix+"/") {
+			delete(m.files, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+// Package fx converts amounts between currencies, so that values held in
+// different currencies, such as a credit limit and a balance, can be compared.
+// A Converter converts them; Stub cannot convert anything.
+package fx
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrCannotCompare is returned when a Converter has no rate between two
+// currencies
+var ErrCannotCompare = errors.New("cannot compare amounts in different currencies")
+
+// Converter converts amounts between currencies
+type Converter interface {
+	// Convert converts amount, in the minor units of from, to the minor units
+	// of to. It returns ErrCannotCompare when it has no rate between them.
+	Convert(ctx context.Context, amount int64, from, to string) (int64, error)
+}
+
+// Stub is the default Converter, used when no exchange rates are configured.
+// It converts nothing but amounts already in the target currency.
+type Stub struct{}
+
+// Convert returns amount when from and to are the same currency and
+// ErrCannotCompare otherwise
+func (Stub) Convert(ctx context.Context, amount int64, from, to string) (int64, error) {
+	if from == to {
+		return amount, nil
+	}
+	return 0, ErrCannotCompare
+}
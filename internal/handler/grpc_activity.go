@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+	"github.com/pesio-ai/be-lib-common/auth"
+	pb "github.com/pesio-ai/be-lib-proto/gen/go/ap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GetVendorActivity returns a page of a vendor's activity feed, newest first
+func (h *GRPCHandler) GetVendorActivity(ctx context.Context, req *pb.GetVendorActivityRequest) (*pb.GetVendorActivityResponse, error) {
+	userCtx, err := auth.GetUserContext(ctx)
+	if err != nil {
+		h.log.Warn().Err(err).Msg("User context not found")
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	h.log.Info().
+		Str("id", req.Id).
+		Str("entity_id", req.EntityId).
+		Strs("types", req.Types).
+		Str("user_id", userCtx.UserID).
+		Msg("gRPC GetVendorActivity request")
+
+	if req.EntityId != userCtx.EntityID {
+		h.log.Warn().
+			Str("req_entity_id", req.EntityId).
+			Str("user_entity_id", userCtx.EntityID).
+			Msg("Entity ID mismatch")
+		return nil, status.Error(codes.PermissionDenied, "access denied: entity mismatch")
+	}
+
+	q := service.ActivityQuery{
+		Types:  req.Types,
+		Cursor: req.Cursor,
+		Limit:  int(req.Limit),
+
+		ResolveUsers: req.ResolveUsers,
+	}
+	if req.Since != nil {
+		q.Since = req.Since.AsTime()
+	}
+
+	page, err := h.vendorService.GetVendorActivity(ctx, req.Id, req.EntityId, q, func(section string) bool {
+		return h.opts.Sections.Allows(section, userCtx.UserID)
+	})
+	if err != nil {
+		if !repository.IsNotFound(err) {
+			h.log.Error().Err(err).Msg("Failed to get vendor activity")
+		}
+		return nil, toGRPCError(err)
+	}
+
+	resp := &pb.GetVendorActivityResponse{NextCursor: page.NextCursor}
+	for _, item := range page.Items {
+		payload, _ := structpb.NewStruct(jsonMap(item.Payload))
+		resp.Items = append(resp.Items, &pb.ActivityItem{
+			Id:        item.ID,
+			Type:      item.Type,
+			ActorId:   stringToProto(item.Actor),
+			Timestamp: timestamppb.New(item.Timestamp),
+			Summary:   item.Summary,
+			Payload:   payload,
+			ActorUser: userToProto(item.ActorUser),
+		})
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+	pb "github.com/pesio-ai/be-lib-proto/gen/go/ap"
+)
+
+// GetVendorContacts returns a page of the contacts of a vendor with the
+// number of matching contacts. Page and page size default to 1 and 50.
+func (h *GRPCHandler) GetVendorContacts(ctx context.Context, req *pb.GetVendorContactsRequest) (*pb.GetVendorContactsResponse, error) {
+	h.log.Info().
+		Str("vendor_id", req.VendorId).
+		Int32("page", req.Page).
+		Int32("page_size", req.PageSize).
+		Msg("gRPC GetVendorContacts request")
+
+	page := int(req.Page)
+	pageSize := int(req.PageSize)
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = service.DefaultContactPageSize
+	}
+
+	filter := repository.ContactFilter{
+		VendorID:  req.VendorId,
+		IsPrimary: req.IsPrimary,
+		Sort:      req.Sort,
+	}
+	if req.ContactType != "" {
+		filter.ContactType = &req.ContactType
+	}
+
+	result, err := h.vendorService.ListVendorContacts(ctx, filter, page, pageSize)
+	if err != nil {
+		h.log.Error().Err(err).Msg("Failed to get vendor contacts")
+		return nil, toGRPCError(err)
+	}
+
+	resp := &pb.GetVendorContactsResponse{
+		Contacts: make([]*pb.VendorContact, len(result.Contacts)),
+		Total:    result.Total,
+		Page:     int32(page),
+		PageSize: int32(pageSize),
+	}
+	for i, contact := range result.Contacts {
+		resp.Contacts[i] = contactToProto(contact)
+	}
+	return resp, nil
+}
+
+// GetContactsForCommunication handles gRPC requests resolving the contacts of
+// a vendor receiving a communication type, e.g. for the purchasing and
+// payments services to address purchase orders and remittance advices
+func (h *GRPCHandler) GetContactsForCommunication(ctx context.Context, req *pb.GetContactsForCommunicationRequest) (*pb.GetContactsForCommunicationResponse, error) {
+	h.log.Info().
+		Str("vendor_id", req.VendorId).
+		Str("entity_id", req.EntityId).
+		Str("communication_type", req.CommunicationType).
+		Msg("gRPC GetContactsForCommunication request")
+
+	contacts, err := h.vendorService.GetContactsForCommunication(ctx, req.VendorId, req.EntityId, req.CommunicationType)
+	if err != nil {
+		h.log.Error().Err(err).Msg("Failed to get contacts for communication")
+		return nil, toGRPCError(err)
+	}
+
+	resp := &pb.GetContactsForCommunicationResponse{
+		Contacts: make([]*pb.VendorContact, len(contacts)),
+	}
+	for i, contact := range contacts {
+		resp.Contacts[i] = contactToProto(contact)
+	}
+	return resp, nil
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+	pb "github.com/pesio-ai/be-lib-proto/gen/go/ap"
+)
+
+// The vendor_type and status string fields of the proto are deprecated in
+// favour of the VendorType and VendorStatus enums. Until they are removed,
+// requests may set either; both are converted here to the lowercase codes the
+// service uses, e.g. VENDOR_STATUS_PENDING_APPROVAL and "Pending_Approval" to
+// "pending_approval".
+
+const (
+	vendorTypeEnumPrefix   = "VENDOR_TYPE_"
+	vendorStatusEnumPrefix = "VENDOR_STATUS_"
+)
+
+// enumCode returns the lowercase code of an enum value name, e.g.
+// VENDOR_TYPE_SERVICE_PROVIDER -> service_provider
+func enumCode(name, prefix string) string {
+	return strings.ToLower(strings.TrimPrefix(name, prefix))
+}
+
+// enumCodes lists the codes of every value of an enum but UNSPECIFIED, in
+// enum order
+func enumCodes(names map[int32]string, prefix string) []string {
+	numbers := make([]int, 0, len(names))
+	for number := range names {
+		if number != 0 {
+			numbers = append(numbers, int(number))
+		}
+	}
+	sort.Ints(numbers)
+
+	codes := make([]string, len(numbers))
+	for i, number := range numbers {
+		codes[i] = enumCode(names[int32(number)], prefix)
+	}
+	return codes
+}
+
+// invalidEnumField is the InvalidArgument error of a request field
+func invalidEnumField(field, message string) error {
+	return toGRPCError(&service.ValidationError{Violations: []service.FieldViolation{{Field: field, Message: message}}})
+}
+
+// vendorTypeFromProto resolves the vendor type of a request from its enum or
+// string field; empty when neither is set. Strings that are not built-in
+// types pass through as entity vendor type codes, which the service checks
+// against the entity's registry.
+func vendorTypeFromProto(enum pb.VendorType, value string) (string, error) {
+	code := strings.ToLower(strings.TrimSpace(value))
+	if enum == pb.VendorType_VENDOR_TYPE_UNSPECIFIED {
+		return code, nil
+	}
+
+	name, ok := pb.VendorType_name[int32(enum)]
+	if !ok {
+		return "", invalidEnumField("vendor_type_enum", fmt.Sprintf("unknown vendor type %d, valid types: %s",
+			enum, strings.Join(enumCodes(pb.VendorType_name, vendorTypeEnumPrefix), ", ")))
+	}
+	enumValue := enumCode(name, vendorTypeEnumPrefix)
+	if code != "" && code != enumValue {
+		return "", invalidEnumField("vendor_type", fmt.Sprintf("vendor_type %q contradicts vendor_type_enum %s", value, name))
+	}
+	return enumValue, nil
+}
+
+// vendorTypeToProto returns the enum of a vendor type code, UNSPECIFIED for
+// types the entity added to its registry
+func vendorTypeToProto(code string) pb.VendorType {
+	return pb.VendorType(pb.VendorType_value[vendorTypeEnumPrefix+strings.ToUpper(code)])
+}
+
+// vendorStatusFromProto resolves the vendor status of a request from its enum
+// or string field; empty when neither is set
+func vendorStatusFromProto(enum pb.VendorStatus, value string) (string, error) {
+	code := strings.ToLower(strings.TrimSpace(value))
+	if code != "" {
+		if _, ok := pb.VendorStatus_value[vendorStatusEnumPrefix+strings.ToUpper(code)]; !ok || code == "unspecified" {
+			return "", invalidEnumField("status", fmt.Sprintf("unknown status %q, valid statuses: %s",
+				value, strings.Join(enumCodes(pb.VendorStatus_name, vendorStatusEnumPrefix), ", ")))
+		}
+	}
+	if enum == pb.VendorStatus_VENDOR_STATUS_UNSPECIFIED {
+		return code, nil
+	}
+
+	name, ok := pb.VendorStatus_name[int32(enum)]
+	if !ok {
+		return "", invalidEnumField("status_enum", fmt.Sprintf("unknown status %d, valid statuses: %s",
+			enum, strings.Join(enumCodes(pb.VendorStatus_name, vendorStatusEnumPrefix), ", ")))
+	}
+	enumValue := enumCode(name, vendorStatusEnumPrefix)
+	if code != "" && code != enumValue {
+		return "", invalidEnumField("status", fmt.Sprintf("status %q contradicts status_enum %s", value, name))
+	}
+	return enumValue, nil
+}
+
+// vendorStatusToProto returns the enum of a vendor status
+func vendorStatusToProto(status string) pb.VendorStatus {
+	return pb.VendorStatus(pb.VendorStatus_value[vendorStatusEnumPrefix+strings.ToUpper(status)])
+}
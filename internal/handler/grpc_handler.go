@@ -2,30 +2,55 @@ package handler
 
 import (
 	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/pesio-ai/be-ap-vendors/internal/authz"
+	"github.com/pesio-ai/be-ap-vendors/internal/identity"
 	"github.com/pesio-ai/be-lib-common/auth"
+	"github.com/pesio-ai/be-lib-common/errors"
 	"github.com/pesio-ai/be-lib-common/logger"
 	commonpb "github.com/pesio-ai/be-lib-proto/gen/go/common"
 	pb "github.com/pesio-ai/be-lib-proto/gen/go/ap"
 	"github.com/pesio-ai/be-ap-vendors/internal/repository"
 	"github.com/pesio-ai/be-ap-vendors/internal/service"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// GRPCOptions configures optional gRPC handler behaviour
+type GRPCOptions struct {
+	// WatchHeartbeatInterval is how often WatchVendors streams send heartbeats
+	WatchHeartbeatInterval time.Duration
+	// Admins authorizes admin-only RPCs; nil denies them to everyone
+	Admins *authz.AdminPolicy
+	// Sections restricts vendor snapshot sections; nil shows every section
+	Sections *authz.SectionPolicy
+}
+
 // GRPCHandler handles gRPC requests for vendors service
 type GRPCHandler struct {
 	pb.UnimplementedVendorsServiceServer
-	vendorService *service.VendorService
+	vendorService GRPCService
 	log           *logger.Logger
+	opts          GRPCOptions
 }
 
 // NewGRPCHandler creates a new gRPC handler
-func NewGRPCHandler(vendorService *service.VendorService, log *logger.Logger) *GRPCHandler {
+func NewGRPCHandler(vendorService GRPCService, log *logger.Logger, opts GRPCOptions) *GRPCHandler {
+	if opts.WatchHeartbeatInterval <= 0 {
+		opts.WatchHeartbeatInterval = 15 * time.Second
+	}
+
 	return &GRPCHandler{
 		vendorService: vendorService,
 		log:           log,
+		opts:          opts,
 	}
 }
 
@@ -54,37 +79,55 @@ func (h *GRPCHandler) CreateVendor(ctx context.Context, req *pb.CreateVendorRequ
 		return nil, status.Error(codes.PermissionDenied, "access denied: entity mismatch")
 	}
 
+	vendorType, err := vendorTypeFromProto(req.VendorTypeEnum, req.VendorType)
+	if err != nil {
+		return nil, err
+	}
+
 	svcReq := &service.CreateVendorRequest{
-		EntityID:          req.EntityId,
-		VendorCode:        req.VendorCode,
-		VendorName:        req.VendorName,
-		LegalName:         stringPtr(req.LegalName),
-		VendorType:        req.VendorType,
-		TaxID:             stringPtr(req.TaxId),
-		IsTaxExempt:       req.IsTaxExempt,
-		Is1099Vendor:      req.Is_1099Vendor,
-		Email:             stringPtr(req.Email),
-		Phone:             stringPtr(req.Phone),
-		Fax:               stringPtr(req.Fax),
-		Website:           stringPtr(req.Website),
-		AddressLine1:      stringPtr(req.AddressLine1),
-		AddressLine2:      stringPtr(req.AddressLine2),
-		City:              stringPtr(req.City),
-		StateProvince:     stringPtr(req.StateProvince),
-		PostalCode:        stringPtr(req.PostalCode),
-		Country:           req.Country,
-		PaymentTerms:      req.PaymentTerms,
-		PaymentMethod:     stringPtr(req.PaymentMethod),
-		Currency:          req.Currency,
-		CreditLimit:       int64Ptr(req.CreditLimit),
-		BankName:          stringPtr(req.BankName),
-		BankAccountNumber: stringPtr(req.BankAccountNumber),
-		BankRoutingNumber: stringPtr(req.BankRoutingNumber),
-		SwiftCode:         stringPtr(req.SwiftCode),
-		IBAN:              stringPtr(req.Iban),
-		Notes:             stringPtr(req.Notes),
-		Tags:              req.Tags,
-		CreatedBy:         userCtx.UserID, // Use authenticated user ID
+		EntityID:           req.EntityId,
+		VendorCode:         req.VendorCode,
+		VendorName:         req.VendorName,
+		LegalName:          stringPtr(req.LegalName),
+		DoingBusinessAs:    stringPtr(req.DoingBusinessAs),
+		VendorType:         vendorType,
+		TaxID:              stringPtr(req.TaxId),
+		IsTaxExempt:        req.IsTaxExempt,
+		Is1099Vendor:       req.Is_1099Vendor,
+		Email:              stringPtr(req.Email),
+		RemittanceEmail:    stringPtr(req.RemittanceEmail),
+		Phone:              stringPtr(req.Phone),
+		Fax:                stringPtr(req.Fax),
+		Website:            stringPtr(req.Website),
+		AddressLine1:       stringPtr(req.AddressLine1),
+		AddressLine2:       stringPtr(req.AddressLine2),
+		City:               stringPtr(req.City),
+		StateProvince:      stringPtr(req.StateProvince),
+		PostalCode:         stringPtr(req.PostalCode),
+		Country:            req.Country,
+		Locale:             stringPtr(req.Locale),
+		AcceptedCurrencies: req.AcceptedCurrencies,
+		PaymentTerms:       req.PaymentTerms,
+		PaymentMethod:      stringPtr(req.PaymentMethod),
+		Currency:           req.Currency,
+		CreditLimit:        int64Ptr(req.CreditLimit),
+		BankName:           stringPtr(req.BankName),
+		BankAccountNumber:  stringPtr(req.BankAccountNumber),
+		BankRoutingNumber:  stringPtr(req.BankRoutingNumber),
+		SwiftCode:          stringPtr(req.SwiftCode),
+		IBAN:               stringPtr(req.Iban),
+		Notes:              stringPtr(req.Notes),
+		Tags:               req.Tags,
+		TemplateID:         req.TemplateId,
+		CreatedBy:          userCtx.UserID, // Use authenticated user ID
+		Contacts:           contactInputsFromProto(req.Contacts),
+
+		CreditLimitCurrency:         stringPtr(req.CreditLimitCurrency),
+		CreditLimitCurrencyOverride: req.CreditLimitCurrencyOverride,
+
+		Source:          req.Source,
+		SourceReference: stringPtr(req.SourceReference),
+		DefaultSource:   service.VendorSourceAPI,
 	}
 
 	vendor, err := h.vendorService.CreateVendor(ctx, svcReq)
@@ -103,11 +146,29 @@ func (h *GRPCHandler) GetVendor(ctx context.Context, req *pb.GetVendorRequest) (
 		Str("entity_id", req.EntityId).
 		Msg("gRPC GetVendor request")
 
-	vendor, err := h.vendorService.GetVendor(ctx, req.Id, req.EntityId)
+	// Vendor API keys only see the portal view of their own vendor
+	if err := checkVendorKey(ctx, req.Id, req.EntityId); err != nil {
+		return nil, err
+	}
+	_, byVendorKey := authz.VendorKeyFromContext(ctx)
+	expand := req.Expand
+	if req.ResolveUsers {
+		expand = append(expand, service.ExpandUsers)
+	}
+	if byVendorKey {
+		expand = nil
+	}
+
+	vendor, err := h.vendorService.GetVendor(ctx, req.Id, req.EntityId, expand...)
 	if err != nil {
 		h.log.Error().Err(err).Msg("Failed to get vendor")
 		return nil, toGRPCError(err)
 	}
+	if byVendorKey {
+		vendor = service.PortalVendor(vendor)
+	} else if userCtx, err := auth.GetUserContext(ctx); err == nil && userCtx != nil && userCtx.EntityID == req.EntityId {
+		h.vendorService.RecordVendorView(ctx, userCtx.UserID, req.EntityId, vendor.ID)
+	}
 
 	return vendorToProto(vendor), nil
 }
@@ -136,39 +197,59 @@ func (h *GRPCHandler) UpdateVendor(ctx context.Context, req *pb.UpdateVendorRequ
 		return nil, status.Error(codes.PermissionDenied, "access denied: entity mismatch")
 	}
 
+	// Status changes go through the activate, deactivate and suspend RPCs
+	if req.Status != "" || req.StatusEnum != pb.VendorStatus_VENDOR_STATUS_UNSPECIFIED {
+		return nil, toGRPCError(service.CheckWritableFields([]string{"status"}))
+	}
+
+	vendorType, err := vendorTypeFromProto(req.VendorTypeEnum, req.VendorType)
+	if err != nil {
+		return nil, err
+	}
+
 	svcReq := &service.UpdateVendorRequest{
-		ID:                req.Id,
-		EntityID:          req.EntityId,
-		VendorCode:        req.VendorCode,
-		VendorName:        req.VendorName,
-		LegalName:         stringPtr(req.LegalName),
-		VendorType:        req.VendorType,
-		Status:            req.Status,
-		TaxID:             stringPtr(req.TaxId),
-		IsTaxExempt:       req.IsTaxExempt,
-		Is1099Vendor:      req.Is_1099Vendor,
-		Email:             stringPtr(req.Email),
-		Phone:             stringPtr(req.Phone),
-		Fax:               stringPtr(req.Fax),
-		Website:           stringPtr(req.Website),
-		AddressLine1:      stringPtr(req.AddressLine1),
-		AddressLine2:      stringPtr(req.AddressLine2),
-		City:              stringPtr(req.City),
-		StateProvince:     stringPtr(req.StateProvince),
-		PostalCode:        stringPtr(req.PostalCode),
-		Country:           req.Country,
-		PaymentTerms:      req.PaymentTerms,
-		PaymentMethod:     stringPtr(req.PaymentMethod),
-		Currency:          req.Currency,
-		CreditLimit:       int64Ptr(req.CreditLimit),
-		BankName:          stringPtr(req.BankName),
-		BankAccountNumber: stringPtr(req.BankAccountNumber),
-		BankRoutingNumber: stringPtr(req.BankRoutingNumber),
-		SwiftCode:         stringPtr(req.SwiftCode),
-		IBAN:              stringPtr(req.Iban),
-		Notes:             stringPtr(req.Notes),
-		Tags:              req.Tags,
-		UpdatedBy:         userCtx.UserID, // Use authenticated user ID
+		ID:                 req.Id,
+		EntityID:           req.EntityId,
+		VendorCode:         req.VendorCode,
+		VendorName:         req.VendorName,
+		LegalName:          stringPtr(req.LegalName),
+		DoingBusinessAs:    stringPtr(req.DoingBusinessAs),
+		VendorType:         vendorType,
+		TaxID:              stringPtr(req.TaxId),
+		IsTaxExempt:        req.IsTaxExempt,
+		Is1099Vendor:       req.Is_1099Vendor,
+		Email:              stringPtr(req.Email),
+		RemittanceEmail:    stringPtr(req.RemittanceEmail),
+		Phone:              stringPtr(req.Phone),
+		Fax:                stringPtr(req.Fax),
+		Website:            stringPtr(req.Website),
+		AddressLine1:       stringPtr(req.AddressLine1),
+		AddressLine2:       stringPtr(req.AddressLine2),
+		City:               stringPtr(req.City),
+		StateProvince:      stringPtr(req.StateProvince),
+		PostalCode:         stringPtr(req.PostalCode),
+		Country:            req.Country,
+		Locale:             stringPtr(req.Locale),
+		AcceptedCurrencies: req.AcceptedCurrencies,
+		PaymentTerms:       req.PaymentTerms,
+		PaymentMethod:      stringPtr(req.PaymentMethod),
+		Currency:           req.Currency,
+		CreditLimit:        int64Ptr(req.CreditLimit),
+		BankName:           stringPtr(req.BankName),
+		BankAccountNumber:  stringPtr(req.BankAccountNumber),
+		BankRoutingNumber:  stringPtr(req.BankRoutingNumber),
+		SwiftCode:          stringPtr(req.SwiftCode),
+		IBAN:               stringPtr(req.Iban),
+		Notes:              stringPtr(req.Notes),
+		Tags:               req.Tags,
+		UpdatedBy:          userCtx.UserID, // Use authenticated user ID
+		OverrideCodeLock:   h.opts.Admins.IsAdmin(userCtx.UserID),
+
+		CurrencyConversionNote: req.CurrencyConversionNote,
+		OverrideCurrencyLock:   h.opts.Admins.IsAdmin(userCtx.UserID),
+
+		CreditLimitCurrency:         stringPtr(req.CreditLimitCurrency),
+		CreditLimitCurrencyOverride: req.CreditLimitCurrencyOverride,
 	}
 
 	vendor, err := h.vendorService.UpdateVendor(ctx, svcReq)
@@ -180,25 +261,152 @@ func (h *GRPCHandler) UpdateVendor(ctx context.Context, req *pb.UpdateVendorRequ
 	return vendorToProto(vendor), nil
 }
 
-// DeleteVendor deletes a vendor
-func (h *GRPCHandler) DeleteVendor(ctx context.Context, req *pb.DeleteVendorRequest) (*commonpb.Response, error) {
+// UpsertVendor creates or partially updates a vendor keyed on vendor code
+func (h *GRPCHandler) UpsertVendor(ctx context.Context, req *pb.UpsertVendorRequest) (*pb.UpsertVendorResponse, error) {
+	// Extract user context from authenticated request
+	userCtx, err := auth.GetUserContext(ctx)
+	if err != nil {
+		h.log.Warn().Err(err).Msg("User context not found")
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	h.log.Info().
+		Str("entity_id", req.EntityId).
+		Str("vendor_code", req.VendorCode).
+		Str("user_id", userCtx.UserID).
+		Msg("gRPC UpsertVendor request")
+
+	// Verify entity_id matches authenticated user's entity
+	if req.EntityId != userCtx.EntityID {
+		h.log.Warn().
+			Str("req_entity_id", req.EntityId).
+			Str("user_entity_id", userCtx.EntityID).
+			Msg("Entity ID mismatch")
+		return nil, status.Error(codes.PermissionDenied, "access denied: entity mismatch")
+	}
+
+	// An unset vendor type keeps the vendor's on update
+	vendorType := req.VendorType
+	if vendorType != nil || req.VendorTypeEnum != pb.VendorType_VENDOR_TYPE_UNSPECIFIED {
+		var value string
+		if vendorType != nil {
+			value = *vendorType
+		}
+		code, err := vendorTypeFromProto(req.VendorTypeEnum, value)
+		if err != nil {
+			return nil, err
+		}
+		vendorType = &code
+	}
+
+	svcReq := &service.UpsertVendorRequest{
+		EntityID:           req.EntityId,
+		VendorCode:         req.VendorCode,
+		VendorName:         req.VendorName,
+		LegalName:          req.LegalName,
+		DoingBusinessAs:    req.DoingBusinessAs,
+		VendorType:         vendorType,
+		TaxID:              req.TaxId,
+		IsTaxExempt:        req.IsTaxExempt,
+		Is1099Vendor:       req.Is_1099Vendor,
+		Email:              req.Email,
+		RemittanceEmail:    req.RemittanceEmail,
+		Phone:              req.Phone,
+		Fax:                req.Fax,
+		Website:            req.Website,
+		AddressLine1:       req.AddressLine1,
+		AddressLine2:       req.AddressLine2,
+		City:               req.City,
+		StateProvince:      req.StateProvince,
+		PostalCode:         req.PostalCode,
+		Country:            req.Country,
+		Locale:             req.Locale,
+		AcceptedCurrencies: req.AcceptedCurrencies,
+		PaymentTerms:       req.PaymentTerms,
+		PaymentMethod:      req.PaymentMethod,
+		Currency:           req.Currency,
+		CreditLimit:        req.CreditLimit,
+		BankName:           req.BankName,
+		BankAccountNumber:  req.BankAccountNumber,
+		BankRoutingNumber:  req.BankRoutingNumber,
+		SwiftCode:          req.SwiftCode,
+		IBAN:               req.Iban,
+		Notes:              req.Notes,
+		Tags:               req.Tags,
+		UpdatedBy:          userCtx.UserID, // Use authenticated user ID
+
+		CurrencyConversionNote: req.CurrencyConversionNote,
+		OverrideCurrencyLock:   h.opts.Admins.IsAdmin(userCtx.UserID),
+
+		CreditLimitCurrency:         req.CreditLimitCurrency,
+		CreditLimitCurrencyOverride: req.CreditLimitCurrencyOverride,
+
+		Source:          req.Source,
+		SourceReference: req.SourceReference,
+		DefaultSource:   service.VendorSourceERPSync,
+	}
+
+	vendor, created, err := h.vendorService.UpsertVendorByCode(ctx, svcReq)
+	if err != nil {
+		h.log.Error().Err(err).Msg("Failed to upsert vendor")
+		return nil, toGRPCError(err)
+	}
+
+	return &pb.UpsertVendorResponse{
+		Vendor:  vendorToProto(vendor),
+		Created: created,
+	}, nil
+}
+
+// DeleteVendor deletes a vendor, or with dry_run reports what the delete would
+// touch without writing anything
+func (h *GRPCHandler) DeleteVendor(ctx context.Context, req *pb.DeleteVendorRequest) (*pb.DeleteVendorResponse, error) {
 	h.log.Info().
 		Str("id", req.Id).
 		Str("entity_id", req.EntityId).
+		Bool("dry_run", req.DryRun).
 		Msg("gRPC DeleteVendor request")
 
-	err := h.vendorService.DeleteVendor(ctx, req.Id, req.EntityId)
+	report, err := h.vendorService.DeleteVendor(ctx, req.Id, req.EntityId, req.DryRun, req.KeepChildren)
 	if err != nil {
 		h.log.Error().Err(err).Msg("Failed to delete vendor")
 		return nil, toGRPCError(err)
 	}
 
-	return &commonpb.Response{
+	message := "Vendor deleted successfully"
+	if req.DryRun {
+		message = "Dry run: vendor not deleted"
+	}
+	return &pb.DeleteVendorResponse{
 		Success: true,
-		Message: "Vendor deleted successfully",
+		Message: message,
+		Report:  deleteReportToProto(report),
 	}, nil
 }
 
+func deleteReportToProto(report *service.DeleteReport) *pb.DeleteReport {
+	pbReport := &pb.DeleteReport{
+		VendorId:        report.VendorID,
+		DryRun:          report.DryRun,
+		Contacts:        int32(report.Contacts),
+		ExternalRefs:    make(map[string]string, len(report.ExternalRefs)),
+		ActiveApiKeys:   int32(report.ActiveAPIKeys),
+		ChildrenDeleted: report.ChildrenDeleted,
+	}
+	for _, ref := range report.ExternalRefs {
+		pbReport.ExternalRefs[ref.System] = ref.ExternalID
+	}
+	if report.Children != nil {
+		pbReport.Children = &pb.VendorChildren{
+			Contacts:             int32(report.Children.Contacts),
+			Documents:            int32(report.Children.Documents),
+			BankVerifications:    int32(report.Children.BankVerifications),
+			PendingStatusChanges: int32(report.Children.PendingStatusChanges),
+		}
+	}
+	return pbReport
+}
+
 // ListVendors lists vendors with filtering and pagination
 func (h *GRPCHandler) ListVendors(ctx context.Context, req *pb.ListVendorsRequest) (*pb.ListVendorsResponse, error) {
 	h.log.Info().
@@ -207,46 +415,239 @@ func (h *GRPCHandler) ListVendors(ctx context.Context, req *pb.ListVendorsReques
 		Int32("page_size", req.PageSize).
 		Msg("gRPC ListVendors request")
 
+	statusCode, err := vendorStatusFromProto(req.StatusEnum, req.Status)
+	if err != nil {
+		return nil, err
+	}
 	var status *string
-	if req.Status != "" {
-		status = &req.Status
+	if statusCode != "" {
+		status = &statusCode
 	}
 
+	typeCode, err := vendorTypeFromProto(req.VendorTypeEnum, req.VendorType)
+	if err != nil {
+		return nil, err
+	}
 	var vendorType *string
-	if req.VendorType != "" {
-		vendorType = &req.VendorType
+	if typeCode != "" {
+		vendorType = &typeCode
 	}
 
 	page := int(req.Page)
 	pageSize := int(req.PageSize)
-	if page <= 0 {
+	if page == 0 {
 		page = 1
 	}
-	if pageSize <= 0 {
+	if pageSize == 0 {
 		pageSize = 20
 	}
 
-	vendors, total, err := h.vendorService.ListVendors(ctx, req.EntityId, status, vendorType, req.ActiveOnly, page, pageSize)
+	filter := repository.VendorFilter{
+		EntityID:        req.EntityId,
+		Status:          status,
+		VendorType:      vendorType,
+		ActiveOnly:      req.ActiveOnly,
+		Is1099Vendor:    req.Is_1099Vendor,
+		IsTaxExempt:     req.IsTaxExempt,
+		HasCreditLimit:  req.HasCreditLimit,
+		OverCreditLimit: req.OverCreditLimit,
+		MissingTaxID:    req.MissingTaxId,
+		Locale:          req.Locale,
+		MissingLocale:   req.MissingLocale,
+		Currency:        strings.ToUpper(req.Currency),
+		Tag:             service.NormalizeTag(req.Tag),
+		Source:          req.Source,
+		ResolveUsers:    req.ResolveUsers,
+		// The response cannot tell estimated totals apart
+		TotalMode: repository.TotalExact,
+	}
+
+	if req.FieldMask != nil {
+		fields, err := h.maskedVendorFields(ctx, req.FieldMask.Paths)
+		if err != nil {
+			return nil, err
+		}
+		filter.Fields = fields
+	}
+
+	result, err := h.vendorService.ListVendors(ctx, filter, page, pageSize)
 	if err != nil {
 		h.log.Error().Err(err).Msg("Failed to list vendors")
 		return nil, toGRPCError(err)
 	}
 
-	pbVendors := make([]*pb.Vendor, len(vendors))
-	for i, vendor := range vendors {
+	pbVendors := make([]*pb.Vendor, len(result.Vendors))
+	for i, vendor := range result.Vendors {
 		pbVendors[i] = vendorToProto(vendor)
+		if len(filter.Fields) > 0 {
+			maskTimestamps(pbVendors[i], filter.Fields)
+		}
 	}
 
 	return &pb.ListVendorsResponse{
 		Vendors:  pbVendors,
-		Total:    total,
+		Total:    result.Total,
 		Page:     int32(page),
 		PageSize: int32(pageSize),
 	}, nil
 }
 
-// ActivateVendor activates a vendor
-func (h *GRPCHandler) ActivateVendor(ctx context.Context, req *pb.ActivateVendorRequest) (*commonpb.Response, error) {
+// ListVendorChanges returns vendors changed after a watermark for incremental sync
+func (h *GRPCHandler) ListVendorChanges(ctx context.Context, req *pb.ListVendorChangesRequest) (*pb.ListVendorChangesResponse, error) {
+	userCtx, err := auth.GetUserContext(ctx)
+	if err != nil {
+		h.log.Warn().Err(err).Msg("User context not found")
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	h.log.Info().
+		Str("entity_id", req.EntityId).
+		Int64("since", req.Since).
+		Int32("limit", req.Limit).
+		Msg("gRPC ListVendorChanges request")
+
+	if req.EntityId != userCtx.EntityID {
+		h.log.Warn().
+			Str("req_entity_id", req.EntityId).
+			Str("user_entity_id", userCtx.EntityID).
+			Msg("Entity ID mismatch")
+		return nil, status.Error(codes.PermissionDenied, "access denied: entity mismatch")
+	}
+
+	changes, err := h.vendorService.ListVendorChanges(ctx, req.EntityId, req.Since, int(req.Limit))
+	if err != nil {
+		h.log.Error().Err(err).Msg("Failed to list vendor changes")
+		return nil, toGRPCError(err)
+	}
+
+	pbChanges := make([]*pb.VendorChange, len(changes.Changes))
+	for i, change := range changes.Changes {
+		pbChange := &pb.VendorChange{
+			ChangeSeq:  change.ChangeSeq,
+			VendorId:   change.VendorID,
+			VendorCode: change.VendorCode,
+			Deleted:    change.Deleted,
+		}
+		if change.Vendor != nil {
+			pbChange.Vendor = vendorToProto(change.Vendor)
+		}
+		pbChanges[i] = pbChange
+	}
+
+	return &pb.ListVendorChangesResponse{
+		Changes:   pbChanges,
+		Watermark: changes.Watermark,
+		HasMore:   changes.HasMore,
+	}, nil
+}
+
+// WatchVendors streams the changes of the caller's entity, replaying changes
+// after req.Since first and then pushing live changes with periodic heartbeats
+func (h *GRPCHandler) WatchVendors(req *pb.WatchVendorsRequest, stream grpc.ServerStreamingServer[pb.VendorChangeEvent]) error {
+	ctx := stream.Context()
+
+	userCtx, err := auth.GetUserContext(ctx)
+	if err != nil {
+		h.log.Warn().Err(err).Msg("User context not found")
+		return status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	// Streams are always scoped to the authenticated entity
+	if req.EntityId != "" && req.EntityId != userCtx.EntityID {
+		h.log.Warn().
+			Str("req_entity_id", req.EntityId).
+			Str("user_entity_id", userCtx.EntityID).
+			Msg("Entity ID mismatch")
+		return status.Error(codes.PermissionDenied, "access denied: entity mismatch")
+	}
+
+	h.log.Info().
+		Str("entity_id", userCtx.EntityID).
+		Int64("since", req.Since).
+		Str("user_id", userCtx.UserID).
+		Msg("gRPC WatchVendors stream opened")
+
+	err = h.vendorService.WatchVendorChanges(ctx, userCtx.EntityID, req.Since, h.opts.WatchHeartbeatInterval,
+		func(change *service.VendorChange) error {
+			pbChange := &pb.VendorChange{
+				ChangeSeq:  change.ChangeSeq,
+				VendorId:   change.VendorID,
+				VendorCode: change.VendorCode,
+				Deleted:    change.Deleted,
+			}
+			if change.Vendor != nil {
+				pbChange.Vendor = vendorToProto(change.Vendor)
+			}
+			return stream.Send(&pb.VendorChangeEvent{
+				Change:    pbChange,
+				Watermark: change.ChangeSeq,
+			})
+		},
+		func(watermark int64) error {
+			return stream.Send(&pb.VendorChangeEvent{
+				Heartbeat: true,
+				Watermark: watermark,
+			})
+		},
+	)
+
+	h.log.Info().
+		Str("entity_id", userCtx.EntityID).
+		Err(err).
+		Msg("gRPC WatchVendors stream closed")
+
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return toGRPCError(err)
+	}
+
+	return nil
+}
+
+// TransferVendor moves a vendor to another entity (admin only)
+func (h *GRPCHandler) TransferVendor(ctx context.Context, req *pb.TransferVendorRequest) (*pb.TransferVendorResponse, error) {
+	userCtx, err := auth.GetUserContext(ctx)
+	if err != nil {
+		h.log.Warn().Err(err).Msg("User context not found")
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	h.log.Info().
+		Str("id", req.Id).
+		Str("from_entity_id", req.FromEntityId).
+		Str("to_entity_id", req.ToEntityId).
+		Str("user_id", userCtx.UserID).
+		Msg("gRPC TransferVendor request")
+
+	// Transfers span entities, so they are restricted to admins instead of entity scoping
+	if !h.opts.Admins.IsAdmin(userCtx.UserID) {
+		h.log.Warn().
+			Str("user_id", userCtx.UserID).
+			Msg("Admin role required")
+		return nil, status.Error(codes.PermissionDenied, "access denied: admin role required")
+	}
+
+	vendor, err := h.vendorService.TransferVendor(ctx, &service.TransferVendorRequest{
+		VendorID:      req.Id,
+		FromEntityID:  req.FromEntityId,
+		ToEntityID:    req.ToEntityId,
+		NewVendorCode: req.NewVendorCode,
+		TransferredBy: userCtx.UserID,
+	})
+	if err != nil {
+		h.log.Error().Err(err).Msg("Failed to transfer vendor")
+		return nil, toGRPCError(err)
+	}
+
+	return &pb.TransferVendorResponse{
+		Vendor: vendorToProto(vendor),
+	}, nil
+}
+
+// ActivateVendor activates a vendor and returns it with the response
+func (h *GRPCHandler) ActivateVendor(ctx context.Context, req *pb.ActivateVendorRequest) (*pb.VendorStatusResponse, error) {
 	// Extract user context from authenticated request
 	userCtx, err := auth.GetUserContext(ctx)
 	if err != nil {
@@ -269,20 +670,21 @@ func (h *GRPCHandler) ActivateVendor(ctx context.Context, req *pb.ActivateVendor
 		return nil, status.Error(codes.PermissionDenied, "access denied: entity mismatch")
 	}
 
-	err = h.vendorService.ActivateVendor(ctx, req.Id, req.EntityId, userCtx.UserID)
+	vendor, err := h.vendorService.ActivateVendor(ctx, req.Id, req.EntityId, userCtx.UserID)
 	if err != nil {
 		h.log.Error().Err(err).Msg("Failed to activate vendor")
 		return nil, toGRPCError(err)
 	}
 
-	return &commonpb.Response{
+	return &pb.VendorStatusResponse{
 		Success: true,
 		Message: "Vendor activated successfully",
+		Vendor:  vendorToProto(vendor),
 	}, nil
 }
 
-// DeactivateVendor deactivates a vendor
-func (h *GRPCHandler) DeactivateVendor(ctx context.Context, req *pb.DeactivateVendorRequest) (*commonpb.Response, error) {
+// DeactivateVendor deactivates a vendor and returns it with the response
+func (h *GRPCHandler) DeactivateVendor(ctx context.Context, req *pb.DeactivateVendorRequest) (*pb.VendorStatusResponse, error) {
 	// Extract user context from authenticated request
 	userCtx, err := auth.GetUserContext(ctx)
 	if err != nil {
@@ -305,15 +707,53 @@ func (h *GRPCHandler) DeactivateVendor(ctx context.Context, req *pb.DeactivateVe
 		return nil, status.Error(codes.PermissionDenied, "access denied: entity mismatch")
 	}
 
-	err = h.vendorService.DeactivateVendor(ctx, req.Id, req.EntityId, userCtx.UserID)
+	vendor, err := h.vendorService.DeactivateVendor(ctx, req.Id, req.EntityId, userCtx.UserID)
 	if err != nil {
 		h.log.Error().Err(err).Msg("Failed to deactivate vendor")
 		return nil, toGRPCError(err)
 	}
 
-	return &commonpb.Response{
+	return &pb.VendorStatusResponse{
 		Success: true,
 		Message: "Vendor deactivated successfully",
+		Vendor:  vendorToProto(vendor),
+	}, nil
+}
+
+// SuspendVendor suspends a vendor and returns it with the response
+func (h *GRPCHandler) SuspendVendor(ctx context.Context, req *pb.SuspendVendorRequest) (*pb.VendorStatusResponse, error) {
+	// Extract user context from authenticated request
+	userCtx, err := auth.GetUserContext(ctx)
+	if err != nil {
+		h.log.Warn().Err(err).Msg("User context not found")
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	h.log.Info().
+		Str("id", req.Id).
+		Str("entity_id", req.EntityId).
+		Str("user_id", userCtx.UserID).
+		Msg("gRPC SuspendVendor request")
+
+	// Verify entity_id matches authenticated user's entity
+	if req.EntityId != userCtx.EntityID {
+		h.log.Warn().
+			Str("req_entity_id", req.EntityId).
+			Str("user_entity_id", userCtx.EntityID).
+			Msg("Entity ID mismatch")
+		return nil, status.Error(codes.PermissionDenied, "access denied: entity mismatch")
+	}
+
+	vendor, err := h.vendorService.SuspendVendor(ctx, req.Id, req.EntityId, userCtx.UserID)
+	if err != nil {
+		h.log.Error().Err(err).Msg("Failed to suspend vendor")
+		return nil, toGRPCError(err)
+	}
+
+	return &pb.VendorStatusResponse{
+		Success: true,
+		Message: "Vendor suspended successfully",
+		Vendor:  vendorToProto(vendor),
 	}, nil
 }
 
@@ -324,15 +764,16 @@ func (h *GRPCHandler) ValidateVendor(ctx context.Context, req *pb.ValidateVendor
 		Str("entity_id", req.EntityId).
 		Msg("gRPC ValidateVendor request")
 
-	valid, message, err := h.vendorService.ValidateVendor(ctx, req.Id, req.EntityId)
+	validation, err := h.vendorService.ValidateVendor(ctx, req.Id, req.EntityId, req.InvoiceCurrency)
 	if err != nil {
 		h.log.Error().Err(err).Msg("Failed to validate vendor")
 		return nil, toGRPCError(err)
 	}
 
 	return &pb.ValidateVendorResponse{
-		Valid:   valid,
-		Message: message,
+		Valid:    validation.Valid,
+		Message:  validation.Message,
+		Warnings: validation.Warnings,
 	}, nil
 }
 
@@ -359,42 +800,143 @@ func (h *GRPCHandler) UpdateBalance(ctx context.Context, req *pb.UpdateBalanceRe
 // Helper functions
 
 func vendorToProto(vendor *repository.Vendor) *pb.Vendor {
-	return &pb.Vendor{
-		Id:                vendor.ID,
-		EntityId:          vendor.EntityID,
-		VendorCode:        vendor.VendorCode,
-		VendorName:        vendor.VendorName,
-		LegalName:         stringToProto(vendor.LegalName),
-		VendorType:        vendor.VendorType,
-		Status:            vendor.Status,
-		TaxId:             stringToProto(vendor.TaxID),
-		IsTaxExempt:       vendor.IsTaxExempt,
-		Is_1099Vendor:     vendor.Is1099Vendor,
-		Email:             stringToProto(vendor.Email),
-		Phone:             stringToProto(vendor.Phone),
-		Fax:               stringToProto(vendor.Fax),
-		Website:           stringToProto(vendor.Website),
-		AddressLine1:      stringToProto(vendor.AddressLine1),
-		AddressLine2:      stringToProto(vendor.AddressLine2),
-		City:              stringToProto(vendor.City),
-		StateProvince:     stringToProto(vendor.StateProvince),
-		PostalCode:        stringToProto(vendor.PostalCode),
-		Country:           vendor.Country,
-		PaymentTerms:      vendor.PaymentTerms,
-		PaymentMethod:     stringToProto(vendor.PaymentMethod),
-		Currency:          vendor.Currency,
-		CreditLimit:       int64ToProto(vendor.CreditLimit),
-		CurrentBalance:    vendor.CurrentBalance,
-		BankName:          stringToProto(vendor.BankName),
-		BankAccountNumber: stringToProto(vendor.BankAccountNumber),
-		BankRoutingNumber: stringToProto(vendor.BankRoutingNumber),
-		SwiftCode:         stringToProto(vendor.SwiftCode),
-		Iban:              stringToProto(vendor.IBAN),
-		Notes:             stringToProto(vendor.Notes),
-		Tags:              vendor.Tags,
-		CreatedAt:         timestamppb.New(vendor.CreatedAt),
-		UpdatedAt:         timestamppb.New(vendor.UpdatedAt),
+	pbVendor := &pb.Vendor{
+		Id:                 vendor.ID,
+		EntityId:           vendor.EntityID,
+		VendorCode:         vendor.VendorCode,
+		VendorName:         vendor.VendorName,
+		LegalName:          stringToProto(vendor.LegalName),
+		DoingBusinessAs:    stringToProto(vendor.DoingBusinessAs),
+		VendorType:         vendor.VendorType,
+		Status:             vendor.Status,
+		TaxId:              stringToProto(vendor.TaxID),
+		IsTaxExempt:        vendor.IsTaxExempt,
+		Is_1099Vendor:      vendor.Is1099Vendor,
+		Email:              stringToProto(vendor.Email),
+		RemittanceEmail:    stringToProto(vendor.RemittanceEmail),
+		Phone:              stringToProto(vendor.Phone),
+		Fax:                stringToProto(vendor.Fax),
+		Website:            stringToProto(vendor.Website),
+		AddressLine1:       stringToProto(vendor.AddressLine1),
+		AddressLine2:       stringToProto(vendor.AddressLine2),
+		City:               stringToProto(vendor.City),
+		StateProvince:      stringToProto(vendor.StateProvince),
+		PostalCode:         stringToProto(vendor.PostalCode),
+		Country:            vendor.Country,
+		Locale:             stringToProto(vendor.Locale),
+		AcceptedCurrencies: vendor.AcceptedCurrencies,
+		PaymentTerms:       vendor.PaymentTerms,
+		PaymentMethod:      stringToProto(vendor.PaymentMethod),
+		Currency:           vendor.Currency,
+		CreditLimit:        int64ToProto(vendor.CreditLimit),
+		CurrentBalance:     vendor.CurrentBalance,
+		BankName:           stringToProto(vendor.BankName),
+		BankAccountNumber:  stringToProto(vendor.BankAccountNumber),
+		BankRoutingNumber:  stringToProto(vendor.BankRoutingNumber),
+		SwiftCode:          stringToProto(vendor.SwiftCode),
+		Iban:               stringToProto(vendor.IBAN),
+		Notes:              stringToProto(vendor.Notes),
+		Tags:               vendor.Tags,
+		TemplateId:         stringToProto(vendor.TemplateID),
+		ExternalRefs:       vendor.ExternalRefs,
+		Warnings:           vendor.Warnings,
+		CreatedAt:          timestamppb.New(vendor.CreatedAt),
+		UpdatedAt:          timestamppb.New(vendor.UpdatedAt),
+
+		CreditLimitCurrency: stringToProto(vendor.CreditLimitCurrency),
+		VendorTypeEnum:      vendorTypeToProto(vendor.VendorType),
+		StatusEnum:          vendorStatusToProto(vendor.Status),
+		Source:              vendor.Source,
+		SourceReference:     stringToProto(vendor.SourceReference),
+	}
+	if vendor.Approval != nil {
+		pbVendor.ApprovalsRequired = int32(vendor.Approval.Required)
+		pbVendor.ApprovalsReceived = int32(vendor.Approval.Received)
+	}
+	if vendor.Risk != nil {
+		pbVendor.RiskScore = int32(vendor.Risk.Score)
+		for _, factor := range vendor.Risk.Factors {
+			pbVendor.RiskFactors = append(pbVendor.RiskFactors, factor.Factor)
+		}
+	}
+	for _, w := range vendor.ValidationWarnings {
+		pbVendor.ValidationWarnings = append(pbVendor.ValidationWarnings, &pb.ValidationWarning{
+			Code:    w.Code,
+			Field:   w.Field,
+			Message: w.Message,
+		})
+	}
+	pbVendor.CreatedByUser = userToProto(vendor.CreatedByUser)
+	pbVendor.UpdatedByUser = userToProto(vendor.UpdatedByUser)
+	return pbVendor
+}
+
+// userToProto converts a resolved user, nil when it was not resolved
+func userToProto(user *identity.User) *pb.VendorUser {
+	if user == nil {
+		return nil
+	}
+	return &pb.VendorUser{Id: user.ID, DisplayName: user.DisplayName, Email: user.Email}
+}
+
+// maskedVendorFields validates the paths of a ListVendors field mask. Bank
+// fields are restricted like the bank section of the snapshot.
+func (h *GRPCHandler) maskedVendorFields(ctx context.Context, paths []string) ([]string, error) {
+	fields, err := service.ParseVendorFields(paths)
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	if service.RequestsBankFields(fields) {
+		var userID string
+		if userCtx, err := auth.GetUserContext(ctx); err == nil && userCtx != nil {
+			userID = userCtx.UserID
+		}
+		if !h.opts.Sections.Allows(service.SnapshotBank, userID) {
+			return nil, status.Error(codes.PermissionDenied, "vendor bank details are restricted")
+		}
+	}
+	return fields, nil
+}
+
+// maskTimestamps clears the timestamps of a vendor listed with a field mask
+// that leaves them out; the other fields left out are already empty
+func maskTimestamps(vendor *pb.Vendor, fields []string) {
+	var createdAt, updatedAt bool
+	for _, field := range fields {
+		createdAt = createdAt || field == "created_at"
+		updatedAt = updatedAt || field == "updated_at"
+	}
+	if !createdAt {
+		vendor.CreatedAt = nil
+	}
+	if !updatedAt {
+		vendor.UpdatedAt = nil
+	}
+}
+
+func contactInputsFromProto(inputs []*pb.VendorContactInput) []*service.AddContactRequest {
+	if len(inputs) == 0 {
+		return nil
 	}
+
+	contacts := make([]*service.AddContactRequest, len(inputs))
+	for i, input := range inputs {
+		contacts[i] = &service.AddContactRequest{
+			ContactType:            input.ContactType,
+			FirstName:              input.FirstName,
+			LastName:               input.LastName,
+			Title:                  stringPtr(input.Title),
+			Email:                  stringPtr(input.Email),
+			Phone:                  stringPtr(input.Phone),
+			Mobile:                 stringPtr(input.Mobile),
+			IsPrimary:              input.IsPrimary,
+			ReceivesPurchaseOrders: input.ReceivesPurchaseOrders,
+			ReceivesRemittance:     input.ReceivesRemittance,
+			ReceivesStatements:     input.ReceivesStatements,
+			Notes:                  stringPtr(input.Notes),
+		}
+	}
+	return contacts
 }
 
 func stringToProto(s *string) string {
@@ -426,6 +968,176 @@ func int64Ptr(i int64) *int64 {
 }
 
 func toGRPCError(err error) error {
+	// Validation errors carry every field violation as a BadRequest detail
+	var validationErr *service.ValidationError
+	if stderrors.As(err, &validationErr) {
+		badRequest := &errdetails.BadRequest{}
+		for _, violation := range validationErr.Violations {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       violation.Field,
+				Description: violation.Message,
+			})
+		}
+
+		st := status.New(codes.InvalidArgument, validationErr.Error())
+		if detailed, detailErr := st.WithDetails(badRequest); detailErr == nil {
+			return detailed.Err()
+		}
+		return st.Err()
+	}
+
+	// Debounced duplicate creates point at the vendor just created
+	var duplicateErr *service.DuplicateCreateError
+	if stderrors.As(err, &duplicateErr) {
+		st := status.New(codes.AlreadyExists, duplicateErr.Error())
+		if duplicateErr.VendorID != "" {
+			if detailed, detailErr := st.WithDetails(&errdetails.ResourceInfo{
+				ResourceType: "vendor",
+				ResourceName: duplicateErr.VendorID,
+				Description:  "identical vendor created moments ago",
+			}); detailErr == nil {
+				return detailed.Err()
+			}
+		}
+		return st.Err()
+	}
+
+	// Locked vendor codes name the vendor as a PreconditionFailure detail
+	var lockedErr *service.VendorCodeLockedError
+	if stderrors.As(err, &lockedErr) {
+		st := status.New(codes.FailedPrecondition, lockedErr.Error())
+		preconditionFailure := &errdetails.PreconditionFailure{
+			Violations: []*errdetails.PreconditionFailure_Violation{{
+				Type:        "VENDOR_CODE_LOCKED",
+				Subject:     "vendor:" + lockedErr.VendorID,
+				Description: "the entity locks vendor codes after activation",
+			}},
+		}
+		if detailed, detailErr := st.WithDetails(preconditionFailure); detailErr == nil {
+			return detailed.Err()
+		}
+		return st.Err()
+	}
+
+	// Blocked currency changes name the vendor and its balance as a PreconditionFailure detail
+	var currencyErr *service.CurrencyChangeBlockedError
+	if stderrors.As(err, &currencyErr) {
+		st := status.New(codes.FailedPrecondition, currencyErr.Error())
+		preconditionFailure := &errdetails.PreconditionFailure{
+			Violations: []*errdetails.PreconditionFailure_Violation{{
+				Type:        "CURRENCY_CHANGE_BLOCKED",
+				Subject:     "vendor:" + currencyErr.VendorID,
+				Description: fmt.Sprintf("current balance %d %s", currencyErr.Balance, currencyErr.From),
+			}},
+		}
+		if detailed, detailErr := st.WithDetails(preconditionFailure); detailErr == nil {
+			return detailed.Err()
+		}
+		return st.Err()
+	}
+
+	// Removals of the last recipient of a required communication type name
+	// the contact as a PreconditionFailure detail
+	var lastRecipientErr *service.LastRecipientError
+	if stderrors.As(err, &lastRecipientErr) {
+		st := status.New(codes.FailedPrecondition, lastRecipientErr.Error())
+		preconditionFailure := &errdetails.PreconditionFailure{
+			Violations: []*errdetails.PreconditionFailure_Violation{{
+				Type:        "LAST_COMMUNICATION_RECIPIENT",
+				Subject:     "contact:" + lastRecipientErr.ContactID,
+				Description: lastRecipientErr.CommunicationType + " needs at least one recipient",
+			}},
+		}
+		if detailed, detailErr := st.WithDetails(preconditionFailure); detailErr == nil {
+			return detailed.Err()
+		}
+		return st.Err()
+	}
+
+	// Writes to suspended or deleted entities name the entity as a PreconditionFailure detail
+	var readOnlyErr *service.EntityReadOnlyError
+	if stderrors.As(err, &readOnlyErr) {
+		st := status.New(codes.FailedPrecondition, readOnlyErr.Error())
+		preconditionFailure := &errdetails.PreconditionFailure{
+			Violations: []*errdetails.PreconditionFailure_Violation{{
+				Type:        "ENTITY_READ_ONLY",
+				Subject:     "entity:" + readOnlyErr.EntityID,
+				Description: "the entity is " + readOnlyErr.State,
+			}},
+		}
+		if detailed, detailErr := st.WithDetails(preconditionFailure); detailErr == nil {
+			return detailed.Err()
+		}
+		return st.Err()
+	}
+
+	// Deletes of vendors with child records list them as PreconditionFailure details
+	var childrenErr *service.VendorHasChildrenError
+	if stderrors.As(err, &childrenErr) {
+		st := status.New(codes.FailedPrecondition, childrenErr.Error())
+		preconditionFailure := &errdetails.PreconditionFailure{}
+		for _, child := range []struct {
+			kind  string
+			count int
+		}{
+			{"contacts", childrenErr.Children.Contacts},
+			{"documents", childrenErr.Children.Documents},
+			{"bank_verifications", childrenErr.Children.BankVerifications},
+			{"pending_status_changes", childrenErr.Children.PendingStatusChanges},
+		} {
+			if child.count == 0 {
+				continue
+			}
+			preconditionFailure.Violations = append(preconditionFailure.Violations, &errdetails.PreconditionFailure_Violation{
+				Type:        "VENDOR_HAS_CHILDREN",
+				Subject:     "vendor:" + childrenErr.VendorID,
+				Description: fmt.Sprintf("%d %s", child.count, strings.ReplaceAll(child.kind, "_", " ")),
+			})
+		}
+		if detailed, detailErr := st.WithDetails(preconditionFailure); detailErr == nil {
+			return detailed.Err()
+		}
+		return st.Err()
+	}
+
+	// Exceeded vendor quotas carry the entity's count and limit as a QuotaFailure detail
+	var quotaErr *service.QuotaExceededError
+	if stderrors.As(err, &quotaErr) {
+		st := status.New(codes.ResourceExhausted, quotaErr.Error())
+		quotaFailure := &errdetails.QuotaFailure{
+			Violations: []*errdetails.QuotaFailure_Violation{{
+				Subject:     "entity:" + quotaErr.EntityID,
+				Description: fmt.Sprintf("vendor quota: %d of %d vendors in use", quotaErr.Count, quotaErr.Limit),
+			}},
+		}
+		if detailed, detailErr := st.WithDetails(quotaFailure); detailErr == nil {
+			return detailed.Err()
+		}
+		return st.Err()
+	}
+
+	if repository.IsQueryTimeout(err) {
+		return status.Error(codes.DeadlineExceeded, "the database did not respond in time, please retry")
+	}
+
+	if repository.IsNotFound(err) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+
+	if repository.IsForeignKeyViolation(err) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	// Values the database could not parse, e.g. unknown enum values or
+	// malformed UUIDs, are the caller's
+	if repository.IsInvalidInput(err) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	var appErr *errors.AppError
+	if stderrors.As(err, &appErr) && appErr.Code == errors.ErrCodeInvalidInput {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	// TODO: Map common errors to gRPC status codes
 	return status.Error(codes.Internal, err.Error())
 }
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	pb "github.com/pesio-ai/be-lib-proto/gen/go/ap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Payment terms are global today. Requests carry entity_id so that
+// entity-specific terms can be scoped without changing the API.
+
+// ListPaymentTerms lists payment terms, only active ones unless only_active is false
+func (h *GRPCHandler) ListPaymentTerms(ctx context.Context, req *pb.ListPaymentTermsRequest) (*pb.ListPaymentTermsResponse, error) {
+	onlyActive := onlyActiveOrDefault(req.OnlyActive)
+
+	h.log.Info().
+		Str("entity_id", req.EntityId).
+		Bool("only_active", onlyActive).
+		Msg("gRPC ListPaymentTerms request")
+
+	terms, err := h.vendorService.ListPaymentTerms(ctx, onlyActive)
+	if err != nil {
+		h.log.Error().Err(err).Msg("Failed to list payment terms")
+		return nil, toGRPCError(err)
+	}
+
+	pbTerms := make([]*pb.PaymentTerm, len(terms))
+	for i, term := range terms {
+		pbTerms[i] = paymentTermToProto(term)
+	}
+
+	return &pb.ListPaymentTermsResponse{PaymentTerms: pbTerms}, nil
+}
+
+// GetPaymentTermByCode resolves a payment term code, e.g. to compute due dates
+func (h *GRPCHandler) GetPaymentTermByCode(ctx context.Context, req *pb.GetPaymentTermByCodeRequest) (*pb.PaymentTerm, error) {
+	onlyActive := onlyActiveOrDefault(req.OnlyActive)
+
+	h.log.Info().
+		Str("entity_id", req.EntityId).
+		Str("code", req.Code).
+		Bool("only_active", onlyActive).
+		Msg("gRPC GetPaymentTermByCode request")
+
+	term, err := h.vendorService.GetPaymentTermByCode(ctx, req.Code, onlyActive)
+	if err != nil {
+		if !repository.IsNotFound(err) {
+			h.log.Error().Err(err).Msg("Failed to get payment term")
+		}
+		return nil, toGRPCError(err)
+	}
+
+	return paymentTermToProto(term), nil
+}
+
+// onlyActiveOrDefault applies the only_active default of true when unset
+func onlyActiveOrDefault(onlyActive *bool) bool {
+	return onlyActive == nil || *onlyActive
+}
+
+func paymentTermToProto(term *repository.PaymentTerm) *pb.PaymentTerm {
+	pbTerm := &pb.PaymentTerm{
+		Id:              term.ID,
+		Code:            term.Code,
+		Description:     term.Description,
+		NetDays:         int32(term.NetDays),
+		DiscountPercent: term.DiscountPercent,
+		IsActive:        term.IsActive,
+		CreatedAt:       timestamppb.New(term.CreatedAt),
+	}
+	if term.DiscountDays != nil {
+		days := int32(*term.DiscountDays)
+		pbTerm.DiscountDays = &days
+	}
+	return pbTerm
+}
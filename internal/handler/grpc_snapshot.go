@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-lib-common/auth"
+	pb "github.com/pesio-ai/be-lib-proto/gen/go/ap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GetVendorSnapshot returns a vendor with everything its detail page shows.
+// Sections the caller may not see are left out and listed in omitted; section
+// load times are sent in the server-timing response header.
+func (h *GRPCHandler) GetVendorSnapshot(ctx context.Context, req *pb.GetVendorSnapshotRequest) (*pb.VendorSnapshot, error) {
+	userCtx, err := auth.GetUserContext(ctx)
+	if err != nil {
+		h.log.Warn().Err(err).Msg("User context not found")
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	h.log.Info().
+		Str("id", req.Id).
+		Str("entity_id", req.EntityId).
+		Str("user_id", userCtx.UserID).
+		Msg("gRPC GetVendorSnapshot request")
+
+	if req.EntityId != userCtx.EntityID {
+		h.log.Warn().
+			Str("req_entity_id", req.EntityId).
+			Str("user_entity_id", userCtx.EntityID).
+			Msg("Entity ID mismatch")
+		return nil, status.Error(codes.PermissionDenied, "access denied: entity mismatch")
+	}
+
+	snapshot, err := h.vendorService.GetVendorSnapshot(ctx, req.Id, req.EntityId, func(section string) bool {
+		return h.opts.Sections.Allows(section, userCtx.UserID)
+	})
+	if err != nil {
+		if !repository.IsNotFound(err) {
+			h.log.Error().Err(err).Msg("Failed to get vendor snapshot")
+		}
+		return nil, toGRPCError(err)
+	}
+
+	grpc.SetHeader(ctx, metadata.Pairs("server-timing", serverTiming(snapshot.Timings)))
+
+	resp := &pb.VendorSnapshot{
+		Vendor:   vendorToProto(snapshot.Vendor),
+		Notes:    stringToProto(snapshot.Notes),
+		Omitted:  snapshot.Omitted,
+		Warnings: snapshot.Warnings,
+	}
+	for _, contact := range snapshot.Contacts {
+		resp.Contacts = append(resp.Contacts, contactToProto(contact))
+	}
+	for _, doc := range snapshot.Documents {
+		resp.Documents = append(resp.Documents, &pb.VendorDocument{
+			Id:             doc.ID,
+			VendorId:       doc.VendorID,
+			DocumentType:   doc.DocumentType,
+			DocumentName:   doc.DocumentName,
+			DocumentUrl:    doc.DocumentURL,
+			FileSize:       int64ToProto(doc.FileSize),
+			MimeType:       stringToProto(doc.MimeType),
+			ExpirationDate: stringToProto(doc.ExpirationDate),
+			UploadedBy:     stringToProto(doc.UploadedBy),
+			UploadedAt:     doc.UploadedAt,
+		})
+	}
+	if bank := snapshot.Bank; bank != nil {
+		resp.Bank = &pb.BankDetails{
+			BankName:          stringToProto(bank.BankName),
+			BankAccountNumber: stringToProto(bank.BankAccountNumber),
+			BankRoutingNumber: stringToProto(bank.BankRoutingNumber),
+			SwiftCode:         stringToProto(bank.SwiftCode),
+			Iban:              stringToProto(bank.IBAN),
+		}
+	}
+	for _, t := range snapshot.BalanceTransactions {
+		resp.BalanceTransactions = append(resp.BalanceTransactions, &pb.BalanceTransaction{
+			Id:           t.ID,
+			VendorId:     t.VendorID,
+			EntityId:     t.EntityID,
+			Amount:       t.Amount,
+			BalanceAfter: t.BalanceAfter,
+			CreatedAt:    timestamppb.New(t.CreatedAt),
+		})
+	}
+	for _, entry := range snapshot.AuditEntries {
+		details, _ := structpb.NewStruct(jsonMap(entry.Details))
+		resp.AuditEntries = append(resp.AuditEntries, &pb.AuditEntry{
+			Id:        entry.ID,
+			EntityId:  entry.EntityID,
+			VendorId:  entry.VendorID,
+			Action:    entry.Action,
+			ActorId:   stringToProto(entry.ActorID),
+			Details:   details,
+			CreatedAt: timestamppb.New(entry.CreatedAt),
+		})
+	}
+	for _, summary := range snapshot.Spend {
+		spend := &pb.SpendSummary{
+			Period:       summary.Period,
+			Currency:     summary.Currency,
+			Amount:       summary.Amount,
+			PaymentCount: summary.PaymentCount,
+			From:         timestamppb.New(summary.From),
+			To:           timestamppb.New(summary.To),
+		}
+		if summary.LastPaymentAt != nil {
+			spend.LastPaymentAt = timestamppb.New(*summary.LastPaymentAt)
+		}
+		resp.Spend = append(resp.Spend, spend)
+	}
+
+	return resp, nil
+}
+
+// jsonMap returns m in the shape decoded JSON has, as structpb requires
+func jsonMap(m map[string]interface{}) map[string]interface{} {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil
+	}
+	var decoded map[string]interface{}
+	json.Unmarshal(data, &decoded)
+	return decoded
+}
+
+// contactToProto converts a vendor contact to its protobuf form
+func contactToProto(contact *repository.VendorContact) *pb.VendorContact {
+	return &pb.VendorContact{
+		Id:                     contact.ID,
+		VendorId:               contact.VendorID,
+		ContactType:            contact.ContactType,
+		FirstName:              contact.FirstName,
+		LastName:               contact.LastName,
+		Title:                  stringToProto(contact.Title),
+		Email:                  stringToProto(contact.Email),
+		Phone:                  stringToProto(contact.Phone),
+		Mobile:                 stringToProto(contact.Mobile),
+		IsPrimary:              contact.IsPrimary,
+		ReceivesPurchaseOrders: contact.ReceivesPurchaseOrders,
+		ReceivesRemittance:     contact.ReceivesRemittance,
+		ReceivesStatements:     contact.ReceivesStatements,
+		Notes:                  stringToProto(contact.Notes),
+		CreatedAt:              timestamppb.New(contact.CreatedAt),
+		UpdatedAt:              timestamppb.New(contact.UpdatedAt),
+	}
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+// StreamAuthInterceptor authenticates streaming RPCs with a unary auth
+// interceptor: the unary interceptor validates the stream's incoming metadata and
+// the stream handler runs with the authenticated context it produces.
+func StreamAuthInterceptor(unary grpc.UnaryServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		unaryInfo := &grpc.UnaryServerInfo{Server: srv, FullMethod: info.FullMethod}
+
+		_, err := unary(ss.Context(), nil, unaryInfo, func(ctx context.Context, _ interface{}) (interface{}, error) {
+			return nil, handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+		})
+		return err
+	}
+}
+
+// authenticatedStream overrides the stream context with the authenticated one
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
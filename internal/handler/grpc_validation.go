@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+	pb "github.com/pesio-ai/be-lib-proto/gen/go/ap"
+	"google.golang.org/grpc"
+)
+
+// Limits on the lookup and filter strings of RPC requests. Vendor fields are
+// checked against their column sizes by the service.
+const (
+	// maxRequestCodeLength bounds codes, as the vendor_code column does
+	maxRequestCodeLength = 50
+	// maxRequestTextLength bounds searches and other free text
+	maxRequestTextLength = 255
+)
+
+// requestValidator collects the field violations of an RPC request
+type requestValidator struct {
+	violations []service.FieldViolation
+}
+
+func (v *requestValidator) add(field, message string) {
+	v.violations = append(v.violations, service.FieldViolation{Field: field, Message: message})
+}
+
+// id checks a required UUID field
+func (v *requestValidator) id(field, value string) {
+	if value == "" {
+		v.add(field, field+" is required")
+		return
+	}
+	v.optionalID(field, value)
+}
+
+// optionalID checks a UUID field that may be left empty
+func (v *requestValidator) optionalID(field, value string) {
+	if value != "" && !service.IsUUID(value) {
+		v.add(field, fmt.Sprintf("%s must be a UUID, got %q", field, value))
+	}
+}
+
+// nonNegative checks a page, page size, limit or watermark, where zero
+// selects the default
+func (v *requestValidator) nonNegative(field string, value int64) {
+	if value < 0 {
+		v.add(field, field+" must not be negative")
+	}
+}
+
+// maxLength checks that a string is at most max characters
+func (v *requestValidator) maxLength(field, value string, max int) {
+	if utf8.RuneCountInString(value) > max {
+		v.add(field, fmt.Sprintf("%s must be at most %d characters", field, max))
+	}
+}
+
+// err returns the violations as an InvalidArgument error with a BadRequest
+// detail, or nil
+func (v *requestValidator) err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return toGRPCError(&service.ValidationError{Violations: v.violations})
+}
+
+// validateRequest checks the fields of an RPC request that no handler could
+// serve without: IDs present and well-formed, no negative paging and lookup
+// strings of sane length. Requests of other types pass.
+func validateRequest(req interface{}) error {
+	v := &requestValidator{}
+	switch req := req.(type) {
+	case *pb.CreateVendorRequest:
+		v.id("entity_id", req.EntityId)
+		v.optionalID("template_id", req.TemplateId)
+	case *pb.GetVendorRequest:
+		v.id("id", req.Id)
+		v.id("entity_id", req.EntityId)
+	case *pb.UpdateVendorRequest:
+		v.id("id", req.Id)
+		v.id("entity_id", req.EntityId)
+	case *pb.UpsertVendorRequest:
+		v.id("entity_id", req.EntityId)
+	case *pb.DeleteVendorRequest:
+		v.id("id", req.Id)
+		v.id("entity_id", req.EntityId)
+	case *pb.ListVendorsRequest:
+		v.id("entity_id", req.EntityId)
+		v.nonNegative("page", int64(req.Page))
+		v.nonNegative("page_size", int64(req.PageSize))
+		if req.Source != "" && !service.IsValidVendorSource(req.Source) {
+			v.add("source", fmt.Sprintf("unknown vendor source %q", req.Source))
+		}
+		v.maxLength("locale", req.Locale, maxRequestCodeLength)
+		v.maxLength("currency", req.Currency, maxRequestCodeLength)
+		v.maxLength("tag", req.Tag, maxRequestTextLength)
+	case *pb.ListVendorSummariesRequest:
+		v.id("entity_id", req.EntityId)
+		v.nonNegative("page", int64(req.Page))
+		v.nonNegative("page_size", int64(req.PageSize))
+		v.maxLength("name", req.Name, maxRequestTextLength)
+		v.maxLength("locale", req.Locale, maxRequestCodeLength)
+		v.maxLength("currency", req.Currency, maxRequestCodeLength)
+		v.maxLength("tag", req.Tag, maxRequestTextLength)
+	case *pb.ListVendorChangesRequest:
+		v.id("entity_id", req.EntityId)
+		v.nonNegative("since", req.Since)
+		v.nonNegative("limit", int64(req.Limit))
+	case *pb.WatchVendorsRequest:
+		// Streams default to the caller's entity
+		v.optionalID("entity_id", req.EntityId)
+		v.nonNegative("since", req.Since)
+	case *pb.TransferVendorRequest:
+		v.id("id", req.Id)
+		v.id("from_entity_id", req.FromEntityId)
+		v.id("to_entity_id", req.ToEntityId)
+		v.maxLength("new_vendor_code", req.NewVendorCode, maxRequestCodeLength)
+	case *pb.ActivateVendorRequest:
+		v.id("id", req.Id)
+		v.id("entity_id", req.EntityId)
+	case *pb.DeactivateVendorRequest:
+		v.id("id", req.Id)
+		v.id("entity_id", req.EntityId)
+	case *pb.SuspendVendorRequest:
+		v.id("id", req.Id)
+		v.id("entity_id", req.EntityId)
+	case *pb.ValidateVendorRequest:
+		v.id("id", req.Id)
+		v.id("entity_id", req.EntityId)
+		v.maxLength("invoice_currency", req.InvoiceCurrency, maxRequestCodeLength)
+	case *pb.UpdateBalanceRequest:
+		v.id("id", req.Id)
+		v.id("entity_id", req.EntityId)
+	case *pb.GetVendorActivityRequest:
+		v.id("id", req.Id)
+		v.id("entity_id", req.EntityId)
+		v.nonNegative("limit", int64(req.Limit))
+		v.maxLength("cursor", req.Cursor, maxRequestTextLength)
+	case *pb.GetVendorContactsRequest:
+		v.id("vendor_id", req.VendorId)
+		v.nonNegative("page", int64(req.Page))
+		v.nonNegative("page_size", int64(req.PageSize))
+		v.maxLength("contact_type", req.ContactType, maxRequestCodeLength)
+		v.maxLength("sort", req.Sort, maxRequestCodeLength)
+	case *pb.GetContactsForCommunicationRequest:
+		v.id("vendor_id", req.VendorId)
+		v.id("entity_id", req.EntityId)
+		if !service.IsValidCommunicationType(req.CommunicationType) {
+			v.add("communication_type", fmt.Sprintf("communication_type must be one of %s, got %q",
+				strings.Join(service.CommunicationTypes, ", "), req.CommunicationType))
+		}
+	case *pb.GetVendorSnapshotRequest:
+		v.id("id", req.Id)
+		v.id("entity_id", req.EntityId)
+	case *pb.ListPaymentTermsRequest:
+		// Payment terms are global, so entity_id is optional
+		v.optionalID("entity_id", req.EntityId)
+	case *pb.GetPaymentTermByCodeRequest:
+		v.optionalID("entity_id", req.EntityId)
+		v.maxLength("code", req.Code, maxRequestCodeLength)
+	case *pb.MatchVendorsRequest:
+		v.id("entity_id", req.EntityId)
+		v.maxLength("name", req.Name, maxRequestTextLength)
+		v.maxLength("tax_id", req.TaxId, maxRequestCodeLength)
+		v.maxLength("bank_last4", req.BankLast4, maxRequestCodeLength)
+		v.maxLength("country", req.Country, maxRequestCodeLength)
+	}
+	return v.err()
+}
+
+// ValidationInterceptor rejects unary RPCs whose request fails
+// validateRequest with InvalidArgument before they reach the handler
+func ValidationInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := validateRequest(req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ValidationStreamInterceptor applies validateRequest to the messages
+// streaming RPCs receive
+func ValidationStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatedStream{ServerStream: ss})
+	}
+}
+
+// validatedStream validates every message received
+type validatedStream struct {
+	grpc.ServerStream
+}
+
+func (s *validatedStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return validateRequest(m)
+}
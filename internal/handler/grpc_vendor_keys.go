@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"context"
+	stderrors "errors"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/authz"
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+	pb "github.com/pesio-ai/be-lib-proto/gen/go/ap"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// vendorKeyMetadata is the metadata key carrying a vendor API key
+const vendorKeyMetadata = "x-vendor-api-key"
+
+// vendorKeyMethods are the RPCs vendor API keys may call, with the scope each
+// requires. Their handlers restrict the key to its own vendor.
+var vendorKeyMethods = map[string]string{
+	pb.VendorsService_GetVendor_FullMethodName: service.ScopeReadProfile,
+}
+
+// VendorKeyInterceptor authenticates calls presenting a vendor API key in
+// x-vendor-api-key metadata instead of passing them to auth, the user auth
+// interceptor. Such calls may only reach vendorKeyMethods; the handlers find
+// the key with authz.VendorKeyFromContext. Every use of a key is logged.
+func VendorKeyInterceptor(authenticator VendorKeyAuthenticator, log *zerolog.Logger, auth grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get(vendorKeyMetadata)
+		if len(values) == 0 {
+			return auth(ctx, req, info, handler)
+		}
+
+		key, err := authenticator.AuthenticateVendorAPIKey(ctx, values[0])
+		if err != nil {
+			if stderrors.Is(err, service.ErrInvalidAPIKey) {
+				log.Warn().Str("method", info.FullMethod).Msg("Invalid vendor API key presented")
+				return nil, status.Error(codes.Unauthenticated, err.Error())
+			}
+			return nil, toGRPCError(err)
+		}
+
+		// Runs before the request logging interceptor, so the fields are set here
+		log.Info().
+			Str("key_id", key.KeyID).
+			Str("vendor_id", key.VendorID).
+			Str("entity_id", key.EntityID).
+			Str("method", info.FullMethod).
+			Msg("Vendor API key used")
+
+		scope, ok := vendorKeyMethods[info.FullMethod]
+		if !ok {
+			return nil, status.Error(codes.PermissionDenied, "vendor API keys cannot call "+info.FullMethod)
+		}
+		if !key.HasScope(scope) {
+			return nil, status.Error(codes.PermissionDenied, "the API key lacks the "+scope+" scope")
+		}
+		return handler(authz.WithVendorKey(ctx, key), req)
+	}
+}
+
+// checkVendorKey restricts a call made with a vendor API key to the key's
+// vendor. Calls without a key pass.
+func checkVendorKey(ctx context.Context, vendorID, entityID string) error {
+	key, ok := authz.VendorKeyFromContext(ctx)
+	if ok && (vendorID != key.VendorID || entityID != key.EntityID) {
+		return status.Error(codes.PermissionDenied, "the API key only grants access to its own vendor")
+	}
+	return nil
+}
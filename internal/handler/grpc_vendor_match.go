@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+	pb "github.com/pesio-ai/be-lib-proto/gen/go/ap"
+)
+
+// MatchVendors ranks the vendors most likely to be the supplier named on an
+// invoice, for the invoice OCR pipeline
+func (h *GRPCHandler) MatchVendors(ctx context.Context, req *pb.MatchVendorsRequest) (*pb.MatchVendorsResponse, error) {
+	h.log.Info().
+		Str("entity_id", req.EntityId).
+		Msg("gRPC MatchVendors request")
+
+	matches, err := h.vendorService.MatchVendors(ctx, &service.MatchVendorsRequest{
+		EntityID:  req.EntityId,
+		Name:      req.Name,
+		TaxID:     req.TaxId,
+		BankLast4: req.BankLast4,
+		Country:   req.Country,
+	})
+	if err != nil {
+		h.log.Error().Err(err).Msg("Failed to match vendors")
+		return nil, toGRPCError(err)
+	}
+
+	resp := &pb.MatchVendorsResponse{Candidates: make([]*pb.VendorMatchCandidate, len(matches))}
+	for i, match := range matches {
+		resp.Candidates[i] = &pb.VendorMatchCandidate{
+			Vendor:         vendorToProto(match.Vendor),
+			Score:          match.Score,
+			MatchedOn:      match.MatchedOn,
+			MatchedName:    match.MatchedName,
+			NameSimilarity: match.Breakdown.NameSimilarity,
+			TaxIdBoost:     match.Breakdown.TaxIDBoost,
+			BankBoost:      match.Breakdown.BankBoost,
+			CountryBoost:   match.Breakdown.CountryBoost,
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+	pb "github.com/pesio-ai/be-lib-proto/gen/go/ap"
+)
+
+// ListVendorSummaries lists the lightweight vendor summaries meant for
+// dropdowns and typeaheads, with the filters of ListVendors and a name search
+func (h *GRPCHandler) ListVendorSummaries(ctx context.Context, req *pb.ListVendorSummariesRequest) (*pb.ListVendorSummariesResponse, error) {
+	h.log.Info().
+		Str("entity_id", req.EntityId).
+		Int32("page", req.Page).
+		Int32("page_size", req.PageSize).
+		Msg("gRPC ListVendorSummaries request")
+
+	page := int(req.Page)
+	pageSize := int(req.PageSize)
+	if page == 0 {
+		page = 1
+	}
+	if pageSize == 0 {
+		pageSize = 20
+	}
+
+	filter := repository.VendorFilter{
+		EntityID:        req.EntityId,
+		ActiveOnly:      req.ActiveOnly,
+		Is1099Vendor:    req.Is_1099Vendor,
+		IsTaxExempt:     req.IsTaxExempt,
+		HasCreditLimit:  req.HasCreditLimit,
+		OverCreditLimit: req.OverCreditLimit,
+		MissingTaxID:    req.MissingTaxId,
+		Locale:          req.Locale,
+		MissingLocale:   req.MissingLocale,
+		Currency:        strings.ToUpper(req.Currency),
+		Tag:             service.NormalizeTag(req.Tag),
+		Name:            strings.TrimSpace(req.Name),
+		// The response cannot tell estimated totals apart
+		TotalMode: repository.TotalExact,
+	}
+	statusCode, err := vendorStatusFromProto(req.StatusEnum, req.Status)
+	if err != nil {
+		return nil, err
+	}
+	if statusCode != "" {
+		filter.Status = &statusCode
+	}
+	typeCode, err := vendorTypeFromProto(req.VendorTypeEnum, req.VendorType)
+	if err != nil {
+		return nil, err
+	}
+	if typeCode != "" {
+		filter.VendorType = &typeCode
+	}
+
+	result, err := h.vendorService.ListVendorSummaries(ctx, filter, page, pageSize)
+	if err != nil {
+		h.log.Error().Err(err).Msg("Failed to list vendor summaries")
+		return nil, toGRPCError(err)
+	}
+
+	summaries := make([]*pb.VendorSummary, len(result.Vendors))
+	for i, sum := range result.Vendors {
+		summaries[i] = &pb.VendorSummary{
+			Id:                  sum.ID,
+			VendorCode:          sum.VendorCode,
+			VendorName:          sum.VendorName,
+			Status:              sum.Status,
+			Currency:            sum.Currency,
+			IsPreferred:         sum.IsPreferred,
+			PrimaryContactEmail: stringToProto(sum.PrimaryContactEmail),
+			StatusEnum:          vendorStatusToProto(sum.Status),
+		}
+	}
+
+	return &pb.ListVendorSummariesResponse{
+		Vendors:  summaries,
+		Total:    result.Total,
+		Page:     int32(page),
+		PageSize: int32(pageSize),
+	}, nil
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+	"github.com/pesio-ai/be-lib-common/auth"
+)
+
+// GetVendorActivity handles GET /api/v1/vendors/{id}/activity requests
+func (h *HTTPHandler) GetVendorActivity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id", "type", "since", "cursor", "limit", "resolve_users") {
+		return
+	}
+
+	vendorID := r.PathValue("id")
+	entityID := r.URL.Query().Get("entity_id")
+	reqlog.SetVendor(r.Context(), vendorID)
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	q := service.ActivityQuery{Cursor: r.URL.Query().Get("cursor")}
+	if raw := r.URL.Query().Get("type"); raw != "" {
+		q.Types = strings.Split(raw, ",")
+	}
+	since, perr := queryTimestamp(r, "since")
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+	q.Since = since
+	limit, perr := queryInt(r, "limit", service.DefaultActivityLimit, 1, service.MaxActivityLimit)
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+	q.Limit = limit
+	resolveUsers, perr := queryBool(r, "resolve_users")
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+	q.ResolveUsers = resolveUsers != nil && *resolveUsers
+
+	var userID string
+	if user, err := auth.GetUserContext(r.Context()); err == nil && user != nil {
+		userID = user.UserID
+	}
+
+	page, err := h.service.GetVendorActivity(r.Context(), vendorID, entityID, q, func(section string) bool {
+		return h.opts.Sections.Allows(section, userID)
+	})
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
@@ -0,0 +1,428 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+)
+
+// adminTokenHeader carries the shared token of internal admin endpoints
+const adminTokenHeader = "X-Admin-Token"
+
+const codeForbidden = "FORBIDDEN"
+
+// requireAdminToken rejects requests without the configured admin token. It
+// returns false after writing the error response.
+func (h *HTTPHandler) requireAdminToken(w http.ResponseWriter, r *http.Request) bool {
+	return checkAdminToken(w, r, h.opts.AdminToken)
+}
+
+// RequireAdminToken guards every route of next with the admin token
+func RequireAdminToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !checkAdminToken(w, r, token) {
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func checkAdminToken(w http.ResponseWriter, r *http.Request, adminToken string) bool {
+	if adminToken == "" {
+		writeError(w, http.StatusForbidden, errorBody{
+			Code:    codeForbidden,
+			Message: "admin endpoints are disabled (ADMIN_API_TOKEN is not set)",
+		})
+		return false
+	}
+
+	token := r.Header.Get(adminTokenHeader)
+	if subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+		writeError(w, http.StatusForbidden, errorBody{
+			Code:    codeForbidden,
+			Message: "invalid or missing " + adminTokenHeader + " header",
+		})
+		return false
+	}
+
+	return true
+}
+
+// hasAdminToken reports whether the request carries the configured admin
+// token, without rejecting it otherwise
+func (h *HTTPHandler) hasAdminToken(r *http.Request) bool {
+	token := r.Header.Get(adminTokenHeader)
+	return h.opts.AdminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(h.opts.AdminToken)) == 1
+}
+
+// Purge handles POST /api/v1/admin/purge requests
+func (h *HTTPHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.requireAdminToken(w, r) {
+		return
+	}
+
+	var req struct {
+		DryRun    bool `json:"dry_run"`
+		BatchSize int  `json:"batch_size,omitempty"`
+	}
+	if r.ContentLength != 0 {
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+	}
+
+	report, err := h.service.Purge(r.Context(), service.PurgeOptions{
+		Defaults:  h.opts.Retention,
+		BatchSize: req.BatchSize,
+		DryRun:    req.DryRun,
+	})
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// RetentionSettings handles GET/PUT /api/v1/admin/retention requests
+func (h *HTTPHandler) RetentionSettings(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdminToken(w, r) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if !h.checkQueryParams(w, r, "entity_id") {
+			return
+		}
+
+		entityID := r.URL.Query().Get("entity_id")
+		if entityID == "" {
+			http.Error(w, "Entity ID is required", http.StatusBadRequest)
+			return
+		}
+
+		settings, err := h.service.GetRetentionSettings(r.Context(), entityID)
+		if err != nil {
+			writeServiceError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		h.writeRetentionSettings(w, settings)
+
+	case http.MethodPut:
+		var settings repository.RetentionSettings
+		if !decodeJSON(w, r, &settings) {
+			return
+		}
+
+		if err := h.service.SetRetentionSettings(r.Context(), &settings); err != nil {
+			writeServiceError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		h.writeRetentionSettings(w, &settings)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// writeRetentionSettings writes entity settings together with the effective retention
+func (h *HTTPHandler) writeRetentionSettings(w http.ResponseWriter, settings *repository.RetentionSettings) {
+	effective := h.opts.Retention
+	if settings.DeletedVendorRetentionDays != nil {
+		effective.DeletedVendorDays = *settings.DeletedVendorRetentionDays
+	}
+	if settings.AuditLogRetentionDays != nil {
+		effective.AuditLogDays = *settings.AuditLogRetentionDays
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"settings":  settings,
+		"effective": effective,
+	})
+}
+
+// ValidationSettings handles GET/PUT /api/v1/admin/validation-settings requests
+func (h *HTTPHandler) ValidationSettings(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdminToken(w, r) {
+		return
+	}
+
+	var settings *repository.ValidationSettings
+	switch r.Method {
+	case http.MethodGet:
+		if !h.checkQueryParams(w, r, "entity_id") {
+			return
+		}
+
+		entityID := r.URL.Query().Get("entity_id")
+		if entityID == "" {
+			http.Error(w, "Entity ID is required", http.StatusBadRequest)
+			return
+		}
+
+		var err error
+		settings, err = h.service.GetValidationSettings(r.Context(), entityID)
+		if err != nil {
+			writeServiceError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+	case http.MethodPut:
+		settings = &repository.ValidationSettings{}
+		if !decodeJSON(w, r, settings) {
+			return
+		}
+
+		if err := h.service.SetValidationSettings(r.Context(), settings); err != nil {
+			writeServiceError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	effective, err := h.service.EffectiveEntityFlags(r.Context(), settings.EntityID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"settings":  settings,
+		"effective": effective,
+	})
+}
+
+// EntitySettings handles GET/PUT /api/v1/admin/entity-settings requests
+func (h *HTTPHandler) EntitySettings(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdminToken(w, r) {
+		return
+	}
+
+	var settings *repository.EntitySettings
+	switch r.Method {
+	case http.MethodGet:
+		if !h.checkQueryParams(w, r, "entity_id") {
+			return
+		}
+
+		entityID := r.URL.Query().Get("entity_id")
+		if entityID == "" {
+			http.Error(w, "Entity ID is required", http.StatusBadRequest)
+			return
+		}
+
+		var err error
+		settings, err = h.service.GetEntitySettings(r.Context(), entityID)
+		if err != nil {
+			writeServiceError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+	case http.MethodPut:
+		settings = &repository.EntitySettings{}
+		if !decodeJSON(w, r, settings) {
+			return
+		}
+
+		if err := h.service.SetEntitySettings(r.Context(), settings); err != nil {
+			writeServiceError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	effective, err := h.service.EffectiveEntityFlags(r.Context(), settings.EntityID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"settings":  settings,
+		"effective": effective,
+	})
+}
+
+// ApprovalPolicy handles GET/PUT /api/v1/admin/approval-policy requests
+func (h *HTTPHandler) ApprovalPolicy(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdminToken(w, r) {
+		return
+	}
+
+	var policy *repository.ApprovalPolicy
+	switch r.Method {
+	case http.MethodGet:
+		if !h.checkQueryParams(w, r, "entity_id") {
+			return
+		}
+
+		entityID := r.URL.Query().Get("entity_id")
+		if entityID == "" {
+			http.Error(w, "Entity ID is required", http.StatusBadRequest)
+			return
+		}
+
+		var err error
+		policy, err = h.service.GetApprovalPolicy(r.Context(), entityID)
+		if err != nil {
+			writeServiceError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+	case http.MethodPut:
+		policy = &repository.ApprovalPolicy{}
+		if !decodeJSON(w, r, policy) {
+			return
+		}
+
+		if err := h.service.SetApprovalPolicy(r.Context(), policy); err != nil {
+			writeServiceError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policy)
+}
+
+// NormalizeAddresses handles POST /api/v1/admin/normalize-addresses requests
+func (h *HTTPHandler) NormalizeAddresses(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.requireAdminToken(w, r) {
+		return
+	}
+
+	var req struct {
+		EntityID string `json:"entity_id,omitempty"`
+		Apply    bool   `json:"apply"`
+	}
+	if r.ContentLength != 0 {
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+	}
+
+	report, err := h.service.NormalizeAddresses(r.Context(), service.AddressNormalizationOptions{
+		EntityID: req.EntityID,
+		Apply:    req.Apply,
+	})
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// RecomputeRiskScores handles POST /api/v1/admin/risk-scores/recompute requests
+func (h *HTTPHandler) RecomputeRiskScores(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.requireAdminToken(w, r) {
+		return
+	}
+
+	var req struct {
+		EntityID string `json:"entity_id,omitempty"`
+	}
+	if r.ContentLength != 0 {
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+	}
+
+	report, err := h.service.RecomputeRiskScores(r.Context(), req.EntityID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// RiskWeights handles GET/PUT /api/v1/admin/risk-weights requests. A PUT
+// replaces the entity's overrides and rescores its vendors.
+func (h *HTTPHandler) RiskWeights(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdminToken(w, r) {
+		return
+	}
+
+	var entityID string
+	var report *service.RiskRecomputeReport
+	switch r.Method {
+	case http.MethodGet:
+		if !h.checkQueryParams(w, r, "entity_id") {
+			return
+		}
+		entityID = r.URL.Query().Get("entity_id")
+
+	case http.MethodPut:
+		var req struct {
+			EntityID  string         `json:"entity_id"`
+			Weights   map[string]int `json:"weights"`
+			UpdatedBy string         `json:"updated_by,omitempty"`
+		}
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+		entityID = req.EntityID
+
+		var err error
+		if report, err = h.service.SetRiskWeights(r.Context(), req.EntityID, req.Weights, req.UpdatedBy); err != nil {
+			writeServiceError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	weights, err := h.service.GetRiskWeights(r.Context(), entityID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"weights":   weights,
+		"recompute": report,
+	})
+}
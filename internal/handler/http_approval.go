@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+	"github.com/pesio-ai/be-lib-common/auth"
+)
+
+// ListApprovalQueue handles GET /api/v1/vendors/approval-queue requests
+func (h *HTTPHandler) ListApprovalQueue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id", "sort", "page", "page_size") {
+		return
+	}
+
+	query := r.URL.Query()
+	entityID := query.Get("entity_id")
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	page, perr := queryInt(r, "page", 1, 1, math.MaxInt32)
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+	pageSize, perr := queryInt(r, "page_size", 50, 1, 100)
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+
+	queue, err := h.service.ListApprovalQueue(r.Context(), entityID, query.Get("sort"), page, pageSize)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(queue)
+}
+
+// CountApprovalQueue handles GET /api/v1/vendors/approval-queue/count requests
+func (h *HTTPHandler) CountApprovalQueue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id") {
+		return
+	}
+
+	entityID := r.URL.Query().Get("entity_id")
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	count, err := h.service.CountApprovalQueue(r.Context(), entityID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(count)
+}
+
+// ApproveVendor handles POST /api/v1/vendors/{id}/approve requests
+func (h *HTTPHandler) ApproveVendor(w http.ResponseWriter, r *http.Request) {
+	h.decideApproval(w, r, h.service.ApproveVendor)
+}
+
+// RejectVendor handles POST /api/v1/vendors/{id}/reject requests
+func (h *HTTPHandler) RejectVendor(w http.ResponseWriter, r *http.Request) {
+	h.decideApproval(w, r, h.service.RejectVendor)
+}
+
+// decideApproval applies an approval decision to the vendor in the path
+func (h *HTTPHandler) decideApproval(w http.ResponseWriter, r *http.Request, decide func(ctx context.Context, req *service.ApprovalDecisionRequest) (*repository.Vendor, error)) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req service.ApprovalDecisionRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	req.VendorID = r.PathValue("id")
+	reqlog.SetVendor(r.Context(), req.VendorID)
+
+	if user, err := auth.GetUserContext(r.Context()); err == nil && user != nil {
+		req.DecidedBy = user.UserID
+	}
+
+	vendor, err := decide(r.Context(), &req)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(vendor)
+}
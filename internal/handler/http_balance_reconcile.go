@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ReconcileBalances handles POST /api/v1/admin/balances/reconcile requests,
+// reporting the vendors of an entity or of all entities whose current_balance
+// differs from their balance ledger, and correcting them with fix
+func (h *HTTPHandler) ReconcileBalances(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.requireAdminToken(w, r) {
+		return
+	}
+
+	var req struct {
+		EntityID string `json:"entity_id,omitempty"`
+		Fix      bool   `json:"fix"`
+	}
+	if r.ContentLength != 0 {
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+	}
+
+	report, err := h.service.ReconcileBalances(r.Context(), req.EntityID, req.Fix)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+)
+
+// ListBankChanges handles GET /api/v1/vendors/bank-changes requests
+func (h *HTTPHandler) ListBankChanges(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id", "since", "limit") {
+		return
+	}
+
+	entityID := r.URL.Query().Get("entity_id")
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	since, perr := queryTimestamp(r, "since")
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+
+	limit, perr := queryInt(r, "limit", service.DefaultBankChangesLimit, 1, service.MaxBankChangesLimit)
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+
+	changes, err := h.service.ListBankChanges(r.Context(), entityID, since, limit)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"changes": changes,
+	})
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+	"github.com/pesio-ai/be-lib-common/auth"
+)
+
+// FindVendorsByBankLast4 handles GET /api/v1/vendors/find-by-bank-last4
+// requests. The lookup reads bank details, so it is restricted like the bank
+// section of the snapshot, needs an authenticated user, and answers with the
+// last four digits only.
+func (h *HTTPHandler) FindVendorsByBankLast4(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id", "last4", "limit") {
+		return
+	}
+
+	entityID := r.URL.Query().Get("entity_id")
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Every lookup is logged with its user, so anonymous lookups are refused
+	var userID string
+	if user, err := auth.GetUserContext(r.Context()); err == nil && user != nil {
+		userID = user.UserID
+	}
+	if userID == "" {
+		writeError(w, http.StatusUnauthorized, errorBody{
+			Code:    codeUnauthorized,
+			Message: "an authenticated user is required (Authorization: Bearer header)",
+		})
+		return
+	}
+	if !h.opts.Sections.Allows(service.SnapshotBank, userID) {
+		writeError(w, http.StatusForbidden, errorBody{
+			Code:    codeForbidden,
+			Message: "vendor bank details are restricted",
+		})
+		return
+	}
+
+	limit, perr := queryInt(r, "limit", service.DefaultBankLast4Limit, 1, service.MaxBankLast4Limit)
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+
+	matches, err := h.service.FindVendorsByBankLast4(r.Context(), entityID, r.URL.Query().Get("last4"), userID, limit)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"vendors": matches,
+	})
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+)
+
+// StartBankVerification handles POST /api/v1/vendors/{id}/bank-verification
+// requests, starting the verification of the vendor's bank account
+func (h *HTTPHandler) StartBankVerification(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.checkQueryParams(w, r, "entity_id") {
+		return
+	}
+
+	entityID := r.URL.Query().Get("entity_id")
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+	vendorID := r.PathValue("id")
+	reqlog.SetVendor(r.Context(), vendorID)
+
+	var req struct {
+		Method string `json:"method,omitempty"`
+	}
+	if r.ContentLength != 0 {
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+	}
+
+	verification, err := h.service.StartBankVerification(r.Context(), vendorID, entityID, req.Method)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(verification)
+}
+
+// ConfirmBankVerification handles POST
+// /api/v1/vendors/{id}/bank-verification/confirm requests, completing a
+// pending micro-deposit verification with the amounts the vendor received
+func (h *HTTPHandler) ConfirmBankVerification(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.checkQueryParams(w, r, "entity_id") {
+		return
+	}
+
+	entityID := r.URL.Query().Get("entity_id")
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+	vendorID := r.PathValue("id")
+	reqlog.SetVendor(r.Context(), vendorID)
+
+	var req struct {
+		Amounts []int64 `json:"amounts"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	verification, err := h.service.ConfirmBankVerification(r.Context(), vendorID, entityID, req.Amounts)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(verification)
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+)
+
+// Blocklist handles /api/v1/admin/blocklist: GET lists the organization
+// blocklist, POST adds an entry
+func (h *HTTPHandler) Blocklist(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdminToken(w, r) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if !h.checkQueryParams(w, r) {
+			return
+		}
+
+		entries, err := h.service.ListBlocklistEntries(r.Context())
+		if err != nil {
+			writeServiceError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"entries": entries})
+
+	case http.MethodPost:
+		var req service.AddBlocklistEntryRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+
+		entry, err := h.service.AddBlocklistEntry(r.Context(), &req)
+		if err != nil {
+			writeServiceError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(entry)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// DeleteBlocklistEntry handles DELETE /api/v1/admin/blocklist/{id} requests
+func (h *HTTPHandler) DeleteBlocklistEntry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.requireAdminToken(w, r) {
+		return
+	}
+
+	if err := h.service.RemoveBlocklistEntry(r.Context(), r.PathValue("id")); err != nil {
+		writeServiceError(w, err, http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BlocklistMatches handles GET /api/v1/admin/blocklist/matches requests,
+// listing the vendors found on the blocklist at create or approval
+func (h *HTTPHandler) BlocklistMatches(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.requireAdminToken(w, r) {
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id", "limit") {
+		return
+	}
+
+	limit, perr := queryInt(r, "limit", service.DefaultBlocklistMatchLimit, 1, service.MaxBlocklistMatchLimit)
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+
+	matches, err := h.service.ListBlocklistMatches(r.Context(), r.URL.Query().Get("entity_id"), limit)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"matches": matches})
+}
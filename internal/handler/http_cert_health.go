@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/certs"
+)
+
+// Certificate health states reported by /health/tls
+const (
+	certStatusOK       = "ok"
+	certStatusExpiring = "expiring"
+	certStatusExpired  = "expired"
+	certStatusDisabled = "disabled"
+)
+
+// CertHealthHandler reports the expiry of the loaded TLS certificates
+type CertHealthHandler struct {
+	sources    []*certs.Source
+	warnBefore time.Duration
+}
+
+// NewCertHealthHandler creates a handler reporting sources; certificates
+// expiring within warnBefore are reported as expiring
+func NewCertHealthHandler(warnBefore time.Duration, sources ...*certs.Source) *CertHealthHandler {
+	h := &CertHealthHandler{warnBefore: warnBefore}
+	for _, source := range sources {
+		if source != nil {
+			h.sources = append(h.sources, source)
+		}
+	}
+	return h
+}
+
+type certHealth struct {
+	Name             string     `json:"name"`
+	Subject          string     `json:"subject,omitempty"`
+	NotAfter         *time.Time `json:"not_after,omitempty"`
+	CANotAfter       *time.Time `json:"ca_not_after,omitempty"`
+	Status           string     `json:"status"`
+	ExpiresInSeconds int64      `json:"expires_in_seconds"`
+}
+
+// ServeHTTP handles GET /health/tls requests. It responds 503 when a loaded
+// certificate has expired.
+func (h *CertHealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	now := time.Now()
+	overall := certStatusDisabled
+	if len(h.sources) > 0 {
+		overall = certStatusOK
+	}
+
+	certificates := make([]certHealth, 0, len(h.sources))
+	for _, source := range h.sources {
+		st := source.Status()
+		expiry := st.Expiry()
+		if expiry.IsZero() {
+			// Trusts only the system roots; nothing of ours can expire
+			continue
+		}
+		c := certHealth{
+			Name:             st.Name,
+			Subject:          st.Subject,
+			NotAfter:         st.NotAfter,
+			CANotAfter:       st.CANotAfter,
+			Status:           certStatusOK,
+			ExpiresInSeconds: int64(expiry.Sub(now).Seconds()),
+		}
+		switch {
+		case !now.Before(expiry):
+			c.Status = certStatusExpired
+			overall = certStatusExpired
+		case expiry.Sub(now) < h.warnBefore:
+			c.Status = certStatusExpiring
+			if overall == certStatusOK {
+				overall = certStatusExpiring
+			}
+		}
+		certificates = append(certificates, c)
+	}
+
+	status := http.StatusOK
+	if overall == certStatusExpired {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":       overall,
+		"certificates": certificates,
+	})
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+)
+
+// ListVendorChanges handles GET /api/v1/vendors/changes requests
+func (h *HTTPHandler) ListVendorChanges(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id", "since", "limit") {
+		return
+	}
+
+	entityID := r.URL.Query().Get("entity_id")
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var since int64
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || v < 0 {
+			writeParamError(w, &paramError{Field: "since", Message: "since must be a non-negative integer watermark"})
+			return
+		}
+		since = v
+	}
+
+	limit, perr := queryInt(r, "limit", service.DefaultChangesLimit, 1, service.MaxChangesLimit)
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+
+	changes, err := h.service.ListVendorChanges(r.Context(), entityID, since, limit)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(changes)
+}
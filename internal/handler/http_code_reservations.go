@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pesio-ai/be-lib-common/auth"
+)
+
+// ReserveVendorCode handles POST /api/v1/vendors/code-reservations, holding
+// the next free vendor code of an entity for a vendor about to be created.
+// The code is reserved for the authenticated user, or reserved_by without one.
+func (h *HTTPHandler) ReserveVendorCode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		EntityID   string `json:"entity_id"`
+		Prefix     string `json:"prefix,omitempty"`
+		ReservedBy string `json:"reserved_by,omitempty"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if user, err := auth.GetUserContext(r.Context()); err == nil && user != nil {
+		req.ReservedBy = user.UserID
+	}
+
+	res, err := h.service.ReserveVendorCode(r.Context(), req.EntityID, req.Prefix, req.ReservedBy)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(res)
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+// vendorETag is a weak validator derived from the vendor's change sequence,
+// which changes on every mutation of the row and of the state returned with
+// the vendor from other tables: approvals and the approval policy, the risk
+// score, the TIN match and the bank verification
+func vendorETag(vendor *repository.Vendor) string {
+	return `W/"` + strconv.FormatInt(vendor.ChangeSeq, 10) + `"`
+}
+
+// writeVendorValidators sets ETag and Last-Modified for a vendor response and
+// reports whether the request's conditional headers match, in which case a 304
+// has been written and no body must follow.
+//
+// HTTP dates only have second granularity while updated_at is stored with
+// microseconds, so Last-Modified is omitted while the vendor's last-modified
+// second is still in progress: a client can otherwise receive a date that a
+// later update within the same second would not advance, and get a stale 304.
+func writeVendorValidators(w http.ResponseWriter, r *http.Request, vendor *repository.Vendor) bool {
+	etag := vendorETag(vendor)
+	modified := vendor.UpdatedAt.UTC().Truncate(time.Second)
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if time.Now().UTC().Truncate(time.Second).After(modified) {
+		w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	// If-None-Match takes precedence over If-Modified-Since (RFC 9110 13.2.2)
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if !etagMatches(inm, etag) {
+			return false
+		}
+	} else if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		since, err := http.ParseTime(ims)
+		if err != nil || modified.After(since) || w.Header().Get("Last-Modified") == "" {
+			return false
+		}
+	} else {
+		return false
+	}
+
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches applies the weak comparison of an If-None-Match header to etag
+func etagMatches(header, etag string) bool {
+	opaque := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == opaque {
+			return true
+		}
+	}
+	return false
+}
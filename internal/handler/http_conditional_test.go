@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+func TestWriteVendorValidators(t *testing.T) {
+	updated := time.Date(2024, 3, 5, 14, 30, 15, 250_000_000, time.UTC)
+	header := updated.Truncate(time.Second).Format(http.TimeFormat)
+	vendor := &repository.Vendor{ChangeSeq: 42, UpdatedAt: updated}
+
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		want304 bool
+	}{
+		{"unconditional", http.MethodGet, nil, false},
+		{"If-Modified-Since equal to updated_at", http.MethodGet, map[string]string{"If-Modified-Since": header}, true},
+		{"If-Modified-Since after updated_at", http.MethodGet, map[string]string{"If-Modified-Since": updated.Add(time.Second).Format(http.TimeFormat)}, true},
+		{"If-Modified-Since a second before updated_at", http.MethodGet, map[string]string{"If-Modified-Since": updated.Add(-time.Second).Format(http.TimeFormat)}, false},
+		{"If-Modified-Since malformed", http.MethodGet, map[string]string{"If-Modified-Since": "yesterday"}, false},
+		{"If-None-Match current", http.MethodGet, map[string]string{"If-None-Match": `W/"42"`}, true},
+		{"If-None-Match strong form", http.MethodGet, map[string]string{"If-None-Match": `"42"`}, true},
+		{"If-None-Match in a list", http.MethodGet, map[string]string{"If-None-Match": `W/"40", W/"42"`}, true},
+		{"If-None-Match wildcard", http.MethodGet, map[string]string{"If-None-Match": "*"}, true},
+		{"If-None-Match stale", http.MethodGet, map[string]string{"If-None-Match": `W/"41"`}, false},
+		{
+			"If-None-Match stale takes precedence over If-Modified-Since",
+			http.MethodGet,
+			map[string]string{"If-None-Match": `W/"41"`, "If-Modified-Since": header},
+			false,
+		},
+		{"HEAD", http.MethodHead, map[string]string{"If-None-Match": `W/"42"`}, true},
+		{"not a read", http.MethodPut, map[string]string{"If-None-Match": `W/"42"`}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/api/v1/vendors/id", nil)
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+
+			if got := writeVendorValidators(w, r, vendor); got != tt.want304 {
+				t.Errorf("writeVendorValidators() = %v, want %v", got, tt.want304)
+			}
+			if tt.want304 && w.Code != http.StatusNotModified {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusNotModified)
+			}
+			if got := w.Header().Get("ETag"); got != `W/"42"` {
+				t.Errorf("ETag = %q, want %q", got, `W/"42"`)
+			}
+			if got := w.Header().Get("Last-Modified"); got != header {
+				t.Errorf("Last-Modified = %q, want %q", got, header)
+			}
+		})
+	}
+}
+
+// TestWriteVendorValidatorsCurrentSecond checks a vendor updated within the
+// current second gets no Last-Modified, since a later update in the same
+// second would not advance it, and so never answers If-Modified-Since with 304
+func TestWriteVendorValidatorsCurrentSecond(t *testing.T) {
+	updated := time.Now().UTC().Add(time.Hour)
+	vendor := &repository.Vendor{ChangeSeq: 7, UpdatedAt: updated}
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/vendors/id", nil)
+	r.Header.Set("If-Modified-Since", updated.Truncate(time.Second).Format(http.TimeFormat))
+	w := httptest.NewRecorder()
+
+	if writeVendorValidators(w, r, vendor) {
+		t.Error("writeVendorValidators() = true, want false")
+	}
+	if got := w.Header().Get("Last-Modified"); got != "" {
+		t.Errorf("Last-Modified = %q, want none", got)
+	}
+	if got := w.Header().Get("ETag"); got != `W/"7"` {
+		t.Errorf("ETag = %q, want %q", got, `W/"7"`)
+	}
+}
+
+func TestGetVendorConditional(t *testing.T) {
+	h, svc := newTestHTTPHandler(t)
+	vendor := createTestVendor(t, svc, "NW-001")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/vendors/{id}", h.GetVendor)
+	mux.HandleFunc("/api/v1/vendors/code", h.GetVendorByCode)
+	get := func(target string, headers map[string]string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		for k, v := range headers {
+			r.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+		return w
+	}
+
+	for _, target := range []string{
+		"/api/v1/vendors/" + vendor.ID + "?entity_id=" + testEntityID,
+		"/api/v1/vendors/code?vendor_code=nw-001&entity_id=" + testEntityID,
+	} {
+		first := get(target, nil)
+		if first.Code != http.StatusOK {
+			t.Fatalf("GET %s status = %d, want %d: %s", target, first.Code, http.StatusOK, first.Body)
+		}
+		etag := first.Header().Get("ETag")
+		if etag == "" {
+			t.Fatalf("GET %s sent no ETag", target)
+		}
+
+		again := get(target, map[string]string{"If-None-Match": etag})
+		if again.Code != http.StatusNotModified {
+			t.Errorf("GET %s with If-None-Match status = %d, want %d", target, again.Code, http.StatusNotModified)
+		}
+		if again.Body.Len() != 0 {
+			t.Errorf("GET %s with If-None-Match sent a body: %s", target, again.Body)
+		}
+	}
+
+	// An update changes the ETag, so the old one no longer matches
+	first := get("/api/v1/vendors/"+vendor.ID+"?entity_id="+testEntityID, nil)
+	if _, err := svc.SuspendVendor(t.Context(), vendor.ID, testEntityID, ""); err != nil {
+		t.Fatalf("SuspendVendor() error = %v", err)
+	}
+	after := get("/api/v1/vendors/"+vendor.ID+"?entity_id="+testEntityID, map[string]string{"If-None-Match": first.Header().Get("ETag")})
+	if after.Code != http.StatusOK {
+		t.Errorf("GET after an update status = %d, want %d", after.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+	"github.com/pesio-ai/be-lib-common/auth"
+)
+
+// DeleteVendorContact handles DELETE /api/v1/vendors/{id}/contacts/{contact_id}
+// requests, refusing to remove the last recipient of a required communication
+// type
+func (h *HTTPHandler) DeleteVendorContact(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id") {
+		return
+	}
+
+	vendorID := r.PathValue("id")
+	entityID := r.URL.Query().Get("entity_id")
+	reqlog.SetVendor(r.Context(), vendorID)
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var removedBy *string
+	if user, err := auth.GetUserContext(r.Context()); err == nil && user != nil {
+		removedBy = &user.UserID
+	}
+
+	if err := h.service.DeleteVendorContact(r.Context(), vendorID, r.PathValue("contact_id"), entityID, removedBy); err != nil {
+		writeServiceError(w, err, http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetContactCommunications handles PATCH
+// /api/v1/vendors/{id}/contacts/{contact_id}/communications requests, turning
+// the communication types a contact receives on or off
+func (h *HTTPHandler) SetContactCommunications(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id") {
+		return
+	}
+
+	var req service.ContactCommunicationsRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	req.VendorID = r.PathValue("id")
+	req.ContactID = r.PathValue("contact_id")
+	req.EntityID = r.URL.Query().Get("entity_id")
+	reqlog.SetVendor(r.Context(), req.VendorID)
+	if user, err := auth.GetUserContext(r.Context()); err == nil && user != nil {
+		req.UpdatedBy = &user.UserID
+	}
+
+	contact, err := h.service.SetContactCommunications(r.Context(), &req)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(contact)
+}
+
+// GetContactsForCommunication handles GET
+// /api/v1/vendors/{id}/communication-contacts requests, listing the contacts
+// of a vendor receiving a communication type
+func (h *HTTPHandler) GetContactsForCommunication(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id", "type") {
+		return
+	}
+
+	vendorID := r.PathValue("id")
+	entityID := r.URL.Query().Get("entity_id")
+	commType := r.URL.Query().Get("type")
+	reqlog.SetVendor(r.Context(), vendorID)
+
+	contacts, err := h.service.GetContactsForCommunication(r.Context(), vendorID, entityID, commType)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"vendor_id":          vendorID,
+		"communication_type": commType,
+		"contacts":           contacts,
+	})
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+)
+
+// ExportContacts handles GET /api/v1/vendors/contacts/export requests
+func (h *HTTPHandler) ExportContacts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id") {
+		return
+	}
+
+	entityID := r.URL.Query().Get("entity_id")
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	contacts, err := h.service.ListEntityContacts(r.Context(), entityID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := service.WriteContactsCSV(&buf, contacts); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="vendor-contacts.csv"`)
+	buf.WriteTo(w)
+}
+
+// ListDuplicateContactEmails handles GET
+// /api/v1/vendors/contacts/duplicate-emails requests, reporting the emails
+// used by several contacts of one vendor
+func (h *HTTPHandler) ListDuplicateContactEmails(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id") {
+		return
+	}
+
+	entityID := r.URL.Query().Get("entity_id")
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	duplicates, err := h.service.ListDuplicateContactEmails(r.Context(), entityID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"duplicates": duplicates,
+	})
+}
+
+// ImportContacts handles POST /api/v1/vendors/contacts/import requests. The
+// CSV is the request body, or the "file" field of a multipart form; with
+// template_id its header is translated by that import template.
+func (h *HTTPHandler) ImportContacts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id", "dry_run", "template_id", "upsert") {
+		return
+	}
+
+	entityID := r.URL.Query().Get("entity_id")
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	dryRun, perr := queryBool(r, "dry_run")
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+	upsert, perr := queryBool(r, "upsert")
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+
+	// The body is read up front so that an upload over the body limit fails
+	// with a 413 rather than as a CSV error halfway through the import
+	var src io.Reader
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			if !isBodyTooLarge(w, err) {
+				http.Error(w, "Multipart uploads need a \"file\" field", http.StatusBadRequest)
+			}
+			return
+		}
+		defer file.Close()
+		src = file
+	} else {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			if !isBodyTooLarge(w, err) {
+				http.Error(w, "Failed to read request body", http.StatusBadRequest)
+			}
+			return
+		}
+		src = bytes.NewReader(body)
+	}
+
+	result, err := h.service.ImportContacts(r.Context(), &service.ImportContactsRequest{
+		EntityID:   entityID,
+		CSV:        src,
+		DryRun:     dryRun != nil && *dryRun,
+		TemplateID: r.URL.Query().Get("template_id"),
+		Upsert:     upsert != nil && *upsert,
+	})
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusOK
+	if !result.DryRun && result.Failed > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
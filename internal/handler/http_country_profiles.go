@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ListCountryProfiles handles GET /api/v1/country-profiles requests, listing
+// every profile, or the profile of one country with ?country=
+func (h *HTTPHandler) ListCountryProfiles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "country") {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if code := r.URL.Query().Get("country"); code != "" {
+		profile, err := h.service.GetCountryProfile(r.Context(), code)
+		if err != nil {
+			writeServiceError(w, err, http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(profile)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"profiles": h.service.ListCountryProfiles(r.Context()),
+	})
+}
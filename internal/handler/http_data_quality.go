@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+)
+
+// GetDataQualityReport handles GET /api/v1/vendors/data-quality requests,
+// counting the vendors failing each enabled data quality rule and listing a
+// page of them per rule
+func (h *HTTPHandler) GetDataQualityReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id", "rule", "page", "page_size") {
+		return
+	}
+
+	entityID := r.URL.Query().Get("entity_id")
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	page, perr := queryInt(r, "page", 1, 1, math.MaxInt32)
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+
+	pageSize, perr := queryInt(r, "page_size", service.DefaultDataQualityPageSize, 1, service.MaxDataQualityPageSize)
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+
+	report, err := h.service.GetDataQualityReport(r.Context(), entityID, r.URL.Query().Get("rule"), page, pageSize)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pesio-ai/be-ap-vendors/internal/buildinfo"
+)
+
+// redacted replaces secret config values in the diagnostics output
+const redacted = "[REDACTED]"
+
+// DebugHandler serves diagnostics on the admin listener. It must never be
+// registered on the public mux.
+type DebugHandler struct {
+	serviceName string
+	version     string
+	config      map[string]interface{}
+	pool        *pgxpool.Pool
+	startedAt   time.Time
+}
+
+// NewDebugHandler creates a diagnostics handler. config is reported as-is, so
+// secrets must already be redacted (see RedactSecret).
+func NewDebugHandler(serviceName, version string, config map[string]interface{}, pool *pgxpool.Pool) *DebugHandler {
+	return &DebugHandler{
+		serviceName: serviceName,
+		version:     version,
+		config:      config,
+		pool:        pool,
+		startedAt:   time.Now(),
+	}
+}
+
+// RedactSecret reports whether a secret is configured without exposing it
+func RedactSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	return redacted
+}
+
+// Register adds /debug/info, /debug/vars and the pprof handlers to mux
+func (h *DebugHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/info", h.Info)
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+}
+
+// Info handles GET /debug/info requests
+func (h *DebugHandler) Info(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	info := map[string]interface{}{
+		"service":        h.serviceName,
+		"version":        h.version,
+		"build":          buildinfo.Get(),
+		"started_at":     h.startedAt.UTC(),
+		"uptime_seconds": int64(time.Since(h.startedAt).Seconds()),
+		"config":         h.config,
+		"runtime": map[string]interface{}{
+			"goroutines":       runtime.NumGoroutine(),
+			"gomaxprocs":       runtime.GOMAXPROCS(0),
+			"num_cpu":          runtime.NumCPU(),
+			"heap_alloc_bytes": mem.HeapAlloc,
+			"heap_inuse_bytes": mem.HeapInuse,
+			"sys_bytes":        mem.Sys,
+			"num_gc":           mem.NumGC,
+			"gc_pause_total":   time.Duration(mem.PauseTotalNs).String(),
+		},
+	}
+
+	if h.pool != nil {
+		stat := h.pool.Stat()
+		info["db_pool"] = map[string]interface{}{
+			"max_conns":                stat.MaxConns(),
+			"total_conns":              stat.TotalConns(),
+			"idle_conns":               stat.IdleConns(),
+			"acquired_conns":           stat.AcquiredConns(),
+			"constructing_conns":       stat.ConstructingConns(),
+			"acquire_count":            stat.AcquireCount(),
+			"empty_acquire_count":      stat.EmptyAcquireCount(),
+			"canceled_acquire_count":   stat.CanceledAcquireCount(),
+			"acquire_wait_total":       stat.AcquireDuration().String(),
+			"empty_acquire_wait_total": stat.EmptyAcquireWaitTime().String(),
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(info)
+}
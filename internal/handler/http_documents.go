@@ -0,0 +1,113 @@
+package handler
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/docstore"
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+)
+
+// Error codes of vendor document downloads
+const (
+	codeDocumentsDisabled     = "DOCUMENTS_DISABLED"
+	codeDocumentStorageFailed = "DOCUMENT_STORAGE_FAILED"
+	codeRangeNotSatisfiable   = "RANGE_NOT_SATISFIABLE"
+)
+
+// DownloadVendorDocument handles GET /api/v1/vendors/documents/{id}/download
+// requests for a document of a vendor of the authenticated user's entity. The
+// document is restricted like the documents section of the snapshot. Large
+// files answer with a redirect to a short-lived signed link; small ones are
+// streamed, honouring a Range header. Every download is recorded in the
+// vendor's audit trail.
+func (h *HTTPHandler) DownloadVendorDocument(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.checkQueryParams(w, r) {
+		return
+	}
+	userID, entityID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	if !h.opts.Sections.Allows(service.SnapshotDocuments, userID) {
+		writeError(w, http.StatusForbidden, errorBody{
+			Code:    codeForbidden,
+			Message: "vendor documents are restricted",
+		})
+		return
+	}
+
+	download, err := h.service.DownloadVendorDocument(r.Context(), r.PathValue("id"), entityID, userID, r.Header.Get("Range"))
+	if err != nil {
+		writeDocumentError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if download.Object == nil {
+		http.Redirect(w, r, download.RedirectURL, http.StatusFound)
+		return
+	}
+	defer download.Object.Body.Close()
+
+	obj := download.Object
+	contentType := obj.ContentType
+	if contentType == "" && download.Document.MimeType != nil {
+		contentType = *download.Document.MimeType
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, dispositionName(download.Document.DocumentName)))
+	w.Header().Set("Accept-Ranges", "bytes")
+	if obj.ContentLength >= 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(obj.ContentLength, 10))
+	}
+	if obj.ContentRange != "" {
+		w.Header().Set("Content-Range", obj.ContentRange)
+		w.WriteHeader(http.StatusPartialContent)
+	}
+	io.Copy(w, obj.Body)
+}
+
+// dispositionName makes a document name safe to quote in Content-Disposition
+func dispositionName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '"' || r == '\\' || r < ' ' || r == 0x7f {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// writeDocumentError maps the errors of document downloads to their status
+func writeDocumentError(w http.ResponseWriter, err error) {
+	switch {
+	case stderrors.Is(err, service.ErrDocumentStorageDisabled):
+		writeError(w, http.StatusServiceUnavailable, errorBody{
+			Code:    codeDocumentsDisabled,
+			Message: err.Error(),
+		})
+	case stderrors.Is(err, service.ErrDocumentStorageFailed):
+		writeError(w, http.StatusBadGateway, errorBody{
+			Code:    codeDocumentStorageFailed,
+			Message: err.Error(),
+		})
+	case stderrors.Is(err, docstore.ErrRangeNotSatisfiable):
+		writeError(w, http.StatusRequestedRangeNotSatisfiable, errorBody{
+			Code:    codeRangeNotSatisfiable,
+			Message: err.Error(),
+		})
+	default:
+		writeServiceError(w, err, http.StatusNotFound)
+	}
+}
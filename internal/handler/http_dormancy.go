@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+)
+
+// ListDormantVendors handles GET /api/v1/vendors/dormant, listing the vendors
+// of an entity flagged dormant by ID, a page at a time
+func (h *HTTPHandler) ListDormantVendors(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.checkQueryParams(w, r, "entity_id", "after", "limit") {
+		return
+	}
+
+	entityID := r.URL.Query().Get("entity_id")
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+	limit, perr := queryInt(r, "limit", service.DefaultDormantPageSize, 1, service.MaxDormantPageSize)
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+
+	page, err := h.service.ListDormantVendors(r.Context(), entityID, r.URL.Query().Get("after"), limit)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(page)
+}
+
+// DetectDormantVendors handles POST /api/v1/admin/dormant-vendors/detect
+// requests, flagging the dormant vendors of an entity or of all entities
+func (h *HTTPHandler) DetectDormantVendors(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.requireAdminToken(w, r) {
+		return
+	}
+
+	var req struct {
+		EntityID string `json:"entity_id,omitempty"`
+	}
+	if r.ContentLength != 0 {
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+	}
+
+	report, err := h.service.DetectDormantVendors(r.Context(), req.EntityID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+)
+
+// CleanupEntity handles POST /api/v1/admin/entity-cleanup requests, cleaning
+// up a deleted entity like an entity.deleted event does. A dry run reports
+// what would be deleted and cancelled.
+func (h *HTTPHandler) CleanupEntity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.requireAdminToken(w, r) {
+		return
+	}
+
+	var req struct {
+		EntityID    string `json:"entity_id"`
+		DryRun      bool   `json:"dry_run"`
+		RequestedBy string `json:"requested_by,omitempty"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	report, err := h.service.CleanupEntity(r.Context(), req.EntityID, req.DryRun, stringPtr(req.RequestedBy), nil)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// EntityLifecycle handles GET /api/v1/admin/entity-lifecycle requests,
+// returning the lifecycle state of an entity and its log
+func (h *HTTPHandler) EntityLifecycle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.requireAdminToken(w, r) {
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id", "limit") {
+		return
+	}
+
+	limit, perr := queryInt(r, "limit", service.DefaultEntityLifecycleLimit, 1, service.MaxEntityLifecycleLimit)
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+
+	lifecycle, err := h.service.GetEntityLifecycle(r.Context(), r.URL.Query().Get("entity_id"), limit)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lifecycle)
+}
@@ -0,0 +1,366 @@
+package handler
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+	"github.com/pesio-ai/be-ap-vendors/internal/tinmatch"
+	"github.com/pesio-ai/be-ap-vendors/internal/utctime"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// Error codes used in the HTTP error envelope
+const (
+	codeInvalidParameter  = "INVALID_PARAMETER"
+	codeUnknownParameter  = "UNKNOWN_PARAMETER"
+	codeValidationFailed  = "VALIDATION_FAILED"
+	codeQueryTimeout      = "QUERY_TIMEOUT"
+	codeQuotaExceeded     = "QUOTA_EXCEEDED"
+	codeDuplicateCreate   = "DUPLICATE_CREATE"
+	codeVendorCodeLocked  = "VENDOR_CODE_LOCKED"
+	codeTINMatchThrottled = "TIN_MATCH_THROTTLED"
+	codeEntityReadOnly    = "ENTITY_READ_ONLY"
+	codeDuplicateContact  = "DUPLICATE_CONTACT"
+	codeCurrencyBlocked   = "CURRENCY_CHANGE_BLOCKED"
+	codeVendorHasChildren = "VENDOR_HAS_CHILDREN"
+	codeForeignKey        = "FOREIGN_KEY_VIOLATION"
+	codeLastRecipient     = "LAST_COMMUNICATION_RECIPIENT"
+	codeNotFound          = "NOT_FOUND"
+	codeAlreadyExists     = "ALREADY_EXISTS"
+)
+
+// errorEnvelope is the structured error body returned by the HTTP API
+type errorEnvelope struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Code    string                   `json:"code"`
+	Message string                   `json:"message"`
+	Field   string                   `json:"field,omitempty"`
+	Details map[string]interface{}   `json:"details,omitempty"`
+	Errors  []service.FieldViolation `json:"errors,omitempty"`
+}
+
+// writeError writes a structured error envelope with the given status
+func writeError(w http.ResponseWriter, status int, body errorBody) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorEnvelope{Error: body})
+}
+
+// writeServiceError writes a 400 listing every field violation for validation
+// errors or naming a value the database could not parse, a 404 for missing
+// records, a 409 for debounced duplicate creates, duplicate contact emails,
+// locked vendor codes, writes to read-only entities, deletes of vendors with
+// child records, removals of the last recipient of a required communication
+// type, writes breaking a foreign key and records that already exist, such as
+// an external ID mapped to another vendor, a 429 for exceeded vendor quotas, a
+// 503 when the TIN matching provider throttles, a 504 for query timeouts, and
+// falls back to a plain error with fallbackStatus otherwise
+func writeServiceError(w http.ResponseWriter, err error, fallbackStatus int) {
+	if repository.IsQueryTimeout(err) {
+		writeError(w, http.StatusGatewayTimeout, errorBody{
+			Code:    codeQueryTimeout,
+			Message: "the database did not respond in time, please retry",
+		})
+		return
+	}
+
+	if repository.IsInvalidInput(err) {
+		writeError(w, http.StatusBadRequest, errorBody{
+			Code:    codeInvalidParameter,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if repository.IsForeignKeyViolation(err) {
+		writeError(w, http.StatusConflict, errorBody{
+			Code:    codeForeignKey,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	var validationErr *service.ValidationError
+	if stderrors.As(err, &validationErr) {
+		writeError(w, http.StatusBadRequest, errorBody{
+			Code:    codeValidationFailed,
+			Message: validationErr.Error(),
+			Errors:  validationErr.Violations,
+		})
+		return
+	}
+
+	var duplicateErr *service.DuplicateCreateError
+	if stderrors.As(err, &duplicateErr) {
+		writeError(w, http.StatusConflict, errorBody{
+			Code:    codeDuplicateCreate,
+			Message: duplicateErr.Error(),
+			Details: map[string]interface{}{
+				"vendor_id":      duplicateErr.VendorID,
+				"window_seconds": duplicateErr.Window.Seconds(),
+			},
+		})
+		return
+	}
+
+	var duplicateContactErr *service.DuplicateContactError
+	if stderrors.As(err, &duplicateContactErr) {
+		writeError(w, http.StatusConflict, errorBody{
+			Code:    codeDuplicateContact,
+			Message: duplicateContactErr.Error(),
+			Field:   "email",
+			Details: map[string]interface{}{
+				"vendor_id":  duplicateContactErr.VendorID,
+				"contact_id": duplicateContactErr.ContactID,
+			},
+		})
+		return
+	}
+
+	var lastRecipientErr *service.LastRecipientError
+	if stderrors.As(err, &lastRecipientErr) {
+		writeError(w, http.StatusConflict, errorBody{
+			Code:    codeLastRecipient,
+			Message: lastRecipientErr.Error(),
+			Details: map[string]interface{}{
+				"vendor_id":          lastRecipientErr.VendorID,
+				"contact_id":         lastRecipientErr.ContactID,
+				"communication_type": lastRecipientErr.CommunicationType,
+			},
+		})
+		return
+	}
+
+	var lockedErr *service.VendorCodeLockedError
+	if stderrors.As(err, &lockedErr) {
+		writeError(w, http.StatusConflict, errorBody{
+			Code:    codeVendorCodeLocked,
+			Message: lockedErr.Error(),
+			Details: map[string]interface{}{
+				"vendor_id":   lockedErr.VendorID,
+				"vendor_code": lockedErr.VendorCode,
+			},
+		})
+		return
+	}
+
+	var currencyErr *service.CurrencyChangeBlockedError
+	if stderrors.As(err, &currencyErr) {
+		writeError(w, http.StatusConflict, errorBody{
+			Code:    codeCurrencyBlocked,
+			Message: currencyErr.Error(),
+			Details: map[string]interface{}{
+				"vendor_id":       currencyErr.VendorID,
+				"currency":        currencyErr.From,
+				"balance":         currencyErr.Balance,
+				"recent_activity": currencyErr.RecentActivity,
+			},
+		})
+		return
+	}
+
+	var readOnlyErr *service.EntityReadOnlyError
+	if stderrors.As(err, &readOnlyErr) {
+		writeError(w, http.StatusConflict, errorBody{
+			Code:    codeEntityReadOnly,
+			Message: readOnlyErr.Error(),
+			Details: map[string]interface{}{
+				"entity_id": readOnlyErr.EntityID,
+				"state":     readOnlyErr.State,
+			},
+		})
+		return
+	}
+
+	var childrenErr *service.VendorHasChildrenError
+	if stderrors.As(err, &childrenErr) {
+		writeError(w, http.StatusConflict, errorBody{
+			Code:    codeVendorHasChildren,
+			Message: childrenErr.Error(),
+			Details: map[string]interface{}{
+				"vendor_id": childrenErr.VendorID,
+				"children":  childrenErr.Children,
+			},
+		})
+		return
+	}
+
+	var refConflictErr *repository.ExternalRefConflictError
+	if stderrors.As(err, &refConflictErr) {
+		writeError(w, http.StatusConflict, errorBody{
+			Code:    codeAlreadyExists,
+			Message: refConflictErr.Error(),
+			Field:   "external_id",
+			Details: map[string]interface{}{
+				"vendor_id":   refConflictErr.VendorID,
+				"vendor_code": refConflictErr.VendorCode,
+				"system":      refConflictErr.System,
+				"external_id": refConflictErr.ExternalID,
+			},
+		})
+		return
+	}
+
+	var quotaErr *service.QuotaExceededError
+	if stderrors.As(err, &quotaErr) {
+		writeError(w, http.StatusTooManyRequests, errorBody{
+			Code:    codeQuotaExceeded,
+			Message: quotaErr.Error(),
+			Details: map[string]interface{}{
+				"entity_id": quotaErr.EntityID,
+				"count":     quotaErr.Count,
+				"limit":     quotaErr.Limit,
+			},
+		})
+		return
+	}
+
+	var throttledErr *tinmatch.ThrottledError
+	if stderrors.As(err, &throttledErr) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(throttledErr.RetryAfter.Seconds())))
+		writeError(w, http.StatusServiceUnavailable, errorBody{
+			Code:    codeTINMatchThrottled,
+			Message: throttledErr.Error(),
+		})
+		return
+	}
+
+	var appErr *errors.AppError
+	if stderrors.As(err, &appErr) {
+		switch appErr.Code {
+		case errors.ErrCodeNotFound:
+			writeError(w, http.StatusNotFound, errorBody{Code: codeNotFound, Message: err.Error()})
+			return
+		case errors.ErrCodeAlreadyExists:
+			writeError(w, http.StatusConflict, errorBody{Code: codeAlreadyExists, Message: err.Error()})
+			return
+		}
+	}
+
+	http.Error(w, err.Error(), fallbackStatus)
+}
+
+// paramError describes a query parameter that failed to parse or validate
+type paramError struct {
+	Field   string
+	Message string
+}
+
+func (e *paramError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// writeParamError writes a 400 response for an invalid query parameter
+func writeParamError(w http.ResponseWriter, err *paramError) {
+	writeError(w, http.StatusBadRequest, errorBody{
+		Code:    codeInvalidParameter,
+		Message: err.Message,
+		Field:   err.Field,
+	})
+}
+
+// checkQueryParams rejects unrecognized query parameters when strict mode is enabled.
+// It returns false after writing the error response.
+func (h *HTTPHandler) checkQueryParams(w http.ResponseWriter, r *http.Request, allowed ...string) bool {
+	if !h.opts.StrictQueryParams {
+		return true
+	}
+
+	known := map[string]bool{readConsistencyParam: true}
+	for _, name := range allowed {
+		known[name] = true
+	}
+
+	var unknown []string
+	for name := range r.URL.Query() {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return true
+	}
+
+	sort.Strings(unknown)
+	writeError(w, http.StatusBadRequest, errorBody{
+		Code:    codeUnknownParameter,
+		Message: "unrecognized query parameters: " + strings.Join(unknown, ", "),
+		Details: map[string]interface{}{"unknown": unknown},
+	})
+	return false
+}
+
+// queryInt parses an integer query parameter within [min, max], returning defaultValue when absent
+func queryInt(r *http.Request, name string, defaultValue, min, max int) (int, *paramError) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return defaultValue, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, &paramError{Field: name, Message: fmt.Sprintf("%s must be an integer, got %q", name, raw)}
+	}
+	if value < min || value > max {
+		return 0, &paramError{Field: name, Message: fmt.Sprintf("%s must be between %d and %d", name, min, max)}
+	}
+
+	return value, nil
+}
+
+// queryTime parses an optional time query parameter given as an RFC 3339
+// timestamp with an offset or a date (a UTC day), returning it in UTC, or the
+// zero time when absent
+func queryTime(r *http.Request, name string) (time.Time, *paramError) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := utctime.ParseTimeOrDate(raw)
+	if err != nil {
+		return time.Time{}, &paramError{Field: name, Message: utctime.Message(name, raw, err, true)}
+	}
+	return t, nil
+}
+
+// queryTimestamp parses an optional RFC 3339 timestamp query parameter, which
+// must carry an offset, returning it in UTC, or the zero time when absent
+func queryTimestamp(r *http.Request, name string) (time.Time, *paramError) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := utctime.Parse(raw)
+	if err != nil {
+		return time.Time{}, &paramError{Field: name, Message: utctime.Message(name, raw, err, false)}
+	}
+	return t, nil
+}
+
+// queryBool parses an optional boolean query parameter, returning nil when absent
+func queryBool(r *http.Request, name string) (*bool, *paramError) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return nil, &paramError{Field: name, Message: fmt.Sprintf("%s must be true or false, got %q", name, raw)}
+	}
+
+	return &value, nil
+}
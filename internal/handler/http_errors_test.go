@@ -0,0 +1,93 @@
+package handler
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+func TestQueryTimestamp(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    time.Time
+		wantErr string
+	}{
+		{name: "absent", query: ""},
+		{name: "utc", query: "since=2026-04-04T16:30:00Z", want: time.Date(2026, 4, 4, 16, 30, 0, 0, time.UTC)},
+		{name: "escaped offset", query: "since=2026-04-05T02:30:00%2B10:00", want: time.Date(2026, 4, 4, 16, 30, 0, 0, time.UTC)},
+		{name: "unescaped offset", query: "since=2026-04-05T02:30:00+11:00", want: time.Date(2026, 4, 4, 15, 30, 0, 0, time.UTC)},
+		{name: "no offset", query: "since=2026-04-05T02:30:00", wantErr: "UTC offset"},
+		{name: "date", query: "since=2026-04-05", wantErr: "RFC 3339"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/vendors/activity?"+tt.query, nil)
+			got, perr := queryTimestamp(r, "since")
+			if tt.wantErr != "" {
+				if perr == nil || perr.Field != "since" || !strings.Contains(perr.Message, tt.wantErr) {
+					t.Errorf("queryTimestamp(%q) error = %+v, want one mentioning %q", tt.query, perr, tt.wantErr)
+				}
+				return
+			}
+			if perr != nil {
+				t.Fatalf("queryTimestamp(%q) error = %+v", tt.query, perr)
+			}
+			if !got.Equal(tt.want) || (!got.IsZero() && got.Location() != time.UTC) {
+				t.Errorf("queryTimestamp(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryTime(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/vendors/stats/growth?from=2026-04-05&to=2026-04-05T02:30:00+10:00", nil)
+
+	from, perr := queryTime(r, "from")
+	if perr != nil || !from.Equal(time.Date(2026, 4, 5, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("queryTime(from) = %v, %+v, want midnight UTC", from, perr)
+	}
+	to, perr := queryTime(r, "to")
+	if perr != nil || !to.Equal(time.Date(2026, 4, 4, 16, 30, 0, 0, time.UTC)) {
+		t.Errorf("queryTime(to) = %v, %+v, want 16:30 UTC the day before", to, perr)
+	}
+}
+
+func TestWriteServiceError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"lookup not found", &repository.NotFoundError{Resource: "vendor", ID: "v-1", Err: errors.NotFound("vendor", "v-1")}, http.StatusNotFound, codeNotFound},
+		{"wrapped not found", fmt.Errorf("loading: %w", errors.NotFound("external_ref", "quickbooks")), http.StatusNotFound, codeNotFound},
+		{"already exists", errors.AlreadyExists("vendor", "NW-001"), http.StatusConflict, codeAlreadyExists},
+		{"query timeout", errors.Wrap(&repository.QueryTimeoutError{Op: "GetByExternalRef", Budget: time.Second}, errors.ErrCodeInternal, "failed"), http.StatusGatewayTimeout, codeQueryTimeout},
+		{"internal", errors.Wrap(stderrors.New("connection reset"), errors.ErrCodeInternal, "failed"), http.StatusInternalServerError, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			writeServiceError(rec, tt.err, http.StatusInternalServerError)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			if body := decodeErrorBody(t, rec); body.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", body.Code, tt.wantCode)
+			}
+		})
+	}
+}
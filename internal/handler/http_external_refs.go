@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pesio-ai/be-lib-common/auth"
+)
+
+// VendorExternalRefs handles GET/POST/DELETE /api/v1/vendors/external-refs requests
+func (h *HTTPHandler) VendorExternalRefs(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.getExternalRefs(w, r)
+	case http.MethodPost, http.MethodPut:
+		h.setExternalRef(w, r)
+	case http.MethodDelete:
+		h.deleteExternalRef(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *HTTPHandler) getExternalRefs(w http.ResponseWriter, r *http.Request) {
+	if !h.checkQueryParams(w, r, "vendor_id", "entity_id") {
+		return
+	}
+
+	vendorID := r.URL.Query().Get("vendor_id")
+	entityID := r.URL.Query().Get("entity_id")
+	if vendorID == "" || entityID == "" {
+		http.Error(w, "Vendor ID and Entity ID are required", http.StatusBadRequest)
+		return
+	}
+
+	refs, err := h.service.GetExternalRefs(r.Context(), vendorID, entityID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"external_refs": refs,
+	})
+}
+
+func (h *HTTPHandler) setExternalRef(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		VendorID   string `json:"vendor_id"`
+		EntityID   string `json:"entity_id"`
+		System     string `json:"system"`
+		ExternalID string `json:"external_id"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if req.VendorID == "" || req.EntityID == "" {
+		http.Error(w, "Vendor ID and Entity ID are required", http.StatusBadRequest)
+		return
+	}
+
+	var createdBy string
+	if user, err := auth.GetUserContext(r.Context()); err == nil && user != nil {
+		createdBy = user.UserID
+	}
+
+	ref, err := h.service.SetExternalRef(r.Context(), req.VendorID, req.EntityID, req.System, req.ExternalID, createdBy)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ref)
+}
+
+func (h *HTTPHandler) deleteExternalRef(w http.ResponseWriter, r *http.Request) {
+	if !h.checkQueryParams(w, r, "vendor_id", "entity_id", "system") {
+		return
+	}
+
+	vendorID := r.URL.Query().Get("vendor_id")
+	entityID := r.URL.Query().Get("entity_id")
+	system := r.URL.Query().Get("system")
+	if vendorID == "" || entityID == "" || system == "" {
+		http.Error(w, "Vendor ID, Entity ID and system are required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.DeleteExternalRef(r.Context(), vendorID, entityID, system); err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetVendorByExternalRef handles GET /api/v1/vendors/by-external-ref requests
+func (h *HTTPHandler) GetVendorByExternalRef(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id", "system", "external_id") {
+		return
+	}
+
+	entityID := r.URL.Query().Get("entity_id")
+	system := r.URL.Query().Get("system")
+	externalID := r.URL.Query().Get("external_id")
+	if entityID == "" || system == "" || externalID == "" {
+		http.Error(w, "Entity ID, system and external_id are required", http.StatusBadRequest)
+		return
+	}
+
+	vendor, err := h.service.GetVendorByExternalRef(r.Context(), entityID, system, externalID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(vendor)
+}
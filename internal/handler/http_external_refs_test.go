@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func externalRefBody(vendorID, externalID string) string {
+	return `{"vendor_id": "` + vendorID + `", "entity_id": "` + testEntityID + `", "system": "quickbooks", "external_id": "` + externalID + `"}`
+}
+
+func TestSetExternalRefConflict(t *testing.T) {
+	h, svc := newTestHTTPHandler(t)
+	owner := createTestVendor(t, svc, "NW-001")
+	other := createTestVendor(t, svc, "NW-002")
+
+	if rec := serve(h.VendorExternalRefs, http.MethodPost, "/api/v1/vendors/external-refs", externalRefBody(owner.ID, "QB-42")); rec.Code != http.StatusOK {
+		t.Fatalf("mapping QB-42 status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+
+	rec := serve(h.VendorExternalRefs, http.MethodPost, "/api/v1/vendors/external-refs", externalRefBody(other.ID, "QB-42"))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("mapping QB-42 to a second vendor status = %d, want %d: %s", rec.Code, http.StatusConflict, rec.Body)
+	}
+	body := decodeErrorBody(t, rec)
+	if body.Code != codeAlreadyExists {
+		t.Errorf("code = %q, want %q", body.Code, codeAlreadyExists)
+	}
+	if body.Details["vendor_id"] != owner.ID || body.Details["vendor_code"] != "NW-001" {
+		t.Errorf("details = %v, want the vendor holding QB-42", body.Details)
+	}
+
+	// Remapping the holder itself is no conflict
+	if rec := serve(h.VendorExternalRefs, http.MethodPut, "/api/v1/vendors/external-refs", externalRefBody(owner.ID, "QB-42")); rec.Code != http.StatusOK {
+		t.Errorf("remapping QB-42 status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+}
+
+func TestExternalRefsNotFound(t *testing.T) {
+	h, svc := newTestHTTPHandler(t)
+	vendor := createTestVendor(t, svc, "NW-001")
+	missingVendor := "33333333-3333-4333-8333-333333333333"
+
+	query := func(params ...string) string {
+		values := url.Values{"entity_id": {testEntityID}}
+		for i := 0; i < len(params); i += 2 {
+			values.Set(params[i], params[i+1])
+		}
+		return "?" + values.Encode()
+	}
+
+	tests := []struct {
+		name   string
+		handle http.HandlerFunc
+		method string
+		target string
+	}{
+		{"get refs of a missing vendor", h.VendorExternalRefs, http.MethodGet,
+			"/api/v1/vendors/external-refs" + query("vendor_id", missingVendor)},
+		{"delete a missing ref", h.VendorExternalRefs, http.MethodDelete,
+			"/api/v1/vendors/external-refs" + query("vendor_id", vendor.ID, "system", "quickbooks")},
+		{"lookup an unmapped external id", h.GetVendorByExternalRef, http.MethodGet,
+			"/api/v1/vendors/by-external-ref" + query("system", "quickbooks", "external_id", "QB-404")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(tt.handle, tt.method, tt.target, "")
+			if rec.Code != http.StatusNotFound {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusNotFound, rec.Body)
+			}
+			if body := decodeErrorBody(t, rec); body.Code != codeNotFound {
+				t.Errorf("code = %q, want %q", body.Code, codeNotFound)
+			}
+		})
+	}
+}
+
+func TestGetVendorByExternalRef(t *testing.T) {
+	h, svc := newTestHTTPHandler(t)
+	vendor := createTestVendor(t, svc, "NW-001")
+	if rec := serve(h.VendorExternalRefs, http.MethodPost, "/api/v1/vendors/external-refs", externalRefBody(vendor.ID, "QB-42")); rec.Code != http.StatusOK {
+		t.Fatalf("mapping QB-42 status = %d: %s", rec.Code, rec.Body)
+	}
+
+	rec := serve(h.GetVendorByExternalRef, http.MethodGet,
+		"/api/v1/vendors/by-external-ref?entity_id="+testEntityID+"&system=QuickBooks&external_id=QB-42", "")
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-lib-common/auth"
+)
+
+// requireUser returns the user and entity IDs of the authenticated user of r,
+// writing a 401 when there is none
+func requireUser(w http.ResponseWriter, r *http.Request) (userID, entityID string, ok bool) {
+	user, err := auth.GetUserContext(r.Context())
+	if err != nil || user == nil {
+		writeError(w, http.StatusUnauthorized, errorBody{Code: codeUnauthorized, Message: "authentication required"})
+		return "", "", false
+	}
+	return user.UserID, user.EntityID, true
+}
+
+// ToggleFavoriteVendor handles POST /api/v1/vendors/{id}/favorite, adding the
+// vendor to the favorites of the authenticated user or removing it
+func (h *HTTPHandler) ToggleFavoriteVendor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	vendorID := r.PathValue("id")
+	reqlog.SetVendor(r.Context(), vendorID)
+	userID, entityID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	favorite, err := h.service.ToggleFavoriteVendor(r.Context(), userID, entityID, vendorID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"vendor_id": vendorID,
+		"favorite":  favorite,
+	})
+}
+
+// ListFavoriteVendors handles GET /api/v1/vendors/favorites, listing the
+// favorite vendors of the authenticated user in their entity by name
+func (h *HTTPHandler) ListFavoriteVendors(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.checkQueryParams(w, r) {
+		return
+	}
+	userID, entityID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	vendors, err := h.service.ListFavoriteVendors(r.Context(), userID, entityID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"vendors": vendors})
+}
+
+// ListRecentVendors handles GET /api/v1/vendors/recent, listing the vendors
+// the authenticated user last viewed in their entity, most recent first
+func (h *HTTPHandler) ListRecentVendors(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.checkQueryParams(w, r) {
+		return
+	}
+	userID, entityID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	vendors, err := h.service.ListRecentVendors(r.Context(), userID, entityID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"vendors": vendors})
+}
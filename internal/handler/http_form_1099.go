@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+)
+
+// exportJobLocation returns the path of an export job
+func exportJobLocation(jobID string) string {
+	return "/api/v1/vendors/export-jobs/" + jobID
+}
+
+// Form1099Report handles POST /api/v1/vendors/1099-nec requests. The body
+// carries the tax year and the payments to report. format=json (the default)
+// returns the report, format=zip downloads the provider CSV and the error CSV
+// of excluded vendors, and async=true generates the zip in the background.
+func (h *HTTPHandler) Form1099Report(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.checkQueryParams(w, r, "format", "async") {
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "zip" {
+		writeParamError(w, &paramError{Field: "format", Message: fmt.Sprintf("format must be json or zip, got %q", format)})
+		return
+	}
+	async, perr := queryBool(r, "async")
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+
+	var req service.Form1099Request
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	if async != nil && *async {
+		job, err := h.service.StartForm1099Export(r.Context(), &req)
+		if err != nil {
+			writeServiceError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", exportJobLocation(job.ID))
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(job)
+		return
+	}
+
+	export, err := h.service.GenerateForm1099(r.Context(), &req)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(export)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := service.WriteForm1099Archive(&buf, export); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, service.Form1099ArchiveName(export.TaxYear)))
+	buf.WriteTo(w)
+}
+
+// GetExportJob handles GET /api/v1/vendors/export-jobs/{id} requests,
+// returning the status of a background export and, for a completed vendor
+// export, its signed download_url
+func (h *HTTPHandler) GetExportJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.checkQueryParams(w, r, "entity_id") {
+		return
+	}
+
+	entityID := r.URL.Query().Get("entity_id")
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.service.GetExportJob(r.Context(), r.PathValue("id"), entityID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound)
+		return
+	}
+	setExportDownloadURL(job, entityID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// DownloadExportJob handles GET /api/v1/vendors/export-jobs/{id}/download
+// requests, returning the file of a completed background export. Vendor
+// exports need the expires and signature of their download_url.
+func (h *HTTPHandler) DownloadExportJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.checkQueryParams(w, r, "entity_id", "expires", "signature") {
+		return
+	}
+
+	entityID := r.URL.Query().Get("entity_id")
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	link, perr := exportLinkParams(r)
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+
+	file, err := h.service.GetExportJobFile(r.Context(), r.PathValue("id"), entityID, link)
+	if err != nil {
+		writeVendorExportError(w, err, http.StatusNotFound)
+		return
+	}
+	defer file.Body.Close()
+
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, file.Name))
+	io.Copy(w, file.Body)
+}
@@ -2,27 +2,71 @@ package handler
 
 import (
 	"encoding/json"
+	"fmt"
+	"math"
 	"net/http"
-	"strconv"
+	"net/url"
+	"strings"
 
 	"github.com/pesio-ai/be-lib-common/logger"
+	"github.com/pesio-ai/be-ap-vendors/internal/authz"
+	"github.com/pesio-ai/be-ap-vendors/internal/locale"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
 	"github.com/pesio-ai/be-ap-vendors/internal/service"
+	"github.com/pesio-ai/be-lib-common/auth"
+	"golang.org/x/text/currency"
 )
 
+// HTTPOptions configures optional behaviour of the HTTP handler
+type HTTPOptions struct {
+	// StrictQueryParams rejects requests carrying unrecognized query parameters
+	StrictQueryParams bool
+	// AdminToken guards the internal /api/v1/admin endpoints; empty disables them
+	AdminToken string
+	// Retention is the default retention used by admin purges
+	Retention service.RetentionPolicy
+	// Sections restricts vendor snapshot sections; nil shows every section
+	Sections *authz.SectionPolicy
+}
+
 // HTTPHandler handles HTTP requests
 type HTTPHandler struct {
-	service *service.VendorService
+	service HTTPService
 	log     *logger.Logger
+	opts    HTTPOptions
 }
 
 // NewHTTPHandler creates a new HTTP handler
-func NewHTTPHandler(service *service.VendorService, log *logger.Logger) *HTTPHandler {
+func NewHTTPHandler(service HTTPService, log *logger.Logger, opts HTTPOptions) *HTTPHandler {
 	return &HTTPHandler{
 		service: service,
 		log:     log,
+		opts:    opts,
 	}
 }
 
+// vendorLocation is the canonical URL of a vendor, used in Location headers
+func vendorLocation(vendorID string) string {
+	return "/api/v1/vendors/" + url.PathEscape(vendorID)
+}
+
+// contactLocation is the canonical URL of a vendor contact
+func contactLocation(vendorID, contactID string) string {
+	return vendorLocation(vendorID) + "/contacts/" + url.PathEscape(contactID)
+}
+
+// vendorListParams are the query parameters accepted by the vendor lists
+var vendorListParams = []string{
+	"entity_id", "status", "vendor_type", "active_only",
+	"is_1099_vendor", "is_tax_exempt", "has_credit_limit", "over_credit_limit", "missing_tax_id",
+	"min_risk_score", "name", "locale", "missing_locale", "currency", "tag", "sort", "page", "page_size",
+	"total_mode", "source", "exclude_suspected_test",
+}
+
+// listVendorsParams are the query parameters accepted by ListVendors
+var listVendorsParams = append([]string{"fields", "include_counts", "resolve_users"}, vendorListParams...)
+
 // CreateVendor handles create vendor HTTP requests
 func (h *HTTPHandler) CreateVendor(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -31,21 +75,22 @@ func (h *HTTPHandler) CreateVendor(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req service.CreateVendorRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	// TODO: Get user ID from JWT token
 	// req.CreatedBy = "system" // Leave empty for NULL
+	req.DefaultSource = service.VendorSourceUI
 
 	vendor, err := h.service.CreateVendor(r.Context(), &req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", vendorLocation(vendor.ID))
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(vendor)
 }
@@ -57,17 +102,43 @@ func (h *HTTPHandler) GetVendor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	vendorID := r.URL.Query().Get("id")
+	// Served both as /api/v1/vendors/{id} and /api/v1/vendors/get?id={id}
+	vendorID := r.PathValue("id")
+	if vendorID == "" {
+		vendorID = r.URL.Query().Get("id")
+	}
 	entityID := r.URL.Query().Get("entity_id")
+	reqlog.SetVendor(r.Context(), vendorID)
 
 	if vendorID == "" || entityID == "" {
 		http.Error(w, "Vendor ID and Entity ID are required", http.StatusBadRequest)
 		return
 	}
 
-	vendor, err := h.service.GetVendor(r.Context(), vendorID, entityID)
+	var expand []string
+	if raw := r.URL.Query().Get("expand"); raw != "" {
+		expand = strings.Split(raw, ",")
+	}
+	resolveUsers, perr := queryBool(r, "resolve_users")
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+	if resolveUsers != nil && *resolveUsers {
+		expand = append(expand, service.ExpandUsers)
+	}
+
+	vendor, err := h.service.GetVendor(r.Context(), vendorID, entityID, expand...)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeServiceError(w, err, http.StatusNotFound)
+		return
+	}
+	if user, err := auth.GetUserContext(r.Context()); err == nil && user != nil && user.EntityID == entityID {
+		h.service.RecordVendorView(r.Context(), user.UserID, entityID, vendor.ID)
+	}
+
+	// Expanded data is not covered by the vendor's validators
+	if len(expand) == 0 && writeVendorValidators(w, r, vendor) {
 		return
 	}
 
@@ -82,7 +153,7 @@ func (h *HTTPHandler) GetVendorByCode(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	vendorCode := r.URL.Query().Get("vendor_code")
+	vendorCode := strings.TrimSpace(r.URL.Query().Get("vendor_code"))
 	entityID := r.URL.Query().Get("entity_id")
 
 	if vendorCode == "" || entityID == "" {
@@ -92,7 +163,11 @@ func (h *HTTPHandler) GetVendorByCode(w http.ResponseWriter, r *http.Request) {
 
 	vendor, err := h.service.GetVendorByCode(r.Context(), vendorCode, entityID)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeServiceError(w, err, http.StatusNotFound)
+		return
+	}
+
+	if writeVendorValidators(w, r, vendor) {
 		return
 	}
 
@@ -107,15 +182,96 @@ func (h *HTTPHandler) ListVendors(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.checkQueryParams(w, r, listVendorsParams...) {
+		return
+	}
+
+	filter, page, pageSize, ok := parseVendorListQuery(w, r)
+	if !ok {
+		return
+	}
+
+	fields, err := service.ParseVendorFields(strings.Split(r.URL.Query().Get("fields"), ","))
+	if err != nil {
+		writeServiceError(w, err, http.StatusBadRequest)
+		return
+	}
+	if service.RequestsBankFields(fields) {
+		// Bank details are restricted like the bank section of the snapshot
+		var userID string
+		if user, err := auth.GetUserContext(r.Context()); err == nil && user != nil {
+			userID = user.UserID
+		}
+		if !h.opts.Sections.Allows(service.SnapshotBank, userID) {
+			writeError(w, http.StatusForbidden, errorBody{
+				Code:    codeForbidden,
+				Message: "vendor bank details are restricted",
+			})
+			return
+		}
+	}
+	filter.Fields = fields
+
+	includeCounts, perr := queryBool(r, "include_counts")
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+	filter.IncludeCounts = includeCounts != nil && *includeCounts
+	resolveUsers, perr := queryBool(r, "resolve_users")
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+	filter.ResolveUsers = resolveUsers != nil && *resolveUsers
+
+	result, err := h.service.ListVendors(r.Context(), filter, page, pageSize)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	var vendors interface{} = result.Vendors
+	if len(fields) > 0 {
+		selected := make([]map[string]interface{}, len(result.Vendors))
+		for i, vendor := range result.Vendors {
+			selected[i] = vendor.FieldValues(fields)
+			if filter.IncludeCounts {
+				selected[i]["contacts_count"] = vendor.ContactsCount
+				selected[i]["documents_count"] = vendor.DocumentsCount
+				selected[i]["expiring_documents_count"] = vendor.ExpiringDocumentsCount
+			}
+			if vendor.CreatedByUser != nil {
+				selected[i]["created_by_user"] = vendor.CreatedByUser
+			}
+			if vendor.UpdatedByUser != nil {
+				selected[i]["updated_by_user"] = vendor.UpdatedByUser
+			}
+		}
+		vendors = selected
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"vendors":    vendors,
+		"total":      result.Total,
+		"total_mode": result.TotalMode,
+		"page":       page,
+		"pageSize":   pageSize,
+	})
+}
+
+// parseVendorListQuery parses the filters, search, order and paging of the
+// vendor lists, answering the request itself when they are invalid
+func parseVendorListQuery(w http.ResponseWriter, r *http.Request) (filter repository.VendorFilter, page, pageSize int, ok bool) {
 	entityID := r.URL.Query().Get("entity_id")
 	if entityID == "" {
 		http.Error(w, "Entity ID is required", http.StatusBadRequest)
-		return
+		return filter, 0, 0, false
 	}
 
 	status := r.URL.Query().Get("status")
 	vendorType := r.URL.Query().Get("vendor_type")
-	activeOnly := r.URL.Query().Get("active_only") == "true"
 
 	var statusPtr *string
 	if status != "" {
@@ -127,29 +283,116 @@ func (h *HTTPHandler) ListVendors(w http.ResponseWriter, r *http.Request) {
 		vendorTypePtr = &vendorType
 	}
 
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
+	filter = repository.VendorFilter{
+		EntityID:   entityID,
+		Status:     statusPtr,
+		VendorType: vendorTypePtr,
+		Name:       strings.TrimSpace(r.URL.Query().Get("name")),
 	}
 
-	pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
-	if pageSize < 1 || pageSize > 100 {
-		pageSize = 50
+	boolParams := []struct {
+		name string
+		dest **bool
+	}{
+		{"is_1099_vendor", &filter.Is1099Vendor},
+		{"is_tax_exempt", &filter.IsTaxExempt},
+		{"has_credit_limit", &filter.HasCreditLimit},
+		{"over_credit_limit", &filter.OverCreditLimit},
+		{"missing_tax_id", &filter.MissingTaxID},
+		{"missing_locale", &filter.MissingLocale},
+	}
+	for _, p := range boolParams {
+		value, perr := queryBool(r, p.name)
+		if perr != nil {
+			writeParamError(w, perr)
+			return filter, 0, 0, false
+		}
+		*p.dest = value
 	}
 
-	vendors, total, err := h.service.ListVendors(r.Context(), entityID, statusPtr, vendorTypePtr, activeOnly, page, pageSize)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	activeOnly, perr := queryBool(r, "active_only")
+	if perr != nil {
+		writeParamError(w, perr)
+		return filter, 0, 0, false
 	}
+	filter.ActiveOnly = activeOnly != nil && *activeOnly
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"vendors":  vendors,
-		"total":    total,
-		"page":     page,
-		"pageSize": pageSize,
-	})
+	excludeSuspectedTest, perr := queryBool(r, "exclude_suspected_test")
+	if perr != nil {
+		writeParamError(w, perr)
+		return filter, 0, 0, false
+	}
+	filter.ExcludeSuspectedTest = excludeSuspectedTest != nil && *excludeSuspectedTest
+
+	if raw := r.URL.Query().Get("locale"); raw != "" {
+		tag, err := locale.Parse(raw)
+		if err != nil {
+			writeParamError(w, &paramError{Field: "locale", Message: err.Error()})
+			return filter, 0, 0, false
+		}
+		filter.Locale = tag
+	}
+
+	if raw := r.URL.Query().Get("currency"); raw != "" {
+		code, err := currency.ParseISO(raw)
+		if err != nil || len(raw) != 3 {
+			writeParamError(w, &paramError{Field: "currency", Message: fmt.Sprintf("currency must be an ISO 4217 currency code, got %q", raw)})
+			return filter, 0, 0, false
+		}
+		filter.Currency = code.String()
+	}
+
+	if raw := r.URL.Query().Get("tag"); raw != "" {
+		filter.Tag = service.NormalizeTag(raw)
+	}
+
+	if raw := r.URL.Query().Get("source"); raw != "" {
+		if !service.IsValidVendorSource(raw) {
+			writeParamError(w, &paramError{Field: "source", Message: fmt.Sprintf("source must be ui, api, import, portal, erp_sync, seed or unknown, got %q", raw)})
+			return filter, 0, 0, false
+		}
+		filter.Source = raw
+	}
+
+	if r.URL.Query().Has("min_risk_score") {
+		minRiskScore, perr := queryInt(r, "min_risk_score", 0, 0, 100)
+		if perr != nil {
+			writeParamError(w, perr)
+			return filter, 0, 0, false
+		}
+		filter.MinRiskScore = &minRiskScore
+	}
+
+	switch sort := r.URL.Query().Get("sort"); sort {
+	case "", repository.SortVendorName, repository.SortRiskScoreDesc, repository.SortRiskScoreAsc:
+		filter.Sort = sort
+	default:
+		writeParamError(w, &paramError{Field: "sort", Message: fmt.Sprintf("sort must be %s, %s or %s, got %q",
+			repository.SortVendorName, repository.SortRiskScoreDesc, repository.SortRiskScoreAsc, sort)})
+		return filter, 0, 0, false
+	}
+
+	if mode := r.URL.Query().Get("total_mode"); service.IsValidTotalMode(mode) {
+		filter.TotalMode = mode
+	} else {
+		writeParamError(w, &paramError{Field: "total_mode", Message: fmt.Sprintf("total_mode must be %s, %s, %s or %s, got %q",
+			service.TotalModeAuto, repository.TotalExact, repository.TotalEstimate, repository.TotalNone, mode)})
+		return filter, 0, 0, false
+	}
+
+	page, perr = queryInt(r, "page", 1, 1, math.MaxInt32)
+	if perr != nil {
+		writeParamError(w, perr)
+		return filter, 0, 0, false
+	}
+
+	pageSize, perr = queryInt(r, "page_size", 50, 1, 100)
+	if perr != nil {
+		writeParamError(w, perr)
+		return filter, 0, 0, false
+	}
+
+	return filter, page, pageSize, true
 }
 
 // UpdateVendor handles update vendor HTTP requests
@@ -160,17 +403,21 @@ func (h *HTTPHandler) UpdateVendor(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req service.UpdateVendorRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeVendorWrite(w, r, &req, service.CheckWritableFields) {
 		return
 	}
 
 	// TODO: Get user ID from JWT token
 	// req.UpdatedBy = "system" // Leave empty for NULL
 
+	// Callers holding the admin token may change locked vendor codes and the
+	// currency of vendors with a balance
+	req.OverrideCodeLock = h.hasAdminToken(r)
+	req.OverrideCurrencyLock = req.OverrideCodeLock
+
 	vendor, err := h.service.UpdateVendor(r.Context(), &req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -178,13 +425,55 @@ func (h *HTTPHandler) UpdateVendor(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(vendor)
 }
 
-// DeleteVendor handles delete vendor HTTP requests
+// UpsertVendorByCode handles PUT /api/v1/vendors/by-code/{code} requests
+func (h *HTTPHandler) UpsertVendorByCode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req service.UpsertVendorRequest
+	if !decodeVendorWrite(w, r, &req, service.CheckUpsertFields) {
+		return
+	}
+
+	req.VendorCode = r.PathValue("code")
+	req.OverrideCurrencyLock = h.hasAdminToken(r)
+	req.DefaultSource = service.VendorSourceERPSync
+	if req.EntityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	vendor, created, err := h.service.UpsertVendorByCode(r.Context(), &req)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if created {
+		w.Header().Set("Location", vendorLocation(vendor.ID))
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"vendor":  vendor,
+		"created": created,
+	})
+}
+
+// DeleteVendor handles delete vendor HTTP requests. With dry_run=true it
+// returns what the delete would touch without deleting anything.
 func (h *HTTPHandler) DeleteVendor(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
+	if !h.checkQueryParams(w, r, "id", "entity_id", "dry_run", "keep_children") {
+		return
+	}
+
 	vendorID := r.URL.Query().Get("id")
 	entityID := r.URL.Query().Get("entity_id")
 
@@ -193,12 +482,51 @@ func (h *HTTPHandler) DeleteVendor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.DeleteVendor(r.Context(), vendorID, entityID); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	dryRun, perr := queryBool(r, "dry_run")
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+	keepChildren, perr := queryBool(r, "keep_children")
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+
+	report, err := h.service.DeleteVendor(r.Context(), vendorID, entityID, dryRun != nil && *dryRun, keepChildren)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if !report.DryRun {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// BulkDeleteVendors handles POST /api/v1/vendors/bulk-delete requests
+func (h *HTTPHandler) BulkDeleteVendors(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	var req service.BulkDeleteRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	report, err := h.service.BulkDeleteVendors(r.Context(), &req)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
 }
 
 // ActivateVendor handles activate vendor HTTP requests
@@ -212,21 +540,21 @@ func (h *HTTPHandler) ActivateVendor(w http.ResponseWriter, r *http.Request) {
 		ID       string `json:"id"`
 		EntityID string `json:"entity_id"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	// TODO: Get user ID from JWT token
 	updatedBy := ""
 
-	if err := h.service.ActivateVendor(r.Context(), req.ID, req.EntityID, updatedBy); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	vendor, err := h.service.ActivateVendor(r.Context(), req.ID, req.EntityID, updatedBy)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"status":"activated"}`))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(vendor)
 }
 
 // DeactivateVendor handles deactivate vendor HTTP requests
@@ -240,21 +568,49 @@ func (h *HTTPHandler) DeactivateVendor(w http.ResponseWriter, r *http.Request) {
 		ID       string `json:"id"`
 		EntityID string `json:"entity_id"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
 	// TODO: Get user ID from JWT token
 	updatedBy := ""
 
-	if err := h.service.DeactivateVendor(r.Context(), req.ID, req.EntityID, updatedBy); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	vendor, err := h.service.DeactivateVendor(r.Context(), req.ID, req.EntityID, updatedBy)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"status":"deactivated"}`))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(vendor)
+}
+
+// SuspendVendor handles suspend vendor HTTP requests
+func (h *HTTPHandler) SuspendVendor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		ID       string `json:"id"`
+		EntityID string `json:"entity_id"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	// TODO: Get user ID from JWT token
+	updatedBy := ""
+
+	vendor, err := h.service.SuspendVendor(r.Context(), req.ID, req.EntityID, updatedBy)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(vendor)
 }
 
 // ValidateVendor handles validate vendor HTTP requests
@@ -272,17 +628,14 @@ func (h *HTTPHandler) ValidateVendor(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	valid, message, err := h.service.ValidateVendor(r.Context(), vendorID, entityID)
+	validation, err := h.service.ValidateVendor(r.Context(), vendorID, entityID, r.URL.Query().Get("invoice_currency"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"valid":   valid,
-		"message": message,
-	})
+	json.NewEncoder(w).Encode(validation)
 }
 
 // GetVendorContacts handles get vendor contacts HTTP requests
@@ -292,25 +645,66 @@ func (h *HTTPHandler) GetVendorContacts(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if !h.checkQueryParams(w, r, "vendor_id", "contact_type", "is_primary", "sort", "page", "page_size") {
+		return
+	}
+
 	vendorID := r.URL.Query().Get("vendor_id")
 	if vendorID == "" {
 		http.Error(w, "Vendor ID is required", http.StatusBadRequest)
 		return
 	}
 
-	contacts, err := h.service.GetVendorContacts(r.Context(), vendorID)
+	filter := repository.ContactFilter{VendorID: vendorID}
+	if contactType := r.URL.Query().Get("contact_type"); contactType != "" {
+		filter.ContactType = &contactType
+	}
+
+	isPrimary, perr := queryBool(r, "is_primary")
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+	filter.IsPrimary = isPrimary
+
+	if sort := r.URL.Query().Get("sort"); repository.IsValidContactSort(sort) {
+		filter.Sort = sort
+	} else {
+		writeParamError(w, &paramError{Field: "sort", Message: fmt.Sprintf("sort must be %s, %s, %s or %s, got %q",
+			repository.SortContactName, repository.SortContactLastName,
+			repository.SortContactCreatedAt, repository.SortContactCreatedAtDesc, sort)})
+		return
+	}
+
+	page, perr := queryInt(r, "page", 1, 1, math.MaxInt32)
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+
+	pageSize, perr := queryInt(r, "page_size", service.DefaultContactPageSize, 1, service.MaxContactPageSize)
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+
+	result, err := h.service.ListVendorContacts(r.Context(), filter, page, pageSize)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"contacts": contacts,
+		"contacts": result.Contacts,
+		"total":    result.Total,
+		"page":     page,
+		"pageSize": pageSize,
 	})
 }
 
-// AddVendorContact handles add vendor contact HTTP requests
+// AddVendorContact handles add vendor contact HTTP requests. An upsert
+// overwriting the contact with the same email answers 200 instead of 201.
 func (h *HTTPHandler) AddVendorContact(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -318,19 +712,46 @@ func (h *HTTPHandler) AddVendorContact(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req service.AddContactRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
-	contact, err := h.service.AddVendorContact(r.Context(), &req)
+	contact, created, err := h.service.AddVendorContact(r.Context(), &req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", contactLocation(contact.VendorID, contact.ID))
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(contact)
+}
+
+// GetVendorContact handles GET /api/v1/vendors/{id}/contacts/{contact_id} requests
+func (h *HTTPHandler) GetVendorContact(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r) {
+		return
+	}
+
+	reqlog.SetVendor(r.Context(), r.PathValue("id"))
+
+	contact, err := h.service.GetVendorContact(r.Context(), r.PathValue("id"), r.PathValue("contact_id"))
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(contact)
 }
 
@@ -343,7 +764,7 @@ func (h *HTTPHandler) GetPaymentTerms(w http.ResponseWriter, r *http.Request) {
 
 	terms, err := h.service.GetPaymentTerms(r.Context())
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -360,13 +781,16 @@ func (h *HTTPHandler) UpdateBalance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !h.checkQueryParams(w, r) {
+		return
+	}
+
 	var req struct {
 		VendorID string `json:"vendor_id"`
 		EntityID string `json:"entity_id"`
 		Amount   int64  `json:"amount"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
@@ -375,8 +799,13 @@ func (h *HTTPHandler) UpdateBalance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Amount == 0 {
+		writeParamError(w, &paramError{Field: "amount", Message: "amount must be a non-zero integer"})
+		return
+	}
+
 	if err := h.service.UpdateBalance(r.Context(), req.VendorID, req.EntityID, req.Amount); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, err, http.StatusInternalServerError)
 		return
 	}
 
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository/memory"
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+	"github.com/pesio-ai/be-lib-common/logger"
+)
+
+const testEntityID = "11111111-1111-4111-8111-111111111111"
+
+// newTestHTTPHandler returns an HTTP handler serving a vendor service backed
+// by an empty in-memory store
+func newTestHTTPHandler(t *testing.T, opts ...service.Option) (*HTTPHandler, *service.VendorService) {
+	t.Helper()
+	log := logger.New(logger.Config{Level: "error", Environment: "test", ServiceName: "be-ap-vendors-test"})
+	svc := service.NewVendorService(memory.New(), log, opts...)
+	return NewHTTPHandler(svc, log, HTTPOptions{}), svc
+}
+
+// serve runs handle for a request with method, target and body, returning the
+// recorded response
+func serve(handle http.HandlerFunc, method, target, body string) *httptest.ResponseRecorder {
+	var req *http.Request
+	if body == "" {
+		req = httptest.NewRequest(method, target, nil)
+	} else {
+		req = httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	handle(rec, req)
+	return rec
+}
+
+func createVendorBody(code string) string {
+	body := map[string]interface{}{
+		"entity_id":     testEntityID,
+		"vendor_name":   "Northwind Traders",
+		"vendor_type":   "supplier",
+		"country":       "US",
+		"payment_terms": "NET30",
+		"currency":      "USD",
+		"email":         "ap@northwind.com",
+	}
+	if code != "" {
+		body["vendor_code"] = code
+	}
+	raw, _ := json.Marshal(body)
+	return string(raw)
+}
+
+// createTestVendor creates a vendor through the service and returns it
+func createTestVendor(t *testing.T, svc *service.VendorService, code string) *repository.Vendor {
+	t.Helper()
+	email := "ap@northwind.com"
+	vendor, err := svc.CreateVendor(context.Background(), &service.CreateVendorRequest{
+		EntityID:     testEntityID,
+		VendorCode:   code,
+		VendorName:   "Northwind Traders",
+		VendorType:   "supplier",
+		Country:      "US",
+		PaymentTerms: "NET30",
+		Currency:     "USD",
+		Email:        &email,
+	})
+	if err != nil {
+		t.Fatalf("CreateVendor(%q) error = %v", code, err)
+	}
+	return vendor
+}
+
+func TestCreateVendorLocation(t *testing.T) {
+	tests := []struct {
+		name string
+		// code returns the vendor code to create the vendor with
+		code func(t *testing.T, svc *service.VendorService) string
+	}{
+		{
+			name: "explicit code",
+			code: func(t *testing.T, svc *service.VendorService) string { return "NW-001" },
+		},
+		{
+			name: "lowercase code",
+			code: func(t *testing.T, svc *service.VendorService) string { return "nw-001" },
+		},
+		{
+			name: "generated code",
+			code: func(t *testing.T, svc *service.VendorService) string {
+				res, err := svc.ReserveVendorCode(context.Background(), testEntityID, "", "")
+				if err != nil {
+					t.Fatalf("ReserveVendorCode() error = %v", err)
+				}
+				return res.VendorCode
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, svc := newTestHTTPHandler(t)
+
+			rec := serve(h.CreateVendor, http.MethodPost, "/api/v1/vendors", createVendorBody(tt.code(t, svc)))
+			if rec.Code != http.StatusCreated {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body)
+			}
+
+			var vendor repository.Vendor
+			if err := json.NewDecoder(rec.Body).Decode(&vendor); err != nil {
+				t.Fatalf("decoding the body: %v", err)
+			}
+			if vendor.ID == "" {
+				t.Fatal("the body carries no vendor ID")
+			}
+			if got, want := rec.Header().Get("Location"), "/api/v1/vendors/"+vendor.ID; got != want {
+				t.Errorf("Location = %q, want %q", got, want)
+			}
+
+			// The Location is the canonical vendor URL
+			mux := http.NewServeMux()
+			mux.HandleFunc("/api/v1/vendors/{id}", h.GetVendor)
+			get := httptest.NewRequest(http.MethodGet, rec.Header().Get("Location")+"?entity_id="+testEntityID, nil)
+			got := httptest.NewRecorder()
+			mux.ServeHTTP(got, get)
+			if got.Code != http.StatusOK {
+				t.Errorf("GET Location status = %d, want %d: %s", got.Code, http.StatusOK, got.Body)
+			}
+		})
+	}
+}
+
+func TestAddVendorContactLocation(t *testing.T) {
+	h, svc := newTestHTTPHandler(t)
+	vendor := createTestVendor(t, svc, "NW-001")
+
+	body := `{"vendor_id": "` + vendor.ID + `", "contact_type": "billing", "first_name": "Ada", "last_name": "Lovelace", "email": "ada@northwind.com"}`
+	rec := serve(h.AddVendorContact, http.MethodPost, "/api/v1/vendors/contacts", body)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body)
+	}
+
+	var contact repository.VendorContact
+	if err := json.NewDecoder(rec.Body).Decode(&contact); err != nil {
+		t.Fatalf("decoding the body: %v", err)
+	}
+	if contact.ID == "" {
+		t.Fatal("the body carries no contact ID")
+	}
+	want := "/api/v1/vendors/" + vendor.ID + "/contacts/" + contact.ID
+	if got := rec.Header().Get("Location"); got != want {
+		t.Errorf("Location = %q, want %q", got, want)
+	}
+
+	// An upsert of the same contact keeps pointing at it with a 200
+	upsert := strings.Replace(body, `"email"`, `"upsert": true, "email"`, 1)
+	rec = serve(h.AddVendorContact, http.MethodPost, "/api/v1/vendors/contacts", upsert)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("upsert status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+	if got := rec.Header().Get("Location"); got != want {
+		t.Errorf("upsert Location = %q, want %q", got, want)
+	}
+
+	// The Location is the canonical contact URL
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/vendors/{id}/contacts/{contact_id}", h.GetVendorContact)
+	got := httptest.NewRecorder()
+	mux.ServeHTTP(got, httptest.NewRequest(http.MethodGet, want, nil))
+	if got.Code != http.StatusOK {
+		t.Errorf("GET Location status = %d, want %d: %s", got.Code, http.StatusOK, got.Body)
+	}
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-lib-common/auth"
+)
+
+// importTemplateRequest is the body of import template creates and updates
+type importTemplateRequest struct {
+	EntityID   string              `json:"entity_id"`
+	Name       string              `json:"name"`
+	Columns    map[string]string   `json:"columns"`
+	Defaults   map[string]string   `json:"defaults,omitempty"`
+	Transforms map[string][]string `json:"transforms,omitempty"`
+}
+
+// writeImportTemplateError writes a 404 for unknown templates and a service
+// error with fallbackStatus otherwise
+func writeImportTemplateError(w http.ResponseWriter, err error, fallbackStatus int) {
+	if repository.IsNotFound(err) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	writeServiceError(w, err, fallbackStatus)
+}
+
+// ImportTemplates handles /api/v1/import-templates: GET lists the import
+// templates of an entity, POST creates one
+func (h *HTTPHandler) ImportTemplates(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		if !h.checkQueryParams(w, r, "entity_id") {
+			return
+		}
+		entityID := r.URL.Query().Get("entity_id")
+		if entityID == "" {
+			http.Error(w, "Entity ID is required", http.StatusBadRequest)
+			return
+		}
+
+		templates, err := h.service.ListImportTemplates(r.Context(), entityID)
+		if err != nil {
+			writeServiceError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"import_templates": templates})
+
+	case http.MethodPost:
+		var req importTemplateRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+
+		t := &repository.ImportTemplate{
+			EntityID:   req.EntityID,
+			Name:       req.Name,
+			Columns:    req.Columns,
+			Defaults:   req.Defaults,
+			Transforms: req.Transforms,
+		}
+		if user, err := auth.GetUserContext(r.Context()); err == nil && user != nil {
+			t.CreatedBy = &user.UserID
+		}
+
+		if err := h.service.CreateImportTemplate(r.Context(), t); err != nil {
+			writeServiceError(w, err, http.StatusConflict)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(t)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// ImportTemplate handles /api/v1/import-templates/{id}: GET retrieves an import
+// template, PUT replaces it and DELETE removes it
+func (h *HTTPHandler) ImportTemplate(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	switch r.Method {
+	case http.MethodGet, http.MethodDelete:
+		if !h.checkQueryParams(w, r, "entity_id") {
+			return
+		}
+		entityID := r.URL.Query().Get("entity_id")
+		if entityID == "" {
+			http.Error(w, "Entity ID is required", http.StatusBadRequest)
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			if err := h.service.DeleteImportTemplate(r.Context(), id, entityID); err != nil {
+				writeImportTemplateError(w, err, http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		t, err := h.service.GetImportTemplate(r.Context(), id, entityID)
+		if err != nil {
+			writeImportTemplateError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(t)
+
+	case http.MethodPut:
+		var req importTemplateRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+
+		t := &repository.ImportTemplate{
+			ID:         id,
+			EntityID:   req.EntityID,
+			Name:       req.Name,
+			Columns:    req.Columns,
+			Defaults:   req.Defaults,
+			Transforms: req.Transforms,
+		}
+		if user, err := auth.GetUserContext(r.Context()); err == nil && user != nil {
+			t.UpdatedBy = &user.UserID
+		}
+
+		if err := h.service.UpdateImportTemplate(r.Context(), t); err != nil {
+			writeImportTemplateError(w, err, http.StatusConflict)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(t)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
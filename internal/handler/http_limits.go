@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	codePayloadTooLarge = "PAYLOAD_TOO_LARGE"
+	codeUnknownField    = "UNKNOWN_FIELD"
+)
+
+// lenientJSONHeader set to true lets a legacy integration send body fields
+// the API does not know, which are then ignored as before
+const lenientJSONHeader = "X-Lenient-JSON"
+
+type lenientJSONKey struct{}
+
+// StrictJSON makes decodeJSON reject request bodies carrying fields their
+// target does not declare, so a misspelled field fails with a 400 instead of
+// being dropped. Requests are decoded leniently when strict is false or they
+// set lenientJSONHeader to true.
+func StrictJSON(strict bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lenient := !strict || strings.EqualFold(r.Header.Get(lenientJSONHeader), "true")
+			ctx := context.WithValue(r.Context(), lenientJSONKey{}, lenient)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// BodyLimit bounds request bodies to limit bytes, or to the limit overrides
+// gives the request path. Bodies declaring a larger Content-Length are
+// rejected with 413 before they are read; others fail once the limit is read
+// past, which decodeJSON turns into a 413 as well.
+func BodyLimit(limit int64, overrides map[string]int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := limit
+			if override, ok := overrides[r.URL.Path]; ok {
+				n = override
+			}
+			if n <= 0 || r.Body == nil || r.Body == http.NoBody {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if r.ContentLength > n {
+				writeBodyTooLarge(w, n)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, n)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// writeBodyTooLarge writes the 413 for a body over limit bytes
+func writeBodyTooLarge(w http.ResponseWriter, limit int64) {
+	writeError(w, http.StatusRequestEntityTooLarge, errorBody{
+		Code:    codePayloadTooLarge,
+		Message: fmt.Sprintf("the request body exceeds the limit of %d bytes", limit),
+		Details: map[string]interface{}{"limit_bytes": limit},
+	})
+}
+
+// isBodyTooLarge reports whether err comes from reading past the body limit,
+// writing the 413 if so
+func isBodyTooLarge(w http.ResponseWriter, err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	if !stderrors.As(err, &maxBytesErr) {
+		return false
+	}
+	writeBodyTooLarge(w, maxBytesErr.Limit)
+	return true
+}
+
+// decodeJSON decodes the JSON request body into v. On failure it writes a 413
+// for bodies over the limit, a 400 naming the first unknown field unless the
+// request is lenient (see StrictJSON), or a 400 otherwise, and returns false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := decodeBody(r, r.Body, v); err != nil {
+		if !isBodyTooLarge(w, err) && !isUnknownField(w, err) {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+		}
+		return false
+	}
+	return true
+}
+
+// decodeBody decodes body into v, disallowing unknown fields unless the
+// request is lenient
+func decodeBody(r *http.Request, body io.Reader, v interface{}) error {
+	dec := json.NewDecoder(body)
+	if lenient, _ := r.Context().Value(lenientJSONKey{}).(bool); !lenient {
+		dec.DisallowUnknownFields()
+	}
+	return dec.Decode(v)
+}
+
+// isUnknownField reports whether err rejects a body field the target does not
+// declare, writing the 400 naming it if so
+func isUnknownField(w http.ResponseWriter, err error) bool {
+	// encoding/json reports unknown fields only through the message
+	field, ok := strings.CutPrefix(err.Error(), `json: unknown field "`)
+	if !ok {
+		return false
+	}
+	field = strings.TrimSuffix(field, `"`)
+	writeError(w, http.StatusBadRequest, errorBody{
+		Code:    codeUnknownField,
+		Message: fmt.Sprintf("unknown field %q in the request body", field),
+		Field:   field,
+	})
+	return true
+}
+
+// decodeVendorWrite decodes a vendor update or upsert body like decodeJSON,
+// first rejecting the fields it sets that check refuses (immutable or
+// server-managed ones) with a 400 naming the fields
+func decodeVendorWrite(w http.ResponseWriter, r *http.Request, v interface{}, check func([]string) error) bool {
+	var raw map[string]json.RawMessage
+	if !decodeJSON(w, r, &raw) {
+		return false
+	}
+
+	fields := make([]string, 0, len(raw))
+	for field := range raw {
+		fields = append(fields, field)
+	}
+	if err := check(fields); err != nil {
+		writeServiceError(w, err, http.StatusBadRequest)
+		return false
+	}
+
+	body, err := json.Marshal(raw)
+	if err == nil {
+		err = decodeBody(r, bytes.NewReader(body), v)
+	}
+	if err != nil {
+		if !isUnknownField(w, err) {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// decodeErrorBody decodes the structured error of a response
+func decodeErrorBody(t *testing.T, rec *httptest.ResponseRecorder) errorBody {
+	t.Helper()
+	var envelope errorEnvelope
+	if err := json.NewDecoder(rec.Body).Decode(&envelope); err != nil {
+		t.Fatalf("decoding the error body: %v", err)
+	}
+	return envelope.Error
+}
+
+func TestDecodeJSONRejectsUnknownFields(t *testing.T) {
+	h, svc := newTestHTTPHandler(t)
+	vendor := createTestVendor(t, svc, "NW-001")
+
+	tests := []struct {
+		name   string
+		method string
+		handle http.HandlerFunc
+		body   string
+		field  string
+	}{
+		{
+			name:   "create vendor",
+			method: http.MethodPost,
+			handle: h.CreateVendor,
+			body:   strings.Replace(createVendorBody("NW-002"), `"currency"`, `"credit_limt": 500000, "currency"`, 1),
+			field:  "credit_limt",
+		},
+		{
+			name:   "create vendor nested contact",
+			method: http.MethodPost,
+			handle: h.CreateVendor,
+			body: strings.Replace(createVendorBody("NW-002"), `"currency"`,
+				`"contacts": [{"contact_type": "billing", "frist_name": "Ada", "last_name": "Lovelace"}], "currency"`, 1),
+			field: "frist_name",
+		},
+		{
+			name:   "update vendor",
+			method: http.MethodPut,
+			handle: h.UpdateVendor,
+			body:   `{"id": "` + vendor.ID + `", "entity_id": "` + testEntityID + `", "vendor_name": "Northwind", "credit_limt": 500000}`,
+			field:  "credit_limt",
+		},
+		{
+			name:   "add contact",
+			method: http.MethodPost,
+			handle: h.AddVendorContact,
+			body:   `{"vendor_id": "` + vendor.ID + `", "contact_type": "billing", "first_name": "Ada", "last_name": "Lovelace", "emial": "ada@northwind.com"}`,
+			field:  "emial",
+		},
+		{
+			name:   "update balance",
+			method: http.MethodPost,
+			handle: h.UpdateBalance,
+			body:   `{"vendor_id": "` + vendor.ID + `", "entity_id": "` + testEntityID + `", "ammount": 100}`,
+			field:  "ammount",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(tt.handle, tt.method, "/api/v1/vendors", tt.body)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusBadRequest, rec.Body)
+			}
+
+			body := decodeErrorBody(t, rec)
+			if body.Code != codeUnknownField {
+				t.Errorf("code = %q, want %q", body.Code, codeUnknownField)
+			}
+			if body.Field != tt.field {
+				t.Errorf("field = %q, want %q", body.Field, tt.field)
+			}
+			if !strings.Contains(body.Message, tt.field) {
+				t.Errorf("message = %q, want it to name %q", body.Message, tt.field)
+			}
+		})
+	}
+}
+
+func TestStrictJSONLenientModes(t *testing.T) {
+	tests := []struct {
+		name       string
+		strict     bool
+		header     string
+		wantStatus int
+	}{
+		{"strict", true, "", http.StatusBadRequest},
+		{"strict with lenient header", true, "true", http.StatusCreated},
+		{"strict with lenient header in other case", true, "TRUE", http.StatusCreated},
+		{"strict with header off", true, "false", http.StatusBadRequest},
+		{"lenient", false, "", http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _ := newTestHTTPHandler(t)
+			handle := StrictJSON(tt.strict)(http.HandlerFunc(h.CreateVendor))
+
+			body := strings.Replace(createVendorBody("NW-001"), `"currency"`, `"credit_limt": 500000, "currency"`, 1)
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/vendors", strings.NewReader(body))
+			if tt.header != "" {
+				r.Header.Set(lenientJSONHeader, tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handle.ServeHTTP(rec, r)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+}
+
+func TestDecodeJSONKnownFields(t *testing.T) {
+	h, _ := newTestHTTPHandler(t)
+	rec := serve(h.CreateVendor, http.MethodPost, "/api/v1/vendors", createVendorBody("NW-001"))
+	if rec.Code != http.StatusCreated {
+		t.Errorf("status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body)
+	}
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+)
+
+// GetOnboardingStatus handles GET /api/v1/vendors/{id}/onboarding-status
+// requests
+func (h *HTTPHandler) GetOnboardingStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id") {
+		return
+	}
+
+	vendorID := r.PathValue("id")
+	entityID := r.URL.Query().Get("entity_id")
+	reqlog.SetVendor(r.Context(), vendorID)
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	status, err := h.service.GetOnboardingStatus(r.Context(), vendorID, entityID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
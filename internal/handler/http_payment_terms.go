@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+)
+
+const codePaymentTermInUse = "PAYMENT_TERM_IN_USE"
+
+// GetPaymentTermUsage handles GET /api/v1/payment-terms/{code}/usage
+// requests, counting the vendors that use a payment term
+func (h *HTTPHandler) GetPaymentTermUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r) {
+		return
+	}
+
+	usage, err := h.service.GetPaymentTermUsage(r.Context(), r.PathValue("code"))
+	if err != nil {
+		writePaymentTermError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(usage)
+}
+
+// DeactivatePaymentTerm handles POST
+// /api/v1/admin/payment-terms/{code}/deactivate requests
+func (h *HTTPHandler) DeactivatePaymentTerm(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.requireAdminToken(w, r) {
+		return
+	}
+
+	var req struct {
+		ReplacementCode string `json:"replacement_code,omitempty"`
+		Force           bool   `json:"force,omitempty"`
+		UpdatedBy       string `json:"updated_by,omitempty"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	result, err := h.service.DeactivatePaymentTerm(r.Context(), r.PathValue("code"), req.ReplacementCode, req.Force, stringPtr(req.UpdatedBy))
+	if err != nil {
+		writePaymentTermError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func writePaymentTermError(w http.ResponseWriter, err error) {
+	var inUse *service.PaymentTermInUseError
+	switch {
+	case stderrors.As(err, &inUse):
+		writeError(w, http.StatusConflict, errorBody{
+			Code:    codePaymentTermInUse,
+			Message: inUse.Error(),
+			Details: map[string]interface{}{"code": inUse.Code, "vendors": inUse.Vendors},
+		})
+	case repository.IsNotFound(err):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		writeServiceError(w, err, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+	"github.com/pesio-ai/be-lib-common/auth"
+)
+
+// VendorScheduledStatusChanges handles
+// /api/v1/vendors/{id}/scheduled-status-changes: GET lists the scheduled
+// status changes of the vendor, POST schedules one
+func (h *HTTPHandler) VendorScheduledStatusChanges(w http.ResponseWriter, r *http.Request) {
+	vendorID := r.PathValue("id")
+	reqlog.SetVendor(r.Context(), vendorID)
+
+	switch r.Method {
+	case http.MethodGet:
+		if !h.checkQueryParams(w, r, "entity_id", "state") {
+			return
+		}
+		h.listScheduledStatusChanges(w, r, vendorID)
+
+	case http.MethodPost:
+		var req service.ScheduleStatusChangeRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+		req.VendorID = vendorID
+		if user, err := auth.GetUserContext(r.Context()); err == nil && user != nil {
+			req.RequestedBy = &user.UserID
+		}
+
+		sc, err := h.service.ScheduleStatusChange(r.Context(), &req)
+		if err != nil {
+			writeServiceError(w, err, http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(sc)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// ListScheduledStatusChanges handles GET
+// /api/v1/vendors/scheduled-status-changes, listing the scheduled status
+// changes of an entity
+func (h *HTTPHandler) ListScheduledStatusChanges(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.checkQueryParams(w, r, "entity_id", "vendor_id", "state") {
+		return
+	}
+	h.listScheduledStatusChanges(w, r, r.URL.Query().Get("vendor_id"))
+}
+
+// listScheduledStatusChanges writes the scheduled status changes of the
+// entity of r, of vendorID when set
+func (h *HTTPHandler) listScheduledStatusChanges(w http.ResponseWriter, r *http.Request, vendorID string) {
+	entityID := r.URL.Query().Get("entity_id")
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	changes, err := h.service.ListScheduledStatusChanges(r.Context(), repository.ScheduledStatusChangeFilter{
+		EntityID: entityID,
+		VendorID: vendorID,
+		State:    r.URL.Query().Get("state"),
+	})
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"scheduled_status_changes": changes})
+}
+
+// CancelScheduledStatusChange handles POST
+// /api/v1/vendors/{id}/scheduled-status-changes/{schedule_id}/cancel requests
+func (h *HTTPHandler) CancelScheduledStatusChange(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.checkQueryParams(w, r, "entity_id") {
+		return
+	}
+
+	vendorID := r.PathValue("id")
+	entityID := r.URL.Query().Get("entity_id")
+	reqlog.SetVendor(r.Context(), vendorID)
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var cancelledBy *string
+	if user, err := auth.GetUserContext(r.Context()); err == nil && user != nil {
+		cancelledBy = &user.UserID
+	}
+
+	sc, err := h.service.CancelScheduledStatusChange(r.Context(), r.PathValue("schedule_id"), vendorID, entityID, cancelledBy)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sc)
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+	"github.com/pesio-ai/be-lib-common/auth"
+)
+
+// GetVendorSnapshot handles GET /api/v1/vendors/{id}/snapshot requests. Section
+// load times are sent in the Server-Timing header.
+func (h *HTTPHandler) GetVendorSnapshot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id") {
+		return
+	}
+
+	vendorID := r.PathValue("id")
+	entityID := r.URL.Query().Get("entity_id")
+	reqlog.SetVendor(r.Context(), vendorID)
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	// Restricted sections are only shown to identified users allowed to see them
+	var userID string
+	if user, err := auth.GetUserContext(r.Context()); err == nil && user != nil {
+		userID = user.UserID
+	}
+
+	snapshot, err := h.service.GetVendorSnapshot(r.Context(), vendorID, entityID, func(section string) bool {
+		return h.opts.Sections.Allows(section, userID)
+	})
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Server-Timing", serverTiming(snapshot.Timings))
+	json.NewEncoder(w).Encode(snapshot)
+}
+
+// serverTiming formats section load times as a Server-Timing header value
+func serverTiming(timings []service.SectionTiming) string {
+	metrics := make([]string, len(timings))
+	for i, t := range timings {
+		metrics[i] = fmt.Sprintf("%s;dur=%.2f", t.Section, float64(t.Duration.Microseconds())/1000)
+	}
+	return strings.Join(metrics, ", ")
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+	"github.com/pesio-ai/be-lib-common/auth"
+)
+
+// GetVendorSpend handles GET /api/v1/vendors/{id}/spend requests. Spend is the
+// spend section of the snapshot and restricted like it.
+func (h *HTTPHandler) GetVendorSpend(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id", "period") {
+		return
+	}
+
+	vendorID := r.PathValue("id")
+	entityID := r.URL.Query().Get("entity_id")
+	reqlog.SetVendor(r.Context(), vendorID)
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var userID string
+	if user, err := auth.GetUserContext(r.Context()); err == nil && user != nil {
+		userID = user.UserID
+	}
+	if !h.opts.Sections.Allows(service.SnapshotSpend, userID) {
+		writeError(w, http.StatusForbidden, errorBody{
+			Code:    codeForbidden,
+			Message: "vendor spend is restricted",
+		})
+		return
+	}
+
+	var periods []string
+	if raw := r.URL.Query().Get("period"); raw != "" {
+		periods = strings.Split(raw, ",")
+	}
+
+	result, err := h.service.GetVendorSpend(r.Context(), vendorID, entityID, periods)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
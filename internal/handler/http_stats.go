@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+)
+
+// GetVendorStats handles GET /api/v1/vendors/stats requests
+func (h *HTTPHandler) GetVendorStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id") {
+		return
+	}
+
+	entityID := r.URL.Query().Get("entity_id")
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	stats, err := h.service.GetVendorStats(r.Context(), entityID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// GetVendorGrowth handles GET /api/v1/vendors/metrics/growth requests
+func (h *HTTPHandler) GetVendorGrowth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id", "granularity", "from", "to") {
+		return
+	}
+
+	q := repository.GrowthQuery{
+		EntityID:    r.URL.Query().Get("entity_id"),
+		Granularity: r.URL.Query().Get("granularity"),
+	}
+	if q.EntityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	bounds := []struct {
+		name string
+		dest *time.Time
+	}{
+		{"from", &q.From},
+		{"to", &q.To},
+	}
+	for _, b := range bounds {
+		t, perr := queryTime(r, b.name)
+		if perr != nil {
+			writeParamError(w, perr)
+			return
+		}
+		*b.dest = t
+	}
+
+	growth, err := h.service.GetVendorGrowth(r.Context(), q)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(growth)
+}
+
+// GetGeographyReport handles GET /api/v1/vendors/reports/geography requests,
+// as JSON or, with format=csv, as a CSV download
+func (h *HTTPHandler) GetGeographyReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id", "status", "vendor_type", "include_balances", "format") {
+		return
+	}
+
+	q := service.GeographyQuery{
+		EntityID:   r.URL.Query().Get("entity_id"),
+		Status:     stringPtr(r.URL.Query().Get("status")),
+		VendorType: stringPtr(r.URL.Query().Get("vendor_type")),
+	}
+	if q.EntityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" {
+		writeParamError(w, &paramError{Field: "format", Message: fmt.Sprintf("format must be json or csv, got %q", format)})
+		return
+	}
+	includeBalances, perr := queryBool(r, "include_balances")
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+	q.IncludeBalances = includeBalances != nil && *includeBalances
+
+	report, err := h.service.GetGeographyReport(r.Context(), q)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := service.WriteGeographyCSV(&buf, report); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="vendor-geography-%s.csv"`, time.Now().UTC().Format("20060102")))
+	buf.WriteTo(w)
+}
+
+// GetPaymentMixReport handles GET /api/v1/vendors/reports/payment-mix
+// requests, as JSON or, with format=csv, as a CSV download
+func (h *HTTPHandler) GetPaymentMixReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id", "vendor_type", "country", "format") {
+		return
+	}
+
+	q := service.PaymentMixQuery{
+		EntityID:   r.URL.Query().Get("entity_id"),
+		VendorType: stringPtr(r.URL.Query().Get("vendor_type")),
+		Country:    stringPtr(r.URL.Query().Get("country")),
+	}
+	if q.EntityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "csv" {
+		writeParamError(w, &paramError{Field: "format", Message: fmt.Sprintf("format must be json or csv, got %q", format)})
+		return
+	}
+
+	report, err := h.service.GetPaymentMixReport(r.Context(), q)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := service.WritePaymentMixCSV(&buf, report); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="vendor-payment-mix-%s.csv"`, time.Now().UTC().Format("20060102")))
+	buf.WriteTo(w)
+}
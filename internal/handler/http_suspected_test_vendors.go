@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+)
+
+// ListSuspectedTestVendors handles GET /api/v1/vendors/suspected-test
+// requests, listing a page of the probable test vendors of an entity with
+// the rules each matches
+func (h *HTTPHandler) ListSuspectedTestVendors(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id", "page", "page_size") {
+		return
+	}
+
+	entityID := r.URL.Query().Get("entity_id")
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	page, perr := queryInt(r, "page", 1, 1, math.MaxInt32)
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+
+	pageSize, perr := queryInt(r, "page_size", service.DefaultSuspectedTestPageSize, 1, service.MaxSuspectedTestPageSize)
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+
+	result, err := h.service.ListSuspectedTestVendors(r.Context(), entityID, page, pageSize)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// ConfirmTestVendors handles POST /api/v1/vendors/suspected-test/confirm
+// requests, tagging or soft-deleting vendors confirmed to be test vendors
+func (h *HTTPHandler) ConfirmTestVendors(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req service.ConfirmTestVendorsRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	result, err := h.service.ConfirmTestVendors(r.Context(), &req)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
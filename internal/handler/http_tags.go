@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ListTags handles GET /api/v1/vendors/tags requests, listing the tags of an
+// entity's vendors with the number of vendors using each
+func (h *HTTPHandler) ListTags(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id") {
+		return
+	}
+
+	entityID := r.URL.Query().Get("entity_id")
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	tags, err := h.service.ListTags(r.Context(), entityID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"tags": tags})
+}
+
+// RenameTag handles POST /api/v1/admin/tags/rename requests, renaming a tag
+// on every vendor of an entity
+func (h *HTTPHandler) RenameTag(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.requireAdminToken(w, r) {
+		return
+	}
+
+	var req struct {
+		EntityID  string `json:"entity_id"`
+		From      string `json:"from"`
+		To        string `json:"to"`
+		UpdatedBy string `json:"updated_by,omitempty"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	updated, err := h.service.RenameTag(r.Context(), req.EntityID, req.From, req.To, stringPtr(req.UpdatedBy))
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"vendors_updated": updated})
+}
+
+// DeleteTag handles POST /api/v1/admin/tags/delete requests, removing a tag
+// from every vendor of an entity
+func (h *HTTPHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.requireAdminToken(w, r) {
+		return
+	}
+
+	var req struct {
+		EntityID  string `json:"entity_id"`
+		Tag       string `json:"tag"`
+		UpdatedBy string `json:"updated_by,omitempty"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	updated, err := h.service.DeleteTag(r.Context(), req.EntityID, req.Tag, stringPtr(req.UpdatedBy))
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"vendors_updated": updated})
+}
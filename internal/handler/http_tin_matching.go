@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+)
+
+// VerifyVendorTIN handles POST /api/v1/vendors/{id}/verify-tin requests,
+// verifying the tax ID and legal name of the vendor with the IRS TIN matching
+// service
+func (h *HTTPHandler) VerifyVendorTIN(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.checkQueryParams(w, r, "entity_id") {
+		return
+	}
+
+	entityID := r.URL.Query().Get("entity_id")
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+	vendorID := r.PathValue("id")
+	reqlog.SetVendor(r.Context(), vendorID)
+
+	match, err := h.service.VerifyVendorTIN(r.Context(), vendorID, entityID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(match)
+}
+
+// RunTINMatching handles POST /api/v1/admin/tin-matching/run requests,
+// verifying the 1099 vendors of an entity or of all entities
+func (h *HTTPHandler) RunTINMatching(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.requireAdminToken(w, r) {
+		return
+	}
+
+	var req struct {
+		EntityID string `json:"entity_id,omitempty"`
+	}
+	if r.ContentLength != 0 {
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+	}
+
+	report, err := h.service.RunTINMatching(r.Context(), req.EntityID)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UserAuth identifies the users of HTTP requests with the gRPC unary auth
+// interceptor, so that handlers find them with auth.GetUserContext as gRPC
+// handlers do. The interceptor validates the request's Authorization header,
+// presented as incoming metadata, and the request continues with the
+// authenticated context. Requests without an Authorization header, and those
+// presenting a vendor API key, which VendorKeyAuth handles, continue
+// anonymously; a header that fails validation is rejected with a 401.
+func UserAuth(unary grpc.UnaryServerInterceptor) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization := r.Header.Get("Authorization")
+			if authorization == "" || vendorKeyFromRequest(r) != "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := metadata.NewIncomingContext(r.Context(), metadata.Pairs("authorization", authorization))
+			info := &grpc.UnaryServerInfo{FullMethod: r.URL.Path}
+			served := false
+			_, err := unary(ctx, nil, info, func(ctx context.Context, _ interface{}) (interface{}, error) {
+				served = true
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return nil, nil
+			})
+			if err == nil || served {
+				return
+			}
+
+			if status.Code(err) == codes.PermissionDenied {
+				writeError(w, http.StatusForbidden, errorBody{Code: codeForbidden, Message: status.Convert(err).Message()})
+				return
+			}
+			writeError(w, http.StatusUnauthorized, errorBody{Code: codeUnauthorized, Message: status.Convert(err).Message()})
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+	"github.com/pesio-ai/be-lib-common/auth"
+)
+
+// VendorAliases handles /api/v1/vendors/{id}/aliases: GET lists the aliases of
+// the vendor, POST adds one and warns about other vendors known by the name
+func (h *HTTPHandler) VendorAliases(w http.ResponseWriter, r *http.Request) {
+	vendorID := r.PathValue("id")
+	reqlog.SetVendor(r.Context(), vendorID)
+
+	switch r.Method {
+	case http.MethodGet:
+		if !h.checkQueryParams(w, r, "entity_id") {
+			return
+		}
+		entityID := r.URL.Query().Get("entity_id")
+		if entityID == "" {
+			http.Error(w, "Entity ID is required", http.StatusBadRequest)
+			return
+		}
+
+		aliases, err := h.service.ListVendorAliases(r.Context(), vendorID, entityID)
+		if err != nil {
+			writeServiceError(w, err, http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"aliases": aliases})
+
+	case http.MethodPost:
+		var req service.AddVendorAliasRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+		req.VendorID = vendorID
+		if user, err := auth.GetUserContext(r.Context()); err == nil && user != nil {
+			req.CreatedBy = &user.UserID
+		}
+
+		added, err := h.service.AddVendorAlias(r.Context(), &req)
+		if err != nil {
+			writeServiceError(w, err, http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(added)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// DeleteVendorAlias handles DELETE /api/v1/vendors/{id}/aliases/{alias_id}
+// requests
+func (h *HTTPHandler) DeleteVendorAlias(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id") {
+		return
+	}
+
+	vendorID := r.PathValue("id")
+	entityID := r.URL.Query().Get("entity_id")
+	reqlog.SetVendor(r.Context(), vendorID)
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var removedBy *string
+	if user, err := auth.GetUserContext(r.Context()); err == nil && user != nil {
+		removedBy = &user.UserID
+	}
+
+	if err := h.service.RemoveVendorAlias(r.Context(), r.PathValue("alias_id"), vendorID, entityID, removedBy); err != nil {
+		writeServiceError(w, err, http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MatchVendors handles /api/v1/vendors/match: GET returns the vendors whose
+// name, legal name, DBA name or an alias equals the name given, POST ranks the
+// vendors most likely to be a supplier named on an invoice
+func (h *HTTPHandler) MatchVendors(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		if !h.checkQueryParams(w, r, "entity_id", "name") {
+			return
+		}
+
+		matches, err := h.service.MatchVendorsByName(r.Context(), r.URL.Query().Get("entity_id"), r.URL.Query().Get("name"))
+		if err != nil {
+			writeServiceError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"matches": matches})
+
+	case http.MethodPost:
+		if !h.checkQueryParams(w, r) {
+			return
+		}
+		var req service.MatchVendorsRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+
+		candidates, err := h.service.MatchVendors(r.Context(), &req)
+		if err != nil {
+			writeServiceError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"candidates": candidates})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
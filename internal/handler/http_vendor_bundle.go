@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+	"github.com/pesio-ai/be-lib-common/auth"
+)
+
+// ExportVendorBundle handles GET /api/v1/vendors/{id}/export requests,
+// downloading the vendor as a bundle for ImportVendorBundle. Sections the
+// caller may not see are left out or masked as in the snapshot.
+func (h *HTTPHandler) ExportVendorBundle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id") {
+		return
+	}
+
+	vendorID := r.PathValue("id")
+	entityID := r.URL.Query().Get("entity_id")
+	reqlog.SetVendor(r.Context(), vendorID)
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var userID string
+	if user, err := auth.GetUserContext(r.Context()); err == nil && user != nil {
+		userID = user.UserID
+	}
+
+	bundle, err := h.service.ExportVendorBundle(r.Context(), vendorID, entityID, userID, func(section string) bool {
+		return h.opts.Sections.Allows(section, userID)
+	})
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="vendor-%s.json"`, bundle.Vendor.ID))
+	json.NewEncoder(w).Encode(bundle)
+}
+
+// ImportVendorBundle handles POST /api/v1/vendors/import-bundle requests,
+// recreating the vendor of a bundle in an entity. A vendor code in use answers
+// 409 with the import report listing the conflict.
+func (h *HTTPHandler) ImportVendorBundle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req service.ImportVendorBundleRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if user, err := auth.GetUserContext(r.Context()); err == nil && user != nil {
+		req.ImportedBy = user.UserID
+	}
+
+	result, err := h.service.ImportVendorBundle(r.Context(), &req)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !result.Imported {
+		w.WriteHeader(http.StatusConflict)
+	} else {
+		w.Header().Set("Location", vendorLocation(result.Vendor.ID))
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(result)
+}
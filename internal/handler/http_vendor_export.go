@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+)
+
+// Error codes of vendor exports
+const (
+	codeExportsDisabled   = "EXPORTS_DISABLED"
+	codeExportLinkInvalid = "EXPORT_LINK_INVALID"
+)
+
+type vendorExportRequest struct {
+	EntityID             string  `json:"entity_id"`
+	ExcludeSuspectedTest bool    `json:"exclude_suspected_test"`
+	CreatedBy            *string `json:"created_by,omitempty"`
+}
+
+// StartVendorExport handles POST /api/v1/vendors/export-jobs requests,
+// queuing a CSV export of the vendors of an entity for the worker. Poll
+// GET /api/v1/vendors/export-jobs/{id} for its progress and download_url.
+func (h *HTTPHandler) StartVendorExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req vendorExportRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	job, err := h.service.StartVendorExport(r.Context(), req.EntityID, req.ExcludeSuspectedTest, req.CreatedBy)
+	if err != nil {
+		writeVendorExportError(w, err, http.StatusInternalServerError)
+		return
+	}
+	setExportDownloadURL(job, req.EntityID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", exportJobLocation(job.ID))
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// setExportDownloadURL sets the signed download URL of a job with a link
+func setExportDownloadURL(job *service.ExportJob, entityID string) {
+	if job.Link == nil {
+		return
+	}
+	q := url.Values{}
+	q.Set("entity_id", entityID)
+	q.Set("expires", strconv.FormatInt(job.Link.Expires, 10))
+	q.Set("signature", job.Link.Signature)
+	job.DownloadURL = exportJobLocation(job.ID) + "/download?" + q.Encode()
+}
+
+// exportLinkParams parses the signed link of a download request; it is nil
+// when the request carries none
+func exportLinkParams(r *http.Request) (*service.ExportLink, *paramError) {
+	expires, signature := r.URL.Query().Get("expires"), r.URL.Query().Get("signature")
+	if expires == "" && signature == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return nil, &paramError{Field: "expires", Message: "expires must be a Unix timestamp"}
+	}
+	return &service.ExportLink{Expires: n, Signature: signature}, nil
+}
+
+// writeVendorExportError maps the errors of vendor exports to their status
+func writeVendorExportError(w http.ResponseWriter, err error, fallbackStatus int) {
+	var linkErr *service.ExportLinkError
+	switch {
+	case stderrors.Is(err, service.ErrVendorExportsDisabled):
+		writeError(w, http.StatusServiceUnavailable, errorBody{
+			Code:    codeExportsDisabled,
+			Message: err.Error(),
+		})
+	case stderrors.As(err, &linkErr):
+		writeError(w, http.StatusForbidden, errorBody{
+			Code:    codeExportLinkInvalid,
+			Message: linkErr.Error(),
+		})
+	default:
+		writeServiceError(w, err, fallbackStatus)
+	}
+}
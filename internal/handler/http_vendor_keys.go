@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/authz"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+	"github.com/pesio-ai/be-lib-common/auth"
+	"github.com/rs/zerolog"
+)
+
+const (
+	codeUnauthorized = "UNAUTHORIZED"
+
+	// vendorKeyHeader carries a vendor API key; "Authorization: Bearer vk_..."
+	// is accepted as well
+	vendorKeyHeader = "X-Vendor-API-Key"
+	// portalPathPrefix is the part of the HTTP API open to vendor API keys
+	portalPathPrefix = "/api/v1/portal/"
+)
+
+// vendorKeyFromRequest returns the vendor API key presented with a request
+func vendorKeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get(vendorKeyHeader); key != "" {
+		return key
+	}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && service.IsVendorAPIKey(token) {
+		return token
+	}
+	return ""
+}
+
+// VendorKeyAuth authenticates requests presenting a vendor API key. They may
+// only reach the portal routes under /api/v1/portal/, which in turn require a
+// key; the handlers find it with authz.VendorKeyFromContext. Every use of a
+// key is logged.
+func VendorKeyAuth(authenticator VendorKeyAuthenticator, log *zerolog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			secret := vendorKeyFromRequest(r)
+			portal := strings.HasPrefix(r.URL.Path, portalPathPrefix)
+			if secret == "" {
+				if portal {
+					writeError(w, http.StatusUnauthorized, errorBody{
+						Code:    codeUnauthorized,
+						Message: "a vendor API key is required (" + vendorKeyHeader + " header)",
+					})
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			key, err := authenticator.AuthenticateVendorAPIKey(ctx, secret)
+			if err != nil {
+				if stderrors.Is(err, service.ErrInvalidAPIKey) {
+					reqlog.Logger(ctx, log).Warn().Str("path", r.URL.Path).Msg("Invalid vendor API key presented")
+					writeError(w, http.StatusUnauthorized, errorBody{Code: codeUnauthorized, Message: err.Error()})
+					return
+				}
+				writeServiceError(w, err, http.StatusInternalServerError)
+				return
+			}
+
+			reqlog.SetEntity(ctx, key.EntityID)
+			reqlog.SetVendor(ctx, key.VendorID)
+			reqlog.Logger(ctx, log).Info().
+				Str("key_id", key.KeyID).
+				Str("method", r.Method).
+				Str("path", r.URL.Path).
+				Msg("Vendor API key used")
+
+			if !portal {
+				writeError(w, http.StatusForbidden, errorBody{
+					Code:    codeForbidden,
+					Message: "vendor API keys can only access " + portalPathPrefix,
+				})
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(authz.WithVendorKey(ctx, key)))
+		})
+	}
+}
+
+// portalKey returns the vendor key of a portal request if it has scope,
+// writing the error response otherwise
+func portalKey(w http.ResponseWriter, r *http.Request, scope string) (*authz.VendorKey, bool) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return nil, false
+	}
+
+	key, ok := authz.VendorKeyFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, errorBody{Code: codeUnauthorized, Message: "a vendor API key is required"})
+		return nil, false
+	}
+	if !key.HasScope(scope) {
+		writeError(w, http.StatusForbidden, errorBody{
+			Code:    codeForbidden,
+			Message: "the API key lacks the " + scope + " scope",
+		})
+		return nil, false
+	}
+	return key, true
+}
+
+// GetPortalProfile handles GET /api/v1/portal/profile requests: the profile of
+// the key's vendor (read_profile scope)
+func (h *HTTPHandler) GetPortalProfile(w http.ResponseWriter, r *http.Request) {
+	key, ok := portalKey(w, r, service.ScopeReadProfile)
+	if !ok || !h.checkQueryParams(w, r) {
+		return
+	}
+
+	profile, err := h.service.GetPortalProfile(r.Context(), key)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+// GetPortalSpend handles GET /api/v1/portal/spend requests: what the key's
+// vendor was paid (read_invoices scope)
+func (h *HTTPHandler) GetPortalSpend(w http.ResponseWriter, r *http.Request) {
+	key, ok := portalKey(w, r, service.ScopeReadInvoices)
+	if !ok || !h.checkQueryParams(w, r, "period") {
+		return
+	}
+
+	var periods []string
+	if raw := r.URL.Query().Get("period"); raw != "" {
+		periods = strings.Split(raw, ",")
+	}
+
+	result, err := h.service.GetPortalSpend(r.Context(), key, periods)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// VendorAPIKeys handles /api/v1/vendors/{id}/api-keys: GET lists the keys of
+// the vendor, POST creates one and returns its secret once
+func (h *HTTPHandler) VendorAPIKeys(w http.ResponseWriter, r *http.Request) {
+	vendorID := r.PathValue("id")
+	reqlog.SetVendor(r.Context(), vendorID)
+
+	switch r.Method {
+	case http.MethodGet:
+		if !h.checkQueryParams(w, r, "entity_id") {
+			return
+		}
+		entityID := r.URL.Query().Get("entity_id")
+		if entityID == "" {
+			http.Error(w, "Entity ID is required", http.StatusBadRequest)
+			return
+		}
+
+		keys, err := h.service.ListVendorAPIKeys(r.Context(), vendorID, entityID)
+		if err != nil {
+			writeServiceError(w, err, http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"api_keys": keys})
+
+	case http.MethodPost:
+		var req service.CreateVendorAPIKeyRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+		req.VendorID = vendorID
+		if user, err := auth.GetUserContext(r.Context()); err == nil && user != nil {
+			req.CreatedBy = &user.UserID
+		}
+
+		created, err := h.service.CreateVendorAPIKey(r.Context(), &req)
+		if err != nil {
+			writeServiceError(w, err, http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(created)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// RevokeVendorAPIKey handles POST /api/v1/vendors/{id}/api-keys/{key_id}/revoke
+// requests
+func (h *HTTPHandler) RevokeVendorAPIKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id") {
+		return
+	}
+
+	vendorID := r.PathValue("id")
+	entityID := r.URL.Query().Get("entity_id")
+	reqlog.SetVendor(r.Context(), vendorID)
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	var revokedBy *string
+	if user, err := auth.GetUserContext(r.Context()); err == nil && user != nil {
+		revokedBy = &user.UserID
+	}
+
+	key, err := h.service.RevokeVendorAPIKey(r.Context(), r.PathValue("key_id"), vendorID, entityID, revokedBy)
+	if err != nil {
+		writeServiceError(w, err, http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(key)
+}
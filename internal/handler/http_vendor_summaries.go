@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ListVendorSummaries handles GET /api/v1/vendors/summaries requests. It takes
+// the filters and search of ListVendors and returns the lightweight summaries
+// meant for dropdowns and typeaheads.
+func (h *HTTPHandler) ListVendorSummaries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, vendorListParams...) {
+		return
+	}
+
+	filter, page, pageSize, ok := parseVendorListQuery(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.ListVendorSummaries(r.Context(), filter, page, pageSize)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"vendors":    result.Vendors,
+		"total":      result.Total,
+		"total_mode": result.TotalMode,
+		"page":       page,
+		"pageSize":   pageSize,
+	})
+}
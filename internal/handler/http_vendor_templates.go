@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-lib-common/auth"
+)
+
+// vendorTemplateRequest is the body of vendor template creates and updates
+type vendorTemplateRequest struct {
+	EntityID string                            `json:"entity_id"`
+	Name     string                            `json:"name"`
+	Defaults repository.VendorTemplateDefaults `json:"defaults"`
+}
+
+// writeVendorTemplateError writes a 404 for unknown templates and a service
+// error with fallbackStatus otherwise
+func writeVendorTemplateError(w http.ResponseWriter, err error, fallbackStatus int) {
+	if repository.IsNotFound(err) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	writeServiceError(w, err, fallbackStatus)
+}
+
+// VendorTemplates handles /api/v1/vendor-templates: GET lists the vendor
+// templates of an entity, POST creates one
+func (h *HTTPHandler) VendorTemplates(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		if !h.checkQueryParams(w, r, "entity_id") {
+			return
+		}
+		entityID := r.URL.Query().Get("entity_id")
+		if entityID == "" {
+			http.Error(w, "Entity ID is required", http.StatusBadRequest)
+			return
+		}
+
+		templates, err := h.service.ListVendorTemplates(r.Context(), entityID)
+		if err != nil {
+			writeServiceError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"vendor_templates": templates})
+
+	case http.MethodPost:
+		var req vendorTemplateRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+
+		t := &repository.VendorTemplate{
+			EntityID: req.EntityID,
+			Name:     req.Name,
+			Defaults: req.Defaults,
+		}
+		if user, err := auth.GetUserContext(r.Context()); err == nil && user != nil {
+			t.CreatedBy = &user.UserID
+		}
+
+		if err := h.service.CreateVendorTemplate(r.Context(), t); err != nil {
+			writeServiceError(w, err, http.StatusConflict)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(t)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// VendorTemplate handles /api/v1/vendor-templates/{id}: GET retrieves a vendor
+// template, PUT replaces it and DELETE removes it
+func (h *HTTPHandler) VendorTemplate(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	switch r.Method {
+	case http.MethodGet, http.MethodDelete:
+		if !h.checkQueryParams(w, r, "entity_id") {
+			return
+		}
+		entityID := r.URL.Query().Get("entity_id")
+		if entityID == "" {
+			http.Error(w, "Entity ID is required", http.StatusBadRequest)
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			if err := h.service.DeleteVendorTemplate(r.Context(), id, entityID); err != nil {
+				writeVendorTemplateError(w, err, http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		t, err := h.service.GetVendorTemplate(r.Context(), id, entityID)
+		if err != nil {
+			writeVendorTemplateError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(t)
+
+	case http.MethodPut:
+		var req vendorTemplateRequest
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+
+		t := &repository.VendorTemplate{
+			ID:       id,
+			EntityID: req.EntityID,
+			Name:     req.Name,
+			Defaults: req.Defaults,
+		}
+		if user, err := auth.GetUserContext(r.Context()); err == nil && user != nil {
+			t.UpdatedBy = &user.UserID
+		}
+
+		if err := h.service.UpdateVendorTemplate(r.Context(), t); err != nil {
+			writeVendorTemplateError(w, err, http.StatusConflict)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(t)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
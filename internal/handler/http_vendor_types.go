@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+)
+
+const codeVendorTypeInUse = "VENDOR_TYPE_IN_USE"
+
+// ListVendorTypes handles GET /api/v1/vendor-types requests
+func (h *HTTPHandler) ListVendorTypes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !h.checkQueryParams(w, r, "entity_id", "include_deprecated") {
+		return
+	}
+
+	entityID := r.URL.Query().Get("entity_id")
+	if entityID == "" {
+		http.Error(w, "Entity ID is required", http.StatusBadRequest)
+		return
+	}
+
+	includeDeprecated, perr := queryBool(r, "include_deprecated")
+	if perr != nil {
+		writeParamError(w, perr)
+		return
+	}
+
+	types, err := h.service.ListVendorTypes(r.Context(), entityID, includeDeprecated != nil && *includeDeprecated)
+	if err != nil {
+		writeServiceError(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"vendor_types": types,
+	})
+}
+
+// VendorTypes handles GET/POST/PUT/DELETE /api/v1/admin/vendor-types requests
+func (h *HTTPHandler) VendorTypes(w http.ResponseWriter, r *http.Request) {
+	if !h.requireAdminToken(w, r) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if !h.checkQueryParams(w, r, "entity_id") {
+			return
+		}
+
+		entityID := r.URL.Query().Get("entity_id")
+		if entityID == "" {
+			http.Error(w, "Entity ID is required", http.StatusBadRequest)
+			return
+		}
+
+		types, err := h.service.ListVendorTypes(r.Context(), entityID, true)
+		if err != nil {
+			writeServiceError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"vendor_types": types,
+		})
+
+	case http.MethodPost, http.MethodPut:
+		var req struct {
+			EntityID     string `json:"entity_id"`
+			Code         string `json:"code"`
+			Label        string `json:"label"`
+			IsDeprecated bool   `json:"is_deprecated"`
+			UpdatedBy    string `json:"updated_by,omitempty"`
+		}
+		if !decodeJSON(w, r, &req) {
+			return
+		}
+
+		vt := &repository.VendorType{
+			EntityID:     req.EntityID,
+			Code:         req.Code,
+			Label:        req.Label,
+			IsDeprecated: req.IsDeprecated,
+		}
+		if req.UpdatedBy != "" {
+			vt.UpdatedBy = &req.UpdatedBy
+		}
+
+		status := http.StatusCreated
+		var err error
+		if r.Method == http.MethodPost {
+			err = h.service.CreateVendorType(r.Context(), vt)
+		} else {
+			status = http.StatusOK
+			err = h.service.UpdateVendorType(r.Context(), vt)
+		}
+		if err != nil {
+			writeVendorTypeError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(vt)
+
+	case http.MethodDelete:
+		if !h.checkQueryParams(w, r, "entity_id", "code") {
+			return
+		}
+
+		entityID := r.URL.Query().Get("entity_id")
+		code := r.URL.Query().Get("code")
+		if entityID == "" || code == "" {
+			http.Error(w, "Entity ID and code are required", http.StatusBadRequest)
+			return
+		}
+
+		if err := h.service.DeleteVendorType(r.Context(), entityID, code); err != nil {
+			writeVendorTypeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// writeVendorTypeError writes a 409 for types still in use, a 404 for unknown
+// types and a service error otherwise
+func writeVendorTypeError(w http.ResponseWriter, err error) {
+	var inUse *service.VendorTypeInUseError
+	switch {
+	case stderrors.As(err, &inUse):
+		writeError(w, http.StatusConflict, errorBody{
+			Code:    codeVendorTypeInUse,
+			Message: inUse.Error(),
+			Details: map[string]interface{}{"code": inUse.Code, "vendors": inUse.Vendors},
+		})
+	case repository.IsNotFound(err):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		writeServiceError(w, err, http.StatusInternalServerError)
+	}
+}
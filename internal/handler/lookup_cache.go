@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"google.golang.org/grpc"
+)
+
+// LookupCache installs the vendor lookup cache of HTTP requests, so that the
+// vendor reads of a request are queried once until the request writes
+func LookupCache(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(repository.WithLookupCache(r.Context())))
+	})
+}
+
+// LookupCacheInterceptor installs the vendor lookup cache of unary RPCs.
+// Streams live too long for it and read without one.
+func LookupCacheInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(repository.WithLookupCache(ctx), req)
+	}
+}
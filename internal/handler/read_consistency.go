@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// readConsistencyParam selects where a request's reads are served from:
+// "replica" (default) or "primary"
+const readConsistencyParam = "read_consistency"
+
+// readConsistencyMetadata is the gRPC metadata counterpart of readConsistencyParam
+const readConsistencyMetadata = "x-read-consistency"
+
+// ReadConsistency installs the read routing state of HTTP requests, honouring
+// the read_consistency query parameter
+func ReadConsistency(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		level := r.URL.Query().Get(readConsistencyParam)
+		if level != "" && !repository.IsValidReadConsistency(level) {
+			writeParamError(w, &paramError{Field: readConsistencyParam, Message: "must be primary or replica"})
+			return
+		}
+
+		ctx := repository.WithReadConsistency(r.Context(), level == repository.ReadConsistencyPrimary)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ReadConsistencyInterceptor installs the read routing state of unary RPCs,
+// honouring the x-read-consistency metadata key
+func ReadConsistencyInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var level string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(readConsistencyMetadata); len(values) > 0 {
+				level = values[0]
+			}
+		}
+		if level != "" && !repository.IsValidReadConsistency(level) {
+			return nil, status.Error(codes.InvalidArgument, readConsistencyMetadata+" must be primary or replica")
+		}
+
+		return handler(repository.WithReadConsistency(ctx, level == repository.ReadConsistencyPrimary), req)
+	}
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/authz"
+	"github.com/pesio-ai/be-ap-vendors/internal/country"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/service"
+)
+
+// HTTPService is the part of the vendor service used by the HTTP handler
+type HTTPService interface {
+	CreateVendor(ctx context.Context, req *service.CreateVendorRequest) (*repository.Vendor, error)
+	GetVendor(ctx context.Context, id, entityID string, expand ...string) (*repository.Vendor, error)
+	GetVendorSnapshot(ctx context.Context, id, entityID string, allowed func(section string) bool) (*service.VendorSnapshot, error)
+	ExportVendorBundle(ctx context.Context, id, entityID, userID string, allowed func(section string) bool) (*service.VendorBundle, error)
+	ImportVendorBundle(ctx context.Context, req *service.ImportVendorBundleRequest) (*service.BundleImportResult, error)
+	GetVendorActivity(ctx context.Context, vendorID, entityID string, q service.ActivityQuery, allowed func(section string) bool) (*service.ActivityPage, error)
+	GetVendorSpend(ctx context.Context, id, entityID string, periods []string) (*service.VendorSpend, error)
+	GetOnboardingStatus(ctx context.Context, id, entityID string) (*service.OnboardingStatus, error)
+	GetVendorByCode(ctx context.Context, code, entityID string) (*repository.Vendor, error)
+	UpdateVendor(ctx context.Context, req *service.UpdateVendorRequest) (*repository.Vendor, error)
+	UpsertVendorByCode(ctx context.Context, req *service.UpsertVendorRequest) (*repository.Vendor, bool, error)
+	DeleteVendor(ctx context.Context, id, entityID string, dryRun bool, keepChildren *bool) (*service.DeleteReport, error)
+	BulkDeleteVendors(ctx context.Context, req *service.BulkDeleteRequest) (*service.BulkDeleteReport, error)
+	ListVendors(ctx context.Context, filter repository.VendorFilter, page, pageSize int) (*service.VendorPage, error)
+	ListVendorSummaries(ctx context.Context, filter repository.VendorFilter, page, pageSize int) (*service.VendorSummaryPage, error)
+	ListVendorChanges(ctx context.Context, entityID string, since int64, limit int) (*service.VendorChanges, error)
+	ListBankChanges(ctx context.Context, entityID string, since time.Time, limit int) ([]*repository.AuditEntry, error)
+	GetVendorStats(ctx context.Context, entityID string) (*service.VendorStats, error)
+	GetVendorGrowth(ctx context.Context, q repository.GrowthQuery) (*service.VendorGrowth, error)
+	GetGeographyReport(ctx context.Context, q service.GeographyQuery) (*service.GeographyReport, error)
+	GetPaymentMixReport(ctx context.Context, q service.PaymentMixQuery) (*service.PaymentMixReport, error)
+	ListApprovalQueue(ctx context.Context, entityID, sort string, page, pageSize int) (*service.ApprovalQueue, error)
+	CountApprovalQueue(ctx context.Context, entityID string) (*service.ApprovalQueueCount, error)
+	ApproveVendor(ctx context.Context, req *service.ApprovalDecisionRequest) (*repository.Vendor, error)
+	RejectVendor(ctx context.Context, req *service.ApprovalDecisionRequest) (*repository.Vendor, error)
+	ActivateVendor(ctx context.Context, id, entityID, updatedBy string) (*repository.Vendor, error)
+	DeactivateVendor(ctx context.Context, id, entityID, updatedBy string) (*repository.Vendor, error)
+	SuspendVendor(ctx context.Context, id, entityID, updatedBy string) (*repository.Vendor, error)
+	ValidateVendor(ctx context.Context, vendorID, entityID, invoiceCurrency string) (*service.VendorValidation, error)
+	UpdateBalance(ctx context.Context, vendorID, entityID string, amount int64) error
+
+	ListVendorContacts(ctx context.Context, filter repository.ContactFilter, page, pageSize int) (*service.ContactPage, error)
+	GetVendorContact(ctx context.Context, vendorID, contactID string) (*repository.VendorContact, error)
+	AddVendorContact(ctx context.Context, req *service.AddContactRequest) (*repository.VendorContact, bool, error)
+	SetContactCommunications(ctx context.Context, req *service.ContactCommunicationsRequest) (*repository.VendorContact, error)
+	DeleteVendorContact(ctx context.Context, vendorID, contactID, entityID string, removedBy *string) error
+	GetContactsForCommunication(ctx context.Context, vendorID, entityID, commType string) ([]*repository.VendorContact, error)
+	ListDuplicateContactEmails(ctx context.Context, entityID string) ([]*repository.DuplicateContactEmail, error)
+	GetDataQualityReport(ctx context.Context, entityID, rule string, page, pageSize int) (*service.DataQualityReport, error)
+	ListSuspectedTestVendors(ctx context.Context, entityID string, page, pageSize int) (*service.SuspectedTestPage, error)
+	ConfirmTestVendors(ctx context.Context, req *service.ConfirmTestVendorsRequest) (*service.TestVendorConfirmation, error)
+	ListEntityContacts(ctx context.Context, entityID string) ([]*repository.EntityContact, error)
+	ImportContacts(ctx context.Context, req *service.ImportContactsRequest) (*service.ImportResult, error)
+	GetPaymentTerms(ctx context.Context) ([]*repository.PaymentTerm, error)
+	GetPaymentTermUsage(ctx context.Context, code string) (*service.PaymentTermUsage, error)
+	DeactivatePaymentTerm(ctx context.Context, code, replacementCode string, force bool, actorID *string) (*service.PaymentTermDeactivation, error)
+
+	SetExternalRef(ctx context.Context, vendorID, entityID, system, externalID, createdBy string) (*repository.VendorExternalRef, error)
+	GetExternalRefs(ctx context.Context, vendorID, entityID string) ([]*repository.VendorExternalRef, error)
+	DeleteExternalRef(ctx context.Context, vendorID, entityID, system string) error
+	GetVendorByExternalRef(ctx context.Context, entityID, system, externalID string) (*repository.Vendor, error)
+
+	GetRetentionSettings(ctx context.Context, entityID string) (*repository.RetentionSettings, error)
+	SetRetentionSettings(ctx context.Context, settings *repository.RetentionSettings) error
+	GetValidationSettings(ctx context.Context, entityID string) (*repository.ValidationSettings, error)
+	SetValidationSettings(ctx context.Context, settings *repository.ValidationSettings) error
+	GetEntitySettings(ctx context.Context, entityID string) (*repository.EntitySettings, error)
+	SetEntitySettings(ctx context.Context, settings *repository.EntitySettings) error
+	GetApprovalPolicy(ctx context.Context, entityID string) (*repository.ApprovalPolicy, error)
+	SetApprovalPolicy(ctx context.Context, policy *repository.ApprovalPolicy) error
+	ListVendorTypes(ctx context.Context, entityID string, includeDeprecated bool) ([]*repository.VendorType, error)
+	ListCountryProfiles(ctx context.Context) []*country.Profile
+	GetCountryProfile(ctx context.Context, code string) (*country.Profile, error)
+	CreateVendorType(ctx context.Context, vt *repository.VendorType) error
+	UpdateVendorType(ctx context.Context, vt *repository.VendorType) error
+	DeleteVendorType(ctx context.Context, entityID, code string) error
+	ListImportTemplates(ctx context.Context, entityID string) ([]*repository.ImportTemplate, error)
+	GetImportTemplate(ctx context.Context, id, entityID string) (*repository.ImportTemplate, error)
+	CreateImportTemplate(ctx context.Context, t *repository.ImportTemplate) error
+	UpdateImportTemplate(ctx context.Context, t *repository.ImportTemplate) error
+	DeleteImportTemplate(ctx context.Context, id, entityID string) error
+	ListVendorTemplates(ctx context.Context, entityID string) ([]*repository.VendorTemplate, error)
+	GetVendorTemplate(ctx context.Context, id, entityID string) (*repository.VendorTemplate, error)
+	CreateVendorTemplate(ctx context.Context, t *repository.VendorTemplate) error
+	UpdateVendorTemplate(ctx context.Context, t *repository.VendorTemplate) error
+	DeleteVendorTemplate(ctx context.Context, id, entityID string) error
+	ListTags(ctx context.Context, entityID string) ([]*repository.TagCount, error)
+	RenameTag(ctx context.Context, entityID, from, to string, actorID *string) (int, error)
+	DeleteTag(ctx context.Context, entityID, tag string, actorID *string) (int, error)
+	EffectiveEntityFlags(ctx context.Context, entityID string) (*service.EntityFlags, error)
+	Purge(ctx context.Context, opts service.PurgeOptions) (*service.PurgeReport, error)
+	NormalizeAddresses(ctx context.Context, opts service.AddressNormalizationOptions) (*service.AddressNormalizationReport, error)
+	AddBlocklistEntry(ctx context.Context, req *service.AddBlocklistEntryRequest) (*repository.BlocklistEntry, error)
+	ListBlocklistEntries(ctx context.Context) ([]*repository.BlocklistEntry, error)
+	RemoveBlocklistEntry(ctx context.Context, id string) error
+	ListBlocklistMatches(ctx context.Context, entityID string, limit int) ([]*repository.BlocklistMatch, error)
+	RecomputeRiskScores(ctx context.Context, entityID string) (*service.RiskRecomputeReport, error)
+	DetectDormantVendors(ctx context.Context, entityID string) (*service.DormancyReport, error)
+	ReconcileBalances(ctx context.Context, entityID string, fix bool) (*service.BalanceReconciliation, error)
+	ListDormantVendors(ctx context.Context, entityID, after string, limit int) (*service.DormantVendorPage, error)
+	GenerateForm1099(ctx context.Context, req *service.Form1099Request) (*service.Form1099Export, error)
+	StartForm1099Export(ctx context.Context, req *service.Form1099Request) (*service.ExportJob, error)
+	GetExportJob(ctx context.Context, id, entityID string) (*service.ExportJob, error)
+	GetExportJobFile(ctx context.Context, id, entityID string, link *service.ExportLink) (*service.ExportFile, error)
+	StartVendorExport(ctx context.Context, entityID string, excludeSuspectedTest bool, createdBy *string) (*service.ExportJob, error)
+	DownloadVendorDocument(ctx context.Context, documentID, entityID, userID, byteRange string) (*service.DocumentDownload, error)
+	VerifyVendorTIN(ctx context.Context, id, entityID string) (*repository.TINMatch, error)
+	StartBankVerification(ctx context.Context, id, entityID, method string) (*repository.BankVerification, error)
+	ConfirmBankVerification(ctx context.Context, id, entityID string, amounts []int64) (*repository.BankVerification, error)
+	FindVendorsByBankLast4(ctx context.Context, entityID, last4, userID string, limit int) ([]*repository.BankLast4Match, error)
+	RunTINMatching(ctx context.Context, entityID string) (*service.TINMatchReport, error)
+	ReserveVendorCode(ctx context.Context, entityID, requestedPrefix, reservedBy string) (*repository.VendorCodeReservation, error)
+	CleanupEntity(ctx context.Context, entityID string, dryRun bool, actorID, eventID *string) (*service.EntityCleanupReport, error)
+	GetEntityLifecycle(ctx context.Context, entityID string, limit int) (*service.EntityLifecycle, error)
+	GetRiskWeights(ctx context.Context, entityID string) (*service.RiskWeights, error)
+	SetRiskWeights(ctx context.Context, entityID string, overrides map[string]int, updatedBy string) (*service.RiskRecomputeReport, error)
+	CreateVendorAPIKey(ctx context.Context, req *service.CreateVendorAPIKeyRequest) (*service.CreatedVendorAPIKey, error)
+	ListVendorAPIKeys(ctx context.Context, vendorID, entityID string) ([]*repository.VendorAPIKey, error)
+	RevokeVendorAPIKey(ctx context.Context, keyID, vendorID, entityID string, revokedBy *string) (*repository.VendorAPIKey, error)
+	GetPortalProfile(ctx context.Context, key *authz.VendorKey) (*service.PortalProfile, error)
+	GetPortalSpend(ctx context.Context, key *authz.VendorKey, periods []string) (*service.VendorSpend, error)
+	AddVendorAlias(ctx context.Context, req *service.AddVendorAliasRequest) (*service.AddedVendorAlias, error)
+	ListVendorAliases(ctx context.Context, vendorID, entityID string) ([]*repository.VendorAlias, error)
+	RemoveVendorAlias(ctx context.Context, aliasID, vendorID, entityID string, removedBy *string) error
+	MatchVendorsByName(ctx context.Context, entityID, name string) ([]*repository.VendorNameMatch, error)
+	MatchVendors(ctx context.Context, req *service.MatchVendorsRequest) ([]*service.VendorMatch, error)
+	ScheduleStatusChange(ctx context.Context, req *service.ScheduleStatusChangeRequest) (*repository.ScheduledStatusChange, error)
+	CancelScheduledStatusChange(ctx context.Context, id, vendorID, entityID string, cancelledBy *string) (*repository.ScheduledStatusChange, error)
+	ListScheduledStatusChanges(ctx context.Context, filter repository.ScheduledStatusChangeFilter) ([]*repository.ScheduledStatusChange, error)
+	ToggleFavoriteVendor(ctx context.Context, userID, entityID, vendorID string) (bool, error)
+	ListFavoriteVendors(ctx context.Context, userID, entityID string) ([]*repository.Vendor, error)
+	RecordVendorView(ctx context.Context, userID, entityID, vendorID string)
+	ListRecentVendors(ctx context.Context, userID, entityID string) ([]*repository.Vendor, error)
+}
+
+// VendorKeyAuthenticator resolves vendor API keys presented to the HTTP API
+// and gRPC
+type VendorKeyAuthenticator interface {
+	AuthenticateVendorAPIKey(ctx context.Context, secret string) (*authz.VendorKey, error)
+}
+
+// GRPCService is the part of the vendor service used by the gRPC handler
+type GRPCService interface {
+	CreateVendor(ctx context.Context, req *service.CreateVendorRequest) (*repository.Vendor, error)
+	GetVendor(ctx context.Context, id, entityID string, expand ...string) (*repository.Vendor, error)
+	GetVendorSnapshot(ctx context.Context, id, entityID string, allowed func(section string) bool) (*service.VendorSnapshot, error)
+	GetVendorActivity(ctx context.Context, vendorID, entityID string, q service.ActivityQuery, allowed func(section string) bool) (*service.ActivityPage, error)
+	UpdateVendor(ctx context.Context, req *service.UpdateVendorRequest) (*repository.Vendor, error)
+	UpsertVendorByCode(ctx context.Context, req *service.UpsertVendorRequest) (*repository.Vendor, bool, error)
+	DeleteVendor(ctx context.Context, id, entityID string, dryRun bool, keepChildren *bool) (*service.DeleteReport, error)
+	ListVendors(ctx context.Context, filter repository.VendorFilter, page, pageSize int) (*service.VendorPage, error)
+	ListVendorSummaries(ctx context.Context, filter repository.VendorFilter, page, pageSize int) (*service.VendorSummaryPage, error)
+	ActivateVendor(ctx context.Context, id, entityID, updatedBy string) (*repository.Vendor, error)
+	DeactivateVendor(ctx context.Context, id, entityID, updatedBy string) (*repository.Vendor, error)
+	SuspendVendor(ctx context.Context, id, entityID, updatedBy string) (*repository.Vendor, error)
+	ValidateVendor(ctx context.Context, vendorID, entityID, invoiceCurrency string) (*service.VendorValidation, error)
+	UpdateBalance(ctx context.Context, vendorID, entityID string, amount int64) error
+	TransferVendor(ctx context.Context, req *service.TransferVendorRequest) (*repository.Vendor, error)
+	MatchVendors(ctx context.Context, req *service.MatchVendorsRequest) ([]*service.VendorMatch, error)
+	ListVendorContacts(ctx context.Context, filter repository.ContactFilter, page, pageSize int) (*service.ContactPage, error)
+	GetContactsForCommunication(ctx context.Context, vendorID, entityID, commType string) ([]*repository.VendorContact, error)
+	RecordVendorView(ctx context.Context, userID, entityID, vendorID string)
+
+	ListPaymentTerms(ctx context.Context, onlyActive bool) ([]*repository.PaymentTerm, error)
+	GetPaymentTermByCode(ctx context.Context, code string, onlyActive bool) (*repository.PaymentTerm, error)
+
+	ListVendorChanges(ctx context.Context, entityID string, since int64, limit int) (*service.VendorChanges, error)
+	WatchVendorChanges(
+		ctx context.Context,
+		entityID string,
+		since int64,
+		heartbeat time.Duration,
+		emit func(change *service.VendorChange) error,
+		onHeartbeat func(watermark int64) error,
+	) error
+}
+
+var (
+	_ HTTPService = (*service.VendorService)(nil)
+	_ GRPCService = (*service.VendorService)(nil)
+)
@@ -0,0 +1,327 @@
+// Package identity keeps the vendors service usable while the identity service
+// is slow or down. A Guard bounds and retries identity calls, stops calling
+// identity through a circuit breaker after repeated failures, and caches
+// successful token validations for a short time.
+package identity
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"expvar"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Options configures a Guard
+type Options struct {
+	// CallTimeout bounds every attempt of an identity call
+	CallTimeout time.Duration
+	// MaxRetries is the number of retries of calls failing with Unavailable
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry, doubled on each retry
+	RetryBackoff time.Duration
+	// BreakerThreshold is the number of consecutive failed calls opening the breaker
+	BreakerThreshold int
+	// BreakerCooldown is how long the breaker stays open before a probe call
+	BreakerCooldown time.Duration
+	// CacheTTL is how long successful responses are reused; 0 disables the cache
+	CacheTTL time.Duration
+	// CacheSize bounds the number of cached responses
+	CacheSize int
+}
+
+// Breaker states, as published in vendors_identity_breaker_state
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half_open"
+)
+
+// Metrics served on /debug/vars
+var (
+	callCounts   = expvar.NewMap("vendors_identity_calls")
+	breakerState = expvar.NewString("vendors_identity_breaker_state")
+)
+
+// errBreakerOpen is returned for calls short-circuited by the open breaker
+var errBreakerOpen = status.Error(codes.Unavailable, "identity service unavailable (circuit breaker open)")
+
+// Guard protects calls to the identity service. Install UnaryClientInterceptor
+// on the identity connection and wrap the auth interceptor with WrapAuth.
+type Guard struct {
+	opts Options
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+
+	cacheMu sync.Mutex
+	cache   map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	reply     proto.Message
+	expiresAt time.Time
+}
+
+// NewGuard creates a guard with a closed breaker
+func NewGuard(opts Options) *Guard {
+	breakerState.Set(StateClosed)
+	return &Guard{
+		opts:  opts,
+		state: StateClosed,
+		cache: make(map[string]cacheEntry),
+	}
+}
+
+// State returns the breaker state
+func (g *Guard) State() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.state
+}
+
+// allow reports whether a call may go to identity. Once the cooldown has
+// passed an open breaker lets a single probe call through.
+func (g *Guard) allow() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch g.state {
+	case StateOpen:
+		if time.Since(g.openedAt) < g.opts.BreakerCooldown {
+			return false
+		}
+		g.setState(StateHalfOpen)
+		g.probing = true
+		return true
+	case StateHalfOpen:
+		if g.probing {
+			return false
+		}
+		g.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record updates the breaker with the outcome of a call
+func (g *Guard) record(failed bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.probing = false
+	if !failed {
+		g.failures = 0
+		g.setState(StateClosed)
+		return
+	}
+
+	g.failures++
+	if g.state == StateHalfOpen || g.failures >= g.opts.BreakerThreshold {
+		if g.state != StateOpen {
+			callCounts.Add("breaker_opened", 1)
+		}
+		g.openedAt = time.Now()
+		g.setState(StateOpen)
+	}
+}
+
+func (g *Guard) setState(state string) {
+	if g.state != state {
+		g.state = state
+		breakerState.Set(state)
+	}
+}
+
+// retryAfter is the time until the breaker lets the next probe through
+func (g *Guard) retryAfter() time.Duration {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.state != StateOpen {
+		return g.opts.RetryBackoff
+	}
+	if wait := g.opts.BreakerCooldown - time.Since(g.openedAt); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// isFailure reports whether an identity error means identity is unhealthy.
+// Rejections such as an invalid token are answers, not failures.
+func isFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// UnaryClientInterceptor serves cached responses, short-circuits calls while
+// the breaker is open, and bounds and retries the remaining calls
+func (g *Guard) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		key := g.cacheKey(ctx, method, req)
+		if g.fromCache(key, reply) {
+			callCounts.Add("cache_hit", 1)
+			return nil
+		}
+
+		if !g.allow() {
+			callCounts.Add("short_circuited", 1)
+			markUnavailable(ctx)
+			return errBreakerOpen
+		}
+
+		err := g.invoke(ctx, method, req, reply, cc, invoker, opts...)
+		g.record(isFailure(err))
+		if err != nil {
+			if isFailure(err) {
+				callCounts.Add("failed", 1)
+				markUnavailable(ctx)
+			}
+			return err
+		}
+
+		callCounts.Add("ok", 1)
+		g.store(key, reply)
+		return nil
+	}
+}
+
+// invoke runs a call with a bounded timeout per attempt, retrying with
+// exponential backoff while identity answers Unavailable
+func (g *Guard) invoke(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	backoff := g.opts.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, g.opts.CallTimeout)
+		err := invoker(attemptCtx, method, req, reply, cc, opts...)
+		cancel()
+
+		if status.Code(err) != codes.Unavailable || attempt >= g.opts.MaxRetries {
+			return err
+		}
+
+		callCounts.Add("retried", 1)
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// cacheKey identifies a call by method, request and forwarded credentials.
+// Only a hash is kept, so cached keys never contain tokens.
+func (g *Guard) cacheKey(ctx context.Context, method string, req interface{}) string {
+	if g.opts.CacheTTL <= 0 {
+		return ""
+	}
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return ""
+	}
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return ""
+	}
+
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write(data)
+	if md, ok := metadata.FromOutgoingContext(ctx); ok {
+		for _, value := range md.Get("authorization") {
+			h.Write([]byte(value))
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (g *Guard) fromCache(key string, reply interface{}) bool {
+	if key == "" {
+		return false
+	}
+	msg, ok := reply.(proto.Message)
+	if !ok {
+		return false
+	}
+
+	g.cacheMu.Lock()
+	entry, found := g.cache[key]
+	g.cacheMu.Unlock()
+	if !found || time.Now().After(entry.expiresAt) {
+		return false
+	}
+
+	proto.Merge(msg, entry.reply)
+	return true
+}
+
+func (g *Guard) store(key string, reply interface{}) {
+	msg, ok := reply.(proto.Message)
+	if key == "" || !ok {
+		return
+	}
+
+	now := time.Now()
+	g.cacheMu.Lock()
+	defer g.cacheMu.Unlock()
+
+	if len(g.cache) >= g.opts.CacheSize {
+		for k, entry := range g.cache {
+			if now.After(entry.expiresAt) {
+				delete(g.cache, k)
+			}
+		}
+		if len(g.cache) >= g.opts.CacheSize {
+			g.cache = make(map[string]cacheEntry)
+		}
+	}
+	g.cache[key] = cacheEntry{reply: proto.Clone(msg), expiresAt: now.Add(g.opts.CacheTTL)}
+}
+
+// unavailableKey carries a flag set when an identity call of the request
+// failed because identity is unavailable
+type unavailableKey struct{}
+
+func markUnavailable(ctx context.Context) {
+	if flag, ok := ctx.Value(unavailableKey{}).(*bool); ok {
+		*flag = true
+	}
+}
+
+// WrapAuth wraps an auth interceptor so that requests rejected because
+// identity is unavailable fail with Unauthenticated and a retry-after header
+// (seconds) instead of the auth interceptor's own error
+func (g *Guard) WrapAuth(auth grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		unavailable := new(bool)
+		resp, err := auth(context.WithValue(ctx, unavailableKey{}, unavailable), req, info, handler)
+		if err == nil || !*unavailable {
+			return resp, err
+		}
+
+		seconds := int64((g.retryAfter() + time.Second - 1) / time.Second)
+		if seconds < 1 {
+			seconds = 1
+		}
+		grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.FormatInt(seconds, 10)))
+		return nil, status.Error(codes.Unauthenticated,
+			fmt.Sprintf("identity service unavailable, retry after %ds", seconds))
+	}
+}
@@ -0,0 +1,68 @@
+package identity
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	identitypb "github.com/pesio-ai/be-lib-proto/gen/go/platform"
+)
+
+// User is the display name and email of a user ID, as the identity service
+// knows them
+type User struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name,omitempty"`
+	Email       string `json:"email,omitempty"`
+}
+
+// UserResolver looks up users by ID
+type UserResolver interface {
+	// ResolveUsers returns the users of ids by ID. Unknown IDs are left out.
+	ResolveUsers(ctx context.Context, ids []string) (map[string]*User, error)
+}
+
+var _ UserResolver = Stub{}
+
+// Stub is the default UserResolver, knowing no users
+type Stub struct{}
+
+// ResolveUsers returns no users
+func (Stub) ResolveUsers(ctx context.Context, ids []string) (map[string]*User, error) {
+	return map[string]*User{}, nil
+}
+
+var _ UserResolver = (*GRPCUserResolver)(nil)
+
+// GRPCUserResolver looks users up with the identity service, in one call per
+// batch of IDs
+type GRPCUserResolver struct {
+	client  identitypb.IdentityServiceClient
+	timeout time.Duration
+}
+
+// NewGRPCUserResolver creates a resolver calling client. Every call is bounded
+// by timeout.
+func NewGRPCUserResolver(client identitypb.IdentityServiceClient, timeout time.Duration) *GRPCUserResolver {
+	return &GRPCUserResolver{client: client, timeout: timeout}
+}
+
+// ResolveUsers asks the identity service for the users of ids
+func (r *GRPCUserResolver) ResolveUsers(ctx context.Context, ids []string) (map[string]*User, error) {
+	users := make(map[string]*User, len(ids))
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	resp, err := r.client.BatchGetUsers(ctx, &identitypb.BatchGetUsersRequest{UserIds: ids})
+	if err != nil {
+		return nil, fmt.Errorf("get users from identity service: %w", err)
+	}
+	for _, u := range resp.Users {
+		users[u.Id] = &User{ID: u.Id, DisplayName: u.DisplayName, Email: u.Email}
+	}
+	return users, nil
+}
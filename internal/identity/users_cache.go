@@ -0,0 +1,97 @@
+package identity
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+var _ UserResolver = (*UserCache)(nil)
+
+// UserCache reuses the users of a UserResolver for a while, holding at most
+// size users and evicting the least recently used first. Only the IDs missing
+// from the cache are asked for, in one batch. Errors and unknown IDs are not
+// cached.
+type UserCache struct {
+	resolver UserResolver
+	ttl      time.Duration
+	size     int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+}
+
+type userEntry struct {
+	user      User
+	expiresAt time.Time
+}
+
+// NewUserCache creates a cache over resolver keeping at most size users for ttl
+func NewUserCache(resolver UserResolver, ttl time.Duration, size int) *UserCache {
+	return &UserCache{
+		resolver: resolver,
+		ttl:      ttl,
+		size:     size,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// ResolveUsers returns the cached users of ids, asking the resolver for the
+// others
+func (c *UserCache) ResolveUsers(ctx context.Context, ids []string) (map[string]*User, error) {
+	users := make(map[string]*User, len(ids))
+	var missing []string
+
+	c.mu.Lock()
+	now := time.Now()
+	for _, id := range ids {
+		if elem, ok := c.entries[id]; ok {
+			entry := elem.Value.(*userEntry)
+			if now.Before(entry.expiresAt) {
+				c.order.MoveToFront(elem)
+				user := entry.user
+				users[id] = &user
+				continue
+			}
+		}
+		missing = append(missing, id)
+	}
+	c.mu.Unlock()
+
+	if len(missing) == 0 {
+		return users, nil
+	}
+	resolved, err := c.resolver.ResolveUsers(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, user := range resolved {
+		users[id] = user
+		c.put(*user)
+	}
+	return users, nil
+}
+
+// put stores a user as the most recently used one, evicting the least
+// recently used beyond size. Callers hold c.mu.
+func (c *UserCache) put(user User) {
+	entry := &userEntry{user: user, expiresAt: time.Now().Add(c.ttl)}
+	if elem, ok := c.entries[user.ID]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[user.ID] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*userEntry).user.ID)
+	}
+}
@@ -0,0 +1,123 @@
+// Package leader elects one instance among replicas with a Postgres advisory
+// lock, so that background jobs run on a single instance at a time. The lock
+// is held by a session: when the leader stops or loses its connection,
+// Postgres releases it and a standby takes over.
+package leader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrReleased is the cause of a lease's context once it was released
+var ErrReleased = errors.New("leadership released")
+
+// Elector acquires the leadership lock
+type Elector struct {
+	pool          *pgxpool.Pool
+	lockID        int64
+	checkInterval time.Duration
+}
+
+// New creates an elector for the advisory lock lockID. Leases check their
+// connection every checkInterval.
+func New(pool *pgxpool.Pool, lockID int64, checkInterval time.Duration) *Elector {
+	return &Elector{pool: pool, lockID: lockID, checkInterval: checkInterval}
+}
+
+// TryAcquire takes the leadership if no other instance holds it. It returns a
+// nil lease, and no error, when another instance leads.
+func (e *Elector) TryAcquire(ctx context.Context) (*Lease, error) {
+	conn, err := e.pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("acquire connection: %w", err)
+	}
+
+	var acquired bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, e.lockID).Scan(&acquired); err != nil {
+		conn.Release()
+		return nil, fmt.Errorf("acquire leadership lock: %w", err)
+	}
+	if !acquired {
+		conn.Release()
+		return nil, nil
+	}
+
+	// The lease outlives ctx: only losing the lock or Release ends it
+	leaseCtx, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+	lease := &Lease{
+		conn:   conn,
+		lockID: e.lockID,
+		ctx:    leaseCtx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go lease.hold(e.checkInterval)
+	return lease, nil
+}
+
+// Lease is the leadership of this instance, held until Release or until the
+// lock's connection fails
+type Lease struct {
+	conn   *pgxpool.Conn
+	lockID int64
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	done   chan struct{}
+	lost   bool
+}
+
+// Context returns a context canceled once the leadership ends;
+// context.Cause tells why
+func (l *Lease) Context() context.Context {
+	return l.ctx
+}
+
+// hold checks the lock's connection every interval, ending the lease when it
+// fails: the session, and so the lock, may be gone
+func (l *Lease) hold(interval time.Duration) {
+	defer close(l.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		checkCtx, cancel := context.WithTimeout(l.ctx, interval)
+		_, err := l.conn.Exec(checkCtx, `SELECT 1`)
+		cancel()
+		if err != nil && l.ctx.Err() == nil {
+			l.lost = true
+			l.cancel(fmt.Errorf("leadership lost: %w", err))
+			return
+		}
+	}
+}
+
+// Release gives up the leadership, letting a standby take over
+func (l *Lease) Release() {
+	l.cancel(ErrReleased)
+	<-l.done
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	unlocked := false
+	if !l.lost {
+		_, err := l.conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, l.lockID)
+		unlocked = err == nil
+	}
+	if !unlocked {
+		// The session may still hold the lock; closing it makes sure it is
+		// freed rather than returned to the pool
+		l.conn.Conn().Close(ctx)
+	}
+	l.conn.Release()
+}
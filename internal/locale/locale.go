@@ -0,0 +1,59 @@
+// Package locale validates vendor locales, the BCP 47 language tags (e.g.
+// fr-CA) purchase orders and remittance emails are written in.
+package locale
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Examples are valid locales, quoted when a locale is rejected
+var Examples = []string{"en", "en-US", "fr-CA", "de-CH", "zh-Hant-TW"}
+
+// Parse returns the canonical form of a BCP 47 language tag, e.g. "fr-CA" for
+// "fr_ca". Tags that are malformed, name unknown subtags or have no language
+// are rejected.
+func Parse(s string) (string, error) {
+	tag, err := language.Parse(strings.TrimSpace(s))
+	if err == nil {
+		if base, confidence := tag.Base(); confidence != language.Exact || base.String() == "und" {
+			err = fmt.Errorf("no language")
+		}
+	}
+	if err != nil {
+		return "", fmt.Errorf("%q is not a BCP 47 language tag such as %s", s, strings.Join(Examples, ", "))
+	}
+	return tag.String(), nil
+}
+
+// ForCountry returns the default locale of vendors in a country given by its
+// ISO 3166-1 alpha-2 code: the most likely language spoken there with the
+// country as region, e.g. "fr-FR" for FR and "en-CA" for CA. It returns ""
+// for unknown countries.
+func ForCountry(country string) string {
+	region, err := language.ParseRegion(country)
+	if err != nil {
+		return ""
+	}
+	tag, err := language.Compose(region)
+	if err != nil {
+		return ""
+	}
+	base, confidence := tag.Base()
+	if confidence < language.High || !region.IsCountry() {
+		return ""
+	}
+	tag, err = language.Compose(base, region)
+	if err != nil {
+		return ""
+	}
+	return tag.String()
+}
+
+// Matches reports whether locale is the filter locale or, when the filter is
+// a bare language, any locale of that language
+func Matches(locale, filter string) bool {
+	return locale == filter || strings.HasPrefix(locale, filter+"-")
+}
@@ -0,0 +1,278 @@
+// Package migrate applies the embedded SQL migrations and tracks the schema
+// version in a golang-migrate compatible schema_migrations table.
+package migrate
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lockID is the advisory lock serializing migration runs of all instances
+const lockID = 7394561208
+
+// fileName matches NNN_name.sql, NNN_name.up.sql and NNN_name.down.sql
+var fileName = regexp.MustCompile(`^(\d+)_(.+?)(\.up|\.down)?\.sql$`)
+
+// Migration is one schema version
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// String returns the migration as "NNN_name"
+func (m Migration) String() string {
+	return fmt.Sprintf("%03d_%s", m.Version, m.Name)
+}
+
+// ErrSchemaTooNew is returned by Check when the database was migrated by a
+// newer binary
+var ErrSchemaTooNew = stderrors.New("database schema is newer than this binary supports")
+
+// ErrDirty is returned when the version table is marked dirty, which another
+// migration tool leaves behind after a failed migration
+var ErrDirty = stderrors.New("database schema is dirty; fix it manually and run migrate force")
+
+// Load reads the migrations of fsys ordered by version. Every version needs
+// an up migration; down migrations are optional.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(match[1])
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", entry.Name(), err)
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if match[2] != m.Name {
+			return nil, fmt.Errorf("migration %d has files with different names: %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == ".down" {
+			m.Down = string(data)
+		} else {
+			m.Up = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %s has no up migration", m)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Runner applies migrations to a database
+type Runner struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+}
+
+// NewRunner creates a runner for migrations, as returned by Load
+func NewRunner(pool *pgxpool.Pool, migrations []Migration) *Runner {
+	return &Runner{pool: pool, migrations: migrations}
+}
+
+// Latest returns the newest version known to the binary
+func (r *Runner) Latest() int {
+	if len(r.migrations) == 0 {
+		return 0
+	}
+	return r.migrations[len(r.migrations)-1].Version
+}
+
+// Version returns the schema version of the database, 0 when it was never
+// migrated
+func (r *Runner) Version(ctx context.Context) (version int, dirty bool, err error) {
+	err = r.pool.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	var pgErr *pgconn.PgError
+	switch {
+	case stderrors.Is(err, pgx.ErrNoRows):
+		return 0, false, nil
+	case stderrors.As(err, &pgErr) && pgErr.Code == "42P01": // undefined_table
+		return 0, false, nil
+	case err != nil:
+		return 0, false, fmt.Errorf("read schema version: %w", err)
+	}
+	return version, dirty, nil
+}
+
+// Check fails with ErrSchemaTooNew when the database is ahead of the binary
+// and with ErrDirty when it is marked dirty. It returns the pending migrations.
+func (r *Runner) Check(ctx context.Context) ([]Migration, error) {
+	version, dirty, err := r.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if dirty {
+		return nil, fmt.Errorf("%w (version %d)", ErrDirty, version)
+	}
+	if version > r.Latest() {
+		return nil, fmt.Errorf("%w: database is at version %d, binary at %d", ErrSchemaTooNew, version, r.Latest())
+	}
+	return r.pending(version), nil
+}
+
+func (r *Runner) pending(version int) []Migration {
+	var pending []Migration
+	for _, m := range r.migrations {
+		if m.Version > version {
+			pending = append(pending, m)
+		}
+	}
+	return pending
+}
+
+// Up applies every pending migration, each in its own transaction, and
+// returns the applied migrations. With dryRun it only returns them.
+func (r *Runner) Up(ctx context.Context, dryRun bool) ([]Migration, error) {
+	var applied []Migration
+	err := r.locked(ctx, func(conn *pgxpool.Conn) error {
+		pending, err := r.Check(ctx)
+		if err != nil || dryRun {
+			applied = pending
+			return err
+		}
+
+		for _, m := range pending {
+			if err := apply(ctx, conn, m.Up, m.Version); err != nil {
+				return fmt.Errorf("apply %s: %w", m, err)
+			}
+			applied = append(applied, m)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations and returns the reverted
+// migrations. With dryRun it only returns them.
+func (r *Runner) Down(ctx context.Context, steps int, dryRun bool) ([]Migration, error) {
+	var reverted []Migration
+	err := r.locked(ctx, func(conn *pgxpool.Conn) error {
+		if _, err := r.Check(ctx); err != nil {
+			return err
+		}
+		version, _, err := r.Version(ctx)
+		if err != nil {
+			return err
+		}
+
+		for i := len(r.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			m := r.migrations[i]
+			if m.Version > version {
+				continue
+			}
+			if m.Down == "" {
+				return fmt.Errorf("migration %s has no down migration", m)
+			}
+
+			previous := 0
+			if i > 0 {
+				previous = r.migrations[i-1].Version
+			}
+			if !dryRun {
+				if err := apply(ctx, conn, m.Down, previous); err != nil {
+					return fmt.Errorf("revert %s: %w", m, err)
+				}
+			}
+			reverted = append(reverted, m)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Force sets the schema version without running migrations, e.g. to adopt a
+// database whose schema was applied by hand, and clears the dirty flag
+func (r *Runner) Force(ctx context.Context, version int) error {
+	return r.locked(ctx, func(conn *pgxpool.Conn) error {
+		return setVersion(ctx, conn, version, false)
+	})
+}
+
+// locked runs fn holding the migration advisory lock on one connection
+func (r *Runner) locked(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := r.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockID); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, lockID)
+
+	return fn(conn)
+}
+
+// apply runs sql and records version in one transaction, so a failing
+// migration leaves both the schema and its version untouched
+func apply(ctx context.Context, conn *pgxpool.Conn, sql string, version int) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Without arguments the statements run over the simple protocol, which
+	// allows several statements per file
+	if _, err := tx.Exec(ctx, sql); err != nil {
+		return err
+	}
+	if err := setVersion(ctx, tx, version, false); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// execer is a connection or transaction
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+}
+
+// setVersion records the schema version, creating the version table on first use
+func setVersion(ctx context.Context, q execer, version int, dirty bool) error {
+	if _, err := q.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)`); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	if _, err := q.Exec(ctx, `DELETE FROM schema_migrations`); err != nil {
+		return fmt.Errorf("update schema version: %w", err)
+	}
+	if version == 0 && !dirty {
+		return nil
+	}
+	if _, err := q.Exec(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, $2)`, version, dirty); err != nil {
+		return fmt.Errorf("update schema version: %w", err)
+	}
+	return nil
+}
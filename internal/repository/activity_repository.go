@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// ActivityRange selects a page of one source of a vendor's activity, newest
+// first. Rows are returned when created at or after Since and, unless Before is
+// zero, created before Before or at Before with an ID below BeforeID.
+type ActivityRange struct {
+	Since    time.Time
+	Before   time.Time
+	BeforeID string
+	Limit    int
+}
+
+// before returns the upper bound as a nullable query argument
+func (r ActivityRange) before() *time.Time {
+	if r.Before.IsZero() {
+		return nil
+	}
+	return &r.Before
+}
+
+// Includes reports whether a row created at createdAt with the given ID is in
+// the range
+func (r ActivityRange) Includes(createdAt time.Time, id string) bool {
+	if createdAt.Before(r.Since) {
+		return false
+	}
+	return r.Before.IsZero() || createdAt.Before(r.Before) || (createdAt.Equal(r.Before) && id < r.BeforeID)
+}
+
+// DocumentUpload is the upload of a vendor document
+type DocumentUpload struct {
+	ID           string
+	VendorID     string
+	DocumentType string
+	DocumentName string
+	UploadedBy   *string
+	UploadedAt   time.Time
+}
+
+// ListAuditActivity retrieves audit entries of a vendor in rng, restricted to
+// actions unless empty
+func (r *VendorRepository) ListAuditActivity(ctx context.Context, vendorID, entityID string, actions []string, rng ActivityRange) ([]*AuditEntry, error) {
+	query := `
+		SELECT id, entity_id, vendor_id, action, actor_id, details, created_at
+		FROM vendor_audit_log
+		WHERE entity_id = $1 AND vendor_id = $2 AND created_at >= $3
+		  AND ($4::timestamptz IS NULL OR created_at < $4 OR (created_at = $4 AND id::text COLLATE "C" < $5))
+		  AND (cardinality($6::text[]) = 0 OR action = ANY($6))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $7
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, entityID, vendorID, rng.Since, rng.before(), rng.BeforeID, actions, rng.Limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list audit activity")
+	}
+	defer rows.Close()
+
+	entries := make([]*AuditEntry, 0)
+	for rows.Next() {
+		entry := &AuditEntry{}
+		var details []byte
+		if err := rows.Scan(&entry.ID, &entry.EntityID, &entry.VendorID, &entry.Action, &entry.ActorID, &details, &entry.CreatedAt); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan audit entry")
+		}
+		if err := json.Unmarshal(details, &entry.Details); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to decode audit details")
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// ListBalanceActivity retrieves balance ledger entries of a vendor in rng
+func (r *VendorRepository) ListBalanceActivity(ctx context.Context, vendorID, entityID string, rng ActivityRange) ([]*BalanceTransaction, error) {
+	query := `
+		SELECT id, vendor_id, entity_id, amount, balance_after, kind, created_at
+		FROM vendor_balance_transactions
+		WHERE vendor_id = $1 AND entity_id = $2 AND created_at >= $3
+		  AND ($4::timestamptz IS NULL OR created_at < $4 OR (created_at = $4 AND id::text COLLATE "C" < $5))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $6
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, vendorID, entityID, rng.Since, rng.before(), rng.BeforeID, rng.Limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list balance activity")
+	}
+	defer rows.Close()
+
+	transactions := make([]*BalanceTransaction, 0)
+	for rows.Next() {
+		t := &BalanceTransaction{}
+		if err := rows.Scan(&t.ID, &t.VendorID, &t.EntityID, &t.Amount, &t.BalanceAfter, &t.Kind, &t.CreatedAt); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan balance transaction")
+		}
+		transactions = append(transactions, t)
+	}
+
+	return transactions, nil
+}
+
+// ListDocumentActivity retrieves document uploads of a vendor in rng
+func (r *VendorRepository) ListDocumentActivity(ctx context.Context, vendorID string, rng ActivityRange) ([]*DocumentUpload, error) {
+	query := `
+		SELECT id, vendor_id, document_type, document_name, uploaded_by, uploaded_at
+		FROM vendor_documents
+		WHERE vendor_id = $1 AND uploaded_at >= $2
+		  AND ($3::timestamptz IS NULL OR uploaded_at < $3 OR (uploaded_at = $3 AND id::text COLLATE "C" < $4))
+		ORDER BY uploaded_at DESC, id DESC
+		LIMIT $5
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, vendorID, rng.Since, rng.before(), rng.BeforeID, rng.Limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list document activity")
+	}
+	defer rows.Close()
+
+	uploads := make([]*DocumentUpload, 0)
+	for rows.Next() {
+		u := &DocumentUpload{}
+		if err := rows.Scan(&u.ID, &u.VendorID, &u.DocumentType, &u.DocumentName, &u.UploadedBy, &u.UploadedAt); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan document upload")
+		}
+		uploads = append(uploads, u)
+	}
+
+	return uploads, nil
+}
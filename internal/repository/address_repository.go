@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// VendorRegion is the country and state or province of a vendor
+type VendorRegion struct {
+	ID            string  `json:"id"`
+	EntityID      string  `json:"entity_id"`
+	VendorCode    string  `json:"vendor_code"`
+	Country       string  `json:"country"`
+	StateProvince *string `json:"state_province"`
+}
+
+// ListVendorRegions retrieves the regions of up to limit live vendors with an
+// ID greater than afterID, ordered by ID. An empty entityID lists all entities.
+func (r *VendorRepository) ListVendorRegions(ctx context.Context, entityID, afterID string, limit int) ([]*VendorRegion, error) {
+	query := `
+		SELECT id, entity_id, vendor_code, country, state_province
+		FROM vendors
+		WHERE deleted_at IS NULL
+		  AND (NULLIF($1, '') IS NULL OR entity_id = NULLIF($1, '')::uuid)
+		  AND (NULLIF($2, '') IS NULL OR id > NULLIF($2, '')::uuid)
+		ORDER BY id
+		LIMIT $3
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, entityID, afterID, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list vendor regions")
+	}
+	defer rows.Close()
+
+	regions := make([]*VendorRegion, 0)
+	for rows.Next() {
+		region := &VendorRegion{}
+		if err := rows.Scan(&region.ID, &region.EntityID, &region.VendorCode, &region.Country, &region.StateProvince); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan vendor region")
+		}
+		regions = append(regions, region)
+	}
+
+	return regions, nil
+}
+
+// UpdateVendorRegion sets the country and state or province of a live vendor
+func (r *VendorRepository) UpdateVendorRegion(ctx context.Context, region *VendorRegion) error {
+	query := `
+		UPDATE vendors
+		SET country = $3, state_province = $4, updated_at = NOW()
+		WHERE id = $1 AND entity_id = $2 AND deleted_at IS NULL
+	`
+
+	tag, err := r.q.Exec(ctx, query, region.ID, region.EntityID, region.Country, region.StateProvince)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update vendor region")
+	}
+	if tag.RowsAffected() == 0 {
+		return notFound("vendor", region.ID)
+	}
+
+	return nil
+}
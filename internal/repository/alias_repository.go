@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// VendorAlias is an alternate name of a vendor, such as a trade name invoices
+// arrive under
+type VendorAlias struct {
+	ID        string    `json:"id"`
+	VendorID  string    `json:"vendor_id"`
+	Alias     string    `json:"alias"`
+	CreatedBy *string   `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Names a vendor can be matched on, in order of preference
+const (
+	MatchedOnVendorName      = "vendor_name"
+	MatchedOnLegalName       = "legal_name"
+	MatchedOnDoingBusinessAs = "doing_business_as"
+	MatchedOnAlias           = "alias"
+)
+
+// VendorNameMatch is a vendor found by name, with the name that matched
+type VendorNameMatch struct {
+	Vendor      *Vendor `json:"vendor"`
+	MatchedOn   string  `json:"matched_on"`
+	MatchedName string  `json:"matched_name"`
+}
+
+// NormalizeName returns the form vendor names are matched in: lowercase with
+// runs of whitespace collapsed to one space
+func NormalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// normalizedColumn is NormalizeName in SQL
+func normalizedColumn(column string) string {
+	return `lower(regexp_replace(btrim(` + column + `), '\s+', ' ', 'g'))`
+}
+
+// InsertVendorAlias stores an alias of a vendor
+func (r *VendorRepository) InsertVendorAlias(ctx context.Context, alias *VendorAlias) error {
+	query := `
+		INSERT INTO vendor_aliases (vendor_id, alias, normalized_alias, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	err := r.q.QueryRow(ctx, query, alias.VendorID, alias.Alias, NormalizeName(alias.Alias), alias.CreatedBy).
+		Scan(&alias.ID, &alias.CreatedAt)
+	var pgErr *pgconn.PgError
+	if stderrors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return errors.AlreadyExists("vendor_alias", alias.Alias)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create vendor alias")
+	}
+
+	return nil
+}
+
+// ListVendorAliases retrieves the aliases of a vendor ordered by alias
+func (r *VendorRepository) ListVendorAliases(ctx context.Context, vendorID string) ([]*VendorAlias, error) {
+	query := `
+		SELECT id, vendor_id, alias, created_by, created_at
+		FROM vendor_aliases
+		WHERE vendor_id = $1
+		ORDER BY normalized_alias
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, vendorID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list vendor aliases")
+	}
+	defer rows.Close()
+
+	aliases := make([]*VendorAlias, 0)
+	for rows.Next() {
+		alias := &VendorAlias{}
+		if err := rows.Scan(&alias.ID, &alias.VendorID, &alias.Alias, &alias.CreatedBy, &alias.CreatedAt); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan vendor alias")
+		}
+		aliases = append(aliases, alias)
+	}
+
+	return aliases, nil
+}
+
+// DeleteVendorAlias removes an alias of a vendor and returns it
+func (r *VendorRepository) DeleteVendorAlias(ctx context.Context, id, vendorID string) (*VendorAlias, error) {
+	query := `
+		DELETE FROM vendor_aliases
+		WHERE id = $1 AND vendor_id = $2
+		RETURNING id, vendor_id, alias, created_by, created_at
+	`
+
+	alias := &VendorAlias{}
+	err := r.q.QueryRow(ctx, query, id, vendorID).
+		Scan(&alias.ID, &alias.VendorID, &alias.Alias, &alias.CreatedBy, &alias.CreatedAt)
+	if stderrors.Is(err, pgx.ErrNoRows) {
+		return nil, notFound("vendor_alias", id)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to delete vendor alias")
+	}
+
+	return alias, nil
+}
+
+// MatchVendorsByName retrieves the non-deleted vendors of an entity whose
+// vendor name, legal name, doing-business-as name or an alias equals name once
+// normalized, ordered by vendor name. A vendor matching several ways is
+// returned once, with the first of them in MatchedOn order.
+func (r *VendorRepository) MatchVendorsByName(ctx context.Context, entityID, name string) ([]*VendorNameMatch, error) {
+	query := `
+		SELECT * FROM (
+			SELECT DISTINCT ON (v.id) ` + prefixedVendorColumns("v") + `, m.matched_on, m.matched_name
+			FROM (
+				SELECT id AS vendor_id, 1 AS rank, '` + MatchedOnVendorName + `' AS matched_on, vendor_name AS matched_name
+				FROM vendors WHERE entity_id = $1 AND ` + normalizedColumn("vendor_name") + ` = $2
+				UNION ALL
+				SELECT id, 2, '` + MatchedOnLegalName + `', legal_name
+				FROM vendors WHERE entity_id = $1 AND ` + normalizedColumn("legal_name") + ` = $2
+				UNION ALL
+				SELECT id, 3, '` + MatchedOnDoingBusinessAs + `', doing_business_as
+				FROM vendors WHERE entity_id = $1 AND ` + normalizedColumn("doing_business_as") + ` = $2
+				UNION ALL
+				SELECT vendor_id, 4, '` + MatchedOnAlias + `', alias
+				FROM vendor_aliases WHERE normalized_alias = $2
+			) m
+			JOIN vendors v ON v.id = m.vendor_id
+			WHERE v.entity_id = $1 AND v.deleted_at IS NULL
+			ORDER BY v.id, m.rank
+		) matches
+		ORDER BY vendor_name, id
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, entityID, NormalizeName(name))
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to match vendors by name")
+	}
+	defer rows.Close()
+
+	matches := make([]*VendorNameMatch, 0)
+	for rows.Next() {
+		match := &VendorNameMatch{}
+		vendor, err := scanVendor(rows, &match.MatchedOn, &match.MatchedName)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan vendor name match")
+		}
+		match.Vendor = vendor
+		matches = append(matches, match)
+	}
+
+	return matches, nil
+}
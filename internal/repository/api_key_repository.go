@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// VendorAPIKey is a supplier portal credential scoped to one vendor. Only the
+// hash of its secret is stored.
+type VendorAPIKey struct {
+	ID       string `json:"id"`
+	VendorID string `json:"vendor_id"`
+	EntityID string `json:"entity_id"`
+	Name     string `json:"name"`
+	// KeyPrefix is the start of the secret, shown to tell keys apart
+	KeyPrefix  string     `json:"key_prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  *string    `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	RevokedBy  *string    `json:"revoked_by"`
+}
+
+const vendorAPIKeyColumns = `id, vendor_id, entity_id, name, key_prefix, key_hash, scopes, created_by, created_at, last_used_at, revoked_at, revoked_by`
+
+func scanVendorAPIKey(row pgx.Row) (*VendorAPIKey, error) {
+	key := &VendorAPIKey{}
+	err := row.Scan(
+		&key.ID,
+		&key.VendorID,
+		&key.EntityID,
+		&key.Name,
+		&key.KeyPrefix,
+		&key.KeyHash,
+		&key.Scopes,
+		&key.CreatedBy,
+		&key.CreatedAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+		&key.RevokedBy,
+	)
+	return key, err
+}
+
+// InsertVendorAPIKey stores a new API key
+func (r *VendorRepository) InsertVendorAPIKey(ctx context.Context, key *VendorAPIKey) error {
+	query := `
+		INSERT INTO vendor_api_keys (vendor_id, entity_id, name, key_prefix, key_hash, scopes, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	err := r.q.QueryRow(ctx, query,
+		key.VendorID,
+		key.EntityID,
+		key.Name,
+		key.KeyPrefix,
+		key.KeyHash,
+		key.Scopes,
+		key.CreatedBy,
+	).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create vendor API key")
+	}
+
+	return nil
+}
+
+// ListVendorAPIKeys retrieves the API keys of a vendor, revoked ones included,
+// oldest first
+func (r *VendorRepository) ListVendorAPIKeys(ctx context.Context, vendorID, entityID string) ([]*VendorAPIKey, error) {
+	query := `
+		SELECT ` + vendorAPIKeyColumns + `
+		FROM vendor_api_keys
+		WHERE vendor_id = $1 AND entity_id = $2
+		ORDER BY created_at, id
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, vendorID, entityID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list vendor API keys")
+	}
+	defer rows.Close()
+
+	keys := make([]*VendorAPIKey, 0)
+	for rows.Next() {
+		key, err := scanVendorAPIKey(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan vendor API key")
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// GetVendorAPIKeyByHash retrieves the API key whose secret has hash, revoked
+// or not. It reads the primary so that revocations take effect at once.
+func (r *VendorRepository) GetVendorAPIKeyByHash(ctx context.Context, hash string) (*VendorAPIKey, error) {
+	query := `SELECT ` + vendorAPIKeyColumns + ` FROM vendor_api_keys WHERE key_hash = $1`
+
+	key, err := scanVendorAPIKey(r.q.QueryRow(ctx, query, hash))
+	if err == pgx.ErrNoRows {
+		return nil, notFound("vendor_api_key", "")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get vendor API key")
+	}
+
+	return key, nil
+}
+
+// RevokeVendorAPIKey revokes an API key of a vendor. Revoking a revoked key
+// keeps its original revocation.
+func (r *VendorRepository) RevokeVendorAPIKey(ctx context.Context, id, vendorID, entityID string, revokedBy *string) (*VendorAPIKey, error) {
+	query := `
+		UPDATE vendor_api_keys
+		SET revoked_at = COALESCE(revoked_at, NOW()),
+			revoked_by = CASE WHEN revoked_at IS NULL THEN $4 ELSE revoked_by END
+		WHERE id = $1 AND vendor_id = $2 AND entity_id = $3
+		RETURNING ` + vendorAPIKeyColumns
+
+	key, err := scanVendorAPIKey(r.q.QueryRow(ctx, query, id, vendorID, entityID, revokedBy))
+	if err == pgx.ErrNoRows {
+		return nil, notFound("vendor_api_key", id)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to revoke vendor API key")
+	}
+
+	return key, nil
+}
+
+// TouchVendorAPIKey records the use of an API key. The time is only written
+// when the recorded one is older than a minute, to keep busy keys from
+// writing on every request.
+func (r *VendorRepository) TouchVendorAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	query := `
+		UPDATE vendor_api_keys
+		SET last_used_at = $2
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $2 - INTERVAL '1 minute')
+	`
+
+	if _, err := r.q.Exec(ctx, query, id, usedAt); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to record vendor API key use")
+	}
+	return nil
+}
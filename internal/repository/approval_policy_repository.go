@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// ApprovalPolicy holds the approvals the vendors of an entity need before they
+// are activated. Vendors matching a condition need RequiredApprovals, others
+// one; without conditions every vendor needs RequiredApprovals.
+type ApprovalPolicy struct {
+	EntityID          string `json:"entity_id"`
+	RequiredApprovals int    `json:"required_approvals"`
+	// CreditLimitThreshold matches vendors with a credit limit at or above it, in cents
+	CreditLimitThreshold *int64 `json:"credit_limit_threshold"`
+	// WhenBankDetails matches vendors with bank details
+	WhenBankDetails bool      `json:"when_bank_details"`
+	UpdatedBy       *string   `json:"updated_by,omitempty"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// RequiredFor returns the approvals vendor needs under the policy
+func (p *ApprovalPolicy) RequiredFor(vendor *Vendor) int {
+	if p.RequiredApprovals <= 1 {
+		return 1
+	}
+	if p.CreditLimitThreshold == nil && !p.WhenBankDetails {
+		return p.RequiredApprovals
+	}
+	if p.CreditLimitThreshold != nil && vendor.CreditLimit != nil && *vendor.CreditLimit >= *p.CreditLimitThreshold {
+		return p.RequiredApprovals
+	}
+	if p.WhenBankDetails && (!isBlank(vendor.BankAccountNumber) || !isBlank(vendor.IBAN)) {
+		return p.RequiredApprovals
+	}
+	return 1
+}
+
+// sameRequirements reports whether p and other have the same conditions and
+// approvals, so that every vendor needs the same approvals under both
+func (p *ApprovalPolicy) sameRequirements(other *ApprovalPolicy) bool {
+	thresholdsEqual := (p.CreditLimitThreshold == nil) == (other.CreditLimitThreshold == nil) &&
+		(p.CreditLimitThreshold == nil || *p.CreditLimitThreshold == *other.CreditLimitThreshold)
+	return p.RequiredApprovals == other.RequiredApprovals && thresholdsEqual && p.WhenBankDetails == other.WhenBankDetails
+}
+
+// requiredApprovalsSQL is RequiredFor as an SQL expression over a vendors row,
+// with the policy's required approvals, credit limit threshold and bank
+// details condition in the parameters from $first on
+func requiredApprovalsSQL(first int) string {
+	n, threshold, bank := fmt.Sprintf("$%d::int", first), fmt.Sprintf("$%d::bigint", first+1), fmt.Sprintf("$%d::boolean", first+2)
+	return `CASE
+			WHEN ` + n + ` <= 1 THEN 1
+			WHEN ` + threshold + ` IS NULL AND NOT ` + bank + ` THEN ` + n + `
+			WHEN credit_limit >= ` + threshold + ` THEN ` + n + `
+			WHEN ` + bank + ` AND (btrim(COALESCE(bank_account_number, '')) <> '' OR btrim(COALESCE(iban, '')) <> '') THEN ` + n + `
+			ELSE 1
+		END`
+}
+
+func isBlank(s *string) bool {
+	return s == nil || strings.TrimSpace(*s) == ""
+}
+
+// VendorApproval is an approval given to a pending vendor
+type VendorApproval struct {
+	ID       string `json:"id"`
+	VendorID string `json:"vendor_id"`
+	EntityID string `json:"entity_id"`
+	// ApproverID is nil for approvals by unidentified callers
+	ApproverID *string   `json:"approver_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ApprovalState is the progress of a pending vendor through its approval policy
+type ApprovalState struct {
+	Required  int               `json:"required"`
+	Received  int               `json:"received"`
+	Remaining int               `json:"remaining"`
+	Approvals []*VendorApproval `json:"approvals"`
+}
+
+// GetApprovalPolicy retrieves the approval policy of an entity. Entities
+// without a policy get one requiring a single approval.
+func (r *VendorRepository) GetApprovalPolicy(ctx context.Context, entityID string) (*ApprovalPolicy, error) {
+	query := `
+		SELECT entity_id, required_approvals, credit_limit_threshold, when_bank_details, updated_by, updated_at
+		FROM entity_approval_policies
+		WHERE entity_id = $1
+	`
+
+	policy := &ApprovalPolicy{}
+	err := r.q.QueryRow(ctx, query, entityID).Scan(
+		&policy.EntityID,
+		&policy.RequiredApprovals,
+		&policy.CreditLimitThreshold,
+		&policy.WhenBankDetails,
+		&policy.UpdatedBy,
+		&policy.UpdatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return &ApprovalPolicy{EntityID: entityID, RequiredApprovals: 1}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get approval policy")
+	}
+
+	return policy, nil
+}
+
+// UpsertApprovalPolicy creates or replaces the approval policy of an entity,
+// advancing the change_seq of the vendors pending approval whose required
+// approvals it changes
+func (r *VendorRepository) UpsertApprovalPolicy(ctx context.Context, policy *ApprovalPolicy) error {
+	query := `
+		WITH previous AS (
+			SELECT required_approvals, credit_limit_threshold, when_bank_details
+			FROM entity_approval_policies
+			WHERE entity_id = $1
+		), upserted AS (
+			INSERT INTO entity_approval_policies (entity_id, required_approvals, credit_limit_threshold, when_bank_details, updated_by)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (entity_id) DO UPDATE SET
+				required_approvals = EXCLUDED.required_approvals,
+				credit_limit_threshold = EXCLUDED.credit_limit_threshold,
+				when_bank_details = EXCLUDED.when_bank_details,
+				updated_by = EXCLUDED.updated_by
+			RETURNING updated_at
+		)
+		SELECT u.updated_at, COALESCE(p.required_approvals, 1), p.credit_limit_threshold, COALESCE(p.when_bank_details, false)
+		FROM upserted u
+		LEFT JOIN previous p ON true
+	`
+
+	// Entities without a policy required a single approval
+	previous := &ApprovalPolicy{EntityID: policy.EntityID}
+	err := r.q.QueryRow(ctx, query,
+		policy.EntityID,
+		policy.RequiredApprovals,
+		policy.CreditLimitThreshold,
+		policy.WhenBankDetails,
+		policy.UpdatedBy,
+	).Scan(&policy.UpdatedAt, &previous.RequiredApprovals, &previous.CreditLimitThreshold, &previous.WhenBankDetails)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save approval policy")
+	}
+	if previous.sameRequirements(policy) {
+		return nil
+	}
+
+	// The approvals required are returned with pending vendors
+	_, err = r.q.Exec(ctx, `
+		UPDATE vendors SET updated_at = NOW()
+		WHERE entity_id = $1 AND status = 'pending_approval' AND deleted_at IS NULL
+		  AND `+requiredApprovalsSQL(2)+` <> `+requiredApprovalsSQL(5),
+		policy.EntityID,
+		previous.RequiredApprovals, previous.CreditLimitThreshold, previous.WhenBankDetails,
+		policy.RequiredApprovals, policy.CreditLimitThreshold, policy.WhenBankDetails,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update pending vendors")
+	}
+
+	return nil
+}
+
+// LockVendorApprovals serializes approvals of a vendor until the end of the
+// transaction, so that concurrent approvers see each other's approvals
+func (r *VendorRepository) LockVendorApprovals(ctx context.Context, vendorID string) error {
+	if _, err := r.q.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('vendor_approvals'), hashtext($1::text))`, vendorID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to lock vendor approvals")
+	}
+	return nil
+}
+
+// ListVendorApprovals retrieves the approvals of vendors by vendor ID, oldest first
+func (r *VendorRepository) ListVendorApprovals(ctx context.Context, vendorIDs []string) (map[string][]*VendorApproval, error) {
+	query := `
+		SELECT id, vendor_id, entity_id, approver_id, created_at
+		FROM vendor_approvals
+		WHERE vendor_id = ANY($1)
+		ORDER BY created_at, id
+	`
+
+	approvals := make(map[string][]*VendorApproval, len(vendorIDs))
+	if len(vendorIDs) == 0 {
+		return approvals, nil
+	}
+
+	rows, err := r.q.Query(ctx, query, vendorIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list vendor approvals")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		approval := &VendorApproval{}
+		if err := rows.Scan(&approval.ID, &approval.VendorID, &approval.EntityID, &approval.ApproverID, &approval.CreatedAt); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan vendor approval")
+		}
+		approvals[approval.VendorID] = append(approvals[approval.VendorID], approval)
+	}
+
+	return approvals, nil
+}
+
+// InsertVendorApproval records an approval of a vendor, advancing the
+// vendor's change_seq since the approval progress is returned with it
+func (r *VendorRepository) InsertVendorApproval(ctx context.Context, approval *VendorApproval) error {
+	query := `
+		INSERT INTO vendor_approvals (vendor_id, entity_id, approver_id)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+
+	err := r.q.QueryRow(ctx, query, approval.VendorID, approval.EntityID, approval.ApproverID).
+		Scan(&approval.ID, &approval.CreatedAt)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to record vendor approval")
+	}
+
+	return r.touchVendor(ctx, approval.VendorID)
+}
+
+// DeleteVendorApprovals removes the approvals of a vendor, advancing the
+// vendor's change_seq
+func (r *VendorRepository) DeleteVendorApprovals(ctx context.Context, vendorID string) error {
+	if _, err := r.q.Exec(ctx, `DELETE FROM vendor_approvals WHERE vendor_id = $1`, vendorID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete vendor approvals")
+	}
+	return r.touchVendor(ctx, vendorID)
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// ListPendingApproval retrieves the pending_approval vendors of an entity by
+// age, oldest first unless newestFirst, with their total count
+func (r *VendorRepository) ListPendingApproval(ctx context.Context, entityID string, newestFirst bool, limit, offset int) ([]*Vendor, int64, error) {
+	order := "created_at, id"
+	if newestFirst {
+		order = "created_at DESC, id DESC"
+	}
+
+	query := `
+		SELECT ` + vendorColumns + `
+		FROM vendors
+		WHERE entity_id = $1 AND status = 'pending_approval' AND deleted_at IS NULL
+		ORDER BY ` + order + `
+		LIMIT $2 OFFSET $3
+	`
+
+	q := r.reader(ctx)
+
+	var total int64
+	err := q.QueryRow(ctx, `
+		SELECT COUNT(*) FROM vendors
+		WHERE entity_id = $1 AND status = 'pending_approval' AND deleted_at IS NULL
+	`, entityID).Scan(&total)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count pending vendors")
+	}
+
+	rows, err := q.Query(ctx, query, entityID, limit, offset)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to list pending vendors")
+	}
+	defer rows.Close()
+
+	vendors := make([]*Vendor, 0)
+	for rows.Next() {
+		vendor, err := scanVendor(rows)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan vendor")
+		}
+		vendors = append(vendors, vendor)
+	}
+
+	return vendors, total, nil
+}
+
+// CountPendingApproval counts the pending_approval vendors of an entity, and
+// those among them created before overdueBefore
+func (r *VendorRepository) CountPendingApproval(ctx context.Context, entityID string, overdueBefore time.Time) (total, overdue int64, err error) {
+	query := `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE created_at < $2)
+		FROM vendors
+		WHERE entity_id = $1 AND status = 'pending_approval' AND deleted_at IS NULL
+	`
+
+	if err := r.reader(ctx).QueryRow(ctx, query, entityID, overdueBefore).Scan(&total, &overdue); err != nil {
+		return 0, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count pending vendors")
+	}
+	return total, overdue, nil
+}
+
+// ClaimApprovalSLABreaches records up to limit pending_approval vendors of any
+// entity created before createdBefore whose breach was not recorded yet, and
+// returns them
+func (r *VendorRepository) ClaimApprovalSLABreaches(ctx context.Context, createdBefore time.Time, limit int) ([]*Vendor, error) {
+	query := `
+		WITH claimed AS (
+			INSERT INTO vendor_approval_sla_breaches (vendor_id, entity_id)
+			SELECT v.id, v.entity_id
+			FROM vendors v
+			WHERE v.status = 'pending_approval' AND v.deleted_at IS NULL AND v.created_at < $1
+			  AND NOT EXISTS (SELECT 1 FROM vendor_approval_sla_breaches b WHERE b.vendor_id = v.id)
+			ORDER BY v.created_at
+			LIMIT $2
+			ON CONFLICT (vendor_id) DO NOTHING
+			RETURNING vendor_id
+		)
+		SELECT ` + vendorColumns + `
+		FROM vendors
+		WHERE id IN (SELECT vendor_id FROM claimed)
+		ORDER BY created_at
+	`
+
+	rows, err := r.q.Query(ctx, query, createdBefore, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to claim approval SLA breaches")
+	}
+	defer rows.Close()
+
+	vendors := make([]*Vendor, 0)
+	for rows.Next() {
+		vendor, err := scanVendor(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan vendor")
+		}
+		vendors = append(vendors, vendor)
+	}
+
+	return vendors, nil
+}
+
+// ClearApprovalSLABreach forgets the recorded SLA breach of a vendor, so that
+// it is announced again should the vendor return to the queue
+func (r *VendorRepository) ClearApprovalSLABreach(ctx context.Context, vendorID string) error {
+	if _, err := r.q.Exec(ctx, `DELETE FROM vendor_approval_sla_breaches WHERE vendor_id = $1`, vendorID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to clear approval SLA breach")
+	}
+	return nil
+}
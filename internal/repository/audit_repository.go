@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// AuditEntry is a row of the vendor audit log
+type AuditEntry struct {
+	ID        string                 `json:"id"`
+	EntityID  string                 `json:"entity_id"`
+	VendorID  string                 `json:"vendor_id"`
+	Action    string                 `json:"action"`
+	ActorID   *string                `json:"actor_id,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// VendorEvent is a domain event written to the vendor_events outbox
+type VendorEvent struct {
+	ID        string                 `json:"id"`
+	EntityID  string                 `json:"entity_id"`
+	VendorID  string                 `json:"vendor_id"`
+	EventType string                 `json:"event_type"`
+	Payload   map[string]interface{} `json:"payload,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// InsertAuditEntry appends an entry to the vendor audit log
+func (r *VendorRepository) InsertAuditEntry(ctx context.Context, entry *AuditEntry) error {
+	details, err := json.Marshal(entry.Details)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to encode audit details")
+	}
+
+	query := `
+		INSERT INTO vendor_audit_log (entity_id, vendor_id, action, actor_id, details)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	err = r.q.QueryRow(ctx, query,
+		entry.EntityID,
+		entry.VendorID,
+		entry.Action,
+		entry.ActorID,
+		details,
+	).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to insert audit entry")
+	}
+
+	return nil
+}
+
+// InsertEvent writes an event to the vendor_events outbox
+func (r *VendorRepository) InsertEvent(ctx context.Context, event *VendorEvent) error {
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to encode event payload")
+	}
+
+	query := `
+		INSERT INTO vendor_events (entity_id, vendor_id, event_type, payload)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	err = r.q.QueryRow(ctx, query,
+		event.EntityID,
+		event.VendorID,
+		event.EventType,
+		payload,
+	).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to insert vendor event")
+	}
+
+	return nil
+}
+
+// ListAuditEntries retrieves the audit entries of an entity with the given
+// action created at or after since, newest first
+func (r *VendorRepository) ListAuditEntries(ctx context.Context, entityID, action string, since time.Time, limit int) ([]*AuditEntry, error) {
+	query := `
+		SELECT id, entity_id, vendor_id, action, actor_id, details, created_at
+		FROM vendor_audit_log
+		WHERE entity_id = $1 AND action = $2 AND created_at >= $3
+		ORDER BY created_at DESC, id
+		LIMIT $4
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, entityID, action, since, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list audit entries")
+	}
+	defer rows.Close()
+
+	entries := make([]*AuditEntry, 0)
+	for rows.Next() {
+		entry := &AuditEntry{}
+		var details []byte
+		if err := rows.Scan(&entry.ID, &entry.EntityID, &entry.VendorID, &entry.Action, &entry.ActorID, &details, &entry.CreatedAt); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan audit entry")
+		}
+		if err := json.Unmarshal(details, &entry.Details); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to decode audit details")
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
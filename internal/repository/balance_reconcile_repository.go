@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// Kinds of balance ledger entries
+const (
+	// BalanceKindAdjustment entries moved the balance by their amount
+	BalanceKindAdjustment = "adjustment"
+	// BalanceKindReconciliation entries record current_balance being corrected
+	// to the sum of the ledger; their amount is 0
+	BalanceKindReconciliation = "reconciliation"
+)
+
+// BalanceDiscrepancy is a vendor whose current_balance differs from the sum of
+// its balance ledger
+type BalanceDiscrepancy struct {
+	VendorID       string `json:"vendor_id"`
+	EntityID       string `json:"entity_id"`
+	VendorCode     string `json:"vendor_code"`
+	VendorName     string `json:"vendor_name"`
+	CurrentBalance int64  `json:"current_balance"`
+	LedgerBalance  int64  `json:"ledger_balance"`
+	// Drift is current_balance minus the ledger balance
+	Drift int64 `json:"drift"`
+}
+
+// FindBalanceDiscrepancies compares the current_balance of the live vendors of
+// an entity, or of all entities when entityID is empty, to the sum of their
+// balance ledger, returning the vendors where they differ by entity and code
+func (r *VendorRepository) FindBalanceDiscrepancies(ctx context.Context, entityID string) ([]*BalanceDiscrepancy, error) {
+	query := `
+		SELECT v.id, v.entity_id, v.vendor_code, v.vendor_name, v.current_balance, COALESCE(l.total, 0)
+		FROM vendors v
+		LEFT JOIN (
+			SELECT vendor_id, SUM(amount)::bigint AS total
+			FROM vendor_balance_transactions
+			GROUP BY vendor_id
+		) l ON l.vendor_id = v.id
+		WHERE v.deleted_at IS NULL
+		  AND (NULLIF($1, '') IS NULL OR v.entity_id = NULLIF($1, '')::uuid)
+		  AND v.current_balance <> COALESCE(l.total, 0)
+		ORDER BY v.entity_id, v.vendor_code
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, entityID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find balance discrepancies")
+	}
+	defer rows.Close()
+
+	discrepancies := make([]*BalanceDiscrepancy, 0)
+	for rows.Next() {
+		d := &BalanceDiscrepancy{}
+		if err := rows.Scan(&d.VendorID, &d.EntityID, &d.VendorCode, &d.VendorName, &d.CurrentBalance, &d.LedgerBalance); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan balance discrepancy")
+		}
+		d.Drift = d.CurrentBalance - d.LedgerBalance
+		discrepancies = append(discrepancies, d)
+	}
+
+	return discrepancies, nil
+}
+
+// ReconcileVendorBalance sets the current_balance of a vendor to the sum of its
+// balance ledger and records a reconciliation ledger entry, returning the
+// corrected discrepancy, or nil when the balance already matches. The vendor
+// row is locked before the ledger is summed, so it must run in a transaction.
+func (r *VendorRepository) ReconcileVendorBalance(ctx context.Context, vendorID, entityID string) (*BalanceDiscrepancy, error) {
+	d := &BalanceDiscrepancy{VendorID: vendorID, EntityID: entityID}
+	err := r.q.QueryRow(ctx, `
+		SELECT vendor_code, vendor_name, current_balance
+		FROM vendors
+		WHERE id = $1 AND entity_id = $2 AND deleted_at IS NULL
+		FOR UPDATE
+	`, vendorID, entityID).Scan(&d.VendorCode, &d.VendorName, &d.CurrentBalance)
+	if err == pgx.ErrNoRows {
+		return nil, errors.NotFound("vendor", vendorID)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to lock vendor balance")
+	}
+
+	err = r.q.QueryRow(ctx, `
+		SELECT COALESCE(SUM(amount), 0)::bigint FROM vendor_balance_transactions WHERE vendor_id = $1
+	`, vendorID).Scan(&d.LedgerBalance)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to sum balance ledger")
+	}
+	if d.CurrentBalance == d.LedgerBalance {
+		return nil, nil
+	}
+	d.Drift = d.CurrentBalance - d.LedgerBalance
+
+	query := `
+		WITH updated AS (
+			UPDATE vendors
+			SET current_balance = $3,
+			    updated_at = NOW()
+			WHERE id = $1 AND entity_id = $2
+			RETURNING id, entity_id, current_balance
+		)
+		INSERT INTO vendor_balance_transactions (vendor_id, entity_id, amount, balance_after, kind)
+		SELECT id, entity_id, 0, current_balance, $4 FROM updated
+	`
+	if _, err := r.q.Exec(ctx, query, vendorID, entityID, d.LedgerBalance, BalanceKindReconciliation); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to reconcile vendor balance")
+	}
+
+	return d, nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// Which bank detail of a vendor a last-four lookup matched
+const (
+	BankLast4MatchedAccount = "bank_account_number"
+	BankLast4MatchedIBAN    = "iban"
+)
+
+// BankLast4Match is a vendor whose bank account number or IBAN ends in the
+// looked up digits. It carries the last four digits of the account details,
+// never the full numbers.
+type BankLast4Match struct {
+	VendorID     string  `json:"vendor_id"`
+	VendorCode   string  `json:"vendor_code"`
+	VendorName   string  `json:"vendor_name"`
+	Status       string  `json:"status"`
+	BankName     *string `json:"bank_name,omitempty"`
+	AccountLast4 *string `json:"account_last4,omitempty"`
+	IBANLast4    *string `json:"iban_last4,omitempty"`
+	// MatchedOn lists the bank details that ended in the digits
+	MatchedOn []string `json:"matched_on"`
+}
+
+// BankLast4 returns the last four digits of an account number or IBAN as
+// stored next to it, or nil when it has fewer than four digits
+func BankLast4(account *string) *string {
+	if account == nil {
+		return nil
+	}
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, *account)
+	if len(digits) < 4 {
+		return nil
+	}
+	last4 := digits[len(digits)-4:]
+	return &last4
+}
+
+// FindVendorsByBankLast4 retrieves up to limit non-deleted vendors of an
+// entity whose stored bank account number or IBAN last four digits equal
+// last4, ordered by vendor code
+func (r *VendorRepository) FindVendorsByBankLast4(ctx context.Context, entityID, last4 string, limit int) ([]*BankLast4Match, error) {
+	query := `
+		SELECT id, vendor_code, vendor_name, status, bank_name, bank_account_last4, iban_last4
+		FROM vendors
+		WHERE entity_id = $1 AND deleted_at IS NULL
+		  AND (bank_account_last4 = $2 OR iban_last4 = $2)
+		ORDER BY vendor_code, id
+		LIMIT $3
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, entityID, last4, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find vendors by bank last4")
+	}
+	defer rows.Close()
+
+	matches := make([]*BankLast4Match, 0)
+	for rows.Next() {
+		m := &BankLast4Match{}
+		if err := rows.Scan(&m.VendorID, &m.VendorCode, &m.VendorName, &m.Status,
+			&m.BankName, &m.AccountLast4, &m.IBANLast4); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan bank last4 match")
+		}
+		m.MatchedOn = MatchedBankLast4(m, last4)
+		matches = append(matches, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find vendors by bank last4")
+	}
+
+	return matches, nil
+}
+
+// MatchedBankLast4 lists the bank details of a match that end in last4
+func MatchedBankLast4(m *BankLast4Match, last4 string) []string {
+	matched := make([]string, 0, 2)
+	if m.AccountLast4 != nil && *m.AccountLast4 == last4 {
+		matched = append(matched, BankLast4MatchedAccount)
+	}
+	if m.IBANLast4 != nil && *m.IBANLast4 == last4 {
+		matched = append(matched, BankLast4MatchedIBAN)
+	}
+	return matched
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// BankVerification is the stored bank account verification of a vendor.
+// Fingerprint identifies the bank numbers verified; the verification lapses
+// once they change.
+type BankVerification struct {
+	VendorID    string     `json:"-"`
+	EntityID    string     `json:"-"`
+	Status      string     `json:"status"`
+	Method      string     `json:"verification_method,omitempty"`
+	Reference   *string    `json:"-"`
+	Fingerprint string     `json:"-"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	VerifiedAt  *time.Time `json:"verified_at,omitempty"`
+}
+
+// UpsertBankVerification stores the bank account verification of a vendor. A
+// status, method or verified_at differing from the stored ones advances the
+// vendor's change_seq, since the verification is returned with the vendor; a
+// save changing neither does not.
+func (r *VendorRepository) UpsertBankVerification(ctx context.Context, verification *BankVerification) error {
+	query := `
+		WITH previous AS (
+			SELECT status, method, verified_at FROM vendor_bank_verifications WHERE vendor_id = $1
+		), upserted AS (
+			INSERT INTO vendor_bank_verifications (vendor_id, entity_id, status, method, reference, fingerprint, started_at, verified_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			ON CONFLICT (vendor_id) DO UPDATE SET
+				entity_id = EXCLUDED.entity_id,
+				status = EXCLUDED.status,
+				method = EXCLUDED.method,
+				reference = EXCLUDED.reference,
+				fingerprint = EXCLUDED.fingerprint,
+				started_at = EXCLUDED.started_at,
+				verified_at = EXCLUDED.verified_at,
+				updated_at = NOW()
+		)
+		SELECT NOT EXISTS (
+			SELECT 1 FROM previous
+			WHERE status = $3 AND method = $4 AND verified_at IS NOT DISTINCT FROM $8
+		)
+	`
+
+	var changed bool
+	err := r.q.QueryRow(ctx, query,
+		verification.VendorID,
+		verification.EntityID,
+		verification.Status,
+		verification.Method,
+		verification.Reference,
+		verification.Fingerprint,
+		verification.StartedAt,
+		verification.VerifiedAt,
+	).Scan(&changed)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save bank verification")
+	}
+	if changed {
+		return r.touchVendor(ctx, verification.VendorID)
+	}
+	return nil
+}
+
+// ListBankVerifications retrieves the stored bank account verifications of
+// vendors by vendor ID
+func (r *VendorRepository) ListBankVerifications(ctx context.Context, vendorIDs []string) (map[string]*BankVerification, error) {
+	query := `
+		SELECT vendor_id, entity_id, status, method, reference, fingerprint, started_at, verified_at
+		FROM vendor_bank_verifications
+		WHERE vendor_id = ANY($1)
+	`
+
+	verifications := make(map[string]*BankVerification, len(vendorIDs))
+	if len(vendorIDs) == 0 {
+		return verifications, nil
+	}
+
+	rows, err := r.reader(ctx).Query(ctx, query, vendorIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list bank verifications")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		v := &BankVerification{}
+		if err := rows.Scan(&v.VendorID, &v.EntityID, &v.Status, &v.Method, &v.Reference, &v.Fingerprint, &v.StartedAt, &v.VerifiedAt); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan bank verification")
+		}
+		verifications[v.VendorID] = v
+	}
+
+	return verifications, nil
+}
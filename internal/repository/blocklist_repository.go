@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// BlocklistEntry is a tax ID, and optionally a bank account, that no entity of
+// the organization should onboard, e.g. after a vendor was terminated for
+// fraud. EntityID and VendorID record where it was blocked; they are only
+// shown to admins.
+type BlocklistEntry struct {
+	ID string `json:"id"`
+	// TaxID is in NormalizeIdentifier form
+	TaxID string `json:"tax_id"`
+	// BankAccountHash is HashBankAccount of the blocked account number or IBAN
+	BankAccountHash *string   `json:"bank_account_hash,omitempty"`
+	Reason          string    `json:"reason"`
+	EntityID        *string   `json:"entity_id,omitempty"`
+	VendorID        *string   `json:"vendor_id,omitempty"`
+	CreatedBy       *string   `json:"created_by,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// Stages at which vendors are checked against the blocklist
+const (
+	BlocklistStageCreate  = "create"
+	BlocklistStageApprove = "approve"
+)
+
+// Outcomes of a blocklist match
+const (
+	BlocklistOutcomeBlocked = "blocked"
+	BlocklistOutcomeWarned  = "warned"
+)
+
+// BlocklistMatch records a vendor found on the blocklist. VendorID is nil for
+// creates, which are checked before the vendor exists.
+type BlocklistMatch struct {
+	ID         string    `json:"id"`
+	EntryID    string    `json:"entry_id"`
+	EntityID   string    `json:"entity_id"`
+	VendorID   *string   `json:"vendor_id,omitempty"`
+	VendorCode string    `json:"vendor_code"`
+	Stage      string    `json:"stage"`
+	Outcome    string    `json:"outcome"`
+	ActorID    *string   `json:"actor_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// HashBankAccount returns the form bank accounts are stored in on the
+// blocklist: the hex SHA-256 of the account in NormalizeIdentifier form
+func HashBankAccount(account string) string {
+	sum := sha256.Sum256([]byte(NormalizeIdentifier(account)))
+	return hex.EncodeToString(sum[:])
+}
+
+// InsertBlocklistEntry adds an entry to the blocklist
+func (r *VendorRepository) InsertBlocklistEntry(ctx context.Context, entry *BlocklistEntry) error {
+	query := `
+		INSERT INTO vendor_blocklist (tax_id, bank_account_hash, reason, entity_id, vendor_id, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	err := r.q.QueryRow(ctx, query,
+		entry.TaxID,
+		entry.BankAccountHash,
+		entry.Reason,
+		entry.EntityID,
+		entry.VendorID,
+		entry.CreatedBy,
+	).Scan(&entry.ID, &entry.CreatedAt)
+	var pgErr *pgconn.PgError
+	if stderrors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return errors.AlreadyExists("blocklist_entry", entry.TaxID)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create blocklist entry")
+	}
+
+	return nil
+}
+
+// blocklistColumns is the column list matching scanBlocklistEntry
+const blocklistColumns = `id, tax_id, bank_account_hash, reason, entity_id, vendor_id, created_by, created_at`
+
+func scanBlocklistEntry(row rowScanner) (*BlocklistEntry, error) {
+	entry := &BlocklistEntry{}
+	err := row.Scan(&entry.ID, &entry.TaxID, &entry.BankAccountHash, &entry.Reason,
+		&entry.EntityID, &entry.VendorID, &entry.CreatedBy, &entry.CreatedAt)
+	return entry, err
+}
+
+// ListBlocklistEntries retrieves the blocklist, newest first
+func (r *VendorRepository) ListBlocklistEntries(ctx context.Context) ([]*BlocklistEntry, error) {
+	return r.queryBlocklistEntries(ctx, `SELECT `+blocklistColumns+` FROM vendor_blocklist ORDER BY created_at DESC, id`)
+}
+
+// FindBlocklistEntries retrieves the entries with the tax ID, or with one of
+// the bank account hashes. An empty tax ID matches no entry.
+func (r *VendorRepository) FindBlocklistEntries(ctx context.Context, taxID string, bankAccountHashes []string) ([]*BlocklistEntry, error) {
+	query := `
+		SELECT ` + blocklistColumns + `
+		FROM vendor_blocklist
+		WHERE ($1 <> '' AND tax_id = $1) OR bank_account_hash = ANY($2)
+		ORDER BY created_at, id
+	`
+	return r.queryBlocklistEntries(ctx, query, taxID, bankAccountHashes)
+}
+
+func (r *VendorRepository) queryBlocklistEntries(ctx context.Context, query string, args ...interface{}) ([]*BlocklistEntry, error) {
+	rows, err := r.q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list blocklist entries")
+	}
+	defer rows.Close()
+
+	entries := make([]*BlocklistEntry, 0)
+	for rows.Next() {
+		entry, err := scanBlocklistEntry(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan blocklist entry")
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// DeleteBlocklistEntry removes an entry from the blocklist and returns it
+func (r *VendorRepository) DeleteBlocklistEntry(ctx context.Context, id string) (*BlocklistEntry, error) {
+	entry, err := scanBlocklistEntry(r.q.QueryRow(ctx,
+		`DELETE FROM vendor_blocklist WHERE id = $1 RETURNING `+blocklistColumns, id))
+	if stderrors.Is(err, pgx.ErrNoRows) {
+		return nil, notFound("blocklist_entry", id)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to delete blocklist entry")
+	}
+
+	return entry, nil
+}
+
+// InsertBlocklistMatch records a vendor found on the blocklist
+func (r *VendorRepository) InsertBlocklistMatch(ctx context.Context, match *BlocklistMatch) error {
+	query := `
+		INSERT INTO vendor_blocklist_matches (entry_id, entity_id, vendor_id, vendor_code, stage, outcome, actor_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	err := r.q.QueryRow(ctx, query,
+		match.EntryID,
+		match.EntityID,
+		match.VendorID,
+		match.VendorCode,
+		match.Stage,
+		match.Outcome,
+		match.ActorID,
+	).Scan(&match.ID, &match.CreatedAt)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to record blocklist match")
+	}
+
+	return nil
+}
+
+// ListBlocklistMatches retrieves the most recent blocklist matches, of one
+// entity when entityID is set
+func (r *VendorRepository) ListBlocklistMatches(ctx context.Context, entityID string, limit int) ([]*BlocklistMatch, error) {
+	query := `
+		SELECT id, entry_id, entity_id, vendor_id, vendor_code, stage, outcome, actor_id, created_at
+		FROM vendor_blocklist_matches
+		WHERE $1 = '' OR entity_id::text = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, entityID, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list blocklist matches")
+	}
+	defer rows.Close()
+
+	matches := make([]*BlocklistMatch, 0)
+	for rows.Next() {
+		m := &BlocklistMatch{}
+		if err := rows.Scan(&m.ID, &m.EntryID, &m.EntityID, &m.VendorID, &m.VendorCode,
+			&m.Stage, &m.Outcome, &m.ActorID, &m.CreatedAt); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan blocklist match")
+		}
+		matches = append(matches, m)
+	}
+
+	return matches, nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/pesio-ai/be-lib-common/database"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// VendorChangeChannel is the Postgres notification channel vendor mutations are
+// published on, with "<entity_id>:<change_seq>" payloads
+const VendorChangeChannel = "vendor_changes"
+
+// ChangeListener receives committed vendor changes through Postgres LISTEN/NOTIFY
+type ChangeListener struct {
+	db *database.DB
+}
+
+// NewChangeListener creates a new change listener
+func NewChangeListener(db *database.DB) *ChangeListener {
+	return &ChangeListener{db: db}
+}
+
+// Listen holds a dedicated connection listening on VendorChangeChannel and calls
+// onChange for every notification. onReady is called once the listener is active.
+// It blocks until ctx is cancelled or the connection fails.
+func (l *ChangeListener) Listen(ctx context.Context, onReady func(), onChange func(entityID string, changeSeq int64)) error {
+	pooled, err := l.db.Acquire(ctx)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to acquire listener connection")
+	}
+
+	// Take the connection out of the pool so it is never handed out while listening
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+VendorChangeChannel); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to listen for vendor changes")
+	}
+	onReady()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return errors.Wrap(err, errors.ErrCodeInternal, "failed to wait for vendor change notification")
+		}
+
+		entityID, rawSeq, ok := strings.Cut(notification.Payload, ":")
+		if !ok {
+			continue
+		}
+		changeSeq, err := strconv.ParseInt(rawSeq, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		onChange(entityID, changeSeq)
+	}
+}
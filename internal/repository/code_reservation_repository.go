@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// VendorCodeReservation is a vendor code held for an entity until ExpiresAt.
+// Creating the vendor with the code consumes it.
+type VendorCodeReservation struct {
+	ID         string    `json:"id"`
+	EntityID   string    `json:"entity_id"`
+	VendorCode string    `json:"vendor_code"`
+	ReservedBy *string   `json:"reserved_by,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// InsertVendorCodeReservation reserves a vendor code. An expired reservation
+// of the code is taken over; a live one fails with AlreadyExists.
+func (r *VendorRepository) InsertVendorCodeReservation(ctx context.Context, res *VendorCodeReservation) error {
+	query := `
+		INSERT INTO vendor_code_reservations (entity_id, vendor_code, reserved_by, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (entity_id, vendor_code) DO UPDATE
+		SET reserved_by = EXCLUDED.reserved_by, expires_at = EXCLUDED.expires_at, created_at = NOW()
+		WHERE vendor_code_reservations.expires_at <= NOW()
+		RETURNING id, created_at
+	`
+
+	err := r.q.QueryRow(ctx, query, res.EntityID, res.VendorCode, res.ReservedBy, res.ExpiresAt).
+		Scan(&res.ID, &res.CreatedAt)
+	var pgErr *pgconn.PgError
+	if err == pgx.ErrNoRows || (stderrors.As(err, &pgErr) && pgErr.Code == "23505") {
+		return errors.AlreadyExists("vendor_code_reservation", res.VendorCode)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to reserve vendor code")
+	}
+
+	return nil
+}
+
+// MaxVendorCodeNumber returns the largest number following prefix in the
+// vendor codes of an entity, counting deleted vendors and live reservations,
+// or 0 when there is none
+func (r *VendorRepository) MaxVendorCodeNumber(ctx context.Context, entityID, prefix string) (int64, error) {
+	query := `
+		SELECT COALESCE(MAX(substr(code, length($2) + 1)::BIGINT), 0)
+		FROM (
+			SELECT upper(vendor_code) AS code FROM vendors WHERE entity_id = $1
+			UNION ALL
+			SELECT upper(vendor_code) FROM vendor_code_reservations
+			WHERE entity_id = $1 AND expires_at > NOW()
+		) codes
+		WHERE left(code, length($2)) = $2 AND substr(code, length($2) + 1) ~ '^[0-9]{1,18}$'
+	`
+
+	var number int64
+	if err := r.q.QueryRow(ctx, query, entityID, prefix).Scan(&number); err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to find vendor code number")
+	}
+	return number, nil
+}
+
+// GetVendorCodeReservation retrieves the live reservation of a vendor code and
+// locks it until the transaction ends, or nil when the code is not reserved
+func (r *VendorRepository) GetVendorCodeReservation(ctx context.Context, entityID, code string) (*VendorCodeReservation, error) {
+	query := `
+		SELECT id, entity_id, vendor_code, reserved_by, expires_at, created_at
+		FROM vendor_code_reservations
+		WHERE entity_id = $1 AND vendor_code = $2 AND expires_at > NOW()
+		FOR UPDATE
+	`
+
+	res := &VendorCodeReservation{}
+	err := r.q.QueryRow(ctx, query, entityID, code).
+		Scan(&res.ID, &res.EntityID, &res.VendorCode, &res.ReservedBy, &res.ExpiresAt, &res.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get vendor code reservation")
+	}
+	return res, nil
+}
+
+// DeleteVendorCodeReservation removes a vendor code reservation
+func (r *VendorRepository) DeleteVendorCodeReservation(ctx context.Context, id string) error {
+	if _, err := r.q.Exec(ctx, `DELETE FROM vendor_code_reservations WHERE id = $1`, id); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete vendor code reservation")
+	}
+	return nil
+}
+
+// DeleteExpiredVendorCodeReservations releases the expired vendor code
+// reservations and returns how many were released
+func (r *VendorRepository) DeleteExpiredVendorCodeReservations(ctx context.Context) (int, error) {
+	tag, err := r.q.Exec(ctx, `DELETE FROM vendor_code_reservations WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to release vendor code reservations")
+	}
+	return int(tag.RowsAffected()), nil
+}
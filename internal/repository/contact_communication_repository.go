@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// LockVendorContacts serializes changes to the contacts of a vendor until the
+// end of the transaction, so that concurrent changes see each other's
+// communication recipients
+func (r *VendorRepository) LockVendorContacts(ctx context.Context, vendorID string) error {
+	if _, err := r.q.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('vendor_contacts'), hashtext($1::text))`, vendorID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to lock vendor contacts")
+	}
+	return nil
+}
+
+// DeleteContact removes a contact of a vendor
+func (r *VendorRepository) DeleteContact(ctx context.Context, vendorID, contactID string) error {
+	query := `DELETE FROM vendor_contacts WHERE id = $1 AND vendor_id = $2`
+
+	tag, err := r.q.Exec(ctx, query, contactID, vendorID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete vendor contact")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errors.NotFound("contact", contactID)
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// DuplicateContactEmail is an email address used by several contacts of one
+// vendor, with those contacts oldest first
+type DuplicateContactEmail struct {
+	VendorID   string   `json:"vendor_id"`
+	VendorCode string   `json:"vendor_code"`
+	VendorName string   `json:"vendor_name"`
+	Email      string   `json:"email"`
+	ContactIDs []string `json:"contact_ids"`
+}
+
+// NormalizeContactEmail returns the form contact emails are compared in:
+// trimmed and lowercased
+func NormalizeContactEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// FindContactByEmail retrieves the oldest contact of a vendor with the email
+// in NormalizeContactEmail form, or nil when the vendor has none
+func (r *VendorRepository) FindContactByEmail(ctx context.Context, vendorID, email string) (*VendorContact, error) {
+	query := `
+		SELECT id, vendor_id, contact_type, first_name, last_name, title,
+		       email, phone, mobile, is_primary, receives_purchase_orders, receives_remittance,
+		       receives_statements, notes,
+		       created_at, updated_at
+		FROM vendor_contacts
+		WHERE vendor_id = $1 AND lower(btrim(email)) = $2
+		ORDER BY created_at, id
+		LIMIT 1
+	`
+
+	contact := &VendorContact{}
+	err := r.q.QueryRow(ctx, query, vendorID, NormalizeContactEmail(email)).Scan(
+		&contact.ID,
+		&contact.VendorID,
+		&contact.ContactType,
+		&contact.FirstName,
+		&contact.LastName,
+		&contact.Title,
+		&contact.Email,
+		&contact.Phone,
+		&contact.Mobile,
+		&contact.IsPrimary,
+		&contact.ReceivesPurchaseOrders,
+		&contact.ReceivesRemittance,
+		&contact.ReceivesStatements,
+		&contact.Notes,
+		&contact.CreatedAt,
+		&contact.UpdatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find vendor contact by email")
+	}
+
+	return contact, nil
+}
+
+// UpdateContact overwrites a contact of a vendor
+func (r *VendorRepository) UpdateContact(ctx context.Context, contact *VendorContact) error {
+	query := `
+		UPDATE vendor_contacts
+		SET contact_type = $3::contact_type, first_name = $4, last_name = $5, title = $6,
+		    email = $7, phone = $8, mobile = $9, is_primary = $10, notes = $11,
+		    receives_statements = $12, receives_purchase_orders = $13, receives_remittance = $14,
+		    updated_at = NOW()
+		WHERE id = $1 AND vendor_id = $2
+		RETURNING created_at, updated_at
+	`
+
+	err := r.q.QueryRow(ctx, query,
+		contact.ID,
+		contact.VendorID,
+		contact.ContactType,
+		contact.FirstName,
+		contact.LastName,
+		contact.Title,
+		contact.Email,
+		contact.Phone,
+		contact.Mobile,
+		contact.IsPrimary,
+		contact.Notes,
+		contact.ReceivesStatements,
+		contact.ReceivesPurchaseOrders,
+		contact.ReceivesRemittance,
+	).Scan(&contact.CreatedAt, &contact.UpdatedAt)
+
+	if err == pgx.ErrNoRows {
+		return errors.NotFound("contact", contact.ID)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update vendor contact")
+	}
+
+	return nil
+}
+
+// ListDuplicateContactEmails retrieves the emails used by more than one
+// contact of the same live vendor of an entity, compared in
+// NormalizeContactEmail form, ordered by vendor code and email
+func (r *VendorRepository) ListDuplicateContactEmails(ctx context.Context, entityID string) ([]*DuplicateContactEmail, error) {
+	query := `
+		SELECT v.id, v.vendor_code, v.vendor_name, lower(btrim(c.email)) AS email,
+		       array_agg(c.id::text ORDER BY c.created_at, c.id)
+		FROM vendor_contacts c
+		JOIN vendors v ON v.id = c.vendor_id
+		WHERE v.entity_id = $1 AND v.deleted_at IS NULL AND btrim(coalesce(c.email, '')) <> ''
+		GROUP BY v.id, v.vendor_code, v.vendor_name, lower(btrim(c.email))
+		HAVING COUNT(*) > 1
+		ORDER BY v.vendor_code, email
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, entityID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list duplicate contact emails")
+	}
+	defer rows.Close()
+
+	duplicates := make([]*DuplicateContactEmail, 0)
+	for rows.Next() {
+		d := &DuplicateContactEmail{}
+		if err := rows.Scan(&d.VendorID, &d.VendorCode, &d.VendorName, &d.Email, &d.ContactIDs); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan duplicate contact email")
+		}
+		duplicates = append(duplicates, d)
+	}
+
+	return duplicates, nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// EntityContact is a vendor contact together with the code of its vendor
+type EntityContact struct {
+	VendorCode string `json:"vendor_code"`
+	*VendorContact
+}
+
+// ListEntityContacts retrieves the contacts of all live vendors of an entity,
+// ordered by vendor code
+func (r *VendorRepository) ListEntityContacts(ctx context.Context, entityID string) ([]*EntityContact, error) {
+	query := `
+		SELECT v.vendor_code, c.id, c.vendor_id, c.contact_type, c.first_name, c.last_name, c.title,
+		       c.email, c.phone, c.mobile, c.is_primary, c.receives_purchase_orders, c.receives_remittance,
+		       c.receives_statements, c.notes,
+		       c.created_at, c.updated_at
+		FROM vendor_contacts c
+		JOIN vendors v ON v.id = c.vendor_id
+		WHERE v.entity_id = $1 AND v.deleted_at IS NULL
+		ORDER BY v.vendor_code, c.is_primary DESC, c.first_name, c.last_name
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, entityID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list entity contacts")
+	}
+	defer rows.Close()
+
+	contacts := make([]*EntityContact, 0)
+	for rows.Next() {
+		contact := &EntityContact{VendorContact: &VendorContact{}}
+		err := rows.Scan(
+			&contact.VendorCode,
+			&contact.ID,
+			&contact.VendorID,
+			&contact.ContactType,
+			&contact.FirstName,
+			&contact.LastName,
+			&contact.Title,
+			&contact.Email,
+			&contact.Phone,
+			&contact.Mobile,
+			&contact.IsPrimary,
+			&contact.ReceivesPurchaseOrders,
+			&contact.ReceivesRemittance,
+			&contact.ReceivesStatements,
+			&contact.Notes,
+			&contact.CreatedAt,
+			&contact.UpdatedAt,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan vendor contact")
+		}
+		contacts = append(contacts, contact)
+	}
+
+	return contacts, nil
+}
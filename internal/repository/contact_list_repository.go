@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// ContactFilter selects the contacts of a vendor listed by ListContacts
+type ContactFilter struct {
+	VendorID    string
+	ContactType *string
+	IsPrimary   *bool
+	// Sort is the list order: SortContactName (default), SortContactLastName,
+	// SortContactCreatedAt or SortContactCreatedAtDesc
+	Sort string
+}
+
+// Contact list orders; contacts in the same place are ordered by ID so pages
+// do not overlap
+const (
+	// SortContactName lists primary contacts first, then by first and last name
+	SortContactName          = "name"
+	SortContactLastName      = "last_name"
+	SortContactCreatedAt     = "created_at"
+	SortContactCreatedAtDesc = "-created_at"
+)
+
+// contactOrders are the ORDER BY clauses of the contact list orders
+var contactOrders = map[string]string{
+	SortContactName:          "is_primary DESC, first_name, last_name, id",
+	SortContactLastName:      "last_name, first_name, id",
+	SortContactCreatedAt:     "created_at, id",
+	SortContactCreatedAtDesc: "created_at DESC, id",
+}
+
+// IsValidContactSort reports whether sort is a contact list order
+func IsValidContactSort(sort string) bool {
+	_, ok := contactOrders[sort]
+	return sort == "" || ok
+}
+
+// ListContacts retrieves a page of the contacts of a vendor matching the
+// filter, with the number of matching contacts
+func (r *VendorRepository) ListContacts(ctx context.Context, filter ContactFilter, limit, offset int) ([]*VendorContact, int64, error) {
+	where := "vendor_id = $1"
+	args := []interface{}{filter.VendorID}
+	if filter.ContactType != nil {
+		args = append(args, *filter.ContactType)
+		where += fmt.Sprintf(" AND contact_type = $%d::contact_type", len(args))
+	}
+	if filter.IsPrimary != nil {
+		args = append(args, *filter.IsPrimary)
+		where += fmt.Sprintf(" AND is_primary = $%d", len(args))
+	}
+
+	var total int64
+	if err := r.reader(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM vendor_contacts WHERE `+where, args...).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count vendor contacts")
+	}
+
+	order, ok := contactOrders[filter.Sort]
+	if !ok {
+		order = contactOrders[SortContactName]
+	}
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`
+		SELECT id, vendor_id, contact_type, first_name, last_name, title,
+		       email, phone, mobile, is_primary, receives_purchase_orders, receives_remittance,
+		       receives_statements, notes,
+		       created_at, updated_at
+		FROM vendor_contacts
+		WHERE %s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, where, order, len(args)-1, len(args))
+
+	rows, err := r.reader(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to list vendor contacts")
+	}
+	defer rows.Close()
+
+	contacts := make([]*VendorContact, 0)
+	for rows.Next() {
+		contact := &VendorContact{}
+		err := rows.Scan(
+			&contact.ID,
+			&contact.VendorID,
+			&contact.ContactType,
+			&contact.FirstName,
+			&contact.LastName,
+			&contact.Title,
+			&contact.Email,
+			&contact.Phone,
+			&contact.Mobile,
+			&contact.IsPrimary,
+			&contact.ReceivesPurchaseOrders,
+			&contact.ReceivesRemittance,
+			&contact.ReceivesStatements,
+			&contact.Notes,
+			&contact.CreatedAt,
+			&contact.UpdatedAt,
+		)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan vendor contact")
+		}
+
+		contacts = append(contacts, contact)
+	}
+
+	return contacts, total, nil
+}
@@ -0,0 +1,327 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/country"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// Data quality rules, each naming the data a vendor is missing
+const (
+	DataQualityMissingTaxID       = "missing_tax_id"
+	DataQualityMissingAddress     = "missing_address"
+	DataQualityMissingContact     = "missing_contact"
+	DataQualityMissingW9          = "missing_w9"
+	DataQualityMissingBankDetails = "missing_bank_details"
+	DataQualityInactiveTerm       = "inactive_payment_terms"
+	// DataQualityCountryProfile names vendors not meeting the required,
+	// forbidden fields and formats of their country's profile
+	DataQualityCountryProfile = "country_profile"
+)
+
+// DataQualityRule is a completeness check of vendors. Condition is the check
+// in SQL over the vendor row v, true when the vendor fails it; Fails is the
+// same check in Go for stores without SQL.
+type DataQualityRule struct {
+	Name      string
+	Message   string
+	Condition string
+	Fails     func(v *Vendor, signals *DataQualitySignals) bool
+}
+
+// DataQualitySignals are the facts about a vendor kept outside the vendor row
+// that data quality rules look at
+type DataQualitySignals struct {
+	HasContact bool
+	HasW9      bool
+	// InactivePaymentTerm is set when the vendor's payment term was
+	// deactivated without moving the vendor to another term
+	InactivePaymentTerm bool
+}
+
+// DataQualityRules are the data quality rules in the order reports list them
+var DataQualityRules = []DataQualityRule{
+	{
+		Name:      DataQualityMissingTaxID,
+		Message:   "the vendor has no tax_id",
+		Condition: `btrim(coalesce(v.tax_id, '')) = ''`,
+		Fails: func(v *Vendor, _ *DataQualitySignals) bool {
+			return isBlankString(v.TaxID)
+		},
+	},
+	{
+		Name:      DataQualityMissingAddress,
+		Message:   "the vendor has no address; address_line1 and city are required",
+		Condition: `(btrim(coalesce(v.address_line1, '')) = '' OR btrim(coalesce(v.city, '')) = '')`,
+		Fails: func(v *Vendor, _ *DataQualitySignals) bool {
+			return isBlankString(v.AddressLine1) || isBlankString(v.City)
+		},
+	},
+	{
+		Name:      DataQualityMissingContact,
+		Message:   "the vendor has no contacts",
+		Condition: `NOT EXISTS (SELECT 1 FROM vendor_contacts c WHERE c.vendor_id = v.id)`,
+		Fails: func(_ *Vendor, signals *DataQualitySignals) bool {
+			return !signals.HasContact
+		},
+	},
+	{
+		Name:    DataQualityMissingW9,
+		Message: "the vendor is a 1099 vendor without a W-9 on file",
+		Condition: `(v.is_1099_vendor AND NOT EXISTS (
+			SELECT 1 FROM vendor_documents d
+			WHERE d.vendor_id = v.id AND upper(replace(d.document_type, '-', '')) = 'W9'
+		))`,
+		Fails: func(v *Vendor, signals *DataQualitySignals) bool {
+			return v.Is1099Vendor && !signals.HasW9
+		},
+	},
+	{
+		Name:    DataQualityMissingBankDetails,
+		Message: "the vendor is paid by ACH without a bank account and routing number",
+		Condition: `(v.payment_method = 'ach' AND (
+			btrim(coalesce(v.bank_account_number, '')) = '' OR btrim(coalesce(v.bank_routing_number, '')) = ''
+		))`,
+		Fails: func(v *Vendor, _ *DataQualitySignals) bool {
+			return v.PaymentMethod != nil && *v.PaymentMethod == "ach" &&
+				(isBlankString(v.BankAccountNumber) || isBlankString(v.BankRoutingNumber))
+		},
+	},
+	{
+		Name:    DataQualityInactiveTerm,
+		Message: "the vendor uses a payment term that was deactivated",
+		Condition: `EXISTS (
+			SELECT 1 FROM payment_terms pt
+			WHERE upper(pt.code) = upper(v.payment_terms) AND NOT pt.is_active
+		)`,
+		Fails: func(_ *Vendor, signals *DataQualitySignals) bool {
+			return signals.InactivePaymentTerm
+		},
+	},
+	{
+		Name:      DataQualityCountryProfile,
+		Message:   "the vendor does not meet the required fields and formats of its country",
+		Condition: countryProfileCondition(),
+		Fails: func(v *Vendor, _ *DataQualitySignals) bool {
+			return len(country.Lookup(v.Country).Check(v.StringField)) > 0
+		},
+	},
+}
+
+// countryProfileCondition is the SQL check of every country profile, picking
+// the profile of the vendor's country
+func countryProfileCondition() string {
+	var b strings.Builder
+	b.WriteString("(CASE upper(btrim(v.country))")
+	var fallback string
+	for _, profile := range country.Profiles() {
+		var failures []string
+		for _, field := range profile.Required {
+			failures = append(failures, fmt.Sprintf("btrim(coalesce(v.%s, '')) = ''", field))
+		}
+		for _, field := range profile.Forbidden {
+			failures = append(failures, fmt.Sprintf("btrim(coalesce(v.%s, '')) <> ''", field))
+		}
+		fields := make([]string, 0, len(profile.Formats))
+		for field := range profile.Formats {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			// Formats match the value upper-cased and without spaces, as
+			// country.NormalizeValue does
+			value := fmt.Sprintf("upper(replace(btrim(coalesce(v.%s, '')), ' ', ''))", field)
+			pattern := strings.ReplaceAll(profile.Formats[field].Pattern, "'", "''")
+			failures = append(failures, fmt.Sprintf("(%s <> '' AND %s !~ '%s')", value, value, pattern))
+		}
+
+		condition := "FALSE"
+		if len(failures) > 0 {
+			condition = "(" + strings.Join(failures, " OR ") + ")"
+		}
+		if profile.Country == country.DefaultProfile {
+			fallback = condition
+			continue
+		}
+		fmt.Fprintf(&b, " WHEN '%s' THEN %s", profile.Country, condition)
+	}
+	fmt.Fprintf(&b, " ELSE %s END)", fallback)
+	return b.String()
+}
+
+// DataQualityRuleByName returns the data quality rule with a name
+func DataQualityRuleByName(name string) (DataQualityRule, bool) {
+	for _, rule := range DataQualityRules {
+		if rule.Name == name {
+			return rule, true
+		}
+	}
+	return DataQualityRule{}, false
+}
+
+func isBlankString(s *string) bool {
+	return s == nil || strings.TrimSpace(*s) == ""
+}
+
+// DataQualityOffender is a vendor failing a data quality rule
+type DataQualityOffender struct {
+	ID         string `json:"id"`
+	VendorCode string `json:"vendor_code"`
+	VendorName string `json:"vendor_name"`
+	Status     string `json:"status"`
+}
+
+// DataQualityCheck is the outcome of a data quality rule for a vendor
+type DataQualityCheck struct {
+	Rule    string `json:"rule"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message,omitempty"`
+}
+
+// DataQuality is the completeness breakdown of a vendor: which of the
+// enabled data quality rules it passes
+type DataQuality struct {
+	Complete bool               `json:"complete"`
+	Passed   int                `json:"passed"`
+	Total    int                `json:"total"`
+	Checks   []DataQualityCheck `json:"checks"`
+}
+
+// dataQualityConditions returns the SQL conditions of rules, rejecting
+// unknown rules
+func dataQualityConditions(rules []string) ([]string, error) {
+	conditions := make([]string, len(rules))
+	for i, name := range rules {
+		rule, ok := DataQualityRuleByName(name)
+		if !ok {
+			return nil, errors.InvalidInput("rule", fmt.Sprintf("unknown data quality rule %q", name))
+		}
+		conditions[i] = rule.Condition
+	}
+	return conditions, nil
+}
+
+// CountDataQualityIssues counts the live vendors of an entity failing each of
+// the rules
+func (r *VendorRepository) CountDataQualityIssues(ctx context.Context, entityID string, rules []string) (map[string]int64, error) {
+	counts := make(map[string]int64, len(rules))
+	if len(rules) == 0 {
+		return counts, nil
+	}
+	conditions, err := dataQualityConditions(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, len(conditions))
+	for i, condition := range conditions {
+		columns[i] = "COUNT(*) FILTER (WHERE " + condition + ")"
+	}
+	query := `
+		SELECT ` + strings.Join(columns, ", ") + `
+		FROM vendors v
+		WHERE v.entity_id = $1 AND v.deleted_at IS NULL
+	`
+
+	values := make([]int64, len(rules))
+	dest := make([]interface{}, len(rules))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := r.reader(ctx).QueryRow(ctx, query, entityID).Scan(dest...); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to count data quality issues")
+	}
+	for i, rule := range rules {
+		counts[rule] = values[i]
+	}
+
+	return counts, nil
+}
+
+// ListDataQualityOffenders retrieves a page of the live vendors of an entity
+// failing a rule, ordered by vendor code
+func (r *VendorRepository) ListDataQualityOffenders(ctx context.Context, entityID, rule string, limit, offset int) ([]*DataQualityOffender, error) {
+	conditions, err := dataQualityConditions([]string{rule})
+	if err != nil {
+		return nil, err
+	}
+	query := `
+		SELECT v.id, v.vendor_code, v.vendor_name, v.status
+		FROM vendors v
+		WHERE v.entity_id = $1 AND v.deleted_at IS NULL AND ` + conditions[0] + `
+		ORDER BY v.vendor_code, v.id
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, entityID, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list data quality offenders")
+	}
+	defer rows.Close()
+
+	offenders := make([]*DataQualityOffender, 0)
+	for rows.Next() {
+		o := &DataQualityOffender{}
+		if err := rows.Scan(&o.ID, &o.VendorCode, &o.VendorName, &o.Status); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan data quality offender")
+		}
+		offenders = append(offenders, o)
+	}
+
+	return offenders, nil
+}
+
+// ListFailedDataQualityRules retrieves the rules each of vendors fails, by
+// vendor ID, in the order of rules
+func (r *VendorRepository) ListFailedDataQualityRules(ctx context.Context, vendorIDs, rules []string) (map[string][]string, error) {
+	failed := make(map[string][]string, len(vendorIDs))
+	if len(vendorIDs) == 0 || len(rules) == 0 {
+		return failed, nil
+	}
+	conditions, err := dataQualityConditions(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	// A condition on a NULL column is NULL, which fails nothing
+	columns := make([]string, len(conditions))
+	for i, condition := range conditions {
+		columns[i] = "COALESCE(" + condition + ", FALSE)"
+	}
+	query := `
+		SELECT v.id, ` + strings.Join(columns, ", ") + `
+		FROM vendors v
+		WHERE v.id = ANY($1)
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, vendorIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to evaluate data quality rules")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var vendorID string
+		fails := make([]bool, len(rules))
+		dest := make([]interface{}, len(rules)+1)
+		dest[0] = &vendorID
+		for i := range fails {
+			dest[i+1] = &fails[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan data quality rules")
+		}
+		failed[vendorID] = make([]string, 0)
+		for i, rule := range rules {
+			if fails[i] {
+				failed[vendorID] = append(failed[vendorID], rule)
+			}
+		}
+	}
+
+	return failed, nil
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// DormancyBatch is one keyset batch of the dormancy job: the live vendors of
+// an entity, or of all entities when EntityID is empty, after AfterID by ID.
+// Vendors without activity since Cutoff are flagged dormant, the others
+// unflagged.
+type DormancyBatch struct {
+	EntityID string
+	AfterID  string
+	Cutoff   time.Time
+	Limit    int
+}
+
+// DormancyBatchResult is the outcome of a dormancy batch. LastID is empty when
+// the batch had no vendors.
+type DormancyBatchResult struct {
+	LastID  string
+	Scanned int
+	Dormant int
+	Cleared int
+	// Flagged holds the vendors of the batch that were not dormant before
+	Flagged []*DormancyFlag
+}
+
+// DormancyFlag is a vendor flagged dormant
+type DormancyFlag struct {
+	VendorID       string    `json:"vendor_id"`
+	EntityID       string    `json:"entity_id"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+}
+
+// DormantVendor is a vendor flagged dormant with its last activity
+type DormantVendor struct {
+	Vendor         *Vendor   `json:"vendor"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+	FlaggedAt      time.Time `json:"flagged_at"`
+}
+
+// MarkDormantVendors flags or unflags the vendors of a dormancy batch. The
+// last activity of a vendor is the latest of its last_modified_at and its last
+// balance transaction; updates that only advance its change_seq, such as risk
+// rescores, are no activity.
+func (r *VendorRepository) MarkDormantVendors(ctx context.Context, batch DormancyBatch) (*DormancyBatchResult, error) {
+	query := `
+		WITH batch AS (
+			SELECT id, entity_id, last_modified_at
+			FROM vendors
+			WHERE deleted_at IS NULL
+			  AND (NULLIF($1, '') IS NULL OR entity_id = NULLIF($1, '')::uuid)
+			  AND (NULLIF($2, '') IS NULL OR id > NULLIF($2, '')::uuid)
+			ORDER BY id
+			LIMIT $4
+		),
+		activity AS (
+			SELECT b.id, b.entity_id, GREATEST(b.last_modified_at, MAX(t.created_at)) AS last_activity_at
+			FROM batch b
+			LEFT JOIN vendor_balance_transactions t ON t.vendor_id = b.id
+			GROUP BY b.id, b.entity_id, b.last_modified_at
+		),
+		flagged AS (
+			INSERT INTO vendor_dormancy (vendor_id, entity_id, last_activity_at)
+			SELECT id, entity_id, last_activity_at FROM activity WHERE last_activity_at < $3
+			ON CONFLICT (vendor_id) DO UPDATE
+			SET entity_id = EXCLUDED.entity_id, last_activity_at = EXCLUDED.last_activity_at
+			RETURNING vendor_id, entity_id, last_activity_at, (xmax = 0) AS inserted
+		),
+		cleared AS (
+			DELETE FROM vendor_dormancy d
+			USING activity a
+			WHERE d.vendor_id = a.id AND a.last_activity_at >= $3
+			RETURNING d.vendor_id
+		)
+		SELECT
+			(SELECT id::text FROM batch ORDER BY id DESC LIMIT 1),
+			(SELECT COUNT(*) FROM batch),
+			(SELECT COUNT(*) FROM cleared),
+			f.vendor_id, f.entity_id, f.last_activity_at, f.inserted
+		FROM (SELECT 1) AS one
+		LEFT JOIN flagged f ON TRUE
+	`
+
+	rows, err := r.q.Query(ctx, query, batch.EntityID, batch.AfterID, batch.Cutoff, batch.Limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to mark dormant vendors")
+	}
+	defer rows.Close()
+
+	result := &DormancyBatchResult{Flagged: make([]*DormancyFlag, 0)}
+	for rows.Next() {
+		var (
+			lastID         *string
+			scanned        int
+			cleared        int
+			vendorID       *string
+			entityID       *string
+			lastActivityAt *time.Time
+			inserted       *bool
+		)
+		if err := rows.Scan(&lastID, &scanned, &cleared, &vendorID, &entityID, &lastActivityAt, &inserted); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan dormancy batch")
+		}
+		if lastID != nil {
+			result.LastID = *lastID
+		}
+		result.Scanned, result.Cleared = scanned, cleared
+		if vendorID == nil {
+			continue
+		}
+		result.Dormant++
+		if *inserted {
+			result.Flagged = append(result.Flagged, &DormancyFlag{
+				VendorID:       *vendorID,
+				EntityID:       *entityID,
+				LastActivityAt: *lastActivityAt,
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to mark dormant vendors")
+	}
+
+	return result, nil
+}
+
+// ListDormantVendors retrieves up to limit live vendors of an entity flagged
+// dormant, after afterID by ID
+func (r *VendorRepository) ListDormantVendors(ctx context.Context, entityID, afterID string, limit int) ([]*DormantVendor, error) {
+	query := `
+		SELECT ` + prefixedVendorColumns("v") + `, d.last_activity_at, d.flagged_at
+		FROM vendor_dormancy d
+		JOIN vendors v ON v.id = d.vendor_id
+		WHERE v.entity_id = $1 AND v.deleted_at IS NULL
+		  AND (NULLIF($2, '') IS NULL OR v.id > NULLIF($2, '')::uuid)
+		ORDER BY v.id
+		LIMIT $3
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, entityID, afterID, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list dormant vendors")
+	}
+	defer rows.Close()
+
+	dormant := make([]*DormantVendor, 0)
+	for rows.Next() {
+		d := &DormantVendor{}
+		d.Vendor, err = scanVendor(rows, &d.LastActivityAt, &d.FlaggedAt)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan dormant vendor")
+		}
+		dormant = append(dormant, d)
+	}
+
+	return dormant, nil
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// States of entities announced by the platform. Active entities have none.
+const (
+	EntityStateSuspended = "suspended"
+	EntityStateDeleted   = "deleted"
+)
+
+// EntityLifecycleEntry is a row of the entity lifecycle log: a lifecycle
+// change of an entity or a cleanup, with what it did
+type EntityLifecycleEntry struct {
+	ID       string `json:"id"`
+	EntityID string `json:"entity_id"`
+	Action   string `json:"action"`
+	// EventID is the platform event that caused the change
+	EventID   *string                `json:"event_id,omitempty"`
+	ActorID   *string                `json:"actor_id,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// GetEntityState retrieves the state of an entity, or an empty string when it
+// is active
+func (r *VendorRepository) GetEntityState(ctx context.Context, entityID string) (string, error) {
+	var state string
+	err := r.q.QueryRow(ctx, `SELECT state FROM entity_states WHERE entity_id = $1`, entityID).Scan(&state)
+	if err == pgx.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrap(err, errors.ErrCodeInternal, "failed to get entity state")
+	}
+	return state, nil
+}
+
+// SetEntityState sets the state of an entity; an empty state makes it active
+func (r *VendorRepository) SetEntityState(ctx context.Context, entityID, state string) error {
+	var err error
+	if state == "" {
+		_, err = r.q.Exec(ctx, `DELETE FROM entity_states WHERE entity_id = $1`, entityID)
+	} else {
+		_, err = r.q.Exec(ctx, `
+			INSERT INTO entity_states (entity_id, state)
+			VALUES ($1, $2)
+			ON CONFLICT (entity_id) DO UPDATE SET state = EXCLUDED.state, changed_at = NOW()
+		`, entityID, state)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to set entity state")
+	}
+	return nil
+}
+
+// InsertEntityLifecycleEntry appends an entry to the entity lifecycle log
+func (r *VendorRepository) InsertEntityLifecycleEntry(ctx context.Context, entry *EntityLifecycleEntry) error {
+	details, err := json.Marshal(entry.Details)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to encode lifecycle details")
+	}
+
+	query := `
+		INSERT INTO entity_lifecycle_log (entity_id, action, event_id, actor_id, details)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	err = r.q.QueryRow(ctx, query,
+		entry.EntityID,
+		entry.Action,
+		entry.EventID,
+		entry.ActorID,
+		details,
+	).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to insert lifecycle entry")
+	}
+
+	return nil
+}
+
+// ListEntityLifecycleEntries retrieves the lifecycle log of an entity, newest
+// first
+func (r *VendorRepository) ListEntityLifecycleEntries(ctx context.Context, entityID string, limit int) ([]*EntityLifecycleEntry, error) {
+	query := `
+		SELECT id, entity_id, action, event_id, actor_id, details, created_at
+		FROM entity_lifecycle_log
+		WHERE entity_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, entityID, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list lifecycle entries")
+	}
+	defer rows.Close()
+
+	entries := make([]*EntityLifecycleEntry, 0)
+	for rows.Next() {
+		entry := &EntityLifecycleEntry{}
+		var details []byte
+		if err := rows.Scan(&entry.ID, &entry.EntityID, &entry.Action, &entry.EventID, &entry.ActorID, &details, &entry.CreatedAt); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan lifecycle entry")
+		}
+		if err := json.Unmarshal(details, &entry.Details); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to decode lifecycle details")
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// CancelPendingStatusChanges cancels the pending scheduled status changes of
+// an entity and returns how many were cancelled
+func (r *VendorRepository) CancelPendingStatusChanges(ctx context.Context, entityID string, cancelledBy *string) (int, error) {
+	tag, err := r.q.Exec(ctx, `
+		UPDATE scheduled_status_changes
+		SET state = 'cancelled', cancelled_by = $2, processed_at = NOW()
+		WHERE entity_id = $1 AND state = 'pending'
+	`, entityID, cancelledBy)
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to cancel scheduled status changes")
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// CountPendingStatusChanges counts the pending scheduled status changes of an
+// entity
+func (r *VendorRepository) CountPendingStatusChanges(ctx context.Context, entityID string) (int, error) {
+	var count int
+	err := r.reader(ctx).QueryRow(ctx, `
+		SELECT COUNT(*) FROM scheduled_status_changes WHERE entity_id = $1 AND state = 'pending'
+	`, entityID).Scan(&count)
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count scheduled status changes")
+	}
+	return count, nil
+}
+
+// GetConsumerCursor retrieves the stream position of an event consumer, or an
+// empty string when it has not consumed anything yet
+func (r *VendorRepository) GetConsumerCursor(ctx context.Context, consumer string) (string, error) {
+	var cursor string
+	err := r.q.QueryRow(ctx, `SELECT cursor FROM event_consumer_cursors WHERE consumer = $1`, consumer).Scan(&cursor)
+	if err == pgx.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrap(err, errors.ErrCodeInternal, "failed to get consumer cursor")
+	}
+	return cursor, nil
+}
+
+// SetConsumerCursor stores the stream position of an event consumer
+func (r *VendorRepository) SetConsumerCursor(ctx context.Context, consumer, cursor string) error {
+	_, err := r.q.Exec(ctx, `
+		INSERT INTO event_consumer_cursors (consumer, cursor)
+		VALUES ($1, $2)
+		ON CONFLICT (consumer) DO UPDATE SET cursor = EXCLUDED.cursor, updated_at = NOW()
+	`, consumer, cursor)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to set consumer cursor")
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// EntitySettings holds the per-entity switches of vendor maintenance rules.
+// Nil values fall back to the service defaults.
+type EntitySettings struct {
+	EntityID string `json:"entity_id"`
+	// LockVendorCodeAfterActivation rejects vendor code changes on active
+	// vendors unless made with an admin override
+	LockVendorCodeAfterActivation *bool `json:"lock_vendor_code_after_activation"`
+	// StrictBankValidation rejects vendors with an invalid IBAN or SWIFT code
+	// instead of warning
+	StrictBankValidation *bool `json:"strict_bank_validation"`
+	// RequireBankVerification fails the validation of ACH and wire vendors
+	// whose bank account is not verified instead of warning
+	RequireBankVerification *bool `json:"require_bank_verification"`
+	// Payer1099 is the payer printed on the entity's 1099 forms
+	Payer1099 *Payer1099 `json:"payer_1099"`
+	// OnboardingChecklist is the checklist vendor onboarding is measured
+	// against, in display order
+	OnboardingChecklist []OnboardingChecklistItem `json:"onboarding_checklist"`
+	UpdatedBy           *string                   `json:"updated_by,omitempty"`
+	UpdatedAt           time.Time                 `json:"updated_at"`
+}
+
+// Payer1099 is the payer of 1099 forms: the entity's legal name, TIN and
+// address
+type Payer1099 struct {
+	Name         string `json:"name"`
+	TIN          string `json:"tin"`
+	AddressLine1 string `json:"address_line1"`
+	AddressLine2 string `json:"address_line2,omitempty"`
+	City         string `json:"city"`
+	State        string `json:"state"`
+	PostalCode   string `json:"postal_code"`
+	Phone        string `json:"phone,omitempty"`
+}
+
+// OnboardingChecklistItem is an item of an onboarding checklist. Blocking
+// items must be complete before a vendor is ready to activate; the others
+// only count towards its progress.
+type OnboardingChecklistItem struct {
+	Item     string `json:"item"`
+	Blocking bool   `json:"blocking"`
+}
+
+// GetEntitySettings retrieves the settings of an entity. Entities without
+// settings get an empty record, meaning the defaults apply.
+func (r *VendorRepository) GetEntitySettings(ctx context.Context, entityID string) (*EntitySettings, error) {
+	query := `
+		SELECT entity_id, lock_vendor_code_after_activation, strict_bank_validation, require_bank_verification, payer_1099,
+		       onboarding_checklist, updated_by, updated_at
+		FROM entity_settings
+		WHERE entity_id = $1
+	`
+
+	settings := &EntitySettings{}
+	var payer, checklist []byte
+	err := r.q.QueryRow(ctx, query, entityID).Scan(
+		&settings.EntityID,
+		&settings.LockVendorCodeAfterActivation,
+		&settings.StrictBankValidation,
+		&settings.RequireBankVerification,
+		&payer,
+		&checklist,
+		&settings.UpdatedBy,
+		&settings.UpdatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return &EntitySettings{EntityID: entityID}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get entity settings")
+	}
+	if payer != nil {
+		if err := json.Unmarshal(payer, &settings.Payer1099); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to decode 1099 payer")
+		}
+	}
+	if checklist != nil {
+		if err := json.Unmarshal(checklist, &settings.OnboardingChecklist); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to decode onboarding checklist")
+		}
+	}
+
+	return settings, nil
+}
+
+// UpsertEntitySettings creates or replaces the settings of an entity
+func (r *VendorRepository) UpsertEntitySettings(ctx context.Context, settings *EntitySettings) error {
+	query := `
+		INSERT INTO entity_settings (entity_id, lock_vendor_code_after_activation, strict_bank_validation, require_bank_verification, payer_1099,
+		                             onboarding_checklist, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (entity_id) DO UPDATE SET
+			lock_vendor_code_after_activation = EXCLUDED.lock_vendor_code_after_activation,
+			strict_bank_validation = EXCLUDED.strict_bank_validation,
+			require_bank_verification = EXCLUDED.require_bank_verification,
+			payer_1099 = EXCLUDED.payer_1099,
+			onboarding_checklist = EXCLUDED.onboarding_checklist,
+			updated_by = EXCLUDED.updated_by
+		RETURNING updated_at
+	`
+
+	var payer, checklist []byte
+	if settings.Payer1099 != nil {
+		var err error
+		if payer, err = json.Marshal(settings.Payer1099); err != nil {
+			return errors.Wrap(err, errors.ErrCodeInternal, "failed to encode 1099 payer")
+		}
+	}
+	if settings.OnboardingChecklist != nil {
+		var err error
+		if checklist, err = json.Marshal(settings.OnboardingChecklist); err != nil {
+			return errors.Wrap(err, errors.ErrCodeInternal, "failed to encode onboarding checklist")
+		}
+	}
+
+	err := r.q.QueryRow(ctx, query,
+		settings.EntityID,
+		settings.LockVendorCodeAfterActivation,
+		settings.StrictBankValidation,
+		settings.RequireBankVerification,
+		payer,
+		checklist,
+		settings.UpdatedBy,
+	).Scan(&settings.UpdatedAt)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save entity settings")
+	}
+
+	return nil
+}
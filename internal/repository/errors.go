@@ -0,0 +1,157 @@
+package repository
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// NotFoundError is returned by lookups that matched no row. It wraps the
+// be-lib-common not found error; use IsNotFound to detect it.
+type NotFoundError struct {
+	Resource string
+	ID       string
+	Err      error
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return e.Err
+}
+
+func notFound(resource, id string) error {
+	return &NotFoundError{Resource: resource, ID: id, Err: errors.NotFound(resource, id)}
+}
+
+// IsNotFound reports whether err was caused by a lookup that matched no row
+func IsNotFound(err error) bool {
+	var notFoundErr *NotFoundError
+	return stderrors.As(err, &notFoundErr)
+}
+
+// ExternalRefConflictError is returned when an external ID is mapped to a
+// vendor while another vendor of the entity holds it. It wraps the
+// be-lib-common already exists error.
+type ExternalRefConflictError struct {
+	System     string
+	ExternalID string
+	// VendorID and VendorCode name the vendor already holding the external ID
+	VendorID   string
+	VendorCode string
+	Err        error
+}
+
+func (e *ExternalRefConflictError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExternalRefConflictError) Unwrap() error {
+	return e.Err
+}
+
+// ExternalRefConflict returns the ExternalRefConflictError of mapping
+// system:externalID while owner holds it
+func ExternalRefConflict(system, externalID string, owner *Vendor) error {
+	return &ExternalRefConflictError{
+		System:     system,
+		ExternalID: externalID,
+		VendorID:   owner.ID,
+		VendorCode: owner.VendorCode,
+		Err: errors.AlreadyExists("external_ref",
+			fmt.Sprintf("%s:%s is already mapped to vendor %s (%s)", system, externalID, owner.ID, owner.VendorCode)),
+	}
+}
+
+// pgInvalidTextRepresentation is the SQLSTATE of values Postgres cannot parse
+// as their column type, such as unknown enum values or malformed UUIDs
+const pgInvalidTextRepresentation = "22P02"
+
+// InvalidInputError is returned when Postgres rejects a value of the request
+// that does not parse as its column type. It wraps the be-lib-common invalid
+// input error; repository methods wrap it in turn, so use IsInvalidInput to
+// detect it.
+type InvalidInputError struct {
+	Err error
+}
+
+func (e *InvalidInputError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *InvalidInputError) Unwrap() error {
+	return e.Err
+}
+
+// invalidInput converts the cast failures of a statement into an
+// InvalidInputError and returns other errors unchanged
+func invalidInput(err error) error {
+	var pgErr *pgconn.PgError
+	if !stderrors.As(err, &pgErr) || pgErr.Code != pgInvalidTextRepresentation {
+		return err
+	}
+	return &InvalidInputError{Err: errors.Wrap(err, errors.ErrCodeInvalidInput, pgErr.Message)}
+}
+
+// IsInvalidInput reports whether err was caused by a value Postgres could not
+// parse
+func IsInvalidInput(err error) bool {
+	var invalidErr *InvalidInputError
+	return stderrors.As(err, &invalidErr)
+}
+
+// pgForeignKeyViolation is the SQLSTATE of writes that break a foreign key
+const pgForeignKeyViolation = "23503"
+
+// ForeignKeyError is returned when a write breaks a foreign key: a delete of
+// a row other rows still refer to, or a row referring to one that does not
+// exist. Repository methods wrap it, so use IsForeignKeyViolation to detect
+// it.
+type ForeignKeyError struct {
+	// Table is the table of the referring rows
+	Table      string
+	Constraint string
+	// Referenced is set when the write removes a row that is still
+	// referenced, and clear when it refers to a missing row
+	Referenced bool
+	Err        error
+}
+
+func (e *ForeignKeyError) Error() string {
+	if e.Referenced {
+		return fmt.Sprintf("the record is still referenced by %s records", e.Table)
+	}
+	return fmt.Sprintf("a record referenced by %s does not exist", e.Table)
+}
+
+func (e *ForeignKeyError) Unwrap() error {
+	return e.Err
+}
+
+// statementError converts the errors of a statement the caller can act on,
+// cast failures and foreign key violations, and returns other errors
+// unchanged
+func statementError(err error) error {
+	var pgErr *pgconn.PgError
+	if stderrors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+		return &ForeignKeyError{
+			Table:      pgErr.TableName,
+			Constraint: pgErr.ConstraintName,
+			Referenced: strings.Contains(pgErr.Detail, "is still referenced"),
+			Err:        err,
+		}
+	}
+	return invalidInput(err)
+}
+
+// IsForeignKeyViolation reports whether err was caused by a write breaking a
+// foreign key
+func IsForeignKeyViolation(err error) bool {
+	var fkErr *ForeignKeyError
+	return stderrors.As(err, &fkErr)
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// VendorExportJob is a background CSV export of the vendors of an entity,
+// generated by the worker in parts
+type VendorExportJob struct {
+	ID          string
+	EntityID    string
+	Status      string
+	RowsTotal   int64
+	RowsWritten int64
+	Parts       int
+	// CursorID is the ID of the last vendor written
+	CursorID *string
+	// ExcludeSuspectedTest leaves out the vendors flagged as probable test
+	// vendors
+	ExcludeSuspectedTest bool
+	Attempts             int
+	Error                *string
+	CreatedBy            *string
+	CreatedAt            time.Time
+	StartedAt            *time.Time
+	CompletedAt          *time.Time
+	ExpiresAt            *time.Time
+}
+
+const exportJobColumns = `id, entity_id, status, rows_total, rows_written, parts, cursor_id, exclude_suspected_test, attempts,
+	error, created_by, created_at, started_at, completed_at, expires_at`
+
+// scanExportJob scans a row of exportJobColumns
+func scanExportJob(row rowScanner) (*VendorExportJob, error) {
+	job := &VendorExportJob{}
+	err := row.Scan(&job.ID, &job.EntityID, &job.Status, &job.RowsTotal, &job.RowsWritten, &job.Parts, &job.CursorID,
+		&job.ExcludeSuspectedTest, &job.Attempts, &job.Error, &job.CreatedBy, &job.CreatedAt, &job.StartedAt, &job.CompletedAt, &job.ExpiresAt)
+	return job, err
+}
+
+// CreateExportJob queues a vendor export job, setting its ID, status and
+// creation time
+func (r *VendorRepository) CreateExportJob(ctx context.Context, job *VendorExportJob) error {
+	query := `
+		INSERT INTO vendor_export_jobs (entity_id, rows_total, exclude_suspected_test, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + exportJobColumns
+
+	created, err := scanExportJob(r.q.QueryRow(ctx, query, job.EntityID, job.RowsTotal, job.ExcludeSuspectedTest, job.CreatedBy))
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create export job")
+	}
+	*job = *created
+	return nil
+}
+
+// GetExportJob retrieves a vendor export job of an entity
+func (r *VendorRepository) GetExportJob(ctx context.Context, id, entityID string) (*VendorExportJob, error) {
+	query := `
+		SELECT ` + exportJobColumns + `
+		FROM vendor_export_jobs
+		WHERE id = $1 AND entity_id = $2
+	`
+
+	job, err := scanExportJob(r.reader(ctx).QueryRow(ctx, query, id, entityID))
+	if stderrors.Is(err, pgx.ErrNoRows) {
+		return nil, notFound("export_job", id)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get export job")
+	}
+	return job, nil
+}
+
+// ClaimExportJob marks the oldest pending job, or a running one whose lease
+// expired, as running under a lease until leaseUntil and returns it. It
+// returns nil when no job is waiting.
+func (r *VendorRepository) ClaimExportJob(ctx context.Context, leaseUntil time.Time) (*VendorExportJob, error) {
+	query := `
+		UPDATE vendor_export_jobs
+		SET status = 'running', lease_until = $1, started_at = COALESCE(started_at, NOW())
+		WHERE id = (
+			SELECT id FROM vendor_export_jobs
+			WHERE status = 'pending' OR (status = 'running' AND (lease_until IS NULL OR lease_until < NOW()))
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + exportJobColumns
+
+	job, err := scanExportJob(r.q.QueryRow(ctx, query, leaseUntil))
+	if stderrors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to claim export job")
+	}
+	return job, nil
+}
+
+// CheckpointExportJob records the progress of a running job after a part was
+// written and extends its lease
+func (r *VendorRepository) CheckpointExportJob(ctx context.Context, job *VendorExportJob, leaseUntil time.Time) error {
+	query := `
+		UPDATE vendor_export_jobs
+		SET rows_written = $2, parts = $3, cursor_id = $4, lease_until = $5
+		WHERE id = $1 AND status = 'running'
+	`
+
+	tag, err := r.q.Exec(ctx, query, job.ID, job.RowsWritten, job.Parts, job.CursorID, leaseUntil)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to checkpoint export job")
+	}
+	if tag.RowsAffected() == 0 {
+		return notFound("export_job", job.ID)
+	}
+	return nil
+}
+
+// ReleaseExportJob gives up the lease of a running job so that the next run
+// resumes it. A failure is recorded as a failed attempt.
+func (r *VendorRepository) ReleaseExportJob(ctx context.Context, id string, failure *string) error {
+	query := `
+		UPDATE vendor_export_jobs
+		SET lease_until = NULL,
+		    attempts = attempts + CASE WHEN $2::text IS NULL THEN 0 ELSE 1 END,
+		    error = COALESCE($2, error)
+		WHERE id = $1 AND status = 'running'
+	`
+
+	if _, err := r.q.Exec(ctx, query, id, failure); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to release export job")
+	}
+	return nil
+}
+
+// FinishExportJob records the final status of a job, completed or failed with
+// failure, and when it expires
+func (r *VendorRepository) FinishExportJob(ctx context.Context, id, status string, failure *string, expiresAt time.Time) error {
+	query := `
+		UPDATE vendor_export_jobs
+		SET status = $2, error = $3, lease_until = NULL, completed_at = NOW(), expires_at = $4
+		WHERE id = $1
+	`
+
+	tag, err := r.q.Exec(ctx, query, id, status, failure, expiresAt)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to finish export job")
+	}
+	if tag.RowsAffected() == 0 {
+		return notFound("export_job", id)
+	}
+	return nil
+}
+
+// ListExpiredExportJobs retrieves up to limit finished jobs expired at asOf
+func (r *VendorRepository) ListExpiredExportJobs(ctx context.Context, asOf time.Time, limit int) ([]*VendorExportJob, error) {
+	query := `
+		SELECT ` + exportJobColumns + `
+		FROM vendor_export_jobs
+		WHERE expires_at <= $1
+		ORDER BY expires_at
+		LIMIT $2
+	`
+
+	rows, err := r.q.Query(ctx, query, asOf, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list expired export jobs")
+	}
+	defer rows.Close()
+
+	jobs := make([]*VendorExportJob, 0)
+	for rows.Next() {
+		job, err := scanExportJob(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan export job")
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// DeleteExportJob removes a job
+func (r *VendorRepository) DeleteExportJob(ctx context.Context, id string) error {
+	if _, err := r.q.Exec(ctx, `DELETE FROM vendor_export_jobs WHERE id = $1`, id); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete export job")
+	}
+	return nil
+}
+
+// CountExpiredExportJobs counts the finished jobs expired at asOf
+func (r *VendorRepository) CountExpiredExportJobs(ctx context.Context, asOf time.Time) (int64, error) {
+	var count int64
+	err := r.reader(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM vendor_export_jobs WHERE expires_at <= $1`, asOf).Scan(&count)
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count expired export jobs")
+	}
+	return count, nil
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// VendorExternalRef maps a vendor to its identifier in an external system
+type VendorExternalRef struct {
+	ID         string    `json:"id"`
+	VendorID   string    `json:"vendor_id"`
+	EntityID   string    `json:"entity_id"`
+	System     string    `json:"system"`
+	ExternalID string    `json:"external_id"`
+	CreatedBy  *string   `json:"created_by,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// SetExternalRef creates or replaces the vendor's mapping for ref.System. Mapping an
+// external ID that already belongs to another vendor fails with an
+// ExternalRefConflictError.
+func (r *VendorRepository) SetExternalRef(ctx context.Context, ref *VendorExternalRef) error {
+	query := `
+		INSERT INTO vendor_external_refs (vendor_id, entity_id, system, external_id, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (vendor_id, system) DO UPDATE SET external_id = EXCLUDED.external_id
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.q.QueryRow(ctx, query,
+		ref.VendorID,
+		ref.EntityID,
+		ref.System,
+		ref.ExternalID,
+		ref.CreatedBy,
+	).Scan(&ref.ID, &ref.CreatedAt, &ref.UpdatedAt)
+
+	var pgErr *pgconn.PgError
+	if stderrors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "vendor_external_refs_external_unique" {
+		existing, lookupErr := r.GetByExternalRef(ctx, ref.EntityID, ref.System, ref.ExternalID)
+		if lookupErr != nil {
+			return errors.AlreadyExists("external_ref", fmt.Sprintf("%s:%s", ref.System, ref.ExternalID))
+		}
+		return ExternalRefConflict(ref.System, ref.ExternalID, existing)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to set vendor external ref")
+	}
+
+	return nil
+}
+
+// GetExternalRefs retrieves all external refs of a vendor
+func (r *VendorRepository) GetExternalRefs(ctx context.Context, vendorID, entityID string) ([]*VendorExternalRef, error) {
+	query := `
+		SELECT id, vendor_id, entity_id, system, external_id, created_by, created_at, updated_at
+		FROM vendor_external_refs
+		WHERE vendor_id = $1 AND entity_id = $2
+		ORDER BY system
+	`
+
+	rows, err := r.q.Query(ctx, query, vendorID, entityID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get vendor external refs")
+	}
+	defer rows.Close()
+
+	refs := make([]*VendorExternalRef, 0)
+	for rows.Next() {
+		ref := &VendorExternalRef{}
+		err := rows.Scan(
+			&ref.ID,
+			&ref.VendorID,
+			&ref.EntityID,
+			&ref.System,
+			&ref.ExternalID,
+			&ref.CreatedBy,
+			&ref.CreatedAt,
+			&ref.UpdatedAt,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan vendor external ref")
+		}
+
+		refs = append(refs, ref)
+	}
+
+	return refs, nil
+}
+
+// DeleteExternalRef removes the vendor's mapping for a system
+func (r *VendorRepository) DeleteExternalRef(ctx context.Context, vendorID, entityID, system string) error {
+	query := `DELETE FROM vendor_external_refs WHERE vendor_id = $1 AND entity_id = $2 AND system = $3`
+
+	tag, err := r.q.Exec(ctx, query, vendorID, entityID, system)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete vendor external ref")
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errors.NotFound("external_ref", system)
+	}
+
+	return nil
+}
+
+// DeleteAllExternalRefs removes every external mapping of a vendor
+func (r *VendorRepository) DeleteAllExternalRefs(ctx context.Context, vendorID, entityID string) error {
+	query := `DELETE FROM vendor_external_refs WHERE vendor_id = $1 AND entity_id = $2`
+
+	if _, err := r.q.Exec(ctx, query, vendorID, entityID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete vendor external refs")
+	}
+
+	return nil
+}
+
+// GetByExternalRef retrieves the vendor mapped to an external system ID
+func (r *VendorRepository) GetByExternalRef(ctx context.Context, entityID, system, externalID string) (*Vendor, error) {
+	query := `
+		SELECT ` + prefixedVendorColumns("v") + `
+		FROM vendors v
+		JOIN vendor_external_refs x ON x.vendor_id = v.id
+		WHERE x.entity_id = $1 AND x.system = $2 AND x.external_id = $3
+		  AND v.entity_id = $1 AND v.deleted_at IS NULL
+	`
+
+	vendor, err := scanVendor(r.q.QueryRow(ctx, query, entityID, system, externalID))
+	if err == pgx.ErrNoRows {
+		return nil, errors.NotFound("vendor", fmt.Sprintf("%s:%s", system, externalID))
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get vendor by external ref")
+	}
+
+	return vendor, nil
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// UnknownCountry is the country of the geography report bucket of vendors
+// without a country
+const UnknownCountry = "unknown"
+
+// RegionCount counts the live vendors of an entity in a country and state or
+// province with a currency, and sums their balances
+type RegionCount struct {
+	// Country is UnknownCountry for vendors without one
+	Country string
+	// StateProvince is nil for vendors without one
+	StateProvince *string
+	Currency      string
+	Vendors       int64
+	Balance       int64
+}
+
+// CountVendorsByRegion groups the vendors matching filter by country, state
+// or province and currency, ordered by country and state with vendors
+// without a state last
+func (r *VendorRepository) CountVendorsByRegion(ctx context.Context, filter VendorFilter) ([]*RegionCount, error) {
+	where, args := filter.where()
+	query := `
+		SELECT
+			COALESCE(NULLIF(btrim(country), ''), '` + UnknownCountry + `') AS region_country,
+			NULLIF(btrim(state_province), '') AS region_state,
+			currency,
+			COUNT(*),
+			COALESCE(SUM(current_balance), 0)::bigint
+		FROM vendors
+		` + where + `
+		GROUP BY 1, 2, 3
+		ORDER BY 1, 2 NULLS LAST, 3
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to count vendors by region")
+	}
+	defer rows.Close()
+
+	counts := make([]*RegionCount, 0)
+	for rows.Next() {
+		count := &RegionCount{}
+		if err := rows.Scan(&count.Country, &count.StateProvince, &count.Currency, &count.Vendors, &count.Balance); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan region count")
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to count vendors by region")
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// ImportTemplate is a named column mapping of an entity for recurring CSV
+// imports
+type ImportTemplate struct {
+	ID       string `json:"id"`
+	EntityID string `json:"entity_id"`
+	Name     string `json:"name"`
+	// Columns maps source column headers to import columns
+	Columns map[string]string `json:"columns"`
+	// Defaults are the values of import columns the file leaves out or blank
+	Defaults map[string]string `json:"defaults"`
+	// Transforms are applied to the values of import columns, in order
+	Transforms map[string][]string `json:"transforms"`
+	CreatedBy  *string             `json:"created_by"`
+	UpdatedBy  *string             `json:"updated_by"`
+	CreatedAt  time.Time           `json:"created_at"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+}
+
+const importTemplateColumns = `id, entity_id, name, columns, defaults, transforms, created_by, updated_by, created_at, updated_at`
+
+func scanImportTemplate(row pgx.Row) (*ImportTemplate, error) {
+	t := &ImportTemplate{}
+	var columns, defaults, transforms []byte
+	err := row.Scan(
+		&t.ID,
+		&t.EntityID,
+		&t.Name,
+		&columns,
+		&defaults,
+		&transforms,
+		&t.CreatedBy,
+		&t.UpdatedBy,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(columns, &t.Columns); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(defaults, &t.Defaults); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(transforms, &t.Transforms); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// encodeImportTemplate returns the JSONB columns of a template
+func encodeImportTemplate(t *ImportTemplate) (columns, defaults, transforms []byte, err error) {
+	if columns, err = json.Marshal(t.Columns); err != nil {
+		return nil, nil, nil, err
+	}
+	if defaults, err = json.Marshal(t.Defaults); err != nil {
+		return nil, nil, nil, err
+	}
+	if transforms, err = json.Marshal(t.Transforms); err != nil {
+		return nil, nil, nil, err
+	}
+	return columns, defaults, transforms, nil
+}
+
+// ListImportTemplates retrieves the import templates of an entity ordered by name
+func (r *VendorRepository) ListImportTemplates(ctx context.Context, entityID string) ([]*ImportTemplate, error) {
+	query := `
+		SELECT ` + importTemplateColumns + `
+		FROM import_templates
+		WHERE entity_id = $1
+		ORDER BY name
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, entityID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list import templates")
+	}
+	defer rows.Close()
+
+	templates := make([]*ImportTemplate, 0)
+	for rows.Next() {
+		t, err := scanImportTemplate(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan import template")
+		}
+		templates = append(templates, t)
+	}
+
+	return templates, nil
+}
+
+// GetImportTemplate retrieves an import template of an entity
+func (r *VendorRepository) GetImportTemplate(ctx context.Context, id, entityID string) (*ImportTemplate, error) {
+	query := `SELECT ` + importTemplateColumns + ` FROM import_templates WHERE id = $1 AND entity_id = $2`
+
+	t, err := scanImportTemplate(r.reader(ctx).QueryRow(ctx, query, id, entityID))
+	if stderrors.Is(err, pgx.ErrNoRows) {
+		return nil, notFound("import_template", id)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get import template")
+	}
+
+	return t, nil
+}
+
+// CreateImportTemplate stores a new import template
+func (r *VendorRepository) CreateImportTemplate(ctx context.Context, t *ImportTemplate) error {
+	columns, defaults, transforms, err := encodeImportTemplate(t)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to encode import template")
+	}
+
+	query := `
+		INSERT INTO import_templates (entity_id, name, columns, defaults, transforms, created_by, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = r.q.QueryRow(ctx, query, t.EntityID, t.Name, columns, defaults, transforms, t.CreatedBy).
+		Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+	var pgErr *pgconn.PgError
+	if stderrors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return errors.AlreadyExists("import_template", t.Name)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create import template")
+	}
+
+	t.UpdatedBy = t.CreatedBy
+	return nil
+}
+
+// UpdateImportTemplate replaces the name and mapping of an import template
+func (r *VendorRepository) UpdateImportTemplate(ctx context.Context, t *ImportTemplate) error {
+	columns, defaults, transforms, err := encodeImportTemplate(t)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to encode import template")
+	}
+
+	query := `
+		UPDATE import_templates
+		SET name = $3, columns = $4, defaults = $5, transforms = $6, updated_by = $7
+		WHERE id = $1 AND entity_id = $2
+		RETURNING created_by, created_at, updated_at
+	`
+
+	err = r.q.QueryRow(ctx, query, t.ID, t.EntityID, t.Name, columns, defaults, transforms, t.UpdatedBy).
+		Scan(&t.CreatedBy, &t.CreatedAt, &t.UpdatedAt)
+	var pgErr *pgconn.PgError
+	if stderrors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return errors.AlreadyExists("import_template", t.Name)
+	}
+	if stderrors.Is(err, pgx.ErrNoRows) {
+		return notFound("import_template", t.ID)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update import template")
+	}
+
+	return nil
+}
+
+// DeleteImportTemplate removes an import template of an entity
+func (r *VendorRepository) DeleteImportTemplate(ctx context.Context, id, entityID string) error {
+	tag, err := r.q.Exec(ctx, `DELETE FROM import_templates WHERE id = $1 AND entity_id = $2`, id, entityID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete import template")
+	}
+	if tag.RowsAffected() == 0 {
+		return notFound("import_template", id)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"expvar"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// lookupCacheResults counts GetByID and GetByCode calls served from the
+// request lookup cache ("hit") and from the database ("miss")
+var lookupCacheResults = expvar.NewMap("vendors_lookup_cache")
+
+type lookupCacheKey struct{}
+
+// lookupCache memoizes the vendors read by GetByID and GetByCode within a
+// request. Any write of the request clears it; generation keeps a read that
+// raced a write from storing what it read before the write.
+type lookupCache struct {
+	mu         sync.Mutex
+	generation uint64
+	byID       map[[2]string]*cachedVendor
+	byCode     map[[2]string]*cachedVendor
+}
+
+// cachedVendor is a vendor read by the request, and whether it came from the
+// primary
+type cachedVendor struct {
+	vendor  *Vendor
+	primary bool
+}
+
+// WithLookupCache installs a request-scoped cache of GetByID and GetByCode,
+// so that a request reading the same vendor more than once queries it once
+func WithLookupCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, lookupCacheKey{}, &lookupCache{})
+}
+
+// lookupCacheFrom returns the lookup cache of the request, or nil
+func lookupCacheFrom(ctx context.Context) *lookupCache {
+	cache, _ := ctx.Value(lookupCacheKey{}).(*lookupCache)
+	return cache
+}
+
+// invalidateLookups drops the vendors cached by the request
+func invalidateLookups(ctx context.Context) {
+	cache := lookupCacheFrom(ctx)
+	if cache == nil {
+		return
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.generation++
+	cache.byID, cache.byCode = nil, nil
+}
+
+// cachedLookup reads a vendor by ID or code through the lookup cache of the
+// request. Transactions bypass it, and reads that must see the primary skip
+// vendors cached from the replica.
+func (r *VendorRepository) cachedLookup(ctx context.Context, byCode bool, key, entityID string, load func() (*Vendor, error)) (*Vendor, error) {
+	cache := lookupCacheFrom(ctx)
+	if cache == nil || r.inTx {
+		return load()
+	}
+	primary := r.replica == nil || primaryRequired(ctx)
+
+	cache.mu.Lock()
+	index := cache.byID
+	if byCode {
+		index = cache.byCode
+	}
+	entry, ok := index[[2]string{key, entityID}]
+	generation := cache.generation
+	cache.mu.Unlock()
+	if ok && (entry.primary || !primary) {
+		lookupCacheResults.Add("hit", 1)
+		return cloneVendor(entry.vendor), nil
+	}
+
+	lookupCacheResults.Add("miss", 1)
+	vendor, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.generation == generation {
+		if cache.byID == nil {
+			cache.byID = make(map[[2]string]*cachedVendor)
+			cache.byCode = make(map[[2]string]*cachedVendor)
+		}
+		entry := &cachedVendor{vendor: cloneVendor(vendor), primary: primary}
+		cache.byID[[2]string{vendor.ID, vendor.EntityID}] = entry
+		cache.byCode[[2]string{vendor.VendorCode, vendor.EntityID}] = entry
+	}
+	return vendor, nil
+}
+
+// cloneVendor copies a vendor so that callers may change the copy. Strings
+// behind pointer fields are shared and must not be written through.
+func cloneVendor(v *Vendor) *Vendor {
+	c := *v
+	c.AcceptedCurrencies = slices.Clone(v.AcceptedCurrencies)
+	c.Tags = slices.Clone(v.Tags)
+	c.Contacts = slices.Clone(v.Contacts)
+	c.ExternalRefs = maps.Clone(v.ExternalRefs)
+	c.Warnings = slices.Clone(v.Warnings)
+	c.ValidationWarnings = slices.Clone(v.ValidationWarnings)
+	return &c
+}
@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeVendorDB serves the vendor lookups and deletes of a VendorRepository
+// from memory, counting the lookup statements it runs
+type fakeVendorDB struct {
+	mu      sync.Mutex
+	vendors map[string]*Vendor
+	lookups int
+}
+
+func newFakeVendorDB(vendors ...*Vendor) *fakeVendorDB {
+	db := &fakeVendorDB{vendors: make(map[string]*Vendor)}
+	for _, v := range vendors {
+		db.vendors[v.ID] = v
+	}
+	return db
+}
+
+func (db *fakeVendorDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+// Exec runs the soft delete of Delete
+func (db *fakeVendorDB) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if !strings.Contains(sql, "SET deleted_at = NOW()") {
+		return pgconn.CommandTag{}, errors.New("unexpected statement")
+	}
+	v, ok := db.vendors[args[0].(string)]
+	if !ok || v.EntityID != args[1] {
+		return pgconn.NewCommandTag("UPDATE 0"), nil
+	}
+	delete(db.vendors, v.ID)
+	return pgconn.NewCommandTag("UPDATE 1"), nil
+}
+
+func (db *fakeVendorDB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return nil, errors.New("unexpected statement")
+}
+
+// QueryRow runs the lookups of GetByID and GetByCode
+func (db *fakeVendorDB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.lookups++
+	for _, v := range db.vendors {
+		if v.EntityID != args[1] {
+			continue
+		}
+		if (strings.Contains(sql, "WHERE id = $1") && v.ID == args[0]) ||
+			(strings.Contains(sql, "WHERE vendor_code = $1") && v.VendorCode == args[0]) {
+			return fakeVendorRow{vendor: *v}
+		}
+	}
+	return fakeVendorRow{err: pgx.ErrNoRows}
+}
+
+func (db *fakeVendorDB) lookupCount() int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.lookups
+}
+
+// fakeVendorRow scans the leading ID, entity and code columns of scanVendor
+type fakeVendorRow struct {
+	vendor Vendor
+	err    error
+}
+
+func (r fakeVendorRow) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	*dest[0].(*string) = r.vendor.ID
+	*dest[1].(*string) = r.vendor.EntityID
+	*dest[2].(*string) = r.vendor.VendorCode
+	return nil
+}
+
+func newFakeVendorRepository(db *fakeVendorDB) *VendorRepository {
+	return &VendorRepository{q: timedQuerier{q: db, timeouts: DefaultQueryTimeouts}, timeouts: DefaultQueryTimeouts}
+}
+
+func testVendor() *Vendor {
+	return &Vendor{ID: "v-1", EntityID: "e-1", VendorCode: "NW-001"}
+}
+
+func TestLookupCacheWithinRequest(t *testing.T) {
+	db := newFakeVendorDB(testVendor())
+	repo := newFakeVendorRepository(db)
+	ctx := WithLookupCache(t.Context())
+
+	for range 3 {
+		if _, err := repo.GetByID(ctx, "v-1", "e-1"); err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+	}
+	// The vendor read by ID is cached by code too
+	vendor, err := repo.GetByCode(ctx, "NW-001", "e-1")
+	if err != nil {
+		t.Fatalf("GetByCode() error = %v", err)
+	}
+	if vendor.ID != "v-1" {
+		t.Errorf("GetByCode() = %s, want v-1", vendor.ID)
+	}
+	if got := db.lookupCount(); got != 1 {
+		t.Errorf("lookups = %d, want 1", got)
+	}
+
+	// Other entities and unknown vendors are not served from the cache
+	if _, err := repo.GetByID(ctx, "v-1", "e-2"); err == nil {
+		t.Error("GetByID() of another entity found the cached vendor")
+	}
+	if got := db.lookupCount(); got != 2 {
+		t.Errorf("lookups = %d, want 2", got)
+	}
+}
+
+func TestLookupCacheIsPerRequest(t *testing.T) {
+	db := newFakeVendorDB(testVendor())
+	repo := newFakeVendorRepository(db)
+
+	// Each request installs its own cache, as the LookupCache middleware and
+	// interceptor do, and queries the vendor once
+	for request := 1; request <= 3; request++ {
+		ctx := WithLookupCache(t.Context())
+		for range 2 {
+			if _, err := repo.GetByID(ctx, "v-1", "e-1"); err != nil {
+				t.Fatalf("GetByID() error = %v", err)
+			}
+		}
+		if got := db.lookupCount(); got != request {
+			t.Errorf("lookups after request %d = %d, want %d", request, got, request)
+		}
+	}
+
+	// Without a cache every read queries
+	for range 2 {
+		repo.GetByID(t.Context(), "v-1", "e-1")
+	}
+	if got := db.lookupCount(); got != 5 {
+		t.Errorf("lookups without a cache = %d, want 5", got)
+	}
+}
+
+func TestLookupCacheInvalidatedByWrite(t *testing.T) {
+	db := newFakeVendorDB(testVendor())
+	repo := newFakeVendorRepository(db)
+	ctx := WithLookupCache(t.Context())
+
+	if _, err := repo.GetByCode(ctx, "NW-001", "e-1"); err != nil {
+		t.Fatalf("GetByCode() error = %v", err)
+	}
+	if err := repo.Delete(ctx, "v-1", "e-1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	// The write of the request drops the vendor it cached
+	if _, err := repo.GetByID(ctx, "v-1", "e-1"); err == nil {
+		t.Error("GetByID() after Delete() returned the cached vendor")
+	}
+	if _, err := repo.GetByCode(ctx, "NW-001", "e-1"); err == nil {
+		t.Error("GetByCode() after Delete() returned the cached vendor")
+	}
+	if got := db.lookupCount(); got != 3 {
+		t.Errorf("lookups = %d, want 3", got)
+	}
+}
+
+func TestLookupCacheReturnsCopies(t *testing.T) {
+	vendor := testVendor()
+	vendor.Tags = []string{"critical"}
+	db := newFakeVendorDB(vendor)
+	repo := newFakeVendorRepository(db)
+	ctx := WithLookupCache(t.Context())
+
+	first, err := repo.GetByID(ctx, "v-1", "e-1")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	first.VendorCode = "CHANGED"
+	first.Tags = append(first.Tags, "changed")
+
+	second, err := repo.GetByID(ctx, "v-1", "e-1")
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if second.VendorCode != "NW-001" || len(second.Tags) != 0 {
+		t.Errorf("cached vendor = %s %v, changed by the caller of the first read", second.VendorCode, second.Tags)
+	}
+	second.VendorName = "Changed"
+	if third, _ := repo.GetByID(ctx, "v-1", "e-1"); third.VendorName != "" {
+		t.Errorf("cached vendor name = %q, changed by the caller of a cached read", third.VendorName)
+	}
+}
+
+func TestLookupCacheBypassedInTransaction(t *testing.T) {
+	db := newFakeVendorDB(testVendor())
+	repo := newFakeVendorRepository(db)
+	repo.inTx = true
+	ctx := WithLookupCache(t.Context())
+
+	for range 2 {
+		if _, err := repo.GetByID(ctx, "v-1", "e-1"); err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+	}
+	if got := db.lookupCount(); got != 2 {
+		t.Errorf("lookups in a transaction = %d, want 2", got)
+	}
+}
+
+func TestLookupCacheReplicaReads(t *testing.T) {
+	primary := newFakeVendorDB(testVendor())
+	replica := newFakeVendorDB(testVendor())
+	repo := newFakeVendorRepository(primary)
+	repo.replica = timedQuerier{q: replica, timeouts: DefaultQueryTimeouts, target: ReadConsistencyReplica}
+	repo.replicaHealthy = &atomic.Bool{}
+	repo.replicaHealthy.Store(true)
+	ctx := WithLookupCache(WithReadConsistency(t.Context(), false))
+
+	repo.GetByID(ctx, "v-1", "e-1")
+	repo.GetByID(ctx, "v-1", "e-1")
+	if primary.lookupCount() != 0 || replica.lookupCount() != 1 {
+		t.Fatalf("lookups = %d primary, %d replica, want 0 and 1", primary.lookupCount(), replica.lookupCount())
+	}
+
+	// Once the request needs the primary, the replica's copy is not reused,
+	// but the primary's is
+	UsePrimary(ctx)
+	repo.GetByID(ctx, "v-1", "e-1")
+	repo.GetByCode(ctx, "NW-001", "e-1")
+	if primary.lookupCount() != 1 || replica.lookupCount() != 1 {
+		t.Errorf("lookups = %d primary, %d replica, want 1 and 1", primary.lookupCount(), replica.lookupCount())
+	}
+}
@@ -0,0 +1,3838 @@
+// Package memory implements repository.Store in memory, for unit tests and for
+// running the service locally without Postgres (VENDORS_STORAGE=memory). Data
+// is lost on restart.
+package memory
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/locale"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// Store is an in-memory repository.Store. Transactions hold an exclusive lock
+// and work on a copy of the data that replaces it on commit.
+type Store struct {
+	mu   *sync.Mutex
+	data *state
+	inTx bool
+}
+
+var _ repository.Store = (*Store)(nil)
+
+// state is the data of a Store
+type state struct {
+	seq          int64
+	vendors      map[string]repository.Vendor
+	contacts     map[string]repository.VendorContact
+	externalRefs map[string]repository.VendorExternalRef
+	tombstones   []tombstone
+	ledger       []ledgerEntry
+	auditLog     []repository.AuditEntry
+	events       []repository.VendorEvent
+	retention    map[string]repository.RetentionSettings
+	validation   map[string]repository.ValidationSettings
+	settings     map[string]repository.EntitySettings
+	paymentTerms []repository.PaymentTerm
+	// vendorTypes holds the vendor type registries by entity; "" holds the defaults
+	vendorTypes map[string][]repository.VendorType
+	// approvalBreaches holds the recorded approval SLA breaches by vendor ID
+	approvalBreaches map[string]time.Time
+	approvalPolicies map[string]repository.ApprovalPolicy
+	approvals        []repository.VendorApproval
+	// riskWeights holds the risk rule weights by entity; "" holds the defaults
+	riskWeights map[string]map[string]int
+	riskScores  map[string]repository.RiskScore
+	tinMatches  map[string]repository.TINMatch
+	// bankVerifications holds the bank account verifications by vendor ID
+	bankVerifications map[string]repository.BankVerification
+	apiKeys           []repository.VendorAPIKey
+	// importTemplates holds the import templates by ID
+	importTemplates map[string]repository.ImportTemplate
+	// vendorTemplates holds the vendor templates by ID
+	vendorTemplates  map[string]repository.VendorTemplate
+	aliases          []repository.VendorAlias
+	blocklist        []repository.BlocklistEntry
+	blocklistMatches []repository.BlocklistMatch
+	favorites        []userVendor
+	scheduled        []repository.ScheduledStatusChange
+	// dormancy holds the dormancy flags by vendor ID
+	dormancy map[string]dormancyFlag
+	// touches holds the last touch of each vendor touched since its fields
+	// last changed
+	touches map[string]vendorTouch
+	// views holds the recent vendor views, oldest first
+	views []userVendor
+	// entityStates holds the states of suspended and deleted entities
+	entityStates map[string]string
+	lifecycleLog []repository.EntityLifecycleEntry
+	cursors      map[string]string
+	// codeReservations holds the vendor code reservations by ID
+	codeReservations map[string]repository.VendorCodeReservation
+	// exportJobs holds the vendor export jobs by ID
+	exportJobs map[string]exportJob
+}
+
+// exportJob is a vendor export job with its lease
+type exportJob struct {
+	repository.VendorExportJob
+	leaseUntil *time.Time
+}
+
+// userVendor is a vendor in a list of a user, like a favorite or recent view
+type userVendor struct {
+	userID   string
+	entityID string
+	vendorID string
+	at       time.Time
+}
+
+type tombstone struct {
+	entityID   string
+	vendorID   string
+	vendorCode string
+	changeSeq  int64
+	createdAt  time.Time
+}
+
+type dormancyFlag struct {
+	entityID       string
+	lastActivityAt time.Time
+	flaggedAt      time.Time
+}
+
+// vendorTouch is a touch of a vendor and the last change to its fields
+// before it
+type vendorTouch struct {
+	changeSeq      int64
+	updatedAt      time.Time
+	lastModifiedAt time.Time
+}
+
+type ledgerEntry struct {
+	id           string
+	vendorID     string
+	entityID     string
+	amount       int64
+	balanceAfter int64
+	kind         string
+	createdAt    time.Time
+}
+
+// New returns an empty store with the standard payment terms
+func New() *Store {
+	data := &state{
+		vendors:      make(map[string]repository.Vendor),
+		contacts:     make(map[string]repository.VendorContact),
+		externalRefs: make(map[string]repository.VendorExternalRef),
+		retention:    make(map[string]repository.RetentionSettings),
+		validation:   make(map[string]repository.ValidationSettings),
+		settings:     make(map[string]repository.EntitySettings),
+		vendorTypes:  make(map[string][]repository.VendorType),
+
+		approvalBreaches: make(map[string]time.Time),
+		approvalPolicies: make(map[string]repository.ApprovalPolicy),
+
+		// Mirrors the weights seeded by migrations/013_vendor_risk_scores.sql
+		riskWeights: map[string]map[string]int{"": {
+			"missing_tax_id":       25,
+			"foreign_bank_account": 20,
+			"recent_bank_change":   25,
+			"new_vendor":           10,
+			"missing_w9":           15,
+			"over_credit_limit":    20,
+		}},
+		riskScores:        make(map[string]repository.RiskScore),
+		tinMatches:        make(map[string]repository.TINMatch),
+		bankVerifications: make(map[string]repository.BankVerification),
+		dormancy:          make(map[string]dormancyFlag),
+		touches:           make(map[string]vendorTouch),
+		importTemplates:   make(map[string]repository.ImportTemplate),
+		vendorTemplates:   make(map[string]repository.VendorTemplate),
+		entityStates:      make(map[string]string),
+		cursors:           make(map[string]string),
+		codeReservations:  make(map[string]repository.VendorCodeReservation),
+		exportJobs:        make(map[string]exportJob),
+	}
+
+	// Mirrors the payment terms seeded by migrations/001_initial_schema.sql
+	now := time.Now().UTC()
+	for _, term := range []struct {
+		code, description string
+		netDays           int
+		discountPercent   float64
+		discountDays      int
+	}{
+		{"NET30", "Net 30 days", 30, 0, 0},
+		{"NET60", "Net 60 days", 60, 0, 0},
+		{"NET90", "Net 90 days", 90, 0, 0},
+		{"2/10N30", "2% 10 days, Net 30", 30, 2, 10},
+		{"1/10N30", "1% 10 days, Net 30", 30, 1, 10},
+		{"DUE", "Due on receipt", 0, 0, 0},
+		{"COD", "Cash on delivery", 0, 0, 0},
+		{"CIA", "Cash in advance", 0, 0, 0},
+	} {
+		pt := repository.PaymentTerm{
+			ID:          newID(),
+			Code:        term.code,
+			Description: term.description,
+			NetDays:     term.netDays,
+			IsActive:    true,
+			CreatedAt:   now,
+		}
+		if term.discountDays > 0 {
+			percent, days := term.discountPercent, term.discountDays
+			pt.DiscountPercent = &percent
+			pt.DiscountDays = &days
+		}
+		data.paymentTerms = append(data.paymentTerms, pt)
+	}
+
+	// Mirrors the default vendor types of migrations/008_vendor_type_registry.sql
+	for _, vt := range []struct{ code, label string }{
+		{"supplier", "Supplier"},
+		{"contractor", "Contractor"},
+		{"service_provider", "Service Provider"},
+		{"consultant", "Consultant"},
+		{"utility", "Utility"},
+	} {
+		data.vendorTypes[""] = append(data.vendorTypes[""], repository.VendorType{
+			Code:      vt.code,
+			Label:     vt.label,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+
+	return &Store{mu: &sync.Mutex{}, data: data}
+}
+
+// lock takes the store lock unless the store is bound to a transaction, which
+// already holds it
+func (s *Store) lock() func() {
+	if s.inTx {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+// WithTx runs fn against a copy of the data that replaces it when fn returns nil
+func (s *Store) WithTx(ctx context.Context, fn func(repo repository.Store) error) error {
+	defer s.lock()()
+
+	tx := &Store{mu: s.mu, data: s.data.clone(), inTx: true}
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	*s.data = *tx.data
+	return nil
+}
+
+func (d *state) clone() *state {
+	c := *d
+	c.vendors = make(map[string]repository.Vendor, len(d.vendors))
+	for k, v := range d.vendors {
+		c.vendors[k] = v
+	}
+	c.contacts = make(map[string]repository.VendorContact, len(d.contacts))
+	for k, v := range d.contacts {
+		c.contacts[k] = v
+	}
+	c.externalRefs = make(map[string]repository.VendorExternalRef, len(d.externalRefs))
+	for k, v := range d.externalRefs {
+		c.externalRefs[k] = v
+	}
+	c.retention = make(map[string]repository.RetentionSettings, len(d.retention))
+	for k, v := range d.retention {
+		c.retention[k] = v
+	}
+	c.validation = make(map[string]repository.ValidationSettings, len(d.validation))
+	for k, v := range d.validation {
+		c.validation[k] = v
+	}
+	c.settings = make(map[string]repository.EntitySettings, len(d.settings))
+	for k, v := range d.settings {
+		c.settings[k] = v
+	}
+	c.tombstones = append([]tombstone(nil), d.tombstones...)
+	c.ledger = append([]ledgerEntry(nil), d.ledger...)
+	c.auditLog = append([]repository.AuditEntry(nil), d.auditLog...)
+	c.events = append([]repository.VendorEvent(nil), d.events...)
+	c.paymentTerms = append([]repository.PaymentTerm(nil), d.paymentTerms...)
+	c.vendorTypes = make(map[string][]repository.VendorType, len(d.vendorTypes))
+	for k, v := range d.vendorTypes {
+		c.vendorTypes[k] = append([]repository.VendorType(nil), v...)
+	}
+	c.approvalBreaches = make(map[string]time.Time, len(d.approvalBreaches))
+	for k, v := range d.approvalBreaches {
+		c.approvalBreaches[k] = v
+	}
+	c.approvalPolicies = make(map[string]repository.ApprovalPolicy, len(d.approvalPolicies))
+	for k, v := range d.approvalPolicies {
+		c.approvalPolicies[k] = v
+	}
+	c.approvals = append([]repository.VendorApproval(nil), d.approvals...)
+	c.riskWeights = make(map[string]map[string]int, len(d.riskWeights))
+	for k, v := range d.riskWeights {
+		c.riskWeights[k] = maps.Clone(v)
+	}
+	c.riskScores = make(map[string]repository.RiskScore, len(d.riskScores))
+	for k, v := range d.riskScores {
+		c.riskScores[k] = v
+	}
+	c.tinMatches = maps.Clone(d.tinMatches)
+	c.bankVerifications = maps.Clone(d.bankVerifications)
+	c.entityStates = maps.Clone(d.entityStates)
+	c.lifecycleLog = append([]repository.EntityLifecycleEntry(nil), d.lifecycleLog...)
+	c.cursors = maps.Clone(d.cursors)
+	c.codeReservations = maps.Clone(d.codeReservations)
+	c.exportJobs = maps.Clone(d.exportJobs)
+	c.apiKeys = append([]repository.VendorAPIKey(nil), d.apiKeys...)
+	c.importTemplates = maps.Clone(d.importTemplates)
+	c.vendorTemplates = maps.Clone(d.vendorTemplates)
+	c.aliases = append([]repository.VendorAlias(nil), d.aliases...)
+	c.blocklist = append([]repository.BlocklistEntry(nil), d.blocklist...)
+	c.blocklistMatches = append([]repository.BlocklistMatch(nil), d.blocklistMatches...)
+	c.favorites = append([]userVendor(nil), d.favorites...)
+	c.scheduled = append([]repository.ScheduledStatusChange(nil), d.scheduled...)
+	c.dormancy = maps.Clone(d.dormancy)
+	c.touches = maps.Clone(d.touches)
+	c.views = append([]userVendor(nil), d.views...)
+	return &c
+}
+
+// newID returns a random UUID (version 4)
+func newID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// nextSeq advances the change sequence, like the vendor_change_seq trigger
+func (d *state) nextSeq() int64 {
+	d.seq++
+	return d.seq
+}
+
+// touchVendor advances the updated_at and change_seq of a live vendor, like
+// the repository does when state kept outside the vendor row changes
+func (d *state) touchVendor(id string) {
+	v, ok := d.vendors[id]
+	if !ok || v.DeletedAt != nil {
+		return
+	}
+	touch := vendorTouch{lastModifiedAt: d.lastModifiedAt(v)}
+	v.UpdatedAt = time.Now().UTC()
+	v.ChangeSeq = d.nextSeq()
+	d.vendors[id] = v
+	touch.changeSeq, touch.updatedAt = v.ChangeSeq, v.UpdatedAt
+	d.touches[id] = touch
+}
+
+// lastModifiedAt returns the time of the last change to the fields of v,
+// like the last_modified_at column: its updated_at unless its last write was
+// a touch
+func (d *state) lastModifiedAt(v repository.Vendor) time.Time {
+	if touch, ok := d.touches[v.ID]; ok && touch.changeSeq == v.ChangeSeq && touch.updatedAt.Equal(v.UpdatedAt) {
+		return touch.lastModifiedAt
+	}
+	return v.UpdatedAt
+}
+
+// liveVendor returns the vendor with id in entityID unless it is deleted
+func (d *state) liveVendor(id, entityID string) (repository.Vendor, bool) {
+	v, ok := d.vendors[id]
+	if !ok || v.EntityID != entityID || v.DeletedAt != nil {
+		return repository.Vendor{}, false
+	}
+	return v, true
+}
+
+// liveVendorByCode returns the vendor with code in entityID unless it is deleted
+func (d *state) liveVendorByCode(code, entityID string) (repository.Vendor, bool) {
+	for _, v := range d.vendors {
+		if v.EntityID == entityID && v.VendorCode == code && v.DeletedAt == nil {
+			return v, true
+		}
+	}
+	return repository.Vendor{}, false
+}
+
+// stored strips the request-only fields before a vendor is stored
+func stored(v repository.Vendor) repository.Vendor {
+	v.Contacts = nil
+	v.ExternalRefs = nil
+	v.Warnings = nil
+	return v
+}
+
+// Create creates a new vendor
+func (s *Store) Create(ctx context.Context, vendor *repository.Vendor) error {
+	defer s.lock()()
+
+	if _, exists := s.data.liveVendorByCode(vendor.VendorCode, vendor.EntityID); exists {
+		return errors.Wrap(fmt.Errorf("duplicate vendor code %q", vendor.VendorCode), errors.ErrCodeInternal, "failed to create vendor")
+	}
+
+	now := time.Now().UTC()
+	vendor.ID = newID()
+	vendor.CurrentBalance = 0
+	vendor.CreatedAt = now
+	vendor.UpdatedAt = now
+	vendor.DeletedAt = nil
+	vendor.ChangeSeq = s.data.nextSeq()
+
+	s.data.vendors[vendor.ID] = stored(*vendor)
+	return nil
+}
+
+// GetByID retrieves a vendor by ID
+func (s *Store) GetByID(ctx context.Context, id, entityID string) (*repository.Vendor, error) {
+	defer s.lock()()
+
+	v, ok := s.data.liveVendor(id, entityID)
+	if !ok {
+		return nil, errors.NotFound("vendor", id)
+	}
+	return &v, nil
+}
+
+// GetByCode retrieves a vendor by vendor code
+func (s *Store) GetByCode(ctx context.Context, code, entityID string) (*repository.Vendor, error) {
+	defer s.lock()()
+
+	v, ok := s.data.liveVendorByCode(code, entityID)
+	if !ok {
+		return nil, errors.NotFound("vendor", code)
+	}
+	return &v, nil
+}
+
+// Update updates a vendor
+func (s *Store) Update(ctx context.Context, vendor *repository.Vendor) error {
+	defer s.lock()()
+
+	existing, ok := s.data.liveVendor(vendor.ID, vendor.EntityID)
+	if !ok {
+		return errors.NotFound("vendor", vendor.ID)
+	}
+	if other, exists := s.data.liveVendorByCode(vendor.VendorCode, vendor.EntityID); exists && other.ID != vendor.ID {
+		return errors.Wrap(fmt.Errorf("duplicate vendor code %q", vendor.VendorCode), errors.ErrCodeInternal, "failed to update vendor")
+	}
+
+	// Columns not written by the UPDATE keep their stored values
+	vendor.CurrentBalance = existing.CurrentBalance
+	vendor.CreatedBy = existing.CreatedBy
+	vendor.CreatedAt = existing.CreatedAt
+	vendor.TemplateID = existing.TemplateID
+	vendor.Source, vendor.SourceReference = existing.Source, existing.SourceReference
+	vendor.DeletedAt = nil
+	vendor.UpdatedAt = time.Now().UTC()
+	vendor.ChangeSeq = s.data.nextSeq()
+
+	s.data.vendors[vendor.ID] = stored(*vendor)
+	return nil
+}
+
+// UpsertByCode inserts the vendor, or overwrites only the given columns of the
+// existing vendor with the same code. Status is never changed on update.
+func (s *Store) UpsertByCode(ctx context.Context, vendor *repository.Vendor, columns []string) (*repository.Vendor, bool, error) {
+	for _, column := range columns {
+		if !repository.IsUpsertableColumn(column) {
+			return nil, false, errors.InvalidInput(column, "field cannot be set by upsert")
+		}
+	}
+
+	defer s.lock()()
+
+	now := time.Now().UTC()
+	existing, ok := s.data.liveVendorByCode(vendor.VendorCode, vendor.EntityID)
+	if !ok {
+		created := stored(*vendor)
+		created.ID = newID()
+		created.CurrentBalance = 0
+		created.CreatedAt = now
+		created.UpdatedAt = now
+		created.DeletedAt = nil
+		created.ChangeSeq = s.data.nextSeq()
+		s.data.vendors[created.ID] = created
+		return &created, true, nil
+	}
+
+	for _, column := range columns {
+		copyColumn(&existing, vendor, column)
+	}
+	existing.UpdatedBy = vendor.CreatedBy
+	existing.UpdatedAt = now
+	existing.ChangeSeq = s.data.nextSeq()
+	s.data.vendors[existing.ID] = existing
+	return &existing, false, nil
+}
+
+// copyColumn copies one upsertable column from src to dst
+func copyColumn(dst, src *repository.Vendor, column string) {
+	switch column {
+	case "vendor_name":
+		dst.VendorName = src.VendorName
+	case "legal_name":
+		dst.LegalName = src.LegalName
+	case "doing_business_as":
+		dst.DoingBusinessAs = src.DoingBusinessAs
+	case "remittance_email":
+		dst.RemittanceEmail = src.RemittanceEmail
+	case "locale":
+		dst.Locale = src.Locale
+	case "vendor_type":
+		dst.VendorType = src.VendorType
+	case "tax_id":
+		dst.TaxID = src.TaxID
+	case "is_tax_exempt":
+		dst.IsTaxExempt = src.IsTaxExempt
+	case "is_1099_vendor":
+		dst.Is1099Vendor = src.Is1099Vendor
+	case "email":
+		dst.Email = src.Email
+	case "phone":
+		dst.Phone = src.Phone
+	case "fax":
+		dst.Fax = src.Fax
+	case "website":
+		dst.Website = src.Website
+	case "address_line1":
+		dst.AddressLine1 = src.AddressLine1
+	case "address_line2":
+		dst.AddressLine2 = src.AddressLine2
+	case "city":
+		dst.City = src.City
+	case "state_province":
+		dst.StateProvince = src.StateProvince
+	case "postal_code":
+		dst.PostalCode = src.PostalCode
+	case "country":
+		dst.Country = src.Country
+	case "payment_terms":
+		dst.PaymentTerms = src.PaymentTerms
+	case "payment_method":
+		dst.PaymentMethod = src.PaymentMethod
+	case "currency":
+		dst.Currency = src.Currency
+	case "accepted_currencies":
+		dst.AcceptedCurrencies = src.AcceptedCurrencies
+	case "credit_limit":
+		dst.CreditLimit = src.CreditLimit
+	case "credit_limit_currency":
+		dst.CreditLimitCurrency = src.CreditLimitCurrency
+	case "bank_name":
+		dst.BankName = src.BankName
+	case "bank_account_number":
+		dst.BankAccountNumber = src.BankAccountNumber
+	case "bank_routing_number":
+		dst.BankRoutingNumber = src.BankRoutingNumber
+	case "swift_code":
+		dst.SwiftCode = src.SwiftCode
+	case "iban":
+		dst.IBAN = src.IBAN
+	case "notes":
+		dst.Notes = src.Notes
+	case "tags":
+		dst.Tags = src.Tags
+	}
+}
+
+// Delete soft-deletes a vendor
+func (s *Store) Delete(ctx context.Context, id, entityID string) error {
+	defer s.lock()()
+
+	v, ok := s.data.liveVendor(id, entityID)
+	if !ok {
+		return errors.NotFound("vendor", id)
+	}
+
+	now := time.Now().UTC()
+	v.DeletedAt = &now
+	v.UpdatedAt = now
+	v.ChangeSeq = s.data.nextSeq()
+	s.data.vendors[id] = v
+	return nil
+}
+
+// CountVendorChildren counts the child records of a vendor. The store keeps no
+// documents.
+func (s *Store) CountVendorChildren(ctx context.Context, vendorID string) (*repository.VendorChildren, error) {
+	defer s.lock()()
+
+	c := &repository.VendorChildren{}
+	for _, contact := range s.data.contacts {
+		if contact.VendorID == vendorID {
+			c.Contacts++
+		}
+	}
+	if _, ok := s.data.bankVerifications[vendorID]; ok {
+		c.BankVerifications++
+	}
+	for _, sc := range s.data.scheduled {
+		if sc.VendorID == vendorID && sc.State == repository.ScheduleStatePending {
+			c.PendingStatusChanges++
+		}
+	}
+	return c, nil
+}
+
+// DeleteVendorChildren deletes the contacts and bank verification of a vendor
+// and cancels its pending scheduled status changes, returning what it removed
+func (s *Store) DeleteVendorChildren(ctx context.Context, vendorID string) (*repository.VendorChildren, error) {
+	defer s.lock()()
+
+	c := &repository.VendorChildren{}
+	for id, contact := range s.data.contacts {
+		if contact.VendorID == vendorID {
+			delete(s.data.contacts, id)
+			c.Contacts++
+		}
+	}
+	if _, ok := s.data.bankVerifications[vendorID]; ok {
+		delete(s.data.bankVerifications, vendorID)
+		c.BankVerifications++
+	}
+	now := time.Now().UTC()
+	for i := range s.data.scheduled {
+		sc := &s.data.scheduled[i]
+		if sc.VendorID != vendorID || sc.State != repository.ScheduleStatePending {
+			continue
+		}
+		sc.State = repository.ScheduleStateCancelled
+		sc.ProcessedAt = &now
+		c.PendingStatusChanges++
+	}
+	return c, nil
+}
+
+// List retrieves vendors with filtering and pagination, ordered by name
+func (s *Store) List(ctx context.Context, filter repository.VendorFilter, limit, offset int) ([]*repository.Vendor, int64, error) {
+	defer s.lock()()
+
+	matched := make([]repository.Vendor, 0)
+	for _, v := range s.data.vendors {
+		if !matchesFilter(v, filter) {
+			continue
+		}
+		if filter.MinRiskScore != nil {
+			score, ok := s.data.riskScores[v.ID]
+			if !ok || score.Score < *filter.MinRiskScore {
+				continue
+			}
+		}
+		if filter.Name != "" && !s.data.nameContains(v, filter.Name) {
+			continue
+		}
+		matched = append(matched, v)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if filter.Sort == repository.SortRiskScoreDesc || filter.Sort == repository.SortRiskScoreAsc {
+			a, aok := s.data.riskScores[matched[i].ID]
+			b, bok := s.data.riskScores[matched[j].ID]
+			switch {
+			case aok != bok:
+				return aok
+			case aok && a.Score != b.Score:
+				return (a.Score > b.Score) == (filter.Sort == repository.SortRiskScoreDesc)
+			}
+		}
+		if matched[i].VendorName != matched[j].VendorName {
+			return matched[i].VendorName < matched[j].VendorName
+		}
+		return matched[i].ID < matched[j].ID
+	})
+
+	// There is no planner to estimate with, so estimates are exact
+	total := int64(len(matched))
+	if filter.TotalMode == repository.TotalNone {
+		total = -1
+	}
+	vendors := make([]*repository.Vendor, 0)
+	for i := offset; i < len(matched) && len(vendors) < limit; i++ {
+		v := matched[i]
+		if len(filter.Fields) > 0 {
+			vendors = append(vendors, v.Project(filter.Fields))
+			continue
+		}
+		vendors = append(vendors, &v)
+	}
+
+	return vendors, total, nil
+}
+
+// ListSummaries retrieves a page of vendor summaries matching the filter
+func (s *Store) ListSummaries(ctx context.Context, filter repository.VendorFilter, limit, offset int) ([]*repository.VendorSummary, int64, error) {
+	filter.Fields = nil
+	vendors, total, err := s.List(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer s.lock()()
+	summaries := make([]*repository.VendorSummary, 0, len(vendors))
+	for _, v := range vendors {
+		sum := &repository.VendorSummary{
+			ID:         v.ID,
+			VendorCode: v.VendorCode,
+			VendorName: v.VendorName,
+			Status:     v.Status,
+			Currency:   v.Currency,
+		}
+		for _, tag := range v.Tags {
+			sum.IsPreferred = sum.IsPreferred || tag == repository.PreferredVendorTag
+		}
+		var earliest *repository.VendorContact
+		for _, c := range s.data.contacts {
+			if c.VendorID != v.ID || !c.IsPrimary || c.Email == nil {
+				continue
+			}
+			if earliest == nil || c.CreatedAt.Before(earliest.CreatedAt) ||
+				(c.CreatedAt.Equal(earliest.CreatedAt) && c.ID < earliest.ID) {
+				c := c
+				earliest = &c
+			}
+		}
+		if earliest != nil {
+			sum.PrimaryContactEmail = earliest.Email
+		}
+		summaries = append(summaries, sum)
+	}
+	return summaries, total, nil
+}
+
+// matchesFilter mirrors VendorFilter's SQL WHERE clause
+func matchesFilter(v repository.Vendor, f repository.VendorFilter) bool {
+	if v.EntityID != f.EntityID || v.DeletedAt != nil {
+		return false
+	}
+	if f.Status != nil && v.Status != *f.Status {
+		return false
+	}
+	if f.VendorType != nil && v.VendorType != *f.VendorType {
+		return false
+	}
+	if f.ActiveOnly && v.Status != "active" {
+		return false
+	}
+	if f.Is1099Vendor != nil && v.Is1099Vendor != *f.Is1099Vendor {
+		return false
+	}
+	if f.IsTaxExempt != nil && v.IsTaxExempt != *f.IsTaxExempt {
+		return false
+	}
+	if f.HasCreditLimit != nil && (v.CreditLimit != nil) != *f.HasCreditLimit {
+		return false
+	}
+	if f.OverCreditLimit != nil {
+		if repository.OverCreditLimit(&v) != *f.OverCreditLimit {
+			return false
+		}
+	}
+	if f.MissingTaxID != nil {
+		missing := v.TaxID == nil || strings.TrimSpace(*v.TaxID) == ""
+		if missing != *f.MissingTaxID {
+			return false
+		}
+	}
+	if f.Locale != "" && (v.Locale == nil || !locale.Matches(*v.Locale, f.Locale)) {
+		return false
+	}
+	if f.MissingLocale != nil && (v.Locale == nil) != *f.MissingLocale {
+		return false
+	}
+	if f.Currency != "" && v.Currency != f.Currency && !slices.Contains(v.AcceptedCurrencies, f.Currency) {
+		return false
+	}
+	if f.Tag != "" && !slices.Contains(v.Tags, f.Tag) {
+		return false
+	}
+	if f.Source != "" && v.Source != f.Source {
+		return false
+	}
+	if f.Country != "" && v.Country != f.Country {
+		return false
+	}
+	if f.ExcludeSuspectedTest && repository.IsSuspectedTestVendor(&v) {
+		return false
+	}
+	return true
+}
+
+// ListChangedSince retrieves the vendors of an entity changed after afterSeq,
+// merged with transfer tombstones, ordered by change_seq
+func (s *Store) ListChangedSince(ctx context.Context, entityID string, afterSeq int64, limit int) ([]*repository.Vendor, error) {
+	defer s.lock()()
+
+	changes := make([]*repository.Vendor, 0)
+	for _, v := range s.data.vendors {
+		if v.EntityID == entityID && v.ChangeSeq > afterSeq {
+			v := v
+			changes = append(changes, &v)
+		}
+	}
+	for _, t := range s.data.tombstones {
+		if t.entityID == entityID && t.changeSeq > afterSeq {
+			removedAt := t.createdAt
+			changes = append(changes, &repository.Vendor{
+				ID:         t.vendorID,
+				EntityID:   t.entityID,
+				VendorCode: t.vendorCode,
+				ChangeSeq:  t.changeSeq,
+				DeletedAt:  &removedAt,
+			})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].ChangeSeq < changes[j].ChangeSeq })
+	if len(changes) > limit {
+		changes = changes[:limit]
+	}
+	return changes, nil
+}
+
+// ValidateVendor validates if a vendor can be used for invoice creation
+func (s *Store) ValidateVendor(ctx context.Context, vendorID, entityID string) (bool, string, error) {
+	defer s.lock()()
+
+	vendor, ok := s.data.liveVendor(vendorID, entityID)
+	if !ok {
+		return false, "vendor not found", errors.NotFound("vendor", vendorID)
+	}
+
+	if vendor.Status != "active" {
+		return false, fmt.Sprintf("vendor status is '%s', must be active", vendor.Status), nil
+	}
+
+	if repository.OverCreditLimit(&vendor) {
+		return false, fmt.Sprintf("vendor has exceeded credit limit: balance=%d, limit=%d",
+			vendor.CurrentBalance, *vendor.CreditLimit), nil
+	}
+
+	return true, "", nil
+}
+
+// UpdateBalance adjusts the vendor's current balance and records it in the ledger
+func (s *Store) UpdateBalance(ctx context.Context, vendorID, entityID string, amount int64) error {
+	defer s.lock()()
+
+	v, ok := s.data.liveVendor(vendorID, entityID)
+	if !ok {
+		return errors.NotFound("vendor", vendorID)
+	}
+	if v.CurrentBalance+amount < 0 {
+		return errors.Wrap(fmt.Errorf("current_balance would become negative"), errors.ErrCodeInternal, "failed to update vendor balance")
+	}
+
+	now := time.Now().UTC()
+	v.CurrentBalance += amount
+	v.UpdatedAt = now
+	v.ChangeSeq = s.data.nextSeq()
+	s.data.vendors[vendorID] = v
+	s.data.ledger = append(s.data.ledger, ledgerEntry{
+		id:           newID(),
+		vendorID:     vendorID,
+		entityID:     entityID,
+		amount:       amount,
+		balanceAfter: v.CurrentBalance,
+		kind:         repository.BalanceKindAdjustment,
+		createdAt:    now,
+	})
+	return nil
+}
+
+// HasBalanceActivitySince reports whether the balance ledger of a vendor has
+// entries with a non-zero amount since a time
+func (s *Store) HasBalanceActivitySince(ctx context.Context, vendorID string, since time.Time) (bool, error) {
+	defer s.lock()()
+
+	for _, entry := range s.data.ledger {
+		if entry.vendorID == vendorID && entry.amount != 0 && !entry.createdAt.Before(since) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ledgerBalance sums the balance ledger of a vendor
+func (d *state) ledgerBalance(vendorID string) int64 {
+	var total int64
+	for _, entry := range d.ledger {
+		if entry.vendorID == vendorID {
+			total += entry.amount
+		}
+	}
+	return total
+}
+
+// FindBalanceDiscrepancies compares the current_balance of the live vendors of
+// an entity, or of all entities when entityID is empty, to the sum of their
+// balance ledger, returning the vendors where they differ by entity and code
+func (s *Store) FindBalanceDiscrepancies(ctx context.Context, entityID string) ([]*repository.BalanceDiscrepancy, error) {
+	defer s.lock()()
+
+	discrepancies := make([]*repository.BalanceDiscrepancy, 0)
+	for _, v := range s.data.vendors {
+		if v.DeletedAt != nil || (entityID != "" && v.EntityID != entityID) {
+			continue
+		}
+		ledger := s.data.ledgerBalance(v.ID)
+		if v.CurrentBalance == ledger {
+			continue
+		}
+		discrepancies = append(discrepancies, &repository.BalanceDiscrepancy{
+			VendorID:       v.ID,
+			EntityID:       v.EntityID,
+			VendorCode:     v.VendorCode,
+			VendorName:     v.VendorName,
+			CurrentBalance: v.CurrentBalance,
+			LedgerBalance:  ledger,
+			Drift:          v.CurrentBalance - ledger,
+		})
+	}
+	sort.Slice(discrepancies, func(i, j int) bool {
+		if discrepancies[i].EntityID != discrepancies[j].EntityID {
+			return discrepancies[i].EntityID < discrepancies[j].EntityID
+		}
+		return discrepancies[i].VendorCode < discrepancies[j].VendorCode
+	})
+	return discrepancies, nil
+}
+
+// ReconcileVendorBalance sets the current_balance of a vendor to the sum of its
+// balance ledger and records a reconciliation ledger entry, returning the
+// corrected discrepancy, or nil when the balance already matches
+func (s *Store) ReconcileVendorBalance(ctx context.Context, vendorID, entityID string) (*repository.BalanceDiscrepancy, error) {
+	defer s.lock()()
+
+	v, ok := s.data.liveVendor(vendorID, entityID)
+	if !ok {
+		return nil, errors.NotFound("vendor", vendorID)
+	}
+	ledger := s.data.ledgerBalance(vendorID)
+	if v.CurrentBalance == ledger {
+		return nil, nil
+	}
+	discrepancy := &repository.BalanceDiscrepancy{
+		VendorID:       v.ID,
+		EntityID:       v.EntityID,
+		VendorCode:     v.VendorCode,
+		VendorName:     v.VendorName,
+		CurrentBalance: v.CurrentBalance,
+		LedgerBalance:  ledger,
+		Drift:          v.CurrentBalance - ledger,
+	}
+
+	now := time.Now().UTC()
+	v.CurrentBalance = ledger
+	v.UpdatedAt = now
+	v.ChangeSeq = s.data.nextSeq()
+	s.data.vendors[vendorID] = v
+	s.data.ledger = append(s.data.ledger, ledgerEntry{
+		id:           newID(),
+		vendorID:     vendorID,
+		entityID:     entityID,
+		balanceAfter: ledger,
+		kind:         repository.BalanceKindReconciliation,
+		createdAt:    now,
+	})
+	return discrepancy, nil
+}
+
+// GetContacts retrieves all contacts of a vendor, primary contacts first
+func (s *Store) GetContacts(ctx context.Context, vendorID string) ([]*repository.VendorContact, error) {
+	defer s.lock()()
+
+	contacts := make([]*repository.VendorContact, 0)
+	for _, c := range s.data.contacts {
+		if c.VendorID == vendorID {
+			c := c
+			contacts = append(contacts, &c)
+		}
+	}
+	sort.Slice(contacts, func(i, j int) bool {
+		a, b := contacts[i], contacts[j]
+		if a.IsPrimary != b.IsPrimary {
+			return a.IsPrimary
+		}
+		if a.FirstName != b.FirstName {
+			return a.FirstName < b.FirstName
+		}
+		return a.LastName < b.LastName
+	})
+	return contacts, nil
+}
+
+// ListContacts retrieves a page of the contacts of a vendor matching the
+// filter, with the number of matching contacts
+func (s *Store) ListContacts(ctx context.Context, filter repository.ContactFilter, limit, offset int) ([]*repository.VendorContact, int64, error) {
+	defer s.lock()()
+
+	var matching []*repository.VendorContact
+	for _, c := range s.data.contacts {
+		if c.VendorID != filter.VendorID ||
+			(filter.ContactType != nil && c.ContactType != *filter.ContactType) ||
+			(filter.IsPrimary != nil && c.IsPrimary != *filter.IsPrimary) {
+			continue
+		}
+		c := c
+		matching = append(matching, &c)
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		a, b := matching[i], matching[j]
+		switch filter.Sort {
+		case repository.SortContactLastName:
+			if a.LastName != b.LastName {
+				return a.LastName < b.LastName
+			}
+			if a.FirstName != b.FirstName {
+				return a.FirstName < b.FirstName
+			}
+		case repository.SortContactCreatedAt, repository.SortContactCreatedAtDesc:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt) == (filter.Sort == repository.SortContactCreatedAt)
+			}
+		default:
+			if a.IsPrimary != b.IsPrimary {
+				return a.IsPrimary
+			}
+			if a.FirstName != b.FirstName {
+				return a.FirstName < b.FirstName
+			}
+			if a.LastName != b.LastName {
+				return a.LastName < b.LastName
+			}
+		}
+		return a.ID < b.ID
+	})
+
+	contacts := make([]*repository.VendorContact, 0)
+	for i := offset; i < len(matching) && i < offset+limit; i++ {
+		contacts = append(contacts, matching[i])
+	}
+	return contacts, int64(len(matching)), nil
+}
+
+// GetContact retrieves a single contact of a vendor
+func (s *Store) GetContact(ctx context.Context, vendorID, contactID string) (*repository.VendorContact, error) {
+	defer s.lock()()
+
+	c, ok := s.data.contacts[contactID]
+	if !ok || c.VendorID != vendorID {
+		return nil, errors.NotFound("contact", contactID)
+	}
+	return &c, nil
+}
+
+// AddContact adds a contact to a vendor
+func (s *Store) AddContact(ctx context.Context, contact *repository.VendorContact) error {
+	defer s.lock()()
+
+	if _, ok := s.data.vendors[contact.VendorID]; !ok {
+		return errors.Wrap(fmt.Errorf("vendor %s does not exist", contact.VendorID), errors.ErrCodeInternal, "failed to add vendor contact")
+	}
+
+	now := time.Now().UTC()
+	contact.ID = newID()
+	contact.CreatedAt = now
+	contact.UpdatedAt = now
+	s.data.contacts[contact.ID] = *contact
+	return nil
+}
+
+// ListEntityContacts retrieves the contacts of all live vendors of an entity,
+// ordered by vendor code
+func (s *Store) ListEntityContacts(ctx context.Context, entityID string) ([]*repository.EntityContact, error) {
+	defer s.lock()()
+
+	contacts := make([]*repository.EntityContact, 0)
+	for _, c := range s.data.contacts {
+		v, ok := s.data.vendors[c.VendorID]
+		if !ok || v.EntityID != entityID || v.DeletedAt != nil {
+			continue
+		}
+		c := c
+		contacts = append(contacts, &repository.EntityContact{VendorCode: v.VendorCode, VendorContact: &c})
+	}
+	sort.Slice(contacts, func(i, j int) bool {
+		a, b := contacts[i], contacts[j]
+		if a.VendorCode != b.VendorCode {
+			return a.VendorCode < b.VendorCode
+		}
+		if a.IsPrimary != b.IsPrimary {
+			return a.IsPrimary
+		}
+		if a.FirstName != b.FirstName {
+			return a.FirstName < b.FirstName
+		}
+		return a.LastName < b.LastName
+	})
+	return contacts, nil
+}
+
+// FindContactByEmail retrieves the oldest contact of a vendor with the email
+// in NormalizeContactEmail form, or nil when the vendor has none
+func (s *Store) FindContactByEmail(ctx context.Context, vendorID, email string) (*repository.VendorContact, error) {
+	defer s.lock()()
+
+	email = repository.NormalizeContactEmail(email)
+	var found *repository.VendorContact
+	for _, c := range s.data.contacts {
+		if c.VendorID != vendorID || c.Email == nil || repository.NormalizeContactEmail(*c.Email) != email {
+			continue
+		}
+		if found == nil || c.CreatedAt.Before(found.CreatedAt) || (c.CreatedAt.Equal(found.CreatedAt) && c.ID < found.ID) {
+			c := c
+			found = &c
+		}
+	}
+	return found, nil
+}
+
+// UpdateContact overwrites a contact of a vendor
+func (s *Store) UpdateContact(ctx context.Context, contact *repository.VendorContact) error {
+	defer s.lock()()
+
+	stored, ok := s.data.contacts[contact.ID]
+	if !ok || stored.VendorID != contact.VendorID {
+		return errors.NotFound("contact", contact.ID)
+	}
+
+	contact.CreatedAt = stored.CreatedAt
+	contact.UpdatedAt = time.Now().UTC()
+	s.data.contacts[contact.ID] = *contact
+	return nil
+}
+
+// DeleteContact removes a contact of a vendor
+func (s *Store) DeleteContact(ctx context.Context, vendorID, contactID string) error {
+	defer s.lock()()
+
+	c, ok := s.data.contacts[contactID]
+	if !ok || c.VendorID != vendorID {
+		return errors.NotFound("contact", contactID)
+	}
+	delete(s.data.contacts, contactID)
+	return nil
+}
+
+// LockVendorContacts does nothing; the store serializes all access
+func (s *Store) LockVendorContacts(ctx context.Context, vendorID string) error {
+	return nil
+}
+
+// ListDuplicateContactEmails retrieves the emails used by more than one
+// contact of the same live vendor of an entity, ordered by vendor code and
+// email
+func (s *Store) ListDuplicateContactEmails(ctx context.Context, entityID string) ([]*repository.DuplicateContactEmail, error) {
+	defer s.lock()()
+
+	var contacts []repository.VendorContact
+	for _, c := range s.data.contacts {
+		v, ok := s.data.vendors[c.VendorID]
+		if ok && v.EntityID == entityID && v.DeletedAt == nil && c.Email != nil && repository.NormalizeContactEmail(*c.Email) != "" {
+			contacts = append(contacts, c)
+		}
+	}
+	sort.Slice(contacts, func(i, j int) bool {
+		if !contacts[i].CreatedAt.Equal(contacts[j].CreatedAt) {
+			return contacts[i].CreatedAt.Before(contacts[j].CreatedAt)
+		}
+		return contacts[i].ID < contacts[j].ID
+	})
+
+	byEmail := make(map[[2]string]*repository.DuplicateContactEmail)
+	for _, c := range contacts {
+		key := [2]string{c.VendorID, repository.NormalizeContactEmail(*c.Email)}
+		d, ok := byEmail[key]
+		if !ok {
+			v := s.data.vendors[c.VendorID]
+			d = &repository.DuplicateContactEmail{VendorID: v.ID, VendorCode: v.VendorCode, VendorName: v.VendorName, Email: key[1]}
+			byEmail[key] = d
+		}
+		d.ContactIDs = append(d.ContactIDs, c.ID)
+	}
+
+	duplicates := make([]*repository.DuplicateContactEmail, 0)
+	for _, d := range byEmail {
+		if len(d.ContactIDs) > 1 {
+			duplicates = append(duplicates, d)
+		}
+	}
+	sort.Slice(duplicates, func(i, j int) bool {
+		a, b := duplicates[i], duplicates[j]
+		if a.VendorCode != b.VendorCode {
+			return a.VendorCode < b.VendorCode
+		}
+		return a.Email < b.Email
+	})
+	return duplicates, nil
+}
+
+// GetPaymentTerms retrieves all active payment terms ordered by net days
+func (s *Store) GetPaymentTerms(ctx context.Context) ([]*repository.PaymentTerm, error) {
+	return s.ListPaymentTerms(ctx, true)
+}
+
+// ListPaymentTerms retrieves payment terms ordered by net days, optionally
+// only the active ones
+func (s *Store) ListPaymentTerms(ctx context.Context, onlyActive bool) ([]*repository.PaymentTerm, error) {
+	defer s.lock()()
+
+	terms := make([]*repository.PaymentTerm, 0, len(s.data.paymentTerms))
+	for _, t := range s.data.paymentTerms {
+		if t.IsActive || !onlyActive {
+			t := t
+			terms = append(terms, &t)
+		}
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if terms[i].NetDays != terms[j].NetDays {
+			return terms[i].NetDays < terms[j].NetDays
+		}
+		return terms[i].Code < terms[j].Code
+	})
+	return terms, nil
+}
+
+// GetPaymentTermByCode retrieves a payment term by code, case-insensitively
+func (s *Store) GetPaymentTermByCode(ctx context.Context, code string, onlyActive bool) (*repository.PaymentTerm, error) {
+	defer s.lock()()
+
+	for _, t := range s.data.paymentTerms {
+		if strings.EqualFold(t.Code, strings.TrimSpace(code)) && (t.IsActive || !onlyActive) {
+			return &t, nil
+		}
+	}
+	return nil, &repository.NotFoundError{Resource: "payment_term", ID: code, Err: errors.NotFound("payment_term", code)}
+}
+
+// CountPaymentTermUsage counts the live vendors using a payment term code,
+// case-insensitively, by entity, most using first
+func (s *Store) CountPaymentTermUsage(ctx context.Context, code string) ([]*repository.PaymentTermUsage, error) {
+	defer s.lock()()
+
+	counts := make(map[string]int64)
+	for _, v := range s.data.vendors {
+		if v.DeletedAt == nil && strings.EqualFold(v.PaymentTerms, strings.TrimSpace(code)) {
+			counts[v.EntityID]++
+		}
+	}
+	usage := make([]*repository.PaymentTermUsage, 0, len(counts))
+	for entityID, n := range counts {
+		usage = append(usage, &repository.PaymentTermUsage{EntityID: entityID, Vendors: n})
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Vendors != usage[j].Vendors {
+			return usage[i].Vendors > usage[j].Vendors
+		}
+		return usage[i].EntityID < usage[j].EntityID
+	})
+	return usage, nil
+}
+
+// ReplacePaymentTerm moves every live vendor using the payment term from to
+// the term to
+func (s *Store) ReplacePaymentTerm(ctx context.Context, from, to string, updatedBy *string) ([]*repository.ReassignedVendor, error) {
+	defer s.lock()()
+
+	now := time.Now().UTC()
+	vendors := make([]*repository.ReassignedVendor, 0)
+	for id, v := range s.data.vendors {
+		if v.DeletedAt != nil || !strings.EqualFold(v.PaymentTerms, strings.TrimSpace(from)) {
+			continue
+		}
+		v.PaymentTerms = to
+		v.UpdatedBy = updatedBy
+		v.UpdatedAt = now
+		v.ChangeSeq = s.data.nextSeq()
+		s.data.vendors[id] = v
+		vendors = append(vendors, &repository.ReassignedVendor{ID: v.ID, EntityID: v.EntityID, VendorCode: v.VendorCode})
+	}
+	return vendors, nil
+}
+
+// DeactivatePaymentTerm marks a payment term inactive
+func (s *Store) DeactivatePaymentTerm(ctx context.Context, code string) error {
+	defer s.lock()()
+
+	for i, t := range s.data.paymentTerms {
+		if strings.EqualFold(t.Code, strings.TrimSpace(code)) {
+			s.data.paymentTerms[i].IsActive = false
+			return nil
+		}
+	}
+	return &repository.NotFoundError{Resource: "payment_term", ID: code, Err: errors.NotFound("payment_term", code)}
+}
+
+// SetExternalRef creates or replaces the vendor's mapping for ref.System
+func (s *Store) SetExternalRef(ctx context.Context, ref *repository.VendorExternalRef) error {
+	defer s.lock()()
+
+	for _, other := range s.data.externalRefs {
+		if other.EntityID == ref.EntityID && other.System == ref.System && other.ExternalID == ref.ExternalID && other.VendorID != ref.VendorID {
+			owner := s.data.vendors[other.VendorID]
+			return repository.ExternalRefConflict(ref.System, ref.ExternalID, &owner)
+		}
+	}
+
+	now := time.Now().UTC()
+	for id, existing := range s.data.externalRefs {
+		if existing.VendorID == ref.VendorID && existing.System == ref.System {
+			existing.ExternalID = ref.ExternalID
+			existing.UpdatedAt = now
+			s.data.externalRefs[id] = existing
+			ref.ID, ref.CreatedAt, ref.UpdatedAt = existing.ID, existing.CreatedAt, existing.UpdatedAt
+			return nil
+		}
+	}
+
+	ref.ID = newID()
+	ref.CreatedAt = now
+	ref.UpdatedAt = now
+	s.data.externalRefs[ref.ID] = *ref
+	return nil
+}
+
+// GetExternalRefs retrieves all external refs of a vendor ordered by system
+func (s *Store) GetExternalRefs(ctx context.Context, vendorID, entityID string) ([]*repository.VendorExternalRef, error) {
+	defer s.lock()()
+
+	refs := make([]*repository.VendorExternalRef, 0)
+	for _, ref := range s.data.externalRefs {
+		if ref.VendorID == vendorID && ref.EntityID == entityID {
+			ref := ref
+			refs = append(refs, &ref)
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].System < refs[j].System })
+	return refs, nil
+}
+
+// DeleteExternalRef removes the vendor's mapping for a system
+func (s *Store) DeleteExternalRef(ctx context.Context, vendorID, entityID, system string) error {
+	defer s.lock()()
+
+	for id, ref := range s.data.externalRefs {
+		if ref.VendorID == vendorID && ref.EntityID == entityID && ref.System == system {
+			delete(s.data.externalRefs, id)
+			return nil
+		}
+	}
+	return errors.NotFound("external_ref", system)
+}
+
+// DeleteAllExternalRefs removes every external mapping of a vendor
+func (s *Store) DeleteAllExternalRefs(ctx context.Context, vendorID, entityID string) error {
+	defer s.lock()()
+
+	for id, ref := range s.data.externalRefs {
+		if ref.VendorID == vendorID && ref.EntityID == entityID {
+			delete(s.data.externalRefs, id)
+		}
+	}
+	return nil
+}
+
+// GetByExternalRef retrieves the vendor mapped to an external system ID
+func (s *Store) GetByExternalRef(ctx context.Context, entityID, system, externalID string) (*repository.Vendor, error) {
+	defer s.lock()()
+
+	for _, ref := range s.data.externalRefs {
+		if ref.EntityID == entityID && ref.System == system && ref.ExternalID == externalID {
+			if v, ok := s.data.liveVendor(ref.VendorID, entityID); ok {
+				return &v, nil
+			}
+		}
+	}
+	return nil, errors.NotFound("vendor", fmt.Sprintf("%s:%s", system, externalID))
+}
+
+// TransferVendor moves a vendor to another entity under the given vendor code
+func (s *Store) TransferVendor(ctx context.Context, vendorID, fromEntityID, toEntityID, vendorCode string, updatedBy *string) (*repository.Vendor, error) {
+	defer s.lock()()
+
+	v, ok := s.data.liveVendor(vendorID, fromEntityID)
+	if !ok {
+		return nil, errors.NotFound("vendor", vendorID)
+	}
+	if _, exists := s.data.liveVendorByCode(vendorCode, toEntityID); exists {
+		return nil, errors.AlreadyExists("vendor", vendorCode)
+	}
+
+	v.EntityID = toEntityID
+	v.VendorCode = vendorCode
+	v.UpdatedBy = updatedBy
+	v.UpdatedAt = time.Now().UTC()
+	v.ChangeSeq = s.data.nextSeq()
+	s.data.vendors[vendorID] = v
+	return &v, nil
+}
+
+// MoveExternalRefs re-homes a vendor's external system refs to another entity
+func (s *Store) MoveExternalRefs(ctx context.Context, vendorID, toEntityID string) error {
+	defer s.lock()()
+
+	for _, ref := range s.data.externalRefs {
+		if ref.VendorID != vendorID {
+			continue
+		}
+		for _, other := range s.data.externalRefs {
+			if other.VendorID != vendorID && other.EntityID == toEntityID && other.System == ref.System && other.ExternalID == ref.ExternalID {
+				return errors.AlreadyExists("external_ref", "an external ID of the vendor is already mapped in the target entity")
+			}
+		}
+	}
+
+	for id, ref := range s.data.externalRefs {
+		if ref.VendorID == vendorID {
+			ref.EntityID = toEntityID
+			s.data.externalRefs[id] = ref
+		}
+	}
+	return nil
+}
+
+// MoveBalanceLedger re-homes a vendor's balance ledger entries to another entity
+func (s *Store) MoveBalanceLedger(ctx context.Context, vendorID, toEntityID string) error {
+	defer s.lock()()
+
+	for i := range s.data.ledger {
+		if s.data.ledger[i].vendorID == vendorID {
+			s.data.ledger[i].entityID = toEntityID
+		}
+	}
+	return nil
+}
+
+// InsertChangeTombstone records in the change feed of entityID that a vendor
+// left the entity without being deleted
+func (s *Store) InsertChangeTombstone(ctx context.Context, entityID, vendorID, vendorCode string) error {
+	defer s.lock()()
+
+	s.data.tombstones = append(s.data.tombstones, tombstone{
+		entityID:   entityID,
+		vendorID:   vendorID,
+		vendorCode: vendorCode,
+		changeSeq:  s.data.nextSeq(),
+		createdAt:  time.Now().UTC(),
+	})
+	return nil
+}
+
+// InsertAuditEntry appends an entry to the audit log
+func (s *Store) InsertAuditEntry(ctx context.Context, entry *repository.AuditEntry) error {
+	defer s.lock()()
+
+	entry.ID = newID()
+	entry.CreatedAt = time.Now().UTC()
+	s.data.auditLog = append(s.data.auditLog, *entry)
+	return nil
+}
+
+// ListAuditEntries retrieves the audit entries of an entity with the given
+// action created at or after since, newest first
+func (s *Store) ListAuditEntries(ctx context.Context, entityID, action string, since time.Time, limit int) ([]*repository.AuditEntry, error) {
+	defer s.lock()()
+
+	entries := make([]*repository.AuditEntry, 0)
+	for i := len(s.data.auditLog) - 1; i >= 0 && len(entries) < limit; i-- {
+		entry := s.data.auditLog[i]
+		if entry.EntityID == entityID && entry.Action == action && !entry.CreatedAt.Before(since) {
+			entries = append(entries, &entry)
+		}
+	}
+	return entries, nil
+}
+
+// InsertEvent appends an event to the outbox
+func (s *Store) InsertEvent(ctx context.Context, event *repository.VendorEvent) error {
+	defer s.lock()()
+
+	event.ID = newID()
+	event.CreatedAt = time.Now().UTC()
+	s.data.events = append(s.data.events, *event)
+	return nil
+}
+
+// GetRetentionSettings retrieves the retention settings of an entity, or an
+// empty record when the defaults apply
+func (s *Store) GetRetentionSettings(ctx context.Context, entityID string) (*repository.RetentionSettings, error) {
+	defer s.lock()()
+
+	settings, ok := s.data.retention[entityID]
+	if !ok {
+		return &repository.RetentionSettings{EntityID: entityID}, nil
+	}
+	return &settings, nil
+}
+
+// UpsertRetentionSettings creates or replaces the retention settings of an entity
+func (s *Store) UpsertRetentionSettings(ctx context.Context, settings *repository.RetentionSettings) error {
+	defer s.lock()()
+
+	settings.UpdatedAt = time.Now().UTC()
+	s.data.retention[settings.EntityID] = *settings
+	return nil
+}
+
+// GetValidationSettings retrieves the validation settings of an entity, or an
+// empty record when the defaults apply
+func (s *Store) GetValidationSettings(ctx context.Context, entityID string) (*repository.ValidationSettings, error) {
+	defer s.lock()()
+
+	settings, ok := s.data.validation[entityID]
+	if !ok {
+		return &repository.ValidationSettings{EntityID: entityID}, nil
+	}
+	return &settings, nil
+}
+
+// UpsertValidationSettings creates or replaces the validation settings of an entity
+func (s *Store) UpsertValidationSettings(ctx context.Context, settings *repository.ValidationSettings) error {
+	defer s.lock()()
+
+	settings.UpdatedAt = time.Now().UTC()
+	s.data.validation[settings.EntityID] = *settings
+	return nil
+}
+
+// GetEntitySettings retrieves the settings of an entity, or an empty record
+// when the defaults apply
+func (s *Store) GetEntitySettings(ctx context.Context, entityID string) (*repository.EntitySettings, error) {
+	defer s.lock()()
+
+	settings, ok := s.data.settings[entityID]
+	if !ok {
+		return &repository.EntitySettings{EntityID: entityID}, nil
+	}
+	return &settings, nil
+}
+
+// UpsertEntitySettings creates or replaces the settings of an entity
+func (s *Store) UpsertEntitySettings(ctx context.Context, settings *repository.EntitySettings) error {
+	defer s.lock()()
+
+	settings.UpdatedAt = time.Now().UTC()
+	s.data.settings[settings.EntityID] = *settings
+	return nil
+}
+
+// vendorTypesOf returns the vendor type registry of an entity, copying the
+// defaults to it first when materialize is set
+func (d *state) vendorTypesOf(entityID string, materialize bool) []repository.VendorType {
+	if types, ok := d.vendorTypes[entityID]; ok {
+		return types
+	}
+	types := append([]repository.VendorType(nil), d.vendorTypes[""]...)
+	for i := range types {
+		types[i].EntityID = entityID
+	}
+	if materialize {
+		d.vendorTypes[entityID] = types
+	}
+	return types
+}
+
+// ListVendorTypes retrieves the vendor type registry of an entity ordered by label
+func (s *Store) ListVendorTypes(ctx context.Context, entityID string) ([]*repository.VendorType, error) {
+	defer s.lock()()
+
+	types := make([]*repository.VendorType, 0)
+	for _, vt := range s.data.vendorTypesOf(entityID, false) {
+		vt := vt
+		types = append(types, &vt)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if types[i].Label != types[j].Label {
+			return types[i].Label < types[j].Label
+		}
+		return types[i].Code < types[j].Code
+	})
+	return types, nil
+}
+
+// CreateVendorType adds a vendor type to the registry of an entity
+func (s *Store) CreateVendorType(ctx context.Context, vt *repository.VendorType) error {
+	defer s.lock()()
+
+	types := s.data.vendorTypesOf(vt.EntityID, true)
+	for _, existing := range types {
+		if existing.Code == vt.Code {
+			return errors.AlreadyExists("vendor_type", vt.Code)
+		}
+	}
+
+	now := time.Now().UTC()
+	vt.CreatedAt = now
+	vt.UpdatedAt = now
+	s.data.vendorTypes[vt.EntityID] = append(types, *vt)
+	return nil
+}
+
+// UpdateVendorType changes the label and deprecation of a vendor type of an entity
+func (s *Store) UpdateVendorType(ctx context.Context, vt *repository.VendorType) error {
+	defer s.lock()()
+
+	types := s.data.vendorTypesOf(vt.EntityID, true)
+	for i := range types {
+		if types[i].Code == vt.Code {
+			types[i].Label = vt.Label
+			types[i].IsDeprecated = vt.IsDeprecated
+			types[i].UpdatedBy = vt.UpdatedBy
+			types[i].UpdatedAt = time.Now().UTC()
+			vt.CreatedAt, vt.UpdatedAt = types[i].CreatedAt, types[i].UpdatedAt
+			return nil
+		}
+	}
+	return &repository.NotFoundError{Resource: "vendor_type", ID: vt.Code, Err: errors.NotFound("vendor_type", vt.Code)}
+}
+
+// DeleteVendorType removes a vendor type from the registry of an entity
+func (s *Store) DeleteVendorType(ctx context.Context, entityID, code string) error {
+	defer s.lock()()
+
+	types := s.data.vendorTypesOf(entityID, true)
+	for i := range types {
+		if types[i].Code == code {
+			s.data.vendorTypes[entityID] = append(types[:i:i], types[i+1:]...)
+			return nil
+		}
+	}
+	return &repository.NotFoundError{Resource: "vendor_type", ID: code, Err: errors.NotFound("vendor_type", code)}
+}
+
+// CountVendorsByType counts the non-deleted vendors of an entity with a vendor type
+func (s *Store) CountVendorsByType(ctx context.Context, entityID, code string) (int64, error) {
+	defer s.lock()()
+
+	var count int64
+	for _, v := range s.data.vendors {
+		if v.EntityID == entityID && v.VendorType == code && v.DeletedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// vendorNames returns the names a vendor can be matched on, in MatchedOn order
+func (d *state) vendorNames(v repository.Vendor) []repository.VendorNameMatch {
+	names := []repository.VendorNameMatch{{MatchedOn: repository.MatchedOnVendorName, MatchedName: v.VendorName}}
+	if v.LegalName != nil {
+		names = append(names, repository.VendorNameMatch{MatchedOn: repository.MatchedOnLegalName, MatchedName: *v.LegalName})
+	}
+	if v.DoingBusinessAs != nil {
+		names = append(names, repository.VendorNameMatch{MatchedOn: repository.MatchedOnDoingBusinessAs, MatchedName: *v.DoingBusinessAs})
+	}
+	for _, a := range d.aliases {
+		if a.VendorID == v.ID {
+			names = append(names, repository.VendorNameMatch{MatchedOn: repository.MatchedOnAlias, MatchedName: a.Alias})
+		}
+	}
+	return names
+}
+
+// nameContains mirrors the name filter of VendorFilter's SQL WHERE clause
+func (d *state) nameContains(v repository.Vendor, term string) bool {
+	term = strings.ToLower(term)
+	for _, name := range d.vendorNames(v) {
+		if strings.Contains(strings.ToLower(name.MatchedName), term) {
+			return true
+		}
+	}
+	return false
+}
+
+// InsertVendorAlias stores an alias of a vendor
+func (s *Store) InsertVendorAlias(ctx context.Context, alias *repository.VendorAlias) error {
+	defer s.lock()()
+
+	normalized := repository.NormalizeName(alias.Alias)
+	for _, existing := range s.data.aliases {
+		if existing.VendorID == alias.VendorID && repository.NormalizeName(existing.Alias) == normalized {
+			return errors.AlreadyExists("vendor_alias", alias.Alias)
+		}
+	}
+
+	alias.ID = newID()
+	alias.CreatedAt = time.Now().UTC()
+	s.data.aliases = append(s.data.aliases, *alias)
+	return nil
+}
+
+// ListVendorAliases retrieves the aliases of a vendor ordered by alias
+func (s *Store) ListVendorAliases(ctx context.Context, vendorID string) ([]*repository.VendorAlias, error) {
+	defer s.lock()()
+
+	aliases := make([]*repository.VendorAlias, 0)
+	for _, a := range s.data.aliases {
+		if a.VendorID == vendorID {
+			a := a
+			aliases = append(aliases, &a)
+		}
+	}
+	sort.Slice(aliases, func(i, j int) bool {
+		return repository.NormalizeName(aliases[i].Alias) < repository.NormalizeName(aliases[j].Alias)
+	})
+	return aliases, nil
+}
+
+// DeleteVendorAlias removes an alias of a vendor and returns it
+func (s *Store) DeleteVendorAlias(ctx context.Context, id, vendorID string) (*repository.VendorAlias, error) {
+	defer s.lock()()
+
+	for i, a := range s.data.aliases {
+		if a.ID == id && a.VendorID == vendorID {
+			s.data.aliases = slices.Delete(s.data.aliases, i, i+1)
+			return &a, nil
+		}
+	}
+	return nil, &repository.NotFoundError{Resource: "vendor_alias", ID: id, Err: errors.NotFound("vendor_alias", id)}
+}
+
+// MatchVendorsByName retrieves the non-deleted vendors of an entity with a
+// name or alias equal to name once normalized, ordered by vendor name
+func (s *Store) MatchVendorsByName(ctx context.Context, entityID, name string) ([]*repository.VendorNameMatch, error) {
+	defer s.lock()()
+
+	normalized := repository.NormalizeName(name)
+	matches := make([]*repository.VendorNameMatch, 0)
+	for _, v := range s.data.vendors {
+		if v.EntityID != entityID || v.DeletedAt != nil {
+			continue
+		}
+		for _, candidate := range s.data.vendorNames(v) {
+			if repository.NormalizeName(candidate.MatchedName) == normalized {
+				v := v
+				candidate.Vendor = &v
+				matches = append(matches, &candidate)
+				break
+			}
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Vendor.VendorName != matches[j].Vendor.VendorName {
+			return matches[i].Vendor.VendorName < matches[j].Vendor.VendorName
+		}
+		return matches[i].Vendor.ID < matches[j].Vendor.ID
+	})
+	return matches, nil
+}
+
+// FindVendorMatchCandidates retrieves the non-deleted vendors of an entity
+// with a name similar to the query name or a matching tax ID or bank account,
+// identifier matches first and then by name similarity
+func (s *Store) FindVendorMatchCandidates(ctx context.Context, q repository.VendorMatchQuery) ([]*repository.VendorMatchCandidate, error) {
+	defer s.lock()()
+
+	candidates := make([]*repository.VendorMatchCandidate, 0)
+	for _, v := range s.data.vendors {
+		if v.EntityID != q.EntityID || v.DeletedAt != nil {
+			continue
+		}
+		v := v
+		c := &repository.VendorMatchCandidate{
+			Vendor:     &v,
+			TaxIDMatch: q.TaxIDMatches(&v),
+			BankMatch:  q.BankMatches(&v),
+		}
+		c.NameSimilarity = -1
+		for _, name := range s.data.vendorNames(v) {
+			if similarity := repository.TrigramSimilarity(name.MatchedName, q.Name); similarity > c.NameSimilarity {
+				c.MatchedOn, c.MatchedName, c.NameSimilarity = name.MatchedOn, name.MatchedName, similarity
+			}
+		}
+		if c.NameSimilarity >= q.MinSimilarity || c.TaxIDMatch || c.BankMatch {
+			candidates = append(candidates, c)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if ai, bi := a.TaxIDMatch || a.BankMatch, b.TaxIDMatch || b.BankMatch; ai != bi {
+			return ai
+		}
+		if a.NameSimilarity != b.NameSimilarity {
+			return a.NameSimilarity > b.NameSimilarity
+		}
+		return a.Vendor.ID < b.Vendor.ID
+	})
+	if len(candidates) > q.Limit {
+		candidates = candidates[:q.Limit]
+	}
+	return candidates, nil
+}
+
+// InsertScheduledStatusChange stores a pending scheduled status change
+func (s *Store) InsertScheduledStatusChange(ctx context.Context, sc *repository.ScheduledStatusChange) error {
+	defer s.lock()()
+
+	for _, existing := range s.data.scheduled {
+		if existing.VendorID == sc.VendorID && existing.EffectiveDate == sc.EffectiveDate &&
+			existing.State == repository.ScheduleStatePending {
+			return errors.AlreadyExists("scheduled_status_change", sc.EffectiveDate)
+		}
+	}
+	sc.ID = newID()
+	sc.State = repository.ScheduleStatePending
+	sc.CreatedAt = time.Now().UTC()
+	s.data.scheduled = append(s.data.scheduled, *sc)
+	return nil
+}
+
+// LockScheduledStatusChange retrieves a scheduled status change of an entity
+func (s *Store) LockScheduledStatusChange(ctx context.Context, id, entityID string) (*repository.ScheduledStatusChange, error) {
+	defer s.lock()()
+
+	for _, sc := range s.data.scheduled {
+		if sc.ID == id && sc.EntityID == entityID {
+			return &sc, nil
+		}
+	}
+	return nil, errors.NotFound("scheduled_status_change", id)
+}
+
+// ListScheduledStatusChanges retrieves up to limit scheduled status changes
+// matching filter, by effective date and then creation
+func (s *Store) ListScheduledStatusChanges(ctx context.Context, filter repository.ScheduledStatusChangeFilter, limit int) ([]*repository.ScheduledStatusChange, error) {
+	defer s.lock()()
+
+	changes := make([]*repository.ScheduledStatusChange, 0)
+	for _, sc := range s.data.scheduled {
+		if sc.EntityID != filter.EntityID ||
+			(filter.VendorID != "" && sc.VendorID != filter.VendorID) ||
+			(filter.State != "" && sc.State != filter.State) {
+			continue
+		}
+		changes = append(changes, &sc)
+	}
+	sortScheduledStatusChanges(changes)
+	if len(changes) > limit {
+		changes = changes[:limit]
+	}
+	return changes, nil
+}
+
+// ClaimDueStatusChanges retrieves up to limit pending status changes of any
+// entity effective on or before asOf (YYYY-MM-DD), oldest first
+func (s *Store) ClaimDueStatusChanges(ctx context.Context, asOf string, limit int) ([]*repository.ScheduledStatusChange, error) {
+	defer s.lock()()
+
+	changes := make([]*repository.ScheduledStatusChange, 0)
+	for _, sc := range s.data.scheduled {
+		if sc.State == repository.ScheduleStatePending && sc.EffectiveDate <= asOf {
+			changes = append(changes, &sc)
+		}
+	}
+	sortScheduledStatusChanges(changes)
+	if len(changes) > limit {
+		changes = changes[:limit]
+	}
+	return changes, nil
+}
+
+// FinishScheduledStatusChange records the final state of a scheduled status
+// change, with its failure reason or who cancelled it
+func (s *Store) FinishScheduledStatusChange(ctx context.Context, sc *repository.ScheduledStatusChange) error {
+	defer s.lock()()
+
+	for i := range s.data.scheduled {
+		stored := &s.data.scheduled[i]
+		if stored.ID != sc.ID {
+			continue
+		}
+		now := time.Now().UTC()
+		sc.ProcessedAt = &now
+		stored.State = sc.State
+		stored.FailureReason = sc.FailureReason
+		stored.CancelledBy = sc.CancelledBy
+		stored.ProcessedAt = sc.ProcessedAt
+		return nil
+	}
+	return errors.NotFound("scheduled_status_change", sc.ID)
+}
+
+// sortScheduledStatusChanges orders changes by effective date and then creation
+func sortScheduledStatusChanges(changes []*repository.ScheduledStatusChange) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].EffectiveDate != changes[j].EffectiveDate {
+			return changes[i].EffectiveDate < changes[j].EffectiveDate
+		}
+		return changes[i].CreatedAt.Before(changes[j].CreatedAt)
+	})
+}
+
+// ToggleFavoriteVendor adds a vendor to the favorites of a user in an entity,
+// or removes it when it is one already, and reports whether it is now a
+// favorite
+func (s *Store) ToggleFavoriteVendor(ctx context.Context, userID, entityID, vendorID string) (bool, error) {
+	defer s.lock()()
+
+	for i, f := range s.data.favorites {
+		if f.userID == userID && f.entityID == entityID && f.vendorID == vendorID {
+			s.data.favorites = slices.Delete(s.data.favorites, i, i+1)
+			return false, nil
+		}
+	}
+	s.data.favorites = append(s.data.favorites, userVendor{userID: userID, entityID: entityID, vendorID: vendorID, at: time.Now().UTC()})
+	return true, nil
+}
+
+// ListFavoriteVendors retrieves the non-deleted favorite vendors of a user in
+// an entity ordered by vendor name
+func (s *Store) ListFavoriteVendors(ctx context.Context, userID, entityID string) ([]*repository.Vendor, error) {
+	defer s.lock()()
+
+	vendors := s.data.userVendors(s.data.favorites, userID, entityID)
+	sort.SliceStable(vendors, func(i, j int) bool {
+		if vendors[i].VendorName != vendors[j].VendorName {
+			return vendors[i].VendorName < vendors[j].VendorName
+		}
+		return vendors[i].ID < vendors[j].ID
+	})
+	return vendors, nil
+}
+
+// RecordVendorView marks a vendor as viewed by a user in an entity, keeping
+// only the keep latest views of the user in the entity
+func (s *Store) RecordVendorView(ctx context.Context, userID, entityID, vendorID string, keep int) error {
+	defer s.lock()()
+
+	views := make([]userVendor, 0, len(s.data.views)+1)
+	others := 0
+	// Walk newest first so the oldest views of the user are the ones dropped
+	for i := len(s.data.views) - 1; i >= 0; i-- {
+		v := s.data.views[i]
+		if v.userID == userID && v.entityID == entityID {
+			if v.vendorID == vendorID || others >= keep-1 {
+				continue
+			}
+			others++
+		}
+		views = append(views, v)
+	}
+	slices.Reverse(views)
+	s.data.views = append(views, userVendor{userID: userID, entityID: entityID, vendorID: vendorID, at: time.Now().UTC()})
+	return nil
+}
+
+// ListRecentVendors retrieves the non-deleted vendors a user viewed in an
+// entity, most recently viewed first
+func (s *Store) ListRecentVendors(ctx context.Context, userID, entityID string) ([]*repository.Vendor, error) {
+	defer s.lock()()
+
+	vendors := s.data.userVendors(s.data.views, userID, entityID)
+	slices.Reverse(vendors)
+	return vendors, nil
+}
+
+// userVendors returns the non-deleted vendors of the entries of a user in an
+// entity, in the order of entries
+func (d *state) userVendors(entries []userVendor, userID, entityID string) []*repository.Vendor {
+	vendors := make([]*repository.Vendor, 0)
+	for _, e := range entries {
+		if e.userID != userID || e.entityID != entityID {
+			continue
+		}
+		if v, ok := d.liveVendor(e.vendorID, entityID); ok {
+			vendors = append(vendors, &v)
+		}
+	}
+	return vendors
+}
+
+// copyImportTemplate returns t with its own maps, so that callers cannot
+// change the stored template
+func copyImportTemplate(t repository.ImportTemplate) *repository.ImportTemplate {
+	t.Columns = maps.Clone(t.Columns)
+	t.Defaults = maps.Clone(t.Defaults)
+	transforms := make(map[string][]string, len(t.Transforms))
+	for column, names := range t.Transforms {
+		transforms[column] = slices.Clone(names)
+	}
+	t.Transforms = transforms
+	return &t
+}
+
+// importTemplateNameTaken reports whether another template of the entity has name
+func (d *state) importTemplateNameTaken(t *repository.ImportTemplate) bool {
+	for _, existing := range d.importTemplates {
+		if existing.EntityID == t.EntityID && existing.Name == t.Name && existing.ID != t.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// ListImportTemplates retrieves the import templates of an entity ordered by name
+func (s *Store) ListImportTemplates(ctx context.Context, entityID string) ([]*repository.ImportTemplate, error) {
+	defer s.lock()()
+
+	templates := make([]*repository.ImportTemplate, 0)
+	for _, t := range s.data.importTemplates {
+		if t.EntityID == entityID {
+			templates = append(templates, copyImportTemplate(t))
+		}
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+// GetImportTemplate retrieves an import template of an entity
+func (s *Store) GetImportTemplate(ctx context.Context, id, entityID string) (*repository.ImportTemplate, error) {
+	defer s.lock()()
+
+	t, ok := s.data.importTemplates[id]
+	if !ok || t.EntityID != entityID {
+		return nil, &repository.NotFoundError{Resource: "import_template", ID: id, Err: errors.NotFound("import_template", id)}
+	}
+	return copyImportTemplate(t), nil
+}
+
+// CreateImportTemplate stores a new import template
+func (s *Store) CreateImportTemplate(ctx context.Context, t *repository.ImportTemplate) error {
+	defer s.lock()()
+
+	if s.data.importTemplateNameTaken(t) {
+		return errors.AlreadyExists("import_template", t.Name)
+	}
+
+	now := time.Now().UTC()
+	t.ID = newID()
+	t.UpdatedBy = t.CreatedBy
+	t.CreatedAt, t.UpdatedAt = now, now
+	s.data.importTemplates[t.ID] = *copyImportTemplate(*t)
+	return nil
+}
+
+// UpdateImportTemplate replaces the name and mapping of an import template
+func (s *Store) UpdateImportTemplate(ctx context.Context, t *repository.ImportTemplate) error {
+	defer s.lock()()
+
+	existing, ok := s.data.importTemplates[t.ID]
+	if !ok || existing.EntityID != t.EntityID {
+		return &repository.NotFoundError{Resource: "import_template", ID: t.ID, Err: errors.NotFound("import_template", t.ID)}
+	}
+	if s.data.importTemplateNameTaken(t) {
+		return errors.AlreadyExists("import_template", t.Name)
+	}
+
+	t.CreatedBy, t.CreatedAt = existing.CreatedBy, existing.CreatedAt
+	t.UpdatedAt = time.Now().UTC()
+	s.data.importTemplates[t.ID] = *copyImportTemplate(*t)
+	return nil
+}
+
+// DeleteImportTemplate removes an import template of an entity
+func (s *Store) DeleteImportTemplate(ctx context.Context, id, entityID string) error {
+	defer s.lock()()
+
+	if t, ok := s.data.importTemplates[id]; !ok || t.EntityID != entityID {
+		return &repository.NotFoundError{Resource: "import_template", ID: id, Err: errors.NotFound("import_template", id)}
+	}
+	delete(s.data.importTemplates, id)
+	return nil
+}
+
+// copyVendorTemplate returns t with its own slices, so that callers cannot
+// change the stored template
+func copyVendorTemplate(t repository.VendorTemplate) *repository.VendorTemplate {
+	t.Defaults.AcceptedCurrencies = slices.Clone(t.Defaults.AcceptedCurrencies)
+	t.Defaults.Tags = slices.Clone(t.Defaults.Tags)
+	return &t
+}
+
+// vendorTemplateNameTaken reports whether another vendor template of the entity has name
+func (d *state) vendorTemplateNameTaken(t *repository.VendorTemplate) bool {
+	for _, existing := range d.vendorTemplates {
+		if existing.EntityID == t.EntityID && existing.Name == t.Name && existing.ID != t.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// ListVendorTemplates retrieves the vendor templates of an entity ordered by name
+func (s *Store) ListVendorTemplates(ctx context.Context, entityID string) ([]*repository.VendorTemplate, error) {
+	defer s.lock()()
+
+	templates := make([]*repository.VendorTemplate, 0)
+	for _, t := range s.data.vendorTemplates {
+		if t.EntityID == entityID {
+			templates = append(templates, copyVendorTemplate(t))
+		}
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+// GetVendorTemplate retrieves a vendor template of an entity
+func (s *Store) GetVendorTemplate(ctx context.Context, id, entityID string) (*repository.VendorTemplate, error) {
+	defer s.lock()()
+
+	t, ok := s.data.vendorTemplates[id]
+	if !ok || t.EntityID != entityID {
+		return nil, &repository.NotFoundError{Resource: "vendor_template", ID: id, Err: errors.NotFound("vendor_template", id)}
+	}
+	return copyVendorTemplate(t), nil
+}
+
+// CreateVendorTemplate stores a new vendor template
+func (s *Store) CreateVendorTemplate(ctx context.Context, t *repository.VendorTemplate) error {
+	defer s.lock()()
+
+	if s.data.vendorTemplateNameTaken(t) {
+		return errors.AlreadyExists("vendor_template", t.Name)
+	}
+
+	now := time.Now().UTC()
+	t.ID = newID()
+	t.UpdatedBy = t.CreatedBy
+	t.CreatedAt, t.UpdatedAt = now, now
+	s.data.vendorTemplates[t.ID] = *copyVendorTemplate(*t)
+	return nil
+}
+
+// UpdateVendorTemplate replaces the name and defaults of a vendor template
+func (s *Store) UpdateVendorTemplate(ctx context.Context, t *repository.VendorTemplate) error {
+	defer s.lock()()
+
+	existing, ok := s.data.vendorTemplates[t.ID]
+	if !ok || existing.EntityID != t.EntityID {
+		return &repository.NotFoundError{Resource: "vendor_template", ID: t.ID, Err: errors.NotFound("vendor_template", t.ID)}
+	}
+	if s.data.vendorTemplateNameTaken(t) {
+		return errors.AlreadyExists("vendor_template", t.Name)
+	}
+
+	t.CreatedBy, t.CreatedAt = existing.CreatedBy, existing.CreatedAt
+	t.UpdatedAt = time.Now().UTC()
+	s.data.vendorTemplates[t.ID] = *copyVendorTemplate(*t)
+	return nil
+}
+
+// DeleteVendorTemplate removes a vendor template of an entity. Vendors created
+// from it keep its ID.
+func (s *Store) DeleteVendorTemplate(ctx context.Context, id, entityID string) error {
+	defer s.lock()()
+
+	if t, ok := s.data.vendorTemplates[id]; !ok || t.EntityID != entityID {
+		return &repository.NotFoundError{Resource: "vendor_template", ID: id, Err: errors.NotFound("vendor_template", id)}
+	}
+	delete(s.data.vendorTemplates, id)
+	return nil
+}
+
+// retentionCutoff returns the time before which rows of entityID are past retention
+func (d *state) retentionCutoff(entityID string, defaultDays int, auditLog bool) time.Time {
+	days := defaultDays
+	if settings, ok := d.retention[entityID]; ok {
+		override := settings.DeletedVendorRetentionDays
+		if auditLog {
+			override = settings.AuditLogRetentionDays
+		}
+		if override != nil {
+			days = *override
+		}
+	}
+	return time.Now().UTC().AddDate(0, 0, -days)
+}
+
+// purgeableVendors returns the soft-deleted vendors past retention, split into
+// eligible ones and ones kept because of recent ledger activity
+func (d *state) purgeableVendors(defaultDays int) (eligible, blocked []string) {
+	for id, v := range d.vendors {
+		if v.DeletedAt == nil {
+			continue
+		}
+		cutoff := d.retentionCutoff(v.EntityID, defaultDays, false)
+		if !v.DeletedAt.Before(cutoff) {
+			continue
+		}
+
+		recent := false
+		for _, entry := range d.ledger {
+			if entry.vendorID == id && !entry.createdAt.Before(cutoff) {
+				recent = true
+				break
+			}
+		}
+		if recent {
+			blocked = append(blocked, id)
+		} else {
+			eligible = append(eligible, id)
+		}
+	}
+	return eligible, blocked
+}
+
+// CountPurgeableVendors counts soft-deleted vendors past retention
+func (s *Store) CountPurgeableVendors(ctx context.Context, defaultDays int) (eligible, blocked int64, err error) {
+	defer s.lock()()
+
+	e, b := s.data.purgeableVendors(defaultDays)
+	return int64(len(e)), int64(len(b)), nil
+}
+
+// PurgeDeletedVendors hard-deletes up to batchSize eligible soft-deleted vendors
+// with their contacts, refs and ledger
+func (s *Store) PurgeDeletedVendors(ctx context.Context, defaultDays, batchSize int) (int64, error) {
+	defer s.lock()()
+
+	eligible, _ := s.data.purgeableVendors(defaultDays)
+	if len(eligible) > batchSize {
+		eligible = eligible[:batchSize]
+	}
+
+	for _, id := range eligible {
+		delete(s.data.vendors, id)
+		for contactID, c := range s.data.contacts {
+			if c.VendorID == id {
+				delete(s.data.contacts, contactID)
+			}
+		}
+		for refID, ref := range s.data.externalRefs {
+			if ref.VendorID == id {
+				delete(s.data.externalRefs, refID)
+			}
+		}
+		kept := s.data.ledger[:0]
+		for _, entry := range s.data.ledger {
+			if entry.vendorID != id {
+				kept = append(kept, entry)
+			}
+		}
+		s.data.ledger = kept
+		delete(s.data.approvalBreaches, id)
+		delete(s.data.riskScores, id)
+		delete(s.data.tinMatches, id)
+		s.data.approvals = slices.DeleteFunc(s.data.approvals, func(a repository.VendorApproval) bool {
+			return a.VendorID == id
+		})
+		s.data.apiKeys = slices.DeleteFunc(s.data.apiKeys, func(k repository.VendorAPIKey) bool {
+			return k.VendorID == id
+		})
+		s.data.aliases = slices.DeleteFunc(s.data.aliases, func(a repository.VendorAlias) bool {
+			return a.VendorID == id
+		})
+	}
+
+	return int64(len(eligible)), nil
+}
+
+// CountPurgeableTombstones counts transfer tombstones past retention
+func (s *Store) CountPurgeableTombstones(ctx context.Context, defaultDays int) (int64, error) {
+	defer s.lock()()
+
+	var count int64
+	for _, t := range s.data.tombstones {
+		if t.createdAt.Before(s.data.retentionCutoff(t.entityID, defaultDays, false)) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// PurgeTombstones deletes up to batchSize transfer tombstones past retention
+func (s *Store) PurgeTombstones(ctx context.Context, defaultDays, batchSize int) (int64, error) {
+	defer s.lock()()
+
+	var removed int64
+	kept := s.data.tombstones[:0]
+	for _, t := range s.data.tombstones {
+		if removed < int64(batchSize) && t.createdAt.Before(s.data.retentionCutoff(t.entityID, defaultDays, false)) {
+			removed++
+			continue
+		}
+		kept = append(kept, t)
+	}
+	s.data.tombstones = kept
+	return removed, nil
+}
+
+// CountPurgeableAuditLog counts audit log entries past retention
+func (s *Store) CountPurgeableAuditLog(ctx context.Context, defaultDays int) (int64, error) {
+	defer s.lock()()
+
+	var count int64
+	for _, entry := range s.data.auditLog {
+		if entry.CreatedAt.Before(s.data.retentionCutoff(entry.EntityID, defaultDays, true)) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// PurgeAuditLog deletes up to batchSize audit log entries past retention
+func (s *Store) PurgeAuditLog(ctx context.Context, defaultDays, batchSize int) (int64, error) {
+	defer s.lock()()
+
+	var removed int64
+	kept := s.data.auditLog[:0]
+	for _, entry := range s.data.auditLog {
+		if removed < int64(batchSize) && entry.CreatedAt.Before(s.data.retentionCutoff(entry.EntityID, defaultDays, true)) {
+			removed++
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	s.data.auditLog = kept
+	return removed, nil
+}
+
+// ListVendorRegions retrieves the regions of up to limit live vendors with an
+// ID greater than afterID, ordered by ID. An empty entityID lists all entities.
+func (s *Store) ListVendorRegions(ctx context.Context, entityID, afterID string, limit int) ([]*repository.VendorRegion, error) {
+	defer s.lock()()
+
+	regions := make([]*repository.VendorRegion, 0)
+	for _, v := range s.data.vendors {
+		if v.DeletedAt != nil || (entityID != "" && v.EntityID != entityID) || v.ID <= afterID {
+			continue
+		}
+		regions = append(regions, &repository.VendorRegion{
+			ID:            v.ID,
+			EntityID:      v.EntityID,
+			VendorCode:    v.VendorCode,
+			Country:       v.Country,
+			StateProvince: v.StateProvince,
+		})
+	}
+	sort.Slice(regions, func(i, j int) bool { return regions[i].ID < regions[j].ID })
+	if len(regions) > limit {
+		regions = regions[:limit]
+	}
+	return regions, nil
+}
+
+// UpdateVendorRegion sets the country and state or province of a live vendor
+func (s *Store) UpdateVendorRegion(ctx context.Context, region *repository.VendorRegion) error {
+	defer s.lock()()
+
+	v, ok := s.data.liveVendor(region.ID, region.EntityID)
+	if !ok {
+		return &repository.NotFoundError{Resource: "vendor", ID: region.ID, Err: errors.NotFound("vendor", region.ID)}
+	}
+	v.Country = region.Country
+	v.StateProvince = region.StateProvince
+	v.UpdatedAt = time.Now().UTC()
+	v.ChangeSeq = s.data.nextSeq()
+	s.data.vendors[v.ID] = v
+	return nil
+}
+
+// CountLiveVendors counts the vendors of an entity that are not soft-deleted
+func (s *Store) CountLiveVendors(ctx context.Context, entityID string) (int64, error) {
+	defer s.lock()()
+
+	var count int64
+	for _, v := range s.data.vendors {
+		if v.EntityID == entityID && v.DeletedAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// CountVendorsByStatus counts the live vendors of an entity by status
+func (s *Store) CountVendorsByStatus(ctx context.Context, entityID string) (map[string]int64, error) {
+	defer s.lock()()
+
+	counts := make(map[string]int64)
+	for _, v := range s.data.vendors {
+		if v.EntityID == entityID && v.DeletedAt == nil {
+			counts[v.Status]++
+		}
+	}
+	return counts, nil
+}
+
+// VendorGrowth computes the vendor growth series of an entity like the
+// Postgres store
+func (s *Store) VendorGrowth(ctx context.Context, q repository.GrowthQuery) ([]*repository.GrowthPoint, error) {
+	defer s.lock()()
+
+	points := make([]*repository.GrowthPoint, 0)
+	for start := repository.PeriodStart(q.From, q.Granularity); !start.After(q.To); start = repository.NextPeriod(start, q.Granularity) {
+		point := &repository.GrowthPoint{PeriodStart: start, PeriodEnd: repository.NextPeriod(start, q.Granularity)}
+		for _, v := range s.data.vendors {
+			if v.EntityID != q.EntityID || !v.CreatedAt.Before(point.PeriodEnd) {
+				continue
+			}
+			if !v.CreatedAt.Before(point.PeriodStart) {
+				point.Created++
+			}
+			if v.DeletedAt != nil && v.DeletedAt.Before(point.PeriodEnd) {
+				continue
+			}
+			point.Total++
+			if s.data.statusAt(v, point.PeriodEnd) == "active" {
+				point.Active++
+			}
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// CountVendorsByRegion groups the vendors matching filter like the Postgres
+// store
+func (s *Store) CountVendorsByRegion(ctx context.Context, filter repository.VendorFilter) ([]*repository.RegionCount, error) {
+	defer s.lock()()
+
+	type regionKey struct {
+		country, state, currency string
+	}
+	byKey := make(map[regionKey]*repository.RegionCount)
+	for _, v := range s.data.vendors {
+		if !matchesFilter(v, filter) {
+			continue
+		}
+		key := regionKey{country: strings.TrimSpace(v.Country), currency: v.Currency}
+		if key.country == "" {
+			key.country = repository.UnknownCountry
+		}
+		if v.StateProvince != nil {
+			key.state = strings.TrimSpace(*v.StateProvince)
+		}
+
+		count, ok := byKey[key]
+		if !ok {
+			count = &repository.RegionCount{Country: key.country, Currency: key.currency}
+			if key.state != "" {
+				count.StateProvince = &key.state
+			}
+			byKey[key] = count
+		}
+		count.Vendors++
+		count.Balance += v.CurrentBalance
+	}
+
+	counts := slices.Collect(maps.Values(byKey))
+	sort.Slice(counts, func(i, j int) bool {
+		a, b := counts[i], counts[j]
+		if a.Country != b.Country {
+			return a.Country < b.Country
+		}
+		if (a.StateProvince == nil) != (b.StateProvince == nil) {
+			return b.StateProvince == nil
+		}
+		if a.StateProvince != nil && *a.StateProvince != *b.StateProvince {
+			return *a.StateProvince < *b.StateProvince
+		}
+		return a.Currency < b.Currency
+	})
+	return counts, nil
+}
+
+// CountVendorsByPaymentMix groups the vendors matching filter like the
+// Postgres store
+func (s *Store) CountVendorsByPaymentMix(ctx context.Context, filter repository.VendorFilter) ([]*repository.PaymentMixCount, error) {
+	defer s.lock()()
+
+	type mixKey struct {
+		dimension, value, currency string
+	}
+	byKey := make(map[mixKey]*repository.PaymentMixCount)
+	add := func(dimension string, value *string, v repository.Vendor) {
+		key := mixKey{dimension: dimension, currency: v.Currency}
+		if value != nil {
+			key.value = strings.TrimSpace(*value)
+		}
+		count, ok := byKey[key]
+		if !ok {
+			count = &repository.PaymentMixCount{Dimension: dimension, Currency: key.currency}
+			if key.value != "" {
+				count.Value = &key.value
+			}
+			byKey[key] = count
+		}
+		count.Vendors++
+		count.Balance += v.CurrentBalance
+	}
+	for _, v := range s.data.vendors {
+		if !matchesFilter(v, filter) {
+			continue
+		}
+		add(repository.PaymentMixTerms, &v.PaymentTerms, v)
+		add(repository.PaymentMixMethod, v.PaymentMethod, v)
+	}
+
+	counts := slices.Collect(maps.Values(byKey))
+	sort.Slice(counts, func(i, j int) bool {
+		a, b := counts[i], counts[j]
+		if a.Dimension != b.Dimension {
+			return a.Dimension > b.Dimension
+		}
+		if (a.Value == nil) != (b.Value == nil) {
+			return b.Value == nil
+		}
+		if a.Value != nil && *a.Value != *b.Value {
+			return *a.Value < *b.Value
+		}
+		return a.Currency < b.Currency
+	})
+	return counts, nil
+}
+
+// statusAt returns the status of a vendor just before t from its status
+// changes in the audit log, falling back to its current status
+func (d *state) statusAt(v repository.Vendor, t time.Time) string {
+	var last, next *repository.AuditEntry
+	for i := range d.auditLog {
+		entry := &d.auditLog[i]
+		if entry.EntityID != v.EntityID || entry.VendorID != v.ID || entry.Action != "status_changed" {
+			continue
+		}
+		if entry.CreatedAt.Before(t) {
+			if last == nil || !entry.CreatedAt.Before(last.CreatedAt) {
+				last = entry
+			}
+		} else if next == nil || entry.CreatedAt.Before(next.CreatedAt) {
+			next = entry
+		}
+	}
+
+	switch {
+	case last != nil:
+		status, _ := last.Details["to"].(string)
+		return status
+	case next != nil:
+		status, _ := next.Details["from"].(string)
+		return status
+	default:
+		return v.Status
+	}
+}
+
+// ListTagCounts counts the live vendors of an entity by tag, most used first
+func (s *Store) ListTagCounts(ctx context.Context, entityID string) ([]*repository.TagCount, error) {
+	defer s.lock()()
+
+	byTag := make(map[string]int64)
+	for _, v := range s.data.vendors {
+		if v.EntityID == entityID && v.DeletedAt == nil {
+			for _, tag := range v.Tags {
+				byTag[tag]++
+			}
+		}
+	}
+
+	counts := make([]*repository.TagCount, 0, len(byTag))
+	for tag, count := range byTag {
+		counts = append(counts, &repository.TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Tag < counts[j].Tag
+	})
+	return counts, nil
+}
+
+// RenameTag replaces tag from with to on every live vendor of an entity.
+// Vendors already tagged to just lose from.
+func (s *Store) RenameTag(ctx context.Context, entityID, from, to string, updatedBy *string) ([]*repository.TaggedVendor, error) {
+	return s.updateTags(entityID, from, updatedBy, func(tags []string) []string {
+		if slices.Contains(tags, to) {
+			return slices.DeleteFunc(tags, func(tag string) bool { return tag == from })
+		}
+		for i, tag := range tags {
+			if tag == from {
+				tags[i] = to
+			}
+		}
+		return tags
+	})
+}
+
+// DeleteTag removes a tag from every live vendor of an entity
+func (s *Store) DeleteTag(ctx context.Context, entityID, tag string, updatedBy *string) ([]*repository.TaggedVendor, error) {
+	return s.updateTags(entityID, tag, updatedBy, func(tags []string) []string {
+		return slices.DeleteFunc(tags, func(t string) bool { return t == tag })
+	})
+}
+
+// AddTag adds a tag to the live vendors of an entity with the given IDs.
+// Vendors already tagged, or carrying maxTags tags, are left as they are.
+func (s *Store) AddTag(ctx context.Context, entityID string, vendorIDs []string, tag string, maxTags int, updatedBy *string) ([]*repository.TaggedVendor, error) {
+	defer s.lock()()
+
+	now := time.Now().UTC()
+	vendors := make([]*repository.TaggedVendor, 0)
+	for _, id := range vendorIDs {
+		v, ok := s.data.liveVendor(id, entityID)
+		if !ok || slices.Contains(v.Tags, tag) || len(v.Tags) >= maxTags {
+			continue
+		}
+		v.Tags = append(slices.Clone(v.Tags), tag)
+		v.UpdatedBy = updatedBy
+		v.UpdatedAt = now
+		v.ChangeSeq = s.data.nextSeq()
+		s.data.vendors[id] = v
+		vendors = append(vendors, &repository.TaggedVendor{ID: v.ID, VendorCode: v.VendorCode})
+	}
+	return vendors, nil
+}
+
+// updateTags applies change to a copy of the tags of every live vendor of an
+// entity carrying tag
+func (s *Store) updateTags(entityID, tag string, updatedBy *string, change func([]string) []string) ([]*repository.TaggedVendor, error) {
+	defer s.lock()()
+
+	now := time.Now().UTC()
+	vendors := make([]*repository.TaggedVendor, 0)
+	for id, v := range s.data.vendors {
+		if v.EntityID != entityID || v.DeletedAt != nil || !slices.Contains(v.Tags, tag) {
+			continue
+		}
+		v.Tags = change(slices.Clone(v.Tags))
+		v.UpdatedBy = updatedBy
+		v.UpdatedAt = now
+		v.ChangeSeq = s.data.nextSeq()
+		s.data.vendors[id] = v
+		vendors = append(vendors, &repository.TaggedVendor{ID: v.ID, VendorCode: v.VendorCode})
+	}
+	return vendors, nil
+}
+
+// ListDocuments returns no documents; the memory store does not hold any
+func (s *Store) ListDocuments(ctx context.Context, vendorID string, limit int) ([]*repository.VendorDocument, error) {
+	return make([]*repository.VendorDocument, 0), nil
+}
+
+// GetDocument finds no document; the memory store does not hold any
+func (s *Store) GetDocument(ctx context.Context, id, entityID string) (*repository.VendorDocument, error) {
+	return nil, errors.NotFound("document", id)
+}
+
+// AddDocument discards the document; the memory store does not hold any
+func (s *Store) AddDocument(ctx context.Context, doc *repository.VendorDocument) error {
+	doc.ID = newID()
+	return nil
+}
+
+// ListChildCounts counts the contacts of vendors by vendor ID; the memory
+// store holds no documents
+func (s *Store) ListChildCounts(ctx context.Context, vendorIDs []string, expiringBy time.Time) (map[string]*repository.VendorChildCounts, error) {
+	defer s.lock()()
+
+	wanted := make(map[string]bool, len(vendorIDs))
+	for _, id := range vendorIDs {
+		wanted[id] = true
+	}
+	counts := make(map[string]*repository.VendorChildCounts)
+	for _, c := range s.data.contacts {
+		if !wanted[c.VendorID] {
+			continue
+		}
+		if counts[c.VendorID] == nil {
+			counts[c.VendorID] = &repository.VendorChildCounts{}
+		}
+		counts[c.VendorID].Contacts++
+	}
+	return counts, nil
+}
+
+// ListBalanceTransactions retrieves the latest limit balance ledger entries of
+// a vendor, newest first
+func (s *Store) ListBalanceTransactions(ctx context.Context, vendorID, entityID string, limit int) ([]*repository.BalanceTransaction, error) {
+	defer s.lock()()
+
+	transactions := make([]*repository.BalanceTransaction, 0)
+	for i := len(s.data.ledger) - 1; i >= 0 && len(transactions) < limit; i-- {
+		entry := s.data.ledger[i]
+		if entry.vendorID == vendorID && entry.entityID == entityID {
+			transactions = append(transactions, &repository.BalanceTransaction{
+				ID:           entry.id,
+				VendorID:     entry.vendorID,
+				EntityID:     entry.entityID,
+				Amount:       entry.amount,
+				BalanceAfter: entry.balanceAfter,
+				Kind:         entry.kind,
+				CreatedAt:    entry.createdAt,
+			})
+		}
+	}
+	return transactions, nil
+}
+
+// ListVendorAuditEntries retrieves the latest limit audit entries of a vendor,
+// newest first
+func (s *Store) ListVendorAuditEntries(ctx context.Context, vendorID, entityID string, limit int) ([]*repository.AuditEntry, error) {
+	defer s.lock()()
+
+	entries := make([]*repository.AuditEntry, 0)
+	for i := len(s.data.auditLog) - 1; i >= 0 && len(entries) < limit; i-- {
+		entry := s.data.auditLog[i]
+		if entry.EntityID == entityID && entry.VendorID == vendorID {
+			entries = append(entries, &entry)
+		}
+	}
+	return entries, nil
+}
+
+// ListAuditActivity retrieves audit entries of a vendor in rng, restricted to
+// actions unless empty
+func (s *Store) ListAuditActivity(ctx context.Context, vendorID, entityID string, actions []string, rng repository.ActivityRange) ([]*repository.AuditEntry, error) {
+	defer s.lock()()
+
+	entries := make([]*repository.AuditEntry, 0)
+	for _, entry := range s.data.auditLog {
+		if entry.EntityID != entityID || entry.VendorID != vendorID || !rng.Includes(entry.CreatedAt, entry.ID) {
+			continue
+		}
+		if len(actions) > 0 && !slices.Contains(actions, entry.Action) {
+			continue
+		}
+		entry := entry
+		entries = append(entries, &entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return newerActivity(entries[i].CreatedAt, entries[i].ID, entries[j].CreatedAt, entries[j].ID)
+	})
+	if len(entries) > rng.Limit {
+		entries = entries[:rng.Limit]
+	}
+	return entries, nil
+}
+
+// ListBalanceActivity retrieves balance ledger entries of a vendor in rng
+func (s *Store) ListBalanceActivity(ctx context.Context, vendorID, entityID string, rng repository.ActivityRange) ([]*repository.BalanceTransaction, error) {
+	defer s.lock()()
+
+	transactions := make([]*repository.BalanceTransaction, 0)
+	for _, entry := range s.data.ledger {
+		if entry.vendorID != vendorID || entry.entityID != entityID || !rng.Includes(entry.createdAt, entry.id) {
+			continue
+		}
+		transactions = append(transactions, &repository.BalanceTransaction{
+			ID:           entry.id,
+			VendorID:     entry.vendorID,
+			EntityID:     entry.entityID,
+			Amount:       entry.amount,
+			BalanceAfter: entry.balanceAfter,
+			Kind:         entry.kind,
+			CreatedAt:    entry.createdAt,
+		})
+	}
+	sort.Slice(transactions, func(i, j int) bool {
+		return newerActivity(transactions[i].CreatedAt, transactions[i].ID, transactions[j].CreatedAt, transactions[j].ID)
+	})
+	if len(transactions) > rng.Limit {
+		transactions = transactions[:rng.Limit]
+	}
+	return transactions, nil
+}
+
+// ListDocumentActivity returns no uploads; the memory store does not hold documents
+func (s *Store) ListDocumentActivity(ctx context.Context, vendorID string, rng repository.ActivityRange) ([]*repository.DocumentUpload, error) {
+	return make([]*repository.DocumentUpload, 0), nil
+}
+
+// newerActivity orders activity newest first, by ID descending within the same time
+func newerActivity(at time.Time, id string, otherAt time.Time, otherID string) bool {
+	if !at.Equal(otherAt) {
+		return at.After(otherAt)
+	}
+	return id > otherID
+}
+
+// pendingApproval returns the live pending_approval vendors of entityID, or of
+// all entities when it is empty, oldest first
+func (d *state) pendingApproval(entityID string) []repository.Vendor {
+	var pending []repository.Vendor
+	for _, v := range d.vendors {
+		if v.Status == "pending_approval" && v.DeletedAt == nil && (entityID == "" || v.EntityID == entityID) {
+			pending = append(pending, v)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		if !pending[i].CreatedAt.Equal(pending[j].CreatedAt) {
+			return pending[i].CreatedAt.Before(pending[j].CreatedAt)
+		}
+		return pending[i].ID < pending[j].ID
+	})
+	return pending
+}
+
+// ListPendingApproval retrieves the pending_approval vendors of an entity by
+// age, oldest first unless newestFirst, with their total count
+func (s *Store) ListPendingApproval(ctx context.Context, entityID string, newestFirst bool, limit, offset int) ([]*repository.Vendor, int64, error) {
+	defer s.lock()()
+
+	pending := s.data.pendingApproval(entityID)
+	if newestFirst {
+		slices.Reverse(pending)
+	}
+
+	vendors := make([]*repository.Vendor, 0)
+	for i := offset; i < len(pending) && len(vendors) < limit; i++ {
+		v := pending[i]
+		vendors = append(vendors, &v)
+	}
+	return vendors, int64(len(pending)), nil
+}
+
+// CountPendingApproval counts the pending_approval vendors of an entity, and
+// those among them created before overdueBefore
+func (s *Store) CountPendingApproval(ctx context.Context, entityID string, overdueBefore time.Time) (total, overdue int64, err error) {
+	defer s.lock()()
+
+	for _, v := range s.data.pendingApproval(entityID) {
+		total++
+		if v.CreatedAt.Before(overdueBefore) {
+			overdue++
+		}
+	}
+	return total, overdue, nil
+}
+
+// ClaimApprovalSLABreaches records up to limit pending_approval vendors
+// created before createdBefore whose breach was not recorded yet, and returns them
+func (s *Store) ClaimApprovalSLABreaches(ctx context.Context, createdBefore time.Time, limit int) ([]*repository.Vendor, error) {
+	defer s.lock()()
+
+	vendors := make([]*repository.Vendor, 0)
+	for _, v := range s.data.pendingApproval("") {
+		if len(vendors) == limit || !v.CreatedAt.Before(createdBefore) {
+			break
+		}
+		if _, ok := s.data.approvalBreaches[v.ID]; ok {
+			continue
+		}
+		s.data.approvalBreaches[v.ID] = time.Now().UTC()
+		vendors = append(vendors, &v)
+	}
+	return vendors, nil
+}
+
+// ClearApprovalSLABreach forgets the recorded SLA breach of a vendor
+func (s *Store) ClearApprovalSLABreach(ctx context.Context, vendorID string) error {
+	defer s.lock()()
+
+	delete(s.data.approvalBreaches, vendorID)
+	return nil
+}
+
+// GetApprovalPolicy retrieves the approval policy of an entity, requiring a
+// single approval when it has none
+func (s *Store) GetApprovalPolicy(ctx context.Context, entityID string) (*repository.ApprovalPolicy, error) {
+	defer s.lock()()
+
+	if policy, ok := s.data.approvalPolicies[entityID]; ok {
+		return &policy, nil
+	}
+	return &repository.ApprovalPolicy{EntityID: entityID, RequiredApprovals: 1}, nil
+}
+
+// UpsertApprovalPolicy creates or replaces the approval policy of an entity,
+// advancing the change_seq of the vendors pending approval whose required
+// approvals it changes
+func (s *Store) UpsertApprovalPolicy(ctx context.Context, policy *repository.ApprovalPolicy) error {
+	defer s.lock()()
+
+	previous, ok := s.data.approvalPolicies[policy.EntityID]
+	if !ok {
+		previous = repository.ApprovalPolicy{EntityID: policy.EntityID, RequiredApprovals: 1}
+	}
+
+	policy.UpdatedAt = time.Now().UTC()
+	s.data.approvalPolicies[policy.EntityID] = *policy
+	for id, v := range s.data.vendors {
+		if v.EntityID == policy.EntityID && v.Status == "pending_approval" && previous.RequiredFor(&v) != policy.RequiredFor(&v) {
+			s.data.touchVendor(id)
+		}
+	}
+	return nil
+}
+
+// LockVendorApprovals does nothing; the store serializes all access
+func (s *Store) LockVendorApprovals(ctx context.Context, vendorID string) error {
+	return nil
+}
+
+// ListVendorApprovals retrieves the approvals of vendors by vendor ID, oldest first
+func (s *Store) ListVendorApprovals(ctx context.Context, vendorIDs []string) (map[string][]*repository.VendorApproval, error) {
+	defer s.lock()()
+
+	approvals := make(map[string][]*repository.VendorApproval, len(vendorIDs))
+	for _, approval := range s.data.approvals {
+		if slices.Contains(vendorIDs, approval.VendorID) {
+			approval := approval
+			approvals[approval.VendorID] = append(approvals[approval.VendorID], &approval)
+		}
+	}
+	return approvals, nil
+}
+
+// InsertVendorApproval records an approval of a vendor, advancing the
+// vendor's change_seq
+func (s *Store) InsertVendorApproval(ctx context.Context, approval *repository.VendorApproval) error {
+	defer s.lock()()
+
+	approval.ID = newID()
+	approval.CreatedAt = time.Now().UTC()
+	s.data.approvals = append(s.data.approvals, *approval)
+	s.data.touchVendor(approval.VendorID)
+	return nil
+}
+
+// DeleteVendorApprovals removes the approvals of a vendor
+func (s *Store) DeleteVendorApprovals(ctx context.Context, vendorID string) error {
+	defer s.lock()()
+
+	kept := s.data.approvals[:0]
+	for _, approval := range s.data.approvals {
+		if approval.VendorID != vendorID {
+			kept = append(kept, approval)
+		}
+	}
+	s.data.approvals = kept
+	s.data.touchVendor(vendorID)
+	return nil
+}
+
+// GetRiskWeights retrieves the default risk rule weights overridden by the
+// entity's own
+func (s *Store) GetRiskWeights(ctx context.Context, entityID string) (map[string]int, error) {
+	defer s.lock()()
+
+	weights := maps.Clone(s.data.riskWeights[""])
+	for factor, weight := range s.data.riskWeights[entityID] {
+		weights[factor] = weight
+	}
+	return weights, nil
+}
+
+// ReplaceRiskWeights replaces the weights an entity overrides with weights
+func (s *Store) ReplaceRiskWeights(ctx context.Context, entityID string, weights map[string]int, updatedBy *string) error {
+	defer s.lock()()
+
+	s.data.riskWeights[entityID] = maps.Clone(weights)
+	return nil
+}
+
+// dataQualityRules looks up data quality rules by name, rejecting unknown ones
+func dataQualityRules(names []string) ([]repository.DataQualityRule, error) {
+	rules := make([]repository.DataQualityRule, len(names))
+	for i, name := range names {
+		rule, ok := repository.DataQualityRuleByName(name)
+		if !ok {
+			return nil, errors.InvalidInput("rule", fmt.Sprintf("unknown data quality rule %q", name))
+		}
+		rules[i] = rule
+	}
+	return rules, nil
+}
+
+// dataQualitySignals returns the data quality signals of a vendor. The memory
+// store holds no documents, so no vendor has a W-9 on file.
+func (d *state) dataQualitySignals(v *repository.Vendor) *repository.DataQualitySignals {
+	signals := &repository.DataQualitySignals{}
+	for _, c := range d.contacts {
+		if c.VendorID == v.ID {
+			signals.HasContact = true
+			break
+		}
+	}
+	for _, t := range d.paymentTerms {
+		if strings.EqualFold(t.Code, v.PaymentTerms) {
+			signals.InactivePaymentTerm = !t.IsActive
+			break
+		}
+	}
+	return signals
+}
+
+// CountDataQualityIssues counts the live vendors of an entity failing each of
+// the rules
+func (s *Store) CountDataQualityIssues(ctx context.Context, entityID string, names []string) (map[string]int64, error) {
+	defer s.lock()()
+
+	rules, err := dataQualityRules(names)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(rules))
+	for _, rule := range rules {
+		counts[rule.Name] = 0
+	}
+	for _, v := range s.data.vendors {
+		if v.EntityID != entityID || v.DeletedAt != nil {
+			continue
+		}
+		signals := s.data.dataQualitySignals(&v)
+		for _, rule := range rules {
+			if rule.Fails(&v, signals) {
+				counts[rule.Name]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+// ListDataQualityOffenders retrieves a page of the live vendors of an entity
+// failing a rule, ordered by vendor code
+func (s *Store) ListDataQualityOffenders(ctx context.Context, entityID, name string, limit, offset int) ([]*repository.DataQualityOffender, error) {
+	defer s.lock()()
+
+	rules, err := dataQualityRules([]string{name})
+	if err != nil {
+		return nil, err
+	}
+	var matching []*repository.DataQualityOffender
+	for _, v := range s.data.vendors {
+		if v.EntityID != entityID || v.DeletedAt != nil || !rules[0].Fails(&v, s.data.dataQualitySignals(&v)) {
+			continue
+		}
+		matching = append(matching, &repository.DataQualityOffender{ID: v.ID, VendorCode: v.VendorCode, VendorName: v.VendorName, Status: v.Status})
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		if matching[i].VendorCode != matching[j].VendorCode {
+			return matching[i].VendorCode < matching[j].VendorCode
+		}
+		return matching[i].ID < matching[j].ID
+	})
+
+	offenders := make([]*repository.DataQualityOffender, 0)
+	for i := offset; i < len(matching) && i < offset+limit; i++ {
+		offenders = append(offenders, matching[i])
+	}
+	return offenders, nil
+}
+
+// ListFailedDataQualityRules retrieves the rules each of vendors fails, by
+// vendor ID, in the order of rules
+func (s *Store) ListFailedDataQualityRules(ctx context.Context, vendorIDs, names []string) (map[string][]string, error) {
+	defer s.lock()()
+
+	rules, err := dataQualityRules(names)
+	if err != nil {
+		return nil, err
+	}
+	failed := make(map[string][]string, len(vendorIDs))
+	for _, id := range vendorIDs {
+		v, ok := s.data.vendors[id]
+		if !ok {
+			continue
+		}
+		signals := s.data.dataQualitySignals(&v)
+		failed[id] = make([]string, 0)
+		for _, rule := range rules {
+			if rule.Fails(&v, signals) {
+				failed[id] = append(failed[id], rule.Name)
+			}
+		}
+	}
+	return failed, nil
+}
+
+// ListSuspectedTestVendors retrieves a page of the live vendors of an entity
+// flagged as probable test vendors, ordered by vendor code, with how many
+// there are
+func (s *Store) ListSuspectedTestVendors(ctx context.Context, entityID string, limit, offset int) ([]*repository.SuspectedTestVendor, int64, error) {
+	defer s.lock()()
+
+	active := make(map[string]bool)
+	for _, entry := range s.data.ledger {
+		active[entry.vendorID] = true
+	}
+
+	var matching []*repository.SuspectedTestVendor
+	for _, v := range s.data.vendors {
+		if v.EntityID != entityID || v.DeletedAt != nil {
+			continue
+		}
+		reasons := repository.SuspectedTestReasons(&v, &repository.SuspectedTestSignals{HasBalanceActivity: active[v.ID]})
+		if reasons == nil {
+			continue
+		}
+		matching = append(matching, &repository.SuspectedTestVendor{
+			ID: v.ID, VendorCode: v.VendorCode, VendorName: v.VendorName, Status: v.Status, Reasons: reasons,
+		})
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		if matching[i].VendorCode != matching[j].VendorCode {
+			return matching[i].VendorCode < matching[j].VendorCode
+		}
+		return matching[i].ID < matching[j].ID
+	})
+
+	vendors := make([]*repository.SuspectedTestVendor, 0)
+	for i := offset; i < len(matching) && i < offset+limit; i++ {
+		vendors = append(vendors, matching[i])
+	}
+	return vendors, int64(len(matching)), nil
+}
+
+// ListRiskSignals retrieves the risk signals of vendors by vendor ID. The
+// memory store holds no documents, so no vendor has a W-9 on file.
+func (s *Store) ListRiskSignals(ctx context.Context, vendorIDs []string) (map[string]*repository.RiskSignals, error) {
+	defer s.lock()()
+
+	signals := make(map[string]*repository.RiskSignals, len(vendorIDs))
+	for _, id := range vendorIDs {
+		v, ok := s.data.vendors[id]
+		if !ok {
+			continue
+		}
+		signal := &repository.RiskSignals{}
+		for _, entry := range s.data.auditLog {
+			if entry.VendorID == id && entry.EntityID == v.EntityID && entry.Action == "bank_details_changed" &&
+				(signal.LastBankChange == nil || entry.CreatedAt.After(*signal.LastBankChange)) {
+				changedAt := entry.CreatedAt
+				signal.LastBankChange = &changedAt
+			}
+		}
+		signals[id] = signal
+	}
+	return signals, nil
+}
+
+// ListOnboardingSignals retrieves the onboarding signals of vendors by vendor
+// ID. The memory store holds no documents, so no vendor has any on file.
+func (s *Store) ListOnboardingSignals(ctx context.Context, vendorIDs []string) (map[string]*repository.OnboardingSignals, error) {
+	defer s.lock()()
+
+	signals := make(map[string]*repository.OnboardingSignals, len(vendorIDs))
+	for _, id := range vendorIDs {
+		if _, ok := s.data.vendors[id]; ok {
+			signals[id] = &repository.OnboardingSignals{}
+		}
+	}
+	return signals, nil
+}
+
+// UpsertRiskScore stores the risk score of a vendor, advancing the vendor's
+// change_seq when the score or factors changed
+func (s *Store) UpsertRiskScore(ctx context.Context, score *repository.RiskScore) error {
+	defer s.lock()()
+
+	previous, exists := s.data.riskScores[score.VendorID]
+	stored := *score
+	stored.Factors = append([]repository.RiskFactor(nil), score.Factors...)
+	s.data.riskScores[score.VendorID] = stored
+	if !exists || previous.Score != score.Score || !slices.Equal(previous.Factors, score.Factors) {
+		s.data.touchVendor(score.VendorID)
+	}
+	return nil
+}
+
+// ListRiskScores retrieves the stored risk scores of vendors by vendor ID
+func (s *Store) ListRiskScores(ctx context.Context, vendorIDs []string) (map[string]*repository.RiskScore, error) {
+	defer s.lock()()
+
+	scores := make(map[string]*repository.RiskScore, len(vendorIDs))
+	for _, id := range vendorIDs {
+		if score, ok := s.data.riskScores[id]; ok {
+			scores[id] = &score
+		}
+	}
+	return scores, nil
+}
+
+// UpsertTINMatch stores the TIN matching result of a vendor, advancing the
+// vendor's change_seq when its status or fingerprint changed
+func (s *Store) UpsertTINMatch(ctx context.Context, match *repository.TINMatch) error {
+	defer s.lock()()
+
+	previous, exists := s.data.tinMatches[match.VendorID]
+	s.data.tinMatches[match.VendorID] = *match
+	if !exists || previous.Status != match.Status || previous.Fingerprint != match.Fingerprint {
+		s.data.touchVendor(match.VendorID)
+	}
+	return nil
+}
+
+// ListTINMatches retrieves the stored TIN matching results of vendors by
+// vendor ID
+func (s *Store) ListTINMatches(ctx context.Context, vendorIDs []string) (map[string]*repository.TINMatch, error) {
+	defer s.lock()()
+
+	matches := make(map[string]*repository.TINMatch, len(vendorIDs))
+	for _, id := range vendorIDs {
+		if match, ok := s.data.tinMatches[id]; ok {
+			matches[id] = &match
+		}
+	}
+	return matches, nil
+}
+
+// UpsertBankVerification stores the bank account verification of a vendor,
+// advancing the vendor's change_seq when its status, method or verified_at
+// changed
+func (s *Store) UpsertBankVerification(ctx context.Context, verification *repository.BankVerification) error {
+	defer s.lock()()
+
+	previous, exists := s.data.bankVerifications[verification.VendorID]
+	s.data.bankVerifications[verification.VendorID] = *verification
+	if !exists || previous.Status != verification.Status || previous.Method != verification.Method ||
+		!sameTime(previous.VerifiedAt, verification.VerifiedAt) {
+		s.data.touchVendor(verification.VendorID)
+	}
+	return nil
+}
+
+// sameTime reports whether a and b are both unset or the same instant
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// ListBankVerifications retrieves the stored bank account verifications of
+// vendors by vendor ID
+func (s *Store) ListBankVerifications(ctx context.Context, vendorIDs []string) (map[string]*repository.BankVerification, error) {
+	defer s.lock()()
+
+	verifications := make(map[string]*repository.BankVerification, len(vendorIDs))
+	for _, id := range vendorIDs {
+		if verification, ok := s.data.bankVerifications[id]; ok {
+			verifications[id] = &verification
+		}
+	}
+	return verifications, nil
+}
+
+// FindVendorsByBankLast4 retrieves up to limit non-deleted vendors of an
+// entity whose bank account number or IBAN last four digits equal last4,
+// ordered by vendor code
+func (s *Store) FindVendorsByBankLast4(ctx context.Context, entityID, last4 string, limit int) ([]*repository.BankLast4Match, error) {
+	defer s.lock()()
+
+	matches := make([]*repository.BankLast4Match, 0)
+	for _, v := range s.data.vendors {
+		if v.EntityID != entityID || v.DeletedAt != nil {
+			continue
+		}
+		m := &repository.BankLast4Match{
+			VendorID:     v.ID,
+			VendorCode:   v.VendorCode,
+			VendorName:   v.VendorName,
+			Status:       v.Status,
+			BankName:     v.BankName,
+			AccountLast4: repository.BankLast4(v.BankAccountNumber),
+			IBANLast4:    repository.BankLast4(v.IBAN),
+		}
+		if m.MatchedOn = repository.MatchedBankLast4(m, last4); len(m.MatchedOn) > 0 {
+			matches = append(matches, m)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].VendorCode != matches[j].VendorCode {
+			return matches[i].VendorCode < matches[j].VendorCode
+		}
+		return matches[i].VendorID < matches[j].VendorID
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+// ListVendorBatch retrieves up to limit live vendors with an ID greater than
+// afterID, ordered by ID. An empty entityID lists all entities.
+func (s *Store) ListVendorBatch(ctx context.Context, entityID, afterID string, limit int) ([]*repository.Vendor, error) {
+	defer s.lock()()
+
+	var batch []repository.Vendor
+	for _, v := range s.data.vendors {
+		if v.DeletedAt == nil && (entityID == "" || v.EntityID == entityID) && v.ID > afterID {
+			batch = append(batch, v)
+		}
+	}
+	sort.Slice(batch, func(i, j int) bool { return batch[i].ID < batch[j].ID })
+
+	vendors := make([]*repository.Vendor, 0)
+	for i := 0; i < len(batch) && i < limit; i++ {
+		v := batch[i]
+		vendors = append(vendors, &v)
+	}
+	return vendors, nil
+}
+
+// MarkDormantVendors flags or unflags the vendors of a dormancy batch. The
+// last activity of a vendor is the latest of its last change and its last
+// balance transaction; touches are no activity.
+func (s *Store) MarkDormantVendors(ctx context.Context, batch repository.DormancyBatch) (*repository.DormancyBatchResult, error) {
+	defer s.lock()()
+
+	var vendors []repository.Vendor
+	for _, v := range s.data.vendors {
+		if v.DeletedAt == nil && (batch.EntityID == "" || v.EntityID == batch.EntityID) && v.ID > batch.AfterID {
+			vendors = append(vendors, v)
+		}
+	}
+	sort.Slice(vendors, func(i, j int) bool { return vendors[i].ID < vendors[j].ID })
+	if len(vendors) > batch.Limit {
+		vendors = vendors[:batch.Limit]
+	}
+
+	result := &repository.DormancyBatchResult{Scanned: len(vendors), Flagged: make([]*repository.DormancyFlag, 0)}
+	for _, v := range vendors {
+		result.LastID = v.ID
+		lastActivity := s.data.lastModifiedAt(v)
+		for _, e := range s.data.ledger {
+			if e.vendorID == v.ID && e.createdAt.After(lastActivity) {
+				lastActivity = e.createdAt
+			}
+		}
+
+		flag, flagged := s.data.dormancy[v.ID]
+		if !lastActivity.Before(batch.Cutoff) {
+			if flagged {
+				delete(s.data.dormancy, v.ID)
+				result.Cleared++
+			}
+			continue
+		}
+		if !flagged {
+			flag.flaggedAt = time.Now().UTC()
+			result.Flagged = append(result.Flagged, &repository.DormancyFlag{
+				VendorID:       v.ID,
+				EntityID:       v.EntityID,
+				LastActivityAt: lastActivity,
+			})
+		}
+		flag.entityID = v.EntityID
+		flag.lastActivityAt = lastActivity
+		s.data.dormancy[v.ID] = flag
+		result.Dormant++
+	}
+	return result, nil
+}
+
+// ListDormantVendors retrieves up to limit live vendors of an entity flagged
+// dormant, after afterID by ID
+func (s *Store) ListDormantVendors(ctx context.Context, entityID, afterID string, limit int) ([]*repository.DormantVendor, error) {
+	defer s.lock()()
+
+	dormant := make([]*repository.DormantVendor, 0)
+	for id, flag := range s.data.dormancy {
+		v, ok := s.data.liveVendor(id, entityID)
+		if !ok || id <= afterID {
+			continue
+		}
+		dormant = append(dormant, &repository.DormantVendor{
+			Vendor:         &v,
+			LastActivityAt: flag.lastActivityAt,
+			FlaggedAt:      flag.flaggedAt,
+		})
+	}
+	sort.Slice(dormant, func(i, j int) bool { return dormant[i].Vendor.ID < dormant[j].Vendor.ID })
+	if len(dormant) > limit {
+		dormant = dormant[:limit]
+	}
+	return dormant, nil
+}
+
+// InsertVendorAPIKey stores a new API key
+func (s *Store) InsertVendorAPIKey(ctx context.Context, key *repository.VendorAPIKey) error {
+	defer s.lock()()
+
+	for _, k := range s.data.apiKeys {
+		if k.KeyHash == key.KeyHash {
+			return errors.AlreadyExists("vendor_api_key", key.KeyPrefix)
+		}
+	}
+	key.ID = newID()
+	key.CreatedAt = time.Now().UTC()
+	stored := *key
+	stored.Scopes = slices.Clone(key.Scopes)
+	s.data.apiKeys = append(s.data.apiKeys, stored)
+	return nil
+}
+
+// ListVendorAPIKeys retrieves the API keys of a vendor, oldest first
+func (s *Store) ListVendorAPIKeys(ctx context.Context, vendorID, entityID string) ([]*repository.VendorAPIKey, error) {
+	defer s.lock()()
+
+	keys := make([]*repository.VendorAPIKey, 0)
+	for _, k := range s.data.apiKeys {
+		if k.VendorID == vendorID && k.EntityID == entityID {
+			k := k
+			keys = append(keys, &k)
+		}
+	}
+	return keys, nil
+}
+
+// GetVendorAPIKeyByHash retrieves the API key whose secret has hash
+func (s *Store) GetVendorAPIKeyByHash(ctx context.Context, hash string) (*repository.VendorAPIKey, error) {
+	defer s.lock()()
+
+	for _, k := range s.data.apiKeys {
+		if k.KeyHash == hash {
+			return &k, nil
+		}
+	}
+	return nil, &repository.NotFoundError{Resource: "vendor_api_key", Err: errors.NotFound("vendor_api_key", "")}
+}
+
+// RevokeVendorAPIKey revokes an API key of a vendor, keeping an earlier revocation
+func (s *Store) RevokeVendorAPIKey(ctx context.Context, id, vendorID, entityID string, revokedBy *string) (*repository.VendorAPIKey, error) {
+	defer s.lock()()
+
+	for i, k := range s.data.apiKeys {
+		if k.ID != id || k.VendorID != vendorID || k.EntityID != entityID {
+			continue
+		}
+		if k.RevokedAt == nil {
+			now := time.Now().UTC()
+			k.RevokedAt, k.RevokedBy = &now, revokedBy
+			s.data.apiKeys[i] = k
+		}
+		return &k, nil
+	}
+	return nil, &repository.NotFoundError{Resource: "vendor_api_key", ID: id, Err: errors.NotFound("vendor_api_key", id)}
+}
+
+// TouchVendorAPIKey records the use of an API key
+func (s *Store) TouchVendorAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	defer s.lock()()
+
+	for i, k := range s.data.apiKeys {
+		if k.ID == id {
+			usedAt := usedAt
+			s.data.apiKeys[i].LastUsedAt = &usedAt
+		}
+	}
+	return nil
+}
+
+// InsertBlocklistEntry adds an entry to the blocklist
+func (s *Store) InsertBlocklistEntry(ctx context.Context, entry *repository.BlocklistEntry) error {
+	defer s.lock()()
+
+	for _, existing := range s.data.blocklist {
+		if existing.TaxID == entry.TaxID {
+			return errors.AlreadyExists("blocklist_entry", entry.TaxID)
+		}
+	}
+
+	entry.ID = newID()
+	entry.CreatedAt = time.Now().UTC()
+	s.data.blocklist = append(s.data.blocklist, *entry)
+	return nil
+}
+
+// ListBlocklistEntries retrieves the blocklist, newest first
+func (s *Store) ListBlocklistEntries(ctx context.Context) ([]*repository.BlocklistEntry, error) {
+	defer s.lock()()
+
+	entries := make([]*repository.BlocklistEntry, 0, len(s.data.blocklist))
+	for i := len(s.data.blocklist) - 1; i >= 0; i-- {
+		entry := s.data.blocklist[i]
+		entries = append(entries, &entry)
+	}
+	return entries, nil
+}
+
+// FindBlocklistEntries retrieves the entries with the tax ID, or with one of
+// the bank account hashes. An empty tax ID matches no entry.
+func (s *Store) FindBlocklistEntries(ctx context.Context, taxID string, bankAccountHashes []string) ([]*repository.BlocklistEntry, error) {
+	defer s.lock()()
+
+	entries := make([]*repository.BlocklistEntry, 0)
+	for _, entry := range s.data.blocklist {
+		if (taxID != "" && entry.TaxID == taxID) ||
+			(entry.BankAccountHash != nil && slices.Contains(bankAccountHashes, *entry.BankAccountHash)) {
+			entry := entry
+			entries = append(entries, &entry)
+		}
+	}
+	return entries, nil
+}
+
+// DeleteBlocklistEntry removes an entry from the blocklist and returns it
+func (s *Store) DeleteBlocklistEntry(ctx context.Context, id string) (*repository.BlocklistEntry, error) {
+	defer s.lock()()
+
+	for i, entry := range s.data.blocklist {
+		if entry.ID == id {
+			s.data.blocklist = slices.Delete(s.data.blocklist, i, i+1)
+			return &entry, nil
+		}
+	}
+	return nil, &repository.NotFoundError{Resource: "blocklist_entry", ID: id, Err: errors.NotFound("blocklist_entry", id)}
+}
+
+// InsertBlocklistMatch records a vendor found on the blocklist
+func (s *Store) InsertBlocklistMatch(ctx context.Context, match *repository.BlocklistMatch) error {
+	defer s.lock()()
+
+	match.ID = newID()
+	match.CreatedAt = time.Now().UTC()
+	s.data.blocklistMatches = append(s.data.blocklistMatches, *match)
+	return nil
+}
+
+// ListBlocklistMatches retrieves the most recent blocklist matches, of one
+// entity when entityID is set
+func (s *Store) ListBlocklistMatches(ctx context.Context, entityID string, limit int) ([]*repository.BlocklistMatch, error) {
+	defer s.lock()()
+
+	matches := make([]*repository.BlocklistMatch, 0)
+	for i := len(s.data.blocklistMatches) - 1; i >= 0 && len(matches) < limit; i-- {
+		match := s.data.blocklistMatches[i]
+		if entityID == "" || match.EntityID == entityID {
+			matches = append(matches, &match)
+		}
+	}
+	return matches, nil
+}
+
+// GetEntityState retrieves the state of an entity, or an empty string when it
+// is active
+func (s *Store) GetEntityState(ctx context.Context, entityID string) (string, error) {
+	defer s.lock()()
+
+	return s.data.entityStates[entityID], nil
+}
+
+// SetEntityState sets the state of an entity; an empty state makes it active
+func (s *Store) SetEntityState(ctx context.Context, entityID, state string) error {
+	defer s.lock()()
+
+	if state == "" {
+		delete(s.data.entityStates, entityID)
+	} else {
+		s.data.entityStates[entityID] = state
+	}
+	return nil
+}
+
+// InsertEntityLifecycleEntry appends an entry to the entity lifecycle log
+func (s *Store) InsertEntityLifecycleEntry(ctx context.Context, entry *repository.EntityLifecycleEntry) error {
+	defer s.lock()()
+
+	entry.ID = newID()
+	entry.CreatedAt = time.Now().UTC()
+	s.data.lifecycleLog = append(s.data.lifecycleLog, *entry)
+	return nil
+}
+
+// ListEntityLifecycleEntries retrieves the lifecycle log of an entity, newest
+// first
+func (s *Store) ListEntityLifecycleEntries(ctx context.Context, entityID string, limit int) ([]*repository.EntityLifecycleEntry, error) {
+	defer s.lock()()
+
+	entries := make([]*repository.EntityLifecycleEntry, 0)
+	for i := len(s.data.lifecycleLog) - 1; i >= 0 && len(entries) < limit; i-- {
+		entry := s.data.lifecycleLog[i]
+		if entry.EntityID == entityID {
+			entries = append(entries, &entry)
+		}
+	}
+	return entries, nil
+}
+
+// CancelPendingStatusChanges cancels the pending scheduled status changes of
+// an entity and returns how many were cancelled
+func (s *Store) CancelPendingStatusChanges(ctx context.Context, entityID string, cancelledBy *string) (int, error) {
+	defer s.lock()()
+
+	now := time.Now().UTC()
+	cancelled := 0
+	for i := range s.data.scheduled {
+		sc := &s.data.scheduled[i]
+		if sc.EntityID != entityID || sc.State != repository.ScheduleStatePending {
+			continue
+		}
+		sc.State = repository.ScheduleStateCancelled
+		sc.CancelledBy = cancelledBy
+		sc.ProcessedAt = &now
+		cancelled++
+	}
+	return cancelled, nil
+}
+
+// CountPendingStatusChanges counts the pending scheduled status changes of an
+// entity
+func (s *Store) CountPendingStatusChanges(ctx context.Context, entityID string) (int, error) {
+	defer s.lock()()
+
+	count := 0
+	for _, sc := range s.data.scheduled {
+		if sc.EntityID == entityID && sc.State == repository.ScheduleStatePending {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// GetConsumerCursor retrieves the stream position of an event consumer, or an
+// empty string when it has not consumed anything yet
+func (s *Store) GetConsumerCursor(ctx context.Context, consumer string) (string, error) {
+	defer s.lock()()
+
+	return s.data.cursors[consumer], nil
+}
+
+// SetConsumerCursor stores the stream position of an event consumer
+func (s *Store) SetConsumerCursor(ctx context.Context, consumer, cursor string) error {
+	defer s.lock()()
+
+	s.data.cursors[consumer] = cursor
+	return nil
+}
+
+// liveCodeReservation returns the reservation of a vendor code that has not
+// expired
+func (d *state) liveCodeReservation(entityID, code string, now time.Time) (repository.VendorCodeReservation, bool) {
+	for _, res := range d.codeReservations {
+		if res.EntityID == entityID && res.VendorCode == code && res.ExpiresAt.After(now) {
+			return res, true
+		}
+	}
+	return repository.VendorCodeReservation{}, false
+}
+
+// InsertVendorCodeReservation reserves a vendor code. An expired reservation
+// of the code is taken over; a live one fails with AlreadyExists.
+func (s *Store) InsertVendorCodeReservation(ctx context.Context, res *repository.VendorCodeReservation) error {
+	defer s.lock()()
+
+	now := time.Now().UTC()
+	for id, existing := range s.data.codeReservations {
+		if existing.EntityID != res.EntityID || existing.VendorCode != res.VendorCode {
+			continue
+		}
+		if existing.ExpiresAt.After(now) {
+			return errors.AlreadyExists("vendor_code_reservation", res.VendorCode)
+		}
+		delete(s.data.codeReservations, id)
+	}
+
+	res.ID = newID()
+	res.CreatedAt = now
+	s.data.codeReservations[res.ID] = *res
+	return nil
+}
+
+// MaxVendorCodeNumber returns the largest number following prefix in the
+// vendor codes of an entity, counting deleted vendors and live reservations,
+// or 0 when there is none
+func (s *Store) MaxVendorCodeNumber(ctx context.Context, entityID, prefix string) (int64, error) {
+	defer s.lock()()
+
+	var number int64
+	consider := func(code string) {
+		suffix, ok := strings.CutPrefix(strings.ToUpper(code), prefix)
+		if !ok || suffix == "" || len(suffix) > 18 {
+			return
+		}
+		var n int64
+		for _, c := range suffix {
+			if c < '0' || c > '9' {
+				return
+			}
+			n = n*10 + int64(c-'0')
+		}
+		number = max(number, n)
+	}
+
+	now := time.Now().UTC()
+	for _, v := range s.data.vendors {
+		if v.EntityID == entityID {
+			consider(v.VendorCode)
+		}
+	}
+	for _, res := range s.data.codeReservations {
+		if res.EntityID == entityID && res.ExpiresAt.After(now) {
+			consider(res.VendorCode)
+		}
+	}
+	return number, nil
+}
+
+// GetVendorCodeReservation retrieves the live reservation of a vendor code, or
+// nil when the code is not reserved
+func (s *Store) GetVendorCodeReservation(ctx context.Context, entityID, code string) (*repository.VendorCodeReservation, error) {
+	defer s.lock()()
+
+	res, ok := s.data.liveCodeReservation(entityID, code, time.Now().UTC())
+	if !ok {
+		return nil, nil
+	}
+	return &res, nil
+}
+
+// DeleteVendorCodeReservation removes a vendor code reservation
+func (s *Store) DeleteVendorCodeReservation(ctx context.Context, id string) error {
+	defer s.lock()()
+
+	delete(s.data.codeReservations, id)
+	return nil
+}
+
+// DeleteExpiredVendorCodeReservations releases the expired vendor code
+// reservations and returns how many were released
+func (s *Store) DeleteExpiredVendorCodeReservations(ctx context.Context) (int, error) {
+	defer s.lock()()
+
+	now := time.Now().UTC()
+	released := 0
+	for id, res := range s.data.codeReservations {
+		if !res.ExpiresAt.After(now) {
+			delete(s.data.codeReservations, id)
+			released++
+		}
+	}
+	return released, nil
+}
+
+// CreateExportJob queues a vendor export job
+func (s *Store) CreateExportJob(ctx context.Context, job *repository.VendorExportJob) error {
+	defer s.lock()()
+
+	job.ID = newID()
+	job.Status = "pending"
+	job.CreatedAt = time.Now().UTC()
+	s.data.exportJobs[job.ID] = exportJob{VendorExportJob: *job}
+	return nil
+}
+
+// GetExportJob retrieves a vendor export job of an entity
+func (s *Store) GetExportJob(ctx context.Context, id, entityID string) (*repository.VendorExportJob, error) {
+	defer s.lock()()
+
+	job, ok := s.data.exportJobs[id]
+	if !ok || job.EntityID != entityID {
+		return nil, &repository.NotFoundError{Resource: "export_job", ID: id, Err: errors.NotFound("export_job", id)}
+	}
+	return &job.VendorExportJob, nil
+}
+
+// ClaimExportJob marks the oldest pending job, or a running one whose lease
+// expired, as running until leaseUntil; nil when no job is waiting
+func (s *Store) ClaimExportJob(ctx context.Context, leaseUntil time.Time) (*repository.VendorExportJob, error) {
+	defer s.lock()()
+
+	now := time.Now().UTC()
+	var claimed *exportJob
+	for _, job := range s.data.exportJobs {
+		waiting := job.Status == "pending" ||
+			(job.Status == "running" && (job.leaseUntil == nil || job.leaseUntil.Before(now)))
+		if waiting && (claimed == nil || job.CreatedAt.Before(claimed.CreatedAt)) {
+			claimed = &job
+		}
+	}
+	if claimed == nil {
+		return nil, nil
+	}
+	claimed.Status = "running"
+	claimed.leaseUntil = &leaseUntil
+	if claimed.StartedAt == nil {
+		claimed.StartedAt = &now
+	}
+	s.data.exportJobs[claimed.ID] = *claimed
+	job := claimed.VendorExportJob
+	return &job, nil
+}
+
+// CheckpointExportJob records the progress of a running job and extends its
+// lease
+func (s *Store) CheckpointExportJob(ctx context.Context, job *repository.VendorExportJob, leaseUntil time.Time) error {
+	defer s.lock()()
+
+	stored, ok := s.data.exportJobs[job.ID]
+	if !ok || stored.Status != "running" {
+		return &repository.NotFoundError{Resource: "export_job", ID: job.ID, Err: errors.NotFound("export_job", job.ID)}
+	}
+	stored.RowsWritten = job.RowsWritten
+	stored.Parts = job.Parts
+	stored.CursorID = job.CursorID
+	stored.leaseUntil = &leaseUntil
+	s.data.exportJobs[job.ID] = stored
+	return nil
+}
+
+// ReleaseExportJob gives up the lease of a running job, recording a failure
+// as a failed attempt
+func (s *Store) ReleaseExportJob(ctx context.Context, id string, failure *string) error {
+	defer s.lock()()
+
+	stored, ok := s.data.exportJobs[id]
+	if !ok || stored.Status != "running" {
+		return nil
+	}
+	stored.leaseUntil = nil
+	if failure != nil {
+		stored.Attempts++
+		stored.Error = failure
+	}
+	s.data.exportJobs[id] = stored
+	return nil
+}
+
+// FinishExportJob records the final status of a job and when it expires
+func (s *Store) FinishExportJob(ctx context.Context, id, status string, failure *string, expiresAt time.Time) error {
+	defer s.lock()()
+
+	stored, ok := s.data.exportJobs[id]
+	if !ok {
+		return &repository.NotFoundError{Resource: "export_job", ID: id, Err: errors.NotFound("export_job", id)}
+	}
+	now := time.Now().UTC()
+	stored.Status = status
+	stored.Error = failure
+	stored.leaseUntil = nil
+	stored.CompletedAt = &now
+	stored.ExpiresAt = &expiresAt
+	s.data.exportJobs[id] = stored
+	return nil
+}
+
+// ListExpiredExportJobs retrieves up to limit finished jobs expired at asOf
+func (s *Store) ListExpiredExportJobs(ctx context.Context, asOf time.Time, limit int) ([]*repository.VendorExportJob, error) {
+	defer s.lock()()
+
+	jobs := make([]*repository.VendorExportJob, 0)
+	for _, stored := range s.data.exportJobs {
+		if stored.ExpiresAt != nil && !stored.ExpiresAt.After(asOf) && len(jobs) < limit {
+			job := stored.VendorExportJob
+			jobs = append(jobs, &job)
+		}
+	}
+	return jobs, nil
+}
+
+// CountExpiredExportJobs counts the finished jobs expired at asOf
+func (s *Store) CountExpiredExportJobs(ctx context.Context, asOf time.Time) (int64, error) {
+	defer s.lock()()
+
+	var count int64
+	for _, stored := range s.data.exportJobs {
+		if stored.ExpiresAt != nil && !stored.ExpiresAt.After(asOf) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// DeleteExportJob removes a job
+func (s *Store) DeleteExportJob(ctx context.Context, id string) error {
+	defer s.lock()()
+
+	delete(s.data.exportJobs, id)
+	return nil
+}
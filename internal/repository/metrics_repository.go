@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// Granularities of vendor growth metrics
+const (
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+)
+
+// GrowthQuery selects the periods of the vendor growth series of an entity:
+// every week or month from the one containing From to the one containing To,
+// in UTC
+type GrowthQuery struct {
+	EntityID    string
+	Granularity string
+	From        time.Time
+	To          time.Time
+}
+
+// GrowthPoint is one period of the vendor growth series of an entity
+type GrowthPoint struct {
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	// Created counts the vendors created during the period, including those
+	// deleted since
+	Created int64 `json:"created"`
+	// Active counts the vendors active at the end of the period
+	Active int64 `json:"active"`
+	// Total counts the vendors existing and not deleted at the end of the period
+	Total int64 `json:"total"`
+}
+
+// PeriodStart returns the start of the week (Monday) or month containing t, in UTC
+func PeriodStart(t time.Time, granularity string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if granularity == GranularityWeek {
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	}
+	return day.AddDate(0, 0, 1-day.Day())
+}
+
+// NextPeriod returns the start of the period following the one starting at start
+func NextPeriod(start time.Time, granularity string) time.Time {
+	if granularity == GranularityWeek {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 1, 0)
+}
+
+// VendorGrowth aggregates the vendor growth series of an entity in the
+// database. The status of a vendor at the end of a period is the target of its
+// last status change before then; vendors without one are in the status their
+// first later change started from, or failing that their current status.
+func (r *VendorRepository) VendorGrowth(ctx context.Context, q GrowthQuery) ([]*GrowthPoint, error) {
+	query := `
+		WITH periods AS (
+			SELECT p AS period, p + ('1 ' || $2)::interval AS period_next
+			FROM generate_series(
+				date_trunc($2, $3::timestamptz AT TIME ZONE 'UTC'),
+				$4::timestamptz AT TIME ZONE 'UTC',
+				('1 ' || $2)::interval
+			) AS p
+		),
+		created AS (
+			SELECT date_trunc($2, created_at AT TIME ZONE 'UTC') AS period, COUNT(*) AS created
+			FROM vendors
+			WHERE entity_id = $1
+				AND created_at >= (SELECT MIN(period) FROM periods) AT TIME ZONE 'UTC'
+				AND created_at < (SELECT MAX(period_next) FROM periods) AT TIME ZONE 'UTC'
+			GROUP BY 1
+		)
+		SELECT
+			p.period AT TIME ZONE 'UTC',
+			p.period_next AT TIME ZONE 'UTC',
+			COALESCE(c.created, 0),
+			COUNT(v.id) FILTER (WHERE s.status = 'active'),
+			COUNT(v.id)
+		FROM periods p
+		LEFT JOIN created c ON c.period = p.period
+		LEFT JOIN vendors v ON v.entity_id = $1
+			AND v.created_at < p.period_next AT TIME ZONE 'UTC'
+			AND (v.deleted_at IS NULL OR v.deleted_at >= p.period_next AT TIME ZONE 'UTC')
+		LEFT JOIN LATERAL (
+			SELECT COALESCE(
+				(SELECT a.details->>'to' FROM vendor_audit_log a
+				 WHERE a.entity_id = $1 AND a.vendor_id = v.id AND a.action = 'status_changed'
+					AND a.created_at < p.period_next AT TIME ZONE 'UTC'
+				 ORDER BY a.created_at DESC LIMIT 1),
+				(SELECT a.details->>'from' FROM vendor_audit_log a
+				 WHERE a.entity_id = $1 AND a.vendor_id = v.id AND a.action = 'status_changed'
+					AND a.created_at >= p.period_next AT TIME ZONE 'UTC'
+				 ORDER BY a.created_at LIMIT 1),
+				v.status::text
+			) AS status
+		) s ON TRUE
+		GROUP BY p.period, p.period_next, c.created
+		ORDER BY p.period
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, q.EntityID, q.Granularity, q.From, q.To)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to compute vendor growth")
+	}
+	defer rows.Close()
+
+	points := make([]*GrowthPoint, 0)
+	for rows.Next() {
+		point := &GrowthPoint{}
+		if err := rows.Scan(&point.PeriodStart, &point.PeriodEnd, &point.Created, &point.Active, &point.Total); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan vendor growth")
+		}
+		points = append(points, point)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to compute vendor growth")
+	}
+
+	return points, nil
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// OnboardingSignals are the documents on file for a vendor that onboarding
+// checklists look at
+type OnboardingSignals struct {
+	// HasW9 is set when a W-9 document is on file
+	HasW9 bool
+	// HasInsurance is set when an insurance document that has not expired is
+	// on file
+	HasInsurance bool
+}
+
+// ListOnboardingSignals retrieves the onboarding signals of vendors by vendor ID
+func (r *VendorRepository) ListOnboardingSignals(ctx context.Context, vendorIDs []string) (map[string]*OnboardingSignals, error) {
+	query := `
+		SELECT v.id,
+		       EXISTS (
+		           SELECT 1 FROM vendor_documents d
+		           WHERE d.vendor_id = v.id AND upper(replace(d.document_type, '-', '')) = 'W9'
+		       ),
+		       EXISTS (
+		           SELECT 1 FROM vendor_documents d
+		           WHERE d.vendor_id = v.id AND lower(d.document_type) = 'insurance'
+		             AND (d.expiration_date IS NULL OR d.expiration_date >= CURRENT_DATE)
+		       )
+		FROM vendors v
+		WHERE v.id = ANY($1)
+	`
+
+	signals := make(map[string]*OnboardingSignals, len(vendorIDs))
+	if len(vendorIDs) == 0 {
+		return signals, nil
+	}
+
+	rows, err := r.reader(ctx).Query(ctx, query, vendorIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list onboarding signals")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var vendorID string
+		s := &OnboardingSignals{}
+		if err := rows.Scan(&vendorID, &s.HasW9, &s.HasInsurance); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan onboarding signals")
+		}
+		signals[vendorID] = s
+	}
+
+	return signals, nil
+}
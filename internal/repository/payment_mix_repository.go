@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// Dimensions of the payment mix report
+const (
+	PaymentMixTerms  = "payment_terms"
+	PaymentMixMethod = "payment_method"
+)
+
+// PaymentMixCount counts the vendors of an entity with a payment term or a
+// payment method and a currency, and sums their balances
+type PaymentMixCount struct {
+	// Dimension is PaymentMixTerms or PaymentMixMethod
+	Dimension string
+	// Value is the payment term or method, nil for vendors without a method
+	Value    *string
+	Currency string
+	Vendors  int64
+	Balance  int64
+}
+
+// CountVendorsByPaymentMix groups the vendors matching filter by payment term
+// and currency and by payment method and currency in a single query, ordered
+// by dimension, value with vendors without a method last, and currency
+func (r *VendorRepository) CountVendorsByPaymentMix(ctx context.Context, filter VendorFilter) ([]*PaymentMixCount, error) {
+	where, args := filter.where()
+	query := `
+		SELECT
+			CASE WHEN GROUPING(payment_terms) = 0 THEN '` + PaymentMixTerms + `' ELSE '` + PaymentMixMethod + `' END AS dimension,
+			CASE WHEN GROUPING(payment_terms) = 0 THEN payment_terms ELSE NULLIF(btrim(payment_method), '') END AS value,
+			currency,
+			COUNT(*),
+			COALESCE(SUM(current_balance), 0)::bigint
+		FROM vendors
+		` + where + `
+		GROUP BY GROUPING SETS ((payment_terms, currency), (NULLIF(btrim(payment_method), ''), currency))
+		ORDER BY 1 DESC, 2 NULLS LAST, 3
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to count vendors by payment mix")
+	}
+	defer rows.Close()
+
+	counts := make([]*PaymentMixCount, 0)
+	for rows.Next() {
+		count := &PaymentMixCount{}
+		if err := rows.Scan(&count.Dimension, &count.Value, &count.Currency, &count.Vendors, &count.Balance); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan payment mix count")
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to count vendors by payment mix")
+	}
+
+	return counts, nil
+}
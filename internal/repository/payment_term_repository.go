@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+const paymentTermColumns = `id, code, description, net_days, discount_percent, discount_days, is_active, created_at`
+
+// PaymentTermUsage is the number of live vendors of an entity using a
+// payment term
+type PaymentTermUsage struct {
+	EntityID string `json:"entity_id"`
+	Vendors  int64  `json:"vendors"`
+}
+
+// ReassignedVendor is a vendor moved to another payment term
+type ReassignedVendor struct {
+	ID         string `json:"id"`
+	EntityID   string `json:"entity_id"`
+	VendorCode string `json:"vendor_code"`
+}
+
+// ListPaymentTerms retrieves payment terms ordered by net days, optionally
+// only the active ones
+func (r *VendorRepository) ListPaymentTerms(ctx context.Context, onlyActive bool) ([]*PaymentTerm, error) {
+	query := `
+		SELECT ` + paymentTermColumns + `
+		FROM payment_terms
+		WHERE is_active OR NOT $1
+		ORDER BY net_days, code
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, onlyActive)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get payment terms")
+	}
+	defer rows.Close()
+
+	terms := make([]*PaymentTerm, 0)
+	for rows.Next() {
+		term, err := scanPaymentTerm(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan payment term")
+		}
+		terms = append(terms, term)
+	}
+
+	return terms, nil
+}
+
+// GetPaymentTermByCode retrieves a payment term by code, case-insensitively.
+// Inactive terms are not found when onlyActive is set.
+func (r *VendorRepository) GetPaymentTermByCode(ctx context.Context, code string, onlyActive bool) (*PaymentTerm, error) {
+	query := `
+		SELECT ` + paymentTermColumns + `
+		FROM payment_terms
+		WHERE upper(code) = upper($1) AND (is_active OR NOT $2)
+	`
+
+	term, err := scanPaymentTerm(r.reader(ctx).QueryRow(ctx, query, strings.TrimSpace(code), onlyActive))
+	if stderrors.Is(err, pgx.ErrNoRows) {
+		return nil, notFound("payment_term", code)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get payment term")
+	}
+
+	return term, nil
+}
+
+// CountPaymentTermUsage counts the live vendors using a payment term code,
+// case-insensitively, by entity, most using first
+func (r *VendorRepository) CountPaymentTermUsage(ctx context.Context, code string) ([]*PaymentTermUsage, error) {
+	query := `
+		SELECT entity_id, COUNT(*)
+		FROM vendors
+		WHERE upper(payment_terms) = upper($1) AND deleted_at IS NULL
+		GROUP BY entity_id
+		ORDER BY COUNT(*) DESC, entity_id
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, strings.TrimSpace(code))
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to count payment term usage")
+	}
+	defer rows.Close()
+
+	usage := make([]*PaymentTermUsage, 0)
+	for rows.Next() {
+		u := &PaymentTermUsage{}
+		if err := rows.Scan(&u.EntityID, &u.Vendors); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan payment term usage")
+		}
+		usage = append(usage, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to count payment term usage")
+	}
+
+	return usage, nil
+}
+
+// ReplacePaymentTerm moves every live vendor using the payment term from to
+// the term to in a single statement, returning the moved vendors
+func (r *VendorRepository) ReplacePaymentTerm(ctx context.Context, from, to string, updatedBy *string) ([]*ReassignedVendor, error) {
+	query := `
+		UPDATE vendors
+		SET payment_terms = $2, updated_by = $3, updated_at = NOW()
+		WHERE upper(payment_terms) = upper($1) AND deleted_at IS NULL
+		RETURNING id, entity_id, vendor_code
+	`
+
+	rows, err := r.q.Query(ctx, query, strings.TrimSpace(from), to, updatedBy)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to replace payment term")
+	}
+	defer rows.Close()
+
+	vendors := make([]*ReassignedVendor, 0)
+	for rows.Next() {
+		v := &ReassignedVendor{}
+		if err := rows.Scan(&v.ID, &v.EntityID, &v.VendorCode); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan reassigned vendor")
+		}
+		vendors = append(vendors, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to replace payment term")
+	}
+
+	return vendors, nil
+}
+
+// DeactivatePaymentTerm marks a payment term inactive, so it is no longer
+// offered; vendors using it are left as they are
+func (r *VendorRepository) DeactivatePaymentTerm(ctx context.Context, code string) error {
+	query := `UPDATE payment_terms SET is_active = FALSE WHERE upper(code) = upper($1)`
+
+	tag, err := r.q.Exec(ctx, query, strings.TrimSpace(code))
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to deactivate payment term")
+	}
+	if tag.RowsAffected() == 0 {
+		return notFound("payment_term", code)
+	}
+
+	return nil
+}
+
+func scanPaymentTerm(row pgx.Row) (*PaymentTerm, error) {
+	term := &PaymentTerm{}
+	err := row.Scan(
+		&term.ID,
+		&term.Code,
+		&term.Description,
+		&term.NetDays,
+		&term.DiscountPercent,
+		&term.DiscountDays,
+		&term.IsActive,
+		&term.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return term, nil
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"expvar"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/rs/zerolog"
+)
+
+// queryDurations holds a histogram of statement durations per repository
+// method; served on /debug/vars
+var queryDurations = expvar.NewMap("vendors_query_duration_ms")
+
+// queryDurationBuckets are the upper bounds, in milliseconds, of the
+// histogram buckets; slower statements fall into "+Inf"
+var queryDurationBuckets = []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000}
+
+// queryHistogramsMu serializes the creation of histograms in queryDurations
+var queryHistogramsMu sync.Mutex
+
+// durationHistogram counts statements by duration bucket
+type durationHistogram struct {
+	mu     sync.Mutex
+	counts []int64
+	count  int64
+	sumMS  float64
+}
+
+func (h *durationHistogram) observe(d time.Duration) {
+	ms := float64(d) / float64(time.Millisecond)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.counts == nil {
+		h.counts = make([]int64, len(queryDurationBuckets)+1)
+	}
+	i := 0
+	for i < len(queryDurationBuckets) && ms > queryDurationBuckets[i] {
+		i++
+	}
+	h.counts[i]++
+	h.count++
+	h.sumMS += ms
+}
+
+// String renders the histogram for expvar, e.g.
+// {"buckets":{"1":3,"5":1,...,"+Inf":0},"count":4,"sum_ms":7.2}
+func (h *durationHistogram) String() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	buckets := make(map[string]int64, len(queryDurationBuckets)+1)
+	for i, bound := range queryDurationBuckets {
+		buckets[strconv.FormatFloat(bound, 'f', -1, 64)] = h.bucket(i)
+	}
+	buckets["+Inf"] = h.bucket(len(queryDurationBuckets))
+
+	out, _ := json.Marshal(map[string]interface{}{
+		"buckets": buckets,
+		"count":   h.count,
+		"sum_ms":  h.sumMS,
+	})
+	return string(out)
+}
+
+func (h *durationHistogram) bucket(i int) int64 {
+	if h.counts == nil {
+		return 0
+	}
+	return h.counts[i]
+}
+
+// observeQuery adds a statement of op to its duration histogram
+func observeQuery(op string, d time.Duration) {
+	h, ok := queryDurations.Get(op).(*durationHistogram)
+	if !ok {
+		queryHistogramsMu.Lock()
+		if h, ok = queryDurations.Get(op).(*durationHistogram); !ok {
+			h = &durationHistogram{}
+			queryDurations.Set(op, h)
+		}
+		queryHistogramsMu.Unlock()
+	}
+	h.observe(d)
+}
+
+// slowQueryLog logs statements slower than threshold. A nil log logs nothing.
+type slowQueryLog struct {
+	log       *zerolog.Logger
+	threshold time.Duration
+}
+
+// WithSlowQueryLog logs statements slower than threshold at Warn, labelled
+// with the repository method that issued them. Arguments and driver errors
+// are never logged, as they may hold bank details. A threshold of 0 disables it.
+func WithSlowQueryLog(log *zerolog.Logger, threshold time.Duration) Option {
+	return func(r *VendorRepository) {
+		if log != nil && threshold > 0 {
+			r.slow = &slowQueryLog{log: log, threshold: threshold}
+		}
+	}
+}
+
+// check logs the statement of op when it took longer than the threshold
+func (s *slowQueryLog) check(ctx context.Context, op string, d time.Duration, rows int64, err error) {
+	if s == nil || d <= s.threshold {
+		return
+	}
+	reqlog.Logger(ctx, s.log).Warn().
+		Str("op", op).
+		Dur("duration_ms", d).
+		Int64("rows", rows).
+		Bool("failed", err != nil && !stderrors.Is(err, pgx.ErrNoRows)).
+		Msg("Slow query")
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// CountLiveVendors counts the vendors of an entity that are not soft-deleted.
+// Inside a transaction it takes the entity's vendor write lock first, so that
+// concurrent creates of the entity count one after the other.
+func (r *VendorRepository) CountLiveVendors(ctx context.Context, entityID string) (int64, error) {
+	// Same lock as assign_vendor_change_seq, held until the transaction ends
+	if _, err := r.q.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('vendor_change_seq'), hashtext($1::text))`, entityID); err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to lock entity vendors")
+	}
+
+	var count int64
+	err := r.q.QueryRow(ctx, `SELECT COUNT(*) FROM vendors WHERE entity_id = $1 AND deleted_at IS NULL`, entityID).Scan(&count)
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count vendors")
+	}
+
+	return count, nil
+}
+
+// CountVendorsByStatus counts the live vendors of an entity by status
+func (r *VendorRepository) CountVendorsByStatus(ctx context.Context, entityID string) (map[string]int64, error) {
+	query := `
+		SELECT status::text, COUNT(*)
+		FROM vendors
+		WHERE entity_id = $1 AND deleted_at IS NULL
+		GROUP BY status
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, entityID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to count vendors")
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var status string
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan vendor count")
+		}
+		counts[status] = count
+	}
+
+	return counts, nil
+}
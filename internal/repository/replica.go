@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"expvar"
+	"sync/atomic"
+	"time"
+
+	"github.com/pesio-ai/be-lib-common/database"
+)
+
+// Read consistency levels accepted per request
+const (
+	ReadConsistencyPrimary = "primary"
+	ReadConsistencyReplica = "replica"
+)
+
+// IsValidReadConsistency reports whether level is a known read consistency level
+func IsValidReadConsistency(level string) bool {
+	return level == ReadConsistencyPrimary || level == ReadConsistencyReplica
+}
+
+// readsByTarget counts routed reads by target: primary, replica, or
+// primary_fallback when the replica is configured but unhealthy
+var readsByTarget = expvar.NewMap("vendors_repository_reads")
+
+// replicaHealthTimeout bounds a single replica health check
+const replicaHealthTimeout = 2 * time.Second
+
+// WithReadReplica routes GetByID, GetByCode, List, GetContacts and
+// GetPaymentTerms to a read replica. A nil db disables replica reads.
+func WithReadReplica(db *database.DB) Option {
+	return func(r *VendorRepository) {
+		if db != nil {
+			r.replicaDB = db
+		}
+	}
+}
+
+type readConsistencyKey struct{}
+
+// readState tracks whether the reads of a request must see the primary
+type readState struct {
+	primary atomic.Bool
+}
+
+// WithReadConsistency installs the read routing state of a request. With
+// primary every replica-eligible read of the request goes to the primary;
+// otherwise reads go to the replica until the request writes.
+func WithReadConsistency(ctx context.Context, primary bool) context.Context {
+	state := &readState{}
+	state.primary.Store(primary)
+	return context.WithValue(ctx, readConsistencyKey{}, state)
+}
+
+// UsePrimary routes the remaining reads of the request to the primary, e.g.
+// before a read-modify-write
+func UsePrimary(ctx context.Context) context.Context {
+	if state, ok := ctx.Value(readConsistencyKey{}).(*readState); ok {
+		state.primary.Store(true)
+		return ctx
+	}
+	return WithReadConsistency(ctx, true)
+}
+
+// markWritten makes reads after a write in the same request see the write
+func markWritten(ctx context.Context) {
+	if state, ok := ctx.Value(readConsistencyKey{}).(*readState); ok {
+		state.primary.Store(true)
+	}
+	invalidateLookups(ctx)
+}
+
+func primaryRequired(ctx context.Context) bool {
+	state, ok := ctx.Value(readConsistencyKey{}).(*readState)
+	return ok && state.primary.Load()
+}
+
+// reader returns the querier of a replica-eligible read
+func (r *VendorRepository) reader(ctx context.Context) querier {
+	switch {
+	case r.replica == nil || primaryRequired(ctx):
+		readsByTarget.Add(ReadConsistencyPrimary, 1)
+		return r.q
+	case !r.replicaHealthy.Load():
+		readsByTarget.Add("primary_fallback", 1)
+		return r.q
+	default:
+		readsByTarget.Add(ReadConsistencyReplica, 1)
+		return r.replica
+	}
+}
+
+// HasReadReplica reports whether a read replica is configured
+func (r *VendorRepository) HasReadReplica() bool {
+	return r.replica != nil
+}
+
+// MonitorReplica pings the read replica every interval until ctx is done,
+// routing reads to the primary while it is unreachable. onChange is called
+// whenever the replica's health changes.
+func (r *VendorRepository) MonitorReplica(ctx context.Context, interval time.Duration, onChange func(healthy bool, err error)) {
+	if r.replicaDB == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, replicaHealthTimeout)
+		err := r.replicaDB.Ping(pingCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		healthy := err == nil
+		if r.replicaHealthy.Swap(healthy) != healthy && onChange != nil {
+			onChange(healthy, err)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// RetentionSettings holds the per-entity retention overrides. Nil values fall
+// back to the service defaults.
+type RetentionSettings struct {
+	EntityID                   string    `json:"entity_id"`
+	DeletedVendorRetentionDays *int      `json:"deleted_vendor_retention_days"`
+	AuditLogRetentionDays      *int      `json:"audit_log_retention_days"`
+	UpdatedBy                  *string   `json:"updated_by,omitempty"`
+	UpdatedAt                  time.Time `json:"updated_at"`
+}
+
+// Eligibility of soft-deleted vendors for purging. $1 is the default retention in days.
+const purgeableVendorsFrom = `
+		FROM vendors v
+		LEFT JOIN entity_retention_settings s ON s.entity_id = v.entity_id
+		WHERE v.deleted_at IS NOT NULL
+		  AND v.deleted_at < NOW() - make_interval(days => COALESCE(s.deleted_vendor_retention_days, $1))`
+
+// Vendors with balance ledger activity inside the retention window are kept
+const vendorHasRecentLedgerActivity = `
+		EXISTS (
+			SELECT 1 FROM vendor_balance_transactions t
+			WHERE t.vendor_id = v.id
+			  AND t.created_at >= NOW() - make_interval(days => COALESCE(s.deleted_vendor_retention_days, $1))
+		)`
+
+const purgeableTombstonesFrom = `
+		FROM vendor_change_tombstones x
+		LEFT JOIN entity_retention_settings s ON s.entity_id = x.entity_id
+		WHERE x.created_at < NOW() - make_interval(days => COALESCE(s.deleted_vendor_retention_days, $1))`
+
+const purgeableAuditLogFrom = `
+		FROM vendor_audit_log a
+		LEFT JOIN entity_retention_settings s ON s.entity_id = a.entity_id
+		WHERE a.created_at < NOW() - make_interval(days => COALESCE(s.audit_log_retention_days, $1))`
+
+// GetRetentionSettings retrieves the retention settings of an entity. Entities
+// without settings get an empty record, meaning the defaults apply.
+func (r *VendorRepository) GetRetentionSettings(ctx context.Context, entityID string) (*RetentionSettings, error) {
+	query := `
+		SELECT entity_id, deleted_vendor_retention_days, audit_log_retention_days, updated_by, updated_at
+		FROM entity_retention_settings
+		WHERE entity_id = $1
+	`
+
+	settings := &RetentionSettings{}
+	err := r.q.QueryRow(ctx, query, entityID).Scan(
+		&settings.EntityID,
+		&settings.DeletedVendorRetentionDays,
+		&settings.AuditLogRetentionDays,
+		&settings.UpdatedBy,
+		&settings.UpdatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return &RetentionSettings{EntityID: entityID}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get retention settings")
+	}
+
+	return settings, nil
+}
+
+// UpsertRetentionSettings creates or replaces the retention settings of an entity
+func (r *VendorRepository) UpsertRetentionSettings(ctx context.Context, settings *RetentionSettings) error {
+	query := `
+		INSERT INTO entity_retention_settings (entity_id, deleted_vendor_retention_days, audit_log_retention_days, updated_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (entity_id) DO UPDATE SET
+			deleted_vendor_retention_days = EXCLUDED.deleted_vendor_retention_days,
+			audit_log_retention_days = EXCLUDED.audit_log_retention_days,
+			updated_by = EXCLUDED.updated_by
+		RETURNING updated_at
+	`
+
+	err := r.q.QueryRow(ctx, query,
+		settings.EntityID,
+		settings.DeletedVendorRetentionDays,
+		settings.AuditLogRetentionDays,
+		settings.UpdatedBy,
+	).Scan(&settings.UpdatedAt)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save retention settings")
+	}
+
+	return nil
+}
+
+// CountPurgeableVendors counts soft-deleted vendors past retention, split into
+// those eligible for purging and those kept because of recent ledger activity
+func (r *VendorRepository) CountPurgeableVendors(ctx context.Context, defaultDays int) (eligible, blocked int64, err error) {
+	query := `
+		SELECT COUNT(*) FILTER (WHERE NOT ` + vendorHasRecentLedgerActivity + `),
+		       COUNT(*) FILTER (WHERE ` + vendorHasRecentLedgerActivity + `)
+		` + purgeableVendorsFrom
+
+	if err := r.q.QueryRow(ctx, query, defaultDays).Scan(&eligible, &blocked); err != nil {
+		return 0, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count purgeable vendors")
+	}
+
+	return eligible, blocked, nil
+}
+
+// PurgeDeletedVendors hard-deletes up to batchSize eligible soft-deleted vendors
+// (with their contacts, documents, refs and ledger) and returns how many were removed
+func (r *VendorRepository) PurgeDeletedVendors(ctx context.Context, defaultDays, batchSize int) (int64, error) {
+	query := `
+		DELETE FROM vendors
+		WHERE id IN (
+			SELECT v.id ` + purgeableVendorsFrom + `
+			  AND NOT ` + vendorHasRecentLedgerActivity + `
+			LIMIT $2
+			FOR UPDATE OF v SKIP LOCKED
+		)
+	`
+
+	tag, err := r.q.Exec(ctx, query, defaultDays, batchSize)
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to purge deleted vendors")
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// CountPurgeableTombstones counts transfer tombstones past the deleted vendor retention
+func (r *VendorRepository) CountPurgeableTombstones(ctx context.Context, defaultDays int) (int64, error) {
+	var count int64
+	if err := r.q.QueryRow(ctx, `SELECT COUNT(*) `+purgeableTombstonesFrom, defaultDays).Scan(&count); err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count purgeable tombstones")
+	}
+	return count, nil
+}
+
+// PurgeTombstones deletes up to batchSize transfer tombstones past retention
+func (r *VendorRepository) PurgeTombstones(ctx context.Context, defaultDays, batchSize int) (int64, error) {
+	query := `
+		DELETE FROM vendor_change_tombstones
+		WHERE id IN (SELECT x.id ` + purgeableTombstonesFrom + ` LIMIT $2)
+	`
+
+	tag, err := r.q.Exec(ctx, query, defaultDays, batchSize)
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to purge vendor tombstones")
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// CountPurgeableAuditLog counts audit log rows past retention
+func (r *VendorRepository) CountPurgeableAuditLog(ctx context.Context, defaultDays int) (int64, error) {
+	var count int64
+	if err := r.q.QueryRow(ctx, `SELECT COUNT(*) `+purgeableAuditLogFrom, defaultDays).Scan(&count); err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count purgeable audit log rows")
+	}
+	return count, nil
+}
+
+// PurgeAuditLog deletes up to batchSize audit log rows past retention
+func (r *VendorRepository) PurgeAuditLog(ctx context.Context, defaultDays, batchSize int) (int64, error) {
+	query := `
+		DELETE FROM vendor_audit_log
+		WHERE id IN (SELECT a.id ` + purgeableAuditLogFrom + ` LIMIT $2)
+	`
+
+	tag, err := r.q.Exec(ctx, query, defaultDays, batchSize)
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to purge audit log")
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// RiskFactor is a triggered risk rule and the weight it added to a score
+type RiskFactor struct {
+	Factor string `json:"factor"`
+	Weight int    `json:"weight"`
+}
+
+// RiskScore is the computed risk score of a vendor with its factor breakdown
+type RiskScore struct {
+	VendorID   string       `json:"-"`
+	EntityID   string       `json:"-"`
+	Score      int          `json:"score"`
+	Factors    []RiskFactor `json:"factors"`
+	ComputedAt time.Time    `json:"computed_at"`
+}
+
+// RiskSignals are the facts about a vendor kept outside the vendor row that
+// risk rules look at
+type RiskSignals struct {
+	// HasW9 is set when a W-9 document is on file
+	HasW9 bool
+	// LastBankChange is the time of the latest bank details change, if any
+	LastBankChange *time.Time
+}
+
+// GetRiskWeights retrieves the risk rule weights of an entity by factor: the
+// defaults overridden by the entity's own weights
+func (r *VendorRepository) GetRiskWeights(ctx context.Context, entityID string) (map[string]int, error) {
+	query := `
+		SELECT DISTINCT ON (factor) factor, weight
+		FROM risk_rule_weights
+		WHERE entity_id IS NULL OR entity_id = $1
+		ORDER BY factor, entity_id NULLS LAST
+	`
+
+	rows, err := r.q.Query(ctx, query, entityID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get risk weights")
+	}
+	defer rows.Close()
+
+	weights := make(map[string]int)
+	for rows.Next() {
+		var factor string
+		var weight int
+		if err := rows.Scan(&factor, &weight); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan risk weight")
+		}
+		weights[factor] = weight
+	}
+
+	return weights, nil
+}
+
+// ReplaceRiskWeights replaces the weights an entity overrides with weights
+func (r *VendorRepository) ReplaceRiskWeights(ctx context.Context, entityID string, weights map[string]int, updatedBy *string) error {
+	if _, err := r.q.Exec(ctx, `DELETE FROM risk_rule_weights WHERE entity_id = $1`, entityID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to replace risk weights")
+	}
+
+	for factor, weight := range weights {
+		_, err := r.q.Exec(ctx, `
+			INSERT INTO risk_rule_weights (entity_id, factor, weight, updated_by)
+			VALUES ($1, $2, $3, $4)
+		`, entityID, factor, weight, updatedBy)
+		if err != nil {
+			return errors.Wrap(err, errors.ErrCodeInternal, "failed to replace risk weights")
+		}
+	}
+
+	return nil
+}
+
+// ListRiskSignals retrieves the risk signals of vendors by vendor ID
+func (r *VendorRepository) ListRiskSignals(ctx context.Context, vendorIDs []string) (map[string]*RiskSignals, error) {
+	query := `
+		SELECT v.id,
+		       EXISTS (
+		           SELECT 1 FROM vendor_documents d
+		           WHERE d.vendor_id = v.id AND upper(replace(d.document_type, '-', '')) = 'W9'
+		       ),
+		       (
+		           SELECT MAX(a.created_at) FROM vendor_audit_log a
+		           WHERE a.vendor_id = v.id AND a.entity_id = v.entity_id AND a.action = 'bank_details_changed'
+		       )
+		FROM vendors v
+		WHERE v.id = ANY($1)
+	`
+
+	signals := make(map[string]*RiskSignals, len(vendorIDs))
+	if len(vendorIDs) == 0 {
+		return signals, nil
+	}
+
+	rows, err := r.q.Query(ctx, query, vendorIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list risk signals")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var vendorID string
+		s := &RiskSignals{}
+		if err := rows.Scan(&vendorID, &s.HasW9, &s.LastBankChange); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan risk signals")
+		}
+		signals[vendorID] = s
+	}
+
+	return signals, nil
+}
+
+// UpsertRiskScore stores the risk score of a vendor. A score or factors
+// differing from the stored ones advance the vendor's change_seq, since the
+// score is returned with the vendor; a new computed_at alone does not, so
+// that scheduled recomputations leave unchanged vendors out of the change feed.
+func (r *VendorRepository) UpsertRiskScore(ctx context.Context, score *RiskScore) error {
+	factors, err := json.Marshal(score.Factors)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to encode risk factors")
+	}
+
+	query := `
+		WITH previous AS (
+			SELECT score, factors FROM vendor_risk_scores WHERE vendor_id = $1
+		), upserted AS (
+			INSERT INTO vendor_risk_scores (vendor_id, entity_id, score, factors, computed_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (vendor_id) DO UPDATE SET
+				entity_id = EXCLUDED.entity_id,
+				score = EXCLUDED.score,
+				factors = EXCLUDED.factors,
+				computed_at = EXCLUDED.computed_at
+		)
+		SELECT NOT EXISTS (SELECT 1 FROM previous WHERE score = $3 AND factors = $4::jsonb)
+	`
+
+	var changed bool
+	if err := r.q.QueryRow(ctx, query, score.VendorID, score.EntityID, score.Score, factors, score.ComputedAt).Scan(&changed); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save risk score")
+	}
+	if changed {
+		return r.touchVendor(ctx, score.VendorID)
+	}
+	return nil
+}
+
+// ListRiskScores retrieves the stored risk scores of vendors by vendor ID
+func (r *VendorRepository) ListRiskScores(ctx context.Context, vendorIDs []string) (map[string]*RiskScore, error) {
+	query := `
+		SELECT vendor_id, entity_id, score, factors, computed_at
+		FROM vendor_risk_scores
+		WHERE vendor_id = ANY($1)
+	`
+
+	scores := make(map[string]*RiskScore, len(vendorIDs))
+	if len(vendorIDs) == 0 {
+		return scores, nil
+	}
+
+	rows, err := r.reader(ctx).Query(ctx, query, vendorIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list risk scores")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		score := &RiskScore{}
+		var factors []byte
+		if err := rows.Scan(&score.VendorID, &score.EntityID, &score.Score, &factors, &score.ComputedAt); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan risk score")
+		}
+		if err := json.Unmarshal(factors, &score.Factors); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to decode risk factors")
+		}
+		scores[score.VendorID] = score
+	}
+
+	return scores, nil
+}
+
+// ListVendorBatch retrieves up to limit live vendors with an ID greater than
+// afterID, ordered by ID. An empty entityID lists all entities.
+func (r *VendorRepository) ListVendorBatch(ctx context.Context, entityID, afterID string, limit int) ([]*Vendor, error) {
+	query := `
+		SELECT ` + vendorColumns + `
+		FROM vendors
+		WHERE deleted_at IS NULL
+		  AND (NULLIF($1, '') IS NULL OR entity_id = NULLIF($1, '')::uuid)
+		  AND (NULLIF($2, '') IS NULL OR id > NULLIF($2, '')::uuid)
+		ORDER BY id
+		LIMIT $3
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, entityID, afterID, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list vendors")
+	}
+	defer rows.Close()
+
+	vendors := make([]*Vendor, 0)
+	for rows.Next() {
+		vendor, err := scanVendor(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan vendor")
+		}
+		vendors = append(vendors, vendor)
+	}
+
+	return vendors, nil
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// States of a scheduled status change
+const (
+	ScheduleStatePending   = "pending"
+	ScheduleStateApplied   = "applied"
+	ScheduleStateFailed    = "failed"
+	ScheduleStateCancelled = "cancelled"
+)
+
+// ScheduledStatusChange is a vendor status change to be applied by the worker
+// on or after its effective date (YYYY-MM-DD, UTC)
+type ScheduledStatusChange struct {
+	ID            string     `json:"id"`
+	EntityID      string     `json:"entity_id"`
+	VendorID      string     `json:"vendor_id"`
+	TargetStatus  string     `json:"target_status"`
+	EffectiveDate string     `json:"effective_date"`
+	Reason        *string    `json:"reason,omitempty"`
+	State         string     `json:"state"`
+	FailureReason *string    `json:"failure_reason,omitempty"`
+	RequestedBy   *string    `json:"requested_by"`
+	CancelledBy   *string    `json:"cancelled_by,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ProcessedAt   *time.Time `json:"processed_at,omitempty"`
+}
+
+// ScheduledStatusChangeFilter selects the scheduled status changes of an
+// entity, optionally of one vendor and in one state
+type ScheduledStatusChangeFilter struct {
+	EntityID string
+	VendorID string
+	State    string
+}
+
+const scheduledStatusChangeColumns = `id, entity_id, vendor_id, target_status, effective_date, reason, state,
+	failure_reason, requested_by, cancelled_by, created_at, processed_at`
+
+// scanScheduledStatusChange scans a row of scheduledStatusChangeColumns
+func scanScheduledStatusChange(row rowScanner) (*ScheduledStatusChange, error) {
+	sc := &ScheduledStatusChange{}
+	var effectiveDate time.Time
+	err := row.Scan(&sc.ID, &sc.EntityID, &sc.VendorID, &sc.TargetStatus, &effectiveDate, &sc.Reason, &sc.State,
+		&sc.FailureReason, &sc.RequestedBy, &sc.CancelledBy, &sc.CreatedAt, &sc.ProcessedAt)
+	if err != nil {
+		return nil, err
+	}
+	sc.EffectiveDate = effectiveDate.Format(time.DateOnly)
+	return sc, nil
+}
+
+// InsertScheduledStatusChange stores a pending scheduled status change
+func (r *VendorRepository) InsertScheduledStatusChange(ctx context.Context, sc *ScheduledStatusChange) error {
+	query := `
+		INSERT INTO scheduled_status_changes (entity_id, vendor_id, target_status, effective_date, reason, requested_by)
+		VALUES ($1, $2, $3, $4::date, $5, $6)
+		RETURNING id, state, created_at
+	`
+
+	err := r.q.QueryRow(ctx, query, sc.EntityID, sc.VendorID, sc.TargetStatus, sc.EffectiveDate, sc.Reason, sc.RequestedBy).
+		Scan(&sc.ID, &sc.State, &sc.CreatedAt)
+	var pgErr *pgconn.PgError
+	if stderrors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return errors.AlreadyExists("scheduled_status_change", sc.EffectiveDate)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to schedule status change")
+	}
+
+	return nil
+}
+
+// LockScheduledStatusChange retrieves a scheduled status change of an entity
+// and locks it until the end of the transaction
+func (r *VendorRepository) LockScheduledStatusChange(ctx context.Context, id, entityID string) (*ScheduledStatusChange, error) {
+	query := `
+		SELECT ` + scheduledStatusChangeColumns + `
+		FROM scheduled_status_changes
+		WHERE id = $1 AND entity_id = $2
+		FOR UPDATE
+	`
+
+	sc, err := scanScheduledStatusChange(r.q.QueryRow(ctx, query, id, entityID))
+	if stderrors.Is(err, pgx.ErrNoRows) {
+		return nil, notFound("scheduled_status_change", id)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get scheduled status change")
+	}
+
+	return sc, nil
+}
+
+// ListScheduledStatusChanges retrieves up to limit scheduled status changes
+// matching filter, by effective date and then creation
+func (r *VendorRepository) ListScheduledStatusChanges(ctx context.Context, filter ScheduledStatusChangeFilter, limit int) ([]*ScheduledStatusChange, error) {
+	conditions := []string{"entity_id = $1"}
+	args := []interface{}{filter.EntityID}
+	if filter.VendorID != "" {
+		args = append(args, filter.VendorID)
+		conditions = append(conditions, fmt.Sprintf("vendor_id = $%d", len(args)))
+	}
+	if filter.State != "" {
+		args = append(args, filter.State)
+		conditions = append(conditions, fmt.Sprintf("state = $%d", len(args)))
+	}
+	args = append(args, limit)
+
+	query := `
+		SELECT ` + scheduledStatusChangeColumns + `
+		FROM scheduled_status_changes
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY effective_date, created_at
+		LIMIT $` + fmt.Sprint(len(args))
+
+	return r.queryScheduledStatusChanges(ctx, r.reader(ctx), query, "failed to list scheduled status changes", args...)
+}
+
+// ClaimDueStatusChanges locks up to limit pending status changes of any entity
+// effective on or before asOf (YYYY-MM-DD), skipping those another
+// transaction holds, oldest first
+func (r *VendorRepository) ClaimDueStatusChanges(ctx context.Context, asOf string, limit int) ([]*ScheduledStatusChange, error) {
+	query := `
+		SELECT ` + scheduledStatusChangeColumns + `
+		FROM scheduled_status_changes
+		WHERE state = 'pending' AND effective_date <= $1::date
+		ORDER BY effective_date, created_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`
+
+	return r.queryScheduledStatusChanges(ctx, r.q, query, "failed to claim due status changes", asOf, limit)
+}
+
+// FinishScheduledStatusChange records the final state of a scheduled status
+// change, with its failure reason or who cancelled it
+func (r *VendorRepository) FinishScheduledStatusChange(ctx context.Context, sc *ScheduledStatusChange) error {
+	query := `
+		UPDATE scheduled_status_changes
+		SET state = $2, failure_reason = $3, cancelled_by = $4, processed_at = NOW()
+		WHERE id = $1
+		RETURNING processed_at
+	`
+
+	err := r.q.QueryRow(ctx, query, sc.ID, sc.State, sc.FailureReason, sc.CancelledBy).Scan(&sc.ProcessedAt)
+	if stderrors.Is(err, pgx.ErrNoRows) {
+		return notFound("scheduled_status_change", sc.ID)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update scheduled status change")
+	}
+
+	return nil
+}
+
+// queryScheduledStatusChanges runs a query selecting scheduledStatusChangeColumns
+func (r *VendorRepository) queryScheduledStatusChanges(ctx context.Context, q querier, query, message string, args ...interface{}) ([]*ScheduledStatusChange, error) {
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, message)
+	}
+	defer rows.Close()
+
+	changes := make([]*ScheduledStatusChange, 0)
+	for rows.Next() {
+		sc, err := scanScheduledStatusChange(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan scheduled status change")
+		}
+		changes = append(changes, sc)
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// BalanceTransaction is an entry of a vendor's balance ledger
+type BalanceTransaction struct {
+	ID           string    `json:"id"`
+	VendorID     string    `json:"vendor_id"`
+	EntityID     string    `json:"entity_id"`
+	Amount       int64     `json:"amount"`
+	BalanceAfter int64     `json:"balance_after"`
+	Kind         string    `json:"kind"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// ListDocuments retrieves up to limit documents of a vendor, most recently
+// uploaded first
+func (r *VendorRepository) ListDocuments(ctx context.Context, vendorID string, limit int) ([]*VendorDocument, error) {
+	query := `
+		SELECT id, vendor_id, document_type, document_name, document_url, file_size, mime_type,
+		       expiration_date::text, uploaded_by, to_char(uploaded_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')
+		FROM vendor_documents
+		WHERE vendor_id = $1
+		ORDER BY uploaded_at DESC, id
+		LIMIT $2
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, vendorID, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list vendor documents")
+	}
+	defer rows.Close()
+
+	documents := make([]*VendorDocument, 0)
+	for rows.Next() {
+		doc := &VendorDocument{}
+		err := rows.Scan(&doc.ID, &doc.VendorID, &doc.DocumentType, &doc.DocumentName, &doc.DocumentURL,
+			&doc.FileSize, &doc.MimeType, &doc.ExpirationDate, &doc.UploadedBy, &doc.UploadedAt)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan vendor document")
+		}
+		documents = append(documents, doc)
+	}
+
+	return documents, nil
+}
+
+// GetDocument retrieves a document of a live vendor of an entity
+func (r *VendorRepository) GetDocument(ctx context.Context, id, entityID string) (*VendorDocument, error) {
+	query := `
+		SELECT d.id, d.vendor_id, d.document_type, d.document_name, d.document_url, d.file_size, d.mime_type,
+		       d.expiration_date::text, d.uploaded_by, to_char(d.uploaded_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')
+		FROM vendor_documents d
+		JOIN vendors v ON v.id = d.vendor_id
+		WHERE d.id = $1 AND v.entity_id = $2 AND v.deleted_at IS NULL
+	`
+
+	doc := &VendorDocument{}
+	err := r.reader(ctx).QueryRow(ctx, query, id, entityID).Scan(&doc.ID, &doc.VendorID, &doc.DocumentType,
+		&doc.DocumentName, &doc.DocumentURL, &doc.FileSize, &doc.MimeType, &doc.ExpirationDate, &doc.UploadedBy, &doc.UploadedAt)
+	if err == pgx.ErrNoRows {
+		return nil, errors.NotFound("document", id)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get vendor document")
+	}
+
+	return doc, nil
+}
+
+// AddDocument records a document of a vendor under a new ID, keeping its
+// upload time when set
+func (r *VendorRepository) AddDocument(ctx context.Context, doc *VendorDocument) error {
+	query := `
+		INSERT INTO vendor_documents (vendor_id, document_type, document_name, document_url, file_size, mime_type,
+		                              expiration_date, uploaded_by, uploaded_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7::date, $8, COALESCE(NULLIF($9, '')::timestamptz, NOW()))
+		RETURNING id, to_char(uploaded_at AT TIME ZONE 'UTC', 'YYYY-MM-DD"T"HH24:MI:SS"Z"')
+	`
+
+	err := r.q.QueryRow(ctx, query, doc.VendorID, doc.DocumentType, doc.DocumentName, doc.DocumentURL, doc.FileSize,
+		doc.MimeType, doc.ExpirationDate, doc.UploadedBy, doc.UploadedAt).Scan(&doc.ID, &doc.UploadedAt)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to add vendor document")
+	}
+
+	return nil
+}
+
+// VendorChildCounts is the number of contacts and documents of a vendor
+type VendorChildCounts struct {
+	Contacts  int64
+	Documents int64
+	// ExpiringDocuments counts the documents expiring by the cutoff of the
+	// count, including those already expired
+	ExpiringDocuments int64
+}
+
+// ListChildCounts counts the contacts and documents of vendors by vendor ID,
+// with the documents expiring on or before expiringBy. Vendors without any
+// are left out.
+func (r *VendorRepository) ListChildCounts(ctx context.Context, vendorIDs []string, expiringBy time.Time) (map[string]*VendorChildCounts, error) {
+	// Contacts and documents are deleted outright today; filter on their
+	// deleted_at here once they are soft deleted
+	query := `
+		SELECT vendor_id, SUM(contacts)::bigint, SUM(documents)::bigint, SUM(expiring)::bigint
+		FROM (
+			SELECT vendor_id, COUNT(*) AS contacts, 0 AS documents, 0 AS expiring
+			FROM vendor_contacts
+			WHERE vendor_id = ANY($1)
+			GROUP BY vendor_id
+			UNION ALL
+			SELECT vendor_id, 0, COUNT(*), COUNT(*) FILTER (WHERE expiration_date <= $2::date)
+			FROM vendor_documents
+			WHERE vendor_id = ANY($1)
+			GROUP BY vendor_id
+		) counts
+		GROUP BY vendor_id
+	`
+
+	counts := make(map[string]*VendorChildCounts, len(vendorIDs))
+	if len(vendorIDs) == 0 {
+		return counts, nil
+	}
+
+	rows, err := r.reader(ctx).Query(ctx, query, vendorIDs, expiringBy.UTC().Format("2006-01-02"))
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to count vendor contacts and documents")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var vendorID string
+		c := &VendorChildCounts{}
+		if err := rows.Scan(&vendorID, &c.Contacts, &c.Documents, &c.ExpiringDocuments); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan vendor child counts")
+		}
+		counts[vendorID] = c
+	}
+
+	return counts, nil
+}
+
+// ListBalanceTransactions retrieves the latest limit balance ledger entries of
+// a vendor, newest first
+func (r *VendorRepository) ListBalanceTransactions(ctx context.Context, vendorID, entityID string, limit int) ([]*BalanceTransaction, error) {
+	query := `
+		SELECT id, vendor_id, entity_id, amount, balance_after, kind, created_at
+		FROM vendor_balance_transactions
+		WHERE vendor_id = $1 AND entity_id = $2
+		ORDER BY created_at DESC, id
+		LIMIT $3
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, vendorID, entityID, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list balance transactions")
+	}
+	defer rows.Close()
+
+	transactions := make([]*BalanceTransaction, 0)
+	for rows.Next() {
+		t := &BalanceTransaction{}
+		if err := rows.Scan(&t.ID, &t.VendorID, &t.EntityID, &t.Amount, &t.BalanceAfter, &t.Kind, &t.CreatedAt); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan balance transaction")
+		}
+		transactions = append(transactions, t)
+	}
+
+	return transactions, nil
+}
+
+// ListVendorAuditEntries retrieves the latest limit audit entries of a vendor,
+// newest first
+func (r *VendorRepository) ListVendorAuditEntries(ctx context.Context, vendorID, entityID string, limit int) ([]*AuditEntry, error) {
+	query := `
+		SELECT id, entity_id, vendor_id, action, actor_id, details, created_at
+		FROM vendor_audit_log
+		WHERE entity_id = $1 AND vendor_id = $2
+		ORDER BY created_at DESC, id
+		LIMIT $3
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, entityID, vendorID, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list audit entries")
+	}
+	defer rows.Close()
+
+	entries := make([]*AuditEntry, 0)
+	for rows.Next() {
+		entry := &AuditEntry{}
+		var details []byte
+		if err := rows.Scan(&entry.ID, &entry.EntityID, &entry.VendorID, &entry.Action, &entry.ActorID, &details, &entry.CreatedAt); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan audit entry")
+		}
+		if err := json.Unmarshal(details, &entry.Details); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to decode audit details")
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// Store is the persistence API used by the vendor service. VendorRepository
+// implements it on Postgres and memory.Store in memory, for unit tests and
+// local development.
+type Store interface {
+	// WithTx runs fn with a store bound to a single transaction, committed when
+	// fn returns nil and rolled back otherwise
+	WithTx(ctx context.Context, fn func(repo Store) error) error
+
+	// Vendors
+	Create(ctx context.Context, vendor *Vendor) error
+	GetByID(ctx context.Context, id, entityID string) (*Vendor, error)
+	GetByCode(ctx context.Context, code, entityID string) (*Vendor, error)
+	Update(ctx context.Context, vendor *Vendor) error
+	UpsertByCode(ctx context.Context, vendor *Vendor, columns []string) (*Vendor, bool, error)
+	Delete(ctx context.Context, id, entityID string) error
+	CountVendorChildren(ctx context.Context, vendorID string) (*VendorChildren, error)
+	DeleteVendorChildren(ctx context.Context, vendorID string) (*VendorChildren, error)
+	List(ctx context.Context, filter VendorFilter, limit, offset int) ([]*Vendor, int64, error)
+	ListSummaries(ctx context.Context, filter VendorFilter, limit, offset int) ([]*VendorSummary, int64, error)
+	ListChangedSince(ctx context.Context, entityID string, afterSeq int64, limit int) ([]*Vendor, error)
+	ValidateVendor(ctx context.Context, vendorID, entityID string) (bool, string, error)
+	UpdateBalance(ctx context.Context, vendorID, entityID string, amount int64) error
+	HasBalanceActivitySince(ctx context.Context, vendorID string, since time.Time) (bool, error)
+	ListVendorRegions(ctx context.Context, entityID, afterID string, limit int) ([]*VendorRegion, error)
+	UpdateVendorRegion(ctx context.Context, region *VendorRegion) error
+	CountLiveVendors(ctx context.Context, entityID string) (int64, error)
+	CountVendorsByStatus(ctx context.Context, entityID string) (map[string]int64, error)
+	VendorGrowth(ctx context.Context, q GrowthQuery) ([]*GrowthPoint, error)
+	CountVendorsByRegion(ctx context.Context, filter VendorFilter) ([]*RegionCount, error)
+	CountVendorsByPaymentMix(ctx context.Context, filter VendorFilter) ([]*PaymentMixCount, error)
+	ListTagCounts(ctx context.Context, entityID string) ([]*TagCount, error)
+	RenameTag(ctx context.Context, entityID, from, to string, updatedBy *string) ([]*TaggedVendor, error)
+	DeleteTag(ctx context.Context, entityID, tag string, updatedBy *string) ([]*TaggedVendor, error)
+	AddTag(ctx context.Context, entityID string, vendorIDs []string, tag string, maxTags int, updatedBy *string) ([]*TaggedVendor, error)
+	ListDocuments(ctx context.Context, vendorID string, limit int) ([]*VendorDocument, error)
+	GetDocument(ctx context.Context, id, entityID string) (*VendorDocument, error)
+	AddDocument(ctx context.Context, doc *VendorDocument) error
+	ListChildCounts(ctx context.Context, vendorIDs []string, expiringBy time.Time) (map[string]*VendorChildCounts, error)
+	ListBalanceTransactions(ctx context.Context, vendorID, entityID string, limit int) ([]*BalanceTransaction, error)
+	ListVendorAuditEntries(ctx context.Context, vendorID, entityID string, limit int) ([]*AuditEntry, error)
+	ListAuditActivity(ctx context.Context, vendorID, entityID string, actions []string, rng ActivityRange) ([]*AuditEntry, error)
+	ListBalanceActivity(ctx context.Context, vendorID, entityID string, rng ActivityRange) ([]*BalanceTransaction, error)
+	ListDocumentActivity(ctx context.Context, vendorID string, rng ActivityRange) ([]*DocumentUpload, error)
+
+	// Approval queue
+	ListPendingApproval(ctx context.Context, entityID string, newestFirst bool, limit, offset int) ([]*Vendor, int64, error)
+	CountPendingApproval(ctx context.Context, entityID string, overdueBefore time.Time) (total, overdue int64, err error)
+	ClaimApprovalSLABreaches(ctx context.Context, createdBefore time.Time, limit int) ([]*Vendor, error)
+	ClearApprovalSLABreach(ctx context.Context, vendorID string) error
+	LockVendorApprovals(ctx context.Context, vendorID string) error
+	ListVendorApprovals(ctx context.Context, vendorIDs []string) (map[string][]*VendorApproval, error)
+	InsertVendorApproval(ctx context.Context, approval *VendorApproval) error
+	DeleteVendorApprovals(ctx context.Context, vendorID string) error
+	ListOnboardingSignals(ctx context.Context, vendorIDs []string) (map[string]*OnboardingSignals, error)
+
+	// Risk scores
+	GetRiskWeights(ctx context.Context, entityID string) (map[string]int, error)
+	ReplaceRiskWeights(ctx context.Context, entityID string, weights map[string]int, updatedBy *string) error
+	ListRiskSignals(ctx context.Context, vendorIDs []string) (map[string]*RiskSignals, error)
+	UpsertRiskScore(ctx context.Context, score *RiskScore) error
+	ListRiskScores(ctx context.Context, vendorIDs []string) (map[string]*RiskScore, error)
+	ListVendorBatch(ctx context.Context, entityID, afterID string, limit int) ([]*Vendor, error)
+
+	// Entity lifecycle
+	GetEntityState(ctx context.Context, entityID string) (string, error)
+	SetEntityState(ctx context.Context, entityID, state string) error
+	InsertEntityLifecycleEntry(ctx context.Context, entry *EntityLifecycleEntry) error
+	ListEntityLifecycleEntries(ctx context.Context, entityID string, limit int) ([]*EntityLifecycleEntry, error)
+	CancelPendingStatusChanges(ctx context.Context, entityID string, cancelledBy *string) (int, error)
+	CountPendingStatusChanges(ctx context.Context, entityID string) (int, error)
+	GetConsumerCursor(ctx context.Context, consumer string) (string, error)
+	SetConsumerCursor(ctx context.Context, consumer, cursor string) error
+
+	// Vendor code reservations
+	InsertVendorCodeReservation(ctx context.Context, res *VendorCodeReservation) error
+	MaxVendorCodeNumber(ctx context.Context, entityID, prefix string) (int64, error)
+	GetVendorCodeReservation(ctx context.Context, entityID, code string) (*VendorCodeReservation, error)
+	DeleteVendorCodeReservation(ctx context.Context, id string) error
+	DeleteExpiredVendorCodeReservations(ctx context.Context) (int, error)
+
+	// TIN matching
+	UpsertTINMatch(ctx context.Context, match *TINMatch) error
+	ListTINMatches(ctx context.Context, vendorIDs []string) (map[string]*TINMatch, error)
+
+	// Bank account verification
+	UpsertBankVerification(ctx context.Context, verification *BankVerification) error
+	ListBankVerifications(ctx context.Context, vendorIDs []string) (map[string]*BankVerification, error)
+	FindVendorsByBankLast4(ctx context.Context, entityID, last4 string, limit int) ([]*BankLast4Match, error)
+
+	// Data quality
+	CountDataQualityIssues(ctx context.Context, entityID string, rules []string) (map[string]int64, error)
+	ListDataQualityOffenders(ctx context.Context, entityID, rule string, limit, offset int) ([]*DataQualityOffender, error)
+	ListFailedDataQualityRules(ctx context.Context, vendorIDs, rules []string) (map[string][]string, error)
+	ListSuspectedTestVendors(ctx context.Context, entityID string, limit, offset int) ([]*SuspectedTestVendor, int64, error)
+
+	// Balance reconciliation
+	FindBalanceDiscrepancies(ctx context.Context, entityID string) ([]*BalanceDiscrepancy, error)
+	ReconcileVendorBalance(ctx context.Context, vendorID, entityID string) (*BalanceDiscrepancy, error)
+
+	// Dormant vendors
+	MarkDormantVendors(ctx context.Context, batch DormancyBatch) (*DormancyBatchResult, error)
+	ListDormantVendors(ctx context.Context, entityID, afterID string, limit int) ([]*DormantVendor, error)
+
+	// Vendor API keys
+	InsertVendorAPIKey(ctx context.Context, key *VendorAPIKey) error
+	ListVendorAPIKeys(ctx context.Context, vendorID, entityID string) ([]*VendorAPIKey, error)
+	GetVendorAPIKeyByHash(ctx context.Context, hash string) (*VendorAPIKey, error)
+	RevokeVendorAPIKey(ctx context.Context, id, vendorID, entityID string, revokedBy *string) (*VendorAPIKey, error)
+	TouchVendorAPIKey(ctx context.Context, id string, usedAt time.Time) error
+
+	// Vendor aliases and name matching
+	InsertVendorAlias(ctx context.Context, alias *VendorAlias) error
+	ListVendorAliases(ctx context.Context, vendorID string) ([]*VendorAlias, error)
+	DeleteVendorAlias(ctx context.Context, id, vendorID string) (*VendorAlias, error)
+	MatchVendorsByName(ctx context.Context, entityID, name string) ([]*VendorNameMatch, error)
+	FindVendorMatchCandidates(ctx context.Context, q VendorMatchQuery) ([]*VendorMatchCandidate, error)
+
+	// Scheduled status changes
+	InsertScheduledStatusChange(ctx context.Context, sc *ScheduledStatusChange) error
+	LockScheduledStatusChange(ctx context.Context, id, entityID string) (*ScheduledStatusChange, error)
+	ListScheduledStatusChanges(ctx context.Context, filter ScheduledStatusChangeFilter, limit int) ([]*ScheduledStatusChange, error)
+	ClaimDueStatusChanges(ctx context.Context, asOf string, limit int) ([]*ScheduledStatusChange, error)
+	FinishScheduledStatusChange(ctx context.Context, sc *ScheduledStatusChange) error
+
+	// Vendor export jobs
+	CreateExportJob(ctx context.Context, job *VendorExportJob) error
+	GetExportJob(ctx context.Context, id, entityID string) (*VendorExportJob, error)
+	ClaimExportJob(ctx context.Context, leaseUntil time.Time) (*VendorExportJob, error)
+	CheckpointExportJob(ctx context.Context, job *VendorExportJob, leaseUntil time.Time) error
+	ReleaseExportJob(ctx context.Context, id string, failure *string) error
+	FinishExportJob(ctx context.Context, id, status string, failure *string, expiresAt time.Time) error
+	ListExpiredExportJobs(ctx context.Context, asOf time.Time, limit int) ([]*VendorExportJob, error)
+	CountExpiredExportJobs(ctx context.Context, asOf time.Time) (int64, error)
+	DeleteExportJob(ctx context.Context, id string) error
+
+	// Favorite and recently viewed vendors of users
+	ToggleFavoriteVendor(ctx context.Context, userID, entityID, vendorID string) (bool, error)
+	ListFavoriteVendors(ctx context.Context, userID, entityID string) ([]*Vendor, error)
+	RecordVendorView(ctx context.Context, userID, entityID, vendorID string, keep int) error
+	ListRecentVendors(ctx context.Context, userID, entityID string) ([]*Vendor, error)
+
+	// Organization blocklist
+	InsertBlocklistEntry(ctx context.Context, entry *BlocklistEntry) error
+	ListBlocklistEntries(ctx context.Context) ([]*BlocklistEntry, error)
+	FindBlocklistEntries(ctx context.Context, taxID string, bankAccountHashes []string) ([]*BlocklistEntry, error)
+	DeleteBlocklistEntry(ctx context.Context, id string) (*BlocklistEntry, error)
+	InsertBlocklistMatch(ctx context.Context, match *BlocklistMatch) error
+	ListBlocklistMatches(ctx context.Context, entityID string, limit int) ([]*BlocklistMatch, error)
+
+	// Contacts and payment terms
+	GetContacts(ctx context.Context, vendorID string) ([]*VendorContact, error)
+	ListContacts(ctx context.Context, filter ContactFilter, limit, offset int) ([]*VendorContact, int64, error)
+	GetContact(ctx context.Context, vendorID, contactID string) (*VendorContact, error)
+	AddContact(ctx context.Context, contact *VendorContact) error
+	UpdateContact(ctx context.Context, contact *VendorContact) error
+	DeleteContact(ctx context.Context, vendorID, contactID string) error
+	LockVendorContacts(ctx context.Context, vendorID string) error
+	FindContactByEmail(ctx context.Context, vendorID, email string) (*VendorContact, error)
+	ListDuplicateContactEmails(ctx context.Context, entityID string) ([]*DuplicateContactEmail, error)
+	ListEntityContacts(ctx context.Context, entityID string) ([]*EntityContact, error)
+	GetPaymentTerms(ctx context.Context) ([]*PaymentTerm, error)
+	ListPaymentTerms(ctx context.Context, onlyActive bool) ([]*PaymentTerm, error)
+	GetPaymentTermByCode(ctx context.Context, code string, onlyActive bool) (*PaymentTerm, error)
+	CountPaymentTermUsage(ctx context.Context, code string) ([]*PaymentTermUsage, error)
+	ReplacePaymentTerm(ctx context.Context, from, to string, updatedBy *string) ([]*ReassignedVendor, error)
+	DeactivatePaymentTerm(ctx context.Context, code string) error
+
+	// External system refs
+	SetExternalRef(ctx context.Context, ref *VendorExternalRef) error
+	GetExternalRefs(ctx context.Context, vendorID, entityID string) ([]*VendorExternalRef, error)
+	DeleteExternalRef(ctx context.Context, vendorID, entityID, system string) error
+	DeleteAllExternalRefs(ctx context.Context, vendorID, entityID string) error
+	GetByExternalRef(ctx context.Context, entityID, system, externalID string) (*Vendor, error)
+
+	// Transfers, audit log and outbox
+	TransferVendor(ctx context.Context, vendorID, fromEntityID, toEntityID, vendorCode string, updatedBy *string) (*Vendor, error)
+	MoveExternalRefs(ctx context.Context, vendorID, toEntityID string) error
+	MoveBalanceLedger(ctx context.Context, vendorID, toEntityID string) error
+	InsertChangeTombstone(ctx context.Context, entityID, vendorID, vendorCode string) error
+	InsertAuditEntry(ctx context.Context, entry *AuditEntry) error
+	ListAuditEntries(ctx context.Context, entityID, action string, since time.Time, limit int) ([]*AuditEntry, error)
+	InsertEvent(ctx context.Context, event *VendorEvent) error
+
+	// Entity settings
+	GetRetentionSettings(ctx context.Context, entityID string) (*RetentionSettings, error)
+	UpsertRetentionSettings(ctx context.Context, settings *RetentionSettings) error
+	GetValidationSettings(ctx context.Context, entityID string) (*ValidationSettings, error)
+	UpsertValidationSettings(ctx context.Context, settings *ValidationSettings) error
+	GetEntitySettings(ctx context.Context, entityID string) (*EntitySettings, error)
+	UpsertEntitySettings(ctx context.Context, settings *EntitySettings) error
+	GetApprovalPolicy(ctx context.Context, entityID string) (*ApprovalPolicy, error)
+	UpsertApprovalPolicy(ctx context.Context, policy *ApprovalPolicy) error
+	ListVendorTypes(ctx context.Context, entityID string) ([]*VendorType, error)
+	CreateVendorType(ctx context.Context, vt *VendorType) error
+	UpdateVendorType(ctx context.Context, vt *VendorType) error
+	DeleteVendorType(ctx context.Context, entityID, code string) error
+	CountVendorsByType(ctx context.Context, entityID, code string) (int64, error)
+	ListImportTemplates(ctx context.Context, entityID string) ([]*ImportTemplate, error)
+	GetImportTemplate(ctx context.Context, id, entityID string) (*ImportTemplate, error)
+	CreateImportTemplate(ctx context.Context, t *ImportTemplate) error
+	UpdateImportTemplate(ctx context.Context, t *ImportTemplate) error
+	DeleteImportTemplate(ctx context.Context, id, entityID string) error
+	ListVendorTemplates(ctx context.Context, entityID string) ([]*VendorTemplate, error)
+	GetVendorTemplate(ctx context.Context, id, entityID string) (*VendorTemplate, error)
+	CreateVendorTemplate(ctx context.Context, t *VendorTemplate) error
+	UpdateVendorTemplate(ctx context.Context, t *VendorTemplate) error
+	DeleteVendorTemplate(ctx context.Context, id, entityID string) error
+
+	// Retention purges
+	CountPurgeableVendors(ctx context.Context, defaultDays int) (eligible, blocked int64, err error)
+	PurgeDeletedVendors(ctx context.Context, defaultDays, batchSize int) (int64, error)
+	CountPurgeableTombstones(ctx context.Context, defaultDays int) (int64, error)
+	PurgeTombstones(ctx context.Context, defaultDays, batchSize int) (int64, error)
+	CountPurgeableAuditLog(ctx context.Context, defaultDays int) (int64, error)
+	PurgeAuditLog(ctx context.Context, defaultDays, batchSize int) (int64, error)
+}
+
+var _ Store = (*VendorRepository)(nil)
@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// Suspected test vendor rules, each naming a sign of a vendor created for
+// testing or training rather than a real one
+const (
+	SuspectedTestPlaceholderName  = "placeholder_name"
+	SuspectedTestPlaceholderTaxID = "placeholder_tax_id"
+	SuspectedTestExampleEmail     = "example_email"
+	SuspectedTestConfirmed        = "confirmed_test"
+	SuspectedTestNoActivity       = "no_activity"
+)
+
+// TestVendorTag is the tag of vendors confirmed to be test vendors
+const TestVendorTag = "test-vendor"
+
+// SuspectedTestRule is a heuristic for test vendors. Condition is the check in
+// SQL over the vendors row, true when the vendor matches; Matches is the same
+// check in Go for stores without SQL. Supporting rules only add to the reasons
+// of vendors matching another rule and never flag a vendor alone.
+type SuspectedTestRule struct {
+	Name       string
+	Message    string
+	Condition  string
+	Matches    func(v *Vendor, signals *SuspectedTestSignals) bool
+	Supporting bool
+}
+
+// SuspectedTestSignals are the facts about a vendor kept outside the vendor
+// row that suspected test rules look at
+type SuspectedTestSignals struct {
+	// HasBalanceActivity is set when the vendor's balance ledger has entries
+	HasBalanceActivity bool
+}
+
+// placeholderNameWords are the words that mark a name as a placeholder, and
+// fillerNameWords those a placeholder name may carry besides them and
+// numbers. A name made only of such words, with at least one placeholder
+// word, is a placeholder: "Test Vendor 2" is one, "Testa Engineering" and
+// "Test Equipment Rentals" are not.
+var (
+	placeholderNameWords = []string{
+		"test", "testing", "asdf", "asdfg", "qwerty", "dummy", "placeholder", "fake", "sample",
+		"foo", "foobar", "xxx", "zzz", "tbd", "dnu", "do not use",
+	}
+	fillerNameWords = []string{
+		"vendor", "supplier", "company", "account", "new", "co", "corp", "inc", "llc", "ltd", "do", "not", "use",
+	}
+)
+
+// placeholderTaxIDs are the tax IDs, as digits only, entered when the real
+// one was not at hand: repeated digits and sequences
+var placeholderTaxIDs = func() []string {
+	ids := []string{"123456789", "987654321"}
+	for d := '0'; d <= '9'; d++ {
+		ids = append(ids, strings.Repeat(string(d), 9))
+	}
+	return ids
+}()
+
+// exampleEmailPattern matches email addresses at domains reserved for
+// documentation and testing, or common throwaway ones
+const exampleEmailPattern = `@(([a-z0-9-]+\.)*(example\.(com|net|org)|test\.com|mailinator\.com)|[a-z0-9.-]+\.(test|example|invalid|localhost))$`
+
+// Patterns of placeholder names, valid both as Go and Postgres regular
+// expressions; matched case-insensitively
+var (
+	placeholderNameOnly = func() string {
+		words := make([]string, 0, len(placeholderNameWords)+len(fillerNameWords)+1)
+		for _, word := range slices.Concat(placeholderNameWords, fillerNameWords) {
+			if !strings.Contains(word, " ") {
+				words = append(words, word)
+			}
+		}
+		token := "(" + strings.Join(append(words, "[0-9]+"), "|") + ")"
+		return `^\W*` + token + `(\W+` + token + `)*\W*$`
+	}()
+	placeholderNameWord = `(^|\W)(` + strings.Join(placeholderNameWords, "|") + `)(\W|$)`
+
+	placeholderNameOnlyRE = regexp.MustCompile(`(?i)` + placeholderNameOnly)
+	placeholderNameWordRE = regexp.MustCompile(`(?i)` + placeholderNameWord)
+	exampleEmailRE        = regexp.MustCompile(exampleEmailPattern)
+)
+
+// SuspectedTestRules are the suspected test vendor rules in the order reasons
+// are listed
+var SuspectedTestRules = []SuspectedTestRule{
+	{
+		Name:    SuspectedTestPlaceholderName,
+		Message: "the vendor name is a placeholder such as \"test\" or \"asdf\"",
+		Condition: fmt.Sprintf(`(vendors.vendor_name ~* '%s' AND vendors.vendor_name ~* '%s')`,
+			placeholderNameOnly, placeholderNameWord),
+		Matches: func(v *Vendor, _ *SuspectedTestSignals) bool {
+			return placeholderNameOnlyRE.MatchString(v.VendorName) && placeholderNameWordRE.MatchString(v.VendorName)
+		},
+	},
+	{
+		Name:    SuspectedTestPlaceholderTaxID,
+		Message: "the tax ID is a placeholder such as 00-0000000",
+		Condition: fmt.Sprintf(`regexp_replace(coalesce(vendors.tax_id, ''), '[^0-9]', '', 'g') IN ('%s')`,
+			strings.Join(placeholderTaxIDs, "', '")),
+		Matches: func(v *Vendor, _ *SuspectedTestSignals) bool {
+			return v.TaxID != nil && slices.Contains(placeholderTaxIDs, digitsOnly(*v.TaxID))
+		},
+	},
+	{
+		Name:    SuspectedTestExampleEmail,
+		Message: "the email is at a reserved or throwaway domain such as example.com",
+		Condition: fmt.Sprintf(`(lower(btrim(coalesce(vendors.email, ''))) ~ '%[1]s' OR lower(btrim(coalesce(vendors.remittance_email, ''))) ~ '%[1]s')`,
+			exampleEmailPattern),
+		Matches: func(v *Vendor, _ *SuspectedTestSignals) bool {
+			for _, email := range []*string{v.Email, v.RemittanceEmail} {
+				if email != nil && exampleEmailRE.MatchString(strings.ToLower(strings.TrimSpace(*email))) {
+					return true
+				}
+			}
+			return false
+		},
+	},
+	{
+		Name:      SuspectedTestConfirmed,
+		Message:   "the vendor is tagged " + TestVendorTag,
+		Condition: `'` + TestVendorTag + `' = ANY(vendors.tags)`,
+		Matches: func(v *Vendor, _ *SuspectedTestSignals) bool {
+			return slices.Contains(v.Tags, TestVendorTag)
+		},
+	},
+	{
+		Name:    SuspectedTestNoActivity,
+		Message: "the vendor has never had a balance",
+		Condition: `(vendors.current_balance = 0 AND NOT EXISTS (
+			SELECT 1 FROM vendor_balance_transactions t WHERE t.vendor_id = vendors.id
+		))`,
+		Matches: func(v *Vendor, signals *SuspectedTestSignals) bool {
+			return v.CurrentBalance == 0 && !signals.HasBalanceActivity
+		},
+		Supporting: true,
+	},
+}
+
+// digitsOnly returns the digits of s
+func digitsOnly(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// suspectedTestCondition is the SQL check flagging a vendor as a suspected
+// test vendor: any rule but the supporting ones
+func suspectedTestCondition() string {
+	var conditions []string
+	for _, rule := range SuspectedTestRules {
+		if !rule.Supporting {
+			conditions = append(conditions, "COALESCE("+rule.Condition+", FALSE)")
+		}
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")"
+}
+
+// IsSuspectedTestVendor reports whether a vendor matches a suspected test
+// rule. Supporting rules, the only ones looking at signals, are left out.
+func IsSuspectedTestVendor(v *Vendor) bool {
+	for _, rule := range SuspectedTestRules {
+		if !rule.Supporting && rule.Matches(v, &SuspectedTestSignals{}) {
+			return true
+		}
+	}
+	return false
+}
+
+// SuspectedTestReasons returns the reasons of the rules a vendor matches, or
+// none when it only matches supporting rules
+func SuspectedTestReasons(v *Vendor, signals *SuspectedTestSignals) []SuspectedTestReason {
+	reasons := make([]SuspectedTestReason, 0)
+	flagged := false
+	for _, rule := range SuspectedTestRules {
+		if rule.Matches(v, signals) {
+			reasons = append(reasons, SuspectedTestReason{Rule: rule.Name, Message: rule.Message})
+			flagged = flagged || !rule.Supporting
+		}
+	}
+	if !flagged {
+		return nil
+	}
+	return reasons
+}
+
+// SuspectedTestReason is a suspected test rule a vendor matches
+type SuspectedTestReason struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// SuspectedTestVendor is a vendor flagged as a probable test vendor, with the
+// rules it matches
+type SuspectedTestVendor struct {
+	ID         string                `json:"id"`
+	VendorCode string                `json:"vendor_code"`
+	VendorName string                `json:"vendor_name"`
+	Status     string                `json:"status"`
+	Reasons    []SuspectedTestReason `json:"reasons"`
+}
+
+// ListSuspectedTestVendors retrieves a page of the live vendors of an entity
+// flagged as probable test vendors, ordered by vendor code, with how many
+// there are
+func (r *VendorRepository) ListSuspectedTestVendors(ctx context.Context, entityID string, limit, offset int) ([]*SuspectedTestVendor, int64, error) {
+	where := `WHERE entity_id = $1 AND deleted_at IS NULL AND ` + suspectedTestCondition()
+
+	var total int64
+	if err := r.reader(ctx).QueryRow(ctx, `SELECT COUNT(*) FROM vendors `+where, entityID).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count suspected test vendors")
+	}
+
+	columns := make([]string, len(SuspectedTestRules))
+	for i, rule := range SuspectedTestRules {
+		columns[i] = "COALESCE(" + rule.Condition + ", FALSE)"
+	}
+	query := `
+		SELECT id, vendor_code, vendor_name, status, ` + strings.Join(columns, ", ") + `
+		FROM vendors
+		` + where + `
+		ORDER BY vendor_code, id
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, entityID, limit, offset)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to list suspected test vendors")
+	}
+	defer rows.Close()
+
+	vendors := make([]*SuspectedTestVendor, 0)
+	for rows.Next() {
+		v := &SuspectedTestVendor{Reasons: make([]SuspectedTestReason, 0)}
+		matches := make([]bool, len(SuspectedTestRules))
+		dest := []interface{}{&v.ID, &v.VendorCode, &v.VendorName, &v.Status}
+		for i := range matches {
+			dest = append(dest, &matches[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan suspected test vendor")
+		}
+		for i, rule := range SuspectedTestRules {
+			if matches[i] {
+				v.Reasons = append(v.Reasons, SuspectedTestReason{Rule: rule.Name, Message: rule.Message})
+			}
+		}
+		vendors = append(vendors, v)
+	}
+
+	return vendors, total, nil
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func strPtr(s string) *string { return &s }
+
+func reasonRules(reasons []SuspectedTestReason) []string {
+	rules := make([]string, len(reasons))
+	for i, reason := range reasons {
+		rules[i] = reason.Rule
+	}
+	return rules
+}
+
+func TestPlaceholderNames(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"test", true},
+		{"TEST", true},
+		{"Test Vendor", true},
+		{"Test Vendor 2", true},
+		{"asdf", true},
+		{"  qwerty  ", true},
+		{"Dummy Supplier Inc", true},
+		{"DO NOT USE", true},
+		{"Foo Corp", true},
+		{"test-123", true},
+		{"New Vendor", false},
+		{"Vendor 42", false},
+		{"Testa Engineering", false},
+		{"Test Equipment Rentals", false},
+		{"Contest Productions", false},
+		{"Attestation Services LLC", false},
+		{"Sample Brewing Co", false},
+		{"Foobar Labs", false},
+		{"Acme Testing Laboratories", false},
+		{"Zzz Sleep Clinic", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Vendor{VendorName: tt.name, CurrentBalance: 100}
+			if got := IsSuspectedTestVendor(v); got != tt.want {
+				t.Errorf("IsSuspectedTestVendor(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlaceholderTaxIDs(t *testing.T) {
+	tests := []struct {
+		taxID string
+		want  bool
+	}{
+		{"00-0000000", true},
+		{"999999999", true},
+		{"12-3456789", true},
+		{"987-65-4321", true},
+		{"12-3456780", false},
+		{"00-0000001", false},
+		{"0000000", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.taxID, func(t *testing.T) {
+			v := &Vendor{VendorName: "Northwind Traders", TaxID: strPtr(tt.taxID), CurrentBalance: 100}
+			if got := IsSuspectedTestVendor(v); got != tt.want {
+				t.Errorf("IsSuspectedTestVendor(tax ID %q) = %v, want %v", tt.taxID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExampleEmails(t *testing.T) {
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{"ap@example.com", true},
+		{"AP@Example.ORG", true},
+		{"billing@mail.example.net", true},
+		{"x@mailinator.com", true},
+		{"x@vendor.test", true},
+		{"x@host.localhost", true},
+		{"ap@example.company.com", false},
+		{"ap@myexample.com", false},
+		{"ap@testing.com", false},
+		{"ap@northwind.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			v := &Vendor{VendorName: "Northwind Traders", Email: strPtr(tt.email), CurrentBalance: 100}
+			if got := IsSuspectedTestVendor(v); got != tt.want {
+				t.Errorf("IsSuspectedTestVendor(email %q) = %v, want %v", tt.email, got, tt.want)
+			}
+		})
+	}
+
+	v := &Vendor{VendorName: "Northwind Traders", RemittanceEmail: strPtr("remit@example.com"), CurrentBalance: 100}
+	if !IsSuspectedTestVendor(v) {
+		t.Error("a remittance email at example.com should flag the vendor")
+	}
+}
+
+func TestSuspectedTestReasons(t *testing.T) {
+	t.Run("no activity alone does not flag", func(t *testing.T) {
+		v := &Vendor{VendorName: "Northwind Traders"}
+		if reasons := SuspectedTestReasons(v, &SuspectedTestSignals{}); reasons != nil {
+			t.Errorf("reasons = %v, want none", reasonRules(reasons))
+		}
+	})
+
+	t.Run("no activity supports another rule", func(t *testing.T) {
+		v := &Vendor{VendorName: "asdf", TaxID: strPtr("00-0000000")}
+		got := reasonRules(SuspectedTestReasons(v, &SuspectedTestSignals{}))
+		want := []string{SuspectedTestPlaceholderName, SuspectedTestPlaceholderTaxID, SuspectedTestNoActivity}
+		if !slices.Equal(got, want) {
+			t.Errorf("reasons = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("balance activity is not no activity", func(t *testing.T) {
+		v := &Vendor{VendorName: "test"}
+		got := reasonRules(SuspectedTestReasons(v, &SuspectedTestSignals{HasBalanceActivity: true}))
+		want := []string{SuspectedTestPlaceholderName}
+		if !slices.Equal(got, want) {
+			t.Errorf("reasons = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("confirmed test vendor", func(t *testing.T) {
+		v := &Vendor{VendorName: "Northwind Traders", Tags: []string{"pilot", TestVendorTag}, CurrentBalance: 100}
+		got := reasonRules(SuspectedTestReasons(v, &SuspectedTestSignals{}))
+		want := []string{SuspectedTestConfirmed}
+		if !slices.Equal(got, want) {
+			t.Errorf("reasons = %v, want %v", got, want)
+		}
+	})
+}
+
+// TestPlaceholderNamePatternsArePortable checks the name patterns stay within
+// the syntax shared by Go and Postgres regular expressions, since the same
+// strings are used in SQL conditions
+func TestPlaceholderNamePatternsArePortable(t *testing.T) {
+	for _, pattern := range []string{placeholderNameOnly, placeholderNameWord, exampleEmailPattern} {
+		if _, err := regexp.Compile(pattern); err != nil {
+			t.Errorf("pattern %q does not compile: %v", pattern, err)
+		}
+		if regexp.MustCompile(`\(\?|\\[pPdDbB]|'`).MatchString(pattern) {
+			t.Errorf("pattern %q uses syntax not shared with Postgres", pattern)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// TagCount is a tag and the number of live vendors of an entity carrying it
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
+
+// TaggedVendor is a vendor changed by a tag add, rename or delete
+type TaggedVendor struct {
+	ID         string `json:"id"`
+	VendorCode string `json:"vendor_code"`
+}
+
+// ListTagCounts counts the live vendors of an entity by tag, most used first
+func (r *VendorRepository) ListTagCounts(ctx context.Context, entityID string) ([]*TagCount, error) {
+	query := `
+		SELECT tag, COUNT(*)
+		FROM vendors, unnest(tags) AS tag
+		WHERE entity_id = $1 AND deleted_at IS NULL
+		GROUP BY tag
+		ORDER BY COUNT(*) DESC, tag
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, entityID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to count vendor tags")
+	}
+	defer rows.Close()
+
+	counts := make([]*TagCount, 0)
+	for rows.Next() {
+		c := &TagCount{}
+		if err := rows.Scan(&c.Tag, &c.Count); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan vendor tag count")
+		}
+		counts = append(counts, c)
+	}
+
+	return counts, nil
+}
+
+// RenameTag replaces tag from with to on every live vendor of an entity in a
+// single statement. Vendors already tagged to just lose from.
+func (r *VendorRepository) RenameTag(ctx context.Context, entityID, from, to string, updatedBy *string) ([]*TaggedVendor, error) {
+	query := `
+		UPDATE vendors
+		SET tags = CASE WHEN $3 = ANY(tags) THEN array_remove(tags, $2) ELSE array_replace(tags, $2, $3) END,
+		    updated_by = $4, updated_at = NOW()
+		WHERE entity_id = $1 AND deleted_at IS NULL AND $2 = ANY(tags)
+		RETURNING id, vendor_code
+	`
+	return r.updateTags(ctx, "rename", query, entityID, from, to, updatedBy)
+}
+
+// DeleteTag removes a tag from every live vendor of an entity in a single
+// statement
+func (r *VendorRepository) DeleteTag(ctx context.Context, entityID, tag string, updatedBy *string) ([]*TaggedVendor, error) {
+	query := `
+		UPDATE vendors
+		SET tags = array_remove(tags, $2), updated_by = $3, updated_at = NOW()
+		WHERE entity_id = $1 AND deleted_at IS NULL AND $2 = ANY(tags)
+		RETURNING id, vendor_code
+	`
+	return r.updateTags(ctx, "delete", query, entityID, tag, updatedBy)
+}
+
+// AddTag adds a tag to the live vendors of an entity with the given IDs in a
+// single statement. Vendors already tagged, or carrying maxTags tags, are left
+// as they are.
+func (r *VendorRepository) AddTag(ctx context.Context, entityID string, vendorIDs []string, tag string, maxTags int, updatedBy *string) ([]*TaggedVendor, error) {
+	query := `
+		UPDATE vendors
+		SET tags = array_append(coalesce(tags, '{}'), $3), updated_by = $5, updated_at = NOW()
+		WHERE entity_id = $1 AND deleted_at IS NULL AND id = ANY($2)
+		  AND NOT $3 = ANY(coalesce(tags, '{}')) AND cardinality(coalesce(tags, '{}')) < $4
+		RETURNING id, vendor_code
+	`
+	return r.updateTags(ctx, "add", query, entityID, vendorIDs, tag, maxTags, updatedBy)
+}
+
+func (r *VendorRepository) updateTags(ctx context.Context, op, query string, args ...interface{}) ([]*TaggedVendor, error) {
+	rows, err := r.q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to "+op+" vendor tag")
+	}
+	defer rows.Close()
+
+	vendors := make([]*TaggedVendor, 0)
+	for rows.Next() {
+		v := &TaggedVendor{}
+		if err := rows.Scan(&v.ID, &v.VendorCode); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan tagged vendor")
+		}
+		vendors = append(vendors, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to "+op+" vendor tag")
+	}
+
+	return vendors, nil
+}
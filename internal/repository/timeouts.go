@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"expvar"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// ErrCodeQueryTimeout marks statements that exceeded their query budget
+const ErrCodeQueryTimeout errors.ErrorCode = "QUERY_TIMEOUT"
+
+// QueryTimeouts are the per-statement budgets of repository operations
+type QueryTimeouts struct {
+	// Read bounds statements that only read
+	Read time.Duration
+	// Write bounds statements that insert, update or delete
+	Write time.Duration
+	// Bulk bounds every statement issued under WithBulkBudget
+	Bulk time.Duration
+}
+
+// DefaultQueryTimeouts are used when no budgets are configured
+var DefaultQueryTimeouts = QueryTimeouts{
+	Read:  2 * time.Second,
+	Write: 5 * time.Second,
+	Bulk:  30 * time.Second,
+}
+
+// queryBudgetExceeded counts statements cancelled by their budget, by
+// repository method; served on /debug/vars
+var queryBudgetExceeded = expvar.NewMap("vendors_query_budget_exceeded")
+
+// QueryTimeoutError is returned when a statement exceeds its budget. Repository
+// methods wrap it, so use IsQueryTimeout to detect it.
+type QueryTimeoutError struct {
+	Op     string
+	Budget time.Duration
+	Err    error
+}
+
+func (e *QueryTimeoutError) Error() string {
+	return fmt.Sprintf("%s: query exceeded its %s budget", e.Op, e.Budget)
+}
+
+func (e *QueryTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// IsQueryTimeout reports whether err was caused by a query budget
+func IsQueryTimeout(err error) bool {
+	var timeoutErr *QueryTimeoutError
+	return stderrors.As(err, &timeoutErr)
+}
+
+type bulkBudgetKey struct{}
+
+// WithBulkBudget marks ctx so that repository statements use the bulk budget.
+// Statements stay cancellable through ctx, e.g. on client disconnect.
+func WithBulkBudget(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bulkBudgetKey{}, true)
+}
+
+// budget returns the timeout of a statement
+func (t QueryTimeouts) budget(ctx context.Context, sql string) time.Duration {
+	if bulk, _ := ctx.Value(bulkBudgetKey{}).(bool); bulk {
+		return t.Bulk
+	}
+	if isWriteStatement(sql) {
+		return t.Write
+	}
+	return t.Read
+}
+
+// isWriteStatement reports whether sql modifies data, including CTEs with
+// data-modifying clauses and SELECT ... FOR UPDATE
+func isWriteStatement(sql string) bool {
+	upper := strings.ToUpper(sql)
+	for _, keyword := range []string{"INSERT ", "UPDATE ", "DELETE "} {
+		if strings.Contains(upper, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// queryBudget is the deadline of a single statement
+type queryBudget struct {
+	parent context.Context
+	ctx    context.Context
+	cancel context.CancelFunc
+	op     string
+	budget time.Duration
+}
+
+func startBudget(ctx context.Context, timeouts QueryTimeouts, op, sql string) *queryBudget {
+	b := &queryBudget{parent: ctx, ctx: ctx, cancel: func() {}, op: op}
+	if b.budget = timeouts.budget(ctx, sql); b.budget > 0 {
+		b.ctx, b.cancel = context.WithTimeout(ctx, b.budget)
+	}
+	return b
+}
+
+// check converts err into a QueryTimeoutError when the statement's own budget,
+// rather than the caller's context, cancelled it
+func (b *queryBudget) check(err error) error {
+	if err == nil || b.ctx.Err() != context.DeadlineExceeded || b.parent.Err() != nil {
+		return err
+	}
+
+	queryBudgetExceeded.Add(b.op, 1)
+	return &QueryTimeoutError{Op: b.op, Budget: b.budget, Err: err}
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+)
+
+// timedQuerier bounds every statement by its query budget and records its
+// duration and rows in the request timings and query metrics, keyed by the
+// repository method that issued it and, for the replica, its target. Values
+// Postgres cannot parse fail with an InvalidInputError, writes breaking a
+// foreign key with a ForeignKeyError.
+type timedQuerier struct {
+	q        querier
+	timeouts QueryTimeouts
+	target   string
+	slow     *slowQueryLog
+}
+
+func (t timedQuerier) Begin(ctx context.Context) (pgx.Tx, error) {
+	return t.q.Begin(ctx)
+}
+
+func (t timedQuerier) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	start := time.Now()
+	b := t.start(ctx, sql)
+	defer b.cancel()
+
+	tag, err := t.q.Exec(b.ctx, sql, arguments...)
+	recordQuery(b, t.slow, time.Since(start), tag.RowsAffected(), err)
+	return tag, statementError(b.check(err))
+}
+
+func (t timedQuerier) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	start := time.Now()
+	b := t.start(ctx, sql)
+
+	rows, err := t.q.Query(b.ctx, sql, args...)
+	if err != nil {
+		b.cancel()
+		recordQuery(b, t.slow, time.Since(start), 0, err)
+		return nil, statementError(b.check(err))
+	}
+	return &timedRows{Rows: rows, budget: b, slow: t.slow, start: start}, nil
+}
+
+func (t timedQuerier) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	start := time.Now()
+	b := t.start(ctx, sql)
+	return &timedRow{row: t.q.QueryRow(b.ctx, sql, args...), budget: b, slow: t.slow, start: start}
+}
+
+// start opens the budget of a statement issued by the querier's caller. Writes
+// route the request's later reads to the primary.
+func (t timedQuerier) start(ctx context.Context, sql string) *queryBudget {
+	op := callerOp(3)
+	if t.target != "" {
+		op += "@" + t.target
+	}
+	if isWriteStatement(sql) {
+		markWritten(ctx)
+	}
+	return startBudget(ctx, t.timeouts, op, sql)
+}
+
+// timedRows keeps the budget alive until the result set is consumed
+type timedRows struct {
+	pgx.Rows
+	budget *queryBudget
+	slow   *slowQueryLog
+	start  time.Time
+	rows   int64
+	done   bool
+}
+
+func (r *timedRows) Next() bool {
+	if r.Rows.Next() {
+		r.rows++
+		return true
+	}
+	r.finish()
+	return false
+}
+
+func (r *timedRows) Close() {
+	r.Rows.Close()
+	r.finish()
+}
+
+func (r *timedRows) Err() error {
+	return statementError(r.budget.check(r.Rows.Err()))
+}
+
+func (r *timedRows) finish() {
+	if !r.done {
+		r.done = true
+		r.budget.cancel()
+		recordQuery(r.budget, r.slow, time.Since(r.start), r.rows, r.Rows.Err())
+	}
+}
+
+// timedRow finishes the statement when it is scanned, since QueryRow defers
+// errors and execution to Scan
+type timedRow struct {
+	row    pgx.Row
+	budget *queryBudget
+	slow   *slowQueryLog
+	start  time.Time
+}
+
+func (r *timedRow) Scan(dest ...interface{}) error {
+	defer r.budget.cancel()
+
+	err := r.row.Scan(dest...)
+	var rows int64
+	if err == nil {
+		rows = 1
+	}
+	recordQuery(r.budget, r.slow, time.Since(r.start), rows, err)
+	return statementError(r.budget.check(err))
+}
+
+// recordQuery records a finished statement in the request timings and the
+// duration histogram of its method, and logs it when slow
+func recordQuery(b *queryBudget, slow *slowQueryLog, d time.Duration, rows int64, err error) {
+	reqlog.RecordQuery(b.parent, b.op, d, rows)
+	observeQuery(b.op, d)
+	slow.check(b.parent, b.op, d, rows, err)
+}
+
+// callerOp names the repository method skip frames up the stack, e.g. "GetByID"
+func callerOp(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip)
+	if !ok {
+		return "unknown"
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return "unknown"
+	}
+
+	// github.com/.../repository.(*VendorRepository).GetByID.func1 -> GetByID
+	name := fn.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	parts := strings.Split(name, ".")
+	for i := len(parts) - 1; i > 0; i-- {
+		if !strings.HasPrefix(parts[i], "func") && !strings.HasPrefix(parts[i], "(") {
+			return parts[i]
+		}
+	}
+	return name
+}
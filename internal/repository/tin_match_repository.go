@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// TINMatch is the stored TIN matching result of a vendor. Fingerprint
+// identifies the tax ID and legal name checked; the result lapses once they
+// change.
+type TINMatch struct {
+	VendorID    string    `json:"-"`
+	EntityID    string    `json:"-"`
+	Status      string    `json:"status"`
+	Code        *string   `json:"code,omitempty"`
+	Fingerprint string    `json:"-"`
+	CheckedAt   time.Time `json:"checked_at,omitzero"`
+}
+
+// UpsertTINMatch stores the TIN matching result of a vendor. A status or
+// fingerprint differing from the stored ones advances the vendor's
+// change_seq, since the result is returned with the vendor; a recheck with
+// the same outcome does not.
+func (r *VendorRepository) UpsertTINMatch(ctx context.Context, match *TINMatch) error {
+	query := `
+		WITH previous AS (
+			SELECT status, fingerprint FROM vendor_tin_matches WHERE vendor_id = $1
+		), upserted AS (
+			INSERT INTO vendor_tin_matches (vendor_id, entity_id, status, code, fingerprint, checked_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (vendor_id) DO UPDATE SET
+				entity_id = EXCLUDED.entity_id,
+				status = EXCLUDED.status,
+				code = EXCLUDED.code,
+				fingerprint = EXCLUDED.fingerprint,
+				checked_at = EXCLUDED.checked_at
+		)
+		SELECT NOT EXISTS (SELECT 1 FROM previous WHERE status = $3 AND fingerprint = $5)
+	`
+
+	var changed bool
+	if err := r.q.QueryRow(ctx, query, match.VendorID, match.EntityID, match.Status, match.Code, match.Fingerprint, match.CheckedAt).Scan(&changed); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save TIN match")
+	}
+	if changed {
+		return r.touchVendor(ctx, match.VendorID)
+	}
+	return nil
+}
+
+// ListTINMatches retrieves the stored TIN matching results of vendors by
+// vendor ID
+func (r *VendorRepository) ListTINMatches(ctx context.Context, vendorIDs []string) (map[string]*TINMatch, error) {
+	query := `
+		SELECT vendor_id, entity_id, status, code, fingerprint, checked_at
+		FROM vendor_tin_matches
+		WHERE vendor_id = ANY($1)
+	`
+
+	matches := make(map[string]*TINMatch, len(vendorIDs))
+	if len(vendorIDs) == 0 {
+		return matches, nil
+	}
+
+	rows, err := r.reader(ctx).Query(ctx, query, vendorIDs)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list TIN matches")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		match := &TINMatch{}
+		if err := rows.Scan(&match.VendorID, &match.EntityID, &match.Status, &match.Code, &match.Fingerprint, &match.CheckedAt); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan TIN match")
+		}
+		matches[match.VendorID] = match
+	}
+
+	return matches, nil
+}
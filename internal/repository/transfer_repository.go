@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// TransferVendor moves a vendor to another entity under the given vendor code.
+// Contacts and documents reference the vendor by ID and move with it.
+func (r *VendorRepository) TransferVendor(ctx context.Context, vendorID, fromEntityID, toEntityID, vendorCode string, updatedBy *string) (*Vendor, error) {
+	query := `
+		UPDATE vendors
+		SET entity_id = $3, vendor_code = $4, updated_by = $5, updated_at = NOW()
+		WHERE id = $1 AND entity_id = $2 AND deleted_at IS NULL
+		RETURNING ` + vendorColumns
+
+	vendor, err := scanVendor(r.q.QueryRow(ctx, query, vendorID, fromEntityID, toEntityID, vendorCode, updatedBy))
+	if err == pgx.ErrNoRows {
+		return nil, errors.NotFound("vendor", vendorID)
+	}
+
+	var pgErr *pgconn.PgError
+	if stderrors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return nil, errors.AlreadyExists("vendor", vendorCode)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to transfer vendor")
+	}
+
+	return vendor, nil
+}
+
+// MoveExternalRefs re-homes a vendor's external system refs to another entity
+func (r *VendorRepository) MoveExternalRefs(ctx context.Context, vendorID, toEntityID string) error {
+	query := `UPDATE vendor_external_refs SET entity_id = $2 WHERE vendor_id = $1`
+
+	_, err := r.q.Exec(ctx, query, vendorID, toEntityID)
+
+	var pgErr *pgconn.PgError
+	if stderrors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return errors.AlreadyExists("external_ref", "an external ID of the vendor is already mapped in the target entity")
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to move vendor external refs")
+	}
+
+	return nil
+}
+
+// MoveBalanceLedger re-homes a vendor's balance ledger rows to another entity
+func (r *VendorRepository) MoveBalanceLedger(ctx context.Context, vendorID, toEntityID string) error {
+	query := `UPDATE vendor_balance_transactions SET entity_id = $2 WHERE vendor_id = $1`
+
+	if _, err := r.q.Exec(ctx, query, vendorID, toEntityID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to move vendor balance ledger")
+	}
+
+	return nil
+}
+
+// InsertChangeTombstone records in the change feed of entityID that a vendor left
+// the entity without being deleted
+func (r *VendorRepository) InsertChangeTombstone(ctx context.Context, entityID, vendorID, vendorCode string) error {
+	query := `
+		INSERT INTO vendor_change_tombstones (entity_id, vendor_id, vendor_code)
+		VALUES ($1, $2, $3)
+	`
+
+	if _, err := r.q.Exec(ctx, query, entityID, vendorID, vendorCode); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to insert vendor change tombstone")
+	}
+
+	return nil
+}
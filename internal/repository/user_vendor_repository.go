@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// ToggleFavoriteVendor adds a vendor to the favorites of a user in an entity,
+// or removes it when it is one already, and reports whether it is now a
+// favorite
+func (r *VendorRepository) ToggleFavoriteVendor(ctx context.Context, userID, entityID, vendorID string) (bool, error) {
+	query := `
+		WITH removed AS (
+			DELETE FROM vendor_favorites
+			WHERE user_id = $1 AND entity_id = $2 AND vendor_id = $3
+			RETURNING vendor_id
+		)
+		INSERT INTO vendor_favorites (user_id, entity_id, vendor_id)
+		SELECT $1, $2, $3
+		WHERE NOT EXISTS (SELECT 1 FROM removed)
+		ON CONFLICT DO NOTHING
+		RETURNING true
+	`
+
+	var favorite bool
+	err := r.q.QueryRow(ctx, query, userID, entityID, vendorID).Scan(&favorite)
+	if stderrors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, errors.ErrCodeInternal, "failed to toggle favorite vendor")
+	}
+
+	return favorite, nil
+}
+
+// ListFavoriteVendors retrieves the non-deleted favorite vendors of a user in
+// an entity ordered by vendor name
+func (r *VendorRepository) ListFavoriteVendors(ctx context.Context, userID, entityID string) ([]*Vendor, error) {
+	query := `
+		SELECT ` + prefixedVendorColumns("v") + `
+		FROM vendor_favorites f
+		JOIN vendors v ON v.id = f.vendor_id
+		WHERE f.user_id = $1 AND f.entity_id = $2
+		  AND v.entity_id = $2 AND v.deleted_at IS NULL
+		ORDER BY v.vendor_name, v.id
+	`
+
+	return r.listUserVendors(ctx, query, "failed to list favorite vendors", userID, entityID)
+}
+
+// RecordVendorView marks a vendor as viewed by a user in an entity, keeping
+// only the keep latest views of the user in the entity
+func (r *VendorRepository) RecordVendorView(ctx context.Context, userID, entityID, vendorID string, keep int) error {
+	// The trim runs on the snapshot taken before the view is written, so it
+	// keeps keep-1 other views besides the vendor just viewed
+	query := `
+		WITH viewed AS (
+			INSERT INTO vendor_recent_views (user_id, entity_id, vendor_id)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (user_id, entity_id, vendor_id) DO UPDATE SET viewed_at = NOW()
+		)
+		DELETE FROM vendor_recent_views
+		WHERE user_id = $1 AND entity_id = $2 AND vendor_id <> $3
+		  AND vendor_id NOT IN (
+			SELECT vendor_id FROM vendor_recent_views
+			WHERE user_id = $1 AND entity_id = $2 AND vendor_id <> $3
+			ORDER BY viewed_at DESC
+			LIMIT $4 - 1
+		  )
+	`
+
+	if _, err := r.q.Exec(ctx, query, userID, entityID, vendorID, keep); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to record vendor view")
+	}
+
+	return nil
+}
+
+// ListRecentVendors retrieves the non-deleted vendors a user viewed in an
+// entity, most recently viewed first
+func (r *VendorRepository) ListRecentVendors(ctx context.Context, userID, entityID string) ([]*Vendor, error) {
+	query := `
+		SELECT ` + prefixedVendorColumns("v") + `
+		FROM vendor_recent_views rv
+		JOIN vendors v ON v.id = rv.vendor_id
+		WHERE rv.user_id = $1 AND rv.entity_id = $2
+		  AND v.entity_id = $2 AND v.deleted_at IS NULL
+		ORDER BY rv.viewed_at DESC
+	`
+
+	return r.listUserVendors(ctx, query, "failed to list recent vendors", userID, entityID)
+}
+
+// listUserVendors runs a query selecting vendor rows by user and entity
+func (r *VendorRepository) listUserVendors(ctx context.Context, query, message string, userID, entityID string) ([]*Vendor, error) {
+	rows, err := r.reader(ctx).Query(ctx, query, userID, entityID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, message)
+	}
+	defer rows.Close()
+
+	vendors := make([]*Vendor, 0)
+	for rows.Next() {
+		vendor, err := scanVendor(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, message)
+		}
+		vendors = append(vendors, vendor)
+	}
+
+	return vendors, nil
+}
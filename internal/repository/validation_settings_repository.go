@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// ValidationSettings holds the per-entity validation rule overrides. Nil values
+// fall back to the service defaults.
+type ValidationSettings struct {
+	EntityID          string  `json:"entity_id"`
+	ContactMethodRule *string `json:"contact_method_rule"`
+	// StrictAddressValidation turns address validation warnings into errors
+	StrictAddressValidation *bool     `json:"strict_address_validation"`
+	UpdatedBy               *string   `json:"updated_by,omitempty"`
+	UpdatedAt               time.Time `json:"updated_at"`
+}
+
+// GetValidationSettings retrieves the validation settings of an entity. Entities
+// without settings get an empty record, meaning the defaults apply.
+func (r *VendorRepository) GetValidationSettings(ctx context.Context, entityID string) (*ValidationSettings, error) {
+	query := `
+		SELECT entity_id, contact_method_rule, strict_address_validation, updated_by, updated_at
+		FROM entity_validation_settings
+		WHERE entity_id = $1
+	`
+
+	settings := &ValidationSettings{}
+	err := r.q.QueryRow(ctx, query, entityID).Scan(
+		&settings.EntityID,
+		&settings.ContactMethodRule,
+		&settings.StrictAddressValidation,
+		&settings.UpdatedBy,
+		&settings.UpdatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return &ValidationSettings{EntityID: entityID}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get validation settings")
+	}
+
+	return settings, nil
+}
+
+// UpsertValidationSettings creates or replaces the validation settings of an entity
+func (r *VendorRepository) UpsertValidationSettings(ctx context.Context, settings *ValidationSettings) error {
+	query := `
+		INSERT INTO entity_validation_settings (entity_id, contact_method_rule, strict_address_validation, updated_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (entity_id) DO UPDATE SET
+			contact_method_rule = EXCLUDED.contact_method_rule,
+			strict_address_validation = EXCLUDED.strict_address_validation,
+			updated_by = EXCLUDED.updated_by
+		RETURNING updated_at
+	`
+
+	err := r.q.QueryRow(ctx, query,
+		settings.EntityID,
+		settings.ContactMethodRule,
+		settings.StrictAddressValidation,
+		settings.UpdatedBy,
+	).Scan(&settings.UpdatedAt)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save validation settings")
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// VendorChildren counts the records kept apart from a vendor that deleting it
+// has to handle. Bank details and notes are columns of the vendor and go with
+// it.
+type VendorChildren struct {
+	Contacts          int `json:"contacts"`
+	Documents         int `json:"documents"`
+	BankVerifications int `json:"bank_verifications"`
+	// PendingStatusChanges are scheduled status changes not yet applied
+	PendingStatusChanges int `json:"pending_status_changes"`
+}
+
+// Total is the number of child records
+func (c *VendorChildren) Total() int {
+	return c.Contacts + c.Documents + c.BankVerifications + c.PendingStatusChanges
+}
+
+// CountVendorChildren counts the child records of a vendor
+func (r *VendorRepository) CountVendorChildren(ctx context.Context, vendorID string) (*VendorChildren, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM vendor_contacts WHERE vendor_id = $1),
+			(SELECT COUNT(*) FROM vendor_documents WHERE vendor_id = $1),
+			(SELECT COUNT(*) FROM vendor_bank_verifications WHERE vendor_id = $1),
+			(SELECT COUNT(*) FROM scheduled_status_changes WHERE vendor_id = $1 AND state = 'pending')
+	`
+
+	c := &VendorChildren{}
+	err := r.reader(ctx).QueryRow(ctx, query, vendorID).Scan(&c.Contacts, &c.Documents, &c.BankVerifications, &c.PendingStatusChanges)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to count vendor child records")
+	}
+	return c, nil
+}
+
+// DeleteVendorChildren deletes the contacts, documents and bank verifications
+// of a vendor and cancels its pending scheduled status changes, in one
+// statement, returning what it removed
+func (r *VendorRepository) DeleteVendorChildren(ctx context.Context, vendorID string) (*VendorChildren, error) {
+	query := `
+		WITH contacts AS (
+			DELETE FROM vendor_contacts WHERE vendor_id = $1 RETURNING 1
+		), documents AS (
+			DELETE FROM vendor_documents WHERE vendor_id = $1 RETURNING 1
+		), verifications AS (
+			DELETE FROM vendor_bank_verifications WHERE vendor_id = $1 RETURNING 1
+		), changes AS (
+			UPDATE scheduled_status_changes
+			SET state = 'cancelled', processed_at = NOW()
+			WHERE vendor_id = $1 AND state = 'pending'
+			RETURNING 1
+		)
+		SELECT
+			(SELECT COUNT(*) FROM contacts),
+			(SELECT COUNT(*) FROM documents),
+			(SELECT COUNT(*) FROM verifications),
+			(SELECT COUNT(*) FROM changes)
+	`
+
+	c := &VendorChildren{}
+	err := r.q.QueryRow(ctx, query, vendorID).Scan(&c.Contacts, &c.Documents, &c.BankVerifications, &c.PendingStatusChanges)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to delete vendor child records")
+	}
+	return c, nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// vendorFields maps the vendor fields a list can be restricted to, named as
+// their JSON key and column, to the Vendor field holding them
+var vendorFields = map[string]func(v *Vendor) interface{}{
+	"id":                    func(v *Vendor) interface{} { return &v.ID },
+	"entity_id":             func(v *Vendor) interface{} { return &v.EntityID },
+	"vendor_code":           func(v *Vendor) interface{} { return &v.VendorCode },
+	"vendor_name":           func(v *Vendor) interface{} { return &v.VendorName },
+	"legal_name":            func(v *Vendor) interface{} { return &v.LegalName },
+	"doing_business_as":     func(v *Vendor) interface{} { return &v.DoingBusinessAs },
+	"vendor_type":           func(v *Vendor) interface{} { return &v.VendorType },
+	"status":                func(v *Vendor) interface{} { return &v.Status },
+	"tax_id":                func(v *Vendor) interface{} { return &v.TaxID },
+	"is_tax_exempt":         func(v *Vendor) interface{} { return &v.IsTaxExempt },
+	"is_1099_vendor":        func(v *Vendor) interface{} { return &v.Is1099Vendor },
+	"email":                 func(v *Vendor) interface{} { return &v.Email },
+	"remittance_email":      func(v *Vendor) interface{} { return &v.RemittanceEmail },
+	"phone":                 func(v *Vendor) interface{} { return &v.Phone },
+	"fax":                   func(v *Vendor) interface{} { return &v.Fax },
+	"website":               func(v *Vendor) interface{} { return &v.Website },
+	"address_line1":         func(v *Vendor) interface{} { return &v.AddressLine1 },
+	"address_line2":         func(v *Vendor) interface{} { return &v.AddressLine2 },
+	"city":                  func(v *Vendor) interface{} { return &v.City },
+	"state_province":        func(v *Vendor) interface{} { return &v.StateProvince },
+	"postal_code":           func(v *Vendor) interface{} { return &v.PostalCode },
+	"country":               func(v *Vendor) interface{} { return &v.Country },
+	"locale":                func(v *Vendor) interface{} { return &v.Locale },
+	"payment_terms":         func(v *Vendor) interface{} { return &v.PaymentTerms },
+	"payment_method":        func(v *Vendor) interface{} { return &v.PaymentMethod },
+	"currency":              func(v *Vendor) interface{} { return &v.Currency },
+	"accepted_currencies":   func(v *Vendor) interface{} { return &v.AcceptedCurrencies },
+	"credit_limit":          func(v *Vendor) interface{} { return &v.CreditLimit },
+	"credit_limit_currency": func(v *Vendor) interface{} { return &v.CreditLimitCurrency },
+	"current_balance":       func(v *Vendor) interface{} { return &v.CurrentBalance },
+	"bank_name":             func(v *Vendor) interface{} { return &v.BankName },
+	"bank_account_number":   func(v *Vendor) interface{} { return &v.BankAccountNumber },
+	"bank_routing_number":   func(v *Vendor) interface{} { return &v.BankRoutingNumber },
+	"swift_code":            func(v *Vendor) interface{} { return &v.SwiftCode },
+	"iban":                  func(v *Vendor) interface{} { return &v.IBAN },
+	"notes":                 func(v *Vendor) interface{} { return &v.Notes },
+	"tags":                  func(v *Vendor) interface{} { return &v.Tags },
+	"template_id":           func(v *Vendor) interface{} { return &v.TemplateID },
+	"source":                func(v *Vendor) interface{} { return &v.Source },
+	"source_reference":      func(v *Vendor) interface{} { return &v.SourceReference },
+	"created_by":            func(v *Vendor) interface{} { return &v.CreatedBy },
+	"created_at":            func(v *Vendor) interface{} { return &v.CreatedAt },
+	"updated_by":            func(v *Vendor) interface{} { return &v.UpdatedBy },
+	"updated_at":            func(v *Vendor) interface{} { return &v.UpdatedAt },
+	"change_seq":            func(v *Vendor) interface{} { return &v.ChangeSeq },
+}
+
+// VendorBankFields are the selectable vendor fields holding bank details
+var VendorBankFields = []string{"bank_name", "bank_account_number", "bank_routing_number", "swift_code", "iban"}
+
+// IsVendorField reports whether a list can be restricted to the named field
+func IsVendorField(name string) bool {
+	_, ok := vendorFields[name]
+	return ok
+}
+
+// VendorFieldNames returns the fields a list can be restricted to, sorted
+func VendorFieldNames() []string {
+	names := make([]string, 0, len(vendorFields))
+	for name := range vendorFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// selectedVendorColumns returns the column list selecting fields, which must
+// be valid vendor fields
+func selectedVendorColumns(fields []string) string {
+	return strings.Join(fields, ", ")
+}
+
+// scanSelectedVendor scans a row selected with selectedVendorColumns
+func scanSelectedVendor(row rowScanner, fields []string) (*Vendor, error) {
+	vendor := &Vendor{}
+	dest := make([]interface{}, len(fields))
+	for i, field := range fields {
+		dest[i] = vendorFields[field](vendor)
+	}
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return vendor, nil
+}
+
+// Project returns a copy of the vendor holding only the named fields
+func (v *Vendor) Project(fields []string) *Vendor {
+	projected := &Vendor{}
+	for _, field := range fields {
+		if get, ok := vendorFields[field]; ok {
+			reflect.ValueOf(get(projected)).Elem().Set(reflect.ValueOf(get(v)).Elem())
+		}
+	}
+	return projected
+}
+
+// FieldValues returns the named fields of the vendor keyed by their JSON key,
+// for responses restricted to them
+func (v *Vendor) FieldValues(fields []string) map[string]interface{} {
+	values := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if get, ok := vendorFields[field]; ok {
+			values[field] = get(v)
+		}
+	}
+	return values
+}
+
+// StringField returns the value of a text field of a vendor named as in
+// vendorFields, empty when it is unset or not a text field
+func (v *Vendor) StringField(field string) string {
+	get, ok := vendorFields[field]
+	if !ok {
+		return ""
+	}
+	if value, ok := get(v).(**string); ok && *value != nil {
+		return **value
+	}
+	return ""
+}
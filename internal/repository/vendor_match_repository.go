@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// VendorMatchQuery selects the candidates of a fuzzy vendor match
+type VendorMatchQuery struct {
+	EntityID string
+	// Name is compared by trigram similarity with the vendor name, legal
+	// name, DBA name and aliases
+	Name string
+	// TaxIDFragment, when set, is looked for in the vendor's tax ID; both are
+	// compared in NormalizeIdentifier form
+	TaxIDFragment string
+	// BankLast4, when set, is compared with the last four digits of the
+	// vendor's bank account number and IBAN
+	BankLast4 string
+	// MinSimilarity keeps vendors with a name at least this similar unless
+	// their tax ID or bank account matches
+	MinSimilarity float64
+	Limit         int
+}
+
+// VendorMatchCandidate is a vendor found by a fuzzy match, with its most
+// similar name and which identifiers matched
+type VendorMatchCandidate struct {
+	Vendor         *Vendor
+	MatchedOn      string
+	MatchedName    string
+	NameSimilarity float64
+	TaxIDMatch     bool
+	BankMatch      bool
+}
+
+// NormalizeIdentifier returns the form tax IDs and account numbers are
+// compared in: uppercase letters and digits only
+func NormalizeIdentifier(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9', r >= 'A' && r <= 'Z':
+			return r
+		case r >= 'a' && r <= 'z':
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, s)
+}
+
+// TaxIDMatches reports whether the tax ID of v contains the tax ID fragment
+// of q, as the Postgres query does
+func (q VendorMatchQuery) TaxIDMatches(v *Vendor) bool {
+	return q.TaxIDFragment != "" && v.TaxID != nil &&
+		strings.Contains(NormalizeIdentifier(*v.TaxID), q.TaxIDFragment)
+}
+
+// BankMatches reports whether the bank account number or IBAN of v ends in
+// the last four digits of q, as the Postgres query does
+func (q VendorMatchQuery) BankMatches(v *Vendor) bool {
+	if q.BankLast4 == "" {
+		return false
+	}
+	for _, account := range []*string{v.BankAccountNumber, v.IBAN} {
+		if account == nil {
+			continue
+		}
+		digits := strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, *account)
+		if strings.HasSuffix(digits, q.BankLast4) {
+			return true
+		}
+	}
+	return false
+}
+
+// TrigramSimilarity is the similarity function of pg_trgm: the share of the
+// trigrams of the words of a and b the two have in common, from 0 to 1
+func TrigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range ta {
+		if _, ok := tb[t]; ok {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+// trigrams returns the trigrams of the alphanumeric words of s, lowercased and
+// padded with two spaces in front and one behind as pg_trgm does
+func trigrams(s string) map[string]struct{} {
+	set := make(map[string]struct{})
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = struct{}{}
+		}
+	}
+	return set
+}
+
+// FindVendorMatchCandidates retrieves the non-deleted vendors of an entity
+// with a name similar to the query name or a matching tax ID or bank account,
+// identifier matches first and then by name similarity. Each vendor is
+// returned once with its most similar name, the first in MatchedOn order
+// among equally similar ones.
+func (r *VendorRepository) FindVendorMatchCandidates(ctx context.Context, q VendorMatchQuery) ([]*VendorMatchCandidate, error) {
+	query := `
+		WITH names AS (
+			SELECT id AS vendor_id, 1 AS rank, '` + MatchedOnVendorName + `' AS matched_on, vendor_name AS matched_name
+			FROM vendors WHERE entity_id = $1 AND deleted_at IS NULL
+			UNION ALL
+			SELECT id, 2, '` + MatchedOnLegalName + `', legal_name
+			FROM vendors WHERE entity_id = $1 AND deleted_at IS NULL AND legal_name IS NOT NULL
+			UNION ALL
+			SELECT id, 3, '` + MatchedOnDoingBusinessAs + `', doing_business_as
+			FROM vendors WHERE entity_id = $1 AND deleted_at IS NULL AND doing_business_as IS NOT NULL
+			UNION ALL
+			SELECT a.vendor_id, 4, '` + MatchedOnAlias + `', a.alias
+			FROM vendor_aliases a JOIN vendors v ON v.id = a.vendor_id
+			WHERE v.entity_id = $1 AND v.deleted_at IS NULL
+		), best AS (
+			SELECT DISTINCT ON (vendor_id) vendor_id, matched_on, matched_name,
+				similarity(matched_name, $2)::float8 AS name_similarity
+			FROM names
+			ORDER BY vendor_id, name_similarity DESC, rank
+		)
+		SELECT * FROM (
+			SELECT ` + prefixedVendorColumns("v") + `, b.matched_on, b.matched_name, b.name_similarity,
+				($3 <> '' AND regexp_replace(upper(coalesce(v.tax_id, '')), '[^A-Z0-9]', '', 'g') LIKE '%' || $3 || '%') AS tax_id_match,
+				($4 <> '' AND (
+					right(regexp_replace(coalesce(v.bank_account_number, ''), '[^0-9]', '', 'g'), 4) = $4 OR
+					right(regexp_replace(coalesce(v.iban, ''), '[^0-9]', '', 'g'), 4) = $4
+				)) AS bank_match
+			FROM best b
+			JOIN vendors v ON v.id = b.vendor_id
+		) candidates
+		WHERE name_similarity >= $5 OR tax_id_match OR bank_match
+		ORDER BY (tax_id_match OR bank_match) DESC, name_similarity DESC, id
+		LIMIT $6
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, q.EntityID, q.Name, q.TaxIDFragment, q.BankLast4, q.MinSimilarity, q.Limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find vendor match candidates")
+	}
+	defer rows.Close()
+
+	candidates := make([]*VendorMatchCandidate, 0)
+	for rows.Next() {
+		c := &VendorMatchCandidate{}
+		vendor, err := scanVendor(rows, &c.MatchedOn, &c.MatchedName, &c.NameSimilarity, &c.TaxIDMatch, &c.BankMatch)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan vendor match candidate")
+		}
+		c.Vendor = vendor
+		candidates = append(candidates, c)
+	}
+
+	return candidates, nil
+}
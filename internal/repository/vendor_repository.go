@@ -2,83 +2,151 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"math"
+	"strings"
+	"sync/atomic"
 	"time"
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pesio-ai/be-ap-vendors/internal/identity"
 	"github.com/pesio-ai/be-lib-common/database"
 	"github.com/pesio-ai/be-lib-common/errors"
 )
 
 // Vendor represents a vendor/supplier
 type Vendor struct {
-	ID                string     `json:"id"`
-	EntityID          string     `json:"entity_id"`
-	VendorCode        string     `json:"vendor_code"`
-	VendorName        string     `json:"vendor_name"`
-	LegalName         *string    `json:"legal_name,omitempty"`
-	VendorType        string     `json:"vendor_type"`
-	Status            string     `json:"status"`
-	TaxID             *string    `json:"tax_id,omitempty"`
-	IsTaxExempt       bool       `json:"is_tax_exempt"`
-	Is1099Vendor      bool       `json:"is_1099_vendor"`
-	Email             *string    `json:"email,omitempty"`
-	Phone             *string    `json:"phone,omitempty"`
-	Fax               *string    `json:"fax,omitempty"`
-	Website           *string    `json:"website,omitempty"`
-	AddressLine1      *string    `json:"address_line1,omitempty"`
-	AddressLine2      *string    `json:"address_line2,omitempty"`
-	City              *string    `json:"city,omitempty"`
-	StateProvince     *string    `json:"state_province,omitempty"`
-	PostalCode        *string    `json:"postal_code,omitempty"`
-	Country           string     `json:"country"`
-	PaymentTerms      string     `json:"payment_terms"`
-	PaymentMethod     *string    `json:"payment_method,omitempty"`
-	Currency          string     `json:"currency"`
-	CreditLimit       *int64     `json:"credit_limit,omitempty"`
-	CurrentBalance    int64      `json:"current_balance"`
-	BankName          *string    `json:"bank_name,omitempty"`
-	BankAccountNumber *string    `json:"bank_account_number,omitempty"`
-	BankRoutingNumber *string    `json:"bank_routing_number,omitempty"`
-	SwiftCode         *string    `json:"swift_code,omitempty"`
-	IBAN              *string    `json:"iban,omitempty"`
-	Notes             *string    `json:"notes,omitempty"`
-	Tags              []string   `json:"tags,omitempty"`
-	CreatedBy         *string    `json:"created_by,omitempty"`
-	CreatedAt         time.Time  `json:"created_at"`
-	UpdatedBy         *string    `json:"updated_by,omitempty"`
-	UpdatedAt         time.Time  `json:"updated_at"`
+	ID                 string     `json:"id"`
+	EntityID           string     `json:"entity_id"`
+	VendorCode         string     `json:"vendor_code"`
+	VendorName         string     `json:"vendor_name"`
+	LegalName          *string    `json:"legal_name,omitempty"`
+	DoingBusinessAs    *string    `json:"doing_business_as,omitempty"`
+	VendorType         string     `json:"vendor_type"`
+	Status             string     `json:"status"`
+	TaxID              *string    `json:"tax_id,omitempty"`
+	IsTaxExempt        bool       `json:"is_tax_exempt"`
+	Is1099Vendor       bool       `json:"is_1099_vendor"`
+	Email              *string    `json:"email,omitempty"`
+	RemittanceEmail    *string    `json:"remittance_email,omitempty"`
+	Phone              *string    `json:"phone,omitempty"`
+	Fax                *string    `json:"fax,omitempty"`
+	Website            *string    `json:"website,omitempty"`
+	AddressLine1       *string    `json:"address_line1,omitempty"`
+	AddressLine2       *string    `json:"address_line2,omitempty"`
+	City               *string    `json:"city,omitempty"`
+	StateProvince      *string    `json:"state_province,omitempty"`
+	PostalCode         *string    `json:"postal_code,omitempty"`
+	Country            string     `json:"country"`
+	Locale             *string    `json:"locale,omitempty"`
+	PaymentTerms       string     `json:"payment_terms"`
+	PaymentMethod      *string    `json:"payment_method,omitempty"`
+	Currency           string     `json:"currency"`
+	AcceptedCurrencies []string   `json:"accepted_currencies,omitempty"`
+	CreditLimit        *int64     `json:"credit_limit,omitempty"`
+	CurrentBalance     int64      `json:"current_balance"`
+	BankName           *string    `json:"bank_name,omitempty"`
+	BankAccountNumber  *string    `json:"bank_account_number,omitempty"`
+	BankRoutingNumber  *string    `json:"bank_routing_number,omitempty"`
+	SwiftCode          *string    `json:"swift_code,omitempty"`
+	IBAN               *string    `json:"iban,omitempty"`
+	Notes              *string    `json:"notes,omitempty"`
+	Tags               []string   `json:"tags,omitempty"`
+	TemplateID         *string    `json:"template_id,omitempty"`
+	CreatedBy          *string    `json:"created_by,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedBy          *string    `json:"updated_by,omitempty"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+	DeletedAt          *time.Time `json:"deleted_at,omitempty"`
+	ChangeSeq          int64      `json:"change_seq"`
+
+	// CreditLimitCurrency is the currency of CreditLimit, set with it
+	CreditLimitCurrency *string `json:"credit_limit_currency,omitempty"`
+
+	// Source is the creation path that added the vendor, such as "ui" or
+	// "erp_sync", and SourceReference what within it, such as the import job
+	// or sync system. Both are set on create and never change.
+	Source          string  `json:"source"`
+	SourceReference *string `json:"source_reference,omitempty"`
+
+	// Contacts is only populated by operations that create or load contacts with the vendor
+	Contacts []*VendorContact `json:"contacts,omitempty"`
+	// ExternalRefs maps external system name to the vendor's ID there; only populated on request
+	ExternalRefs map[string]string `json:"external_refs,omitempty"`
+	// Warnings are the messages of ValidationWarnings, kept for clients that
+	// predate them
+	Warnings []string `json:"warnings,omitempty"`
+	// ValidationWarnings are non-blocking validation findings returned by
+	// create and update
+	ValidationWarnings []*ValidationWarning `json:"validation_warnings,omitempty"`
+	// Approval is the approval progress of vendors pending approval; only
+	// populated by reads
+	Approval *ApprovalState `json:"approval,omitempty"`
+	// Risk is the stored risk score; only populated by reads and writes that
+	// rescore the vendor
+	Risk *RiskScore `json:"risk,omitempty"`
+	// TINMatch is the TIN matching result of the vendor's current tax ID and
+	// legal name; only populated by reads
+	TINMatch *TINMatch `json:"tin_match,omitempty"`
+	// BankVerification is the verification of the vendor's current bank
+	// account; only populated by reads
+	BankVerification *BankVerification `json:"bank_verification,omitempty"`
+	// DataQuality is the completeness breakdown of the vendor; only populated
+	// on request
+	DataQuality *DataQuality `json:"data_quality,omitempty"`
+	// CreatedByUser and UpdatedByUser are the users of CreatedBy and
+	// UpdatedBy as the identity service knows them; only populated on request
+	CreatedByUser *identity.User `json:"created_by_user,omitempty"`
+	UpdatedByUser *identity.User `json:"updated_by_user,omitempty"`
+
+	// Contact and document counts; only populated by lists on request
+	ContactsCount          *int64 `json:"contacts_count,omitempty"`
+	DocumentsCount         *int64 `json:"documents_count,omitempty"`
+	ExpiringDocumentsCount *int64 `json:"expiring_documents_count,omitempty"`
+}
+
+// ValidationWarning is a validation finding that does not block saving a
+// vendor. Field is empty for findings about the vendor as a whole.
+type ValidationWarning struct {
+	Code    string `json:"code"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
 }
 
 // VendorContact represents a vendor contact person
 type VendorContact struct {
-	ID          string
-	VendorID    string
-	ContactType string
-	FirstName   string
-	LastName    string
-	Title       *string
-	Email       *string
-	Phone       *string
-	Mobile      *string
-	IsPrimary   bool
-	Notes       *string
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID                     string    `json:"id"`
+	VendorID               string    `json:"vendor_id"`
+	ContactType            string    `json:"contact_type"`
+	FirstName              string    `json:"first_name"`
+	LastName               string    `json:"last_name"`
+	Title                  *string   `json:"title,omitempty"`
+	Email                  *string   `json:"email,omitempty"`
+	Phone                  *string   `json:"phone,omitempty"`
+	Mobile                 *string   `json:"mobile,omitempty"`
+	IsPrimary              bool      `json:"is_primary"`
+	ReceivesPurchaseOrders bool      `json:"receives_purchase_orders"`
+	ReceivesRemittance     bool      `json:"receives_remittance"`
+	ReceivesStatements     bool      `json:"receives_statements"`
+	Notes                  *string   `json:"notes,omitempty"`
+	CreatedAt              time.Time `json:"created_at"`
+	UpdatedAt              time.Time `json:"updated_at"`
 }
 
 // VendorDocument represents a vendor document reference
 type VendorDocument struct {
-	ID             string
-	VendorID       string
-	DocumentType   string
-	DocumentName   string
-	DocumentURL    string
-	FileSize       *int64
-	MimeType       *string
-	ExpirationDate *string
-	UploadedBy     *string
-	UploadedAt     string
+	ID             string  `json:"id"`
+	VendorID       string  `json:"vendor_id"`
+	DocumentType   string  `json:"document_type"`
+	DocumentName   string  `json:"document_name"`
+	DocumentURL    string  `json:"document_url"`
+	FileSize       *int64  `json:"file_size,omitempty"`
+	MimeType       *string `json:"mime_type,omitempty"`
+	ExpirationDate *string `json:"expiration_date,omitempty"`
+	UploadedBy     *string `json:"uploaded_by,omitempty"`
+	UploadedAt     string  `json:"uploaded_at"`
 }
 
 // PaymentTerm represents payment terms
@@ -93,14 +161,77 @@ type PaymentTerm struct {
 	CreatedAt       time.Time
 }
 
+// querier is satisfied by both the connection pool and a pgx transaction
+type querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
 // VendorRepository handles vendor data operations
 type VendorRepository struct {
-	db *database.DB
+	q        querier
+	timeouts QueryTimeouts
+	slow     *slowQueryLog
+
+	// replica serves replica-eligible reads; nil without a replica and inside transactions
+	replica        querier
+	replicaDB      *database.DB
+	replicaHealthy *atomic.Bool
+
+	// inTx is set on repositories bound to a transaction, which bypass the
+	// request lookup cache
+	inTx bool
+}
+
+// Option configures optional behaviour of the vendor repository
+type Option func(*VendorRepository)
+
+// WithQueryTimeouts sets the per-statement query budgets
+func WithQueryTimeouts(timeouts QueryTimeouts) Option {
+	return func(r *VendorRepository) {
+		r.timeouts = timeouts
+	}
 }
 
 // NewVendorRepository creates a new vendor repository
-func NewVendorRepository(db *database.DB) *VendorRepository {
-	return &VendorRepository{db: db}
+func NewVendorRepository(db *database.DB, opts ...Option) *VendorRepository {
+	r := &VendorRepository{timeouts: DefaultQueryTimeouts}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.q = timedQuerier{q: db, timeouts: r.timeouts, slow: r.slow}
+	if r.replicaDB != nil {
+		r.replica = timedQuerier{q: r.replicaDB, timeouts: r.timeouts, target: ReadConsistencyReplica, slow: r.slow}
+		r.replicaHealthy = &atomic.Bool{}
+		r.replicaHealthy.Store(true)
+	}
+	return r
+}
+
+// WithTx runs fn with a repository bound to a single transaction. The transaction
+// is committed when fn returns nil and rolled back otherwise. Calling WithTx on a
+// repository that is already inside a transaction creates a savepoint.
+func (r *VendorRepository) WithTx(ctx context.Context, fn func(repo Store) error) error {
+	tx, err := r.q.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to begin transaction")
+	}
+	defer tx.Rollback(ctx)
+
+	txRepo := &VendorRepository{q: timedQuerier{q: tx, timeouts: r.timeouts, slow: r.slow}, timeouts: r.timeouts, slow: r.slow, inTx: true}
+	if err := fn(txRepo); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to commit transaction")
+	}
+	// Lookups cached while the transaction was open predate its writes
+	invalidateLookups(ctx)
+
+	return nil
 }
 
 // Create creates a new vendor
@@ -112,17 +243,20 @@ func (r *VendorRepository) Create(ctx context.Context, vendor *Vendor) error {
 		                     address_line1, address_line2, city, state_province, postal_code, country,
 		                     payment_terms, payment_method, currency, credit_limit,
 		                     bank_name, bank_account_number, bank_routing_number, swift_code, iban,
-		                     notes, tags, created_by)
-		VALUES ($1, $2, $3, $4, $5::vendor_type, $6::vendor_status, $7, $8, $9,
+		                     notes, tags, created_by, doing_business_as, remittance_email, locale,
+		                     accepted_currencies, template_id, bank_account_last4, iban_last4, credit_limit_currency,
+		                     source, source_reference)
+		VALUES ($1, $2, $3, $4, $5, $6::vendor_status, $7, $8, $9,
 		        $10, $11, $12, $13,
 		        $14, $15, $16, $17, $18, $19,
 		        $20, $21::payment_method, $22, $23,
 		        $24, $25, $26, $27, $28,
-		        $29, $30, $31)
-		RETURNING id, created_at, updated_at
+		        $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39,
+		        $40, $41)
+		RETURNING id, created_at, updated_at, change_seq
 	`
 
-	err := r.db.QueryRow(ctx, query,
+	err := r.q.QueryRow(ctx, query,
 		vendor.EntityID,
 		vendor.VendorCode,
 		vendor.VendorName,
@@ -154,7 +288,17 @@ func (r *VendorRepository) Create(ctx context.Context, vendor *Vendor) error {
 		vendor.Notes,
 		vendor.Tags,
 		vendor.CreatedBy,
-	).Scan(&vendor.ID, &vendor.CreatedAt, &vendor.UpdatedAt)
+		vendor.DoingBusinessAs,
+		vendor.RemittanceEmail,
+		vendor.Locale,
+		vendor.AcceptedCurrencies,
+		vendor.TemplateID,
+		BankLast4(vendor.BankAccountNumber),
+		BankLast4(vendor.IBAN),
+		vendor.CreditLimitCurrency,
+		vendor.Source,
+		vendor.SourceReference,
+	).Scan(&vendor.ID, &vendor.CreatedAt, &vendor.UpdatedAt, &vendor.ChangeSeq)
 
 	if err != nil {
 		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create vendor")
@@ -163,143 +307,55 @@ func (r *VendorRepository) Create(ctx context.Context, vendor *Vendor) error {
 	return nil
 }
 
-// GetByID retrieves a vendor by ID
+// GetByID retrieves a vendor by ID, through the request lookup cache
 func (r *VendorRepository) GetByID(ctx context.Context, id, entityID string) (*Vendor, error) {
-	vendor := &Vendor{}
-
 	query := `
-		SELECT id, entity_id, vendor_code, vendor_name, legal_name, vendor_type,
-		       status, tax_id, is_tax_exempt, is_1099_vendor,
-		       email, phone, fax, website,
-		       address_line1, address_line2, city, state_province, postal_code, country,
-		       payment_terms, payment_method, currency, credit_limit, current_balance,
-		       bank_name, bank_account_number, bank_routing_number, swift_code, iban,
-		       notes, tags,
-		       created_by, created_at, updated_by, updated_at
+		SELECT ` + vendorColumns + `
 		FROM vendors
-		WHERE id = $1 AND entity_id = $2
+		WHERE id = $1 AND entity_id = $2 AND deleted_at IS NULL
 	`
 
-	err := r.db.QueryRow(ctx, query, id, entityID).Scan(
-		&vendor.ID,
-		&vendor.EntityID,
-		&vendor.VendorCode,
-		&vendor.VendorName,
-		&vendor.LegalName,
-		&vendor.VendorType,
-		&vendor.Status,
-		&vendor.TaxID,
-		&vendor.IsTaxExempt,
-		&vendor.Is1099Vendor,
-		&vendor.Email,
-		&vendor.Phone,
-		&vendor.Fax,
-		&vendor.Website,
-		&vendor.AddressLine1,
-		&vendor.AddressLine2,
-		&vendor.City,
-		&vendor.StateProvince,
-		&vendor.PostalCode,
-		&vendor.Country,
-		&vendor.PaymentTerms,
-		&vendor.PaymentMethod,
-		&vendor.Currency,
-		&vendor.CreditLimit,
-		&vendor.CurrentBalance,
-		&vendor.BankName,
-		&vendor.BankAccountNumber,
-		&vendor.BankRoutingNumber,
-		&vendor.SwiftCode,
-		&vendor.IBAN,
-		&vendor.Notes,
-		&vendor.Tags,
-		&vendor.CreatedBy,
-		&vendor.CreatedAt,
-		&vendor.UpdatedBy,
-		&vendor.UpdatedAt,
-	)
+	return r.cachedLookup(ctx, false, id, entityID, func() (*Vendor, error) {
+		vendor, err := scanVendor(r.reader(ctx).QueryRow(ctx, query, id, entityID))
 
-	if err == pgx.ErrNoRows {
-		return nil, errors.NotFound("vendor", id)
-	}
-	if err != nil {
-		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get vendor")
-	}
+		if err == pgx.ErrNoRows {
+			return nil, errors.NotFound("vendor", id)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get vendor")
+		}
 
-	return vendor, nil
+		return vendor, nil
+	})
 }
 
-// GetByCode retrieves a vendor by vendor code
+// GetByCode retrieves a vendor by vendor code, through the request lookup cache
 func (r *VendorRepository) GetByCode(ctx context.Context, code, entityID string) (*Vendor, error) {
-	vendor := &Vendor{}
-
 	query := `
-		SELECT id, entity_id, vendor_code, vendor_name, legal_name, vendor_type,
-		       status, tax_id, is_tax_exempt, is_1099_vendor,
-		       email, phone, fax, website,
-		       address_line1, address_line2, city, state_province, postal_code, country,
-		       payment_terms, payment_method, currency, credit_limit, current_balance,
-		       bank_name, bank_account_number, bank_routing_number, swift_code, iban,
-		       notes, tags,
-		       created_by, created_at, updated_by, updated_at
+		SELECT ` + vendorColumns + `
 		FROM vendors
-		WHERE vendor_code = $1 AND entity_id = $2
+		WHERE vendor_code = $1 AND entity_id = $2 AND deleted_at IS NULL
 	`
 
-	err := r.db.QueryRow(ctx, query, code, entityID).Scan(
-		&vendor.ID,
-		&vendor.EntityID,
-		&vendor.VendorCode,
-		&vendor.VendorName,
-		&vendor.LegalName,
-		&vendor.VendorType,
-		&vendor.Status,
-		&vendor.TaxID,
-		&vendor.IsTaxExempt,
-		&vendor.Is1099Vendor,
-		&vendor.Email,
-		&vendor.Phone,
-		&vendor.Fax,
-		&vendor.Website,
-		&vendor.AddressLine1,
-		&vendor.AddressLine2,
-		&vendor.City,
-		&vendor.StateProvince,
-		&vendor.PostalCode,
-		&vendor.Country,
-		&vendor.PaymentTerms,
-		&vendor.PaymentMethod,
-		&vendor.Currency,
-		&vendor.CreditLimit,
-		&vendor.CurrentBalance,
-		&vendor.BankName,
-		&vendor.BankAccountNumber,
-		&vendor.BankRoutingNumber,
-		&vendor.SwiftCode,
-		&vendor.IBAN,
-		&vendor.Notes,
-		&vendor.Tags,
-		&vendor.CreatedBy,
-		&vendor.CreatedAt,
-		&vendor.UpdatedBy,
-		&vendor.UpdatedAt,
-	)
+	return r.cachedLookup(ctx, true, code, entityID, func() (*Vendor, error) {
+		vendor, err := scanVendor(r.reader(ctx).QueryRow(ctx, query, code, entityID))
 
-	if err == pgx.ErrNoRows {
-		return nil, errors.NotFound("vendor", code)
-	}
-	if err != nil {
-		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get vendor by code")
-	}
+		if err == pgx.ErrNoRows {
+			return nil, errors.NotFound("vendor", code)
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get vendor by code")
+		}
 
-	return vendor, nil
+		return vendor, nil
+	})
 }
 
 // Update updates a vendor
 func (r *VendorRepository) Update(ctx context.Context, vendor *Vendor) error {
 	query := `
 		UPDATE vendors
-		SET vendor_code = $3, vendor_name = $4, legal_name = $5, vendor_type = $6::vendor_type,
+		SET vendor_code = $3, vendor_name = $4, legal_name = $5, vendor_type = $6,
 		    status = $7::vendor_status, tax_id = $8, is_tax_exempt = $9, is_1099_vendor = $10,
 		    email = $11, phone = $12, fax = $13, website = $14,
 		    address_line1 = $15, address_line2 = $16, city = $17, state_province = $18,
@@ -307,12 +363,14 @@ func (r *VendorRepository) Update(ctx context.Context, vendor *Vendor) error {
 		    payment_terms = $21, payment_method = $22::payment_method, currency = $23, credit_limit = $24,
 		    bank_name = $25, bank_account_number = $26, bank_routing_number = $27,
 		    swift_code = $28, iban = $29,
-		    notes = $30, tags = $31, updated_by = $32, updated_at = NOW()
-		WHERE id = $1 AND entity_id = $2
-		RETURNING updated_at
+		    notes = $30, tags = $31, updated_by = $32, doing_business_as = $33, remittance_email = $34, locale = $35,
+		    accepted_currencies = $36, bank_account_last4 = $37, iban_last4 = $38, credit_limit_currency = $39,
+		    updated_at = NOW()
+		WHERE id = $1 AND entity_id = $2 AND deleted_at IS NULL
+		RETURNING updated_at, change_seq
 	`
 
-	err := r.db.QueryRow(ctx, query,
+	err := r.q.QueryRow(ctx, query,
 		vendor.ID,
 		vendor.EntityID,
 		vendor.VendorCode,
@@ -345,7 +403,14 @@ func (r *VendorRepository) Update(ctx context.Context, vendor *Vendor) error {
 		vendor.Notes,
 		vendor.Tags,
 		vendor.UpdatedBy,
-	).Scan(&vendor.UpdatedAt)
+		vendor.DoingBusinessAs,
+		vendor.RemittanceEmail,
+		vendor.Locale,
+		vendor.AcceptedCurrencies,
+		BankLast4(vendor.BankAccountNumber),
+		BankLast4(vendor.IBAN),
+		vendor.CreditLimitCurrency,
+	).Scan(&vendor.UpdatedAt, &vendor.ChangeSeq)
 
 	if err == pgx.ErrNoRows {
 		return errors.NotFound("vendor", vendor.ID)
@@ -357,11 +422,136 @@ func (r *VendorRepository) Update(ctx context.Context, vendor *Vendor) error {
 	return nil
 }
 
-// Delete deletes a vendor
+// touchVendor advances the updated_at and change_seq of a vendor whose state
+// kept in another table changed, so that the change feed and the HTTP
+// validators, which only look at the vendor row, see the change
+func (r *VendorRepository) touchVendor(ctx context.Context, vendorID string) error {
+	if _, err := r.q.Exec(ctx, `UPDATE vendors SET updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`, vendorID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update vendor")
+	}
+	return nil
+}
+
+// upsertableColumns are the vendor columns an upsert may overwrite on an existing row
+var upsertableColumns = map[string]bool{
+	"vendor_name": true, "legal_name": true, "doing_business_as": true, "vendor_type": true,
+	"tax_id": true, "is_tax_exempt": true, "is_1099_vendor": true,
+	"email": true, "remittance_email": true, "phone": true, "fax": true, "website": true,
+	"address_line1": true, "address_line2": true, "city": true, "state_province": true,
+	"postal_code": true, "country": true, "locale": true,
+	"payment_terms": true, "payment_method": true, "currency": true, "accepted_currencies": true, "credit_limit": true,
+	"credit_limit_currency": true, "bank_name": true, "bank_account_number": true, "bank_routing_number": true,
+	"swift_code": true, "iban": true,
+	"notes": true, "tags": true,
+}
+
+// bankLast4Columns are the stored last four digits of the upsertable bank
+// details, overwritten with them
+var bankLast4Columns = map[string]string{
+	"bank_account_number": "bank_account_last4",
+	"iban":                "iban_last4",
+}
+
+// IsUpsertableColumn reports whether an upsert may overwrite column on an existing vendor
+func IsUpsertableColumn(column string) bool {
+	return upsertableColumns[column]
+}
+
+// UpsertByCode inserts the vendor, or when a vendor with the same code already exists
+// in the entity, overwrites only the given columns of the existing row. Status is never
+// changed on the update path. It returns the stored vendor and whether it was inserted.
+func (r *VendorRepository) UpsertByCode(ctx context.Context, vendor *Vendor, columns []string) (*Vendor, bool, error) {
+	set := "updated_by = EXCLUDED.created_by, updated_at = NOW()"
+	for _, column := range columns {
+		if !IsUpsertableColumn(column) {
+			return nil, false, errors.InvalidInput(column, "field cannot be set by upsert")
+		}
+		set += fmt.Sprintf(", %s = EXCLUDED.%s", column, column)
+		if last4, ok := bankLast4Columns[column]; ok {
+			set += fmt.Sprintf(", %s = EXCLUDED.%s", last4, last4)
+		}
+	}
+
+	query := `
+		INSERT INTO vendors (entity_id, vendor_code, vendor_name, legal_name, vendor_type,
+		                     status, tax_id, is_tax_exempt, is_1099_vendor,
+		                     email, phone, fax, website,
+		                     address_line1, address_line2, city, state_province, postal_code, country,
+		                     payment_terms, payment_method, currency, credit_limit,
+		                     bank_name, bank_account_number, bank_routing_number, swift_code, iban,
+		                     notes, tags, created_by, doing_business_as, remittance_email, locale,
+		                     accepted_currencies, bank_account_last4, iban_last4, credit_limit_currency,
+		                     source, source_reference)
+		VALUES ($1, $2, $3, $4, $5, $6::vendor_status, $7, $8, $9,
+		        $10, $11, $12, $13,
+		        $14, $15, $16, $17, $18, $19,
+		        $20, $21::payment_method, $22, $23,
+		        $24, $25, $26, $27, $28,
+		        $29, $30, $31, $32, $33, $34, $35, $36, $37, $38,
+		        $39, $40)
+		ON CONFLICT (entity_id, vendor_code) WHERE deleted_at IS NULL DO UPDATE SET ` + set + `
+		RETURNING ` + vendorColumns + `, (xmax = 0) AS created
+	`
+
+	var created bool
+	stored, err := scanVendor(r.q.QueryRow(ctx, query,
+		vendor.EntityID,
+		vendor.VendorCode,
+		vendor.VendorName,
+		vendor.LegalName,
+		vendor.VendorType,
+		vendor.Status,
+		vendor.TaxID,
+		vendor.IsTaxExempt,
+		vendor.Is1099Vendor,
+		vendor.Email,
+		vendor.Phone,
+		vendor.Fax,
+		vendor.Website,
+		vendor.AddressLine1,
+		vendor.AddressLine2,
+		vendor.City,
+		vendor.StateProvince,
+		vendor.PostalCode,
+		vendor.Country,
+		vendor.PaymentTerms,
+		vendor.PaymentMethod,
+		vendor.Currency,
+		vendor.CreditLimit,
+		vendor.BankName,
+		vendor.BankAccountNumber,
+		vendor.BankRoutingNumber,
+		vendor.SwiftCode,
+		vendor.IBAN,
+		vendor.Notes,
+		vendor.Tags,
+		vendor.CreatedBy,
+		vendor.DoingBusinessAs,
+		vendor.RemittanceEmail,
+		vendor.Locale,
+		vendor.AcceptedCurrencies,
+		BankLast4(vendor.BankAccountNumber),
+		BankLast4(vendor.IBAN),
+		vendor.CreditLimitCurrency,
+		vendor.Source,
+		vendor.SourceReference,
+	), &created)
+	if err != nil {
+		return nil, false, errors.Wrap(err, errors.ErrCodeInternal, "failed to upsert vendor")
+	}
+
+	return stored, created, nil
+}
+
+// Delete soft-deletes a vendor; the row remains as a tombstone for the change feed
 func (r *VendorRepository) Delete(ctx context.Context, id, entityID string) error {
-	query := `DELETE FROM vendors WHERE id = $1 AND entity_id = $2`
+	query := `
+		UPDATE vendors
+		SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND entity_id = $2 AND deleted_at IS NULL
+	`
 
-	tag, err := r.db.Exec(ctx, query, id, entityID)
+	tag, err := r.q.Exec(ctx, query, id, entityID)
 	if err != nil {
 		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete vendor")
 	}
@@ -373,61 +563,307 @@ func (r *VendorRepository) Delete(ctx context.Context, id, entityID string) erro
 	return nil
 }
 
-// List retrieves vendors with filtering and pagination
-func (r *VendorRepository) List(ctx context.Context, entityID string, status, vendorType *string, activeOnly bool, limit, offset int) ([]*Vendor, int64, error) {
-	query := `
-		SELECT id, entity_id, vendor_code, vendor_name, legal_name, vendor_type,
+// vendorColumns is the column list matching scanVendor
+const vendorColumns = `id, entity_id, vendor_code, vendor_name, legal_name, vendor_type,
 		       status, tax_id, is_tax_exempt, is_1099_vendor,
 		       email, phone, fax, website,
 		       address_line1, address_line2, city, state_province, postal_code, country,
 		       payment_terms, payment_method, currency, credit_limit, current_balance,
 		       bank_name, bank_account_number, bank_routing_number, swift_code, iban,
 		       notes, tags,
-		       created_by, created_at, updated_by, updated_at
-		FROM vendors
-		WHERE entity_id = $1
-	`
+		       created_by, created_at, updated_by, updated_at,
+		       deleted_at, change_seq, doing_business_as, remittance_email, locale, accepted_currencies,
+		       template_id, credit_limit_currency, source, source_reference`
+
+// prefixedVendorColumns returns vendorColumns qualified with a table alias, for joins
+func prefixedVendorColumns(alias string) string {
+	columns := strings.Split(vendorColumns, ",")
+	for i, column := range columns {
+		columns[i] = alias + "." + strings.TrimSpace(column)
+	}
+	return strings.Join(columns, ", ")
+}
 
-	countQuery := `SELECT COUNT(*) FROM vendors WHERE entity_id = $1`
+// rowScanner is satisfied by pgx.Row and pgx.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
-	args := []interface{}{entityID}
+// scanVendor scans a row selected with vendorColumns, followed by any extra destinations
+func scanVendor(row rowScanner, extra ...interface{}) (*Vendor, error) {
+	vendor := &Vendor{}
+	dest := []interface{}{
+		&vendor.ID,
+		&vendor.EntityID,
+		&vendor.VendorCode,
+		&vendor.VendorName,
+		&vendor.LegalName,
+		&vendor.VendorType,
+		&vendor.Status,
+		&vendor.TaxID,
+		&vendor.IsTaxExempt,
+		&vendor.Is1099Vendor,
+		&vendor.Email,
+		&vendor.Phone,
+		&vendor.Fax,
+		&vendor.Website,
+		&vendor.AddressLine1,
+		&vendor.AddressLine2,
+		&vendor.City,
+		&vendor.StateProvince,
+		&vendor.PostalCode,
+		&vendor.Country,
+		&vendor.PaymentTerms,
+		&vendor.PaymentMethod,
+		&vendor.Currency,
+		&vendor.CreditLimit,
+		&vendor.CurrentBalance,
+		&vendor.BankName,
+		&vendor.BankAccountNumber,
+		&vendor.BankRoutingNumber,
+		&vendor.SwiftCode,
+		&vendor.IBAN,
+		&vendor.Notes,
+		&vendor.Tags,
+		&vendor.CreatedBy,
+		&vendor.CreatedAt,
+		&vendor.UpdatedBy,
+		&vendor.UpdatedAt,
+		&vendor.DeletedAt,
+		&vendor.ChangeSeq,
+		&vendor.DoingBusinessAs,
+		&vendor.RemittanceEmail,
+		&vendor.Locale,
+		&vendor.AcceptedCurrencies,
+		&vendor.TemplateID,
+		&vendor.CreditLimitCurrency,
+		&vendor.Source,
+		&vendor.SourceReference,
+	}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	return vendor, nil
+}
+
+// VendorFilter holds the optional filters applied by List
+type VendorFilter struct {
+	EntityID        string
+	Status          *string
+	VendorType      *string
+	ActiveOnly      bool
+	Is1099Vendor    *bool
+	IsTaxExempt     *bool
+	HasCreditLimit  *bool
+	OverCreditLimit *bool
+	MissingTaxID    *bool
+	// Locale keeps vendors with this locale or, when it is a bare language
+	// such as "fr", any locale of that language
+	Locale        string
+	MissingLocale *bool
+	// Currency keeps vendors with this primary currency or accepting it
+	Currency string
+	// Tag keeps vendors with this tag, in normalized form
+	Tag string
+	// Source keeps vendors created through this creation path
+	Source string
+	// Country keeps vendors with this country code
+	Country string
+	// ExcludeSuspectedTest drops vendors flagged as probable test vendors (see
+	// SuspectedTestRules)
+	ExcludeSuspectedTest bool
+	// MinRiskScore keeps vendors whose stored risk score is at least this
+	MinRiskScore *int
+	// Name keeps vendors whose vendor, legal or doing-business-as name, or one
+	// of whose aliases, contains it, ignoring case
+	Name string
+	// Sort is the list order: SortVendorName (default), SortRiskScoreDesc or SortRiskScoreAsc
+	Sort string
+	// TotalMode is how List computes the total: TotalExact (default),
+	// TotalEstimate or TotalNone
+	TotalMode string
+	// Fields restricts the columns List loads to these vendor fields (see
+	// IsVendorField); the others are left zero. Empty loads every column.
+	Fields []string
+	// IncludeCounts adds the contact and document counts to each vendor
+	IncludeCounts bool
+	// ResolveUsers adds the users of each vendor's created_by and updated_by
+	ResolveUsers bool
+}
+
+// How List computes the total of matching vendors
+const (
+	TotalExact = "exact"
+	// TotalEstimate uses the planner's row estimate, which is fast but rough
+	TotalEstimate = "estimate"
+	// TotalNone skips the count and reports -1
+	TotalNone = "none"
+)
+
+// Vendor list orders; vendors without a risk score sort last by risk
+const (
+	SortVendorName    = "vendor_name"
+	SortRiskScoreDesc = "-risk_score"
+	SortRiskScoreAsc  = "risk_score"
+)
+
+// vendorRiskScore selects the stored risk score of the vendor of the row
+const vendorRiskScore = `(SELECT rs.score FROM vendor_risk_scores rs WHERE rs.vendor_id = vendors.id)`
+
+// likeEscaper escapes the LIKE wildcards of a search term
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// where builds the WHERE clause shared by the list and count queries
+func (f VendorFilter) where() (string, []interface{}) {
+	clause := "WHERE entity_id = $1 AND deleted_at IS NULL"
+	args := []interface{}{f.EntityID}
 	argCount := 2
 
-	if status != nil {
-		query += fmt.Sprintf(" AND status = $%d::vendor_status", argCount)
-		countQuery += fmt.Sprintf(" AND status = $%d::vendor_status", argCount)
-		args = append(args, *status)
+	if f.Status != nil {
+		clause += fmt.Sprintf(" AND status = $%d::vendor_status", argCount)
+		args = append(args, *f.Status)
 		argCount++
 	}
 
-	if vendorType != nil {
-		query += fmt.Sprintf(" AND vendor_type = $%d::vendor_type", argCount)
-		countQuery += fmt.Sprintf(" AND vendor_type = $%d::vendor_type", argCount)
-		args = append(args, *vendorType)
+	if f.VendorType != nil {
+		clause += fmt.Sprintf(" AND vendor_type = $%d", argCount)
+		args = append(args, *f.VendorType)
 		argCount++
 	}
 
-	if activeOnly {
-		query += fmt.Sprintf(" AND status = $%d::vendor_status", argCount)
-		countQuery += fmt.Sprintf(" AND status = $%d::vendor_status", argCount)
+	if f.ActiveOnly {
+		clause += fmt.Sprintf(" AND status = $%d::vendor_status", argCount)
 		args = append(args, "active")
 		argCount++
 	}
 
-	query += " ORDER BY vendor_name"
-	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argCount, argCount+1)
+	if f.Is1099Vendor != nil {
+		clause += fmt.Sprintf(" AND is_1099_vendor = $%d", argCount)
+		args = append(args, *f.Is1099Vendor)
+		argCount++
+	}
+
+	if f.IsTaxExempt != nil {
+		clause += fmt.Sprintf(" AND is_tax_exempt = $%d", argCount)
+		args = append(args, *f.IsTaxExempt)
+		argCount++
+	}
+
+	if f.HasCreditLimit != nil {
+		if *f.HasCreditLimit {
+			clause += " AND credit_limit IS NOT NULL"
+		} else {
+			clause += " AND credit_limit IS NULL"
+		}
+	}
+
+	// Matches ValidateVendor: a balance equal to the limit is already over it.
+	// Limits in another currency than the vendor are never compared.
+	if f.OverCreditLimit != nil {
+		over := "credit_limit IS NOT NULL AND (credit_limit_currency IS NULL OR credit_limit_currency = currency)" +
+			" AND current_balance >= credit_limit"
+		if *f.OverCreditLimit {
+			clause += " AND " + over
+		} else {
+			clause += " AND NOT (" + over + ")"
+		}
+	}
+
+	if f.MissingTaxID != nil {
+		if *f.MissingTaxID {
+			clause += " AND (tax_id IS NULL OR btrim(tax_id) = '')"
+		} else {
+			clause += " AND tax_id IS NOT NULL AND btrim(tax_id) <> ''"
+		}
+	}
+
+	if f.Locale != "" {
+		clause += fmt.Sprintf(" AND (locale = $%[1]d OR locale LIKE $%[1]d || '-%%')", argCount)
+		args = append(args, f.Locale)
+		argCount++
+	}
+
+	if f.MissingLocale != nil {
+		if *f.MissingLocale {
+			clause += " AND locale IS NULL"
+		} else {
+			clause += " AND locale IS NOT NULL"
+		}
+	}
+
+	if f.Currency != "" {
+		clause += fmt.Sprintf(" AND (currency = $%[1]d OR $%[1]d = ANY(accepted_currencies))", argCount)
+		args = append(args, f.Currency)
+		argCount++
+	}
+
+	if f.Tag != "" {
+		clause += fmt.Sprintf(" AND tags @> ARRAY[$%d]::text[]", argCount)
+		args = append(args, f.Tag)
+		argCount++
+	}
+
+	if f.Source != "" {
+		clause += fmt.Sprintf(" AND source = $%d", argCount)
+		args = append(args, f.Source)
+		argCount++
+	}
+
+	if f.Country != "" {
+		clause += fmt.Sprintf(" AND country = $%d", argCount)
+		args = append(args, f.Country)
+		argCount++
+	}
+
+	if f.ExcludeSuspectedTest {
+		clause += " AND NOT " + suspectedTestCondition()
+	}
+
+	if f.MinRiskScore != nil {
+		clause += fmt.Sprintf(" AND %s >= $%d", vendorRiskScore, argCount)
+		args = append(args, *f.MinRiskScore)
+		argCount++
+	}
+
+	if f.Name != "" {
+		clause += fmt.Sprintf(` AND (vendor_name ILIKE $%[1]d OR legal_name ILIKE $%[1]d OR doing_business_as ILIKE $%[1]d
+			OR EXISTS (SELECT 1 FROM vendor_aliases a WHERE a.vendor_id = vendors.id AND a.alias ILIKE $%[1]d))`, argCount)
+		args = append(args, "%"+likeEscaper.Replace(f.Name)+"%")
+		argCount++
+	}
+
+	return clause, args
+}
+
+// List retrieves vendors with filtering and pagination
+func (r *VendorRepository) List(ctx context.Context, filter VendorFilter, limit, offset int) ([]*Vendor, int64, error) {
+	where, args := filter.where()
+
+	columns := vendorColumns
+	if len(filter.Fields) > 0 {
+		columns = selectedVendorColumns(filter.Fields)
+	}
+	query := `
+		SELECT ` + columns + `
+		FROM vendors
+	` + where
+
+	query += filter.orderBy()
+	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
 
 	queryArgs := append(args, limit, offset)
+	q := r.reader(ctx)
 
 	// Get total count
-	var total int64
-	err := r.db.QueryRow(ctx, countQuery, args...).Scan(&total)
+	total, err := countListed(ctx, q, filter.TotalMode, where, args)
 	if err != nil {
-		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count vendors")
+		return nil, 0, err
 	}
 
 	// Get vendors
-	rows, err := r.db.Query(ctx, query, queryArgs...)
+	rows, err := q.Query(ctx, query, queryArgs...)
 	if err != nil {
 		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to list vendors")
 	}
@@ -435,45 +871,12 @@ func (r *VendorRepository) List(ctx context.Context, entityID string, status, ve
 
 	vendors := make([]*Vendor, 0)
 	for rows.Next() {
-		vendor := &Vendor{}
-		err := rows.Scan(
-			&vendor.ID,
-			&vendor.EntityID,
-			&vendor.VendorCode,
-			&vendor.VendorName,
-			&vendor.LegalName,
-			&vendor.VendorType,
-			&vendor.Status,
-			&vendor.TaxID,
-			&vendor.IsTaxExempt,
-			&vendor.Is1099Vendor,
-			&vendor.Email,
-			&vendor.Phone,
-			&vendor.Fax,
-			&vendor.Website,
-			&vendor.AddressLine1,
-			&vendor.AddressLine2,
-			&vendor.City,
-			&vendor.StateProvince,
-			&vendor.PostalCode,
-			&vendor.Country,
-			&vendor.PaymentTerms,
-			&vendor.PaymentMethod,
-			&vendor.Currency,
-			&vendor.CreditLimit,
-			&vendor.CurrentBalance,
-			&vendor.BankName,
-			&vendor.BankAccountNumber,
-			&vendor.BankRoutingNumber,
-			&vendor.SwiftCode,
-			&vendor.IBAN,
-			&vendor.Notes,
-			&vendor.Tags,
-			&vendor.CreatedBy,
-			&vendor.CreatedAt,
-			&vendor.UpdatedBy,
-			&vendor.UpdatedAt,
-		)
+		var vendor *Vendor
+		if len(filter.Fields) > 0 {
+			vendor, err = scanSelectedVendor(rows, filter.Fields)
+		} else {
+			vendor, err = scanVendor(rows)
+		}
 		if err != nil {
 			return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan vendor")
 		}
@@ -484,18 +887,168 @@ func (r *VendorRepository) List(ctx context.Context, entityID string, status, ve
 	return vendors, total, nil
 }
 
+// orderBy returns the ORDER BY clause of the list order of the filter
+func (f VendorFilter) orderBy() string {
+	switch f.Sort {
+	case SortRiskScoreDesc:
+		return " ORDER BY " + vendorRiskScore + " DESC NULLS LAST, vendor_name"
+	case SortRiskScoreAsc:
+		return " ORDER BY " + vendorRiskScore + " ASC NULLS LAST, vendor_name"
+	default:
+		return " ORDER BY vendor_name"
+	}
+}
+
+// countListed returns the total of the vendors matching the WHERE clause of
+// a list as mode asks: counted, estimated, or -1 for none
+func countListed(ctx context.Context, q querier, mode, where string, args []interface{}) (int64, error) {
+	countQuery := `SELECT COUNT(*) FROM vendors ` + where
+
+	var total int64
+	switch mode {
+	case TotalNone:
+		total = -1
+	case TotalEstimate:
+		var err error
+		if total, err = estimateRows(ctx, q, countQuery, args); err != nil {
+			return 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to estimate vendors")
+		}
+	default:
+		if err := q.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+			return 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count vendors")
+		}
+	}
+	return total, nil
+}
+
+// estimateRows returns the planner's estimate of the rows counted by
+// countQuery, read from its plan without running it. The estimate scales the
+// table's statistics by the selectivity of the WHERE clause.
+func estimateRows(ctx context.Context, q querier, countQuery string, args []interface{}) (int64, error) {
+	var raw []byte
+	if err := q.QueryRow(ctx, "EXPLAIN (FORMAT JSON) "+countQuery, args...).Scan(&raw); err != nil {
+		return 0, err
+	}
+
+	// The plan of a count is an aggregate over the scan of the matching rows
+	var plans []struct {
+		Plan struct {
+			Rows  float64 `json:"Plan Rows"`
+			Plans []struct {
+				Rows float64 `json:"Plan Rows"`
+			} `json:"Plans"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(raw, &plans); err != nil {
+		return 0, err
+	}
+	if len(plans) == 0 {
+		return 0, fmt.Errorf("empty plan")
+	}
+	rows := plans[0].Plan.Rows
+	if len(plans[0].Plan.Plans) > 0 {
+		rows = plans[0].Plan.Plans[0].Rows
+	}
+	return int64(math.Round(rows)), nil
+}
+
+// ListChangedSince retrieves vendors of an entity whose change_seq is greater than
+// afterSeq, ordered by change_seq. Soft-deleted vendors and vendors transferred to
+// another entity are included as tombstones (DeletedAt set).
+func (r *VendorRepository) ListChangedSince(ctx context.Context, entityID string, afterSeq int64, limit int) ([]*Vendor, error) {
+	query := `
+		SELECT ` + vendorColumns + `
+		FROM vendors
+		WHERE entity_id = $1 AND change_seq > $2
+		ORDER BY change_seq
+		LIMIT $3
+	`
+
+	rows, err := r.q.Query(ctx, query, entityID, afterSeq, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list vendor changes")
+	}
+	defer rows.Close()
+
+	vendors := make([]*Vendor, 0)
+	for rows.Next() {
+		vendor, err := scanVendor(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan vendor")
+		}
+
+		vendors = append(vendors, vendor)
+	}
+
+	tombstones, err := r.listTombstonesSince(ctx, entityID, afterSeq, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(tombstones) == 0 {
+		return vendors, nil
+	}
+
+	// Merge both ordered lists and keep the first limit changes
+	merged := make([]*Vendor, 0, len(vendors)+len(tombstones))
+	i, j := 0, 0
+	for len(merged) < limit && (i < len(vendors) || j < len(tombstones)) {
+		if j >= len(tombstones) || (i < len(vendors) && vendors[i].ChangeSeq < tombstones[j].ChangeSeq) {
+			merged = append(merged, vendors[i])
+			i++
+		} else {
+			merged = append(merged, tombstones[j])
+			j++
+		}
+	}
+
+	return merged, nil
+}
+
+// listTombstonesSince retrieves transfer tombstones of an entity as vendors with
+// only their identifiers, DeletedAt and ChangeSeq set
+func (r *VendorRepository) listTombstonesSince(ctx context.Context, entityID string, afterSeq int64, limit int) ([]*Vendor, error) {
+	query := `
+		SELECT vendor_id, entity_id, vendor_code, change_seq, created_at
+		FROM vendor_change_tombstones
+		WHERE entity_id = $1 AND change_seq > $2
+		ORDER BY change_seq
+		LIMIT $3
+	`
+
+	rows, err := r.q.Query(ctx, query, entityID, afterSeq, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list vendor tombstones")
+	}
+	defer rows.Close()
+
+	tombstones := make([]*Vendor, 0)
+	for rows.Next() {
+		vendor := &Vendor{}
+		var removedAt time.Time
+		if err := rows.Scan(&vendor.ID, &vendor.EntityID, &vendor.VendorCode, &vendor.ChangeSeq, &removedAt); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan vendor tombstone")
+		}
+		vendor.DeletedAt = &removedAt
+
+		tombstones = append(tombstones, vendor)
+	}
+
+	return tombstones, nil
+}
+
 // GetContacts retrieves all contacts for a vendor
 func (r *VendorRepository) GetContacts(ctx context.Context, vendorID string) ([]*VendorContact, error) {
 	query := `
 		SELECT id, vendor_id, contact_type, first_name, last_name, title,
-		       email, phone, mobile, is_primary, notes,
+		       email, phone, mobile, is_primary, receives_purchase_orders, receives_remittance,
+		       receives_statements, notes,
 		       created_at, updated_at
 		FROM vendor_contacts
 		WHERE vendor_id = $1
 		ORDER BY is_primary DESC, first_name, last_name
 	`
 
-	rows, err := r.db.Query(ctx, query, vendorID)
+	rows, err := r.reader(ctx).Query(ctx, query, vendorID)
 	if err != nil {
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get vendor contacts")
 	}
@@ -515,6 +1068,9 @@ func (r *VendorRepository) GetContacts(ctx context.Context, vendorID string) ([]
 			&contact.Phone,
 			&contact.Mobile,
 			&contact.IsPrimary,
+			&contact.ReceivesPurchaseOrders,
+			&contact.ReceivesRemittance,
+			&contact.ReceivesStatements,
 			&contact.Notes,
 			&contact.CreatedAt,
 			&contact.UpdatedAt,
@@ -529,16 +1085,57 @@ func (r *VendorRepository) GetContacts(ctx context.Context, vendorID string) ([]
 	return contacts, nil
 }
 
+// GetContact retrieves a single contact of a vendor
+func (r *VendorRepository) GetContact(ctx context.Context, vendorID, contactID string) (*VendorContact, error) {
+	query := `
+		SELECT id, vendor_id, contact_type, first_name, last_name, title,
+		       email, phone, mobile, is_primary, receives_purchase_orders, receives_remittance,
+		       receives_statements, notes,
+		       created_at, updated_at
+		FROM vendor_contacts
+		WHERE id = $1 AND vendor_id = $2
+	`
+
+	contact := &VendorContact{}
+	err := r.q.QueryRow(ctx, query, contactID, vendorID).Scan(
+		&contact.ID,
+		&contact.VendorID,
+		&contact.ContactType,
+		&contact.FirstName,
+		&contact.LastName,
+		&contact.Title,
+		&contact.Email,
+		&contact.Phone,
+		&contact.Mobile,
+		&contact.IsPrimary,
+		&contact.ReceivesPurchaseOrders,
+		&contact.ReceivesRemittance,
+		&contact.ReceivesStatements,
+		&contact.Notes,
+		&contact.CreatedAt,
+		&contact.UpdatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, errors.NotFound("contact", contactID)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get vendor contact")
+	}
+
+	return contact, nil
+}
+
 // AddContact adds a contact to a vendor
 func (r *VendorRepository) AddContact(ctx context.Context, contact *VendorContact) error {
 	query := `
 		INSERT INTO vendor_contacts (vendor_id, contact_type, first_name, last_name, title,
-		                             email, phone, mobile, is_primary, notes)
-		VALUES ($1, $2::contact_type, $3, $4, $5, $6, $7, $8, $9, $10)
+		                             email, phone, mobile, is_primary, notes, receives_statements,
+		                             receives_purchase_orders, receives_remittance)
+		VALUES ($1, $2::contact_type, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING id, created_at, updated_at
 	`
 
-	err := r.db.QueryRow(ctx, query,
+	err := r.q.QueryRow(ctx, query,
 		contact.VendorID,
 		contact.ContactType,
 		contact.FirstName,
@@ -549,6 +1146,9 @@ func (r *VendorRepository) AddContact(ctx context.Context, contact *VendorContac
 		contact.Mobile,
 		contact.IsPrimary,
 		contact.Notes,
+		contact.ReceivesStatements,
+		contact.ReceivesPurchaseOrders,
+		contact.ReceivesRemittance,
 	).Scan(&contact.ID, &contact.CreatedAt, &contact.UpdatedAt)
 
 	if err != nil {
@@ -560,40 +1160,7 @@ func (r *VendorRepository) AddContact(ctx context.Context, contact *VendorContac
 
 // GetPaymentTerms retrieves all active payment terms
 func (r *VendorRepository) GetPaymentTerms(ctx context.Context) ([]*PaymentTerm, error) {
-	query := `
-		SELECT id, code, description, net_days, discount_percent, discount_days, is_active, created_at
-		FROM payment_terms
-		WHERE is_active = TRUE
-		ORDER BY net_days
-	`
-
-	rows, err := r.db.Query(ctx, query)
-	if err != nil {
-		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get payment terms")
-	}
-	defer rows.Close()
-
-	terms := make([]*PaymentTerm, 0)
-	for rows.Next() {
-		term := &PaymentTerm{}
-		err := rows.Scan(
-			&term.ID,
-			&term.Code,
-			&term.Description,
-			&term.NetDays,
-			&term.DiscountPercent,
-			&term.DiscountDays,
-			&term.IsActive,
-			&term.CreatedAt,
-		)
-		if err != nil {
-			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan payment term")
-		}
-
-		terms = append(terms, term)
-	}
-
-	return terms, nil
+	return r.ListPaymentTerms(ctx, true)
 }
 
 // ValidateVendor validates if a vendor can be used for invoice creation
@@ -607,8 +1174,9 @@ func (r *VendorRepository) ValidateVendor(ctx context.Context, vendorID, entityI
 		return false, fmt.Sprintf("vendor status is '%s', must be active", vendor.Status), nil
 	}
 
-	// Check credit limit if set
-	if vendor.CreditLimit != nil && vendor.CurrentBalance >= *vendor.CreditLimit {
+	// Check credit limit if set; limits in another currency are left to the
+	// caller, which needs exchange rates to compare them
+	if OverCreditLimit(vendor) {
 		return false, fmt.Sprintf("vendor has exceeded credit limit: balance=%d, limit=%d",
 			vendor.CurrentBalance, *vendor.CreditLimit), nil
 	}
@@ -616,18 +1184,35 @@ func (r *VendorRepository) ValidateVendor(ctx context.Context, vendorID, entityI
 	return true, "", nil
 }
 
-// UpdateBalance updates the vendor's current balance
+// CreditLimitComparable reports whether the credit limit of a vendor is in the
+// vendor currency, so that it compares with the balance as is
+func CreditLimitComparable(v *Vendor) bool {
+	return v.CreditLimitCurrency == nil || *v.CreditLimitCurrency == v.Currency
+}
+
+// OverCreditLimit reports whether the balance of a vendor reached its credit
+// limit in the vendor currency; a balance equal to the limit is already over it
+func OverCreditLimit(v *Vendor) bool {
+	return v.CreditLimit != nil && CreditLimitComparable(v) && v.CurrentBalance >= *v.CreditLimit
+}
+
+// UpdateBalance updates the vendor's current balance and records the adjustment in the balance ledger
 func (r *VendorRepository) UpdateBalance(ctx context.Context, vendorID, entityID string, amount int64) error {
 	query := `
-		UPDATE vendors
-		SET current_balance = current_balance + $3,
-		    updated_at = NOW()
-		WHERE id = $1 AND entity_id = $2
-		RETURNING id
+		WITH updated AS (
+			UPDATE vendors
+			SET current_balance = current_balance + $3,
+			    updated_at = NOW()
+			WHERE id = $1 AND entity_id = $2 AND deleted_at IS NULL
+			RETURNING id, entity_id, current_balance
+		)
+		INSERT INTO vendor_balance_transactions (vendor_id, entity_id, amount, balance_after)
+		SELECT id, entity_id, $3, current_balance FROM updated
+		RETURNING vendor_id
 	`
 
 	var returnedID string
-	err := r.db.QueryRow(ctx, query, vendorID, entityID, amount).Scan(&returnedID)
+	err := r.q.QueryRow(ctx, query, vendorID, entityID, amount).Scan(&returnedID)
 
 	if err == pgx.ErrNoRows {
 		return errors.NotFound("vendor", vendorID)
@@ -638,3 +1223,20 @@ func (r *VendorRepository) UpdateBalance(ctx context.Context, vendorID, entityID
 
 	return nil
 }
+
+// HasBalanceActivitySince reports whether the balance ledger of a vendor has
+// entries with a non-zero amount since a time
+func (r *VendorRepository) HasBalanceActivitySince(ctx context.Context, vendorID string, since time.Time) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM vendor_balance_transactions
+			WHERE vendor_id = $1 AND amount <> 0 AND created_at >= $2
+		)
+	`
+
+	var exists bool
+	if err := r.reader(ctx).QueryRow(ctx, query, vendorID, since).Scan(&exists); err != nil {
+		return false, errors.Wrap(err, errors.ErrCodeInternal, "failed to check balance activity")
+	}
+	return exists, nil
+}
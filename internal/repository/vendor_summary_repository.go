@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// PreferredVendorTag is the tag marking the vendors pickers offer first
+const PreferredVendorTag = "preferred"
+
+// VendorSummary is the lightweight shape of a vendor listed by pickers and
+// typeaheads
+type VendorSummary struct {
+	ID          string `json:"id"`
+	VendorCode  string `json:"vendor_code"`
+	VendorName  string `json:"vendor_name"`
+	Status      string `json:"status"`
+	Currency    string `json:"currency"`
+	IsPreferred bool   `json:"is_preferred"`
+	// PrimaryContactEmail is the email of the earliest primary contact with one
+	PrimaryContactEmail *string `json:"primary_contact_email,omitempty"`
+}
+
+// ListSummaries retrieves a page of vendor summaries matching the filter, in
+// the order of List, with the total computed as filter.TotalMode asks. Fields
+// is ignored.
+func (r *VendorRepository) ListSummaries(ctx context.Context, filter VendorFilter, limit, offset int) ([]*VendorSummary, int64, error) {
+	where, args := filter.where()
+
+	query := `
+		SELECT id, vendor_code, vendor_name, status, currency,
+		       '` + PreferredVendorTag + `' = ANY(tags),
+		       (SELECT c.email FROM vendor_contacts c
+		        WHERE c.vendor_id = vendors.id AND c.is_primary AND c.email IS NOT NULL
+		        ORDER BY c.created_at, c.id
+		        LIMIT 1)
+		FROM vendors
+	` + where + filter.orderBy()
+	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2)
+
+	q := r.reader(ctx)
+	total, err := countListed(ctx, q, filter.TotalMode, where, args)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := q.Query(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to list vendor summaries")
+	}
+	defer rows.Close()
+
+	summaries := make([]*VendorSummary, 0)
+	for rows.Next() {
+		sum := &VendorSummary{}
+		if err := rows.Scan(&sum.ID, &sum.VendorCode, &sum.VendorName, &sum.Status, &sum.Currency,
+			&sum.IsPreferred, &sum.PrimaryContactEmail); err != nil {
+			return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan vendor summary")
+		}
+		summaries = append(summaries, sum)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to list vendor summaries")
+	}
+
+	return summaries, total, nil
+}
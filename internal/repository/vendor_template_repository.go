@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// VendorTemplateDefaults are the create fields a vendor template sets. Fields
+// left nil or empty are not defaulted.
+type VendorTemplateDefaults struct {
+	VendorType         *string  `json:"vendor_type,omitempty"`
+	Country            *string  `json:"country,omitempty"`
+	Locale             *string  `json:"locale,omitempty"`
+	PaymentTerms       *string  `json:"payment_terms,omitempty"`
+	PaymentMethod      *string  `json:"payment_method,omitempty"`
+	Currency           *string  `json:"currency,omitempty"`
+	AcceptedCurrencies []string `json:"accepted_currencies,omitempty"`
+	CreditLimit        *int64   `json:"credit_limit,omitempty"`
+	Notes              *string  `json:"notes,omitempty"`
+	Tags               []string `json:"tags,omitempty"`
+}
+
+// VendorTemplate is a named set of create defaults of an entity, e.g. the
+// currency, payment terms and tags shared by all its UK utility vendors
+type VendorTemplate struct {
+	ID        string                 `json:"id"`
+	EntityID  string                 `json:"entity_id"`
+	Name      string                 `json:"name"`
+	Defaults  VendorTemplateDefaults `json:"defaults"`
+	CreatedBy *string                `json:"created_by"`
+	UpdatedBy *string                `json:"updated_by"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+const vendorTemplateColumns = `id, entity_id, name, defaults, created_by, updated_by, created_at, updated_at`
+
+func scanVendorTemplate(row pgx.Row) (*VendorTemplate, error) {
+	t := &VendorTemplate{}
+	var defaults []byte
+	err := row.Scan(
+		&t.ID,
+		&t.EntityID,
+		&t.Name,
+		&defaults,
+		&t.CreatedBy,
+		&t.UpdatedBy,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(defaults, &t.Defaults); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// ListVendorTemplates retrieves the vendor templates of an entity ordered by name
+func (r *VendorRepository) ListVendorTemplates(ctx context.Context, entityID string) ([]*VendorTemplate, error) {
+	query := `
+		SELECT ` + vendorTemplateColumns + `
+		FROM vendor_templates
+		WHERE entity_id = $1
+		ORDER BY name
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, entityID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list vendor templates")
+	}
+	defer rows.Close()
+
+	templates := make([]*VendorTemplate, 0)
+	for rows.Next() {
+		t, err := scanVendorTemplate(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan vendor template")
+		}
+		templates = append(templates, t)
+	}
+
+	return templates, nil
+}
+
+// GetVendorTemplate retrieves a vendor template of an entity
+func (r *VendorRepository) GetVendorTemplate(ctx context.Context, id, entityID string) (*VendorTemplate, error) {
+	query := `SELECT ` + vendorTemplateColumns + ` FROM vendor_templates WHERE id = $1 AND entity_id = $2`
+
+	t, err := scanVendorTemplate(r.reader(ctx).QueryRow(ctx, query, id, entityID))
+	if stderrors.Is(err, pgx.ErrNoRows) {
+		return nil, notFound("vendor_template", id)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get vendor template")
+	}
+
+	return t, nil
+}
+
+// CreateVendorTemplate stores a new vendor template
+func (r *VendorRepository) CreateVendorTemplate(ctx context.Context, t *VendorTemplate) error {
+	defaults, err := json.Marshal(t.Defaults)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to encode vendor template")
+	}
+
+	query := `
+		INSERT INTO vendor_templates (entity_id, name, defaults, created_by, updated_by)
+		VALUES ($1, $2, $3, $4, $4)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = r.q.QueryRow(ctx, query, t.EntityID, t.Name, defaults, t.CreatedBy).
+		Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+	var pgErr *pgconn.PgError
+	if stderrors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return errors.AlreadyExists("vendor_template", t.Name)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create vendor template")
+	}
+
+	t.UpdatedBy = t.CreatedBy
+	return nil
+}
+
+// UpdateVendorTemplate replaces the name and defaults of a vendor template
+func (r *VendorRepository) UpdateVendorTemplate(ctx context.Context, t *VendorTemplate) error {
+	defaults, err := json.Marshal(t.Defaults)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to encode vendor template")
+	}
+
+	query := `
+		UPDATE vendor_templates
+		SET name = $3, defaults = $4, updated_by = $5
+		WHERE id = $1 AND entity_id = $2
+		RETURNING created_by, created_at, updated_at
+	`
+
+	err = r.q.QueryRow(ctx, query, t.ID, t.EntityID, t.Name, defaults, t.UpdatedBy).
+		Scan(&t.CreatedBy, &t.CreatedAt, &t.UpdatedAt)
+	var pgErr *pgconn.PgError
+	if stderrors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return errors.AlreadyExists("vendor_template", t.Name)
+	}
+	if stderrors.Is(err, pgx.ErrNoRows) {
+		return notFound("vendor_template", t.ID)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update vendor template")
+	}
+
+	return nil
+}
+
+// DeleteVendorTemplate removes a vendor template of an entity. Vendors created
+// from it keep its ID.
+func (r *VendorRepository) DeleteVendorTemplate(ctx context.Context, id, entityID string) error {
+	tag, err := r.q.Exec(ctx, `DELETE FROM vendor_templates WHERE id = $1 AND entity_id = $2`, id, entityID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete vendor template")
+	}
+	if tag.RowsAffected() == 0 {
+		return notFound("vendor_template", id)
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// VendorType is an allowed value of Vendor.VendorType in an entity
+type VendorType struct {
+	EntityID     string    `json:"entity_id"`
+	Code         string    `json:"code"`
+	Label        string    `json:"label"`
+	IsDeprecated bool      `json:"is_deprecated"`
+	UpdatedBy    *string   `json:"updated_by,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ListVendorTypes retrieves the vendor type registry of an entity ordered by
+// label. Entities that never changed their registry get the defaults.
+func (r *VendorRepository) ListVendorTypes(ctx context.Context, entityID string) ([]*VendorType, error) {
+	query := `
+		SELECT code, label, is_deprecated, updated_by, created_at, updated_at
+		FROM vendor_types
+		WHERE entity_id = $1
+		   OR (entity_id IS NULL AND NOT EXISTS (SELECT 1 FROM vendor_types WHERE entity_id = $1))
+		ORDER BY label, code
+	`
+
+	rows, err := r.reader(ctx).Query(ctx, query, entityID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list vendor types")
+	}
+	defer rows.Close()
+
+	types := make([]*VendorType, 0)
+	for rows.Next() {
+		vt := &VendorType{EntityID: entityID}
+		if err := rows.Scan(&vt.Code, &vt.Label, &vt.IsDeprecated, &vt.UpdatedBy, &vt.CreatedAt, &vt.UpdatedAt); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan vendor type")
+		}
+		types = append(types, vt)
+	}
+
+	return types, nil
+}
+
+// materializeVendorTypes copies the default vendor types to an entity that has
+// no registry of its own yet, so that it can be changed
+func (r *VendorRepository) materializeVendorTypes(ctx context.Context, entityID string) error {
+	query := `
+		INSERT INTO vendor_types (entity_id, code, label, is_deprecated)
+		SELECT $1, code, label, is_deprecated
+		FROM vendor_types
+		WHERE entity_id IS NULL
+		  AND NOT EXISTS (SELECT 1 FROM vendor_types WHERE entity_id = $1)
+		ON CONFLICT (entity_id, code) DO NOTHING
+	`
+
+	if _, err := r.q.Exec(ctx, query, entityID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to copy default vendor types")
+	}
+	return nil
+}
+
+// CreateVendorType adds a vendor type to the registry of an entity
+func (r *VendorRepository) CreateVendorType(ctx context.Context, vt *VendorType) error {
+	if err := r.materializeVendorTypes(ctx, vt.EntityID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO vendor_types (entity_id, code, label, is_deprecated, updated_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, updated_at
+	`
+
+	err := r.q.QueryRow(ctx, query, vt.EntityID, vt.Code, vt.Label, vt.IsDeprecated, vt.UpdatedBy).
+		Scan(&vt.CreatedAt, &vt.UpdatedAt)
+	var pgErr *pgconn.PgError
+	if stderrors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return errors.AlreadyExists("vendor_type", vt.Code)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create vendor type")
+	}
+
+	return nil
+}
+
+// UpdateVendorType changes the label and deprecation of a vendor type of an entity
+func (r *VendorRepository) UpdateVendorType(ctx context.Context, vt *VendorType) error {
+	if err := r.materializeVendorTypes(ctx, vt.EntityID); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE vendor_types
+		SET label = $3, is_deprecated = $4, updated_by = $5
+		WHERE entity_id = $1 AND code = $2
+		RETURNING created_at, updated_at
+	`
+
+	err := r.q.QueryRow(ctx, query, vt.EntityID, vt.Code, vt.Label, vt.IsDeprecated, vt.UpdatedBy).
+		Scan(&vt.CreatedAt, &vt.UpdatedAt)
+	if stderrors.Is(err, pgx.ErrNoRows) {
+		return notFound("vendor_type", vt.Code)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update vendor type")
+	}
+
+	return nil
+}
+
+// DeleteVendorType removes a vendor type from the registry of an entity
+func (r *VendorRepository) DeleteVendorType(ctx context.Context, entityID, code string) error {
+	if err := r.materializeVendorTypes(ctx, entityID); err != nil {
+		return err
+	}
+
+	tag, err := r.q.Exec(ctx, `DELETE FROM vendor_types WHERE entity_id = $1 AND code = $2`, entityID, code)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete vendor type")
+	}
+	if tag.RowsAffected() == 0 {
+		return notFound("vendor_type", code)
+	}
+
+	return nil
+}
+
+// CountVendorsByType counts the non-deleted vendors of an entity with a vendor type
+func (r *VendorRepository) CountVendorsByType(ctx context.Context, entityID, code string) (int64, error) {
+	var count int64
+	err := r.q.QueryRow(ctx, `SELECT COUNT(*) FROM vendors WHERE entity_id = $1 AND vendor_type = $2 AND deleted_at IS NULL`, entityID, code).
+		Scan(&count)
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count vendors by type")
+	}
+	return count, nil
+}
@@ -0,0 +1,193 @@
+package reqlog
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pesio-ai/be-lib-common/auth"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+)
+
+// requestIDHeader is set by the shared RequestID middleware
+const requestIDHeader = "X-Request-ID"
+
+// maxLoggedPayload caps the request body logged at debug level
+const maxLoggedPayload = 64 << 10
+
+// Options configures the request logging middleware
+type Options struct {
+	// SlowThreshold logs requests taking longer at Warn with repository timings
+	SlowThreshold time.Duration
+}
+
+// Middleware attaches request fields to the context and logs served requests
+// (see logServed). It must run after the RequestID middleware.
+func Middleware(log *zerolog.Logger, opts Options) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			requestID := w.Header().Get(requestIDHeader)
+			if requestID == "" {
+				requestID = r.Header.Get(requestIDHeader)
+			}
+			ctx, fields := NewContext(r.Context(), requestID)
+
+			query := r.URL.Query()
+			SetEntity(ctx, query.Get("entity_id"))
+			SetVendor(ctx, query.Get("vendor_id"))
+			SetVendor(ctx, query.Get("id"))
+			if user, err := auth.GetUserContext(ctx); err == nil && user != nil {
+				SetUser(ctx, user.UserID)
+			}
+
+			r = r.WithContext(ctx)
+			logPayload(ctx, log, r)
+
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			logServed(log, fields, opts, time.Since(start), func(e *zerolog.Event) *zerolog.Event {
+				return e.Str("method", r.Method).Str("path", r.URL.Path).Int("status", rec.status)
+			})
+		})
+	}
+}
+
+// logServed logs a served request with its total database time and statement
+// count: at Warn with the per-method repository timings when it took longer
+// than the slow threshold, at Debug otherwise
+func logServed(log *zerolog.Logger, fields *Fields, opts Options, elapsed time.Duration, describe func(*zerolog.Event) *zerolog.Event) {
+	l := fields.apply(log.With()).Logger()
+	queries, dbTime := fields.queryTotals()
+
+	event, msg := l.Debug(), "Request served"
+	if opts.SlowThreshold > 0 && elapsed > opts.SlowThreshold {
+		event, msg = l.Warn().Dict("repository", fields.queryTimings()), "Slow request"
+	}
+	describe(event).
+		Dur("duration_ms", elapsed).
+		Int("db_queries", queries).
+		Dur("db_time_ms", dbTime).
+		Msg(msg)
+}
+
+// logPayload logs the redacted JSON body of write requests at debug level
+func logPayload(ctx context.Context, log *zerolog.Logger, r *http.Request) {
+	if log.GetLevel() > zerolog.DebugLevel || r.Body == nil {
+		return
+	}
+	if r.Method != http.MethodPost && r.Method != http.MethodPut && r.Method != http.MethodPatch {
+		return
+	}
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		return
+	}
+
+	buf, err := io.ReadAll(io.LimitReader(r.Body, maxLoggedPayload+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(buf), r.Body), r.Body}
+	if err != nil || len(buf) > maxLoggedPayload {
+		return
+	}
+
+	event := Logger(ctx, log).Debug().Str("method", r.Method).Str("path", r.URL.Path)
+	if redacted, ok := RedactJSON(buf); ok {
+		event = event.RawJSON("payload", redacted)
+	} else {
+		event = event.Int("payload_bytes", len(buf))
+	}
+	event.Msg("Request payload")
+}
+
+// statusRecorder captures the response status for the slow request log
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// UnaryServerInterceptor is the gRPC counterpart of Middleware. It must be
+// chained after the auth interceptor so the user is known.
+func UnaryServerInterceptor(log *zerolog.Logger, opts Options) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+
+		ctx, fields := NewContext(ctx, "")
+		annotateFromMessage(ctx, req)
+		if user, err := auth.GetUserContext(ctx); err == nil && user != nil {
+			SetUser(ctx, user.UserID)
+		}
+
+		if log.GetLevel() <= zerolog.DebugLevel {
+			event := Logger(ctx, log).Debug().Str("method", info.FullMethod)
+			if redacted, ok := Redact(req); ok {
+				event = event.RawJSON("payload", redacted)
+			}
+			event.Msg("Request payload")
+		}
+
+		resp, err := handler(ctx, req)
+
+		logServed(log, fields, opts, time.Since(start), func(e *zerolog.Event) *zerolog.Event {
+			return e.Str("method", info.FullMethod).Err(err)
+		})
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor attaches request fields to server streams
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, _ := NewContext(ss.Context(), "")
+		if user, err := auth.GetUserContext(ctx); err == nil && user != nil {
+			SetUser(ctx, user.UserID)
+		}
+		return handler(srv, &fieldsStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type fieldsStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fieldsStream) Context() context.Context {
+	return s.ctx
+}
+
+// RecvMsg picks up the entity and vendor of the first request message
+func (s *fieldsStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	annotateFromMessage(s.ctx, m)
+	return nil
+}
+
+// annotateFromMessage records the entity and vendor IDs of request messages that carry them
+func annotateFromMessage(ctx context.Context, req interface{}) {
+	if m, ok := req.(interface{ GetEntityId() string }); ok {
+		SetEntity(ctx, m.GetEntityId())
+	}
+	if m, ok := req.(interface{ GetId() string }); ok {
+		SetVendor(ctx, m.GetId())
+	}
+}
@@ -0,0 +1,217 @@
+package reqlog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+)
+
+const testAccountNumber = "000123456789"
+
+// logLines decodes the JSON log lines written to buf
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	for _, raw := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if raw == "" {
+			continue
+		}
+		var line map[string]interface{}
+		if err := json.Unmarshal([]byte(raw), &line); err != nil {
+			t.Fatalf("log line %q is not JSON: %v", raw, err)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// findLine returns the first log line with message msg
+func findLine(t *testing.T, lines []map[string]interface{}, msg string) map[string]interface{} {
+	t.Helper()
+	for _, line := range lines {
+		if line["message"] == msg {
+			return line
+		}
+	}
+	t.Fatalf("no %q log line in %v", msg, lines)
+	return nil
+}
+
+func TestMiddlewareNeverLogsBankNumbers(t *testing.T) {
+	var buf bytes.Buffer
+	log := zerolog.New(&buf).Level(zerolog.DebugLevel)
+
+	var received []byte
+	h := Middleware(&log, Options{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	body := `{"vendor_name": "Northwind", "bank_account_number": "` + testAccountNumber + `",` +
+		` "contacts": [{"first_name": "Ada", "bank_account_number": "` + testAccountNumber + `"}]}`
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/vendors?entity_id=e-1", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if string(received) != body {
+		t.Errorf("handler read %q, want the full body %q", received, body)
+	}
+	if strings.Contains(buf.String(), testAccountNumber) {
+		t.Errorf("log output contains the bank account number:\n%s", buf.String())
+	}
+
+	payload := findLine(t, logLines(t, &buf), "Request payload")
+	if got := payload["entity_id"]; got != "e-1" {
+		t.Errorf("payload entity_id = %v, want e-1", got)
+	}
+	if got := payload["payload"].(map[string]interface{})["bank_account_number"]; got != "****6789" {
+		t.Errorf("payload bank_account_number = %v, want ****6789", got)
+	}
+}
+
+func TestMiddlewareLogsUnparsablePayloadSize(t *testing.T) {
+	var buf bytes.Buffer
+	log := zerolog.New(&buf).Level(zerolog.DebugLevel)
+	h := Middleware(&log, Options{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	body := `{"bank_account_number": "` + testAccountNumber + `"`
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/vendors", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if strings.Contains(buf.String(), testAccountNumber) {
+		t.Errorf("log output contains the bank account number:\n%s", buf.String())
+	}
+	payload := findLine(t, logLines(t, &buf), "Request payload")
+	if got := payload["payload_bytes"]; got != float64(len(body)) {
+		t.Errorf("payload_bytes = %v, want %d", got, len(body))
+	}
+}
+
+func TestUnaryServerInterceptorNeverLogsBankNumbers(t *testing.T) {
+	var buf bytes.Buffer
+	log := zerolog.New(&buf).Level(zerolog.DebugLevel)
+
+	account := testAccountNumber
+	req := &struct {
+		EntityID          string  `json:"entity_id"`
+		BankAccountNumber *string `json:"bank_account_number,omitempty"`
+	}{"e-1", &account}
+
+	intercept := UnaryServerInterceptor(&log, Options{})
+	info := &grpc.UnaryServerInfo{FullMethod: "/ap.VendorService/CreateVendor"}
+	_, err := intercept(context.Background(), req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("interceptor error = %v", err)
+	}
+
+	if strings.Contains(buf.String(), testAccountNumber) {
+		t.Errorf("log output contains the bank account number:\n%s", buf.String())
+	}
+	findLine(t, logLines(t, &buf), "Request payload")
+}
+
+func TestMiddlewareRequestFields(t *testing.T) {
+	var buf bytes.Buffer
+	log := zerolog.New(&buf).Level(zerolog.DebugLevel)
+
+	h := Middleware(&log, Options{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SetVendor(r.Context(), "v-1")
+		SetUser(r.Context(), "u-1")
+		Logger(r.Context(), &log).Info().Msg("Serving")
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/vendors/get?entity_id=e-1", nil)
+	r.Header.Set(requestIDHeader, "req-1")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	lines := logLines(t, &buf)
+	for _, msg := range []string{"Serving", "Request served"} {
+		line := findLine(t, lines, msg)
+		for field, want := range map[string]string{
+			"request_id": "req-1",
+			"entity_id":  "e-1",
+			"vendor_id":  "v-1",
+			"user_id":    "u-1",
+		} {
+			if got := line[field]; got != want {
+				t.Errorf("%q %s = %v, want %s", msg, field, got, want)
+			}
+		}
+	}
+}
+
+func TestMiddlewareSlowRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		wantLevel string
+		wantMsg   string
+	}{
+		{"fast", time.Hour, "debug", "Request served"},
+		{"slow", time.Nanosecond, "warn", "Slow request"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			log := zerolog.New(&buf).Level(zerolog.DebugLevel)
+
+			h := Middleware(&log, Options{SlowThreshold: tt.threshold})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				RecordQuery(r.Context(), "GetByID", 2*time.Millisecond, 1)
+				RecordQuery(r.Context(), "GetByID", 3*time.Millisecond, 1)
+				RecordQuery(r.Context(), "GetContacts", time.Millisecond, 4)
+				time.Sleep(time.Millisecond)
+			}))
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/vendors/get", nil))
+
+			line := findLine(t, logLines(t, &buf), tt.wantMsg)
+			if got := line["level"]; got != tt.wantLevel {
+				t.Errorf("level = %v, want %s", got, tt.wantLevel)
+			}
+			if got := line["db_queries"]; got != float64(3) {
+				t.Errorf("db_queries = %v, want 3", got)
+			}
+
+			repo, ok := line["repository"].(map[string]interface{})
+			if tt.wantLevel == "debug" {
+				if ok {
+					t.Errorf("repository timings = %v, want none below the threshold", repo)
+				}
+				return
+			}
+			if !ok {
+				t.Fatal("slow request log has no repository timings")
+			}
+			getByID, _ := repo["GetByID"].(map[string]interface{})
+			if getByID["count"] != float64(2) || getByID["rows"] != float64(2) {
+				t.Errorf("GetByID timings = %v, want 2 calls and 2 rows", getByID)
+			}
+		})
+	}
+}
+
+func TestMiddlewareSkipsPayloadAboveDebug(t *testing.T) {
+	var buf bytes.Buffer
+	log := zerolog.New(&buf).Level(zerolog.InfoLevel)
+	h := Middleware(&log, Options{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/vendors", strings.NewReader(`{"vendor_name": "Northwind"}`))
+	r.Header.Set("Content-Type", "application/json")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	if buf.Len() != 0 {
+		t.Errorf("log output at info level = %s, want none", buf.String())
+	}
+}
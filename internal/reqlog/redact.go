@@ -0,0 +1,78 @@
+package reqlog
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// sensitiveKeys are payload keys whose values are masked before logging
+var sensitiveKeys = map[string]bool{
+	"bank_account_number": true,
+	"bankaccountnumber":   true,
+	"bank_routing_number": true,
+	"bankroutingnumber":   true,
+	"iban":                true,
+	"secret":              true,
+}
+
+// RedactJSON returns a copy of a JSON payload with bank numbers masked. Payloads
+// that are not valid JSON are not returned at all, since they cannot be
+// inspected.
+func RedactJSON(payload []byte) ([]byte, bool) {
+	var doc interface{}
+	if err := json.Unmarshal(payload, &doc); err != nil {
+		return nil, false
+	}
+
+	out, err := json.Marshal(redactValue(doc))
+	if err != nil {
+		return nil, false
+	}
+	return out, true
+}
+
+// Redact returns v as a JSON payload with bank numbers masked
+func Redact(v interface{}) ([]byte, bool) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+	return RedactJSON(payload)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for key, item := range val {
+			if sensitiveKeys[strings.ToLower(key)] {
+				val[key] = mask(item)
+				continue
+			}
+			val[key] = redactValue(item)
+		}
+		return val
+	case []interface{}:
+		for i, item := range val {
+			val[i] = redactValue(item)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+// mask keeps the last four characters of long values so operators can tell
+// accounts apart without seeing the number
+func mask(v interface{}) interface{} {
+	s, ok := v.(string)
+	if !ok || s == "" {
+		if v == nil {
+			return nil
+		}
+		return "****"
+	}
+	if len(s) <= 8 {
+		return "****"
+	}
+	return "****" + s[len(s)-4:]
+}
@@ -0,0 +1,85 @@
+package reqlog
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRedactJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+	}{
+		{
+			name:    "top level",
+			payload: `{"vendor_name": "Northwind", "bank_account_number": "000123456789"}`,
+			want:    `{"bank_account_number":"****6789","vendor_name":"Northwind"}`,
+		},
+		{
+			name:    "short values are fully masked",
+			payload: `{"bank_account_number": "12345678", "bank_routing_number": "021000021"}`,
+			want:    `{"bank_account_number":"****","bank_routing_number":"****0021"}`,
+		},
+		{
+			name:    "nested objects and arrays",
+			payload: `{"vendor": {"iban": "DE89370400440532013000"}, "contacts": [{"BankAccountNumber": "000123456789"}]}`,
+			want:    `{"contacts":[{"BankAccountNumber":"****6789"}],"vendor":{"iban":"****3000"}}`,
+		},
+		{
+			name:    "key case is ignored",
+			payload: `{"Bank_Account_Number": "000123456789"}`,
+			want:    `{"Bank_Account_Number":"****6789"}`,
+		},
+		{
+			name:    "non string values",
+			payload: `{"bank_account_number": 123456789012, "iban": null}`,
+			want:    `{"bank_account_number":"****","iban":null}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := RedactJSON([]byte(tt.payload))
+			if !ok {
+				t.Fatalf("RedactJSON(%s) failed", tt.payload)
+			}
+			if string(got) != tt.want {
+				t.Errorf("RedactJSON(%s) = %s, want %s", tt.payload, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactJSONInvalid(t *testing.T) {
+	if got, ok := RedactJSON([]byte(`{"bank_account_number": "000123456789"`)); ok || got != nil {
+		t.Errorf("RedactJSON(truncated) = %s, %v, want nothing", got, ok)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	account := "000123456789"
+	req := struct {
+		VendorName        string  `json:"vendor_name"`
+		BankAccountNumber *string `json:"bank_account_number,omitempty"`
+		// Untagged fields are marshalled under their Go name
+		BankRoutingNumber string
+	}{"Northwind", &account, "021000021"}
+
+	got, ok := Redact(req)
+	if !ok {
+		t.Fatal("Redact() failed")
+	}
+	if strings.Contains(string(got), account) || strings.Contains(string(got), "021000021") {
+		t.Errorf("Redact() = %s, leaks a bank number", got)
+	}
+
+	var doc map[string]string
+	if err := json.Unmarshal(got, &doc); err != nil {
+		t.Fatalf("Redact() = %s, not JSON: %v", got, err)
+	}
+	if doc["vendor_name"] != "Northwind" {
+		t.Errorf("vendor_name = %q, want %q", doc["vendor_name"], "Northwind")
+	}
+}
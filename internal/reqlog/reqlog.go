@@ -0,0 +1,163 @@
+// Package reqlog carries per-request log fields (request, entity, vendor and
+// user IDs) and repository timings through the request context, so every log
+// line written while serving a request can be correlated.
+package reqlog
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type contextKey struct{}
+
+// Fields are the log fields of a single request. They are filled in as the
+// request is decoded and served, and are safe for concurrent use.
+type Fields struct {
+	mu        sync.Mutex
+	requestID string
+	entityID  string
+	vendorID  string
+	userID    string
+	queries   map[string]*queryStat
+}
+
+type queryStat struct {
+	count int
+	rows  int64
+	total time.Duration
+}
+
+// NewContext returns a context carrying a fresh set of fields
+func NewContext(ctx context.Context, requestID string) (context.Context, *Fields) {
+	f := &Fields{requestID: requestID}
+	return context.WithValue(ctx, contextKey{}, f), f
+}
+
+// FromContext returns the fields of ctx, or nil outside a request
+func FromContext(ctx context.Context) *Fields {
+	f, _ := ctx.Value(contextKey{}).(*Fields)
+	return f
+}
+
+// SetEntity records the entity the request operates on
+func SetEntity(ctx context.Context, entityID string) {
+	if f := FromContext(ctx); f != nil && entityID != "" {
+		f.mu.Lock()
+		f.entityID = entityID
+		f.mu.Unlock()
+	}
+}
+
+// SetVendor records the vendor the request operates on
+func SetVendor(ctx context.Context, vendorID string) {
+	if f := FromContext(ctx); f != nil && vendorID != "" {
+		f.mu.Lock()
+		f.vendorID = vendorID
+		f.mu.Unlock()
+	}
+}
+
+// SetUser records the authenticated user of the request
+func SetUser(ctx context.Context, userID string) {
+	if f := FromContext(ctx); f != nil && userID != "" {
+		f.mu.Lock()
+		f.userID = userID
+		f.mu.Unlock()
+	}
+}
+
+// RecordQuery adds the duration and rows of a repository operation to the
+// request timings
+func RecordQuery(ctx context.Context, op string, d time.Duration, rows int64) {
+	f := FromContext(ctx)
+	if f == nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.queries == nil {
+		f.queries = make(map[string]*queryStat)
+	}
+	stat, ok := f.queries[op]
+	if !ok {
+		stat = &queryStat{}
+		f.queries[op] = stat
+	}
+	stat.count++
+	stat.rows += rows
+	stat.total += d
+}
+
+// queryTotals returns the number of repository statements of the request and
+// their total duration
+func (f *Fields) queryTotals() (int, time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	count, total := 0, time.Duration(0)
+	for _, stat := range f.queries {
+		count += stat.count
+		total += stat.total
+	}
+	return count, total
+}
+
+// Logger returns base enriched with the request fields of ctx
+func Logger(ctx context.Context, base *zerolog.Logger) *zerolog.Logger {
+	f := FromContext(ctx)
+	if f == nil {
+		return base
+	}
+
+	l := f.apply(base.With()).Logger()
+	return &l
+}
+
+func (f *Fields) apply(c zerolog.Context) zerolog.Context {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.requestID != "" {
+		c = c.Str("request_id", f.requestID)
+	}
+	if f.entityID != "" {
+		c = c.Str("entity_id", f.entityID)
+	}
+	if f.vendorID != "" {
+		c = c.Str("vendor_id", f.vendorID)
+	}
+	if f.userID != "" {
+		c = c.Str("user_id", f.userID)
+	}
+	return c
+}
+
+// queryTimings returns the repository timings as a log dictionary, slowest first
+func (f *Fields) queryTimings() *zerolog.Event {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ops := make([]string, 0, len(f.queries))
+	for op := range f.queries {
+		ops = append(ops, op)
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		return f.queries[ops[i]].total > f.queries[ops[j]].total
+	})
+
+	dict := zerolog.Dict()
+	for _, op := range ops {
+		stat := f.queries[op]
+		dict = dict.Dict(op, zerolog.Dict().
+			Int("count", stat.count).
+			Int64("rows", stat.rows).
+			Dur("total_ms", stat.total))
+	}
+	return dict
+}
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/identity"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+// Activity types of the vendor activity feed
+const (
+	ActivityStatusChange = "status_change"
+	ActivityFieldEdit    = "field_edit"
+	ActivityNote         = "note"
+	ActivityBankChange   = "bank_change"
+	ActivityTransfer     = "transfer"
+	ActivityBalance      = "balance"
+	ActivityDocument     = "document"
+)
+
+// ActivityTypes are the activity types, as accepted by the type filter
+var ActivityTypes = []string{
+	ActivityStatusChange,
+	ActivityFieldEdit,
+	ActivityNote,
+	ActivityBankChange,
+	ActivityTransfer,
+	ActivityBalance,
+	ActivityDocument,
+}
+
+const (
+	// DefaultActivityLimit is the number of activity items returned when no limit is requested
+	DefaultActivityLimit = 50
+	// MaxActivityLimit is the largest number of activity items returned at once
+	MaxActivityLimit = 200
+)
+
+// Sources of activity items, prefixing their IDs
+const (
+	activitySourceAudit    = "audit"
+	activitySourceBalance  = "balance"
+	activitySourceDocument = "document"
+)
+
+// auditActivityTypes maps audit actions to the activity type they appear as
+var auditActivityTypes = map[string]string{
+	AuditActionStatusChanged:      ActivityStatusChange,
+	AuditActionApprovalRecorded:   ActivityStatusChange,
+	AuditActionVendorUpdated:      ActivityFieldEdit,
+	AuditActionNotesChanged:       ActivityNote,
+	AuditActionBankDetailsChanged: ActivityBankChange,
+	AuditActionTransferOut:        ActivityTransfer,
+	AuditActionTransferIn:         ActivityTransfer,
+}
+
+// activitySections are the snapshot sections whose access governs each
+// activity type
+var activitySections = map[string]string{
+	ActivityStatusChange: SnapshotAudit,
+	ActivityFieldEdit:    SnapshotAudit,
+	ActivityNote:         SnapshotNotes,
+	ActivityBankChange:   SnapshotBank,
+	ActivityTransfer:     SnapshotAudit,
+	ActivityBalance:      SnapshotBalanceTransactions,
+	ActivityDocument:     SnapshotDocuments,
+}
+
+// ActivityItem is an entry of a vendor's activity feed
+type ActivityItem struct {
+	// ID is unique across sources, e.g. "audit:<uuid>"
+	ID        string                 `json:"id"`
+	Type      string                 `json:"type"`
+	Actor     *string                `json:"actor,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+	Summary   string                 `json:"summary"`
+	Payload   map[string]interface{} `json:"payload,omitempty"`
+	// ActorUser is the user of Actor; only populated on request
+	ActorUser *identity.User `json:"actor_user,omitempty"`
+}
+
+// ActivityQuery selects a page of a vendor's activity feed
+type ActivityQuery struct {
+	// Types restricts the feed to the given activity types; empty returns all
+	Types []string
+	// Since excludes activity before it; zero returns the whole history
+	Since time.Time
+	// Cursor continues after the last item of a previous page
+	Cursor string
+	Limit  int
+	// ResolveUsers adds the user of each item's actor
+	ResolveUsers bool
+}
+
+// ActivityPage is a page of a vendor's activity feed, newest first
+type ActivityPage struct {
+	Items []*ActivityItem `json:"items"`
+	// NextCursor fetches the next, older page; empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// GetVendorActivity returns a vendor's status changes, field edits, note
+// changes, bank detail changes, transfers, balance movements and document
+// uploads as one stream, newest first. Activity types whose section the caller
+// may not see are left out.
+func (s *VendorService) GetVendorActivity(ctx context.Context, vendorID, entityID string, q ActivityQuery, allowed func(section string) bool) (*ActivityPage, error) {
+	v := &validator{}
+	selected := make(map[string]bool)
+	for _, activityType := range q.Types {
+		_, known := activitySections[activityType]
+		v.check(known, "type", fmt.Sprintf("unknown activity type %q (expected one of %s)", activityType, strings.Join(ActivityTypes, ", ")))
+		selected[activityType] = true
+	}
+	before, beforeID, err := decodeActivityCursor(q.Cursor)
+	v.check(err == nil, "cursor", "invalid cursor")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultActivityLimit
+	}
+	q.Limit = min(q.Limit, MaxActivityLimit)
+
+	if _, err := s.vendorRepo.GetByID(ctx, vendorID, entityID); err != nil {
+		return nil, err
+	}
+
+	include := func(activityType string) bool {
+		return (len(selected) == 0 || selected[activityType]) && allowed(activitySections[activityType])
+	}
+	// Each source returns one item more than the page, so a further page exists
+	// whenever more items are found than the page holds
+	rangeOf := func(source string) repository.ActivityRange {
+		return repository.ActivityRange{
+			Since:    q.Since,
+			Before:   before,
+			BeforeID: sourceBound(source, beforeID),
+			Limit:    q.Limit + 1,
+		}
+	}
+
+	var items []*ActivityItem
+
+	var actions []string
+	for action, activityType := range auditActivityTypes {
+		if include(activityType) {
+			actions = append(actions, action)
+		}
+	}
+	if len(actions) > 0 {
+		sort.Strings(actions)
+		entries, err := s.vendorRepo.ListAuditActivity(ctx, vendorID, entityID, actions, rangeOf(activitySourceAudit))
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			items = append(items, auditActivity(entry))
+		}
+	}
+
+	if include(ActivityBalance) {
+		transactions, err := s.vendorRepo.ListBalanceActivity(ctx, vendorID, entityID, rangeOf(activitySourceBalance))
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range transactions {
+			summary := fmt.Sprintf("Balance adjusted by %+d to %d", t.Amount, t.BalanceAfter)
+			if t.Kind == repository.BalanceKindReconciliation {
+				summary = fmt.Sprintf("Balance reconciled to %d", t.BalanceAfter)
+			}
+			items = append(items, &ActivityItem{
+				ID:        activitySourceBalance + ":" + t.ID,
+				Type:      ActivityBalance,
+				Timestamp: t.CreatedAt,
+				Summary:   summary,
+				Payload:   map[string]interface{}{"amount": t.Amount, "balance_after": t.BalanceAfter, "kind": t.Kind},
+			})
+		}
+	}
+
+	if include(ActivityDocument) {
+		uploads, err := s.vendorRepo.ListDocumentActivity(ctx, vendorID, rangeOf(activitySourceDocument))
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range uploads {
+			items = append(items, &ActivityItem{
+				ID:        activitySourceDocument + ":" + u.ID,
+				Type:      ActivityDocument,
+				Actor:     u.UploadedBy,
+				Timestamp: u.UploadedAt,
+				Summary:   fmt.Sprintf("Uploaded %s document %s", u.DocumentType, u.DocumentName),
+				Payload:   map[string]interface{}{"document_id": u.ID, "document_type": u.DocumentType, "document_name": u.DocumentName},
+			})
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].Timestamp.Equal(items[j].Timestamp) {
+			return items[i].Timestamp.After(items[j].Timestamp)
+		}
+		return items[i].ID > items[j].ID
+	})
+
+	page := &ActivityPage{Items: make([]*ActivityItem, 0, q.Limit)}
+	if len(items) > q.Limit {
+		items = items[:q.Limit]
+		last := items[len(items)-1]
+		page.NextCursor = encodeActivityCursor(last.Timestamp, last.ID)
+	}
+	page.Items = append(page.Items, items...)
+	if q.ResolveUsers {
+		s.attachActorUsers(ctx, page.Items)
+	}
+	return page, nil
+}
+
+// auditActivity presents an audit entry as an activity item
+func auditActivity(entry *repository.AuditEntry) *ActivityItem {
+	item := &ActivityItem{
+		ID:        activitySourceAudit + ":" + entry.ID,
+		Type:      auditActivityTypes[entry.Action],
+		Actor:     entry.ActorID,
+		Timestamp: entry.CreatedAt,
+		Payload:   entry.Details,
+	}
+
+	switch entry.Action {
+	case AuditActionStatusChanged:
+		item.Summary = fmt.Sprintf("Status changed from %v to %v", entry.Details["from"], entry.Details["to"])
+	case AuditActionApprovalRecorded:
+		item.Summary = fmt.Sprintf("Approval %v of %v recorded", entry.Details["received"], entry.Details["required"])
+	case AuditActionVendorUpdated:
+		item.Summary = "Updated " + strings.Join(stringList(entry.Details["changed_fields"]), ", ")
+	case AuditActionNotesChanged:
+		item.Summary = "Notes updated"
+		if entry.Details["notes"] == nil {
+			item.Summary = "Notes cleared"
+		}
+	case AuditActionBankDetailsChanged:
+		item.Summary = "Bank details changed: " + strings.Join(stringList(entry.Details["changed_fields"]), ", ")
+	case AuditActionTransferOut:
+		item.Summary = fmt.Sprintf("Transferred to entity %v", entry.Details["to_entity_id"])
+	case AuditActionTransferIn:
+		item.Summary = fmt.Sprintf("Transferred from entity %v", entry.Details["from_entity_id"])
+	}
+	return item
+}
+
+// stringList reads a list of strings from audit details, as stored or as
+// decoded from JSON
+func stringList(value interface{}) []string {
+	switch list := value.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		names := make([]string, 0, len(list))
+		for _, item := range list {
+			names = append(names, fmt.Sprint(item))
+		}
+		return names
+	}
+	return nil
+}
+
+// encodeActivityCursor encodes the position after an activity item
+func encodeActivityCursor(timestamp time.Time, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(timestamp.UTC().Format(time.RFC3339Nano) + "|" + id))
+}
+
+// decodeActivityCursor decodes a cursor into the timestamp and ID of the item
+// it follows; an empty cursor starts at the newest item
+func decodeActivityCursor(cursor string) (time.Time, string, error) {
+	if cursor == "" {
+		return time.Time{}, "", nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok || !strings.Contains(id, ":") {
+		return time.Time{}, "", fmt.Errorf("malformed cursor")
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return time.Time{}, "", err
+	}
+	return timestamp, id, nil
+}
+
+// sourceBound translates the cursor item ID into the bound on row IDs of a
+// source for rows at the cursor timestamp. Items order by "source:id", so rows
+// of sources sorting before the cursor's source all come after it, and rows of
+// sources sorting after it all came before.
+func sourceBound(source, cursorID string) string {
+	cursorSource, id, _ := strings.Cut(cursorID, ":")
+	switch {
+	case cursorID == "":
+		return ""
+	case source == cursorSource:
+		return id
+	case source < cursorSource:
+		// Above every UUID
+		return "~"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/address"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+const (
+	// addressScanBatchSize is the number of vendors read per batch
+	addressScanBatchSize = 500
+	// maxReportedMismatches bounds the vendors listed in a normalization report
+	maxReportedMismatches = 1000
+)
+
+// AddressNormalizationOptions configures a normalization run
+type AddressNormalizationOptions struct {
+	// EntityID limits the run to one entity; empty scans all entities
+	EntityID string
+	// Apply rewrites mismatching vendors; otherwise they are only reported
+	Apply bool
+}
+
+// AddressMismatch is a vendor whose country or state/province is not in
+// normalized form
+type AddressMismatch struct {
+	VendorID      string  `json:"vendor_id"`
+	EntityID      string  `json:"entity_id"`
+	VendorCode    string  `json:"vendor_code"`
+	Country       string  `json:"country"`
+	StateProvince *string `json:"state_province"`
+	// NormalizedCountry and NormalizedStateProvince are the normalized values
+	NormalizedCountry       string  `json:"normalized_country"`
+	NormalizedStateProvince *string `json:"normalized_state_province"`
+}
+
+// AddressNormalizationReport summarizes a normalization run
+type AddressNormalizationReport struct {
+	Applied    bool `json:"applied"`
+	Scanned    int  `json:"scanned"`
+	Mismatched int  `json:"mismatched"`
+	Fixed      int  `json:"fixed"`
+	// Vendors lists the first mismatching vendors; Truncated is set when there were more
+	Vendors   []*AddressMismatch `json:"vendors"`
+	Truncated bool               `json:"truncated"`
+}
+
+// NormalizeAddresses finds vendors whose country or state/province differ from
+// the form create and update store them in, and rewrites them when opts.Apply
+// is set. Vendors deleted while the run is in progress are skipped.
+func (s *VendorService) NormalizeAddresses(ctx context.Context, opts AddressNormalizationOptions) (*AddressNormalizationReport, error) {
+	ctx = repository.WithBulkBudget(repository.UsePrimary(ctx))
+
+	report := &AddressNormalizationReport{
+		Applied: opts.Apply,
+		Vendors: make([]*AddressMismatch, 0),
+	}
+
+	var afterID string
+	for {
+		regions, err := s.vendorRepo.ListVendorRegions(ctx, opts.EntityID, afterID, addressScanBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(regions) == 0 {
+			break
+		}
+		afterID = regions[len(regions)-1].ID
+		report.Scanned += len(regions)
+
+		for _, region := range regions {
+			country := address.NormalizeCountry(region.Country)
+			state := normalizeRegion(country, region.StateProvince)
+			if country == region.Country && equalStrings(state, region.StateProvince) {
+				continue
+			}
+
+			report.Mismatched++
+			if len(report.Vendors) < maxReportedMismatches {
+				report.Vendors = append(report.Vendors, &AddressMismatch{
+					VendorID:                region.ID,
+					EntityID:                region.EntityID,
+					VendorCode:              region.VendorCode,
+					Country:                 region.Country,
+					StateProvince:           region.StateProvince,
+					NormalizedCountry:       country,
+					NormalizedStateProvince: state,
+				})
+			} else {
+				report.Truncated = true
+			}
+
+			if !opts.Apply {
+				continue
+			}
+			region.Country, region.StateProvince = country, state
+			if err := s.vendorRepo.UpdateVendorRegion(ctx, region); err != nil {
+				if repository.IsNotFound(err) {
+					continue
+				}
+				return nil, err
+			}
+			report.Fixed++
+		}
+	}
+
+	s.logger(ctx).Info().
+		Str("entity_id", opts.EntityID).
+		Bool("applied", report.Applied).
+		Int("scanned", report.Scanned).
+		Int("mismatched", report.Mismatched).
+		Int("fixed", report.Fixed).
+		Msg("Vendor addresses normalized")
+
+	return report, nil
+}
+
+// equalStrings reports whether two optional strings hold the same value
+func equalStrings(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/address"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+// normalizeRegion returns the canonical form of a vendor's state or province,
+// nil when it is blank
+func normalizeRegion(country string, region *string) *string {
+	if region == nil {
+		return nil
+	}
+	normalized := address.NormalizeRegion(country, *region)
+	if normalized == "" {
+		return nil
+	}
+	return &normalized
+}
+
+// vendorAddress returns the mailing address of a vendor
+func vendorAddress(vendor *repository.Vendor) address.Address {
+	value := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	return address.Address{
+		Line1:         value(vendor.AddressLine1),
+		Line2:         value(vendor.AddressLine2),
+		City:          value(vendor.City),
+		StateProvince: value(vendor.StateProvince),
+		PostalCode:    value(vendor.PostalCode),
+		Country:       vendor.Country,
+	}
+}
+
+// checkAddress validates the address of a vendor being created (before is nil)
+// or updated, unless an update leaves the address unchanged. Problems are
+// recorded in v when the entity enables strict address validation and are
+// returned as warnings otherwise. A validator that cannot be reached only
+// produces a warning.
+func (s *VendorService) checkAddress(ctx context.Context, v *validator, before, vendor *repository.Vendor) ([]*repository.ValidationWarning, error) {
+	addr := vendorAddress(vendor)
+	if addr.IsEmpty() || (before != nil && vendorAddress(before) == addr) {
+		return nil, nil
+	}
+
+	issues := address.Check(addr)
+	verified, err := s.addressValidator.Validate(ctx, addr)
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Msg("Address validation provider failed")
+	}
+	issues = append(issues, verified...)
+
+	var warnings []*repository.ValidationWarning
+	if err != nil {
+		warnings = append(warnings, &repository.ValidationWarning{
+			Code:    WarningAddressUnverified,
+			Message: "address could not be verified: the address validation provider is unavailable",
+		})
+	}
+	if len(issues) == 0 {
+		return warnings, nil
+	}
+
+	flags, err := s.entityFlags(ctx, vendor.EntityID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, issue := range issues {
+		if flags.StrictAddressValidation {
+			v.add(issue.Field, issue.Message)
+		} else {
+			warnings = append(warnings, &repository.ValidationWarning{Code: WarningInvalidAddress, Field: issue.Field, Message: issue.Message})
+		}
+	}
+	return warnings, nil
+}
+
+// applyAddressColumns copies the address fields named in columns from src to dst
+func applyAddressColumns(dst, src *repository.Vendor, columns []string) {
+	for _, column := range columns {
+		switch column {
+		case "address_line1":
+			dst.AddressLine1 = src.AddressLine1
+		case "address_line2":
+			dst.AddressLine2 = src.AddressLine2
+		case "city":
+			dst.City = src.City
+		case "state_province":
+			dst.StateProvince = src.StateProvince
+		case "postal_code":
+			dst.PostalCode = src.PostalCode
+		case "country":
+			dst.Country = src.Country
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+)
+
+// Audit actions written for vendor alias management
+const (
+	AuditActionAliasAdded   = "alias_added"
+	AuditActionAliasRemoved = "alias_removed"
+)
+
+// maxAliasLength matches vendor_aliases.alias
+const maxAliasLength = 255
+
+// AddVendorAliasRequest adds an alternate name to a vendor
+type AddVendorAliasRequest struct {
+	VendorID  string  `json:"-"`
+	EntityID  string  `json:"entity_id"`
+	Alias     string  `json:"alias"`
+	CreatedBy *string `json:"-"`
+}
+
+// AddedVendorAlias is a new alias with the other vendors of the entity already
+// known by the same name. Trade names are occasionally shared, so conflicts are
+// reported rather than rejected.
+type AddedVendorAlias struct {
+	*repository.VendorAlias
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// AddVendorAlias adds an alias to a vendor. An alias equal to one the vendor
+// already has, ignoring case and spacing, is rejected.
+func (s *VendorService) AddVendorAlias(ctx context.Context, req *AddVendorAliasRequest) (*AddedVendorAlias, error) {
+	req.Alias = strings.TrimSpace(req.Alias)
+	v := &validator{}
+	v.check(req.EntityID != "", "entity_id", "entity_id is required")
+	v.check(req.Alias != "", "alias", "alias is required")
+	v.check(utf8.RuneCountInString(req.Alias) <= maxAliasLength, "alias",
+		fmt.Sprintf("alias must be at most %d characters", maxAliasLength))
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	if err := s.checkEntityWritable(ctx, req.EntityID); err != nil {
+		return nil, err
+	}
+
+	alias := &repository.VendorAlias{
+		VendorID:  req.VendorID,
+		Alias:     req.Alias,
+		CreatedBy: req.CreatedBy,
+	}
+	err := s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		if _, err := repo.GetByID(ctx, req.VendorID, req.EntityID); err != nil {
+			return err
+		}
+		if err := repo.InsertVendorAlias(ctx, alias); err != nil {
+			if isAlreadyExists(err) {
+				v := &validator{}
+				v.add("alias", fmt.Sprintf("the vendor already has the alias %q", req.Alias))
+				return v.err()
+			}
+			return err
+		}
+		return repo.InsertAuditEntry(ctx, &repository.AuditEntry{
+			EntityID: req.EntityID,
+			VendorID: alias.VendorID,
+			Action:   AuditActionAliasAdded,
+			ActorID:  req.CreatedBy,
+			Details: map[string]interface{}{
+				"alias_id": alias.ID,
+				"alias":    alias.Alias,
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reqlog.SetVendor(ctx, alias.VendorID)
+	s.logger(ctx).Info().Str("alias_id", alias.ID).Msg("Vendor alias added")
+
+	added := &AddedVendorAlias{VendorAlias: alias}
+	matches, err := s.vendorRepo.MatchVendorsByName(repository.UsePrimary(ctx), req.EntityID, alias.Alias)
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Str("alias_id", alias.ID).Msg("Failed to check vendor alias conflicts")
+		return added, nil
+	}
+	for _, match := range matches {
+		if match.Vendor.ID == alias.VendorID {
+			continue
+		}
+		added.Warnings = append(added.Warnings, fmt.Sprintf("%q is also the %s of vendor %s (%s)",
+			match.MatchedName, strings.ReplaceAll(match.MatchedOn, "_", " "), match.Vendor.VendorCode, match.Vendor.VendorName))
+	}
+	return added, nil
+}
+
+// ListVendorAliases retrieves the aliases of a vendor
+func (s *VendorService) ListVendorAliases(ctx context.Context, vendorID, entityID string) ([]*repository.VendorAlias, error) {
+	if _, err := s.vendorRepo.GetByID(ctx, vendorID, entityID); err != nil {
+		return nil, err
+	}
+	return s.vendorRepo.ListVendorAliases(ctx, vendorID)
+}
+
+// RemoveVendorAlias removes an alias of a vendor
+func (s *VendorService) RemoveVendorAlias(ctx context.Context, aliasID, vendorID, entityID string, removedBy *string) error {
+	if err := s.checkEntityWritable(ctx, entityID); err != nil {
+		return err
+	}
+	var alias *repository.VendorAlias
+	err := s.vendorRepo.WithTx(ctx, func(repo repository.Store) (err error) {
+		if _, err := repo.GetByID(ctx, vendorID, entityID); err != nil {
+			return err
+		}
+		alias, err = repo.DeleteVendorAlias(ctx, aliasID, vendorID)
+		if err != nil {
+			return err
+		}
+		return repo.InsertAuditEntry(ctx, &repository.AuditEntry{
+			EntityID: entityID,
+			VendorID: vendorID,
+			Action:   AuditActionAliasRemoved,
+			ActorID:  removedBy,
+			Details: map[string]interface{}{
+				"alias_id": alias.ID,
+				"alias":    alias.Alias,
+			},
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	reqlog.SetVendor(ctx, vendorID)
+	s.logger(ctx).Info().Str("alias_id", alias.ID).Msg("Vendor alias removed")
+	return nil
+}
+
+// MatchVendorsByName finds the vendors of an entity known by a name, comparing
+// vendor names, legal names, DBA names and aliases without regard to case or
+// spacing. Several vendors can match.
+func (s *VendorService) MatchVendorsByName(ctx context.Context, entityID, name string) ([]*repository.VendorNameMatch, error) {
+	v := &validator{}
+	v.check(entityID != "", "entity_id", "entity_id is required")
+	v.check(strings.TrimSpace(name) != "", "name", "name is required")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return s.vendorRepo.MatchVendorsByName(ctx, entityID, name)
+}
+
+// Weights of fuzzy vendor matching. A candidate scores its name similarity
+// plus a boost per matching identifier, capped at 1.
+const (
+	minMatchNameSimilarity = 0.3
+	taxIDMatchBoost        = 0.3
+	bankMatchBoost         = 0.3
+	countryMatchBoost      = 0.05
+	maxMatchCandidates     = 10
+	// matchCandidatePool is how many candidates the store ranks before the
+	// country boost reorders them
+	matchCandidatePool = 5 * maxMatchCandidates
+	// minTaxIDFragmentLength keeps short fragments from matching most vendors
+	minTaxIDFragmentLength = 4
+)
+
+// MatchVendorsRequest asks which vendor a raw supplier name, as extracted from
+// an invoice, belongs to
+type MatchVendorsRequest struct {
+	EntityID string `json:"entity_id"`
+	Name     string `json:"name"`
+	// TaxID is the supplier's tax ID or part of it
+	TaxID string `json:"tax_id,omitempty"`
+	// BankLast4 are the last four digits of the supplier's bank account
+	BankLast4 string `json:"bank_last4,omitempty"`
+	Country   string `json:"country,omitempty"`
+}
+
+// VendorMatchScore explains the score of a match candidate
+type VendorMatchScore struct {
+	NameSimilarity float64 `json:"name_similarity"`
+	TaxIDBoost     float64 `json:"tax_id_boost"`
+	BankBoost      float64 `json:"bank_boost"`
+	CountryBoost   float64 `json:"country_boost"`
+}
+
+// VendorMatch is a candidate vendor of a fuzzy match. Score runs from 0 to 1;
+// MatchedOn and MatchedName are the vendor's name most similar to the
+// requested one.
+type VendorMatch struct {
+	Vendor      *repository.Vendor `json:"vendor"`
+	Score       float64            `json:"score"`
+	MatchedOn   string             `json:"matched_on"`
+	MatchedName string             `json:"matched_name"`
+	Breakdown   VendorMatchScore   `json:"breakdown"`
+}
+
+// MatchVendors ranks the vendors of an entity by how likely they are the
+// supplier described by req: trigram similarity of the name with the vendor's
+// names and aliases, boosted by a matching tax ID, bank account or country.
+// At most 10 candidates are returned, best first.
+func (s *VendorService) MatchVendors(ctx context.Context, req *MatchVendorsRequest) ([]*VendorMatch, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	taxID := repository.NormalizeIdentifier(req.TaxID)
+	country := strings.ToUpper(strings.TrimSpace(req.Country))
+	v := &validator{}
+	v.check(req.EntityID != "", "entity_id", "entity_id is required")
+	v.check(req.Name != "", "name", "name is required")
+	v.check(req.TaxID == "" || len(taxID) >= minTaxIDFragmentLength, "tax_id",
+		fmt.Sprintf("tax_id must have at least %d letters or digits", minTaxIDFragmentLength))
+	v.check(req.BankLast4 == "" || isDigits(req.BankLast4, 4), "bank_last4", "bank_last4 must be 4 digits")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.vendorRepo.FindVendorMatchCandidates(ctx, repository.VendorMatchQuery{
+		EntityID:      req.EntityID,
+		Name:          req.Name,
+		TaxIDFragment: taxID,
+		BankLast4:     req.BankLast4,
+		MinSimilarity: minMatchNameSimilarity,
+		Limit:         matchCandidatePool,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]*VendorMatch, 0, len(candidates))
+	for _, c := range candidates {
+		breakdown := VendorMatchScore{NameSimilarity: roundScore(c.NameSimilarity)}
+		if c.TaxIDMatch {
+			breakdown.TaxIDBoost = taxIDMatchBoost
+		}
+		if c.BankMatch {
+			breakdown.BankBoost = bankMatchBoost
+		}
+		if country != "" && strings.EqualFold(c.Vendor.Country, country) {
+			breakdown.CountryBoost = countryMatchBoost
+		}
+		score := breakdown.NameSimilarity + breakdown.TaxIDBoost + breakdown.BankBoost + breakdown.CountryBoost
+		matches = append(matches, &VendorMatch{
+			Vendor:      c.Vendor,
+			Score:       roundScore(min(score, 1)),
+			MatchedOn:   c.MatchedOn,
+			MatchedName: c.MatchedName,
+			Breakdown:   breakdown,
+		})
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if len(matches) > maxMatchCandidates {
+		matches = matches[:maxMatchCandidates]
+	}
+	return matches, nil
+}
+
+// roundScore rounds a match score to three decimals
+func roundScore(score float64) float64 {
+	return math.Round(score*1000) / 1000
+}
+
+// isDigits reports whether s is n ASCII digits
+func isDigits(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/authz"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// Scopes of vendor API keys
+const (
+	// ScopeReadProfile reads the vendor's own profile and contacts
+	ScopeReadProfile = "read_profile"
+	// ScopeReadInvoices reads what the vendor was paid
+	ScopeReadInvoices = "read_invoices"
+)
+
+// VendorAPIKeyScopes are the scopes a vendor API key can carry
+var VendorAPIKeyScopes = []string{ScopeReadProfile, ScopeReadInvoices}
+
+// Audit actions written for vendor API key management
+const (
+	AuditActionAPIKeyCreated = "api_key_created"
+	AuditActionAPIKeyRevoked = "api_key_revoked"
+)
+
+const (
+	// vendorAPIKeyPrefix starts every vendor API key secret
+	vendorAPIKeyPrefix = "vk_"
+	// vendorAPIKeyDisplayLength is the length of the stored key prefix
+	vendorAPIKeyDisplayLength = 11
+	// maxVendorAPIKeyNameLength matches vendor_api_keys.name
+	maxVendorAPIKeyNameLength = 100
+)
+
+// ErrInvalidAPIKey is returned for vendor API keys that are unknown or
+// revoked, or whose vendor no longer exists
+var ErrInvalidAPIKey = stderrors.New("invalid or revoked API key")
+
+// IsVendorAPIKey reports whether a presented credential has the form of a
+// vendor API key
+func IsVendorAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, vendorAPIKeyPrefix)
+}
+
+// CreateVendorAPIKeyRequest creates an API key for a vendor
+type CreateVendorAPIKeyRequest struct {
+	VendorID  string   `json:"-"`
+	EntityID  string   `json:"entity_id"`
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	CreatedBy *string  `json:"-"`
+}
+
+// CreatedVendorAPIKey is a new API key with its secret, which is not stored
+// and cannot be retrieved again
+type CreatedVendorAPIKey struct {
+	*repository.VendorAPIKey
+	Secret string `json:"secret"`
+}
+
+// CreateVendorAPIKey creates an API key scoped to one vendor
+func (s *VendorService) CreateVendorAPIKey(ctx context.Context, req *CreateVendorAPIKeyRequest) (*CreatedVendorAPIKey, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	v := &validator{}
+	v.check(req.EntityID != "", "entity_id", "entity_id is required")
+	v.check(req.Name != "", "name", "name is required")
+	v.check(len(req.Name) <= maxVendorAPIKeyNameLength, "name",
+		fmt.Sprintf("name must be at most %d characters", maxVendorAPIKeyNameLength))
+	v.check(len(req.Scopes) > 0, "scopes", "at least one scope is required")
+	for _, scope := range req.Scopes {
+		v.check(slices.Contains(VendorAPIKeyScopes, scope), "scopes",
+			fmt.Sprintf("unknown scope %q (expected %s)", scope, strings.Join(VendorAPIKeyScopes, ", ")))
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	if err := s.checkEntityWritable(ctx, req.EntityID); err != nil {
+		return nil, err
+	}
+
+	secret, err := newVendorAPIKeySecret()
+	if err != nil {
+		return nil, err
+	}
+	scopes := slices.Clone(req.Scopes)
+	slices.Sort(scopes)
+	key := &repository.VendorAPIKey{
+		VendorID:  req.VendorID,
+		EntityID:  req.EntityID,
+		Name:      req.Name,
+		KeyPrefix: secret[:vendorAPIKeyDisplayLength],
+		KeyHash:   hashVendorAPIKey(secret),
+		Scopes:    slices.Compact(scopes),
+		CreatedBy: req.CreatedBy,
+	}
+
+	err = s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		if _, err := repo.GetByID(ctx, req.VendorID, req.EntityID); err != nil {
+			return err
+		}
+		if err := repo.InsertVendorAPIKey(ctx, key); err != nil {
+			return err
+		}
+		return repo.InsertAuditEntry(ctx, &repository.AuditEntry{
+			EntityID: key.EntityID,
+			VendorID: key.VendorID,
+			Action:   AuditActionAPIKeyCreated,
+			ActorID:  req.CreatedBy,
+			Details: map[string]interface{}{
+				"key_id":     key.ID,
+				"name":       key.Name,
+				"key_prefix": key.KeyPrefix,
+				"scopes":     key.Scopes,
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reqlog.SetVendor(ctx, key.VendorID)
+	s.logger(ctx).Info().
+		Str("key_id", key.ID).
+		Strs("scopes", key.Scopes).
+		Msg("Vendor API key created")
+
+	return &CreatedVendorAPIKey{VendorAPIKey: key, Secret: secret}, nil
+}
+
+// ListVendorAPIKeys retrieves the API keys of a vendor, revoked ones included
+func (s *VendorService) ListVendorAPIKeys(ctx context.Context, vendorID, entityID string) ([]*repository.VendorAPIKey, error) {
+	if _, err := s.vendorRepo.GetByID(ctx, vendorID, entityID); err != nil {
+		return nil, err
+	}
+	return s.vendorRepo.ListVendorAPIKeys(ctx, vendorID, entityID)
+}
+
+// RevokeVendorAPIKey revokes an API key of a vendor; it stops working at once.
+// Revoking a revoked key succeeds without changing it.
+func (s *VendorService) RevokeVendorAPIKey(ctx context.Context, keyID, vendorID, entityID string, revokedBy *string) (*repository.VendorAPIKey, error) {
+	if err := s.checkEntityWritable(ctx, entityID); err != nil {
+		return nil, err
+	}
+	var key *repository.VendorAPIKey
+	err := s.vendorRepo.WithTx(ctx, func(repo repository.Store) (err error) {
+		key, err = repo.RevokeVendorAPIKey(ctx, keyID, vendorID, entityID, revokedBy)
+		if err != nil {
+			return err
+		}
+		return repo.InsertAuditEntry(ctx, &repository.AuditEntry{
+			EntityID: key.EntityID,
+			VendorID: key.VendorID,
+			Action:   AuditActionAPIKeyRevoked,
+			ActorID:  revokedBy,
+			Details: map[string]interface{}{
+				"key_id":     key.ID,
+				"name":       key.Name,
+				"key_prefix": key.KeyPrefix,
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reqlog.SetVendor(ctx, key.VendorID)
+	s.logger(ctx).Info().Str("key_id", key.ID).Msg("Vendor API key revoked")
+
+	return key, nil
+}
+
+// AuthenticateVendorAPIKey resolves a presented secret to the vendor key it
+// belongs to, failing with ErrInvalidAPIKey for unknown and revoked keys and
+// keys of vendors that were deleted or transferred. The use is recorded in
+// last_used_at.
+func (s *VendorService) AuthenticateVendorAPIKey(ctx context.Context, secret string) (*authz.VendorKey, error) {
+	if !IsVendorAPIKey(secret) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	ctx = repository.UsePrimary(ctx)
+	key, err := s.vendorRepo.GetVendorAPIKeyByHash(ctx, hashVendorAPIKey(secret))
+	if isNotFound(err) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return nil, ErrInvalidAPIKey
+	}
+	if _, err := s.vendorRepo.GetByID(ctx, key.VendorID, key.EntityID); err != nil {
+		if isNotFound(err) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	if err := s.vendorRepo.TouchVendorAPIKey(ctx, key.ID, time.Now().UTC()); err != nil {
+		s.logger(ctx).Warn().Err(err).Str("key_id", key.ID).Msg("Failed to record vendor API key use")
+	}
+
+	return &authz.VendorKey{
+		KeyID:    key.ID,
+		VendorID: key.VendorID,
+		EntityID: key.EntityID,
+		Scopes:   key.Scopes,
+	}, nil
+}
+
+// PortalVendor returns a copy of a vendor with the fields suppliers may not
+// see through the portal cleared: bank details, credit limit, notes, tags,
+// internal users, external system IDs, approval progress and risk score
+func PortalVendor(vendor *repository.Vendor) *repository.Vendor {
+	v := *vendor
+	v.CreditLimit = nil
+	v.BankName, v.BankAccountNumber, v.BankRoutingNumber, v.SwiftCode, v.IBAN = nil, nil, nil, nil, nil
+	v.Notes, v.Tags = nil, nil
+	v.CreatedBy, v.UpdatedBy = nil, nil
+	v.ExternalRefs, v.Approval, v.Risk, v.Warnings = nil, nil, nil, nil
+	return &v
+}
+
+// PortalProfile is what a supplier sees of its own vendor record
+type PortalProfile struct {
+	Vendor   *repository.Vendor          `json:"vendor"`
+	Contacts []*repository.VendorContact `json:"contacts"`
+}
+
+// GetPortalProfile returns the profile of the vendor of a key with the
+// read_profile scope
+func (s *VendorService) GetPortalProfile(ctx context.Context, key *authz.VendorKey) (*PortalProfile, error) {
+	vendor, err := s.vendorRepo.GetByID(ctx, key.VendorID, key.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	contacts, err := s.vendorRepo.GetContacts(ctx, vendor.ID)
+	if err != nil {
+		return nil, err
+	}
+	if contacts == nil {
+		contacts = make([]*repository.VendorContact, 0)
+	}
+	return &PortalProfile{Vendor: PortalVendor(vendor), Contacts: contacts}, nil
+}
+
+// GetPortalSpend returns what the vendor of a key with the read_invoices scope
+// was paid
+func (s *VendorService) GetPortalSpend(ctx context.Context, key *authz.VendorKey, periods []string) (*VendorSpend, error) {
+	return s.GetVendorSpend(ctx, key.VendorID, key.EntityID, periods)
+}
+
+// newVendorAPIKeySecret returns a random secret with the vendor API key prefix
+func newVendorAPIKeySecret() (string, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("generate API key: %w", err)
+	}
+	return vendorAPIKeyPrefix + base64.RawURLEncoding.EncodeToString(b[:]), nil
+}
+
+// hashVendorAPIKey returns the stored form of a secret. Secrets are random, so
+// an unsalted hash is enough to make a leaked table useless.
+func hashVendorAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// isNotFound reports whether err is a not found error of the store, in either
+// of the forms lookups return
+func isNotFound(err error) bool {
+	var appErr *errors.AppError
+	return repository.IsNotFound(err) || (stderrors.As(err, &appErr) && appErr.Code == errors.ErrCodeNotFound)
+}
+
+// isAlreadyExists reports whether err is a unique constraint conflict
+func isAlreadyExists(err error) bool {
+	var appErr *errors.AppError
+	return stderrors.As(err, &appErr) && appErr.Code == errors.ErrCodeAlreadyExists
+}
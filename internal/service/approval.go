@@ -0,0 +1,470 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+)
+
+// Event types written for approval decisions and overdue approvals
+const (
+	EventVendorApproved            = "vendor.approved"
+	EventVendorRejected            = "vendor.rejected"
+	EventVendorApprovalSLABreached = "vendor.approval_sla_breached"
+	EventVendorApprovalRecorded    = "vendor.approval_recorded"
+
+	// AuditActionApprovalRecorded is written for approvals that leave a vendor pending
+	AuditActionApprovalRecorded = "approval_recorded"
+)
+
+// Approval queue sort orders: by age, oldest first, or newest first
+const (
+	ApprovalSortAge       = "age"
+	ApprovalSortAgeNewest = "-age"
+)
+
+const (
+	// approvalSLABatchSize is the number of breaches claimed per transaction
+	approvalSLABatchSize = 500
+	// maxDecisionReasonLength bounds the reason given for an approval decision
+	maxDecisionReasonLength = 1000
+)
+
+// ApprovalCompleteness summarizes what a pending vendor lacks before it can be
+// approved with confidence
+type ApprovalCompleteness struct {
+	Complete bool `json:"complete"`
+	// Missing names the missing pieces: tax_id, bank_details, contact, address
+	Missing []string `json:"missing"`
+}
+
+// ApprovalQueueItem is a vendor awaiting approval
+type ApprovalQueueItem struct {
+	ID          string  `json:"id"`
+	VendorCode  string  `json:"vendor_code"`
+	VendorName  string  `json:"vendor_name"`
+	VendorType  string  `json:"vendor_type"`
+	RequestedBy *string `json:"requested_by"`
+	// RequestedAt is when the vendor was created; AgeSeconds is the time since
+	RequestedAt  time.Time            `json:"requested_at"`
+	AgeSeconds   int64                `json:"age_seconds"`
+	SLABreached  bool                 `json:"sla_breached"`
+	Completeness ApprovalCompleteness `json:"completeness"`
+	// Approval is the progress through the entity's approval policy
+	Approval *repository.ApprovalState `json:"approval"`
+	// ReadyToActivate is set when the vendor completed every blocking item
+	// of the entity's onboarding checklist but the approval
+	ReadyToActivate bool `json:"ready_to_activate"`
+}
+
+// ApprovalQueue is a page of the approval queue of an entity
+type ApprovalQueue struct {
+	Items []*ApprovalQueueItem `json:"vendors"`
+	// Count is the number of vendors awaiting approval, for a badge
+	Count    int64 `json:"count"`
+	Page     int   `json:"page"`
+	PageSize int   `json:"page_size"`
+}
+
+// ApprovalQueueCount is the size of the approval queue of an entity
+type ApprovalQueueCount struct {
+	Count int64 `json:"count"`
+	// Overdue counts the vendors waiting longer than the approval SLA
+	Overdue int64 `json:"overdue"`
+}
+
+// ApprovalDecisionRequest approves or rejects a pending vendor
+type ApprovalDecisionRequest struct {
+	VendorID  string `json:"-"`
+	EntityID  string `json:"entity_id"`
+	Reason    string `json:"reason,omitempty"`
+	DecidedBy string `json:"-"`
+}
+
+// approvalCompleteness reports the missing pieces of a pending vendor
+func approvalCompleteness(v *repository.Vendor) ApprovalCompleteness {
+	missing := make([]string, 0)
+	if isBlank(v.TaxID) {
+		missing = append(missing, "tax_id")
+	}
+	if isBlank(v.BankAccountNumber) && isBlank(v.IBAN) {
+		missing = append(missing, "bank_details")
+	}
+	if isBlank(v.Email) && isBlank(v.Phone) {
+		missing = append(missing, "contact")
+	}
+	if isBlank(v.AddressLine1) {
+		missing = append(missing, "address")
+	}
+	return ApprovalCompleteness{Complete: len(missing) == 0, Missing: missing}
+}
+
+// isBlank reports whether an optional string is unset or blank
+func isBlank(s *string) bool {
+	return s == nil || strings.TrimSpace(*s) == ""
+}
+
+// ListApprovalQueue returns the vendors of an entity awaiting approval, sorted
+// by age, with the size of the queue
+func (s *VendorService) ListApprovalQueue(ctx context.Context, entityID, sort string, page, pageSize int) (*ApprovalQueue, error) {
+	v := &validator{}
+	v.check(entityID != "", "entity_id", "entity_id is required")
+	v.check(sort == "" || sort == ApprovalSortAge || sort == ApprovalSortAgeNewest,
+		"sort", "sort must be age or -age")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 50
+	}
+
+	vendors, total, err := s.vendorRepo.ListPendingApproval(ctx, entityID, sort == ApprovalSortAgeNewest, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := s.vendorRepo.GetApprovalPolicy(ctx, entityID)
+	if err != nil {
+		return nil, err
+	}
+	states, err := approvalStates(ctx, s.vendorRepo, policy, vendors)
+	if err != nil {
+		return nil, err
+	}
+	onboarding, err := s.onboardingStatuses(ctx, entityID, vendors, states)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	queue := &ApprovalQueue{
+		Items:    make([]*ApprovalQueueItem, 0, len(vendors)),
+		Count:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}
+	for _, vendor := range vendors {
+		age := now.Sub(vendor.CreatedAt)
+		queue.Items = append(queue.Items, &ApprovalQueueItem{
+			ID:           vendor.ID,
+			VendorCode:   vendor.VendorCode,
+			VendorName:   vendor.VendorName,
+			VendorType:   vendor.VendorType,
+			RequestedBy:  vendor.CreatedBy,
+			RequestedAt:  vendor.CreatedAt,
+			AgeSeconds:   int64(age.Seconds()),
+			SLABreached:  s.approvalSLA > 0 && age > s.approvalSLA,
+			Completeness: approvalCompleteness(vendor),
+			Approval:     states[vendor.ID],
+
+			ReadyToActivate: onboarding[vendor.ID].ReadyToActivate,
+		})
+	}
+
+	return queue, nil
+}
+
+// CountApprovalQueue returns the number of vendors of an entity awaiting
+// approval and how many of them are overdue
+func (s *VendorService) CountApprovalQueue(ctx context.Context, entityID string) (*ApprovalQueueCount, error) {
+	v := &validator{}
+	v.check(entityID != "", "entity_id", "entity_id is required")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	// Without an SLA nothing is overdue
+	overdueBefore := time.Time{}
+	if s.approvalSLA > 0 {
+		overdueBefore = time.Now().Add(-s.approvalSLA)
+	}
+
+	total, overdue, err := s.vendorRepo.CountPendingApproval(ctx, entityID, overdueBefore)
+	if err != nil {
+		return nil, err
+	}
+	return &ApprovalQueueCount{Count: total, Overdue: overdue}, nil
+}
+
+// ApproveVendor records an approval of a vendor awaiting approval, and
+// activates it once its entity's approval policy is satisfied. The response
+// carries the approval progress.
+func (s *VendorService) ApproveVendor(ctx context.Context, req *ApprovalDecisionRequest) (*repository.Vendor, error) {
+	ctx = repository.UsePrimary(ctx)
+	if err := validateDecision(req); err != nil {
+		return nil, err
+	}
+	if err := s.checkEntityWritable(ctx, req.EntityID); err != nil {
+		return nil, err
+	}
+
+	var approverID *string
+	if req.DecidedBy != "" {
+		approverID = &req.DecidedBy
+	}
+
+	// Checked before the transaction, so that a blocked approval is still recorded
+	current, err := s.vendorRepo.GetByID(ctx, req.VendorID, req.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	var warnings []*repository.ValidationWarning
+	if current.Status == "pending_approval" {
+		if warnings, err = s.checkBlocklist(ctx, current, repository.BlocklistStageApprove, approverID); err != nil {
+			return nil, err
+		}
+	}
+
+	var vendor *repository.Vendor
+	err = s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		// Lock before reading, so that concurrent approvers see each other
+		if err := repo.LockVendorApprovals(ctx, req.VendorID); err != nil {
+			return err
+		}
+		var err error
+		if vendor, err = pendingVendor(ctx, repo, req); err != nil {
+			return err
+		}
+
+		state, err := s.vendorApprovalState(ctx, repo, vendor)
+		if err != nil {
+			return err
+		}
+
+		v := &validator{}
+		v.check(approverID != nil || state.Required == 1,
+			"approver", "the approver must be identified when a vendor needs more than one approval")
+		for _, approval := range state.Approvals {
+			v.check(approverID == nil || approval.ApproverID == nil || *approval.ApproverID != *approverID,
+				"approver", "this approver has already approved the vendor; another approver is required")
+		}
+		if err := v.err(); err != nil {
+			return err
+		}
+
+		approval := &repository.VendorApproval{VendorID: vendor.ID, EntityID: vendor.EntityID, ApproverID: approverID}
+		if err := repo.InsertVendorApproval(ctx, approval); err != nil {
+			return err
+		}
+		state.Approvals = append(state.Approvals, approval)
+		state.Received++
+		state.Remaining--
+		vendor.Approval = state
+
+		if state.Remaining > 0 {
+			return recordPartialApproval(ctx, repo, vendor, approverID)
+		}
+		return s.recordDecision(ctx, repo, vendor, "active", EventVendorApproved, req.Reason, approverID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	setWarnings(vendor, warnings)
+
+	reqlog.SetEntity(ctx, req.EntityID)
+	reqlog.SetVendor(ctx, req.VendorID)
+	s.logger(ctx).Info().
+		Int("approvals_received", vendor.Approval.Received).
+		Int("approvals_required", vendor.Approval.Required).
+		Msg("Vendor approval recorded")
+
+	return vendor, nil
+}
+
+// RejectVendor deactivates a vendor awaiting approval, recording the reason
+func (s *VendorService) RejectVendor(ctx context.Context, req *ApprovalDecisionRequest) (*repository.Vendor, error) {
+	ctx = repository.UsePrimary(ctx)
+	if err := validateDecision(req); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkEntityWritable(ctx, req.EntityID); err != nil {
+		return nil, err
+	}
+	var updatedBy *string
+	if req.DecidedBy != "" {
+		updatedBy = &req.DecidedBy
+	}
+
+	var vendor *repository.Vendor
+	err := s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		if err := repo.LockVendorApprovals(ctx, req.VendorID); err != nil {
+			return err
+		}
+		var err error
+		if vendor, err = pendingVendor(ctx, repo, req); err != nil {
+			return err
+		}
+		return s.recordDecision(ctx, repo, vendor, "inactive", EventVendorRejected, req.Reason, updatedBy)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reqlog.SetEntity(ctx, req.EntityID)
+	reqlog.SetVendor(ctx, req.VendorID)
+	s.logger(ctx).Info().Msg("Vendor rejected")
+
+	return vendor, nil
+}
+
+// validateDecision checks the fields of an approval decision
+func validateDecision(req *ApprovalDecisionRequest) error {
+	v := &validator{}
+	v.check(req.VendorID != "", "id", "vendor id is required")
+	v.check(req.EntityID != "", "entity_id", "entity_id is required")
+	v.check(len(req.Reason) <= maxDecisionReasonLength, "reason", "reason must be at most 1000 characters")
+	return v.err()
+}
+
+// pendingVendor loads the vendor of a decision, which must await approval
+func pendingVendor(ctx context.Context, repo repository.Store, req *ApprovalDecisionRequest) (*repository.Vendor, error) {
+	vendor, err := repo.GetByID(ctx, req.VendorID, req.EntityID)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &validator{}
+	v.check(vendor.Status == "pending_approval", "status", "vendor is not awaiting approval (status is "+vendor.Status+")")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return vendor, nil
+}
+
+// recordPartialApproval writes an approval_recorded audit entry and publishes
+// a vendor.approval_recorded event for an approval that leaves the vendor pending
+func recordPartialApproval(ctx context.Context, repo repository.Store, vendor *repository.Vendor, approverID *string) error {
+	details := map[string]interface{}{
+		"vendor_code": vendor.VendorCode,
+		"approver_id": approverID,
+		"received":    vendor.Approval.Received,
+		"required":    vendor.Approval.Required,
+	}
+
+	if err := repo.InsertAuditEntry(ctx, &repository.AuditEntry{
+		EntityID: vendor.EntityID,
+		VendorID: vendor.ID,
+		Action:   AuditActionApprovalRecorded,
+		ActorID:  approverID,
+		Details:  details,
+	}); err != nil {
+		return err
+	}
+	return repo.InsertEvent(ctx, &repository.VendorEvent{
+		EntityID:  vendor.EntityID,
+		VendorID:  vendor.ID,
+		EventType: EventVendorApprovalRecorded,
+		Payload:   details,
+	})
+}
+
+// recordDecision moves a pending vendor to status, writing a status_changed
+// audit entry and publishing eventType on the store of the decision's transaction
+func (s *VendorService) recordDecision(ctx context.Context, repo repository.Store, vendor *repository.Vendor, status, eventType, reason string, decidedBy *string) error {
+	decision := "approved"
+	if status != "active" {
+		decision = "rejected"
+	}
+
+	vendor.Status = status
+	vendor.UpdatedBy = decidedBy
+	if err := repo.Update(ctx, vendor); err != nil {
+		return err
+	}
+
+	details := map[string]interface{}{
+		"from":        "pending_approval",
+		"to":          status,
+		"decision":    decision,
+		"vendor_code": vendor.VendorCode,
+		"decided_by":  decidedBy,
+	}
+	if reason != "" {
+		details["reason"] = reason
+	}
+
+	if err := repo.InsertAuditEntry(ctx, &repository.AuditEntry{
+		EntityID: vendor.EntityID,
+		VendorID: vendor.ID,
+		Action:   AuditActionStatusChanged,
+		ActorID:  decidedBy,
+		Details:  details,
+	}); err != nil {
+		return err
+	}
+	if err := repo.InsertEvent(ctx, &repository.VendorEvent{
+		EntityID:  vendor.EntityID,
+		VendorID:  vendor.ID,
+		EventType: eventType,
+		Payload:   details,
+	}); err != nil {
+		return err
+	}
+	return repo.ClearApprovalSLABreach(ctx, vendor.ID)
+}
+
+// CheckApprovalSLA publishes a vendor.approval_sla_breached event for every
+// vendor, of any entity, that has been awaiting approval longer than the SLA.
+// Each vendor is announced once per stay in the queue. It returns the number
+// of breaches published; without an SLA it does nothing.
+func (s *VendorService) CheckApprovalSLA(ctx context.Context) (int, error) {
+	if s.approvalSLA <= 0 {
+		return 0, nil
+	}
+	ctx = repository.UsePrimary(ctx)
+
+	createdBefore := time.Now().Add(-s.approvalSLA)
+	published := 0
+	for {
+		var claimed int
+		err := s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+			vendors, err := repo.ClaimApprovalSLABreaches(ctx, createdBefore, approvalSLABatchSize)
+			if err != nil {
+				return err
+			}
+			claimed = len(vendors)
+
+			for _, vendor := range vendors {
+				if err := repo.InsertEvent(ctx, &repository.VendorEvent{
+					EntityID:  vendor.EntityID,
+					VendorID:  vendor.ID,
+					EventType: EventVendorApprovalSLABreached,
+					Payload: map[string]interface{}{
+						"vendor_code":  vendor.VendorCode,
+						"requested_by": vendor.CreatedBy,
+						"requested_at": vendor.CreatedAt,
+						"sla_hours":    s.approvalSLA.Hours(),
+						"age_hours":    time.Since(vendor.CreatedAt).Hours(),
+					},
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return published, err
+		}
+		published += claimed
+		if claimed < approvalSLABatchSize {
+			break
+		}
+	}
+
+	if published > 0 {
+		s.logger(ctx).Warn().
+			Int("breaches", published).
+			Dur("sla", s.approvalSLA).
+			Msg("Vendors awaiting approval beyond SLA")
+	}
+
+	return published, nil
+}
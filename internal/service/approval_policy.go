@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+)
+
+// maxRequiredApprovals matches entity_approval_policies_required_check
+const maxRequiredApprovals = 5
+
+// approvalStates returns the approval progress of pending vendors of the
+// entity of policy, by vendor ID
+func approvalStates(ctx context.Context, repo repository.Store, policy *repository.ApprovalPolicy, vendors []*repository.Vendor) (map[string]*repository.ApprovalState, error) {
+	ids := make([]string, len(vendors))
+	for i, vendor := range vendors {
+		ids[i] = vendor.ID
+	}
+	approvals, err := repo.ListVendorApprovals(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make(map[string]*repository.ApprovalState, len(vendors))
+	for _, vendor := range vendors {
+		state := &repository.ApprovalState{
+			Required:  policy.RequiredFor(vendor),
+			Received:  len(approvals[vendor.ID]),
+			Approvals: approvals[vendor.ID],
+		}
+		if state.Approvals == nil {
+			state.Approvals = make([]*repository.VendorApproval, 0)
+		}
+		state.Remaining = max(state.Required-state.Received, 0)
+		states[vendor.ID] = state
+	}
+	return states, nil
+}
+
+// vendorApprovalState returns the approval progress of a pending vendor
+func (s *VendorService) vendorApprovalState(ctx context.Context, repo repository.Store, vendor *repository.Vendor) (*repository.ApprovalState, error) {
+	policy, err := repo.GetApprovalPolicy(ctx, vendor.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	states, err := approvalStates(ctx, repo, policy, []*repository.Vendor{vendor})
+	if err != nil {
+		return nil, err
+	}
+	return states[vendor.ID], nil
+}
+
+// checkApprovalTransition applies the approval policy to a status change made
+// outside the approval endpoints, on the store of the update's transaction:
+// a vendor needing more than one approval cannot be activated directly, and a
+// vendor returning to pending_approval starts over without approvals.
+func (s *VendorService) checkApprovalTransition(ctx context.Context, repo repository.Store, before, after *repository.Vendor) error {
+	if before.Status == after.Status {
+		return nil
+	}
+
+	if after.Status == "pending_approval" {
+		return repo.DeleteVendorApprovals(ctx, after.ID)
+	}
+
+	if before.Status == "pending_approval" && after.Status == "active" {
+		state, err := s.vendorApprovalState(ctx, repo, before)
+		if err != nil {
+			return err
+		}
+		v := &validator{}
+		v.check(state.Required == 1, "status",
+			fmt.Sprintf("vendor needs %d approvals before it can be activated; use the approve endpoint", state.Required))
+		return v.err()
+	}
+	return nil
+}
+
+// GetApprovalPolicy retrieves the approval policy of an entity
+func (s *VendorService) GetApprovalPolicy(ctx context.Context, entityID string) (*repository.ApprovalPolicy, error) {
+	return s.vendorRepo.GetApprovalPolicy(ctx, entityID)
+}
+
+// SetApprovalPolicy creates or replaces the approval policy of an entity.
+// Vendors already pending are held to the new policy.
+func (s *VendorService) SetApprovalPolicy(ctx context.Context, policy *repository.ApprovalPolicy) error {
+	v := &validator{}
+	v.check(policy.EntityID != "", "entity_id", "entity_id is required")
+	v.check(policy.RequiredApprovals >= 1 && policy.RequiredApprovals <= maxRequiredApprovals,
+		"required_approvals", fmt.Sprintf("required_approvals must be between 1 and %d", maxRequiredApprovals))
+	v.check(policy.CreditLimitThreshold == nil || *policy.CreditLimitThreshold >= 0,
+		"credit_limit_threshold", "credit_limit_threshold cannot be negative")
+	if err := v.err(); err != nil {
+		return err
+	}
+
+	if err := s.vendorRepo.UpsertApprovalPolicy(ctx, policy); err != nil {
+		return err
+	}
+
+	reqlog.SetEntity(ctx, policy.EntityID)
+	s.logger(ctx).Info().Int("required_approvals", policy.RequiredApprovals).Msg("Approval policy updated")
+
+	return nil
+}
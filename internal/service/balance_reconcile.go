@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+// AuditActionBalanceReconciled is audited for every vendor whose
+// current_balance was corrected to the sum of its balance ledger
+const AuditActionBalanceReconciled = "balance_reconciled"
+
+// BalanceReconciliation is the outcome of a balance reconciliation run
+type BalanceReconciliation struct {
+	EntityID string `json:"entity_id,omitempty"`
+	Fix      bool   `json:"fix"`
+	// Discrepancies are the vendors whose current_balance differs from the
+	// sum of their balance ledger
+	Discrepancies []*repository.BalanceDiscrepancy `json:"discrepancies"`
+	// Fixed counts the vendors corrected; vendors balanced again by the time
+	// they are fixed are not counted
+	Fixed int `json:"fixed"`
+}
+
+// ReconcileBalances compares the current_balance of the live vendors of an
+// entity, or of all entities when entityID is empty, to the sum of their
+// balance ledger and returns the discrepancies. With fix, every discrepant
+// vendor is corrected in its own transaction, which re-checks the drift under
+// a row lock, writes a reconciliation ledger entry and audits the correction.
+func (s *VendorService) ReconcileBalances(ctx context.Context, entityID string, fix bool) (*BalanceReconciliation, error) {
+	ctx = repository.WithBulkBudget(repository.UsePrimary(ctx))
+
+	discrepancies, err := s.vendorRepo.FindBalanceDiscrepancies(ctx, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &BalanceReconciliation{EntityID: entityID, Fix: fix, Discrepancies: discrepancies}
+	if fix {
+		for _, discrepancy := range discrepancies {
+			var fixed *repository.BalanceDiscrepancy
+			err := s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+				var err error
+				fixed, err = repo.ReconcileVendorBalance(ctx, discrepancy.VendorID, discrepancy.EntityID)
+				if err != nil || fixed == nil {
+					return err
+				}
+				return repo.InsertAuditEntry(ctx, &repository.AuditEntry{
+					EntityID: fixed.EntityID,
+					VendorID: fixed.VendorID,
+					Action:   AuditActionBalanceReconciled,
+					Details: map[string]interface{}{
+						"vendor_code":      fixed.VendorCode,
+						"previous_balance": fixed.CurrentBalance,
+						"ledger_balance":   fixed.LedgerBalance,
+						"drift":            fixed.Drift,
+					},
+				})
+			})
+			if err != nil {
+				return nil, err
+			}
+			if fixed != nil {
+				report.Fixed++
+			}
+		}
+	}
+
+	event := s.logger(ctx).Info()
+	if len(discrepancies) > 0 {
+		event = s.logger(ctx).Warn()
+	}
+	event.
+		Str("entity_id", entityID).
+		Int("discrepancies", len(discrepancies)).
+		Bool("fix", fix).
+		Int("fixed", report.Fixed).
+		Msg("Vendor balances reconciled")
+
+	return report, nil
+}
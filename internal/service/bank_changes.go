@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// Audit action and event type written when the bank details of a vendor change
+const (
+	AuditActionBankDetailsChanged = "bank_details_changed"
+	EventVendorBankDetailsChanged = "vendor.bank_details_changed"
+)
+
+// SeverityHigh marks audit entries that need prompt human review
+const SeverityHigh = "high"
+
+const (
+	// DefaultBankChangesWindow is how far back bank changes are listed when no
+	// since is requested, covering Treasury's daily review
+	DefaultBankChangesWindow = 24 * time.Hour
+	// DefaultBankChangesLimit is the number of bank changes returned when no limit is requested
+	DefaultBankChangesLimit = 100
+	// MaxBankChangesLimit is the largest number of bank changes returned at once
+	MaxBankChangesLimit = 1000
+)
+
+// bankField is a vendor bank detail compared on updates. Masked fields only
+// ever leave the service as their last four characters.
+type bankField struct {
+	name   string
+	masked bool
+	value  func(v *repository.Vendor) *string
+}
+
+var bankFields = []bankField{
+	{"bank_name", false, func(v *repository.Vendor) *string { return v.BankName }},
+	{"bank_account_number", true, func(v *repository.Vendor) *string { return v.BankAccountNumber }},
+	{"bank_routing_number", true, func(v *repository.Vendor) *string { return v.BankRoutingNumber }},
+	{"swift_code", false, func(v *repository.Vendor) *string { return v.SwiftCode }},
+	{"iban", true, func(v *repository.Vendor) *string { return v.IBAN }},
+}
+
+// lastFour masks all but the last four characters of a bank number
+func lastFour(s string) string {
+	if len(s) <= 4 {
+		return "****"
+	}
+	return "****" + s[len(s)-4:]
+}
+
+// bankDetailsChange compares the bank details of a vendor before and after an
+// update. It returns nil when none changed, and otherwise the changed fields
+// with their masked values before and after.
+func bankDetailsChange(before, after *repository.Vendor) map[string]interface{} {
+	var changed []string
+	previous := map[string]interface{}{}
+	current := map[string]interface{}{}
+
+	for _, field := range bankFields {
+		old, updated := field.value(before), field.value(after)
+		oldValue, newValue := "", ""
+		if old != nil {
+			oldValue = *old
+		}
+		if updated != nil {
+			newValue = *updated
+		}
+		if oldValue == newValue {
+			continue
+		}
+
+		changed = append(changed, field.name)
+		previous[field.name], current[field.name] = nil, nil
+		if oldValue != "" {
+			previous[field.name] = oldValue
+			if field.masked {
+				previous[field.name] = lastFour(oldValue)
+			}
+		}
+		if newValue != "" {
+			current[field.name] = newValue
+			if field.masked {
+				current[field.name] = lastFour(newValue)
+			}
+		}
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+	return map[string]interface{}{
+		"changed_fields": changed,
+		"before":         previous,
+		"after":          current,
+	}
+}
+
+// recordBankDetailsChange writes a high-severity audit entry and publishes a
+// vendor.bank_details_changed event when an update changed the bank details
+// of a vendor. It runs on the store of the update's transaction.
+func (s *VendorService) recordBankDetailsChange(ctx context.Context, repo repository.Store, before, after *repository.Vendor, changedBy *string) error {
+	change := bankDetailsChange(before, after)
+	if change == nil {
+		return nil
+	}
+
+	change["severity"] = SeverityHigh
+	change["vendor_code"] = after.VendorCode
+	change["changed_by"] = changedBy
+
+	if err := repo.InsertAuditEntry(ctx, &repository.AuditEntry{
+		EntityID: after.EntityID,
+		VendorID: after.ID,
+		Action:   AuditActionBankDetailsChanged,
+		ActorID:  changedBy,
+		Details:  change,
+	}); err != nil {
+		return err
+	}
+
+	if err := repo.InsertEvent(ctx, &repository.VendorEvent{
+		EntityID:  after.EntityID,
+		VendorID:  after.ID,
+		EventType: EventVendorBankDetailsChanged,
+		Payload:   change,
+	}); err != nil {
+		return err
+	}
+
+	event := s.logger(ctx).Warn().
+		Str("vendor_code", after.VendorCode).
+		Strs("fields", change["changed_fields"].([]string))
+	if changedBy != nil {
+		event = event.Str("changed_by", *changedBy)
+	}
+	event.Msg("Vendor bank details changed")
+
+	return nil
+}
+
+// ListBankChanges returns the bank detail changes of an entity's vendors made
+// at or after since, newest first. A zero since lists the last 24 hours.
+func (s *VendorService) ListBankChanges(ctx context.Context, entityID string, since time.Time, limit int) ([]*repository.AuditEntry, error) {
+	if entityID == "" {
+		return nil, errors.InvalidInput("entity_id", "entity_id is required")
+	}
+	if since.IsZero() {
+		since = time.Now().Add(-DefaultBankChangesWindow)
+	}
+	if limit <= 0 {
+		limit = DefaultBankChangesLimit
+	}
+	if limit > MaxBankChangesLimit {
+		limit = MaxBankChangesLimit
+	}
+
+	return s.vendorRepo.ListAuditEntries(ctx, entityID, AuditActionBankDetailsChanged, since, limit)
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+)
+
+// Bounds of the vendors a bank last-four lookup returns at once
+const (
+	// DefaultBankLast4Limit is the number of vendors returned when no limit is requested
+	DefaultBankLast4Limit = 20
+	// MaxBankLast4Limit is the largest number of vendors returned at once
+	MaxBankLast4Limit = 100
+)
+
+var bankLast4Pattern = regexp.MustCompile(`^[0-9]{4}$`)
+
+// FindVendorsByBankLast4 finds the vendors of an entity whose bank account
+// number or IBAN ends in the four digits last4, with the last four digits of
+// their account details only. Every lookup is logged with the user who made
+// it, as it reads bank details.
+func (s *VendorService) FindVendorsByBankLast4(ctx context.Context, entityID, last4, userID string, limit int) ([]*repository.BankLast4Match, error) {
+	reqlog.SetEntity(ctx, entityID)
+
+	v := &validator{}
+	v.check(entityID != "", "entity_id", "entity_id is required")
+	v.check(bankLast4Pattern.MatchString(last4), "last4", "last4 must be exactly four digits")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = DefaultBankLast4Limit
+	}
+	if limit > MaxBankLast4Limit {
+		limit = MaxBankLast4Limit
+	}
+
+	matches, err := s.vendorRepo.FindVendorsByBankLast4(ctx, entityID, last4, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger(ctx).Info().
+		Str("user_id", userID).
+		Str("last4", last4).
+		Int("matches", len(matches)).
+		Msg("Vendors looked up by bank account last four digits")
+
+	return matches, nil
+}
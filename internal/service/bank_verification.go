@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/bankverify"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+)
+
+// verifiedPaymentMethods are the payment methods that pay into the vendor's
+// bank account, which must be verified before the first payment
+var verifiedPaymentMethods = []string{"ach", "wire"}
+
+// bankFingerprint identifies the bank account of a vendor by its account and
+// routing numbers and IBAN, or is empty when it has neither an account number
+// nor an IBAN
+func bankFingerprint(vendor *repository.Vendor) string {
+	account := repository.NormalizeIdentifier(deref(vendor.BankAccountNumber))
+	iban := repository.NormalizeIdentifier(deref(vendor.IBAN))
+	if account == "" && iban == "" {
+		return ""
+	}
+	routing := repository.NormalizeIdentifier(deref(vendor.BankRoutingNumber))
+	sum := sha256.Sum256([]byte(account + "\x00" + routing + "\x00" + iban))
+	return hex.EncodeToString(sum[:])
+}
+
+// currentBankVerification returns the stored verification of a vendor when it
+// applies to the vendor's current bank account, or nil
+func currentBankVerification(vendor *repository.Vendor, verification *repository.BankVerification) *repository.BankVerification {
+	if verification == nil || verification.Fingerprint != bankFingerprint(vendor) {
+		return nil
+	}
+	return verification
+}
+
+// attachBankVerifications sets the bank account verifications of vendors.
+// Vendors with a bank account never verified, or changed since, get an
+// unverified one.
+func (s *VendorService) attachBankVerifications(ctx context.Context, vendors ...*repository.Vendor) error {
+	ids := make([]string, len(vendors))
+	for i, vendor := range vendors {
+		ids[i] = vendor.ID
+	}
+	verifications, err := s.vendorRepo.ListBankVerifications(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, vendor := range vendors {
+		vendor.BankVerification = currentBankVerification(vendor, verifications[vendor.ID])
+		if vendor.BankVerification == nil && bankFingerprint(vendor) != "" {
+			vendor.BankVerification = &repository.BankVerification{Status: bankverify.StatusUnverified}
+		}
+	}
+	return nil
+}
+
+// bankVerificationIssue describes why a vendor paid by ACH or wire cannot be
+// paid into its bank account yet, or is empty
+func bankVerificationIssue(vendor *repository.Vendor) string {
+	if vendor.PaymentMethod == nil || !slices.Contains(verifiedPaymentMethods, *vendor.PaymentMethod) {
+		return ""
+	}
+	if vendor.BankVerification != nil && vendor.BankVerification.Status == bankverify.StatusVerified {
+		return ""
+	}
+	return fmt.Sprintf("payment method %s requires a verified bank account; the vendor's bank account is not verified", *vendor.PaymentMethod)
+}
+
+// StartBankVerification starts verifying the current bank account of a vendor
+// with the bank verifier, by micro-deposits unless another method is given.
+// Instant verifications are final at once; micro-deposits stay pending until
+// confirmed.
+func (s *VendorService) StartBankVerification(ctx context.Context, id, entityID, method string) (*repository.BankVerification, error) {
+	reqlog.SetEntity(ctx, entityID)
+	reqlog.SetVendor(ctx, id)
+	ctx = repository.UsePrimary(ctx)
+
+	if method == "" {
+		method = bankverify.MethodMicroDeposits
+	}
+	v := &validator{}
+	v.check(bankverify.IsValidMethod(method), "method", "method must be micro_deposits or instant")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkEntityWritable(ctx, entityID); err != nil {
+		return nil, err
+	}
+	vendor, err := s.vendorRepo.GetByID(ctx, id, entityID)
+	if err != nil {
+		return nil, err
+	}
+	fingerprint := bankFingerprint(vendor)
+	v.check(fingerprint != "", "bank_account_number", "the vendor has no bank account number or IBAN to verify")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	attempt, err := s.bankVerifier.Start(ctx, bankverify.Account{
+		AccountNumber: deref(vendor.BankAccountNumber),
+		RoutingNumber: deref(vendor.BankRoutingNumber),
+		IBAN:          deref(vendor.IBAN),
+		SwiftCode:     deref(vendor.SwiftCode),
+		Country:       vendor.Country,
+	}, method)
+	if err != nil {
+		return nil, fmt.Errorf("start bank verification: %w", err)
+	}
+
+	now := time.Now().UTC()
+	verification := &repository.BankVerification{
+		VendorID:    vendor.ID,
+		EntityID:    vendor.EntityID,
+		Status:      attempt.Status,
+		Method:      attempt.Method,
+		Fingerprint: fingerprint,
+		StartedAt:   &now,
+	}
+	if attempt.Reference != "" {
+		verification.Reference = &attempt.Reference
+	}
+	if attempt.Status == bankverify.StatusVerified {
+		verification.VerifiedAt = &now
+	}
+	if err := s.vendorRepo.UpsertBankVerification(ctx, verification); err != nil {
+		return nil, err
+	}
+
+	s.logger(ctx).Info().
+		Str("method", verification.Method).
+		Str("bank_verification_status", verification.Status).
+		Msg("Bank verification started")
+
+	return verification, nil
+}
+
+// ConfirmBankVerification completes the pending verification of a vendor's
+// current bank account with the micro-deposit amounts, in cents, the vendor
+// received
+func (s *VendorService) ConfirmBankVerification(ctx context.Context, id, entityID string, amounts []int64) (*repository.BankVerification, error) {
+	reqlog.SetEntity(ctx, entityID)
+	reqlog.SetVendor(ctx, id)
+	ctx = repository.UsePrimary(ctx)
+
+	v := &validator{}
+	v.check(len(amounts) > 0, "amounts", "amounts are required")
+	for i, amount := range amounts {
+		v.check(amount > 0, fmt.Sprintf("amounts[%d]", i), "amounts must be positive")
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkEntityWritable(ctx, entityID); err != nil {
+		return nil, err
+	}
+	vendor, err := s.vendorRepo.GetByID(ctx, id, entityID)
+	if err != nil {
+		return nil, err
+	}
+	verifications, err := s.vendorRepo.ListBankVerifications(ctx, []string{vendor.ID})
+	if err != nil {
+		return nil, err
+	}
+	verification := currentBankVerification(vendor, verifications[vendor.ID])
+	pending := verification != nil && verification.Status == bankverify.StatusPending && verification.Reference != nil
+	v.check(pending, "bank_verification", "the vendor's bank account has no pending verification; start one first")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	status, err := s.bankVerifier.Confirm(ctx, *verification.Reference, amounts)
+	if err != nil {
+		return nil, fmt.Errorf("confirm bank verification: %w", err)
+	}
+
+	verification.Status = status
+	if status == bankverify.StatusVerified {
+		now := time.Now().UTC()
+		verification.VerifiedAt = &now
+	}
+	if err := s.vendorRepo.UpsertBankVerification(ctx, verification); err != nil {
+		return nil, err
+	}
+
+	s.logger(ctx).Info().Str("bank_verification_status", status).Msg("Bank verification confirmed")
+
+	return verification, nil
+}
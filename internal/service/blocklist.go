@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+)
+
+// What happens to vendors found on the organization blocklist
+const (
+	BlocklistPolicyBlock = "block"
+	BlocklistPolicyWarn  = "warn"
+)
+
+const (
+	// DefaultBlocklistMatchLimit is the number of blocklist matches returned when no limit is requested
+	DefaultBlocklistMatchLimit = 100
+	// MaxBlocklistMatchLimit is the largest number of blocklist matches returned at once
+	MaxBlocklistMatchLimit = 1000
+)
+
+// IsValidBlocklistPolicy reports whether policy is a known blocklist policy
+func IsValidBlocklistPolicy(policy string) bool {
+	return policy == BlocklistPolicyBlock || policy == BlocklistPolicyWarn
+}
+
+// AddBlocklistEntryRequest blocks a tax ID, and optionally a bank account
+// number or IBAN. With VendorID and EntityID, the tax ID and bank account
+// default to those of the vendor.
+type AddBlocklistEntryRequest struct {
+	TaxID       string  `json:"tax_id,omitempty"`
+	BankAccount string  `json:"bank_account,omitempty"`
+	Reason      string  `json:"reason"`
+	EntityID    string  `json:"entity_id,omitempty"`
+	VendorID    string  `json:"vendor_id,omitempty"`
+	CreatedBy   *string `json:"-"`
+}
+
+// AddBlocklistEntry adds a tax ID to the blocklist. Only the hash of the bank
+// account is stored.
+func (s *VendorService) AddBlocklistEntry(ctx context.Context, req *AddBlocklistEntryRequest) (*repository.BlocklistEntry, error) {
+	ctx = repository.UsePrimary(ctx)
+	v := &validator{}
+	v.check(req.VendorID == "" || req.EntityID != "", "entity_id", "entity_id is required with vendor_id")
+	v.check(req.Reason != "", "reason", "reason is required")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	entry := &repository.BlocklistEntry{
+		TaxID:     repository.NormalizeIdentifier(req.TaxID),
+		Reason:    req.Reason,
+		CreatedBy: req.CreatedBy,
+	}
+	bankAccount := req.BankAccount
+	if req.VendorID != "" {
+		vendor, err := s.vendorRepo.GetByID(ctx, req.VendorID, req.EntityID)
+		if err != nil {
+			return nil, err
+		}
+		if entry.TaxID == "" && vendor.TaxID != nil {
+			entry.TaxID = repository.NormalizeIdentifier(*vendor.TaxID)
+		}
+		if bankAccount == "" {
+			bankAccount = firstSet(vendor.BankAccountNumber, vendor.IBAN)
+		}
+		entry.EntityID, entry.VendorID = &req.EntityID, &req.VendorID
+	} else if req.EntityID != "" {
+		entry.EntityID = &req.EntityID
+	}
+	if repository.NormalizeIdentifier(bankAccount) != "" {
+		hash := repository.HashBankAccount(bankAccount)
+		entry.BankAccountHash = &hash
+	}
+
+	v.check(entry.TaxID != "", "tax_id", "tax_id is required and must contain letters or digits")
+	checkLength(v, "tax_id", entry.TaxID, 50)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.vendorRepo.InsertBlocklistEntry(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	s.logger(ctx).Info().Str("blocklist_entry_id", entry.ID).Msg("Blocklist entry added")
+	return entry, nil
+}
+
+// ListBlocklistEntries retrieves the blocklist, newest first
+func (s *VendorService) ListBlocklistEntries(ctx context.Context) ([]*repository.BlocklistEntry, error) {
+	return s.vendorRepo.ListBlocklistEntries(ctx)
+}
+
+// RemoveBlocklistEntry removes an entry from the blocklist. Its recorded
+// matches are kept.
+func (s *VendorService) RemoveBlocklistEntry(ctx context.Context, id string) error {
+	entry, err := s.vendorRepo.DeleteBlocklistEntry(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	s.logger(ctx).Info().Str("blocklist_entry_id", entry.ID).Msg("Blocklist entry removed")
+	return nil
+}
+
+// ListBlocklistMatches retrieves the most recent blocklist matches, of one
+// entity when entityID is set
+func (s *VendorService) ListBlocklistMatches(ctx context.Context, entityID string, limit int) ([]*repository.BlocklistMatch, error) {
+	if limit <= 0 {
+		limit = DefaultBlocklistMatchLimit
+	}
+	return s.vendorRepo.ListBlocklistMatches(ctx, entityID, min(limit, MaxBlocklistMatchLimit))
+}
+
+// checkBlocklist looks up the tax ID and bank accounts of a vendor on the
+// blocklist and records every match. With the block policy a match is
+// returned as a validation error, with the warn policy as a warning. Messages
+// give the reason of the entry but never the entity that blocked it.
+func (s *VendorService) checkBlocklist(ctx context.Context, vendor *repository.Vendor, stage string, actorID *string) ([]*repository.ValidationWarning, error) {
+	var taxID string
+	if vendor.TaxID != nil {
+		taxID = repository.NormalizeIdentifier(*vendor.TaxID)
+	}
+	var hashes []string
+	for _, account := range []*string{vendor.BankAccountNumber, vendor.IBAN} {
+		if account != nil && repository.NormalizeIdentifier(*account) != "" {
+			hashes = append(hashes, repository.HashBankAccount(*account))
+		}
+	}
+	if taxID == "" && len(hashes) == 0 {
+		return nil, nil
+	}
+
+	ctx = repository.UsePrimary(ctx)
+	entries, err := s.vendorRepo.FindBlocklistEntries(ctx, taxID, hashes)
+	if err != nil || len(entries) == 0 {
+		return nil, err
+	}
+
+	outcome := repository.BlocklistOutcomeWarned
+	if s.blocklistPolicy == BlocklistPolicyBlock {
+		outcome = repository.BlocklistOutcomeBlocked
+	}
+	var vendorID *string
+	if vendor.ID != "" {
+		vendorID = &vendor.ID
+	}
+
+	v := &validator{}
+	var warnings []*repository.ValidationWarning
+	for _, entry := range entries {
+		// Matches are recorded outside of any transaction of the caller, so
+		// that rejected creates and approvals are audited too
+		if err := s.vendorRepo.InsertBlocklistMatch(ctx, &repository.BlocklistMatch{
+			EntryID:    entry.ID,
+			EntityID:   vendor.EntityID,
+			VendorID:   vendorID,
+			VendorCode: vendor.VendorCode,
+			Stage:      stage,
+			Outcome:    outcome,
+			ActorID:    actorID,
+		}); err != nil {
+			return nil, err
+		}
+
+		field, message := "tax_id", "tax ID is on the organization blocklist: "+entry.Reason
+		if taxID == "" || entry.TaxID != taxID {
+			field, message = "bank_account_number", "bank account is on the organization blocklist: "+entry.Reason
+		}
+		if outcome == repository.BlocklistOutcomeBlocked {
+			v.add(field, message)
+		} else {
+			warnings = append(warnings, &repository.ValidationWarning{Code: WarningBlocklisted, Field: field, Message: message})
+		}
+	}
+
+	reqlog.SetEntity(ctx, vendor.EntityID)
+	s.logger(ctx).Warn().
+		Str("vendor_code", vendor.VendorCode).
+		Str("stage", stage).
+		Str("outcome", outcome).
+		Int("matches", len(entries)).
+		Msg("Vendor found on the blocklist")
+	return warnings, v.err()
+}
+
+// firstSet returns the first of values that is set, or ""
+func firstSet(values ...*string) string {
+	for _, value := range values {
+		if isSet(value) {
+			return *value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+)
+
+// Bounds of a bulk delete
+const (
+	maxBulkDelete = 1000
+	// bulkDeleteChunk is how many vendors are deleted per transaction
+	bulkDeleteChunk = 100
+)
+
+// Outcomes of a vendor in a bulk delete
+const (
+	BulkDeleteEligible = "eligible"
+	BulkDeleteDeleted  = "deleted"
+	BulkDeleteSkipped  = "skipped"
+	BulkDeleteFailed   = "failed"
+)
+
+// BulkDeleteFilter selects the vendors of a bulk delete instead of a list of
+// IDs, as the list filters of the same names do
+type BulkDeleteFilter struct {
+	Status     *string `json:"status,omitempty"`
+	VendorType *string `json:"vendor_type,omitempty"`
+	Tag        string  `json:"tag,omitempty"`
+	Name       string  `json:"name,omitempty"`
+}
+
+// BulkDeleteRequest soft-deletes up to 1000 vendors given by IDs or a filter.
+// A dry run reports what would happen and returns the confirmation token the
+// real run of the same request must carry.
+type BulkDeleteRequest struct {
+	EntityID          string            `json:"entity_id"`
+	IDs               []string          `json:"ids,omitempty"`
+	Filter            *BulkDeleteFilter `json:"filter,omitempty"`
+	DryRun            bool              `json:"dry_run"`
+	ConfirmationToken string            `json:"confirmation_token,omitempty"`
+}
+
+// BulkDeleteResult is the outcome of one vendor of a bulk delete
+type BulkDeleteResult struct {
+	ID         string `json:"id"`
+	VendorCode string `json:"vendor_code,omitempty"`
+	Status     string `json:"status"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// BulkDeleteReport is the outcome of a bulk delete. ConfirmationToken and
+// TokenExpiresAt are only set by dry runs.
+type BulkDeleteReport struct {
+	DryRun            bool                `json:"dry_run"`
+	Results           []*BulkDeleteResult `json:"results"`
+	Eligible          int                 `json:"eligible"`
+	Deleted           int                 `json:"deleted"`
+	Skipped           int                 `json:"skipped"`
+	Failed            int                 `json:"failed"`
+	ConfirmationToken string              `json:"confirmation_token,omitempty"`
+	TokenExpiresAt    *time.Time          `json:"token_expires_at,omitempty"`
+}
+
+// BulkDeleteVendors soft-deletes the vendors of req that pass the delete
+// checks, in transactions of 100 vendors, and releases their external system
+// mappings like DeleteVendor. Vendors failing a check are skipped with the
+// reason. The real run must carry the confirmation token of a dry run of the
+// same request; tokens are single-use.
+func (s *VendorService) BulkDeleteVendors(ctx context.Context, req *BulkDeleteRequest) (*BulkDeleteReport, error) {
+	v := &validator{}
+	v.check(req.EntityID != "", "entity_id", "entity_id is required")
+	v.check(len(req.IDs) > 0 || req.Filter != nil, "ids", "ids or filter is required")
+	v.check(len(req.IDs) == 0 || req.Filter == nil, "filter", "filter cannot be combined with ids")
+	v.check(len(req.IDs) <= maxBulkDelete, "ids", fmt.Sprintf("at most %d ids can be deleted at once", maxBulkDelete))
+	v.check(req.DryRun || req.ConfirmationToken != "", "confirmation_token",
+		"confirmation_token is required; run the request with dry_run first")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	reqlog.SetEntity(ctx, req.EntityID)
+	ctx = repository.WithBulkBudget(repository.UsePrimary(ctx))
+	fingerprint := bulkDeleteFingerprint(req)
+	if !req.DryRun {
+		if err := s.takeBulkDeleteToken(ctx, req.ConfirmationToken, fingerprint); err != nil {
+			return nil, err
+		}
+	}
+
+	ids, err := s.bulkDeleteIDs(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &BulkDeleteReport{DryRun: req.DryRun, Results: make([]*BulkDeleteResult, 0, len(ids))}
+	for start := 0; start < len(ids); start += bulkDeleteChunk {
+		chunk := ids[start:min(start+bulkDeleteChunk, len(ids))]
+		results := make([]*BulkDeleteResult, 0, len(chunk))
+		err := s.withTx(ctx, req.DryRun, func(repo repository.Store) error {
+			results = results[:0]
+			for _, id := range chunk {
+				result, err := s.bulkDeleteVendor(ctx, repo, id, req.EntityID, req.DryRun)
+				if err != nil {
+					return err
+				}
+				results = append(results, result)
+			}
+			return nil
+		})
+		if err != nil {
+			s.logger(ctx).Error().Err(err).Int("vendors", len(chunk)).Msg("Bulk delete chunk failed")
+			results = results[:0]
+			for _, id := range chunk {
+				results = append(results, &BulkDeleteResult{ID: id, Status: BulkDeleteFailed, Reason: err.Error()})
+			}
+		}
+		report.Results = append(report.Results, results...)
+	}
+
+	for _, result := range report.Results {
+		switch result.Status {
+		case BulkDeleteEligible:
+			report.Eligible++
+		case BulkDeleteDeleted:
+			report.Deleted++
+		case BulkDeleteSkipped:
+			report.Skipped++
+		case BulkDeleteFailed:
+			report.Failed++
+		}
+	}
+
+	if req.DryRun {
+		token, expiresAt, err := s.issueBulkDeleteToken(ctx, fingerprint)
+		if err != nil {
+			return nil, err
+		}
+		report.ConfirmationToken = token
+		report.TokenExpiresAt = &expiresAt
+	}
+
+	s.logger(ctx).Info().
+		Bool("dry_run", req.DryRun).
+		Int("eligible", report.Eligible).
+		Int("deleted", report.Deleted).
+		Int("skipped", report.Skipped).
+		Int("failed", report.Failed).
+		Msg("Vendors bulk deleted")
+
+	return report, nil
+}
+
+// bulkDeleteIDs returns the distinct IDs of req, or the IDs of the vendors its
+// filter matches, failing when the filter matches more than can be deleted
+func (s *VendorService) bulkDeleteIDs(ctx context.Context, req *BulkDeleteRequest) ([]string, error) {
+	if req.Filter == nil {
+		ids := make([]string, 0, len(req.IDs))
+		for _, id := range req.IDs {
+			if id = strings.TrimSpace(id); id != "" && !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+		return ids, nil
+	}
+
+	vendors, total, err := s.vendorRepo.List(ctx, repository.VendorFilter{
+		EntityID:   req.EntityID,
+		Status:     req.Filter.Status,
+		VendorType: req.Filter.VendorType,
+		Tag:        NormalizeTag(req.Filter.Tag),
+		Name:       strings.TrimSpace(req.Filter.Name),
+	}, maxBulkDelete, 0)
+	if err != nil {
+		return nil, err
+	}
+	if total > maxBulkDelete {
+		v := &validator{}
+		v.add("filter", fmt.Sprintf("filter matches %d vendors; at most %d can be deleted at once", total, maxBulkDelete))
+		return nil, v.err()
+	}
+
+	ids := make([]string, len(vendors))
+	for i, vendor := range vendors {
+		ids[i] = vendor.ID
+	}
+	return ids, nil
+}
+
+// bulkDeleteVendor deletes one vendor of a bulk delete in the transaction of
+// repo, or reports why it is skipped
+func (s *VendorService) bulkDeleteVendor(ctx context.Context, repo repository.Store, id, entityID string, dryRun bool) (*BulkDeleteResult, error) {
+	vendor, err := repo.GetByID(ctx, id, entityID)
+	if isNotFound(err) {
+		return &BulkDeleteResult{ID: id, Status: BulkDeleteSkipped, Reason: "vendor not found"}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BulkDeleteResult{ID: id, VendorCode: vendor.VendorCode}
+	// TODO: Check if vendor has open invoices (when invoice service is implemented)
+	if vendor.CurrentBalance != 0 {
+		result.Status = BulkDeleteSkipped
+		result.Reason = fmt.Sprintf("vendor has an open balance of %d", vendor.CurrentBalance)
+		return result, nil
+	}
+
+	if err := repo.Delete(ctx, id, entityID); err != nil {
+		return nil, err
+	}
+	if err := repo.DeleteAllExternalRefs(ctx, id, entityID); err != nil {
+		return nil, err
+	}
+
+	result.Status = BulkDeleteDeleted
+	if dryRun {
+		result.Status = BulkDeleteEligible
+	}
+	return result, nil
+}
+
+// bulkDeleteFingerprint identifies the vendors a bulk delete request targets,
+// so that a confirmation token only confirms the request it was issued for
+func bulkDeleteFingerprint(req *BulkDeleteRequest) string {
+	ids := slices.Clone(req.IDs)
+	for i := range ids {
+		ids[i] = strings.TrimSpace(ids[i])
+	}
+	slices.Sort(ids)
+	filter, _ := json.Marshal(req.Filter)
+
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		req.EntityID,
+		strings.Join(slices.Compact(ids), ","),
+		string(filter),
+	}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// bulkDeleteTokenKey is the key of a confirmation token in the key store
+func bulkDeleteTokenKey(token string) string {
+	return "vendors:bulk-delete:" + token
+}
+
+// issueBulkDeleteToken returns a new confirmation token for the request with
+// fingerprint and when it expires
+func (s *VendorService) issueBulkDeleteToken(ctx context.Context, fingerprint string) (string, time.Time, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", time.Time{}, fmt.Errorf("generate confirmation token: %w", err)
+	}
+	token := hex.EncodeToString(b[:])
+
+	expiresAt := time.Now().UTC().Add(s.bulkDeleteTokenTTL)
+	if err := s.bulkDeleteTokens.Set(ctx, bulkDeleteTokenKey(token), fingerprint, s.bulkDeleteTokenTTL); err != nil {
+		return "", time.Time{}, fmt.Errorf("store confirmation token: %w", err)
+	}
+	return token, expiresAt, nil
+}
+
+// takeBulkDeleteToken consumes a confirmation token, failing unless it was
+// issued for the request with fingerprint and has not expired or been used
+func (s *VendorService) takeBulkDeleteToken(ctx context.Context, token, fingerprint string) error {
+	issuedFor, ok, err := s.bulkDeleteTokens.Take(ctx, bulkDeleteTokenKey(token))
+	if err != nil {
+		return fmt.Errorf("check confirmation token: %w", err)
+	}
+
+	v := &validator{}
+	v.check(ok, "confirmation_token", "confirmation_token is unknown, expired or already used; run the request with dry_run again")
+	v.check(!ok || issuedFor == fingerprint, "confirmation_token", "confirmation_token was issued for a different request")
+	return v.err()
+}
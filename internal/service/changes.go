@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+const (
+	// DefaultChangesLimit is the page size used when no limit is requested
+	DefaultChangesLimit = 100
+	// MaxChangesLimit is the largest page of changes returned at once
+	MaxChangesLimit = 1000
+)
+
+// VendorChange is a single entry of the vendor change feed. Deleted vendors are
+// returned as tombstones carrying only their identifiers.
+type VendorChange struct {
+	ChangeSeq  int64              `json:"change_seq"`
+	VendorID   string             `json:"vendor_id"`
+	VendorCode string             `json:"vendor_code"`
+	Deleted    bool               `json:"deleted"`
+	Vendor     *repository.Vendor `json:"vendor,omitempty"`
+}
+
+// VendorChanges is a page of the vendor change feed
+type VendorChanges struct {
+	Changes []*VendorChange `json:"changes"`
+	// Watermark is the change_seq to pass as "since" on the next call. It equals
+	// the requested watermark when there are no new changes.
+	Watermark int64 `json:"watermark"`
+	HasMore   bool  `json:"has_more"`
+}
+
+// ListVendorChanges returns vendors of an entity changed after the given
+// watermark, ordered by change_seq. Each vendor appears once with its latest
+// state, so replaying from the same watermark is idempotent.
+func (s *VendorService) ListVendorChanges(ctx context.Context, entityID string, since int64, limit int) (*VendorChanges, error) {
+	if since < 0 {
+		return nil, errors.InvalidInput("since", "since must be a non-negative watermark")
+	}
+	if limit <= 0 {
+		limit = DefaultChangesLimit
+	}
+	if limit > MaxChangesLimit {
+		limit = MaxChangesLimit
+	}
+
+	// Fetch one extra row to detect whether more changes are pending
+	vendors, err := s.vendorRepo.ListChangedSince(ctx, entityID, since, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &VendorChanges{
+		Changes:   make([]*VendorChange, 0, len(vendors)),
+		Watermark: since,
+	}
+	if len(vendors) > limit {
+		vendors = vendors[:limit]
+		result.HasMore = true
+	}
+
+	for _, vendor := range vendors {
+		change := &VendorChange{
+			ChangeSeq:  vendor.ChangeSeq,
+			VendorID:   vendor.ID,
+			VendorCode: vendor.VendorCode,
+			Deleted:    vendor.DeletedAt != nil,
+		}
+		if !change.Deleted {
+			change.Vendor = vendor
+		}
+		result.Changes = append(result.Changes, change)
+		result.Watermark = vendor.ChangeSeq
+	}
+
+	return result, nil
+}
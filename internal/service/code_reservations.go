@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// DefaultVendorCodePrefix starts reserved vendor codes when no prefix is
+// requested
+const DefaultVendorCodePrefix = "V"
+
+// maxVendorCodePrefixLength leaves room for the number of a reserved code
+const maxVendorCodePrefixLength = 10
+
+// vendorCodeDigits is the least number of digits of a reserved code; V00042
+const vendorCodeDigits = 5
+
+// reserveAttempts bounds how often a reservation retries after a concurrent
+// reservation took the code it picked
+const reserveAttempts = 5
+
+var vendorCodePrefixPattern = regexp.MustCompile(`^[A-Z0-9-]*$`)
+
+// ReserveVendorCode allocates the next free vendor code of an entity starting
+// with requestedPrefix and holds it for the reservation TTL. Creating a vendor
+// with the code consumes the reservation; only reservedBy may do so when it is
+// set. Expired reservations are released by the worker.
+func (s *VendorService) ReserveVendorCode(ctx context.Context, entityID, requestedPrefix, reservedBy string) (*repository.VendorCodeReservation, error) {
+	reqlog.SetEntity(ctx, entityID)
+	ctx = repository.UsePrimary(ctx)
+
+	prefix := strings.ToUpper(strings.TrimSpace(requestedPrefix))
+	if prefix == "" {
+		prefix = DefaultVendorCodePrefix
+	}
+
+	v := &validator{}
+	v.check(entityID != "", "entity_id", "entity_id is required")
+	v.check(len(prefix) <= maxVendorCodePrefixLength, "prefix",
+		fmt.Sprintf("prefix must be at most %d characters", maxVendorCodePrefixLength))
+	v.check(vendorCodePrefixPattern.MatchString(prefix), "prefix", "prefix may only contain letters, digits and dashes")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkEntityWritable(ctx, entityID); err != nil {
+		return nil, err
+	}
+
+	var by *string
+	if reservedBy != "" {
+		by = &reservedBy
+	}
+
+	for attempt := 0; ; attempt++ {
+		number, err := s.vendorRepo.MaxVendorCodeNumber(ctx, entityID, prefix)
+		if err != nil {
+			return nil, err
+		}
+
+		res := &repository.VendorCodeReservation{
+			EntityID:   entityID,
+			VendorCode: fmt.Sprintf("%s%0*d", prefix, vendorCodeDigits, number+1),
+			ReservedBy: by,
+			ExpiresAt:  time.Now().UTC().Add(s.codeReservationTTL),
+		}
+		err = s.vendorRepo.InsertVendorCodeReservation(ctx, res)
+		if isAlreadyExists(err) && attempt < reserveAttempts-1 {
+			// Another reservation took the code in the meantime
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		s.logger(ctx).Info().
+			Str("vendor_code", res.VendorCode).
+			Time("expires_at", res.ExpiresAt).
+			Msg("Vendor code reserved")
+		return res, nil
+	}
+}
+
+// claimReservedCode consumes the live reservation of a vendor code about to be
+// used by a vendor. A code reserved by someone else than userID fails with
+// AlreadyExists; reservations without a user can be consumed by anyone.
+func claimReservedCode(ctx context.Context, repo repository.Store, entityID, code string, userID *string) error {
+	res, err := repo.GetVendorCodeReservation(ctx, entityID, code)
+	if err != nil || res == nil {
+		return err
+	}
+	if res.ReservedBy != nil && (userID == nil || *userID != *res.ReservedBy) {
+		return errors.AlreadyExists("vendor_code_reservation", code)
+	}
+	return repo.DeleteVendorCodeReservation(ctx, res.ID)
+}
+
+// ReleaseExpiredCodeReservations releases the vendor code reservations whose
+// TTL passed without a vendor being created, returning how many it released
+func (s *VendorService) ReleaseExpiredCodeReservations(ctx context.Context) (int, error) {
+	released, err := s.vendorRepo.DeleteExpiredVendorCodeReservations(repository.UsePrimary(ctx))
+	if err != nil {
+		return 0, err
+	}
+	if released > 0 {
+		s.logger(ctx).Info().Int("released", released).Msg("Expired vendor code reservations released")
+	}
+	return released, nil
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+)
+
+// Communication types sent to the contacts of a vendor
+const (
+	CommunicationPurchaseOrders = "purchase_orders"
+	CommunicationRemittance     = "remittance"
+	CommunicationStatements     = "statements"
+)
+
+// CommunicationTypes are the communication types, in report order
+var CommunicationTypes = []string{CommunicationPurchaseOrders, CommunicationRemittance, CommunicationStatements}
+
+// maxCommunicationRecipients is the most contacts of a vendor receiving one
+// communication type
+const maxCommunicationRecipients = 5
+
+// Audit actions written for contact changes
+const (
+	AuditActionContactCommunications = "contact_communications_changed"
+	AuditActionContactRemoved        = "contact_removed"
+)
+
+// IsValidCommunicationType reports whether t is a communication type
+func IsValidCommunicationType(t string) bool {
+	return slices.Contains(CommunicationTypes, t)
+}
+
+// ParseCommunicationTypes validates the communication types every vendor
+// with contacts must keep a recipient of
+func ParseCommunicationTypes(names []string) ([]string, error) {
+	for _, name := range names {
+		if !IsValidCommunicationType(name) {
+			return nil, fmt.Errorf("unknown communication type %q", name)
+		}
+	}
+	return names, nil
+}
+
+// ReceivesCommunication reports whether a contact receives a communication type
+func ReceivesCommunication(contact *repository.VendorContact, commType string) bool {
+	switch commType {
+	case CommunicationPurchaseOrders:
+		return contact.ReceivesPurchaseOrders
+	case CommunicationRemittance:
+		return contact.ReceivesRemittance
+	case CommunicationStatements:
+		return contact.ReceivesStatements
+	}
+	return false
+}
+
+// communicationField is the contact field holding whether a contact
+// receives a communication type
+func communicationField(commType string) string {
+	return "receives_" + commType
+}
+
+// LastRecipientError is returned when a contact change or delete would leave
+// a vendor without a recipient of a required communication type
+type LastRecipientError struct {
+	VendorID          string
+	ContactID         string
+	CommunicationType string
+}
+
+func (e *LastRecipientError) Error() string {
+	label := communicationLabel(e.CommunicationType)
+	return fmt.Sprintf("contact %s is the last contact of vendor %s receiving %s, which is required; have another contact receive %s first",
+		e.ContactID, e.VendorID, label, label)
+}
+
+// communicationLabel is a communication type as written in messages
+func communicationLabel(commType string) string {
+	return strings.ReplaceAll(commType, "_", " ")
+}
+
+// checkContactRecipients allows at most maxCommunicationRecipients of the
+// contacts created with a vendor to receive each communication type
+func checkContactRecipients(v *validator, contacts []*AddContactRequest) {
+	recipients := make(map[string]int, len(CommunicationTypes))
+	for i, req := range contacts {
+		if req == nil {
+			continue
+		}
+		contact := &repository.VendorContact{
+			ReceivesPurchaseOrders: req.ReceivesPurchaseOrders,
+			ReceivesRemittance:     req.ReceivesRemittance,
+			ReceivesStatements:     req.ReceivesStatements,
+		}
+		for _, commType := range CommunicationTypes {
+			if !ReceivesCommunication(contact, commType) {
+				continue
+			}
+			if recipients[commType]++; recipients[commType] > maxCommunicationRecipients {
+				v.add(fmt.Sprintf("contacts[%d].%s", i, communicationField(commType)),
+					fmt.Sprintf("at most %d contacts can receive %s", maxCommunicationRecipients, communicationLabel(commType)))
+			}
+		}
+	}
+}
+
+// checkCommunicationChange checks the contacts of a vendor after a contact was
+// added, updated or deleted, in the transaction making the change and holding
+// LockVendorContacts. A communication type the contact took on may have at
+// most maxCommunicationRecipients recipients, and a required type it gave up
+// must keep one. before is the contact before the change, nil when added, and
+// after is nil when deleted.
+func (s *VendorService) checkCommunicationChange(ctx context.Context, repo repository.Store, before, after *repository.VendorContact) error {
+	contact := after
+	if contact == nil {
+		contact = before
+	}
+	contacts, err := repo.GetContacts(ctx, contact.VendorID)
+	if err != nil {
+		return err
+	}
+
+	v := &validator{}
+	for _, commType := range CommunicationTypes {
+		had := before != nil && ReceivesCommunication(before, commType)
+		has := after != nil && ReceivesCommunication(after, commType)
+		if had == has {
+			continue
+		}
+
+		recipients := 0
+		for _, c := range contacts {
+			if ReceivesCommunication(c, commType) {
+				recipients++
+			}
+		}
+		if has {
+			v.check(recipients <= maxCommunicationRecipients, communicationField(commType),
+				fmt.Sprintf("at most %d contacts of a vendor can receive %s", maxCommunicationRecipients, communicationLabel(commType)))
+		} else if recipients == 0 && slices.Contains(s.requiredCommunications, commType) {
+			return &LastRecipientError{VendorID: contact.VendorID, ContactID: contact.ID, CommunicationType: commType}
+		}
+	}
+	return v.err()
+}
+
+// ContactCommunicationsRequest turns communication types on or off for a
+// contact; nil fields are left as they are
+type ContactCommunicationsRequest struct {
+	VendorID               string  `json:"-"`
+	EntityID               string  `json:"-"`
+	ContactID              string  `json:"-"`
+	ReceivesPurchaseOrders *bool   `json:"receives_purchase_orders,omitempty"`
+	ReceivesRemittance     *bool   `json:"receives_remittance,omitempty"`
+	ReceivesStatements     *bool   `json:"receives_statements,omitempty"`
+	UpdatedBy              *string `json:"-"`
+}
+
+// SetContactCommunications turns the communication types of a contact on or
+// off and audits the change. Turning a type on past the recipient limit fails
+// validation; turning off the last recipient of a required type fails with a
+// LastRecipientError.
+func (s *VendorService) SetContactCommunications(ctx context.Context, req *ContactCommunicationsRequest) (*repository.VendorContact, error) {
+	v := &validator{}
+	v.check(req.EntityID != "", "entity_id", "entity_id is required")
+	v.check(req.ReceivesPurchaseOrders != nil || req.ReceivesRemittance != nil || req.ReceivesStatements != nil, "receives_purchase_orders",
+		"set at least one of receives_purchase_orders, receives_remittance and receives_statements")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	if err := s.checkEntityWritable(ctx, req.EntityID); err != nil {
+		return nil, err
+	}
+
+	var contact *repository.VendorContact
+	err := s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		if _, err := repo.GetByID(ctx, req.VendorID, req.EntityID); err != nil {
+			return err
+		}
+		if err := repo.LockVendorContacts(ctx, req.VendorID); err != nil {
+			return err
+		}
+		before, err := repo.GetContact(ctx, req.VendorID, req.ContactID)
+		if err != nil {
+			return err
+		}
+
+		updated := *before
+		contact = &updated
+		if req.ReceivesPurchaseOrders != nil {
+			contact.ReceivesPurchaseOrders = *req.ReceivesPurchaseOrders
+		}
+		if req.ReceivesRemittance != nil {
+			contact.ReceivesRemittance = *req.ReceivesRemittance
+		}
+		if req.ReceivesStatements != nil {
+			contact.ReceivesStatements = *req.ReceivesStatements
+		}
+
+		changes := make(map[string]interface{})
+		for _, commType := range CommunicationTypes {
+			if has := ReceivesCommunication(contact, commType); has != ReceivesCommunication(before, commType) {
+				changes[communicationField(commType)] = has
+			}
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+
+		if err := repo.UpdateContact(ctx, contact); err != nil {
+			return err
+		}
+		if err := s.checkCommunicationChange(ctx, repo, before, contact); err != nil {
+			return err
+		}
+		changes["contact_id"] = contact.ID
+		return repo.InsertAuditEntry(ctx, &repository.AuditEntry{
+			EntityID: req.EntityID,
+			VendorID: req.VendorID,
+			Action:   AuditActionContactCommunications,
+			ActorID:  req.UpdatedBy,
+			Details:  changes,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reqlog.SetVendor(ctx, req.VendorID)
+	s.logger(ctx).Info().Str("contact_id", contact.ID).Msg("Vendor contact communications updated")
+	return contact, nil
+}
+
+// DeleteVendorContact removes a contact of a vendor and audits it. Removing
+// the last recipient of a required communication type fails with a
+// LastRecipientError.
+func (s *VendorService) DeleteVendorContact(ctx context.Context, vendorID, contactID, entityID string, removedBy *string) error {
+	if err := s.checkEntityWritable(ctx, entityID); err != nil {
+		return err
+	}
+
+	err := s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		if _, err := repo.GetByID(ctx, vendorID, entityID); err != nil {
+			return err
+		}
+		if err := repo.LockVendorContacts(ctx, vendorID); err != nil {
+			return err
+		}
+		contact, err := repo.GetContact(ctx, vendorID, contactID)
+		if err != nil {
+			return err
+		}
+		if err := repo.DeleteContact(ctx, vendorID, contactID); err != nil {
+			return err
+		}
+		if err := s.checkCommunicationChange(ctx, repo, contact, nil); err != nil {
+			return err
+		}
+		return repo.InsertAuditEntry(ctx, &repository.AuditEntry{
+			EntityID: entityID,
+			VendorID: vendorID,
+			Action:   AuditActionContactRemoved,
+			ActorID:  removedBy,
+			Details: map[string]interface{}{
+				"contact_id": contact.ID,
+				"first_name": contact.FirstName,
+				"last_name":  contact.LastName,
+			},
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	reqlog.SetVendor(ctx, vendorID)
+	s.logger(ctx).Info().Str("contact_id", contactID).Msg("Vendor contact removed")
+	return nil
+}
+
+// GetContactsForCommunication resolves the contacts of a vendor receiving a
+// communication type, primary contacts first. Other services call it to
+// address purchase orders, remittance advices and statements.
+func (s *VendorService) GetContactsForCommunication(ctx context.Context, vendorID, entityID, commType string) ([]*repository.VendorContact, error) {
+	v := &validator{}
+	v.check(entityID != "", "entity_id", "entity_id is required")
+	v.check(IsValidCommunicationType(commType), "type",
+		fmt.Sprintf("type must be one of %s, got %q", strings.Join(CommunicationTypes, ", "), commType))
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.vendorRepo.GetByID(ctx, vendorID, entityID); err != nil {
+		return nil, err
+	}
+	contacts, err := s.vendorRepo.GetContacts(ctx, vendorID)
+	if err != nil {
+		return nil, err
+	}
+
+	reqlog.SetVendor(ctx, vendorID)
+	return slices.DeleteFunc(contacts, func(c *repository.VendorContact) bool {
+		return !ReceivesCommunication(c, commType)
+	}), nil
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// DuplicateContactError is returned when a contact is added to a vendor that
+// already has a contact with the same email, ignoring case
+type DuplicateContactError struct {
+	VendorID  string
+	ContactID string
+	Email     string
+}
+
+func (e *DuplicateContactError) Error() string {
+	return fmt.Sprintf("vendor %s already has contact %s with email %s", e.VendorID, e.ContactID, e.Email)
+}
+
+// ListDuplicateContactEmails reports the emails used by several contacts of
+// the same vendor across an entity, so contacts added before duplicates were
+// rejected can be cleaned up
+func (s *VendorService) ListDuplicateContactEmails(ctx context.Context, entityID string) ([]*repository.DuplicateContactEmail, error) {
+	if entityID == "" {
+		return nil, errors.InvalidInput("entity_id", "entity_id is required")
+	}
+	return s.vendorRepo.ListDuplicateContactEmails(ctx, entityID)
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/csvimport"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// MaxImportRows is the largest number of rows accepted by one import
+const MaxImportRows = 10000
+
+// ContactCSVColumns are the columns of contact exports and imports, in export order
+var ContactCSVColumns = []string{
+	"vendor_code", "contact_type", "first_name", "last_name", "title",
+	"email", "phone", "mobile", "is_primary", "notes",
+}
+
+// contactRequiredColumns must be present in the header of a contact import
+var contactRequiredColumns = []string{"vendor_code", "contact_type", "first_name", "last_name"}
+
+// phonePattern accepts digits with the usual separators and an optional
+// leading +, e.g. "+1 (555) 010-2030"
+var phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 ().\-]{5,48}$`)
+
+// ImportRowError is a problem with one row of an import
+type ImportRowError struct {
+	// Line is the line of the row in the file, the header being line 1
+	Line    int    `json:"line"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportResult summarizes an import. Nothing is written by a dry run, nor when
+// any row has errors.
+type ImportResult struct {
+	DryRun  bool `json:"dry_run"`
+	Rows    int  `json:"rows"`
+	Created int  `json:"created"`
+	// Updated counts rows overwriting an existing contact in an upsert
+	Updated int `json:"updated"`
+	// Skipped counts rows duplicating an existing contact or an earlier row
+	Skipped int               `json:"skipped"`
+	Failed  int               `json:"failed"`
+	Errors  []*ImportRowError `json:"errors"`
+	// Duplicates lists the skipped rows
+	Duplicates []*ImportRowError `json:"duplicates"`
+}
+
+// ImportContactsRequest represents a contact import request
+type ImportContactsRequest struct {
+	EntityID string
+	CSV      io.Reader
+	DryRun   bool
+	// TemplateID names an import template translating the header of the CSV
+	TemplateID string
+	// Upsert overwrites the contact with the same email of a vendor instead
+	// of skipping the row, as AddVendorContact does
+	Upsert bool
+}
+
+// ListEntityContacts retrieves the contacts of all vendors of an entity with
+// their vendor codes
+func (s *VendorService) ListEntityContacts(ctx context.Context, entityID string) ([]*repository.EntityContact, error) {
+	if entityID == "" {
+		return nil, errors.InvalidInput("entity_id", "entity_id is required")
+	}
+	return s.vendorRepo.ListEntityContacts(ctx, entityID)
+}
+
+// WriteContactsCSV writes contacts as CSV with the ContactCSVColumns header,
+// the format accepted by ImportContacts
+func WriteContactsCSV(w io.Writer, contacts []*repository.EntityContact) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(ContactCSVColumns); err != nil {
+		return err
+	}
+
+	value := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	for _, c := range contacts {
+		if err := cw.Write([]string{
+			c.VendorCode,
+			c.ContactType,
+			c.FirstName,
+			c.LastName,
+			value(c.Title),
+			value(c.Email),
+			value(c.Phone),
+			value(c.Mobile),
+			strconv.FormatBool(c.IsPrimary),
+			value(c.Notes),
+		}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// ImportContacts adds the contacts of a CSV upload to the vendors of an entity,
+// matching rows to vendors by vendor_code. Rows whose email is already used by
+// a contact of the same vendor are skipped, or with Upsert overwrite that
+// contact, keeping whether it receives statements; rows repeating the email of
+// an earlier row are skipped. The contacts are only written when every row is
+// valid, all in one transaction.
+func (s *VendorService) ImportContacts(ctx context.Context, req *ImportContactsRequest) (*ImportResult, error) {
+	reqlog.SetEntity(ctx, req.EntityID)
+	ctx = repository.UsePrimary(ctx)
+
+	v := &validator{}
+	v.check(req.EntityID != "", "entity_id", "entity_id is required")
+	var mapping *csvimport.Mapping
+	if req.TemplateID != "" && req.EntityID != "" {
+		var err error
+		if mapping, err = s.importMapping(ctx, v, req.TemplateID, req.EntityID); err != nil {
+			return nil, err
+		}
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	rows, err := csvimport.ReadMapped(req.CSV, mapping, ContactCSVColumns, contactRequiredColumns, MaxImportRows)
+	if err != nil {
+		v.add("file", err.Error())
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	if err := s.checkEntityWritable(ctx, req.EntityID); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.vendorRepo.ListEntityContacts(ctx, req.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	// The oldest contact with an email is the one AddVendorContact matches
+	existingByEmail := make(map[string]*repository.VendorContact, len(existing))
+	for _, c := range existing {
+		if !isSet(c.Email) {
+			continue
+		}
+		key := contactEmailKey(c.VendorID, *c.Email)
+		if prev, ok := existingByEmail[key]; !ok || c.CreatedAt.Before(prev.CreatedAt) ||
+			(c.CreatedAt.Equal(prev.CreatedAt) && c.ID < prev.ID) {
+			existingByEmail[key] = c.VendorContact
+		}
+	}
+	emails := make(map[string]bool)
+
+	result := &ImportResult{
+		DryRun:     req.DryRun,
+		Rows:       len(rows),
+		Errors:     make([]*ImportRowError, 0),
+		Duplicates: make([]*ImportRowError, 0),
+	}
+	vendors := make(map[string]*repository.Vendor)
+	var contacts, updates []*repository.VendorContact
+
+	for _, row := range rows {
+		code := normalizeVendorCode(row.Get("vendor_code"))
+		vendor, seen := vendors[code]
+		if !seen {
+			vendor, _ = s.vendorRepo.GetByCode(ctx, code, req.EntityID)
+			vendors[code] = vendor
+		}
+
+		v := &validator{}
+		contact := contactFromRow(v, row)
+		if vendor == nil && code != "" {
+			v.add("vendor_code", fmt.Sprintf("no vendor with code %q", code))
+		}
+		if len(v.violations) > 0 {
+			result.Failed++
+			for _, violation := range v.violations {
+				result.Errors = append(result.Errors, &ImportRowError{Line: row.Line, Field: violation.Field, Message: violation.Message})
+			}
+			continue
+		}
+
+		contact.VendorID = vendor.ID
+		if isSet(contact.Email) {
+			key := contactEmailKey(vendor.ID, *contact.Email)
+			match := existingByEmail[key]
+			if emails[key] || (match != nil && !req.Upsert) {
+				result.Skipped++
+				result.Duplicates = append(result.Duplicates, &ImportRowError{
+					Line:    row.Line,
+					Field:   "email",
+					Message: fmt.Sprintf("vendor %s already has a contact with email %s", code, *contact.Email),
+				})
+				continue
+			}
+			emails[key] = true
+			if match != nil {
+				contact.ID = match.ID
+				contact.ReceivesPurchaseOrders = match.ReceivesPurchaseOrders
+				contact.ReceivesRemittance = match.ReceivesRemittance
+				contact.ReceivesStatements = match.ReceivesStatements
+				updates = append(updates, contact)
+				continue
+			}
+		}
+		contacts = append(contacts, contact)
+	}
+
+	if req.DryRun || result.Failed > 0 {
+		return result, nil
+	}
+
+	err = s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		for _, contact := range contacts {
+			if err := repo.AddContact(ctx, contact); err != nil {
+				return err
+			}
+		}
+		for _, contact := range updates {
+			if err := repo.UpdateContact(ctx, contact); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Created = len(contacts)
+	result.Updated = len(updates)
+
+	s.logger(ctx).Info().
+		Int("rows", result.Rows).
+		Int("created", result.Created).
+		Int("updated", result.Updated).
+		Int("skipped", result.Skipped).
+		Msg("Vendor contacts imported")
+
+	return result, nil
+}
+
+// contactFromRow validates an import row and builds the contact, reusing the
+// contact type validation of AddVendorContact
+func contactFromRow(v *validator, row csvimport.Row) *repository.VendorContact {
+	optional := func(column string) *string {
+		if value := row.Get(column); value != "" {
+			return &value
+		}
+		return nil
+	}
+
+	isPrimary := false
+	if raw := row.Get("is_primary"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		v.check(err == nil, "is_primary", "is_primary must be true or false")
+		isPrimary = parsed
+	}
+
+	contact := newContact(v, &AddContactRequest{
+		ContactType: row.Get("contact_type"),
+		FirstName:   row.Get("first_name"),
+		LastName:    row.Get("last_name"),
+		Title:       optional("title"),
+		Email:       optional("email"),
+		Phone:       optional("phone"),
+		Mobile:      optional("mobile"),
+		IsPrimary:   isPrimary,
+		Notes:       optional("notes"),
+	}, "")
+
+	v.check(row.Get("vendor_code") != "", "vendor_code", "vendor_code is required")
+	v.check(row.Get("first_name") != "" && len(row.Get("first_name")) <= 100, "first_name", "first_name is required and must be at most 100 characters")
+	v.check(row.Get("last_name") != "" && len(row.Get("last_name")) <= 100, "last_name", "last_name is required and must be at most 100 characters")
+	v.check(len(row.Get("title")) <= 100, "title", "title must be at most 100 characters")
+
+	if email := row.Get("email"); email != "" {
+		v.check(isEmailAddress(email) && len(email) <= 255, "email", "email must be a valid email address")
+	}
+	for _, column := range []string{"phone", "mobile"} {
+		if phone := row.Get(column); phone != "" {
+			v.check(phonePattern.MatchString(phone), column, column+" must be a phone number of digits, spaces, dots, dashes and parentheses")
+		}
+	}
+
+	return contact
+}
+
+// contactEmailKey identifies a contact email of a vendor, ignoring case
+func contactEmailKey(vendorID, email string) string {
+	return vendorID + "\x00" + repository.NormalizeContactEmail(email)
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// Modes of the rule requiring a reachable contact method on vendors
+const (
+	ContactMethodRuleOff     = "off"
+	ContactMethodRuleWarn    = "warn"
+	ContactMethodRuleEnforce = "enforce"
+)
+
+const contactMethodMessage = "vendor has no reachable contact method: set email or phone, or add a primary contact with an email"
+
+// IsValidContactMethodRule reports whether rule is a known contact method rule mode
+func IsValidContactMethodRule(rule string) bool {
+	switch rule {
+	case ContactMethodRuleOff, ContactMethodRuleWarn, ContactMethodRuleEnforce:
+		return true
+	}
+	return false
+}
+
+// hasReachableContact reports whether a vendor has an email, a phone, or a
+// primary contact with an email
+func hasReachableContact(vendor *repository.Vendor, contacts []*repository.VendorContact) bool {
+	if isSet(vendor.Email) || isSet(vendor.Phone) {
+		return true
+	}
+	for _, contact := range contacts {
+		if contact.IsPrimary && isSet(contact.Email) {
+			return true
+		}
+	}
+	return false
+}
+
+func isSet(s *string) bool {
+	return s != nil && strings.TrimSpace(*s) != ""
+}
+
+// checkContactMethod applies the entity's contact method rule. In enforce mode a
+// violation is recorded in v; in warn mode it is returned as a warning instead.
+func (s *VendorService) checkContactMethod(ctx context.Context, v *validator, vendor *repository.Vendor, contacts []*repository.VendorContact) ([]*repository.ValidationWarning, error) {
+	if hasReachableContact(vendor, contacts) {
+		return nil, nil
+	}
+
+	flags, err := s.entityFlags(ctx, vendor.EntityID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch flags.ContactMethodRule {
+	case ContactMethodRuleEnforce:
+		v.add("email", contactMethodMessage)
+		return nil, nil
+	case ContactMethodRuleWarn:
+		return []*repository.ValidationWarning{{Code: WarningNoContactMethod, Message: contactMethodMessage}}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// GetValidationSettings retrieves the validation settings of an entity
+func (s *VendorService) GetValidationSettings(ctx context.Context, entityID string) (*repository.ValidationSettings, error) {
+	return s.vendorRepo.GetValidationSettings(ctx, entityID)
+}
+
+// SetValidationSettings creates or replaces the validation settings of an entity
+func (s *VendorService) SetValidationSettings(ctx context.Context, settings *repository.ValidationSettings) error {
+	if settings.EntityID == "" {
+		return errors.InvalidInput("entity_id", "entity_id is required")
+	}
+	if settings.ContactMethodRule != nil && !IsValidContactMethodRule(*settings.ContactMethodRule) {
+		return errors.InvalidInput("contact_method_rule", "contact_method_rule must be off, warn or enforce")
+	}
+
+	if err := s.vendorRepo.UpsertValidationSettings(ctx, settings); err != nil {
+		return err
+	}
+	s.flagsCache.invalidate(settings.EntityID)
+
+	reqlog.SetEntity(ctx, settings.EntityID)
+	s.logger(ctx).Info().Msg("Validation settings updated")
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/country"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+// Modes of the rule applying country profiles to vendor writes
+const (
+	CountryProfileRuleOff     = "off"
+	CountryProfileRuleWarn    = "warn"
+	CountryProfileRuleEnforce = "enforce"
+)
+
+// IsValidCountryProfileRule reports whether rule is a known country profile rule mode
+func IsValidCountryProfileRule(rule string) bool {
+	switch rule {
+	case CountryProfileRuleOff, CountryProfileRuleWarn, CountryProfileRuleEnforce:
+		return true
+	}
+	return false
+}
+
+// ListCountryProfiles returns the profiles vendors are checked against, the
+// default profile of other countries first
+func (s *VendorService) ListCountryProfiles(ctx context.Context) []*country.Profile {
+	return country.Profiles()
+}
+
+// GetCountryProfile returns the profile vendors of a country are checked
+// against, the default profile for countries without their own
+func (s *VendorService) GetCountryProfile(ctx context.Context, code string) (*country.Profile, error) {
+	v := &validator{}
+	v.check(len(code) == 2, "country", fmt.Sprintf("country must be a 2-letter ISO code, got %q", code))
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return country.Lookup(code), nil
+}
+
+// checkCountryProfile applies the profile of a vendor's country to a vendor
+// being created (before is nil) or updated, unless an update keeps the profile
+// and every field profiles can name. In enforce mode problems are recorded in
+// v; in warn mode they are returned as warnings instead.
+func (s *VendorService) checkCountryProfile(v *validator, before, vendor *repository.Vendor) []*repository.ValidationWarning {
+	if s.countryProfileRule == CountryProfileRuleOff {
+		return nil
+	}
+	if before != nil && !countryProfileChanged(before, vendor) {
+		return nil
+	}
+
+	var warnings []*repository.ValidationWarning
+	for _, issue := range country.Lookup(vendor.Country).Check(vendor.StringField) {
+		if s.countryProfileRule == CountryProfileRuleEnforce {
+			v.add(issue.Field, issue.Message)
+		} else {
+			warnings = append(warnings, &repository.ValidationWarning{Code: WarningCountryProfile, Field: issue.Field, Message: issue.Message})
+		}
+	}
+	return warnings
+}
+
+// countryProfileChanged reports whether an update changes the country of a
+// vendor or any field a country profile can name
+func countryProfileChanged(before, after *repository.Vendor) bool {
+	if country.Lookup(before.Country) != country.Lookup(after.Country) {
+		return true
+	}
+	for _, field := range country.Fields {
+		if before.StringField(field) != after.StringField(field) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyCountryProfileColumns copies the fields named in columns that country
+// profiles can name, besides the address fields, from src to dst
+func applyCountryProfileColumns(dst, src *repository.Vendor, columns []string) {
+	for _, column := range columns {
+		switch column {
+		case "legal_name":
+			dst.LegalName = src.LegalName
+		case "doing_business_as":
+			dst.DoingBusinessAs = src.DoingBusinessAs
+		case "tax_id":
+			dst.TaxID = src.TaxID
+		case "email":
+			dst.Email = src.Email
+		case "remittance_email":
+			dst.RemittanceEmail = src.RemittanceEmail
+		case "phone":
+			dst.Phone = src.Phone
+		case "fax":
+			dst.Fax = src.Fax
+		case "website":
+			dst.Website = src.Website
+		}
+	}
+}
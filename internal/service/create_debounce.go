@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// pendingCreate marks a debounce key whose create has not finished yet
+const pendingCreate = "pending"
+
+// DuplicateCreateError is returned when an identical vendor was created within
+// the debounce window, typically by a double-submitted form
+type DuplicateCreateError struct {
+	EntityID string
+	// VendorID is the vendor just created; empty while that create is in progress
+	VendorID string
+	Window   time.Duration
+}
+
+func (e *DuplicateCreateError) Error() string {
+	if e.VendorID == "" {
+		return fmt.Sprintf("an identical vendor is being created; retry after %s or set force", e.Window)
+	}
+	return fmt.Sprintf("an identical vendor %s was created less than %s ago; set force to create another", e.VendorID, e.Window)
+}
+
+// createKey identifies creates of the same vendor by entity, name, tax ID and
+// email. Only a hash is kept, so keys never contain tax IDs.
+func createKey(req *CreateVendorRequest) string {
+	var taxID, email string
+	if req.TaxID != nil {
+		taxID = strings.TrimSpace(*req.TaxID)
+	}
+	if req.Email != nil {
+		email = strings.ToLower(strings.TrimSpace(*req.Email))
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		req.EntityID,
+		strings.ToLower(strings.TrimSpace(req.VendorName)),
+		taxID,
+		email,
+	}, "\x00")))
+	return "vendors:create:" + hex.EncodeToString(sum[:])
+}
+
+// claimCreate fails with DuplicateCreateError when an identical create was seen
+// within the debounce window, and otherwise marks this one as pending. It
+// returns the key to settle with settleCreate, or "" when nothing was claimed.
+// Store failures are logged and let the create through.
+func (s *VendorService) claimCreate(ctx context.Context, req *CreateVendorRequest) (string, error) {
+	if s.recentCreates == nil || s.createWindow <= 0 || req.Force {
+		return "", nil
+	}
+
+	key := createKey(req)
+	existing, claimed, err := s.recentCreates.Claim(ctx, key, pendingCreate, s.createWindow)
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Msg("Create debounce unavailable, skipping duplicate check")
+		return "", nil
+	}
+	if !claimed {
+		if existing == pendingCreate {
+			existing = ""
+		}
+		return "", &DuplicateCreateError{EntityID: req.EntityID, VendorID: existing, Window: s.createWindow}
+	}
+	return key, nil
+}
+
+// settleCreate records the vendor created for a claimed key, or releases the
+// key when the create failed
+func (s *VendorService) settleCreate(ctx context.Context, key, vendorID string) {
+	if key == "" {
+		return
+	}
+
+	var err error
+	if vendorID == "" {
+		err = s.recentCreates.Delete(ctx, key)
+	} else {
+		err = s.recentCreates.Set(ctx, key, vendorID, s.createWindow)
+	}
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Msg("Failed to update create debounce")
+	}
+}
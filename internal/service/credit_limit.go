@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/fx"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+// AuditActionCreditLimitCurrencyOverride is written when a vendor is given a
+// credit limit in another currency than its own
+const AuditActionCreditLimitCurrencyOverride = "credit_limit_currency_override"
+
+// creditLimitCurrency returns the currency of the credit limit of a vendor in
+// currency: the requested one, or currency when none is requested, and nil
+// without a limit. A limit in another currency needs override.
+func creditLimitCurrency(v *validator, limit *int64, currency string, requested *string, override bool) *string {
+	if limit == nil {
+		return nil
+	}
+	code := currency
+	if requested != nil && strings.TrimSpace(*requested) != "" {
+		code = strings.ToUpper(strings.TrimSpace(*requested))
+		if !isCurrencyCode(code) {
+			v.add("credit_limit_currency", "credit_limit_currency must be an ISO 4217 currency code")
+			return nil
+		}
+	}
+	v.check(code == currency || override, "credit_limit_currency",
+		fmt.Sprintf("credit_limit_currency %s differs from the vendor currency %s; set credit_limit_currency_override to keep the limit in %s", code, currency, code))
+	return &code
+}
+
+// recordCreditLimitCurrencyOverride audits a vendor newly given a credit
+// limit in another currency than its own. before is nil for new vendors.
+func (s *VendorService) recordCreditLimitCurrencyOverride(ctx context.Context, repo repository.Store, before, after *repository.Vendor, actorID *string) error {
+	if after.CreditLimit == nil || repository.CreditLimitComparable(after) {
+		return nil
+	}
+	if before != nil && !repository.CreditLimitComparable(before) && deref(before.CreditLimitCurrency) == deref(after.CreditLimitCurrency) {
+		return nil
+	}
+
+	return repo.InsertAuditEntry(ctx, &repository.AuditEntry{
+		EntityID: after.EntityID,
+		VendorID: after.ID,
+		Action:   AuditActionCreditLimitCurrencyOverride,
+		ActorID:  actorID,
+		Details: map[string]interface{}{
+			"currency":              after.Currency,
+			"credit_limit":          *after.CreditLimit,
+			"credit_limit_currency": *after.CreditLimitCurrency,
+		},
+	})
+}
+
+// checkForeignCreditLimit compares the balance of a vendor with a credit
+// limit in another currency, converted to the vendor currency. It returns the
+// reason the vendor is over its limit, or a warning when the limit cannot be
+// converted; both are empty when the limit is in the vendor currency.
+func (s *VendorService) checkForeignCreditLimit(ctx context.Context, vendor *repository.Vendor) (over, warning string, err error) {
+	if vendor.CreditLimit == nil || repository.CreditLimitComparable(vendor) {
+		return "", "", nil
+	}
+
+	limit, err := s.fxConverter.Convert(ctx, *vendor.CreditLimit, *vendor.CreditLimitCurrency, vendor.Currency)
+	if stderrors.Is(err, fx.ErrCannotCompare) {
+		return "", fmt.Sprintf("credit limit in %s cannot be compared with the balance in %s", *vendor.CreditLimitCurrency, vendor.Currency), nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	if vendor.CurrentBalance >= limit {
+		return fmt.Sprintf("vendor has exceeded credit limit: balance=%d %s, limit=%d %s (%d %s)",
+			vendor.CurrentBalance, vendor.Currency, *vendor.CreditLimit, *vendor.CreditLimitCurrency, limit, vendor.Currency), "", nil
+	}
+	return "", "", nil
+}
+
+// keptCreditLimitCurrency returns the requested credit limit currency of an
+// update and whether it may differ from the vendor currency. A limit already
+// kept in another currency stays there when the request names none.
+func keptCreditLimitCurrency(before *repository.Vendor, requested *string, override bool) (*string, bool) {
+	stored := before.CreditLimitCurrency
+	if stored == nil || *stored == before.Currency {
+		return requested, override
+	}
+	if requested == nil || strings.TrimSpace(*requested) == "" {
+		return stored, true
+	}
+	return requested, override || strings.EqualFold(strings.TrimSpace(*requested), *stored)
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"golang.org/x/text/currency"
+)
+
+// maxAcceptedCurrencies bounds the accepted currencies of a vendor
+const maxAcceptedCurrencies = 20
+
+// isCurrencyCode reports whether code is an ISO 4217 currency code
+func isCurrencyCode(code string) bool {
+	_, err := currency.ParseISO(code)
+	return len(code) == 3 && err == nil
+}
+
+// normalizeCurrencies validates requested accepted currencies and returns
+// them uppercased without duplicates, or nil when none are given
+func normalizeCurrencies(v *validator, codes []string) []string {
+	if len(codes) == 0 {
+		return nil
+	}
+	v.check(len(codes) <= maxAcceptedCurrencies, "accepted_currencies",
+		fmt.Sprintf("at most %d accepted currencies are allowed", maxAcceptedCurrencies))
+
+	normalized := make([]string, 0, len(codes))
+	for i, code := range codes {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if !isCurrencyCode(code) {
+			v.add(fmt.Sprintf("accepted_currencies[%d]", i), fmt.Sprintf("%q is not an ISO 4217 currency code", code))
+			continue
+		}
+		if !slices.Contains(normalized, code) {
+			normalized = append(normalized, code)
+		}
+	}
+	return normalized
+}
+
+// checkAcceptedCurrencies requires accepted currencies, when set, to include
+// the primary currency of the vendor
+func checkAcceptedCurrencies(v *validator, primary string, accepted []string) {
+	if len(accepted) > 0 && !slices.Contains(accepted, primary) {
+		v.add("accepted_currencies", fmt.Sprintf("accepted_currencies must include the primary currency %s", primary))
+	}
+}
+
+// acceptsCurrency reports whether a vendor can be invoiced in code: its
+// primary currency or one of its accepted currencies
+func acceptsCurrency(vendor *repository.Vendor, code string) bool {
+	return vendor.Currency == code || slices.Contains(vendor.AcceptedCurrencies, code)
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+// AuditActionCurrencyChanged is written when an admin changes the currency of
+// a vendor with an outstanding balance or recent ledger activity
+const AuditActionCurrencyChanged = "currency_changed"
+
+// CurrencyChangeBlockedError is returned when an update changes the currency
+// of a vendor whose balance is not zero or whose balance ledger moved within
+// the configured window, as its amounts would no longer be in its currency
+type CurrencyChangeBlockedError struct {
+	VendorID string
+	From     string
+	To       string
+	Balance  int64
+	// RecentActivity is set when the ledger moved within the last WindowDays
+	RecentActivity bool
+	WindowDays     int
+}
+
+func (e *CurrencyChangeBlockedError) Error() string {
+	reason := fmt.Sprintf("the current balance is %d", e.Balance)
+	if e.Balance == 0 {
+		reason = fmt.Sprintf("the balance changed in the last %d days", e.WindowDays)
+	}
+	return fmt.Sprintf("currency cannot change from %s to %s because %s; an admin override with a currency_conversion_note is required", e.From, e.To, reason)
+}
+
+// checkCurrencyChange rejects a currency change of a vendor with a balance or
+// recent ledger activity, unless override is set and a conversion note is
+// given. It reports whether the change goes through on the override, so that
+// it can be audited.
+func (s *VendorService) checkCurrencyChange(ctx context.Context, before *repository.Vendor, currency string, override bool, note string) (bool, error) {
+	if before == nil || strings.EqualFold(before.Currency, currency) {
+		return false, nil
+	}
+
+	recent := false
+	if before.CurrentBalance == 0 && s.currencyWindowDays > 0 {
+		since := time.Now().UTC().AddDate(0, 0, -s.currencyWindowDays)
+		var err error
+		if recent, err = s.vendorRepo.HasBalanceActivitySince(ctx, before.ID, since); err != nil {
+			return false, err
+		}
+	}
+	if before.CurrentBalance == 0 && !recent {
+		return false, nil
+	}
+
+	if override {
+		if strings.TrimSpace(note) == "" {
+			v := &validator{}
+			v.add("currency_conversion_note", "currency_conversion_note is required to change the currency of a vendor with a balance")
+			return false, v.err()
+		}
+		return true, nil
+	}
+	return false, &CurrencyChangeBlockedError{
+		VendorID:       before.ID,
+		From:           before.Currency,
+		To:             strings.ToUpper(currency),
+		Balance:        before.CurrentBalance,
+		RecentActivity: recent,
+		WindowDays:     s.currencyWindowDays,
+	}
+}
+
+// recordCurrencyOverride audits a currency change made through an admin
+// override with its conversion note
+func (s *VendorService) recordCurrencyOverride(ctx context.Context, repo repository.Store, before, after *repository.Vendor, note string, actorID *string) error {
+	return repo.InsertAuditEntry(ctx, &repository.AuditEntry{
+		EntityID: before.EntityID,
+		VendorID: before.ID,
+		Action:   AuditActionCurrencyChanged,
+		ActorID:  actorID,
+		Details: map[string]interface{}{
+			"from":            before.Currency,
+			"to":              after.Currency,
+			"balance":         before.CurrentBalance,
+			"conversion_note": strings.TrimSpace(note),
+			"override":        true,
+		},
+	})
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+// ExpandDataQuality includes the completeness breakdown of a vendor in GetVendor
+const ExpandDataQuality = "data_quality"
+
+// Page sizes of the offender lists of the data quality report
+const (
+	// DefaultDataQualityPageSize is the number of offenders listed per rule when no page size is requested
+	DefaultDataQualityPageSize = 50
+	// MaxDataQualityPageSize is the largest number of offenders listed per rule at once
+	MaxDataQualityPageSize = 200
+)
+
+// DataQualityRuleReport is the outcome of a data quality rule across an
+// entity: how many vendors fail it and a page of them
+type DataQualityRuleReport struct {
+	Rule    string                            `json:"rule"`
+	Message string                            `json:"message"`
+	Count   int64                             `json:"count"`
+	Vendors []*repository.DataQualityOffender `json:"vendors"`
+}
+
+// DataQualityReport is the punch list of vendors missing required data
+type DataQualityReport struct {
+	EntityID string                   `json:"entity_id"`
+	Rules    []*DataQualityRuleReport `json:"rules"`
+	Page     int                      `json:"page"`
+	PageSize int                      `json:"page_size"`
+}
+
+// AllDataQualityRules returns the names of every data quality rule
+func AllDataQualityRules() []string {
+	names := make([]string, len(repository.DataQualityRules))
+	for i, rule := range repository.DataQualityRules {
+		names[i] = rule.Name
+	}
+	return names
+}
+
+// ParseDataQualityRules checks a configured list of data quality rules; an
+// empty list enables every rule
+func ParseDataQualityRules(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return AllDataQualityRules(), nil
+	}
+	for _, name := range names {
+		if _, ok := repository.DataQualityRuleByName(name); !ok {
+			return nil, fmt.Errorf("unknown data quality rule %q", name)
+		}
+	}
+	return names, nil
+}
+
+// GetDataQualityReport evaluates the enabled data quality rules over the live
+// vendors of an entity, counting the vendors failing each and listing a page
+// of them. A rule limits the report to that rule.
+func (s *VendorService) GetDataQualityReport(ctx context.Context, entityID, rule string, page, pageSize int) (*DataQualityReport, error) {
+	rules := s.dataQualityRules
+	v := &validator{}
+	v.check(entityID != "", "entity_id", "entity_id is required")
+	if rule != "" {
+		enabled := false
+		for _, name := range rules {
+			enabled = enabled || name == rule
+		}
+		v.check(enabled, "rule", fmt.Sprintf("rule must be an enabled data quality rule, got %q", rule))
+		rules = []string{rule}
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	counts, err := s.vendorRepo.CountDataQualityIssues(ctx, entityID, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DataQualityReport{EntityID: entityID, Rules: make([]*DataQualityRuleReport, 0, len(rules)), Page: page, PageSize: pageSize}
+	for _, name := range rules {
+		definition, _ := repository.DataQualityRuleByName(name)
+		ruleReport := &DataQualityRuleReport{
+			Rule:    name,
+			Message: definition.Message,
+			Count:   counts[name],
+			Vendors: make([]*repository.DataQualityOffender, 0),
+		}
+		if ruleReport.Count > int64((page-1)*pageSize) {
+			if ruleReport.Vendors, err = s.vendorRepo.ListDataQualityOffenders(ctx, entityID, name, pageSize, (page-1)*pageSize); err != nil {
+				return nil, err
+			}
+		}
+		report.Rules = append(report.Rules, ruleReport)
+	}
+	return report, nil
+}
+
+// attachDataQuality sets the completeness breakdown of a vendor under the
+// enabled data quality rules
+func (s *VendorService) attachDataQuality(ctx context.Context, vendor *repository.Vendor) error {
+	failed, err := s.vendorRepo.ListFailedDataQualityRules(ctx, []string{vendor.ID}, s.dataQualityRules)
+	if err != nil {
+		return err
+	}
+	failing := make(map[string]bool)
+	for _, name := range failed[vendor.ID] {
+		failing[name] = true
+	}
+
+	quality := &repository.DataQuality{Total: len(s.dataQualityRules), Checks: make([]repository.DataQualityCheck, 0, len(s.dataQualityRules))}
+	for _, name := range s.dataQualityRules {
+		check := repository.DataQualityCheck{Rule: name, Passed: !failing[name]}
+		if check.Passed {
+			quality.Passed++
+		} else {
+			definition, _ := repository.DataQualityRuleByName(name)
+			check.Message = definition.Message
+		}
+		quality.Checks = append(quality.Checks, check)
+	}
+	quality.Complete = quality.Passed == quality.Total
+	vendor.DataQuality = quality
+	return nil
+}
+
+// dataQualityWarnings describes the data quality rules a vendor fails
+func dataQualityWarnings(vendor *repository.Vendor) []string {
+	if vendor.DataQuality == nil {
+		return nil
+	}
+	var warnings []string
+	for _, check := range vendor.DataQuality.Checks {
+		if !check.Passed {
+			warnings = append(warnings, check.Message)
+		}
+	}
+	return warnings
+}
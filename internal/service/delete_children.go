@@ -0,0 +1,47 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+// VendorHasChildrenError is returned when deleting a vendor with child
+// records without saying whether to keep them
+type VendorHasChildrenError struct {
+	VendorID string
+	Children *repository.VendorChildren
+}
+
+func (e *VendorHasChildrenError) Error() string {
+	return fmt.Sprintf("vendor %s has %s; set keep_children=false to delete them with the vendor or keep_children=true to keep them",
+		e.VendorID, describeChildren(e.Children))
+}
+
+// describeChildren lists the child records counted in c, e.g. "2 contacts
+// and 1 document"
+func describeChildren(c *repository.VendorChildren) string {
+	var parts []string
+	for _, child := range []struct {
+		count int
+		name  string
+	}{
+		{c.Contacts, "contact"},
+		{c.Documents, "document"},
+		{c.BankVerifications, "bank account verification"},
+		{c.PendingStatusChanges, "pending status change"},
+	} {
+		switch child.count {
+		case 0:
+		case 1:
+			parts = append(parts, "1 "+child.name)
+		default:
+			parts = append(parts, fmt.Sprintf("%d %ss", child.count, child.name))
+		}
+	}
+	if len(parts) < 2 {
+		return strings.Join(parts, "")
+	}
+	return strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+}
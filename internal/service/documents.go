@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/docstore"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// AuditActionDocumentDownloaded records a download of a vendor document
+const AuditActionDocumentDownloaded = "document_downloaded"
+
+// Ways a document download is delivered
+const (
+	DocumentDeliveryRedirect = "redirect"
+	DocumentDeliveryStream   = "stream"
+)
+
+var (
+	// ErrDocumentStorageDisabled is returned when no document store is
+	// configured
+	ErrDocumentStorageDisabled = stderrors.New("vendor document downloads are not enabled")
+	// ErrDocumentStorageFailed is returned when the document store fails
+	ErrDocumentStorageFailed = stderrors.New("the document could not be read from storage")
+)
+
+// DocumentDownload is how a vendor document is handed to a caller: a signed
+// RedirectURL valid until ExpiresAt, or the Object to stream
+type DocumentDownload struct {
+	Document    *repository.VendorDocument
+	RedirectURL string
+	ExpiresAt   time.Time
+	Object      *docstore.Object
+}
+
+// DownloadVendorDocument prepares the download of a document of a live vendor
+// of an entity and records it in the audit trail. Files up to the stream
+// threshold, or from stores that cannot sign links, are streamed (byteRange
+// selecting part of them); larger ones get a short-lived signed link. The
+// caller closes the Object of streamed downloads.
+func (s *VendorService) DownloadVendorDocument(ctx context.Context, documentID, entityID, userID, byteRange string) (*DocumentDownload, error) {
+	reqlog.SetEntity(ctx, entityID)
+	if entityID == "" {
+		return nil, errors.InvalidInput("entity_id", "entity_id is required")
+	}
+	if s.documents == nil {
+		return nil, ErrDocumentStorageDisabled
+	}
+
+	doc, err := s.vendorRepo.GetDocument(ctx, documentID, entityID)
+	if err != nil {
+		return nil, err
+	}
+	reqlog.SetVendor(ctx, doc.VendorID)
+
+	download := &DocumentDownload{Document: doc}
+	stream := doc.FileSize != nil && *doc.FileSize <= s.documentStreamMaxBytes
+	if !stream {
+		download.ExpiresAt = time.Now().Add(s.documentLinkTTL)
+		download.RedirectURL, err = s.documents.SignURL(ctx, doc.DocumentURL, download.ExpiresAt)
+		if stderrors.Is(err, docstore.ErrSigningUnsupported) {
+			stream, err = true, nil
+		}
+		if err != nil {
+			return nil, s.documentStorageFailed(ctx, err)
+		}
+	}
+	delivery := DocumentDeliveryRedirect
+	if stream {
+		delivery = DocumentDeliveryStream
+		download.RedirectURL, download.ExpiresAt = "", time.Time{}
+		download.Object, err = s.documents.Open(ctx, doc.DocumentURL, byteRange)
+		switch {
+		case stderrors.Is(err, docstore.ErrNotFound):
+			return nil, errors.NotFound("document file", documentID)
+		case stderrors.Is(err, docstore.ErrRangeNotSatisfiable):
+			return nil, err
+		case err != nil:
+			return nil, s.documentStorageFailed(ctx, err)
+		}
+	}
+
+	details := map[string]interface{}{
+		"document_id":   doc.ID,
+		"document_type": doc.DocumentType,
+		"document_name": doc.DocumentName,
+		"delivery":      delivery,
+	}
+	if stream && byteRange != "" {
+		details["range"] = byteRange
+	}
+	var actorID *string
+	if userID != "" {
+		actorID = &userID
+	}
+	err = s.vendorRepo.InsertAuditEntry(ctx, &repository.AuditEntry{
+		EntityID: entityID,
+		VendorID: doc.VendorID,
+		Action:   AuditActionDocumentDownloaded,
+		ActorID:  actorID,
+		Details:  details,
+	})
+	if err != nil {
+		if download.Object != nil {
+			download.Object.Body.Close()
+		}
+		return nil, err
+	}
+
+	s.logger(ctx).Info().
+		Str("document_id", doc.ID).
+		Str("delivery", delivery).
+		Msg("Vendor document downloaded")
+	return download, nil
+}
+
+// documentStorageFailed logs a failure of the document store, which names
+// storage URLs, and returns ErrDocumentStorageFailed in its place
+func (s *VendorService) documentStorageFailed(ctx context.Context, err error) error {
+	s.logger(ctx).Error().Err(err).Msg("Failed to read vendor document from storage")
+	return ErrDocumentStorageFailed
+}
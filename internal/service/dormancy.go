@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+// EventVendorFlaggedDormant is published for every vendor newly flagged
+// dormant when dormancy events are enabled
+const EventVendorFlaggedDormant = "vendor.flagged_dormant"
+
+const (
+	// dormancyBatchSize is how many vendors the dormancy job checks per transaction
+	dormancyBatchSize = 1000
+)
+
+// Page sizes of dormant vendor lists
+const (
+	DefaultDormantPageSize = 100
+	MaxDormantPageSize     = 500
+)
+
+// DormancyReport is the outcome of a dormancy job run
+type DormancyReport struct {
+	EntityID string `json:"entity_id,omitempty"`
+	// Cutoff is the time vendors without activity since are dormant
+	Cutoff  time.Time `json:"cutoff"`
+	Scanned int       `json:"scanned"`
+	Dormant int       `json:"dormant"`
+	// NewlyFlagged counts the dormant vendors that were not flagged before
+	NewlyFlagged int `json:"newly_flagged"`
+	// Cleared counts the flagged vendors with activity since
+	Cleared int `json:"cleared"`
+}
+
+// DormantVendorPage is a page of dormant vendors; NextAfter is the cursor of
+// the next page, empty on the last one
+type DormantVendorPage struct {
+	Vendors   []*repository.DormantVendor `json:"vendors"`
+	NextAfter string                      `json:"next_after,omitempty"`
+}
+
+// DetectDormantVendors flags the live vendors of an entity, or of all entities
+// when entityID is empty, with no balance ledger activity and no updates for
+// the configured number of months, and unflags those active again. Vendors are
+// checked in keyset batches of 1000, each in its own transaction. Dormancy is
+// only a flag: vendor status is never changed.
+func (s *VendorService) DetectDormantVendors(ctx context.Context, entityID string) (*DormancyReport, error) {
+	ctx = repository.WithBulkBudget(repository.UsePrimary(ctx))
+
+	report := &DormancyReport{
+		EntityID: entityID,
+		Cutoff:   time.Now().UTC().AddDate(0, -s.dormantMonths, 0),
+	}
+	var afterID string
+	for {
+		var result *repository.DormancyBatchResult
+		err := s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+			var err error
+			result, err = repo.MarkDormantVendors(ctx, repository.DormancyBatch{
+				EntityID: entityID,
+				AfterID:  afterID,
+				Cutoff:   report.Cutoff,
+				Limit:    dormancyBatchSize,
+			})
+			if err != nil || !s.dormancyEvents {
+				return err
+			}
+
+			for _, flag := range result.Flagged {
+				if err := repo.InsertEvent(ctx, &repository.VendorEvent{
+					EntityID:  flag.EntityID,
+					VendorID:  flag.VendorID,
+					EventType: EventVendorFlaggedDormant,
+					Payload: map[string]interface{}{
+						"last_activity_at": flag.LastActivityAt,
+						"dormant_months":   s.dormantMonths,
+					},
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		report.Scanned += result.Scanned
+		report.Dormant += result.Dormant
+		report.NewlyFlagged += len(result.Flagged)
+		report.Cleared += result.Cleared
+		if result.Scanned < dormancyBatchSize {
+			break
+		}
+		afterID = result.LastID
+	}
+
+	s.logger(ctx).Info().
+		Str("entity_id", entityID).
+		Int("scanned", report.Scanned).
+		Int("dormant", report.Dormant).
+		Int("newly_flagged", report.NewlyFlagged).
+		Int("cleared", report.Cleared).
+		Msg("Dormant vendors detected")
+
+	return report, nil
+}
+
+// ListDormantVendors retrieves a page of the vendors of an entity flagged
+// dormant by the last dormancy run, by ID after the cursor after
+func (s *VendorService) ListDormantVendors(ctx context.Context, entityID, after string, limit int) (*DormantVendorPage, error) {
+	v := &validator{}
+	v.check(entityID != "", "entity_id", "entity_id is required")
+	v.check(limit >= 1 && limit <= MaxDormantPageSize, "limit", fmt.Sprintf("limit must be between 1 and %d", MaxDormantPageSize))
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	dormant, err := s.vendorRepo.ListDormantVendors(ctx, entityID, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	vendors := make([]*repository.Vendor, len(dormant))
+	for i, d := range dormant {
+		vendors[i] = d.Vendor
+	}
+	if err := s.attachRiskScores(ctx, vendors...); err != nil {
+		return nil, err
+	}
+
+	page := &DormantVendorPage{Vendors: dormant}
+	if len(dormant) == limit {
+		page.NextAfter = dormant[len(dormant)-1].Vendor.ID
+	}
+	return page, nil
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+// errDryRun ends the transaction of a dry run so that it is rolled back
+var errDryRun = stderrors.New("dry run")
+
+// withTx runs fn in a transaction like Store.WithTx. With dryRun the
+// transaction is rolled back once fn succeeds: every check and write runs,
+// but nothing is kept, including the audit entries and events fn writes.
+func (s *VendorService) withTx(ctx context.Context, dryRun bool, fn func(repo repository.Store) error) error {
+	err := s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		if err := fn(repo); err != nil {
+			return err
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if dryRun && stderrors.Is(err, errDryRun) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxCachedEntityFlags bounds the entities whose flags are cached; expired
+// entries are dropped once it is reached
+const maxCachedEntityFlags = 10000
+
+// EntityFlags are the validation switches in effect for an entity: its own
+// validation and entity settings, or the service defaults where it has none.
+// Validation consults them through entityFlags only, so every check resolves
+// them the same way.
+type EntityFlags struct {
+	ContactMethodRule             string `json:"contact_method_rule"`
+	StrictAddressValidation       bool   `json:"strict_address_validation"`
+	StrictBankValidation          bool   `json:"strict_bank_validation"`
+	LockVendorCodeAfterActivation bool   `json:"lock_vendor_code_after_activation"`
+	RequireBankVerification       bool   `json:"require_bank_verification"`
+	// EntityState is set when the platform suspended or deleted the entity,
+	// which makes its vendors read-only
+	EntityState string `json:"entity_state,omitempty"`
+}
+
+// entityFlagsCache holds the resolved flags of entities for a short while, so
+// validation does not read the settings tables on every write. Changes made
+// through this instance apply at once; other instances see them once their
+// entry expires.
+type entityFlagsCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cachedEntityFlags
+}
+
+type cachedEntityFlags struct {
+	flags     EntityFlags
+	expiresAt time.Time
+}
+
+func newEntityFlagsCache(ttl time.Duration) *entityFlagsCache {
+	return &entityFlagsCache{ttl: ttl, entries: make(map[string]cachedEntityFlags)}
+}
+
+func (c *entityFlagsCache) get(entityID string, now time.Time) (EntityFlags, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[entityID]
+	if !ok || !now.Before(entry.expiresAt) {
+		return EntityFlags{}, false
+	}
+	return entry.flags, true
+}
+
+func (c *entityFlagsCache) put(entityID string, flags EntityFlags, now time.Time) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCachedEntityFlags {
+		for id, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+	}
+	c.entries[entityID] = cachedEntityFlags{flags: flags, expiresAt: now.Add(c.ttl)}
+}
+
+func (c *entityFlagsCache) invalidate(entityID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, entityID)
+}
+
+// entityFlags returns the validation switches in effect for an entity
+func (s *VendorService) entityFlags(ctx context.Context, entityID string) (*EntityFlags, error) {
+	now := time.Now()
+	if flags, ok := s.flagsCache.get(entityID, now); ok {
+		return &flags, nil
+	}
+
+	validation, err := s.vendorRepo.GetValidationSettings(ctx, entityID)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := s.vendorRepo.GetEntitySettings(ctx, entityID)
+	if err != nil {
+		return nil, err
+	}
+	state, err := s.vendorRepo.GetEntityState(ctx, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	flags := EntityFlags{
+		ContactMethodRule:       s.contactMethodRule,
+		StrictAddressValidation: s.strictAddresses,
+		StrictBankValidation:    s.strictBank,
+		RequireBankVerification: s.requireBankVerification,
+		EntityState:             state,
+	}
+	if validation.ContactMethodRule != nil {
+		flags.ContactMethodRule = *validation.ContactMethodRule
+	}
+	if validation.StrictAddressValidation != nil {
+		flags.StrictAddressValidation = *validation.StrictAddressValidation
+	}
+	if settings.StrictBankValidation != nil {
+		flags.StrictBankValidation = *settings.StrictBankValidation
+	}
+	if settings.RequireBankVerification != nil {
+		flags.RequireBankVerification = *settings.RequireBankVerification
+	}
+	if settings.LockVendorCodeAfterActivation != nil {
+		flags.LockVendorCodeAfterActivation = *settings.LockVendorCodeAfterActivation
+	}
+
+	s.flagsCache.put(entityID, flags, now)
+	return &flags, nil
+}
+
+// EffectiveEntityFlags returns the validation switches in effect for an
+// entity, with the service defaults applied
+func (s *VendorService) EffectiveEntityFlags(ctx context.Context, entityID string) (*EntityFlags, error) {
+	return s.entityFlags(ctx, entityID)
+}
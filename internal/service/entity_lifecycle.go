@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/entityevents"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+)
+
+// Actions of the entity lifecycle log
+const (
+	EntityActionSuspended   = "suspended"
+	EntityActionReactivated = "reactivated"
+	EntityActionCleanup     = "cleanup"
+)
+
+// Bounds of the entity lifecycle log returned at once
+const (
+	// DefaultEntityLifecycleLimit is the number of log entries returned when no limit is requested
+	DefaultEntityLifecycleLimit = 50
+	// MaxEntityLifecycleLimit is the largest number of log entries returned at once
+	MaxEntityLifecycleLimit = 500
+)
+
+// entityEventsConsumer names the entity event consumer's cursor
+const entityEventsConsumer = "entity_events"
+
+// entityCleanupChunk is how many vendors an entity cleanup deletes per
+// transaction
+const entityCleanupChunk = 100
+
+// EntityReadOnlyError is returned when a write targets an entity the platform
+// suspended or deleted
+type EntityReadOnlyError struct {
+	EntityID string
+	State    string
+}
+
+func (e *EntityReadOnlyError) Error() string {
+	return fmt.Sprintf("entity %s is %s; its vendors are read-only", e.EntityID, e.State)
+}
+
+// EntityCleanupReport is the outcome of an entity cleanup. A dry run counts
+// what a real run would delete and cancel without changing anything.
+type EntityCleanupReport struct {
+	EntityID string `json:"entity_id"`
+	DryRun   bool   `json:"dry_run"`
+	// Vendors counts the live vendors of the entity found by the cleanup
+	Vendors                   int `json:"vendors"`
+	Deleted                   int `json:"deleted"`
+	ScheduledChangesCancelled int `json:"scheduled_changes_cancelled"`
+}
+
+// EntityLifecycle is the lifecycle state of an entity and its log, newest
+// first
+type EntityLifecycle struct {
+	EntityID string                             `json:"entity_id"`
+	State    string                             `json:"state"`
+	Log      []*repository.EntityLifecycleEntry `json:"log"`
+}
+
+// checkEntityWritable rejects writes to an entity the platform suspended or
+// deleted. Writes without an entity are left to their own checks.
+func (s *VendorService) checkEntityWritable(ctx context.Context, entityID string) error {
+	if entityID == "" {
+		return nil
+	}
+	flags, err := s.entityFlags(ctx, entityID)
+	if err != nil {
+		return err
+	}
+	if flags.EntityState != "" {
+		return &EntityReadOnlyError{EntityID: entityID, State: flags.EntityState}
+	}
+	return nil
+}
+
+// GetEntityLifecycle retrieves the lifecycle state of an entity and its last
+// limit log entries
+func (s *VendorService) GetEntityLifecycle(ctx context.Context, entityID string, limit int) (*EntityLifecycle, error) {
+	if entityID == "" {
+		v := &validator{}
+		v.add("entity_id", "entity_id is required")
+		return nil, v.err()
+	}
+
+	state, err := s.vendorRepo.GetEntityState(ctx, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if state == "" {
+		state = "active"
+	}
+	entries, err := s.vendorRepo.ListEntityLifecycleEntries(ctx, entityID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &EntityLifecycle{EntityID: entityID, State: state, Log: entries}, nil
+}
+
+// CleanupEntity marks an entity deleted, soft-deletes all its vendors in
+// transactions of 100 vendors, releasing their external system mappings like
+// DeleteVendor, cancels its pending scheduled status changes, and logs a
+// summary. Unlike a bulk delete it does not skip vendors with an open
+// balance: the entity is gone. Running it again only picks up what is left.
+func (s *VendorService) CleanupEntity(ctx context.Context, entityID string, dryRun bool, actorID, eventID *string) (*EntityCleanupReport, error) {
+	if entityID == "" {
+		v := &validator{}
+		v.add("entity_id", "entity_id is required")
+		return nil, v.err()
+	}
+
+	reqlog.SetEntity(ctx, entityID)
+	ctx = repository.WithBulkBudget(repository.UsePrimary(ctx))
+	report := &EntityCleanupReport{EntityID: entityID, DryRun: dryRun}
+
+	if dryRun {
+		var afterID string
+		for {
+			vendors, err := s.vendorRepo.ListVendorBatch(ctx, entityID, afterID, entityCleanupChunk)
+			if err != nil {
+				return nil, err
+			}
+			if len(vendors) == 0 {
+				break
+			}
+			report.Vendors += len(vendors)
+			afterID = vendors[len(vendors)-1].ID
+		}
+		pending, err := s.vendorRepo.CountPendingStatusChanges(ctx, entityID)
+		if err != nil {
+			return nil, err
+		}
+		report.ScheduledChangesCancelled = pending
+		return report, nil
+	}
+
+	// Block writes first so no vendors appear behind the cleanup
+	if err := s.setEntityState(ctx, entityID, repository.EntityStateDeleted); err != nil {
+		return nil, err
+	}
+
+	var afterID string
+	for {
+		vendors, err := s.vendorRepo.ListVendorBatch(ctx, entityID, afterID, entityCleanupChunk)
+		if err != nil {
+			return nil, err
+		}
+		if len(vendors) == 0 {
+			break
+		}
+		err = s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+			for _, vendor := range vendors {
+				if err := repo.Delete(ctx, vendor.ID, entityID); err != nil {
+					return err
+				}
+				if err := repo.DeleteAllExternalRefs(ctx, vendor.ID, entityID); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		report.Vendors += len(vendors)
+		report.Deleted += len(vendors)
+		afterID = vendors[len(vendors)-1].ID
+	}
+
+	cancelled, err := s.vendorRepo.CancelPendingStatusChanges(ctx, entityID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	report.ScheduledChangesCancelled = cancelled
+
+	err = s.vendorRepo.InsertEntityLifecycleEntry(ctx, &repository.EntityLifecycleEntry{
+		EntityID: entityID,
+		Action:   EntityActionCleanup,
+		EventID:  eventID,
+		ActorID:  actorID,
+		Details: map[string]interface{}{
+			"vendors_deleted":             report.Deleted,
+			"scheduled_changes_cancelled": report.ScheduledChangesCancelled,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger(ctx).Info().
+		Str("entity_id", entityID).
+		Int("deleted", report.Deleted).
+		Int("scheduled_changes_cancelled", report.ScheduledChangesCancelled).
+		Msg("Entity cleaned up")
+
+	return report, nil
+}
+
+// setEntityState changes the state of an entity and drops its cached flags,
+// so this instance applies the change at once
+func (s *VendorService) setEntityState(ctx context.Context, entityID, state string) error {
+	if err := s.vendorRepo.SetEntityState(ctx, entityID, state); err != nil {
+		return err
+	}
+	s.flagsCache.invalidate(entityID)
+	return nil
+}
+
+// changeEntityState suspends or reactivates an entity and logs the change
+func (s *VendorService) changeEntityState(ctx context.Context, entityID, state, action string, eventID *string) error {
+	current, err := s.vendorRepo.GetEntityState(ctx, entityID)
+	if err != nil {
+		return err
+	}
+	if current == repository.EntityStateDeleted || current == state {
+		// Deleted entities stay deleted, and redelivered events change nothing
+		return nil
+	}
+
+	if err := s.setEntityState(ctx, entityID, state); err != nil {
+		return err
+	}
+	err = s.vendorRepo.InsertEntityLifecycleEntry(ctx, &repository.EntityLifecycleEntry{
+		EntityID: entityID,
+		Action:   action,
+		EventID:  eventID,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger(ctx).Info().Str("entity_id", entityID).Str("action", action).Msg("Entity state changed")
+	return nil
+}
+
+// HandleEntityEvent applies an entity lifecycle event: entity.deleted cleans
+// the entity up, entity.suspended makes it read-only and entity.reactivated
+// makes a suspended entity writable again. Other events are ignored.
+func (s *VendorService) HandleEntityEvent(ctx context.Context, event entityevents.Event) error {
+	if event.EntityID == "" {
+		s.logger(ctx).Warn().Str("event_id", event.ID).Str("type", event.Type).Msg("Entity event without entity ignored")
+		return nil
+	}
+
+	eventID := &event.ID
+	switch event.Type {
+	case entityevents.TypeDeleted:
+		_, err := s.CleanupEntity(ctx, event.EntityID, false, nil, eventID)
+		return err
+	case entityevents.TypeSuspended:
+		return s.changeEntityState(ctx, event.EntityID, repository.EntityStateSuspended, EntityActionSuspended, eventID)
+	case entityevents.TypeReactivated:
+		return s.changeEntityState(ctx, event.EntityID, "", EntityActionReactivated, eventID)
+	}
+	return nil
+}
+
+// ConsumeEntityEvents applies the entity lifecycle events of source after the
+// stored cursor until it is drained, returning how many it applied. The
+// cursor only advances past events that were applied, so a failed batch is
+// read again on the next run.
+func (s *VendorService) ConsumeEntityEvents(ctx context.Context, source entityevents.Source) (int, error) {
+	cursor, err := s.vendorRepo.GetConsumerCursor(ctx, entityEventsConsumer)
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for {
+		events, next, err := source.Poll(ctx, cursor)
+		if err != nil {
+			return applied, err
+		}
+		for _, event := range events {
+			if err := s.HandleEntityEvent(ctx, event); err != nil {
+				return applied, fmt.Errorf("apply %s event %s: %w", event.Type, event.ID, err)
+			}
+			applied++
+		}
+		if next == cursor {
+			return applied, nil
+		}
+		if err := s.vendorRepo.SetConsumerCursor(ctx, entityEventsConsumer, next); err != nil {
+			return applied, err
+		}
+		cursor = next
+		if len(events) == 0 {
+			return applied, nil
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// AuditActionVendorCodeRenamed is written when an admin changes the locked
+// code of an active vendor
+const AuditActionVendorCodeRenamed = "vendor_code_renamed"
+
+// VendorCodeLockedError is returned when an update changes the code of an
+// active vendor in an entity that locks vendor codes after activation
+type VendorCodeLockedError struct {
+	VendorID   string
+	VendorCode string
+}
+
+func (e *VendorCodeLockedError) Error() string {
+	return fmt.Sprintf("vendor code %s is locked because the vendor is active; an admin override is required to change it", e.VendorCode)
+}
+
+// GetEntitySettings retrieves the settings of an entity
+func (s *VendorService) GetEntitySettings(ctx context.Context, entityID string) (*repository.EntitySettings, error) {
+	return s.vendorRepo.GetEntitySettings(ctx, entityID)
+}
+
+// SetEntitySettings creates or replaces the settings of an entity
+func (s *VendorService) SetEntitySettings(ctx context.Context, settings *repository.EntitySettings) error {
+	if settings.EntityID == "" {
+		return errors.InvalidInput("entity_id", "entity_id is required")
+	}
+	if settings.Payer1099 != nil {
+		if err := validatePayer1099(settings.Payer1099, "payer_1099"); err != nil {
+			return err
+		}
+	}
+	v := &validator{}
+	validateOnboardingChecklist(v, settings.OnboardingChecklist)
+	if err := v.err(); err != nil {
+		return err
+	}
+
+	if err := s.vendorRepo.UpsertEntitySettings(ctx, settings); err != nil {
+		return err
+	}
+	s.flagsCache.invalidate(settings.EntityID)
+
+	reqlog.SetEntity(ctx, settings.EntityID)
+	s.logger(ctx).Info().Msg("Entity settings updated")
+
+	return nil
+}
+
+// checkVendorCodeLock rejects a code change of an active vendor when the
+// entity locks vendor codes after activation, unless override is set
+func (s *VendorService) checkVendorCodeLock(ctx context.Context, before, after *repository.Vendor, override bool) error {
+	if after.VendorCode == before.VendorCode || before.Status != "active" || override {
+		return nil
+	}
+
+	flags, err := s.entityFlags(ctx, before.EntityID)
+	if err != nil {
+		return err
+	}
+	if !flags.LockVendorCodeAfterActivation {
+		return nil
+	}
+	return &VendorCodeLockedError{VendorID: before.ID, VendorCode: before.VendorCode}
+}
+
+// recordLockedCodeRename keeps the old code of an active vendor renamed
+// through an admin override as an alias, so invoices and exports carrying it
+// still match, and audits the rename
+func (s *VendorService) recordLockedCodeRename(ctx context.Context, repo repository.Store, before, after *repository.Vendor, actorID *string) error {
+	if after.VendorCode == before.VendorCode || before.Status != "active" {
+		return nil
+	}
+
+	flags, err := s.entityFlags(ctx, before.EntityID)
+	if err != nil {
+		return err
+	}
+	if !flags.LockVendorCodeAfterActivation {
+		return nil
+	}
+
+	// A failed insert would abort the transaction, so an existing alias is
+	// looked up rather than tolerated as a conflict
+	aliases, err := repo.ListVendorAliases(ctx, before.ID)
+	if err != nil {
+		return err
+	}
+	kept := slices.ContainsFunc(aliases, func(a *repository.VendorAlias) bool {
+		return repository.NormalizeName(a.Alias) == repository.NormalizeName(before.VendorCode)
+	})
+	if !kept {
+		err := repo.InsertVendorAlias(ctx, &repository.VendorAlias{
+			VendorID:  before.ID,
+			Alias:     before.VendorCode,
+			CreatedBy: actorID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return repo.InsertAuditEntry(ctx, &repository.AuditEntry{
+		EntityID: before.EntityID,
+		VendorID: before.ID,
+		Action:   AuditActionVendorCodeRenamed,
+		ActorID:  actorID,
+		Details: map[string]interface{}{
+			"from":     before.VendorCode,
+			"to":       after.VendorCode,
+			"override": true,
+		},
+	})
+}
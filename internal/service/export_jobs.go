@@ -0,0 +1,195 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// Export job states
+const (
+	ExportJobPending   = "pending"
+	ExportJobRunning   = "running"
+	ExportJobCompleted = "completed"
+	ExportJobFailed    = "failed"
+)
+
+const (
+	// exportJobTTL is how long a finished export job and its file are kept
+	exportJobTTL = time.Hour
+	// maxRunningExportJobs is how many export jobs generate at once; the
+	// others wait as pending
+	maxRunningExportJobs = 2
+)
+
+// ExportJob is an export generated in the background. 1099-NEC jobs are held
+// in the memory of the instance that started them and dropped an hour after
+// they finish; vendor export jobs are stored and generated by the worker.
+type ExportJob struct {
+	ID       string `json:"id"`
+	EntityID string `json:"entity_id"`
+	Kind     string `json:"kind"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	// Summary describes the finished export, e.g. its record counts
+	Summary     interface{} `json:"summary,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	CompletedAt *time.Time  `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time  `json:"expires_at,omitempty"`
+
+	// Progress of vendor exports, in vendors
+	RowsTotal   *int64 `json:"rows_total,omitempty"`
+	RowsWritten *int64 `json:"rows_written,omitempty"`
+	// DownloadURL is the time-limited link to the file of a completed vendor
+	// export, built by the handler from Link
+	DownloadURL string      `json:"download_url,omitempty"`
+	Link        *ExportLink `json:"-"`
+
+	file     []byte
+	fileName string
+}
+
+// exportJobs runs export jobs in the background and holds their files until
+// they expire
+type exportJobs struct {
+	mu    sync.Mutex
+	jobs  map[string]*ExportJob
+	slots chan struct{}
+}
+
+func newExportJobs() *exportJobs {
+	return &exportJobs{
+		jobs:  make(map[string]*ExportJob),
+		slots: make(chan struct{}, maxRunningExportJobs),
+	}
+}
+
+// exportRun generates an export, returning its summary, file and file name
+type exportRun func(ctx context.Context) (summary interface{}, file []byte, fileName string, err error)
+
+// start registers a job and runs it in the background. The job outlives the
+// request that started it: ctx only lends it its values.
+func (j *exportJobs) start(ctx context.Context, entityID, kind string, run exportRun) (*ExportJob, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, fmt.Errorf("generate export job id: %w", err)
+	}
+	job := &ExportJob{
+		ID:        hex.EncodeToString(b[:]),
+		EntityID:  entityID,
+		Kind:      kind,
+		Status:    ExportJobPending,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	j.mu.Lock()
+	j.prune(job.CreatedAt)
+	j.jobs[job.ID] = job
+	snapshot := *job
+	j.mu.Unlock()
+
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		j.slots <- struct{}{}
+		defer func() { <-j.slots }()
+
+		j.update(job.ID, func(job *ExportJob) { job.Status = ExportJobRunning })
+		summary, file, fileName, err := run(ctx)
+		j.update(job.ID, func(job *ExportJob) {
+			now := time.Now().UTC()
+			expiresAt := now.Add(exportJobTTL)
+			job.CompletedAt = &now
+			job.ExpiresAt = &expiresAt
+			if err != nil {
+				job.Status = ExportJobFailed
+				job.Error = err.Error()
+				return
+			}
+			job.Status = ExportJobCompleted
+			job.Summary = summary
+			job.file = file
+			job.fileName = fileName
+		})
+	}()
+
+	return &snapshot, nil
+}
+
+// update applies fn to a job under the lock
+func (j *exportJobs) update(id string, fn func(*ExportJob)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if job, ok := j.jobs[id]; ok {
+		fn(job)
+	}
+}
+
+// get returns a copy of a job of an entity
+func (j *exportJobs) get(id, entityID string) (*ExportJob, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.prune(time.Now().UTC())
+	job, ok := j.jobs[id]
+	if !ok || job.EntityID != entityID {
+		return nil, &repository.NotFoundError{Resource: "export_job", ID: id, Err: errors.NotFound("export_job", id)}
+	}
+	snapshot := *job
+	return &snapshot, nil
+}
+
+// prune drops the jobs expired at now; callers hold the lock
+func (j *exportJobs) prune(now time.Time) {
+	for id, job := range j.jobs {
+		if job.ExpiresAt != nil && !now.Before(*job.ExpiresAt) {
+			delete(j.jobs, id)
+		}
+	}
+}
+
+// GetExportJob retrieves an export job of an entity, held in memory or, for
+// vendor exports, stored
+func (s *VendorService) GetExportJob(ctx context.Context, id, entityID string) (*ExportJob, error) {
+	job, err := s.exportJobs.get(id, entityID)
+	if !repository.IsNotFound(err) || !IsUUID(id) {
+		return job, err
+	}
+
+	stored, err := s.vendorRepo.GetExportJob(repository.UsePrimary(ctx), id, entityID)
+	if err != nil {
+		return nil, err
+	}
+	return s.vendorExportJob(stored), nil
+}
+
+// GetExportJobFile opens the file of a completed export job of an entity. A
+// job not completed yet fails validation; vendor exports also need the
+// signed link of the job.
+func (s *VendorService) GetExportJobFile(ctx context.Context, id, entityID string, link *ExportLink) (*ExportFile, error) {
+	job, err := s.exportJobs.get(id, entityID)
+	if repository.IsNotFound(err) && IsUUID(id) {
+		return s.openVendorExportFile(repository.UsePrimary(ctx), id, entityID, link)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	v := &validator{}
+	v.check(job.Status == ExportJobCompleted, "status", fmt.Sprintf("export job is %s, not completed", job.Status))
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return &ExportFile{
+		Name:        job.fileName,
+		ContentType: "application/zip",
+		Body:        io.NopCloser(bytes.NewReader(job.file)),
+	}, nil
+}
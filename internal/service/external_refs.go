@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// externalSystemPattern restricts external system names to short lowercase identifiers
+var externalSystemPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,49}$`)
+
+// normalizeExternalSystem lowercases and validates an external system name
+func normalizeExternalSystem(system string) (string, error) {
+	system = strings.ToLower(strings.TrimSpace(system))
+	if !externalSystemPattern.MatchString(system) {
+		return "", errors.InvalidInput("system", "system must be 1-50 lowercase letters, digits, '.', '_' or '-' (e.g. quickbooks)")
+	}
+	return system, nil
+}
+
+// SetExternalRef maps a vendor to its ID in an external system, replacing any
+// previous mapping of the vendor for that system
+func (s *VendorService) SetExternalRef(ctx context.Context, vendorID, entityID, system, externalID, createdBy string) (*repository.VendorExternalRef, error) {
+	system, err := normalizeExternalSystem(system)
+	if err != nil {
+		return nil, err
+	}
+
+	externalID = strings.TrimSpace(externalID)
+	if externalID == "" || len(externalID) > 255 {
+		return nil, errors.InvalidInput("external_id", "external_id is required and must be at most 255 characters")
+	}
+
+	// Ensure the vendor belongs to the entity before mapping it
+	ctx = repository.UsePrimary(ctx)
+	if err := s.checkEntityWritable(ctx, entityID); err != nil {
+		return nil, err
+	}
+	if _, err := s.vendorRepo.GetByID(ctx, vendorID, entityID); err != nil {
+		return nil, err
+	}
+
+	ref := &repository.VendorExternalRef{
+		VendorID:   vendorID,
+		EntityID:   entityID,
+		System:     system,
+		ExternalID: externalID,
+	}
+	if createdBy != "" {
+		ref.CreatedBy = &createdBy
+	}
+
+	if err := s.vendorRepo.SetExternalRef(ctx, ref); err != nil {
+		return nil, err
+	}
+
+	reqlog.SetEntity(ctx, entityID)
+	reqlog.SetVendor(ctx, vendorID)
+	s.logger(ctx).Info().
+		Str("system", system).
+		Str("external_id", externalID).
+		Msg("Vendor external ref set")
+
+	return ref, nil
+}
+
+// GetExternalRefs retrieves all external refs of a vendor, failing with not
+// found for vendors missing from the entity
+func (s *VendorService) GetExternalRefs(ctx context.Context, vendorID, entityID string) ([]*repository.VendorExternalRef, error) {
+	if _, err := s.vendorRepo.GetByID(ctx, vendorID, entityID); err != nil {
+		return nil, err
+	}
+	return s.vendorRepo.GetExternalRefs(ctx, vendorID, entityID)
+}
+
+// DeleteExternalRef removes a vendor's mapping for an external system
+func (s *VendorService) DeleteExternalRef(ctx context.Context, vendorID, entityID, system string) error {
+	system, err := normalizeExternalSystem(system)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkEntityWritable(ctx, entityID); err != nil {
+		return err
+	}
+	if err := s.vendorRepo.DeleteExternalRef(ctx, vendorID, entityID, system); err != nil {
+		return err
+	}
+
+	reqlog.SetEntity(ctx, entityID)
+	reqlog.SetVendor(ctx, vendorID)
+	s.logger(ctx).Info().
+		Str("system", system).
+		Msg("Vendor external ref deleted")
+
+	return nil
+}
+
+// GetVendorByExternalRef retrieves the vendor mapped to an external system ID
+func (s *VendorService) GetVendorByExternalRef(ctx context.Context, entityID, system, externalID string) (*repository.Vendor, error) {
+	system, err := normalizeExternalSystem(system)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.vendorRepo.GetByExternalRef(ctx, entityID, system, strings.TrimSpace(externalID))
+}
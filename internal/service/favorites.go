@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+// maxRecentVendors is how many recently viewed vendors are kept per user and entity
+const maxRecentVendors = 25
+
+// ToggleFavoriteVendor adds a vendor to the favorites of a user in an entity,
+// or removes it when it is one already, and reports whether it is now a
+// favorite
+func (s *VendorService) ToggleFavoriteVendor(ctx context.Context, userID, entityID, vendorID string) (bool, error) {
+	v := &validator{}
+	v.check(userID != "", "user_id", "user_id is required")
+	v.check(entityID != "", "entity_id", "entity_id is required")
+	if err := v.err(); err != nil {
+		return false, err
+	}
+
+	if _, err := s.vendorRepo.GetByID(ctx, vendorID, entityID); err != nil {
+		return false, err
+	}
+	favorite, err := s.vendorRepo.ToggleFavoriteVendor(ctx, userID, entityID, vendorID)
+	if err != nil {
+		return false, err
+	}
+
+	s.logger(ctx).Info().
+		Str("vendor_id", vendorID).
+		Str("user_id", userID).
+		Bool("favorite", favorite).
+		Msg("Vendor favorite toggled")
+
+	return favorite, nil
+}
+
+// ListFavoriteVendors retrieves the favorite vendors of a user in an entity
+// ordered by vendor name
+func (s *VendorService) ListFavoriteVendors(ctx context.Context, userID, entityID string) ([]*repository.Vendor, error) {
+	vendors, err := s.vendorRepo.ListFavoriteVendors(ctx, userID, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachRiskScores(ctx, vendors...); err != nil {
+		return nil, err
+	}
+	return vendors, nil
+}
+
+// RecordVendorView adds a vendor to the recently viewed vendors of a user in
+// an entity. Views are a convenience, so failures are logged rather than
+// failing the read that triggered them.
+func (s *VendorService) RecordVendorView(ctx context.Context, userID, entityID, vendorID string) {
+	if userID == "" || entityID == "" {
+		return
+	}
+	if err := s.vendorRepo.RecordVendorView(ctx, userID, entityID, vendorID, maxRecentVendors); err != nil {
+		s.logger(ctx).Warn().Err(err).Str("vendor_id", vendorID).Msg("Failed to record vendor view")
+	}
+}
+
+// ListRecentVendors retrieves the vendors a user last viewed in an entity,
+// most recently viewed first
+func (s *VendorService) ListRecentVendors(ctx context.Context, userID, entityID string) ([]*repository.Vendor, error) {
+	vendors, err := s.vendorRepo.ListRecentVendors(ctx, userID, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachRiskScores(ctx, vendors...); err != nil {
+		return nil, err
+	}
+	return vendors, nil
+}
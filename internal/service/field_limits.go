@@ -0,0 +1,66 @@
+package service
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+// maxNotesLength limits notes, which have no column size of their own. Tags
+// are limited in tags.go.
+const maxNotesLength = 10000
+
+// checkLength records a violation when value is longer than max characters
+func checkLength(v *validator, field, value string, max int) {
+	v.check(utf8.RuneCountInString(value) <= max, field,
+		fmt.Sprintf("%s must be at most %d characters", field, max))
+}
+
+// checkOptionalLength is checkLength for optional fields
+func checkOptionalLength(v *validator, field string, value *string, max int) {
+	if value != nil {
+		checkLength(v, field, *value, max)
+	}
+}
+
+// checkVendorFieldLengths validates the text fields of a vendor against the
+// vendors column sizes, and notes against the limit above, so that
+// oversized values fail with a clear message rather than a database error
+func checkVendorFieldLengths(v *validator, vendor *repository.Vendor) {
+	checkLength(v, "vendor_code", vendor.VendorCode, 50)
+	checkLength(v, "vendor_name", vendor.VendorName, 255)
+	checkOptionalLength(v, "legal_name", vendor.LegalName, 255)
+	checkOptionalLength(v, "doing_business_as", vendor.DoingBusinessAs, 255)
+	checkOptionalLength(v, "tax_id", vendor.TaxID, 50)
+	checkOptionalLength(v, "email", vendor.Email, 255)
+	checkOptionalLength(v, "remittance_email", vendor.RemittanceEmail, 255)
+	checkOptionalLength(v, "phone", vendor.Phone, 50)
+	checkOptionalLength(v, "fax", vendor.Fax, 50)
+	checkOptionalLength(v, "website", vendor.Website, 255)
+	checkOptionalLength(v, "address_line1", vendor.AddressLine1, 255)
+	checkOptionalLength(v, "address_line2", vendor.AddressLine2, 255)
+	checkOptionalLength(v, "city", vendor.City, 100)
+	checkOptionalLength(v, "state_province", vendor.StateProvince, 100)
+	checkOptionalLength(v, "postal_code", vendor.PostalCode, 20)
+	checkLength(v, "payment_terms", vendor.PaymentTerms, 50)
+	checkOptionalLength(v, "bank_name", vendor.BankName, 255)
+	checkOptionalLength(v, "bank_account_number", vendor.BankAccountNumber, 100)
+	checkOptionalLength(v, "bank_routing_number", vendor.BankRoutingNumber, 50)
+	checkOptionalLength(v, "swift_code", vendor.SwiftCode, 20)
+	checkOptionalLength(v, "iban", vendor.IBAN, 50)
+	checkOptionalLength(v, "notes", vendor.Notes, maxNotesLength)
+}
+
+// checkContactFieldLengths validates the text fields of a contact against the
+// vendor_contacts column sizes. fieldPrefix qualifies field names as in
+// newContact.
+func checkContactFieldLengths(v *validator, contact *repository.VendorContact, fieldPrefix string) {
+	checkLength(v, fieldPrefix+"first_name", contact.FirstName, 100)
+	checkLength(v, fieldPrefix+"last_name", contact.LastName, 100)
+	checkOptionalLength(v, fieldPrefix+"title", contact.Title, 100)
+	checkOptionalLength(v, fieldPrefix+"email", contact.Email, 255)
+	checkOptionalLength(v, fieldPrefix+"phone", contact.Phone, 50)
+	checkOptionalLength(v, fieldPrefix+"mobile", contact.Mobile, 50)
+	checkOptionalLength(v, fieldPrefix+"notes", contact.Notes, maxNotesLength)
+}
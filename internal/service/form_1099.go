@@ -0,0 +1,458 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+)
+
+const (
+	// form1099BatchSize is how many vendors a 1099 export reads per query
+	form1099BatchSize = 1000
+	// maxForm1099Payments caps the payment lines of one 1099 export request
+	maxForm1099Payments = 50000
+	// form1099ThresholdChangeYear is the first tax year with the $2,000
+	// reporting threshold; earlier years use $600
+	form1099ThresholdChangeYear = 2026
+)
+
+// Reasons a paid vendor is excluded from a 1099-NEC export: missing data, or
+// being a probable test vendor when the request excludes those
+const (
+	Form1099ExcludedNotFound       = "vendor_not_found"
+	Form1099ExcludedMissingTIN     = "missing_tin"
+	Form1099ExcludedInvalidTIN     = "invalid_tin"
+	Form1099ExcludedMissingName    = "missing_legal_name"
+	Form1099ExcludedMissingAddress = "missing_address"
+	Form1099ExcludedSuspectedTest  = "suspected_test_vendor"
+)
+
+// Form1099RecordColumns is the header of the provider CSV of 1099-NEC records
+var Form1099RecordColumns = []string{
+	"tax_year",
+	"payer_tin", "payer_name", "payer_address_line1", "payer_address_line2",
+	"payer_city", "payer_state", "payer_postal_code", "payer_phone",
+	"payee_tin", "payee_name", "payee_second_name", "payee_address_line1",
+	"payee_address_line2", "payee_city", "payee_state", "payee_postal_code",
+	"payee_country", "account_number", "box1_nonemployee_compensation",
+	"box4_federal_income_tax_withheld",
+}
+
+// Form1099ExclusionColumns is the header of the companion error CSV listing
+// the vendors left out of a 1099-NEC export
+var Form1099ExclusionColumns = []string{"vendor_id", "vendor_code", "vendor_name", "reasons"}
+
+// Form1099Payment is what an entity paid a vendor in a tax year, in cents
+type Form1099Payment struct {
+	VendorID string `json:"vendor_id"`
+	// NonemployeeCompensation is reported in box 1
+	NonemployeeCompensation int64 `json:"nonemployee_compensation"`
+	// FederalTaxWithheld is reported in box 4
+	FederalTaxWithheld int64 `json:"federal_tax_withheld,omitempty"`
+}
+
+// Form1099Request asks for the 1099-NEC records of an entity for a tax year
+type Form1099Request struct {
+	EntityID string `json:"entity_id"`
+	TaxYear  int    `json:"tax_year"`
+	// Threshold is the minimum box 1 amount in cents reported; nil uses the
+	// IRS threshold of the tax year
+	Threshold *int64            `json:"threshold,omitempty"`
+	Payments  []Form1099Payment `json:"payments"`
+	// ExcludeSuspectedTest excludes paid vendors flagged as probable test
+	// vendors instead of reporting them
+	ExcludeSuspectedTest bool `json:"exclude_suspected_test,omitempty"`
+}
+
+// Form1099Record is one 1099-NEC form
+type Form1099Record struct {
+	VendorID      string `json:"vendor_id"`
+	VendorCode    string `json:"vendor_code"`
+	PayeeTIN      string `json:"payee_tin"`
+	PayeeName     string `json:"payee_name"`
+	PayeeDBA      string `json:"payee_second_name,omitempty"`
+	PayeeAddress1 string `json:"payee_address_line1"`
+	PayeeAddress2 string `json:"payee_address_line2,omitempty"`
+	PayeeCity     string `json:"payee_city"`
+	PayeeState    string `json:"payee_state"`
+	PayeePostal   string `json:"payee_postal_code"`
+	PayeeCountry  string `json:"payee_country"`
+	Box1          int64  `json:"box1_nonemployee_compensation"`
+	Box4          int64  `json:"box4_federal_income_tax_withheld"`
+	// TINMatch is the TIN matching status of the payee's TIN and name
+	TINMatch string `json:"tin_match_status"`
+}
+
+// Form1099Exclusion is a paid vendor left out of a 1099-NEC export
+type Form1099Exclusion struct {
+	VendorID   string   `json:"vendor_id"`
+	VendorCode string   `json:"vendor_code,omitempty"`
+	VendorName string   `json:"vendor_name,omitempty"`
+	Reasons    []string `json:"reasons"`
+}
+
+// Form1099Export is the 1099-NEC report of an entity for a tax year: the
+// records to file and the paid vendors excluded for missing data. Skipped
+// counts the paid vendors not reportable: not 1099 vendors, or paid less than
+// the threshold without withholding.
+type Form1099Export struct {
+	EntityID  string                `json:"entity_id"`
+	TaxYear   int                   `json:"tax_year"`
+	Threshold int64                 `json:"threshold"`
+	Payer     *repository.Payer1099 `json:"payer"`
+	Records   []*Form1099Record     `json:"records"`
+	Excluded  []*Form1099Exclusion  `json:"excluded"`
+	Skipped   int                   `json:"skipped"`
+}
+
+// Form1099Threshold returns the minimum box 1 amount in cents reported on a
+// 1099-NEC for a tax year
+func Form1099Threshold(taxYear int) int64 {
+	if taxYear >= form1099ThresholdChangeYear {
+		return 200000
+	}
+	return 60000
+}
+
+// GenerateForm1099 builds the 1099-NEC records of an entity for a tax year
+// from the payments supplied, with the payer taken from the entity settings
+// and the payees from the vendors. Payments to the same vendor are summed.
+// Reportable vendors missing a valid TIN, legal name or address are excluded
+// with their reasons rather than failing the export.
+func (s *VendorService) GenerateForm1099(ctx context.Context, req *Form1099Request) (*Form1099Export, error) {
+	if err := validateForm1099Request(req); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.vendorRepo.GetEntitySettings(ctx, req.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePayer1099(settings.Payer1099, "payer_1099"); err != nil {
+		return nil, err
+	}
+
+	export := &Form1099Export{
+		EntityID:  req.EntityID,
+		TaxYear:   req.TaxYear,
+		Threshold: Form1099Threshold(req.TaxYear),
+		Payer:     settings.Payer1099,
+		Records:   []*Form1099Record{},
+		Excluded:  []*Form1099Exclusion{},
+	}
+	if req.Threshold != nil {
+		export.Threshold = *req.Threshold
+	}
+
+	totals := make(map[string]*Form1099Payment, len(req.Payments))
+	for _, p := range req.Payments {
+		total, ok := totals[p.VendorID]
+		if !ok {
+			total = &Form1099Payment{VendorID: p.VendorID}
+			totals[p.VendorID] = total
+		}
+		total.NonemployeeCompensation += p.NonemployeeCompensation
+		total.FederalTaxWithheld += p.FederalTaxWithheld
+	}
+
+	ctx = repository.WithBulkBudget(ctx)
+	var afterID string
+	for {
+		vendors, err := s.vendorRepo.ListVendorBatch(ctx, req.EntityID, afterID, form1099BatchSize)
+		if err != nil {
+			return nil, err
+		}
+		var paid []string
+		for _, vendor := range vendors {
+			if _, ok := totals[vendor.ID]; ok {
+				paid = append(paid, vendor.ID)
+			}
+		}
+		matches, err := s.vendorRepo.ListTINMatches(ctx, paid)
+		if err != nil {
+			return nil, err
+		}
+		for _, vendor := range vendors {
+			total, ok := totals[vendor.ID]
+			if !ok {
+				continue
+			}
+			delete(totals, vendor.ID)
+			if req.ExcludeSuspectedTest && repository.IsSuspectedTestVendor(vendor) {
+				export.Excluded = append(export.Excluded, &Form1099Exclusion{
+					VendorID:   vendor.ID,
+					VendorCode: vendor.VendorCode,
+					VendorName: vendor.VendorName,
+					Reasons:    []string{Form1099ExcludedSuspectedTest},
+				})
+				continue
+			}
+			export.add(vendor, total, tinMatchStatus(vendor, matches[vendor.ID]))
+		}
+		if len(vendors) < form1099BatchSize {
+			break
+		}
+		afterID = vendors[len(vendors)-1].ID
+	}
+
+	// Payments left over name vendors that are deleted or of another entity
+	for _, total := range totals {
+		export.Excluded = append(export.Excluded, &Form1099Exclusion{
+			VendorID: total.VendorID,
+			Reasons:  []string{Form1099ExcludedNotFound},
+		})
+	}
+	slices.SortFunc(export.Excluded, func(a, b *Form1099Exclusion) int {
+		return strings.Compare(a.VendorID, b.VendorID)
+	})
+
+	reqlog.SetEntity(ctx, req.EntityID)
+	s.logger(ctx).Info().
+		Int("tax_year", req.TaxYear).
+		Int("records", len(export.Records)).
+		Int("excluded", len(export.Excluded)).
+		Int("skipped", export.Skipped).
+		Msg("1099-NEC export generated")
+
+	return export, nil
+}
+
+// validateForm1099Request checks the fields of a 1099-NEC export request
+func validateForm1099Request(req *Form1099Request) error {
+	v := &validator{}
+	v.check(req.EntityID != "", "entity_id", "entity_id is required")
+	v.check(req.TaxYear >= 2000 && req.TaxYear <= time.Now().UTC().Year(), "tax_year",
+		"tax_year must be a year from 2000 to the current year")
+	v.check(req.Threshold == nil || *req.Threshold >= 0, "threshold", "threshold must not be negative")
+	v.check(len(req.Payments) > 0, "payments", "payments are required")
+	v.check(len(req.Payments) <= maxForm1099Payments, "payments",
+		fmt.Sprintf("at most %d payments are allowed", maxForm1099Payments))
+	for i, p := range req.Payments {
+		field := fmt.Sprintf("payments[%d]", i)
+		v.check(p.VendorID != "", field+".vendor_id", "vendor_id is required")
+		v.check(p.NonemployeeCompensation >= 0, field+".nonemployee_compensation", "nonemployee_compensation must not be negative")
+		v.check(p.FederalTaxWithheld >= 0, field+".federal_tax_withheld", "federal_tax_withheld must not be negative")
+	}
+	return v.err()
+}
+
+// add adds the record of a paid vendor to the export, or its exclusion
+func (e *Form1099Export) add(vendor *repository.Vendor, total *Form1099Payment, tinMatchStatus string) {
+	// Backup withholding is reported whatever the amount paid
+	if !vendor.Is1099Vendor || (total.NonemployeeCompensation < e.Threshold && total.FederalTaxWithheld == 0) {
+		e.Skipped++
+		return
+	}
+
+	value := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return strings.TrimSpace(*s)
+	}
+
+	var reasons []string
+	tin := normalizeTIN(value(vendor.TaxID))
+	switch {
+	case tin == "":
+		reasons = append(reasons, Form1099ExcludedMissingTIN)
+	case !isDigits(tin, 9):
+		reasons = append(reasons, Form1099ExcludedInvalidTIN)
+	}
+	if value(vendor.LegalName) == "" {
+		reasons = append(reasons, Form1099ExcludedMissingName)
+	}
+	if value(vendor.AddressLine1) == "" || value(vendor.City) == "" ||
+		value(vendor.StateProvince) == "" || value(vendor.PostalCode) == "" {
+		reasons = append(reasons, Form1099ExcludedMissingAddress)
+	}
+	if len(reasons) > 0 {
+		e.Excluded = append(e.Excluded, &Form1099Exclusion{
+			VendorID:   vendor.ID,
+			VendorCode: vendor.VendorCode,
+			VendorName: vendor.VendorName,
+			Reasons:    reasons,
+		})
+		return
+	}
+
+	dba := value(vendor.DoingBusinessAs)
+	if dba == value(vendor.LegalName) {
+		dba = ""
+	}
+	e.Records = append(e.Records, &Form1099Record{
+		VendorID:      vendor.ID,
+		VendorCode:    vendor.VendorCode,
+		PayeeTIN:      tin,
+		PayeeName:     value(vendor.LegalName),
+		PayeeDBA:      dba,
+		PayeeAddress1: value(vendor.AddressLine1),
+		PayeeAddress2: value(vendor.AddressLine2),
+		PayeeCity:     value(vendor.City),
+		PayeeState:    value(vendor.StateProvince),
+		PayeePostal:   value(vendor.PostalCode),
+		PayeeCountry:  vendor.Country,
+		Box1:          total.NonemployeeCompensation,
+		Box4:          total.FederalTaxWithheld,
+		TINMatch:      tinMatchStatus,
+	})
+}
+
+// validatePayer1099 checks that a payer has everything printed on a 1099
+func validatePayer1099(payer *repository.Payer1099, field string) error {
+	v := &validator{}
+	if payer == nil {
+		v.add(field, "the entity settings have no 1099 payer")
+		return v.err()
+	}
+	v.check(strings.TrimSpace(payer.Name) != "", field+".name", "name is required")
+	v.check(isDigits(normalizeTIN(payer.TIN), 9), field+".tin", "tin must be 9 digits")
+	v.check(strings.TrimSpace(payer.AddressLine1) != "", field+".address_line1", "address_line1 is required")
+	v.check(strings.TrimSpace(payer.City) != "", field+".city", "city is required")
+	v.check(strings.TrimSpace(payer.State) != "", field+".state", "state is required")
+	v.check(strings.TrimSpace(payer.PostalCode) != "", field+".postal_code", "postal_code is required")
+	return v.err()
+}
+
+// normalizeTIN strips the dashes and spaces of an EIN or SSN
+func normalizeTIN(tin string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(tin))
+}
+
+// formatCents formats an amount in cents as dollars with two decimals
+func formatCents(cents int64) string {
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
+
+// WriteForm1099RecordsCSV writes the records of an export as the provider CSV
+// with the Form1099RecordColumns header
+func WriteForm1099RecordsCSV(w io.Writer, export *Form1099Export) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(Form1099RecordColumns); err != nil {
+		return err
+	}
+
+	payer := export.Payer
+	for _, r := range export.Records {
+		if err := cw.Write([]string{
+			strconv.Itoa(export.TaxYear),
+			normalizeTIN(payer.TIN), payer.Name, payer.AddressLine1, payer.AddressLine2,
+			payer.City, payer.State, payer.PostalCode, payer.Phone,
+			r.PayeeTIN, r.PayeeName, r.PayeeDBA, r.PayeeAddress1,
+			r.PayeeAddress2, r.PayeeCity, r.PayeeState, r.PayeePostal,
+			r.PayeeCountry, r.VendorCode, formatCents(r.Box1),
+			formatCents(r.Box4),
+		}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteForm1099ExclusionsCSV writes the vendors left out of an export as CSV
+// with the Form1099ExclusionColumns header; reasons are separated by "|"
+func WriteForm1099ExclusionsCSV(w io.Writer, export *Form1099Export) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(Form1099ExclusionColumns); err != nil {
+		return err
+	}
+
+	for _, e := range export.Excluded {
+		if err := cw.Write([]string{
+			e.VendorID,
+			e.VendorCode,
+			e.VendorName,
+			strings.Join(e.Reasons, "|"),
+		}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteForm1099Archive writes an export as a zip of the provider CSV and the
+// companion error CSV
+func WriteForm1099Archive(w io.Writer, export *Form1099Export) error {
+	zw := zip.NewWriter(w)
+	files := []struct {
+		name  string
+		write func(io.Writer, *Form1099Export) error
+	}{
+		{fmt.Sprintf("1099-nec-%d.csv", export.TaxYear), WriteForm1099RecordsCSV},
+		{fmt.Sprintf("1099-nec-%d-errors.csv", export.TaxYear), WriteForm1099ExclusionsCSV},
+	}
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if err := f.write(fw, export); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// ExportKindForm1099 is the kind of 1099-NEC export jobs
+const ExportKindForm1099 = "1099_nec"
+
+// Form1099Summary is the summary of a finished 1099-NEC export job
+type Form1099Summary struct {
+	TaxYear  int `json:"tax_year"`
+	Records  int `json:"records"`
+	Excluded int `json:"excluded"`
+	Skipped  int `json:"skipped"`
+}
+
+// StartForm1099Export validates a 1099-NEC export request and generates the
+// export archive in the background; poll the returned job for its file
+func (s *VendorService) StartForm1099Export(ctx context.Context, req *Form1099Request) (*ExportJob, error) {
+	if err := validateForm1099Request(req); err != nil {
+		return nil, err
+	}
+	settings, err := s.vendorRepo.GetEntitySettings(ctx, req.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePayer1099(settings.Payer1099, "payer_1099"); err != nil {
+		return nil, err
+	}
+
+	return s.exportJobs.start(ctx, req.EntityID, ExportKindForm1099, func(ctx context.Context) (interface{}, []byte, string, error) {
+		export, err := s.GenerateForm1099(ctx, req)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		var buf bytes.Buffer
+		if err := WriteForm1099Archive(&buf, export); err != nil {
+			return nil, nil, "", err
+		}
+		summary := &Form1099Summary{
+			TaxYear:  export.TaxYear,
+			Records:  len(export.Records),
+			Excluded: len(export.Excluded),
+			Skipped:  export.Skipped,
+		}
+		return summary, buf.Bytes(), Form1099ArchiveName(export.TaxYear), nil
+	})
+}
+
+// Form1099ArchiveName is the file name of the 1099-NEC export archive of a
+// tax year
+func Form1099ArchiveName(taxYear int) string {
+	return fmt.Sprintf("1099-nec-%d.zip", taxYear)
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+)
+
+// vendorStatuses are the statuses of the vendor_status enum
+var vendorStatuses = []string{"active", "inactive", "suspended", "pending_approval"}
+
+// GeographyQuery selects the vendors of the geography report of an entity
+type GeographyQuery struct {
+	EntityID   string
+	Status     *string
+	VendorType *string
+	// IncludeBalances adds the summed current balances, by currency
+	IncludeBalances bool
+}
+
+// GeographyReport counts the live vendors of an entity by country and state
+// or province, e.g. for supply-chain risk reviews. Balances are in minor
+// units by currency, and only set when requested.
+type GeographyReport struct {
+	EntityID   string           `json:"entity_id"`
+	Status     *string          `json:"status,omitempty"`
+	VendorType *string          `json:"vendor_type,omitempty"`
+	Vendors    int64            `json:"vendors"`
+	Balances   map[string]int64 `json:"balances,omitempty"`
+	Countries  []*CountryCount  `json:"countries"`
+}
+
+// CountryCount is a country of the geography report. Vendors without a
+// country are counted under repository.UnknownCountry.
+type CountryCount struct {
+	Country  string           `json:"country"`
+	Vendors  int64            `json:"vendors"`
+	Balances map[string]int64 `json:"balances,omitempty"`
+	States   []*StateCount    `json:"states"`
+}
+
+// StateCount is a state or province of a country of the geography report;
+// StateProvince is nil for vendors without one
+type StateCount struct {
+	StateProvince *string          `json:"state_province"`
+	Vendors       int64            `json:"vendors"`
+	Balances      map[string]int64 `json:"balances,omitempty"`
+}
+
+// GetGeographyReport counts the live vendors of an entity by country and
+// state or province. The counts and sums are computed by the store; countries
+// and states are ordered by vendors, most first, with the unknown country and
+// vendors without a state last.
+func (s *VendorService) GetGeographyReport(ctx context.Context, q GeographyQuery) (*GeographyReport, error) {
+	reqlog.SetEntity(ctx, q.EntityID)
+
+	v := &validator{}
+	v.check(q.EntityID != "", "entity_id", "entity_id is required")
+	if q.Status != nil {
+		v.check(slices.Contains(vendorStatuses, *q.Status), "status",
+			fmt.Sprintf("status must be one of %s", strings.Join(vendorStatuses, ", ")))
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	counts, err := s.vendorRepo.CountVendorsByRegion(ctx, repository.VendorFilter{
+		EntityID:   q.EntityID,
+		Status:     q.Status,
+		VendorType: q.VendorType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &GeographyReport{
+		EntityID:   q.EntityID,
+		Status:     q.Status,
+		VendorType: q.VendorType,
+		Countries:  make([]*CountryCount, 0),
+	}
+	countries := make(map[string]*CountryCount)
+	states := make(map[[2]string]*StateCount)
+	for _, count := range counts {
+		country, ok := countries[count.Country]
+		if !ok {
+			country = &CountryCount{Country: count.Country, States: make([]*StateCount, 0)}
+			countries[count.Country] = country
+			report.Countries = append(report.Countries, country)
+		}
+
+		key := [2]string{count.Country, ""}
+		if count.StateProvince != nil {
+			key[1] = *count.StateProvince
+		}
+		state, ok := states[key]
+		if !ok {
+			state = &StateCount{StateProvince: count.StateProvince}
+			states[key] = state
+			country.States = append(country.States, state)
+		}
+
+		report.Vendors += count.Vendors
+		country.Vendors += count.Vendors
+		state.Vendors += count.Vendors
+		if q.IncludeBalances {
+			report.Balances = addBalance(report.Balances, count.Currency, count.Balance)
+			country.Balances = addBalance(country.Balances, count.Currency, count.Balance)
+			state.Balances = addBalance(state.Balances, count.Currency, count.Balance)
+		}
+	}
+
+	sort.SliceStable(report.Countries, func(i, j int) bool {
+		a, b := report.Countries[i], report.Countries[j]
+		if (a.Country == repository.UnknownCountry) != (b.Country == repository.UnknownCountry) {
+			return b.Country == repository.UnknownCountry
+		}
+		if a.Vendors != b.Vendors {
+			return a.Vendors > b.Vendors
+		}
+		return a.Country < b.Country
+	})
+	for _, country := range report.Countries {
+		sort.SliceStable(country.States, func(i, j int) bool {
+			a, b := country.States[i], country.States[j]
+			if (a.StateProvince == nil) != (b.StateProvince == nil) {
+				return b.StateProvince == nil
+			}
+			return a.Vendors > b.Vendors
+		})
+	}
+
+	return report, nil
+}
+
+// addBalance adds amount in currency to balances, allocating it if needed
+func addBalance(balances map[string]int64, currency string, amount int64) map[string]int64 {
+	if balances == nil {
+		balances = make(map[string]int64)
+	}
+	balances[currency] += amount
+	return balances
+}
+
+// WriteGeographyCSV writes a geography report as CSV, one row per state or
+// province: country,state_province,vendors and, when the report has balances,
+// a balance_{currency} column per currency in minor units
+func WriteGeographyCSV(w io.Writer, report *GeographyReport) error {
+	currencies := make([]string, 0, len(report.Balances))
+	for currency := range report.Balances {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	header := []string{"country", "state_province", "vendors"}
+	for _, currency := range currencies {
+		header = append(header, "balance_"+strings.ToLower(currency))
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, country := range report.Countries {
+		for _, state := range country.States {
+			row := []string{country.Country, "", strconv.FormatInt(state.Vendors, 10)}
+			if state.StateProvince != nil {
+				row[1] = *state.StateProvince
+			}
+			for _, currency := range currencies {
+				row = append(row, strconv.FormatInt(state.Balances[currency], 10))
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
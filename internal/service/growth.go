@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+// Bounds of the vendor growth series
+const (
+	// defaultGrowthPeriods is how many periods, ending with the current one,
+	// the series covers without a range
+	defaultGrowthPeriods = 12
+	// maxGrowthPeriods is five years of weeks
+	maxGrowthPeriods = 260
+)
+
+// VendorGrowth is the vendor growth series of an entity, oldest period first
+type VendorGrowth struct {
+	EntityID    string                    `json:"entity_id"`
+	Granularity string                    `json:"granularity"`
+	Points      []*repository.GrowthPoint `json:"points"`
+}
+
+// GetVendorGrowth returns how many vendors an entity created and had active
+// per week or month. Granularity defaults to months; without From the series
+// covers the 12 periods up to To, which defaults to now.
+func (s *VendorService) GetVendorGrowth(ctx context.Context, q repository.GrowthQuery) (*VendorGrowth, error) {
+	if q.Granularity == "" {
+		q.Granularity = repository.GranularityMonth
+	}
+	if q.To.IsZero() {
+		q.To = time.Now().UTC()
+	}
+	if q.From.IsZero() {
+		q.From = repository.PeriodStart(q.To, q.Granularity)
+		for i := 1; i < defaultGrowthPeriods; i++ {
+			q.From = previousPeriod(q.From, q.Granularity)
+		}
+	}
+
+	v := &validator{}
+	v.check(q.EntityID != "", "entity_id", "entity_id is required")
+	v.check(q.Granularity == repository.GranularityWeek || q.Granularity == repository.GranularityMonth,
+		"granularity", "granularity must be week or month")
+	v.check(!q.From.After(q.To), "from", "from must not be after to")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	periods := 0
+	for start := repository.PeriodStart(q.From, q.Granularity); !start.After(q.To); start = repository.NextPeriod(start, q.Granularity) {
+		if periods++; periods > maxGrowthPeriods {
+			v.add("from", fmt.Sprintf("the range must cover at most %d periods", maxGrowthPeriods))
+			return nil, v.err()
+		}
+	}
+
+	points, err := s.vendorRepo.VendorGrowth(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return &VendorGrowth{EntityID: q.EntityID, Granularity: q.Granularity, Points: points}, nil
+}
+
+// previousPeriod returns the start of the period preceding the one starting at start
+func previousPeriod(start time.Time, granularity string) time.Time {
+	if granularity == repository.GranularityWeek {
+		return start.AddDate(0, 0, -7)
+	}
+	return start.AddDate(0, -1, 0)
+}
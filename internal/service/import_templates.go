@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/csvimport"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// maxImportTemplateNameLength matches import_templates.name
+const maxImportTemplateNameLength = 100
+
+// checkImportTemplateMapping records a violation for every column of a template
+// that is not a column of the contact import, and for unknown transforms. It
+// runs when a template is saved and again when it is used, so that a template
+// left behind by a change of the import columns fails clearly.
+func checkImportTemplateMapping(v *validator, t *repository.ImportTemplate) {
+	isColumn := func(column string) bool { return slices.Contains(ContactCSVColumns, column) }
+
+	sources := make([]string, 0, len(t.Columns))
+	for source := range t.Columns {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	mapped := make(map[string]string, len(t.Columns))
+	for _, source := range sources {
+		column := t.Columns[source]
+		switch {
+		case strings.TrimSpace(source) == "":
+			v.add("columns", "source column names cannot be empty")
+		case !isColumn(column):
+			v.add("columns", fmt.Sprintf("source column %q maps to unknown column %q", source, column))
+		case mapped[column] != "":
+			v.add("columns", fmt.Sprintf("source columns %q and %q both map to %q", mapped[column], source, column))
+		default:
+			mapped[column] = source
+		}
+	}
+
+	for _, column := range sortedKeys(t.Defaults) {
+		v.check(isColumn(column), "defaults", fmt.Sprintf("default for unknown column %q", column))
+	}
+	for _, column := range sortedKeys(t.Transforms) {
+		v.check(isColumn(column), "transforms", fmt.Sprintf("transforms for unknown column %q", column))
+		for _, name := range t.Transforms[column] {
+			v.check(csvimport.IsTransform(name), "transforms", fmt.Sprintf("unknown transform %q for column %q (expected %s, %s or %s)",
+				name, column, csvimport.TransformStripLeadingZeros, csvimport.TransformUppercase, csvimport.TransformLowercase))
+		}
+	}
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// validateImportTemplate normalizes and validates an import template
+func validateImportTemplate(t *repository.ImportTemplate) error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Defaults == nil {
+		t.Defaults = make(map[string]string)
+	}
+	if t.Transforms == nil {
+		t.Transforms = make(map[string][]string)
+	}
+
+	v := &validator{}
+	v.check(t.EntityID != "", "entity_id", "entity_id is required")
+	v.check(t.Name != "", "name", "name is required")
+	v.check(len(t.Name) <= maxImportTemplateNameLength, "name",
+		fmt.Sprintf("name must be at most %d characters", maxImportTemplateNameLength))
+	v.check(len(t.Columns) > 0, "columns", "at least one column mapping is required")
+	checkImportTemplateMapping(v, t)
+	return v.err()
+}
+
+// ListImportTemplates retrieves the import templates of an entity
+func (s *VendorService) ListImportTemplates(ctx context.Context, entityID string) ([]*repository.ImportTemplate, error) {
+	if entityID == "" {
+		return nil, errors.InvalidInput("entity_id", "entity_id is required")
+	}
+	return s.vendorRepo.ListImportTemplates(ctx, entityID)
+}
+
+// GetImportTemplate retrieves an import template of an entity
+func (s *VendorService) GetImportTemplate(ctx context.Context, id, entityID string) (*repository.ImportTemplate, error) {
+	return s.vendorRepo.GetImportTemplate(ctx, id, entityID)
+}
+
+// CreateImportTemplate stores a named column mapping for recurring imports
+func (s *VendorService) CreateImportTemplate(ctx context.Context, t *repository.ImportTemplate) error {
+	if err := validateImportTemplate(t); err != nil {
+		return err
+	}
+
+	if err := s.vendorRepo.CreateImportTemplate(ctx, t); err != nil {
+		return err
+	}
+
+	reqlog.SetEntity(ctx, t.EntityID)
+	s.logger(ctx).Info().Str("template_id", t.ID).Str("name", t.Name).Msg("Import template created")
+
+	return nil
+}
+
+// UpdateImportTemplate replaces the name and mapping of an import template
+func (s *VendorService) UpdateImportTemplate(ctx context.Context, t *repository.ImportTemplate) error {
+	if err := validateImportTemplate(t); err != nil {
+		return err
+	}
+
+	if err := s.vendorRepo.UpdateImportTemplate(ctx, t); err != nil {
+		return err
+	}
+
+	reqlog.SetEntity(ctx, t.EntityID)
+	s.logger(ctx).Info().Str("template_id", t.ID).Str("name", t.Name).Msg("Import template updated")
+
+	return nil
+}
+
+// DeleteImportTemplate removes an import template of an entity
+func (s *VendorService) DeleteImportTemplate(ctx context.Context, id, entityID string) error {
+	if err := s.vendorRepo.DeleteImportTemplate(ctx, id, entityID); err != nil {
+		return err
+	}
+
+	reqlog.SetEntity(ctx, entityID)
+	s.logger(ctx).Info().Str("template_id", id).Msg("Import template deleted")
+
+	return nil
+}
+
+// importMapping loads the template of an import and returns its mapping.
+// Templates that no longer match the import columns are reported as
+// violations of template_id.
+func (s *VendorService) importMapping(ctx context.Context, v *validator, templateID, entityID string) (*csvimport.Mapping, error) {
+	t, err := s.vendorRepo.GetImportTemplate(ctx, templateID, entityID)
+	if isNotFound(err) {
+		v.add("template_id", fmt.Sprintf("no import template %s", templateID))
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	check := &validator{}
+	checkImportTemplateMapping(check, t)
+	for _, violation := range check.violations {
+		v.add("template_id", fmt.Sprintf("import template %q is out of date: %s", t.Name, violation.Message))
+	}
+	if len(check.violations) > 0 {
+		return nil, nil
+	}
+
+	return &csvimport.Mapping{Columns: t.Columns, Defaults: t.Defaults, Transforms: t.Transforms}, nil
+}
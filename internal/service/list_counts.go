@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+// ExpiringDocumentWindow is how far ahead a document expiry makes it count as
+// expiring in vendor lists; documents already expired count too
+const ExpiringDocumentWindow = 30 * 24 * time.Hour
+
+// attachChildCounts sets the contact and document counts of vendors, with a
+// single grouped query for the whole page
+func (s *VendorService) attachChildCounts(ctx context.Context, vendors ...*repository.Vendor) error {
+	ids := make([]string, len(vendors))
+	for i, vendor := range vendors {
+		ids[i] = vendor.ID
+	}
+	counts, err := s.vendorRepo.ListChildCounts(ctx, ids, time.Now().Add(ExpiringDocumentWindow))
+	if err != nil {
+		return err
+	}
+	for _, vendor := range vendors {
+		c := counts[vendor.ID]
+		if c == nil {
+			c = &repository.VendorChildCounts{}
+		}
+		vendor.ContactsCount = &c.Contacts
+		vendor.DocumentsCount = &c.Documents
+		vendor.ExpiringDocumentsCount = &c.ExpiringDocuments
+	}
+	return nil
+}
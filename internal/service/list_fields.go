@@ -0,0 +1,49 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// ParseVendorFields validates the fields a vendor list is restricted to and
+// returns them lowercased and deduplicated, always starting with id. It
+// returns nil when no field is named, for lists of whole vendors.
+func ParseVendorFields(names []string) ([]string, error) {
+	fields := []string{"id"}
+	seen := map[string]bool{"id": true}
+	named := false
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		named = true
+		if !repository.IsVendorField(name) {
+			return nil, errors.InvalidInput("fields", fmt.Sprintf("unknown field %q, valid fields: %s",
+				name, strings.Join(repository.VendorFieldNames(), ", ")))
+		}
+		if !seen[name] {
+			seen[name] = true
+			fields = append(fields, name)
+		}
+	}
+	if !named {
+		return nil, nil
+	}
+	return fields, nil
+}
+
+// RequestsBankFields reports whether fields name a vendor bank detail
+func RequestsBankFields(fields []string) bool {
+	for _, field := range fields {
+		for _, bank := range repository.VendorBankFields {
+			if field == bank {
+				return true
+			}
+		}
+	}
+	return false
+}
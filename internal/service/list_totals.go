@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+// TotalModeAuto counts the vendors of a list exactly in small entities and
+// estimates them in entities with more live vendors than the configured
+// threshold. It is the default of ListVendors.
+const TotalModeAuto = "auto"
+
+// maxCachedEntityCounts bounds the entities whose vendor counts are cached;
+// expired entries are dropped once it is reached
+const maxCachedEntityCounts = 10000
+
+// IsValidTotalMode reports whether mode is a total mode of ListVendors
+func IsValidTotalMode(mode string) bool {
+	switch mode {
+	case "", TotalModeAuto, repository.TotalExact, repository.TotalEstimate, repository.TotalNone:
+		return true
+	}
+	return false
+}
+
+// VendorPage is a page of a vendor list. TotalMode tells how Total was
+// computed: exactly, estimated, or not at all with a Total of -1.
+type VendorPage struct {
+	Vendors   []*repository.Vendor `json:"vendors"`
+	Total     int64                `json:"total"`
+	TotalMode string               `json:"total_mode"`
+}
+
+// entityCountCache holds the live vendor counts of entities for a while, so
+// choosing how to count a list does not count the entity on every request
+type entityCountCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]cachedEntityCount
+}
+
+type cachedEntityCount struct {
+	count     int64
+	expiresAt time.Time
+}
+
+func newEntityCountCache(ttl time.Duration) *entityCountCache {
+	return &entityCountCache{ttl: ttl, entries: make(map[string]cachedEntityCount)}
+}
+
+func (c *entityCountCache) get(entityID string, now time.Time) (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[entityID]
+	if !ok || !now.Before(entry.expiresAt) {
+		return 0, false
+	}
+	return entry.count, true
+}
+
+func (c *entityCountCache) put(entityID string, count int64, now time.Time) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCachedEntityCounts {
+		for id, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+	}
+	c.entries[entityID] = cachedEntityCount{count: count, expiresAt: now.Add(c.ttl)}
+}
+
+// resolveTotalMode returns how to count a list requested with mode. Auto
+// estimates in entities with more live vendors than the threshold, going by
+// the cached count of the entity, and counts exactly otherwise.
+func (s *VendorService) resolveTotalMode(ctx context.Context, entityID, mode string) (string, error) {
+	if mode != "" && mode != TotalModeAuto {
+		return mode, nil
+	}
+	if s.estimateTotalsAbove <= 0 {
+		return repository.TotalExact, nil
+	}
+
+	now := time.Now()
+	count, ok := s.entityCounts.get(entityID, now)
+	if !ok {
+		var err error
+		if count, err = s.vendorRepo.CountLiveVendors(ctx, entityID); err != nil {
+			return "", err
+		}
+		s.entityCounts.put(entityID, count, now)
+	}
+	if count > s.estimateTotalsAbove {
+		return repository.TotalEstimate, nil
+	}
+	return repository.TotalExact, nil
+}
+
+// settleEstimate corrects the estimated total of a page listing listed
+// vendors with what the page shows, and returns it with its total mode: a
+// page that is not full ends the list, so its total is exact, and a full page
+// proves at least as many vendors as it reaches. An empty page past the first
+// proves nothing.
+func settleEstimate(total int64, listed, offset, pageSize int) (int64, string) {
+	if listed == 0 && offset > 0 {
+		return total, repository.TotalEstimate
+	}
+	seen := int64(offset + listed)
+	if listed < pageSize {
+		return seen, repository.TotalExact
+	}
+	return max(total, seen), repository.TotalEstimate
+}
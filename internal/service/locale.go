@@ -0,0 +1,26 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/locale"
+)
+
+// vendorLocale validates a requested vendor locale and returns its canonical
+// form. Without one, vendors get the default locale of their country, or none
+// for countries without a known language.
+func vendorLocale(v *validator, requested *string, country string) *string {
+	if requested == nil || strings.TrimSpace(*requested) == "" {
+		if tag := locale.ForCountry(country); tag != "" {
+			return &tag
+		}
+		return nil
+	}
+
+	tag, err := locale.Parse(*requested)
+	if err != nil {
+		v.add("locale", err.Error())
+		return nil
+	}
+	return &tag
+}
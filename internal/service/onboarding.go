@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/address"
+	"github.com/pesio-ai/be-ap-vendors/internal/bankverify"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-ap-vendors/internal/tinmatch"
+)
+
+// Onboarding checklist items
+const (
+	OnboardingW9Received      = "w9_received"
+	OnboardingBankVerified    = "bank_verified"
+	OnboardingInsuranceOnFile = "insurance_on_file"
+	OnboardingScreened        = "screened"
+	OnboardingApproved        = "approved"
+)
+
+// States of onboarding checklist items. Items that do not apply to a vendor,
+// such as a W-9 for a foreign vendor, neither count towards its progress nor
+// block it.
+const (
+	OnboardingStateComplete      = "complete"
+	OnboardingStatePending       = "pending"
+	OnboardingStateMissing       = "missing"
+	OnboardingStateNotApplicable = "not_applicable"
+)
+
+// onboardingItems are the checklist items in their default order
+var onboardingItems = []string{
+	OnboardingW9Received, OnboardingBankVerified, OnboardingInsuranceOnFile, OnboardingScreened, OnboardingApproved,
+}
+
+// defaultOnboardingChecklist is the checklist of entities without their own
+var defaultOnboardingChecklist = []repository.OnboardingChecklistItem{
+	{Item: OnboardingW9Received, Blocking: true},
+	{Item: OnboardingBankVerified, Blocking: true},
+	{Item: OnboardingInsuranceOnFile, Blocking: false},
+	{Item: OnboardingScreened, Blocking: true},
+	{Item: OnboardingApproved, Blocking: true},
+}
+
+// OnboardingItem is the state of a checklist item for a vendor
+type OnboardingItem struct {
+	Item     string `json:"item"`
+	State    string `json:"state"`
+	Blocking bool   `json:"blocking"`
+	// Detail explains items that are not complete
+	Detail string `json:"detail,omitempty"`
+}
+
+// OnboardingStatus is the progress of a vendor through its entity's
+// onboarding checklist
+type OnboardingStatus struct {
+	VendorID string            `json:"vendor_id"`
+	Status   string            `json:"status"`
+	Items    []*OnboardingItem `json:"items"`
+	// PercentComplete is the share of the applicable items that are complete,
+	// rounded down
+	PercentComplete int `json:"percent_complete"`
+	// ReadyToActivate is set when every blocking item but the approval itself
+	// is complete
+	ReadyToActivate bool `json:"ready_to_activate"`
+}
+
+// validateOnboardingChecklist checks the items of an entity's onboarding
+// checklist: known and each listed once
+func validateOnboardingChecklist(v *validator, checklist []repository.OnboardingChecklistItem) {
+	seen := make(map[string]bool, len(checklist))
+	for i, item := range checklist {
+		field := fmt.Sprintf("onboarding_checklist[%d].item", i)
+		if !slices.Contains(onboardingItems, item.Item) {
+			v.add(field, fmt.Sprintf("unknown onboarding checklist item %q; valid items: %v", item.Item, onboardingItems))
+			continue
+		}
+		v.check(!seen[item.Item], field, fmt.Sprintf("onboarding checklist item %s is listed more than once", item.Item))
+		seen[item.Item] = true
+	}
+}
+
+// onboardingChecklist returns the checklist of an entity
+func (s *VendorService) onboardingChecklist(ctx context.Context, entityID string) ([]repository.OnboardingChecklistItem, error) {
+	settings, err := s.vendorRepo.GetEntitySettings(ctx, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if settings.OnboardingChecklist == nil {
+		return defaultOnboardingChecklist, nil
+	}
+	return settings.OnboardingChecklist, nil
+}
+
+// onboardingStatuses evaluates the checklist of an entity for vendors of it,
+// by vendor ID. Approval states are only needed for vendors awaiting
+// approval.
+func (s *VendorService) onboardingStatuses(ctx context.Context, entityID string, vendors []*repository.Vendor, approvals map[string]*repository.ApprovalState) (map[string]*OnboardingStatus, error) {
+	checklist, err := s.onboardingChecklist(ctx, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachBankVerifications(ctx, vendors...); err != nil {
+		return nil, err
+	}
+	if err := s.attachTINMatches(ctx, vendors...); err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(vendors))
+	for i, vendor := range vendors {
+		ids[i] = vendor.ID
+	}
+	signals, err := s.vendorRepo.ListOnboardingSignals(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[string]*OnboardingStatus, len(vendors))
+	for _, vendor := range vendors {
+		signal := signals[vendor.ID]
+		if signal == nil {
+			signal = &repository.OnboardingSignals{}
+		}
+		statuses[vendor.ID] = evaluateOnboarding(vendor, checklist, signal, approvals[vendor.ID])
+	}
+	return statuses, nil
+}
+
+// evaluateOnboarding evaluates a checklist for a vendor
+func evaluateOnboarding(vendor *repository.Vendor, checklist []repository.OnboardingChecklistItem, signals *repository.OnboardingSignals, approval *repository.ApprovalState) *OnboardingStatus {
+	status := &OnboardingStatus{
+		VendorID:        vendor.ID,
+		Status:          vendor.Status,
+		Items:           make([]*OnboardingItem, 0, len(checklist)),
+		ReadyToActivate: true,
+	}
+
+	applicable, complete := 0, 0
+	for _, entry := range checklist {
+		item := &OnboardingItem{Item: entry.Item, Blocking: entry.Blocking}
+		item.State, item.Detail = onboardingItemState(entry.Item, vendor, signals, approval)
+		status.Items = append(status.Items, item)
+
+		if item.State == OnboardingStateNotApplicable {
+			continue
+		}
+		applicable++
+		if item.State == OnboardingStateComplete {
+			complete++
+		} else if item.Blocking && item.Item != OnboardingApproved {
+			status.ReadyToActivate = false
+		}
+	}
+
+	status.PercentComplete = 100
+	if applicable > 0 {
+		status.PercentComplete = complete * 100 / applicable
+	}
+	return status
+}
+
+// onboardingItemState returns the state of a checklist item for a vendor and
+// what it lacks
+func onboardingItemState(item string, vendor *repository.Vendor, signals *repository.OnboardingSignals, approval *repository.ApprovalState) (string, string) {
+	switch item {
+	case OnboardingW9Received:
+		// W-9s are collected from US and 1099 vendors only, as for risk scores
+		if !vendor.Is1099Vendor && address.NormalizeCountry(vendor.Country) != "US" {
+			return OnboardingStateNotApplicable, ""
+		}
+		if !signals.HasW9 {
+			return OnboardingStateMissing, "no W-9 document is on file"
+		}
+
+	case OnboardingBankVerified:
+		paidToBank := vendor.PaymentMethod != nil && slices.Contains(verifiedPaymentMethods, *vendor.PaymentMethod)
+		if bankFingerprint(vendor) == "" {
+			if paidToBank {
+				return OnboardingStateMissing, fmt.Sprintf("payment method %s requires a bank account; none is on file", *vendor.PaymentMethod)
+			}
+			return OnboardingStateNotApplicable, ""
+		}
+		switch vendor.BankVerification.Status {
+		case bankverify.StatusVerified:
+		case bankverify.StatusPending:
+			return OnboardingStatePending, "bank account verification is pending"
+		case bankverify.StatusFailed:
+			return OnboardingStateMissing, "bank account verification failed"
+		default:
+			return OnboardingStateMissing, "bank account is not verified"
+		}
+
+	case OnboardingInsuranceOnFile:
+		if !signals.HasInsurance {
+			return OnboardingStateMissing, "no unexpired insurance document is on file"
+		}
+
+	case OnboardingScreened:
+		// Screening is TIN matching, which covers 1099 vendors
+		if !vendor.Is1099Vendor {
+			return OnboardingStateNotApplicable, ""
+		}
+		switch vendor.TINMatch.Status {
+		case tinmatch.StatusMatched:
+		case tinmatch.StatusPending:
+			return OnboardingStatePending, "tax_id and legal_name verification with the IRS is pending"
+		case tinmatch.StatusMismatched:
+			return OnboardingStateMissing, "tax_id and legal_name do not match IRS records"
+		default:
+			return OnboardingStateMissing, "tax_id and legal_name have not been verified with the IRS"
+		}
+
+	case OnboardingApproved:
+		if vendor.Status != "pending_approval" {
+			break
+		}
+		if approval != nil && approval.Received > 0 {
+			return OnboardingStatePending, fmt.Sprintf("%d of %d approvals received", approval.Received, approval.Required)
+		}
+		return OnboardingStateMissing, "the vendor awaits approval"
+	}
+	return OnboardingStateComplete, ""
+}
+
+// GetOnboardingStatus evaluates the onboarding checklist of a vendor's entity
+// for the vendor
+func (s *VendorService) GetOnboardingStatus(ctx context.Context, id, entityID string) (*OnboardingStatus, error) {
+	vendor, err := s.vendorRepo.GetByID(ctx, id, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	var approvals map[string]*repository.ApprovalState
+	if vendor.Status == "pending_approval" {
+		state, err := s.vendorApprovalState(ctx, s.vendorRepo, vendor)
+		if err != nil {
+			return nil, err
+		}
+		approvals = map[string]*repository.ApprovalState{vendor.ID: state}
+	}
+
+	statuses, err := s.onboardingStatuses(ctx, entityID, []*repository.Vendor{vendor}, approvals)
+	if err != nil {
+		return nil, err
+	}
+
+	reqlog.SetEntity(ctx, entityID)
+	reqlog.SetVendor(ctx, id)
+	return statuses[vendor.ID], nil
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"crypto/rand"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/address"
+	"github.com/pesio-ai/be-ap-vendors/internal/bankverify"
+	"github.com/pesio-ai/be-ap-vendors/internal/debounce"
+	"github.com/pesio-ai/be-ap-vendors/internal/docstore"
+	"github.com/pesio-ai/be-ap-vendors/internal/filestore"
+	"github.com/pesio-ai/be-ap-vendors/internal/fx"
+	"github.com/pesio-ai/be-ap-vendors/internal/identity"
+	"github.com/pesio-ai/be-ap-vendors/internal/spend"
+	"github.com/pesio-ai/be-ap-vendors/internal/tinmatch"
+)
+
+// Option configures optional VendorService behaviour
+type Option func(*VendorService)
+
+// WithContactMethodRule sets the contact method rule applied to entities without
+// their own setting (ContactMethodRuleOff, ContactMethodRuleWarn or ContactMethodRuleEnforce)
+func WithContactMethodRule(rule string) Option {
+	return func(s *VendorService) {
+		s.contactMethodRule = rule
+	}
+}
+
+// WithRequiredCommunications sets the communication types whose last
+// recipient among the contacts of a vendor cannot be removed
+func WithRequiredCommunications(types []string) Option {
+	return func(s *VendorService) {
+		s.requiredCommunications = types
+	}
+}
+
+// WithAddressValidator sets the validator verifying vendor addresses in
+// addition to the built-in postal code and state/province checks
+func WithAddressValidator(validator address.Validator) Option {
+	return func(s *VendorService) {
+		s.addressValidator = validator
+	}
+}
+
+// WithStrictAddressValidation rejects invalid addresses instead of returning
+// warnings for entities without their own setting
+func WithStrictAddressValidation(strict bool) Option {
+	return func(s *VendorService) {
+		s.strictAddresses = strict
+	}
+}
+
+// WithStrictBankValidation rejects invalid IBAN and SWIFT codes instead of
+// returning warnings for entities without their own setting
+func WithStrictBankValidation(strict bool) Option {
+	return func(s *VendorService) {
+		s.strictBank = strict
+	}
+}
+
+// WithRequireBankVerification makes unverified ACH and wire bank accounts
+// fail vendor validation instead of warning, for entities without their own
+// setting
+func WithRequireBankVerification(require bool) Option {
+	return func(s *VendorService) {
+		s.requireBankVerification = require
+	}
+}
+
+// WithBankVerifier sets the verifier of vendor bank accounts
+func WithBankVerifier(verifier bankverify.Verifier) Option {
+	return func(s *VendorService) {
+		s.bankVerifier = verifier
+	}
+}
+
+// WithEntitySettingsCacheTTL sets how long the resolved validation switches
+// of an entity are cached; 0 reads the settings on every check
+func WithEntitySettingsCacheTTL(ttl time.Duration) Option {
+	return func(s *VendorService) {
+		s.flagsCache = newEntityFlagsCache(ttl)
+	}
+}
+
+// WithListTotalEstimates estimates the totals of vendor lists by default in
+// entities with more than threshold live vendors, checking the size of an
+// entity at most once per cacheTTL; a threshold of 0 always counts exactly
+func WithListTotalEstimates(threshold int, cacheTTL time.Duration) Option {
+	return func(s *VendorService) {
+		s.estimateTotalsAbove = int64(threshold)
+		s.entityCounts = newEntityCountCache(cacheTTL)
+	}
+}
+
+// WithQuotaProvider sets the provider of the per-entity vendor quotas enforced
+// when vendors are created
+func WithQuotaProvider(quotas QuotaProvider) Option {
+	return func(s *VendorService) {
+		s.quotas = quotas
+	}
+}
+
+// WithCreateDebounce rejects creates identical to one made less than window
+// ago, remembering recent creates in store
+func WithCreateDebounce(store debounce.Store, window time.Duration) Option {
+	return func(s *VendorService) {
+		s.recentCreates = store
+		s.createWindow = window
+	}
+}
+
+// WithBulkDeleteConfirmations keeps the confirmation tokens of bulk delete dry
+// runs in store, valid for ttl. Tokens are kept in process by default.
+func WithBulkDeleteConfirmations(store debounce.Store, ttl time.Duration) Option {
+	return func(s *VendorService) {
+		s.bulkDeleteTokens = store
+		s.bulkDeleteTokenTTL = ttl
+	}
+}
+
+// WithApprovalSLA sets how long vendors may await approval before they are
+// reported overdue; 0 disables the SLA
+func WithApprovalSLA(sla time.Duration) Option {
+	return func(s *VendorService) {
+		s.approvalSLA = sla
+	}
+}
+
+// WithBlocklistPolicy sets what happens to vendors created or approved with a
+// tax ID or bank account on the organization blocklist (BlocklistPolicyBlock
+// or BlocklistPolicyWarn)
+func WithBlocklistPolicy(policy string) Option {
+	return func(s *VendorService) {
+		s.blocklistPolicy = policy
+	}
+}
+
+// WithSpendProvider sets the provider of vendor spend shown in snapshots and
+// on the spend endpoint
+func WithSpendProvider(provider spend.Provider) Option {
+	return func(s *VendorService) {
+		s.spend = provider
+	}
+}
+
+// WithDormancy sets after how many months without balance ledger activity or
+// updates vendors are flagged dormant, and whether a vendor.flagged_dormant
+// event is published for every newly flagged vendor
+func WithDormancy(months int, events bool) Option {
+	return func(s *VendorService) {
+		s.dormantMonths = months
+		s.dormancyEvents = events
+	}
+}
+
+// WithTINMatcher sets the matcher verifying vendor tax IDs and legal names
+// with the IRS TIN matching service
+func WithTINMatcher(matcher tinmatch.Matcher) Option {
+	return func(s *VendorService) {
+		s.tinMatcher = matcher
+	}
+}
+
+// WithVendorCodeReservationTTL sets how long ReserveVendorCode holds a vendor
+// code for the vendor to be created
+func WithVendorCodeReservationTTL(ttl time.Duration) Option {
+	return func(s *VendorService) {
+		s.codeReservationTTL = ttl
+	}
+}
+
+// WithDataQualityRules sets the data quality rules evaluated by the data
+// quality report, the completeness breakdown and vendor validation
+func WithDataQualityRules(rules []string) Option {
+	return func(s *VendorService) {
+		s.dataQualityRules = rules
+	}
+}
+
+// WithCurrencyChangeActivityWindow sets how many days back balance ledger
+// activity blocks currency changes of vendors; 0 only checks the balance
+func WithCurrencyChangeActivityWindow(days int) Option {
+	return func(s *VendorService) {
+		s.currencyWindowDays = days
+	}
+}
+
+// WithFXConverter sets the converter comparing credit limits kept in another
+// currency than the vendor's with its balance
+func WithFXConverter(converter fx.Converter) Option {
+	return func(s *VendorService) {
+		s.fxConverter = converter
+	}
+}
+
+// WithVendorExports enables vendor export jobs, storing their files in files.
+// Download links are signed with linkKey and valid for linkTTL; replicas
+// serving the same jobs need the same key, and an empty key is replaced by a
+// random one. Finished jobs and their files are purged after retention.
+func WithVendorExports(files filestore.Store, linkKey []byte, linkTTL, retention time.Duration) Option {
+	return func(s *VendorService) {
+		s.exportFiles = files
+		s.exportLinkKey = linkKey
+		if len(s.exportLinkKey) == 0 {
+			s.exportLinkKey = make([]byte, 32)
+			if _, err := rand.Read(s.exportLinkKey); err != nil {
+				panic(err)
+			}
+		}
+		if linkTTL > 0 {
+			s.exportLinkTTL = linkTTL
+		}
+		if retention > 0 {
+			s.exportRetention = retention
+		}
+	}
+}
+
+// WithUserResolver sets the resolver adding the display names and emails of
+// user IDs to vendors and activity on request
+func WithUserResolver(resolver identity.UserResolver) Option {
+	return func(s *VendorService) {
+		s.users = resolver
+	}
+}
+
+// WithCountryProfileRule sets how vendor writes apply the profile of the
+// vendor's country (CountryProfileRuleOff, CountryProfileRuleWarn or
+// CountryProfileRuleEnforce)
+func WithCountryProfileRule(rule string) Option {
+	return func(s *VendorService) {
+		s.countryProfileRule = rule
+	}
+}
+
+// WithDocumentStore enables vendor document downloads from store. Files up to
+// streamMaxBytes are streamed through the service; larger ones are handed out
+// as signed links valid for linkTTL.
+func WithDocumentStore(store docstore.Store, linkTTL time.Duration, streamMaxBytes int64) Option {
+	return func(s *VendorService) {
+		s.documents = store
+		if linkTTL > 0 {
+			s.documentLinkTTL = linkTTL
+		}
+		if streamMaxBytes >= 0 {
+			s.documentStreamMaxBytes = streamMaxBytes
+		}
+	}
+}
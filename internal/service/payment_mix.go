@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+)
+
+// PaymentMixQuery selects the vendors of the payment mix report of an entity
+type PaymentMixQuery struct {
+	EntityID   string
+	VendorType *string
+	Country    *string
+}
+
+// PaymentMixReport counts the active vendors of an entity by payment term and
+// by payment method, e.g. for planning early-payment discount campaigns.
+// Balances are in minor units by currency.
+type PaymentMixReport struct {
+	EntityID       string              `json:"entity_id"`
+	VendorType     *string             `json:"vendor_type,omitempty"`
+	Country        *string             `json:"country,omitempty"`
+	Vendors        int64               `json:"vendors"`
+	Balances       map[string]int64    `json:"balances,omitempty"`
+	PaymentTerms   []*PaymentMixBucket `json:"payment_terms"`
+	PaymentMethods []*PaymentMixBucket `json:"payment_methods"`
+}
+
+// PaymentMixBucket is a payment term or method of the payment mix report;
+// Value is nil for vendors without a payment method
+type PaymentMixBucket struct {
+	Value    *string          `json:"value"`
+	Vendors  int64            `json:"vendors"`
+	Balances map[string]int64 `json:"balances,omitempty"`
+}
+
+// GetPaymentMixReport counts the active vendors of an entity by payment term
+// and by payment method, with their summed balances. The counts and sums are
+// computed by the store; buckets are ordered by vendors, most first, with
+// vendors without a payment method last.
+func (s *VendorService) GetPaymentMixReport(ctx context.Context, q PaymentMixQuery) (*PaymentMixReport, error) {
+	reqlog.SetEntity(ctx, q.EntityID)
+
+	v := &validator{}
+	v.check(q.EntityID != "", "entity_id", "entity_id is required")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	filter := repository.VendorFilter{
+		EntityID:   q.EntityID,
+		VendorType: q.VendorType,
+		ActiveOnly: true,
+	}
+	if q.Country != nil {
+		country := strings.ToUpper(strings.TrimSpace(*q.Country))
+		q.Country, filter.Country = &country, country
+	}
+	counts, err := s.vendorRepo.CountVendorsByPaymentMix(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &PaymentMixReport{
+		EntityID:       q.EntityID,
+		VendorType:     q.VendorType,
+		Country:        q.Country,
+		PaymentTerms:   make([]*PaymentMixBucket, 0),
+		PaymentMethods: make([]*PaymentMixBucket, 0),
+	}
+	buckets := make(map[[2]string]*PaymentMixBucket)
+	for _, count := range counts {
+		key := [2]string{count.Dimension, ""}
+		if count.Value != nil {
+			key[1] = *count.Value
+		}
+		bucket, ok := buckets[key]
+		if !ok {
+			bucket = &PaymentMixBucket{Value: count.Value}
+			buckets[key] = bucket
+			if count.Dimension == repository.PaymentMixTerms {
+				report.PaymentTerms = append(report.PaymentTerms, bucket)
+			} else {
+				report.PaymentMethods = append(report.PaymentMethods, bucket)
+			}
+		}
+
+		bucket.Vendors += count.Vendors
+		bucket.Balances = addBalance(bucket.Balances, count.Currency, count.Balance)
+		// Every vendor has exactly one payment term, so the terms add up to
+		// the report totals
+		if count.Dimension == repository.PaymentMixTerms {
+			report.Vendors += count.Vendors
+			report.Balances = addBalance(report.Balances, count.Currency, count.Balance)
+		}
+	}
+
+	for _, list := range [][]*PaymentMixBucket{report.PaymentTerms, report.PaymentMethods} {
+		sort.SliceStable(list, func(i, j int) bool {
+			a, b := list[i], list[j]
+			if (a.Value == nil) != (b.Value == nil) {
+				return b.Value == nil
+			}
+			return a.Vendors > b.Vendors
+		})
+	}
+
+	return report, nil
+}
+
+// WritePaymentMixCSV writes a payment mix report as CSV, one row per payment
+// term and per payment method: dimension,value,vendors and a
+// balance_{currency} column per currency in minor units
+func WritePaymentMixCSV(w io.Writer, report *PaymentMixReport) error {
+	currencies := make([]string, 0, len(report.Balances))
+	for currency := range report.Balances {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	header := []string{"dimension", "value", "vendors"}
+	for _, currency := range currencies {
+		header = append(header, "balance_"+strings.ToLower(currency))
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, section := range []struct {
+		dimension string
+		buckets   []*PaymentMixBucket
+	}{
+		{repository.PaymentMixTerms, report.PaymentTerms},
+		{repository.PaymentMixMethod, report.PaymentMethods},
+	} {
+		for _, bucket := range section.buckets {
+			row := []string{section.dimension, "", strconv.FormatInt(bucket.Vendors, 10)}
+			if bucket.Value != nil {
+				row[1] = *bucket.Value
+			}
+			for _, currency := range currencies {
+				row = append(row, strconv.FormatInt(bucket.Balances[currency], 10))
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
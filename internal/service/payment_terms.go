@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+// AuditActionPaymentTermReplaced is written for each vendor moved to another
+// payment term when its term is deactivated
+const AuditActionPaymentTermReplaced = "payment_terms_replaced"
+
+// PaymentTermInUseError is returned when deactivating a payment term that
+// vendors still use, without a replacement or force
+type PaymentTermInUseError struct {
+	Code    string
+	Vendors int64
+}
+
+func (e *PaymentTermInUseError) Error() string {
+	return fmt.Sprintf("payment term %s is used by %d vendors; give a replacement_code or force", e.Code, e.Vendors)
+}
+
+// PaymentTermUsage is the number of live vendors using a payment term, in
+// total and by entity
+type PaymentTermUsage struct {
+	Code     string                         `json:"code"`
+	IsActive bool                           `json:"is_active"`
+	Vendors  int64                          `json:"vendors"`
+	Entities []*repository.PaymentTermUsage `json:"entities"`
+}
+
+// PaymentTermDeactivation is the outcome of deactivating a payment term
+type PaymentTermDeactivation struct {
+	Code string `json:"code"`
+	// ReplacementCode is the term the vendors were moved to, if any
+	ReplacementCode   string `json:"replacement_code,omitempty"`
+	VendorsReassigned int    `json:"vendors_reassigned"`
+	// VendorsLeft are the vendors forced to keep the inactive term
+	VendorsLeft int64 `json:"vendors_left"`
+}
+
+// GetPaymentTermUsage counts the live vendors using a payment term, active or
+// not, e.g. to plan its deactivation
+func (s *VendorService) GetPaymentTermUsage(ctx context.Context, code string) (*PaymentTermUsage, error) {
+	term, err := s.vendorRepo.GetPaymentTermByCode(ctx, code, false)
+	if err != nil {
+		return nil, err
+	}
+
+	entities, err := s.vendorRepo.CountPaymentTermUsage(ctx, term.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &PaymentTermUsage{Code: term.Code, IsActive: term.IsActive, Entities: entities}
+	for _, entity := range entities {
+		usage.Vendors += entity.Vendors
+	}
+	return usage, nil
+}
+
+// DeactivatePaymentTerm marks a payment term inactive. Live vendors still
+// using it are moved to the active term replacementCode in a single statement
+// and audited; with force they keep the inactive term instead and are
+// reported by the inactive_payment_terms data quality rule. Without either,
+// a term in use is not deactivated.
+func (s *VendorService) DeactivatePaymentTerm(ctx context.Context, code, replacementCode string, force bool, actorID *string) (*PaymentTermDeactivation, error) {
+	code, replacementCode = strings.TrimSpace(code), strings.TrimSpace(replacementCode)
+
+	v := &validator{}
+	v.check(code != "", "code", "code is required")
+	v.check(replacementCode == "" || !force, "force", "force cannot be combined with replacement_code")
+	v.check(replacementCode == "" || !strings.EqualFold(replacementCode, code), "replacement_code",
+		"replacement_code must differ from the deactivated term")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	term, err := s.vendorRepo.GetPaymentTermByCode(ctx, code, false)
+	if err != nil {
+		return nil, err
+	}
+	var replacement *repository.PaymentTerm
+	if replacementCode != "" {
+		replacement, err = s.vendorRepo.GetPaymentTermByCode(ctx, replacementCode, true)
+		if repository.IsNotFound(err) {
+			v.add("replacement_code", fmt.Sprintf("%s is not an active payment term", replacementCode))
+			return nil, v.err()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Reassigned vendors must all belong to writable entities. The check reads
+	// through s.vendorRepo, so it runs before the transaction.
+	if replacement != nil {
+		usage, err := s.vendorRepo.CountPaymentTermUsage(ctx, term.Code)
+		if err != nil {
+			return nil, err
+		}
+		for _, entity := range usage {
+			if err := s.checkEntityWritable(ctx, entity.EntityID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	result := &PaymentTermDeactivation{Code: term.Code}
+	err = s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		usage, err := repo.CountPaymentTermUsage(ctx, term.Code)
+		if err != nil {
+			return err
+		}
+		var vendors int64
+		for _, entity := range usage {
+			vendors += entity.Vendors
+		}
+
+		switch {
+		case vendors == 0:
+		case replacement != nil:
+			moved, err := repo.ReplacePaymentTerm(ctx, term.Code, replacement.Code, actorID)
+			if err != nil {
+				return err
+			}
+			for _, vendor := range moved {
+				if err := repo.InsertAuditEntry(ctx, &repository.AuditEntry{
+					EntityID: vendor.EntityID,
+					VendorID: vendor.ID,
+					Action:   AuditActionPaymentTermReplaced,
+					ActorID:  actorID,
+					Details: map[string]interface{}{
+						"vendor_code": vendor.VendorCode,
+						"from":        term.Code,
+						"to":          replacement.Code,
+					},
+				}); err != nil {
+					return err
+				}
+			}
+			result.ReplacementCode = replacement.Code
+			result.VendorsReassigned = len(moved)
+		case force:
+			result.VendorsLeft = vendors
+		default:
+			return &PaymentTermInUseError{Code: term.Code, Vendors: vendors}
+		}
+
+		return repo.DeactivatePaymentTerm(ctx, term.Code)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger(ctx).Info().
+		Str("payment_term", term.Code).
+		Str("replacement", result.ReplacementCode).
+		Int("vendors_reassigned", result.VendorsReassigned).
+		Int64("vendors_left", result.VendorsLeft).
+		Msg("Payment term deactivated")
+	return result, nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+// immutableVendorFields are fixed when a vendor is created. entity_id and id
+// identify the vendor in update and upsert requests and are not listed.
+var immutableVendorFields = []string{"created_by", "created_at", "template_id"}
+
+// createOnlyVendorFields are fixed when a vendor is created too, but upsert
+// requests may carry them: they apply only when the upsert creates the vendor
+var createOnlyVendorFields = []string{"source", "source_reference"}
+
+// serverManagedVendorFields change only through their own endpoints or as a
+// side effect of other writes, with the message explaining how
+var serverManagedVendorFields = map[string]string{
+	"status":          "status is managed by the server; use the activate, deactivate, suspend, approve and reject endpoints",
+	"current_balance": "current_balance is managed by the server; use the balance endpoint",
+	"approval":        "approval is managed by the server; use the approve and reject endpoints",
+	"risk":            "risk is computed by the server",
+	"updated_at":      "updated_at is managed by the server",
+	"deleted_at":      "deleted_at is managed by the server; use the delete endpoint",
+	"change_seq":      "change_seq is managed by the server",
+}
+
+// fieldKey folds a request field name so that "current_balance" and
+// "CurrentBalance" compare equal, as JSON decoding matches them
+func fieldKey(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// CheckWritableFields rejects the immutable and server-managed vendor fields
+// among the fields of an update request, naming each one
+func CheckWritableFields(fields []string) error {
+	return checkProtectedFields(fields, true)
+}
+
+// CheckUpsertFields is CheckWritableFields for upsert requests, which may set
+// the create-only fields
+func CheckUpsertFields(fields []string) error {
+	return checkProtectedFields(fields, false)
+}
+
+// checkProtectedFields rejects the protected fields among fields, including
+// the create-only ones when createOnly is set
+func checkProtectedFields(fields []string, createOnly bool) error {
+	protected := make(map[string]string, len(immutableVendorFields)+len(createOnlyVendorFields)+len(serverManagedVendorFields))
+	for _, field := range immutableVendorFields {
+		protected[fieldKey(field)] = fmt.Sprintf("%s cannot be changed", field)
+	}
+	if createOnly {
+		for _, field := range createOnlyVendorFields {
+			protected[fieldKey(field)] = fmt.Sprintf("%s cannot be changed", field)
+		}
+	}
+	for field, message := range serverManagedVendorFields {
+		protected[fieldKey(field)] = message
+	}
+
+	sorted := append([]string(nil), fields...)
+	sort.Strings(sorted)
+
+	v := &validator{}
+	for _, field := range sorted {
+		if message, ok := protected[fieldKey(field)]; ok {
+			v.add(field, message)
+		}
+	}
+	return v.err()
+}
+
+// keepProtectedFields restores the immutable and server-managed fields of an
+// updated vendor from its stored version, so that no update path can change
+// them whatever its request carries
+func keepProtectedFields(before, after *repository.Vendor) {
+	after.ID, after.EntityID = before.ID, before.EntityID
+	after.CreatedBy, after.CreatedAt, after.TemplateID = before.CreatedBy, before.CreatedAt, before.TemplateID
+	after.Source, after.SourceReference = before.Source, before.SourceReference
+	after.Status, after.CurrentBalance = before.Status, before.CurrentBalance
+	after.DeletedAt, after.ChangeSeq = before.DeletedAt, before.ChangeSeq
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// QuotaProvider supplies the vendor quota of entities, e.g. from their
+// pricing tier
+type QuotaProvider interface {
+	// VendorLimit returns the maximum number of live vendors of an entity, or
+	// ok=false when the entity is unlimited
+	VendorLimit(ctx context.Context, entityID string) (limit int64, ok bool, err error)
+}
+
+var _ QuotaProvider = (*StaticQuotaProvider)(nil)
+
+// StaticQuotaProvider serves vendor quotas from configuration
+type StaticQuotaProvider struct {
+	// Default applies to entities without their own limit; 0 means unlimited
+	Default int64
+	// Limits are the per-entity limits
+	Limits map[string]int64
+}
+
+// VendorLimit returns the configured limit of an entity
+func (p *StaticQuotaProvider) VendorLimit(ctx context.Context, entityID string) (int64, bool, error) {
+	if limit, ok := p.Limits[entityID]; ok {
+		return limit, true, nil
+	}
+	return p.Default, p.Default > 0, nil
+}
+
+// ParseVendorQuotas parses "entity_id:limit" pairs, as in VENDOR_QUOTAS
+func ParseVendorQuotas(pairs []string) (map[string]int64, error) {
+	limits := make(map[string]int64, len(pairs))
+	for _, pair := range pairs {
+		entityID, raw, ok := strings.Cut(pair, ":")
+		limit, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+		if !ok || strings.TrimSpace(entityID) == "" || err != nil || limit < 0 {
+			return nil, fmt.Errorf("invalid vendor quota %q (expected entity_id:limit)", pair)
+		}
+		limits[strings.TrimSpace(entityID)] = limit
+	}
+	return limits, nil
+}
+
+// QuotaExceededError is returned when creating a vendor would exceed the
+// entity's vendor quota
+type QuotaExceededError struct {
+	EntityID string
+	Count    int64
+	Limit    int64
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("vendor quota exceeded: entity has %d of %d vendors", e.Count, e.Limit)
+}
+
+// VendorQuota is the vendor quota usage of an entity
+type VendorQuota struct {
+	// Limit is nil for unlimited entities
+	Limit     *int64 `json:"limit"`
+	Used      int64  `json:"used"`
+	Remaining *int64 `json:"remaining"`
+}
+
+// checkVendorQuota fails with QuotaExceededError when an entity holding count
+// live vendors may not get added more. It runs inside the creating transaction,
+// on a count that serializes concurrent creates of the entity.
+func (s *VendorService) checkVendorQuota(ctx context.Context, entityID string, count, added int64) error {
+	limit, ok, err := s.quotas.VendorLimit(ctx, entityID)
+	if err != nil || !ok {
+		return err
+	}
+	if count+added > limit {
+		return &QuotaExceededError{EntityID: entityID, Count: count, Limit: limit}
+	}
+	return nil
+}
+
+// vendorQuota returns the quota usage of an entity holding used live vendors
+func (s *VendorService) vendorQuota(ctx context.Context, entityID string, used int64) (*VendorQuota, error) {
+	quota := &VendorQuota{Used: used}
+	limit, ok, err := s.quotas.VendorLimit(ctx, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		remaining := limit - used
+		if remaining < 0 {
+			remaining = 0
+		}
+		quota.Limit, quota.Remaining = &limit, &remaining
+	}
+	return quota, nil
+}
+
+// VendorStats summarizes the vendors of an entity
+type VendorStats struct {
+	EntityID string           `json:"entity_id"`
+	Total    int64            `json:"total"`
+	ByStatus map[string]int64 `json:"by_status"`
+	Quota    *VendorQuota     `json:"quota"`
+}
+
+// GetVendorStats returns the live vendor counts of an entity by status and its
+// usage of the vendor quota
+func (s *VendorService) GetVendorStats(ctx context.Context, entityID string) (*VendorStats, error) {
+	v := &validator{}
+	v.check(entityID != "", "entity_id", "entity_id is required")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	byStatus, err := s.vendorRepo.CountVendorsByStatus(ctx, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &VendorStats{EntityID: entityID, ByStatus: byStatus}
+	for _, count := range byStatus {
+		stats.Total += count
+	}
+
+	if stats.Quota, err = s.vendorQuota(ctx, entityID, stats.Total); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"fmt"
+	"net/mail"
+	"slices"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+// remittancePaymentMethods are the payment methods that send the vendor a
+// remittance advice by email
+var remittancePaymentMethods = []string{"ach", "wire"}
+
+// isEmailAddress reports whether s is a bare email address
+func isEmailAddress(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+
+// checkRemittanceEmail validates the remittance email of a vendor when set
+func checkRemittanceEmail(v *validator, vendor *repository.Vendor) {
+	if vendor.RemittanceEmail != nil {
+		v.check(isEmailAddress(*vendor.RemittanceEmail), "remittance_email", "remittance_email must be a valid email address")
+	}
+}
+
+// remittanceWarnings warns about vendors paid by a method that sends
+// remittance advices but without a remittance email
+func remittanceWarnings(vendor *repository.Vendor) []string {
+	if vendor.PaymentMethod == nil || !slices.Contains(remittancePaymentMethods, *vendor.PaymentMethod) || isSet(vendor.RemittanceEmail) {
+		return nil
+	}
+	return []string{fmt.Sprintf("payment method %s sends remittance advices but no remittance_email is set", *vendor.PaymentMethod)}
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// DefaultPurgeBatchSize is the number of rows deleted per purge statement
+const DefaultPurgeBatchSize = 500
+
+// RetentionPolicy holds retention periods in days
+type RetentionPolicy struct {
+	DeletedVendorDays int `json:"deleted_vendor_retention_days"`
+	AuditLogDays      int `json:"audit_log_retention_days"`
+}
+
+// PurgeOptions configures a purge run
+type PurgeOptions struct {
+	// Defaults apply to entities without retention settings
+	Defaults  RetentionPolicy
+	BatchSize int
+	// DryRun reports what would be removed without deleting anything
+	DryRun bool
+}
+
+// PurgeReport summarizes a purge run
+type PurgeReport struct {
+	DryRun         bool  `json:"dry_run"`
+	DeletedVendors int64 `json:"deleted_vendors"`
+	// BlockedVendors are past retention but kept because of recent ledger activity
+	BlockedVendors int64 `json:"blocked_vendors"`
+	Tombstones     int64 `json:"tombstones"`
+	AuditLogRows   int64 `json:"audit_log_rows"`
+	// ExportJobs are expired vendor export jobs removed with their files
+	ExportJobs int64 `json:"export_jobs"`
+}
+
+// GetRetentionSettings retrieves the retention settings of an entity
+func (s *VendorService) GetRetentionSettings(ctx context.Context, entityID string) (*repository.RetentionSettings, error) {
+	return s.vendorRepo.GetRetentionSettings(ctx, entityID)
+}
+
+// SetRetentionSettings creates or replaces the retention settings of an entity
+func (s *VendorService) SetRetentionSettings(ctx context.Context, settings *repository.RetentionSettings) error {
+	if settings.EntityID == "" {
+		return errors.InvalidInput("entity_id", "entity_id is required")
+	}
+	if settings.DeletedVendorRetentionDays != nil && *settings.DeletedVendorRetentionDays <= 0 {
+		return errors.InvalidInput("deleted_vendor_retention_days", "deleted_vendor_retention_days must be positive")
+	}
+	if settings.AuditLogRetentionDays != nil && *settings.AuditLogRetentionDays <= 0 {
+		return errors.InvalidInput("audit_log_retention_days", "audit_log_retention_days must be positive")
+	}
+
+	if err := s.vendorRepo.UpsertRetentionSettings(ctx, settings); err != nil {
+		return err
+	}
+
+	reqlog.SetEntity(ctx, settings.EntityID)
+	s.logger(ctx).Info().Msg("Retention settings updated")
+
+	return nil
+}
+
+// Purge hard-deletes soft-deleted vendors, change-feed tombstones and audit log
+// rows that are past their entity's retention period, in batches. Vendors with
+// balance ledger activity inside the retention window are never purged.
+func (s *VendorService) Purge(ctx context.Context, opts PurgeOptions) (*PurgeReport, error) {
+	if opts.Defaults.DeletedVendorDays <= 0 || opts.Defaults.AuditLogDays <= 0 {
+		return nil, errors.InvalidInput("retention", "default retention periods must be positive")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultPurgeBatchSize
+	}
+	ctx = repository.WithBulkBudget(ctx)
+
+	report := &PurgeReport{DryRun: opts.DryRun}
+
+	var err error
+	if opts.DryRun {
+		report.DeletedVendors, report.BlockedVendors, err = s.vendorRepo.CountPurgeableVendors(ctx, opts.Defaults.DeletedVendorDays)
+		if err != nil {
+			return nil, err
+		}
+		if report.Tombstones, err = s.vendorRepo.CountPurgeableTombstones(ctx, opts.Defaults.DeletedVendorDays); err != nil {
+			return nil, err
+		}
+		if report.AuditLogRows, err = s.vendorRepo.CountPurgeableAuditLog(ctx, opts.Defaults.AuditLogDays); err != nil {
+			return nil, err
+		}
+		if report.ExportJobs, err = s.vendorRepo.CountExpiredExportJobs(ctx, time.Now()); err != nil {
+			return nil, err
+		}
+
+		s.logger(ctx).Info().
+			Int64("vendors", report.DeletedVendors).
+			Int64("blocked_vendors", report.BlockedVendors).
+			Int64("tombstones", report.Tombstones).
+			Int64("audit_log_rows", report.AuditLogRows).
+			Int64("export_jobs", report.ExportJobs).
+			Msg("Purge dry run")
+
+		return report, nil
+	}
+
+	// Vendors kept because of ledger activity are reported, not deleted
+	if _, report.BlockedVendors, err = s.vendorRepo.CountPurgeableVendors(ctx, opts.Defaults.DeletedVendorDays); err != nil {
+		return nil, err
+	}
+
+	if report.DeletedVendors, err = s.purgeInBatches(ctx, "vendors", opts.BatchSize, func() (int64, error) {
+		return s.vendorRepo.PurgeDeletedVendors(ctx, opts.Defaults.DeletedVendorDays, opts.BatchSize)
+	}); err != nil {
+		return nil, err
+	}
+
+	if report.Tombstones, err = s.purgeInBatches(ctx, "tombstones", opts.BatchSize, func() (int64, error) {
+		return s.vendorRepo.PurgeTombstones(ctx, opts.Defaults.DeletedVendorDays, opts.BatchSize)
+	}); err != nil {
+		return nil, err
+	}
+
+	if report.AuditLogRows, err = s.purgeInBatches(ctx, "audit_log", opts.BatchSize, func() (int64, error) {
+		return s.vendorRepo.PurgeAuditLog(ctx, opts.Defaults.AuditLogDays, opts.BatchSize)
+	}); err != nil {
+		return nil, err
+	}
+
+	if report.ExportJobs, err = s.purgeExpiredVendorExports(ctx, opts.BatchSize); err != nil {
+		return nil, err
+	}
+
+	s.logger(ctx).Info().
+		Int64("vendors", report.DeletedVendors).
+		Int64("blocked_vendors", report.BlockedVendors).
+		Int64("tombstones", report.Tombstones).
+		Int64("audit_log_rows", report.AuditLogRows).
+		Int64("export_jobs", report.ExportJobs).
+		Msg("Purge completed")
+
+	return report, nil
+}
+
+// purgeInBatches runs purgeBatch until a batch removes fewer than batchSize rows
+func (s *VendorService) purgeInBatches(ctx context.Context, target string, batchSize int, purgeBatch func() (int64, error)) (int64, error) {
+	var total int64
+	for batch := 1; ; batch++ {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+
+		removed, err := purgeBatch()
+		if err != nil {
+			return total, err
+		}
+		total += removed
+
+		s.logger(ctx).Info().
+			Str("target", target).
+			Int("batch", batch).
+			Int64("removed", removed).
+			Int64("total", total).
+			Msg("Purge batch completed")
+
+		if removed < int64(batchSize) {
+			return total, nil
+		}
+	}
+}
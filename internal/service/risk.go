@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/address"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+)
+
+// Risk factors, each scored by a rule with a per-entity weight
+const (
+	RiskMissingTaxID       = "missing_tax_id"
+	RiskForeignBankAccount = "foreign_bank_account"
+	RiskRecentBankChange   = "recent_bank_change"
+	RiskNewVendor          = "new_vendor"
+	RiskMissingW9          = "missing_w9"
+	RiskOverCreditLimit    = "over_credit_limit"
+)
+
+// RiskFactors lists the risk factors in the order breakdowns report them
+var RiskFactors = []string{
+	RiskMissingTaxID, RiskForeignBankAccount, RiskRecentBankChange,
+	RiskNewVendor, RiskMissingW9, RiskOverCreditLimit,
+}
+
+const (
+	// riskRecentWindow is how long a vendor counts as new, and a bank details
+	// change as recent
+	riskRecentWindow = 30 * 24 * time.Hour
+	// maxRiskScore caps risk scores and rule weights
+	maxRiskScore = 100
+	// riskBatchSize is the number of vendors rescored per batch
+	riskBatchSize = 500
+)
+
+// riskRules report whether each factor applies to a vendor
+var riskRules = map[string]func(v *repository.Vendor, signals *repository.RiskSignals, now time.Time) bool{
+	RiskMissingTaxID: func(v *repository.Vendor, _ *repository.RiskSignals, _ time.Time) bool {
+		return isBlank(v.TaxID)
+	},
+	RiskForeignBankAccount: func(v *repository.Vendor, _ *repository.RiskSignals, _ time.Time) bool {
+		country := bankCountry(v)
+		return country != "" && country != address.NormalizeCountry(v.Country)
+	},
+	RiskRecentBankChange: func(_ *repository.Vendor, signals *repository.RiskSignals, now time.Time) bool {
+		return signals.LastBankChange != nil && now.Sub(*signals.LastBankChange) < riskRecentWindow
+	},
+	RiskNewVendor: func(v *repository.Vendor, _ *repository.RiskSignals, now time.Time) bool {
+		return now.Sub(v.CreatedAt) < riskRecentWindow
+	},
+	// W-9s are collected from US persons and 1099 vendors
+	RiskMissingW9: func(v *repository.Vendor, signals *repository.RiskSignals, _ time.Time) bool {
+		return (v.Is1099Vendor || address.NormalizeCountry(v.Country) == "US") && !signals.HasW9
+	},
+	// Matches ValidateVendor: a balance equal to the limit is already over it
+	RiskOverCreditLimit: func(v *repository.Vendor, _ *repository.RiskSignals, _ time.Time) bool {
+		return repository.OverCreditLimit(v)
+	},
+}
+
+// bankCountry returns the country of a vendor's bank account, from the IBAN
+// or else the SWIFT code, or "" when neither is set
+func bankCountry(v *repository.Vendor) string {
+	if iban := strings.ToUpper(strings.ReplaceAll(deref(v.IBAN), " ", "")); len(iban) >= 2 {
+		return iban[:2]
+	}
+	if swift := strings.ToUpper(strings.TrimSpace(deref(v.SwiftCode))); len(swift) >= 6 {
+		return swift[4:6]
+	}
+	return ""
+}
+
+// deref returns the value of an optional string, or ""
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// scoreVendor applies the risk rules to a vendor: its score is the sum of the
+// weights of the factors that apply, capped at 100
+func scoreVendor(v *repository.Vendor, signals *repository.RiskSignals, weights map[string]int, now time.Time) *repository.RiskScore {
+	score := &repository.RiskScore{
+		VendorID:   v.ID,
+		EntityID:   v.EntityID,
+		Factors:    make([]repository.RiskFactor, 0),
+		ComputedAt: now,
+	}
+	for _, factor := range RiskFactors {
+		weight := weights[factor]
+		if weight == 0 || !riskRules[factor](v, signals, now) {
+			continue
+		}
+		score.Factors = append(score.Factors, repository.RiskFactor{Factor: factor, Weight: weight})
+		score.Score += weight
+	}
+	score.Score = min(score.Score, maxRiskScore)
+	return score
+}
+
+// rescoreVendors computes and stores the risk scores of vendors, setting their
+// Risk. It runs on the store of the calling transaction, if any.
+func (s *VendorService) rescoreVendors(ctx context.Context, repo repository.Store, vendors []*repository.Vendor) error {
+	ids := make([]string, len(vendors))
+	for i, vendor := range vendors {
+		ids[i] = vendor.ID
+	}
+	signals, err := repo.ListRiskSignals(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	weights := make(map[string]map[string]int)
+	now := time.Now().UTC()
+	for _, vendor := range vendors {
+		if _, ok := weights[vendor.EntityID]; !ok {
+			if weights[vendor.EntityID], err = repo.GetRiskWeights(ctx, vendor.EntityID); err != nil {
+				return err
+			}
+		}
+		vendorSignals := signals[vendor.ID]
+		if vendorSignals == nil {
+			vendorSignals = &repository.RiskSignals{}
+		}
+
+		vendor.Risk = scoreVendor(vendor, vendorSignals, weights[vendor.EntityID], now)
+		if err := repo.UpsertRiskScore(ctx, vendor.Risk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rescoreVendor computes and stores the risk score of a vendor after a mutation
+func (s *VendorService) rescoreVendor(ctx context.Context, repo repository.Store, vendor *repository.Vendor) error {
+	return s.rescoreVendors(ctx, repo, []*repository.Vendor{vendor})
+}
+
+// attachRiskScores sets the stored risk scores of vendors
+func (s *VendorService) attachRiskScores(ctx context.Context, vendors ...*repository.Vendor) error {
+	ids := make([]string, len(vendors))
+	for i, vendor := range vendors {
+		ids[i] = vendor.ID
+	}
+	scores, err := s.vendorRepo.ListRiskScores(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, vendor := range vendors {
+		vendor.Risk = scores[vendor.ID]
+	}
+	return nil
+}
+
+// RiskRecomputeReport summarizes a risk score recomputation
+type RiskRecomputeReport struct {
+	EntityID string `json:"entity_id,omitempty"`
+	Scanned  int    `json:"scanned"`
+	// Changed counts vendors whose score differs from the stored one
+	Changed int `json:"changed"`
+}
+
+// RecomputeRiskScores rescores the live vendors of an entity, or of all
+// entities when entityID is empty. Time-based factors (new vendor, recent bank
+// change) only lapse when a vendor is rescored, so this also runs on a schedule.
+func (s *VendorService) RecomputeRiskScores(ctx context.Context, entityID string) (*RiskRecomputeReport, error) {
+	ctx = repository.WithBulkBudget(repository.UsePrimary(ctx))
+
+	report := &RiskRecomputeReport{EntityID: entityID}
+	var afterID string
+	for {
+		vendors, err := s.vendorRepo.ListVendorBatch(ctx, entityID, afterID, riskBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(vendors) == 0 {
+			break
+		}
+		afterID = vendors[len(vendors)-1].ID
+		report.Scanned += len(vendors)
+
+		ids := make([]string, len(vendors))
+		for i, vendor := range vendors {
+			ids[i] = vendor.ID
+		}
+		previous, err := s.vendorRepo.ListRiskScores(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+
+		err = s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+			return s.rescoreVendors(ctx, repo, vendors)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, vendor := range vendors {
+			if old := previous[vendor.ID]; old == nil || old.Score != vendor.Risk.Score {
+				report.Changed++
+			}
+		}
+	}
+
+	s.logger(ctx).Info().
+		Str("entity_id", entityID).
+		Int("scanned", report.Scanned).
+		Int("changed", report.Changed).
+		Msg("Vendor risk scores recomputed")
+
+	return report, nil
+}
+
+// RiskWeights are the risk rule weights of an entity
+type RiskWeights struct {
+	EntityID string         `json:"entity_id"`
+	Weights  map[string]int `json:"weights"`
+}
+
+// GetRiskWeights retrieves the effective risk rule weights of an entity
+func (s *VendorService) GetRiskWeights(ctx context.Context, entityID string) (*RiskWeights, error) {
+	v := &validator{}
+	v.check(entityID != "", "entity_id", "entity_id is required")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	weights, err := s.vendorRepo.GetRiskWeights(ctx, entityID)
+	if err != nil {
+		return nil, err
+	}
+	return &RiskWeights{EntityID: entityID, Weights: weights}, nil
+}
+
+// SetRiskWeights replaces the risk rule weights an entity overrides and
+// rescores its vendors with the new weights. Factors left out use the defaults.
+func (s *VendorService) SetRiskWeights(ctx context.Context, entityID string, overrides map[string]int, updatedBy string) (*RiskRecomputeReport, error) {
+	v := &validator{}
+	v.check(entityID != "", "entity_id", "entity_id is required")
+	factors := make([]string, 0, len(overrides))
+	for factor := range overrides {
+		factors = append(factors, factor)
+	}
+	sort.Strings(factors)
+	for _, factor := range factors {
+		if _, ok := riskRules[factor]; !ok {
+			v.add("weights."+factor, fmt.Sprintf("unknown risk factor (expected one of %s)", strings.Join(RiskFactors, ", ")))
+			continue
+		}
+		v.check(overrides[factor] >= 0 && overrides[factor] <= maxRiskScore,
+			"weights."+factor, fmt.Sprintf("weight must be between 0 and %d", maxRiskScore))
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	var updatedByPtr *string
+	if updatedBy != "" {
+		updatedByPtr = &updatedBy
+	}
+
+	err := s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		return repo.ReplaceRiskWeights(ctx, entityID, overrides, updatedByPtr)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reqlog.SetEntity(ctx, entityID)
+	s.logger(ctx).Info().Interface("weights", overrides).Msg("Risk weights updated")
+
+	return s.RecomputeRiskScores(ctx, entityID)
+}
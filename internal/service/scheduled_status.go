@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// Audit actions and events written for scheduled status changes
+const (
+	AuditActionStatusChangeScheduled       = "status_change_scheduled"
+	AuditActionStatusChangeCancelled       = "status_change_cancelled"
+	AuditActionScheduledStatusChangeFailed = "scheduled_status_change_failed"
+
+	EventVendorScheduledStatusApplied = "vendor.scheduled_status_applied"
+	EventVendorScheduledStatusFailed  = "vendor.scheduled_status_failed"
+)
+
+const (
+	maxScheduleReasonLength = 500
+	// scheduledStatusBatchSize is how many due changes are applied per transaction
+	scheduledStatusBatchSize = 100
+	maxScheduledStatusList   = 500
+)
+
+// schedulableStatuses are the statuses a change can be scheduled to; vendors
+// only leave pending_approval through the approval endpoints
+var schedulableStatuses = []string{"active", "inactive", "suspended"}
+
+// ScheduleStatusChangeRequest schedules a vendor status change for a date
+// (YYYY-MM-DD, UTC)
+type ScheduleStatusChangeRequest struct {
+	VendorID      string  `json:"-"`
+	EntityID      string  `json:"entity_id"`
+	TargetStatus  string  `json:"target_status"`
+	EffectiveDate string  `json:"effective_date"`
+	Reason        string  `json:"reason,omitempty"`
+	RequestedBy   *string `json:"-"`
+}
+
+// ScheduledStatusReport counts the due status changes a worker run processed
+type ScheduledStatusReport struct {
+	Applied int `json:"applied"`
+	Failed  int `json:"failed"`
+}
+
+// ScheduleStatusChange schedules a status change of a vendor, applied by the
+// worker on or after its effective date. A vendor can have one pending change
+// per date.
+func (s *VendorService) ScheduleStatusChange(ctx context.Context, req *ScheduleStatusChangeRequest) (*repository.ScheduledStatusChange, error) {
+	req.Reason = strings.TrimSpace(req.Reason)
+	v := &validator{}
+	v.check(req.EntityID != "", "entity_id", "entity_id is required")
+	v.check(slices.Contains(schedulableStatuses, req.TargetStatus), "target_status",
+		fmt.Sprintf("target_status must be one of %s", strings.Join(schedulableStatuses, ", ")))
+	if date, err := time.Parse(time.DateOnly, req.EffectiveDate); err != nil {
+		v.add("effective_date", "effective_date must be a date (YYYY-MM-DD)")
+	} else {
+		v.check(req.EffectiveDate >= time.Now().UTC().Format(time.DateOnly), "effective_date",
+			"effective_date cannot be in the past")
+		req.EffectiveDate = date.Format(time.DateOnly)
+	}
+	v.check(utf8.RuneCountInString(req.Reason) <= maxScheduleReasonLength, "reason",
+		fmt.Sprintf("reason must be at most %d characters", maxScheduleReasonLength))
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	if err := s.checkEntityWritable(ctx, req.EntityID); err != nil {
+		return nil, err
+	}
+
+	var reason *string
+	if req.Reason != "" {
+		reason = &req.Reason
+	}
+	ctx = repository.UsePrimary(ctx)
+	sc := &repository.ScheduledStatusChange{
+		EntityID:      req.EntityID,
+		VendorID:      req.VendorID,
+		TargetStatus:  req.TargetStatus,
+		EffectiveDate: req.EffectiveDate,
+		Reason:        reason,
+		RequestedBy:   req.RequestedBy,
+	}
+	err := s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		vendor, err := repo.GetByID(ctx, req.VendorID, req.EntityID)
+		if err != nil {
+			return err
+		}
+		if err := repo.InsertScheduledStatusChange(ctx, sc); err != nil {
+			return err
+		}
+		return repo.InsertAuditEntry(ctx, &repository.AuditEntry{
+			EntityID: req.EntityID,
+			VendorID: req.VendorID,
+			Action:   AuditActionStatusChangeScheduled,
+			ActorID:  req.RequestedBy,
+			Details:  scheduleDetails(vendor.VendorCode, sc),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reqlog.SetEntity(ctx, req.EntityID)
+	reqlog.SetVendor(ctx, req.VendorID)
+	s.logger(ctx).Info().
+		Str("schedule_id", sc.ID).
+		Str("target_status", sc.TargetStatus).
+		Str("effective_date", sc.EffectiveDate).
+		Msg("Vendor status change scheduled")
+
+	return sc, nil
+}
+
+// CancelScheduledStatusChange cancels a pending scheduled status change of a
+// vendor and returns it
+func (s *VendorService) CancelScheduledStatusChange(ctx context.Context, id, vendorID, entityID string, cancelledBy *string) (*repository.ScheduledStatusChange, error) {
+	ctx = repository.UsePrimary(ctx)
+	if err := s.checkEntityWritable(ctx, entityID); err != nil {
+		return nil, err
+	}
+	var sc *repository.ScheduledStatusChange
+	err := s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		var err error
+		sc, err = repo.LockScheduledStatusChange(ctx, id, entityID)
+		if err != nil {
+			return err
+		}
+		if sc.VendorID != vendorID {
+			return errors.NotFound("scheduled_status_change", id)
+		}
+
+		v := &validator{}
+		v.check(sc.State == repository.ScheduleStatePending, "state",
+			fmt.Sprintf("only pending changes can be cancelled; this one is %s", sc.State))
+		if err := v.err(); err != nil {
+			return err
+		}
+
+		sc.State = repository.ScheduleStateCancelled
+		sc.CancelledBy = cancelledBy
+		if err := repo.FinishScheduledStatusChange(ctx, sc); err != nil {
+			return err
+		}
+		vendor, err := repo.GetByID(ctx, vendorID, entityID)
+		if err != nil {
+			return err
+		}
+		return repo.InsertAuditEntry(ctx, &repository.AuditEntry{
+			EntityID: entityID,
+			VendorID: vendorID,
+			Action:   AuditActionStatusChangeCancelled,
+			ActorID:  cancelledBy,
+			Details:  scheduleDetails(vendor.VendorCode, sc),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reqlog.SetEntity(ctx, entityID)
+	reqlog.SetVendor(ctx, vendorID)
+	s.logger(ctx).Info().Str("schedule_id", id).Msg("Scheduled vendor status change cancelled")
+
+	return sc, nil
+}
+
+// ListScheduledStatusChanges retrieves up to 500 scheduled status changes of
+// an entity matching filter, by effective date
+func (s *VendorService) ListScheduledStatusChanges(ctx context.Context, filter repository.ScheduledStatusChangeFilter) ([]*repository.ScheduledStatusChange, error) {
+	v := &validator{}
+	v.check(filter.EntityID != "", "entity_id", "entity_id is required")
+	v.check(filter.State == "" || slices.Contains([]string{
+		repository.ScheduleStatePending,
+		repository.ScheduleStateApplied,
+		repository.ScheduleStateFailed,
+		repository.ScheduleStateCancelled,
+	}, filter.State), "state", "state must be one of pending, applied, failed, cancelled")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	return s.vendorRepo.ListScheduledStatusChanges(ctx, filter, maxScheduledStatusList)
+}
+
+// ApplyDueStatusChanges applies the pending status changes of every entity
+// effective today or earlier (UTC), in transactions of 100. Changes the
+// vendor's current status makes invalid are marked failed with the reason
+// rather than applied. Each change is processed once, so concurrent or
+// repeated runs are safe.
+func (s *VendorService) ApplyDueStatusChanges(ctx context.Context) (*ScheduledStatusReport, error) {
+	ctx = repository.UsePrimary(ctx)
+	asOf := time.Now().UTC().Format(time.DateOnly)
+
+	report := &ScheduledStatusReport{}
+	for {
+		var claimed, applied, failed int
+		err := s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+			applied, failed = 0, 0
+			changes, err := repo.ClaimDueStatusChanges(ctx, asOf, scheduledStatusBatchSize)
+			if err != nil {
+				return err
+			}
+			claimed = len(changes)
+
+			for _, sc := range changes {
+				if err := s.applyScheduledStatusChange(ctx, repo, sc); err != nil {
+					return err
+				}
+				if sc.State == repository.ScheduleStateApplied {
+					applied++
+				} else {
+					failed++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return report, err
+		}
+		report.Applied += applied
+		report.Failed += failed
+		if claimed < scheduledStatusBatchSize {
+			return report, nil
+		}
+	}
+}
+
+// applyScheduledStatusChange moves the vendor of a due change to its target
+// status like the status endpoints do, or marks the change failed when the
+// transition is invalid, on the store of the run's transaction
+func (s *VendorService) applyScheduledStatusChange(ctx context.Context, repo repository.Store, sc *repository.ScheduledStatusChange) error {
+	vendor, err := repo.GetByID(ctx, sc.VendorID, sc.EntityID)
+	if isNotFound(err) {
+		return s.failScheduledStatusChange(ctx, repo, sc, "", "vendor not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case vendor.Status == sc.TargetStatus:
+		return s.failScheduledStatusChange(ctx, repo, sc, vendor.VendorCode, fmt.Sprintf("vendor is already %s", vendor.Status))
+	case vendor.Status == "pending_approval":
+		return s.failScheduledStatusChange(ctx, repo, sc, vendor.VendorCode, "vendor is pending approval; approve or reject it instead")
+	}
+
+	before := *vendor
+	vendor.Status = sc.TargetStatus
+	vendor.UpdatedBy = sc.RequestedBy
+	if err := s.checkApprovalTransition(ctx, repo, &before, vendor); err != nil {
+		var validationErr *ValidationError
+		if stderrors.As(err, &validationErr) {
+			return s.failScheduledStatusChange(ctx, repo, sc, vendor.VendorCode, err.Error())
+		}
+		return err
+	}
+	if err := repo.Update(ctx, vendor); err != nil {
+		return err
+	}
+	if err := s.recordVendorChanges(ctx, repo, &before, vendor, sc.RequestedBy); err != nil {
+		return err
+	}
+
+	sc.State = repository.ScheduleStateApplied
+	if err := repo.FinishScheduledStatusChange(ctx, sc); err != nil {
+		return err
+	}
+	details := scheduleDetails(vendor.VendorCode, sc)
+	details["from"] = before.Status
+	if err := repo.InsertEvent(ctx, &repository.VendorEvent{
+		EntityID:  sc.EntityID,
+		VendorID:  sc.VendorID,
+		EventType: EventVendorScheduledStatusApplied,
+		Payload:   details,
+	}); err != nil {
+		return err
+	}
+
+	s.logger(ctx).Info().
+		Str("schedule_id", sc.ID).
+		Str("vendor_id", sc.VendorID).
+		Str("from", before.Status).
+		Str("to", sc.TargetStatus).
+		Msg("Scheduled vendor status change applied")
+	return nil
+}
+
+// failScheduledStatusChange marks a due change failed with reason, auditing it
+// on the vendor unless the vendor is gone
+func (s *VendorService) failScheduledStatusChange(ctx context.Context, repo repository.Store, sc *repository.ScheduledStatusChange, vendorCode, reason string) error {
+	sc.State = repository.ScheduleStateFailed
+	sc.FailureReason = &reason
+	if err := repo.FinishScheduledStatusChange(ctx, sc); err != nil {
+		return err
+	}
+
+	s.logger(ctx).Warn().
+		Str("schedule_id", sc.ID).
+		Str("vendor_id", sc.VendorID).
+		Str("reason", reason).
+		Msg("Scheduled vendor status change failed")
+
+	details := scheduleDetails(vendorCode, sc)
+	if vendorCode != "" {
+		if err := repo.InsertAuditEntry(ctx, &repository.AuditEntry{
+			EntityID: sc.EntityID,
+			VendorID: sc.VendorID,
+			Action:   AuditActionScheduledStatusChangeFailed,
+			ActorID:  sc.RequestedBy,
+			Details:  details,
+		}); err != nil {
+			return err
+		}
+	}
+	return repo.InsertEvent(ctx, &repository.VendorEvent{
+		EntityID:  sc.EntityID,
+		VendorID:  sc.VendorID,
+		EventType: EventVendorScheduledStatusFailed,
+		Payload:   details,
+	})
+}
+
+// scheduleDetails are the audit details and event payload of a scheduled
+// status change
+func scheduleDetails(vendorCode string, sc *repository.ScheduledStatusChange) map[string]interface{} {
+	details := map[string]interface{}{
+		"schedule_id":    sc.ID,
+		"to":             sc.TargetStatus,
+		"effective_date": sc.EffectiveDate,
+	}
+	if vendorCode != "" {
+		details["vendor_code"] = vendorCode
+	}
+	if sc.Reason != nil {
+		details["reason"] = *sc.Reason
+	}
+	if sc.FailureReason != nil {
+		details["failure_reason"] = *sc.FailureReason
+	}
+	return details
+}
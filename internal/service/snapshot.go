@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/spend"
+)
+
+// Sections of a vendor snapshot that can be restricted per user
+const (
+	SnapshotContacts            = "contacts"
+	SnapshotDocuments           = "documents"
+	SnapshotBank                = "bank"
+	SnapshotBalanceTransactions = "balance_transactions"
+	SnapshotAudit               = "audit"
+	SnapshotNotes               = "notes"
+	SnapshotSpend               = "spend"
+)
+
+// SnapshotSections are the restrictable sections of a vendor snapshot
+var SnapshotSections = []string{
+	SnapshotContacts,
+	SnapshotDocuments,
+	SnapshotBank,
+	SnapshotBalanceTransactions,
+	SnapshotAudit,
+	SnapshotNotes,
+	SnapshotSpend,
+}
+
+// snapshotListLimit bounds the documents, balance transactions and audit
+// entries of a snapshot
+const snapshotListLimit = 10
+
+// BankDetails are the bank account fields of a vendor
+type BankDetails struct {
+	BankName          *string `json:"bank_name"`
+	BankAccountNumber *string `json:"bank_account_number"`
+	BankRoutingNumber *string `json:"bank_routing_number"`
+	SwiftCode         *string `json:"swift_code"`
+	IBAN              *string `json:"iban"`
+}
+
+// VendorSnapshot is everything the vendor detail page shows, loaded at once.
+// Sections the caller may not see are nil and listed in Omitted. Spend the
+// payments service could not provide is nil with a warning in Warnings.
+type VendorSnapshot struct {
+	Vendor              *repository.Vendor               `json:"vendor"`
+	Contacts            []*repository.VendorContact      `json:"contacts,omitzero"`
+	Documents           []*repository.VendorDocument     `json:"documents,omitzero"`
+	Bank                *BankDetails                     `json:"bank,omitzero"`
+	BalanceTransactions []*repository.BalanceTransaction `json:"balance_transactions,omitzero"`
+	AuditEntries        []*repository.AuditEntry         `json:"audit_entries,omitzero"`
+	Notes               *string                          `json:"notes,omitempty"`
+	Spend               []*spend.Summary                 `json:"spend,omitzero"`
+	Omitted             []string                         `json:"omitted,omitempty"`
+	Warnings            []string                         `json:"warnings,omitempty"`
+
+	// Timings is how long each section took to load, in the order they finished
+	Timings []SectionTiming `json:"-"`
+}
+
+// SectionTiming is the load time of a snapshot section
+type SectionTiming struct {
+	Section  string
+	Duration time.Duration
+}
+
+// GetVendorSnapshot loads a vendor with its contacts, latest documents, bank
+// details, latest balance transactions, latest audit entries, notes and spend.
+// The sections are loaded in parallel after the vendor; allowed decides which
+// sections the caller may see.
+func (s *VendorService) GetVendorSnapshot(ctx context.Context, id, entityID string, allowed func(section string) bool) (*VendorSnapshot, error) {
+	start := time.Now()
+	vendor, err := s.vendorRepo.GetByID(ctx, id, entityID)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &VendorSnapshot{
+		Timings: []SectionTiming{{Section: "vendor", Duration: time.Since(start)}},
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		loadErr error
+	)
+	load := func(section string, fn func() error) {
+		if !allowed(section) {
+			snapshot.Omitted = append(snapshot.Omitted, section)
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := fn()
+
+			mu.Lock()
+			defer mu.Unlock()
+			snapshot.Timings = append(snapshot.Timings, SectionTiming{Section: section, Duration: time.Since(start)})
+			if err != nil && loadErr == nil {
+				loadErr = err
+			}
+		}()
+	}
+
+	load(SnapshotContacts, func() (err error) {
+		snapshot.Contacts, err = s.vendorRepo.GetContacts(ctx, vendor.ID)
+		return err
+	})
+	load(SnapshotDocuments, func() (err error) {
+		snapshot.Documents, err = s.vendorRepo.ListDocuments(ctx, vendor.ID, snapshotListLimit)
+		return err
+	})
+	load(SnapshotBalanceTransactions, func() (err error) {
+		snapshot.BalanceTransactions, err = s.vendorRepo.ListBalanceTransactions(ctx, vendor.ID, entityID, snapshotListLimit)
+		return err
+	})
+	load(SnapshotAudit, func() (err error) {
+		snapshot.AuditEntries, err = s.vendorRepo.ListVendorAuditEntries(ctx, vendor.ID, entityID, snapshotListLimit)
+		return err
+	})
+	// The spend section comes from the payments service; its failure only
+	// leaves it out
+	var spendErr error
+	load(SnapshotSpend, func() error {
+		snapshot.Spend, spendErr = s.loadSpend(ctx, vendor.ID, entityID, spend.Periods)
+		return nil
+	})
+	wg.Wait()
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	if spendErr != nil {
+		snapshot.Warnings = append(snapshot.Warnings, spendUnavailable)
+	}
+
+	// Bank details and notes are columns of the vendor; the vendor section only
+	// carries them in their own sections
+	if allowed(SnapshotBank) {
+		snapshot.Bank = &BankDetails{
+			BankName:          vendor.BankName,
+			BankAccountNumber: vendor.BankAccountNumber,
+			BankRoutingNumber: vendor.BankRoutingNumber,
+			SwiftCode:         vendor.SwiftCode,
+			IBAN:              vendor.IBAN,
+		}
+	} else {
+		snapshot.Omitted = append(snapshot.Omitted, SnapshotBank)
+	}
+	if allowed(SnapshotNotes) {
+		snapshot.Notes = vendor.Notes
+	} else {
+		snapshot.Omitted = append(snapshot.Omitted, SnapshotNotes)
+	}
+	vendor.BankName, vendor.BankAccountNumber, vendor.BankRoutingNumber, vendor.SwiftCode, vendor.IBAN = nil, nil, nil, nil, nil
+	vendor.Notes = nil
+	snapshot.Vendor = vendor
+
+	s.logger(ctx).Debug().
+		Strs("omitted", snapshot.Omitted).
+		Dur("duration", time.Since(start)).
+		Msg("Vendor snapshot loaded")
+
+	return snapshot, nil
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/spend"
+)
+
+// spendUnavailable is the warning returned in place of spend the payments
+// service could not provide
+const spendUnavailable = "spend is unavailable: the payments service did not respond"
+
+// VendorSpend is what an entity paid a vendor. Spend is left out with a warning
+// when the payments service fails.
+type VendorSpend struct {
+	VendorID string           `json:"vendor_id"`
+	EntityID string           `json:"entity_id"`
+	Spend    []*spend.Summary `json:"spend,omitzero"`
+	Warnings []string         `json:"warnings,omitempty"`
+}
+
+// GetVendorSpend returns the spend of a vendor within periods, or within every
+// supported period when none are given
+func (s *VendorService) GetVendorSpend(ctx context.Context, id, entityID string, periods []string) (*VendorSpend, error) {
+	v := &validator{}
+	v.check(!slices.ContainsFunc(periods, func(period string) bool { return !spend.IsValidPeriod(period) }),
+		"period", "period must be ytd or ttm")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	if len(periods) == 0 {
+		periods = spend.Periods
+	}
+
+	vendor, err := s.vendorRepo.GetByID(ctx, id, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &VendorSpend{VendorID: vendor.ID, EntityID: vendor.EntityID}
+	if result.Spend, err = s.loadSpend(ctx, vendor.ID, vendor.EntityID, periods); err != nil {
+		result.Warnings = append(result.Warnings, spendUnavailable)
+	}
+	return result, nil
+}
+
+// loadSpend asks the spend provider for every period in parallel. A failure is
+// logged and returned, for callers to leave spend out rather than fail.
+func (s *VendorService) loadSpend(ctx context.Context, vendorID, entityID string, periods []string) ([]*spend.Summary, error) {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	summaries := make([]*spend.Summary, len(periods))
+	for i, period := range periods {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			summary, err := s.spend.GetVendorSpend(ctx, vendorID, entityID, period)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			summaries[i] = summary
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		s.logger(ctx).Warn().Err(firstErr).Msg("Vendor spend unavailable")
+		return nil, firstErr
+	}
+	return summaries, nil
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+)
+
+// Page sizes of the suspected test vendor list
+const (
+	// DefaultSuspectedTestPageSize is the number of vendors listed when no page size is requested
+	DefaultSuspectedTestPageSize = 50
+	// MaxSuspectedTestPageSize is the largest number of vendors listed at once
+	MaxSuspectedTestPageSize = 200
+)
+
+// Actions confirming suspected test vendors
+const (
+	// TestVendorActionTag tags the vendors test-vendor, which keeps them
+	// flagged and out of lists, exports and 1099s excluding test vendors
+	TestVendorActionTag = "tag"
+	// TestVendorActionDelete soft-deletes the vendors as a bulk delete does
+	TestVendorActionDelete = "delete"
+)
+
+// SuspectedTestPage is a page of the probable test vendors of an entity
+type SuspectedTestPage struct {
+	EntityID string                            `json:"entity_id"`
+	Vendors  []*repository.SuspectedTestVendor `json:"vendors"`
+	Total    int64                             `json:"total"`
+	Page     int                               `json:"page"`
+	PageSize int                               `json:"page_size"`
+}
+
+// ConfirmTestVendorsRequest confirms vendors as test vendors, tagging them or
+// soft-deleting them. Deletes go through a dry run and confirmation token as
+// bulk deletes do.
+type ConfirmTestVendorsRequest struct {
+	EntityID          string   `json:"entity_id"`
+	IDs               []string `json:"ids"`
+	Action            string   `json:"action,omitempty"`
+	DryRun            bool     `json:"dry_run,omitempty"`
+	ConfirmationToken string   `json:"confirmation_token,omitempty"`
+	UpdatedBy         *string  `json:"updated_by,omitempty"`
+}
+
+// TestVendorConfirmation is the outcome of confirming test vendors: the
+// vendors tagged, or the bulk delete report
+type TestVendorConfirmation struct {
+	Action string                     `json:"action"`
+	Tagged []*repository.TaggedVendor `json:"tagged,omitempty"`
+	Delete *BulkDeleteReport          `json:"delete,omitempty"`
+}
+
+// ListSuspectedTestVendors lists a page of the live vendors of an entity
+// flagged as probable test vendors, each with the rules it matches
+func (s *VendorService) ListSuspectedTestVendors(ctx context.Context, entityID string, page, pageSize int) (*SuspectedTestPage, error) {
+	v := &validator{}
+	v.check(entityID != "", "entity_id", "entity_id is required")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	vendors, total, err := s.vendorRepo.ListSuspectedTestVendors(ctx, entityID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	reqlog.SetEntity(ctx, entityID)
+	return &SuspectedTestPage{EntityID: entityID, Vendors: vendors, Total: total, Page: page, PageSize: pageSize}, nil
+}
+
+// ConfirmTestVendors acts on vendors confirmed to be test vendors: tagging
+// them test-vendor, the default, and auditing every vendor tagged, or
+// soft-deleting them through BulkDeleteVendors
+func (s *VendorService) ConfirmTestVendors(ctx context.Context, req *ConfirmTestVendorsRequest) (*TestVendorConfirmation, error) {
+	if req.Action == "" {
+		req.Action = TestVendorActionTag
+	}
+
+	v := &validator{}
+	v.check(req.EntityID != "", "entity_id", "entity_id is required")
+	v.check(len(req.IDs) > 0, "ids", "ids are required")
+	v.check(len(req.IDs) <= maxBulkDelete, "ids", fmt.Sprintf("at most %d ids can be confirmed at once", maxBulkDelete))
+	v.check(slices.Contains([]string{TestVendorActionTag, TestVendorActionDelete}, req.Action), "action",
+		fmt.Sprintf("action must be %s or %s, got %q", TestVendorActionTag, TestVendorActionDelete, req.Action))
+	v.check(req.Action == TestVendorActionDelete || (!req.DryRun && req.ConfirmationToken == ""), "action",
+		"dry_run and confirmation_token only apply to the delete action")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	if req.Action == TestVendorActionDelete {
+		report, err := s.BulkDeleteVendors(ctx, &BulkDeleteRequest{
+			EntityID:          req.EntityID,
+			IDs:               req.IDs,
+			DryRun:            req.DryRun,
+			ConfirmationToken: req.ConfirmationToken,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &TestVendorConfirmation{Action: req.Action, Delete: report}, nil
+	}
+
+	if err := s.checkEntityWritable(ctx, req.EntityID); err != nil {
+		return nil, err
+	}
+
+	var tagged []*repository.TaggedVendor
+	err := s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		var err error
+		if tagged, err = repo.AddTag(ctx, req.EntityID, req.IDs, repository.TestVendorTag, maxTags, req.UpdatedBy); err != nil {
+			return err
+		}
+		return auditTagChange(ctx, repo, req.EntityID, tagged, AuditActionTagAdded, req.UpdatedBy,
+			map[string]interface{}{"tag": repository.TestVendorTag})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reqlog.SetEntity(ctx, req.EntityID)
+	s.logger(ctx).Info().Int("vendors", len(tagged)).Msg("Test vendors tagged")
+	return &TestVendorConfirmation{Action: req.Action, Tagged: tagged}, nil
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"unicode"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+)
+
+// Limits on the tags of a vendor, in normalized form
+const (
+	maxTags      = 20
+	maxTagLength = 30
+)
+
+// Audit actions written for each vendor changed by a tag add, rename or
+// delete
+const (
+	AuditActionTagAdded   = "tag_added"
+	AuditActionTagRenamed = "tag_renamed"
+	AuditActionTagDeleted = "tag_deleted"
+)
+
+// tagPunctuation are the characters allowed in tags besides letters, digits
+// and spaces
+const tagPunctuation = "-_.&"
+
+// NormalizeTag returns the form tags are stored and filtered in: trimmed,
+// lowercased and with runs of whitespace collapsed to one space
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.Join(strings.Fields(tag), " "))
+}
+
+// checkTag records a violation of field unless tag, in normalized form, is
+// non-empty, short enough and made of allowed characters
+func checkTag(v *validator, field, tag string) {
+	if tag == "" {
+		v.add(field, "tags cannot be empty")
+		return
+	}
+	checkLength(v, field, tag, maxTagLength)
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != ' ' && !strings.ContainsRune(tagPunctuation, r) {
+			v.add(field, fmt.Sprintf("tag %q may only contain letters, digits, spaces and %s", tag, strings.Join(strings.Split(tagPunctuation, ""), " ")))
+			return
+		}
+	}
+}
+
+// normalizeTags validates requested tags and returns them normalized without
+// duplicates, in the order given. field names the tags in violations.
+func normalizeTags(v *validator, field string, tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for i, tag := range tags {
+		tag = NormalizeTag(tag)
+		checkTag(v, fmt.Sprintf("%s[%d]", field, i), tag)
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	v.check(len(normalized) <= maxTags, field, fmt.Sprintf("at most %d tags are allowed", maxTags))
+	return normalized
+}
+
+// ListTags counts the live vendors of an entity by tag, most used first
+func (s *VendorService) ListTags(ctx context.Context, entityID string) ([]*repository.TagCount, error) {
+	v := &validator{}
+	v.check(entityID != "", "entity_id", "entity_id is required")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return s.vendorRepo.ListTagCounts(ctx, entityID)
+}
+
+// RenameTag renames a tag on every live vendor of an entity, merging it into
+// to where a vendor already has both, and audits every changed vendor. It
+// returns the number of vendors changed.
+func (s *VendorService) RenameTag(ctx context.Context, entityID, from, to string, actorID *string) (int, error) {
+	from, to = NormalizeTag(from), NormalizeTag(to)
+
+	v := &validator{}
+	v.check(entityID != "", "entity_id", "entity_id is required")
+	v.check(from != "", "from", "from is required")
+	checkTag(v, "to", to)
+	v.check(from != to, "to", "to must differ from from")
+	if err := v.err(); err != nil {
+		return 0, err
+	}
+	if err := s.checkEntityWritable(ctx, entityID); err != nil {
+		return 0, err
+	}
+
+	var changed []*repository.TaggedVendor
+	err := s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		var err error
+		if changed, err = repo.RenameTag(ctx, entityID, from, to, actorID); err != nil {
+			return err
+		}
+		return auditTagChange(ctx, repo, entityID, changed, AuditActionTagRenamed, actorID,
+			map[string]interface{}{"from": from, "to": to})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	reqlog.SetEntity(ctx, entityID)
+	s.logger(ctx).Info().Str("from", from).Str("to", to).Int("vendors", len(changed)).Msg("Tag renamed")
+	return len(changed), nil
+}
+
+// DeleteTag removes a tag from every live vendor of an entity and audits
+// every changed vendor. It returns the number of vendors changed.
+func (s *VendorService) DeleteTag(ctx context.Context, entityID, tag string, actorID *string) (int, error) {
+	tag = NormalizeTag(tag)
+
+	v := &validator{}
+	v.check(entityID != "", "entity_id", "entity_id is required")
+	v.check(tag != "", "tag", "tag is required")
+	if err := v.err(); err != nil {
+		return 0, err
+	}
+	if err := s.checkEntityWritable(ctx, entityID); err != nil {
+		return 0, err
+	}
+
+	var changed []*repository.TaggedVendor
+	err := s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		var err error
+		if changed, err = repo.DeleteTag(ctx, entityID, tag, actorID); err != nil {
+			return err
+		}
+		return auditTagChange(ctx, repo, entityID, changed, AuditActionTagDeleted, actorID,
+			map[string]interface{}{"tag": tag})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	reqlog.SetEntity(ctx, entityID)
+	s.logger(ctx).Info().Str("tag", tag).Int("vendors", len(changed)).Msg("Tag deleted")
+	return len(changed), nil
+}
+
+// auditTagChange writes an audit entry with details for each vendor changed
+// by a tag add, rename or delete
+func auditTagChange(ctx context.Context, repo repository.Store, entityID string, changed []*repository.TaggedVendor, action string, actorID *string, details map[string]interface{}) error {
+	for _, vendor := range changed {
+		entry := &repository.AuditEntry{
+			EntityID: entityID,
+			VendorID: vendor.ID,
+			Action:   action,
+			ActorID:  actorID,
+			Details:  map[string]interface{}{"vendor_code": vendor.VendorCode},
+		}
+		maps.Copy(entry.Details, details)
+		if err := repo.InsertAuditEntry(ctx, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-ap-vendors/internal/tinmatch"
+)
+
+// tinMatchBatchSize is how many vendors a TIN matching run reads, and sends
+// to the matcher, at a time
+const tinMatchBatchSize = 500
+
+// TINMatchReport is the outcome of a TIN matching run
+type TINMatchReport struct {
+	EntityID string `json:"entity_id,omitempty"`
+	// Scanned counts the live 1099 vendors looked at
+	Scanned int `json:"scanned"`
+	// MissingData counts the 1099 vendors without a tax ID or legal name
+	MissingData int `json:"missing_data"`
+	// Checked counts the vendors sent to the matcher: those never verified,
+	// changed since, or pending
+	Checked    int `json:"checked"`
+	Matched    int `json:"matched"`
+	Mismatched int `json:"mismatched"`
+	Pending    int `json:"pending"`
+	Unverified int `json:"unverified"`
+	// Throttled is set when the run stopped early because the provider
+	// throttled it; the vendors left are checked on the next run
+	Throttled bool `json:"throttled,omitempty"`
+}
+
+// tinFingerprint identifies the tax ID and legal name of a vendor, or is
+// empty when either is missing
+func tinFingerprint(vendor *repository.Vendor) string {
+	tin := normalizeTIN(deref(vendor.TaxID))
+	name := strings.ToUpper(strings.Join(strings.Fields(deref(vendor.LegalName)), " "))
+	if tin == "" || name == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(tin + "\x00" + name))
+	return hex.EncodeToString(sum[:])
+}
+
+// currentTINMatch returns the stored result of a vendor when it applies to
+// the vendor's current tax ID and legal name, or nil
+func currentTINMatch(vendor *repository.Vendor, match *repository.TINMatch) *repository.TINMatch {
+	if match == nil || match.Fingerprint != tinFingerprint(vendor) {
+		return nil
+	}
+	return match
+}
+
+// tinMatchStatus returns the verification status of a vendor's current tax ID
+// and legal name
+func tinMatchStatus(vendor *repository.Vendor, match *repository.TINMatch) string {
+	if match = currentTINMatch(vendor, match); match == nil {
+		return tinmatch.StatusUnverified
+	}
+	return match.Status
+}
+
+// attachTINMatches sets the TIN matching results of vendors. 1099 vendors
+// never verified, or changed since, get an unverified result.
+func (s *VendorService) attachTINMatches(ctx context.Context, vendors ...*repository.Vendor) error {
+	ids := make([]string, len(vendors))
+	for i, vendor := range vendors {
+		ids[i] = vendor.ID
+	}
+	matches, err := s.vendorRepo.ListTINMatches(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, vendor := range vendors {
+		vendor.TINMatch = currentTINMatch(vendor, matches[vendor.ID])
+		if vendor.TINMatch == nil && vendor.Is1099Vendor {
+			vendor.TINMatch = &repository.TINMatch{Status: tinmatch.StatusUnverified}
+		}
+	}
+	return nil
+}
+
+// tinMatchWarnings returns the warnings about the TIN matching result of a
+// 1099 vendor. Unverified vendors get none: that is the state of every vendor
+// until TIN matching runs.
+func tinMatchWarnings(vendor *repository.Vendor) []string {
+	if !vendor.Is1099Vendor || vendor.TINMatch == nil {
+		return nil
+	}
+	switch vendor.TINMatch.Status {
+	case tinmatch.StatusMismatched:
+		return []string{"tax_id and legal_name do not match IRS records; 1099s filed with them may be rejected"}
+	case tinmatch.StatusPending:
+		return []string{"tax_id and legal_name verification with the IRS is pending"}
+	}
+	return nil
+}
+
+// VerifyVendorTIN verifies the tax ID and legal name of a vendor with the TIN
+// matcher and stores the result
+func (s *VendorService) VerifyVendorTIN(ctx context.Context, id, entityID string) (*repository.TINMatch, error) {
+	ctx = repository.UsePrimary(ctx)
+
+	vendor, err := s.vendorRepo.GetByID(ctx, id, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &validator{}
+	v.check(normalizeTIN(deref(vendor.TaxID)) != "", "tax_id", "the vendor has no tax_id to verify")
+	v.check(strings.TrimSpace(deref(vendor.LegalName)) != "", "legal_name", "the vendor has no legal_name to verify")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	matches, err := s.matchTINs(ctx, []*repository.Vendor{vendor})
+	if len(matches) == 0 {
+		return nil, err
+	}
+
+	reqlog.SetEntity(ctx, entityID)
+	reqlog.SetVendor(ctx, id)
+	s.logger(ctx).Info().Str("tin_match_status", matches[0].Status).Msg("Vendor TIN verified")
+
+	return matches[0], nil
+}
+
+// RunTINMatching verifies the live 1099 vendors of an entity, or of all
+// entities when entityID is empty, whose tax ID and legal name were never
+// verified, changed since, or are pending at the provider. Vendors are read
+// and sent to the matcher in keyset batches of 500; a run throttled by the
+// provider stops early, keeping the results so far, and the next run picks
+// up the rest.
+func (s *VendorService) RunTINMatching(ctx context.Context, entityID string) (*TINMatchReport, error) {
+	ctx = repository.WithBulkBudget(repository.UsePrimary(ctx))
+
+	report := &TINMatchReport{EntityID: entityID}
+	var afterID string
+	for {
+		vendors, err := s.vendorRepo.ListVendorBatch(ctx, entityID, afterID, tinMatchBatchSize)
+		if err != nil {
+			return nil, err
+		}
+
+		var candidates []*repository.Vendor
+		for _, vendor := range vendors {
+			if !vendor.Is1099Vendor {
+				continue
+			}
+			report.Scanned++
+			if tinFingerprint(vendor) == "" {
+				report.MissingData++
+				continue
+			}
+			candidates = append(candidates, vendor)
+		}
+
+		due, err := s.dueForTINMatching(ctx, candidates)
+		if err != nil {
+			return nil, err
+		}
+		matches, err := s.matchTINs(ctx, due)
+		for _, match := range matches {
+			report.Checked++
+			switch match.Status {
+			case tinmatch.StatusMatched:
+				report.Matched++
+			case tinmatch.StatusMismatched:
+				report.Mismatched++
+			case tinmatch.StatusPending:
+				report.Pending++
+			default:
+				report.Unverified++
+			}
+		}
+		var throttled *tinmatch.ThrottledError
+		if stderrors.As(err, &throttled) {
+			report.Throttled = true
+			s.logger(ctx).Warn().Dur("retry_after", throttled.RetryAfter).Msg("TIN matching run throttled by the provider")
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(vendors) < tinMatchBatchSize {
+			break
+		}
+		afterID = vendors[len(vendors)-1].ID
+	}
+
+	s.logger(ctx).Info().
+		Str("entity_id", entityID).
+		Int("scanned", report.Scanned).
+		Int("checked", report.Checked).
+		Int("mismatched", report.Mismatched).
+		Bool("throttled", report.Throttled).
+		Msg("TIN matching run finished")
+
+	return report, nil
+}
+
+// dueForTINMatching returns the vendors whose current tax ID and legal name
+// have no final result: never verified, changed since, or pending
+func (s *VendorService) dueForTINMatching(ctx context.Context, vendors []*repository.Vendor) ([]*repository.Vendor, error) {
+	if len(vendors) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(vendors))
+	for i, vendor := range vendors {
+		ids[i] = vendor.ID
+	}
+	matches, err := s.vendorRepo.ListTINMatches(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var due []*repository.Vendor
+	for _, vendor := range vendors {
+		switch tinMatchStatus(vendor, matches[vendor.ID]) {
+		case tinmatch.StatusUnverified, tinmatch.StatusPending:
+			due = append(due, vendor)
+		}
+	}
+	return due, nil
+}
+
+// matchTINs sends the tax IDs and legal names of vendors to the matcher and
+// stores the results. On a matcher error the results received before it are
+// stored and returned with the error.
+func (s *VendorService) matchTINs(ctx context.Context, vendors []*repository.Vendor) ([]*repository.TINMatch, error) {
+	if len(vendors) == 0 {
+		return nil, nil
+	}
+
+	reqs := make([]tinmatch.Request, len(vendors))
+	for i, vendor := range vendors {
+		reqs[i] = tinmatch.Request{
+			TIN:  normalizeTIN(deref(vendor.TaxID)),
+			Name: strings.TrimSpace(deref(vendor.LegalName)),
+		}
+	}
+	results, matchErr := s.tinMatcher.Match(ctx, reqs)
+	if matchErr != nil {
+		matchErr = fmt.Errorf("verify TIN: %w", matchErr)
+	}
+
+	now := time.Now().UTC()
+	matches := make([]*repository.TINMatch, 0, len(results))
+	for i, result := range results[:min(len(results), len(vendors))] {
+		match := &repository.TINMatch{
+			VendorID:    vendors[i].ID,
+			EntityID:    vendors[i].EntityID,
+			Status:      result.Status,
+			Fingerprint: tinFingerprint(vendors[i]),
+			CheckedAt:   now,
+		}
+		if result.Code != "" {
+			match.Code = &result.Code
+		}
+		if err := s.vendorRepo.UpsertTINMatch(ctx, match); err != nil {
+			return matches, err
+		}
+		matches = append(matches, match)
+	}
+	return matches, matchErr
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// maxVendorCodeLength matches vendors.vendor_code VARCHAR(50)
+const maxVendorCodeLength = 50
+
+// Audit actions and event types written for vendor transfers
+const (
+	AuditActionTransferOut = "transfer_out"
+	AuditActionTransferIn  = "transfer_in"
+
+	EventVendorTransferredOut = "vendor.transferred_out"
+	EventVendorTransferredIn  = "vendor.transferred_in"
+)
+
+// TransferVendorRequest represents an admin request to move a vendor to another entity
+type TransferVendorRequest struct {
+	VendorID     string `json:"vendor_id"`
+	FromEntityID string `json:"from_entity_id"`
+	ToEntityID   string `json:"to_entity_id"`
+	// NewVendorCode is the code to use in the target entity. When empty the current
+	// code is kept, or suffixed ("-2", "-3", ...) if it is taken in the target entity.
+	NewVendorCode string `json:"new_vendor_code,omitempty"`
+	TransferredBy string `json:"-"`
+}
+
+// TransferVendor re-homes a vendor, with its contacts, documents, external refs
+// and balance ledger, to another entity in a single transaction. Audit entries and events are
+// written for both entities, and the source entity's change feed receives a
+// tombstone for the vendor.
+func (s *VendorService) TransferVendor(ctx context.Context, req *TransferVendorRequest) (*repository.Vendor, error) {
+	if req.VendorID == "" || req.FromEntityID == "" || req.ToEntityID == "" {
+		return nil, errors.InvalidInput("vendor_id", "vendor_id, from_entity_id and to_entity_id are required")
+	}
+	if req.FromEntityID == req.ToEntityID {
+		return nil, errors.InvalidInput("to_entity_id", "target entity must differ from the source entity")
+	}
+
+	newCode := strings.TrimSpace(req.NewVendorCode)
+	if len(newCode) > maxVendorCodeLength {
+		return nil, errors.InvalidInput("new_vendor_code", fmt.Sprintf("new_vendor_code must be at most %d characters", maxVendorCodeLength))
+	}
+
+	for _, entityID := range []string{req.FromEntityID, req.ToEntityID} {
+		if err := s.checkEntityWritable(ctx, entityID); err != nil {
+			return nil, err
+		}
+	}
+
+	var transferredBy *string
+	if req.TransferredBy != "" {
+		transferredBy = &req.TransferredBy
+	}
+
+	var moved *repository.Vendor
+	err := s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		vendor, err := repo.GetByID(ctx, req.VendorID, req.FromEntityID)
+		if err != nil {
+			return err
+		}
+
+		code, err := resolveTransferCode(ctx, repo, vendor.VendorCode, newCode, req.ToEntityID)
+		if err != nil {
+			return err
+		}
+
+		moved, err = repo.TransferVendor(ctx, vendor.ID, req.FromEntityID, req.ToEntityID, code, transferredBy)
+		if err != nil {
+			return err
+		}
+
+		if err := repo.MoveExternalRefs(ctx, vendor.ID, req.ToEntityID); err != nil {
+			return err
+		}
+
+		if err := repo.MoveBalanceLedger(ctx, vendor.ID, req.ToEntityID); err != nil {
+			return err
+		}
+
+		if err := repo.InsertChangeTombstone(ctx, req.FromEntityID, vendor.ID, vendor.VendorCode); err != nil {
+			return err
+		}
+
+		details := map[string]interface{}{
+			"from_entity_id":       req.FromEntityID,
+			"to_entity_id":         req.ToEntityID,
+			"previous_vendor_code": vendor.VendorCode,
+			"vendor_code":          code,
+			"current_balance":      vendor.CurrentBalance,
+		}
+
+		for _, side := range []struct{ entityID, action, event string }{
+			{req.FromEntityID, AuditActionTransferOut, EventVendorTransferredOut},
+			{req.ToEntityID, AuditActionTransferIn, EventVendorTransferredIn},
+		} {
+			if err := repo.InsertAuditEntry(ctx, &repository.AuditEntry{
+				EntityID: side.entityID,
+				VendorID: vendor.ID,
+				Action:   side.action,
+				ActorID:  transferredBy,
+				Details:  details,
+			}); err != nil {
+				return err
+			}
+
+			if err := repo.InsertEvent(ctx, &repository.VendorEvent{
+				EntityID:  side.entityID,
+				VendorID:  vendor.ID,
+				EventType: side.event,
+				Payload:   details,
+			}); err != nil {
+				return err
+			}
+		}
+
+		// Rescore with the weights of the target entity
+		return s.rescoreVendor(ctx, repo, moved)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reqlog.SetVendor(ctx, moved.ID)
+	s.logger(ctx).Info().
+		Str("from_entity_id", req.FromEntityID).
+		Str("to_entity_id", req.ToEntityID).
+		Str("vendor_code", moved.VendorCode).
+		Str("transferred_by", req.TransferredBy).
+		Msg("Vendor transferred")
+
+	return moved, nil
+}
+
+// resolveTransferCode picks the vendor code to use in the target entity. An
+// explicit code must be free; otherwise the current code is kept or suffixed.
+func resolveTransferCode(ctx context.Context, repo repository.Store, currentCode, explicitCode, toEntityID string) (string, error) {
+	codeTaken := func(code string) bool {
+		existing, _ := repo.GetByCode(ctx, code, toEntityID)
+		return existing != nil
+	}
+
+	if explicitCode != "" {
+		if codeTaken(explicitCode) {
+			return "", errors.AlreadyExists("vendor", explicitCode)
+		}
+		return explicitCode, nil
+	}
+
+	for n := 1; n <= 100; n++ {
+		code := currentCode
+		if n > 1 {
+			suffix := fmt.Sprintf("-%d", n)
+			base := currentCode
+			if len(base)+len(suffix) > maxVendorCodeLength {
+				base = base[:maxVendorCodeLength-len(suffix)]
+			}
+			code = base + suffix
+		}
+
+		if !codeTaken(code) {
+			return code, nil
+		}
+	}
+
+	return "", errors.AlreadyExists("vendor", currentCode)
+}
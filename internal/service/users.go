@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/identity"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+// ExpandUsers includes the users of a vendor's created_by and updated_by in
+// GetVendor
+const ExpandUsers = "users"
+
+// resolveUsers looks up the users of ids in one batch. Resolving users only
+// decorates a response, so a failed lookup is logged and returns no users,
+// leaving the IDs alone.
+func (s *VendorService) resolveUsers(ctx context.Context, ids []string) map[string]*identity.User {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) == 0 {
+		return nil
+	}
+
+	users, err := s.users.ResolveUsers(ctx, unique)
+	if err != nil {
+		s.logger(ctx).Warn().Err(err).Int("users", len(unique)).Msg("Failed to resolve users; returning IDs only")
+		return nil
+	}
+	return users
+}
+
+// attachUsers sets the users of the created_by and updated_by of vendors,
+// with a single lookup for all of them
+func (s *VendorService) attachUsers(ctx context.Context, vendors ...*repository.Vendor) {
+	var ids []string
+	for _, vendor := range vendors {
+		if vendor.CreatedBy != nil {
+			ids = append(ids, *vendor.CreatedBy)
+		}
+		if vendor.UpdatedBy != nil {
+			ids = append(ids, *vendor.UpdatedBy)
+		}
+	}
+	users := s.resolveUsers(ctx, ids)
+	for _, vendor := range vendors {
+		if vendor.CreatedBy != nil {
+			vendor.CreatedByUser = users[*vendor.CreatedBy]
+		}
+		if vendor.UpdatedBy != nil {
+			vendor.UpdatedByUser = users[*vendor.UpdatedBy]
+		}
+	}
+}
+
+// attachActorUsers sets the users of the actors of activity items, with a
+// single lookup for all of them
+func (s *VendorService) attachActorUsers(ctx context.Context, items []*ActivityItem) {
+	var ids []string
+	for _, item := range items {
+		if item.Actor != nil {
+			ids = append(ids, *item.Actor)
+		}
+	}
+	users := s.resolveUsers(ctx, ids)
+	for _, item := range items {
+		if item.Actor != nil {
+			item.ActorUser = users[*item.Actor]
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldViolation describes a single invalid request field
+type FieldViolation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError aggregates every field violation found while validating a
+// request, so callers can report all of them at once
+type ValidationError struct {
+	Violations []FieldViolation
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Violations) == 1 {
+		return fmt.Sprintf("%s: %s", e.Violations[0].Field, e.Violations[0].Message)
+	}
+
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = fmt.Sprintf("%s: %s", v.Field, v.Message)
+	}
+	return fmt.Sprintf("%d validation errors: %s", len(e.Violations), strings.Join(parts, "; "))
+}
+
+// validator collects field violations
+type validator struct {
+	violations []FieldViolation
+}
+
+// add records a violation of field
+func (v *validator) add(field, message string) {
+	v.violations = append(v.violations, FieldViolation{Field: field, Message: message})
+}
+
+// check records a violation of field unless ok
+func (v *validator) check(ok bool, field, message string) {
+	if !ok {
+		v.add(field, message)
+	}
+}
+
+// err returns a *ValidationError with every recorded violation, or nil
+func (v *validator) err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Violations: v.violations}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+// Audit actions written when a vendor's status, fields or notes change
+const (
+	AuditActionStatusChanged = "status_changed"
+	AuditActionVendorUpdated = "vendor_updated"
+	AuditActionNotesChanged  = "notes_changed"
+)
+
+// untrackedFields are vendor fields not reported as field edits: bookkeeping,
+// loaded relations, and fields with their own audit action
+var untrackedFields = map[string]bool{
+	"id": true, "entity_id": true, "status": true, "notes": true, "current_balance": true,
+	"created_by": true, "created_at": true, "updated_by": true, "updated_at": true,
+	"deleted_at": true, "change_seq": true, "contacts": true, "external_refs": true, "warnings": true, "approval": true, "risk": true,
+	"bank_name": true, "bank_account_number": true, "bank_routing_number": true, "swift_code": true, "iban": true,
+}
+
+// changedFields returns the tracked fields that differ between two versions of
+// a vendor, by JSON name
+func changedFields(before, after *repository.Vendor) []string {
+	old, updated := vendorFields(before), vendorFields(after)
+
+	var changed []string
+	for name := range updated {
+		if !untrackedFields[name] && !reflect.DeepEqual(old[name], updated[name]) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range old {
+		if _, ok := updated[name]; !ok && !untrackedFields[name] {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// vendorFields returns the fields of a vendor by JSON name
+func vendorFields(v *repository.Vendor) map[string]interface{} {
+	data, _ := json.Marshal(v)
+	var fields map[string]interface{}
+	json.Unmarshal(data, &fields)
+	return fields
+}
+
+// recordVendorChanges writes audit entries for a changed status, changed notes
+// and other edited fields. Bank details are recorded by recordBankDetailsChange.
+// It runs on the store of the update's transaction.
+func (s *VendorService) recordVendorChanges(ctx context.Context, repo repository.Store, before, after *repository.Vendor, changedBy *string) error {
+	var entries []*repository.AuditEntry
+
+	if before.Status != after.Status {
+		entries = append(entries, &repository.AuditEntry{
+			Action:  AuditActionStatusChanged,
+			Details: map[string]interface{}{"from": before.Status, "to": after.Status},
+		})
+	}
+	if !equalStrings(before.Notes, after.Notes) {
+		entries = append(entries, &repository.AuditEntry{
+			Action:  AuditActionNotesChanged,
+			Details: map[string]interface{}{"notes": after.Notes},
+		})
+	}
+	if changed := changedFields(before, after); len(changed) > 0 {
+		entries = append(entries, &repository.AuditEntry{
+			Action:  AuditActionVendorUpdated,
+			Details: map[string]interface{}{"changed_fields": changed},
+		})
+	}
+
+	for _, entry := range entries {
+		entry.EntityID = after.EntityID
+		entry.VendorID = after.ID
+		entry.ActorID = changedBy
+		entry.Details["vendor_code"] = after.VendorCode
+		if err := repo.InsertAuditEntry(ctx, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
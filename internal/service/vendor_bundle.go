@@ -0,0 +1,385 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+)
+
+// VendorBundleSchemaVersion is the version of the vendor bundles this server
+// writes, and the newest it imports. Bump it when a bundle field changes
+// meaning or a field older servers cannot ignore is added.
+const VendorBundleSchemaVersion = 1
+
+// maxBundleDocuments bounds the document records of a bundle
+const maxBundleDocuments = 1000
+
+// Audit actions written for vendor bundles
+const (
+	AuditActionBundleExported = "bundle_exported"
+	AuditActionBundleImported = "bundle_imported"
+)
+
+// VendorBundle is a self-contained copy of a vendor with its contacts,
+// document records, bank details and notes, for moving it between entities
+// or environments. Sections the exporter may not see are nil and listed in
+// Omitted; bank details the exporter may not see in full are reduced to their
+// last four characters and listed in Masked. Documents are records only: the
+// files stay in the storage of the source environment.
+type VendorBundle struct {
+	SchemaVersion  int                          `json:"schema_version"`
+	ExportedAt     time.Time                    `json:"exported_at"`
+	SourceEntityID string                       `json:"source_entity_id"`
+	Vendor         *repository.Vendor           `json:"vendor"`
+	Contacts       []*repository.VendorContact  `json:"contacts,omitzero"`
+	Documents      []*repository.VendorDocument `json:"documents,omitzero"`
+	Bank           *BankDetails                 `json:"bank,omitzero"`
+	Notes          *string                      `json:"notes,omitempty"`
+	Masked         []string                     `json:"masked,omitempty"`
+	Omitted        []string                     `json:"omitted,omitempty"`
+}
+
+// ExportVendorBundle exports a vendor of an entity as a VendorBundle and
+// records the export in the audit trail. allowed decides which snapshot
+// sections the caller may see: contacts, documents and notes are left out
+// without their section, and bank details are masked without theirs.
+func (s *VendorService) ExportVendorBundle(ctx context.Context, id, entityID, userID string, allowed func(section string) bool) (*VendorBundle, error) {
+	reqlog.SetEntity(ctx, entityID)
+	vendor, err := s.vendorRepo.GetByID(ctx, id, entityID)
+	if err != nil {
+		return nil, err
+	}
+	reqlog.SetVendor(ctx, vendor.ID)
+
+	bundle := &VendorBundle{
+		SchemaVersion:  VendorBundleSchemaVersion,
+		ExportedAt:     time.Now().UTC(),
+		SourceEntityID: entityID,
+	}
+	if allowed(SnapshotContacts) {
+		if bundle.Contacts, err = s.vendorRepo.GetContacts(ctx, vendor.ID); err != nil {
+			return nil, err
+		}
+	} else {
+		bundle.Omitted = append(bundle.Omitted, SnapshotContacts)
+	}
+	if allowed(SnapshotDocuments) {
+		if bundle.Documents, err = s.vendorRepo.ListDocuments(ctx, vendor.ID, maxBundleDocuments); err != nil {
+			return nil, err
+		}
+	} else {
+		bundle.Omitted = append(bundle.Omitted, SnapshotDocuments)
+	}
+	if allowed(SnapshotNotes) {
+		bundle.Notes = vendor.Notes
+	} else {
+		bundle.Omitted = append(bundle.Omitted, SnapshotNotes)
+	}
+
+	bundle.Bank = &BankDetails{
+		BankName:          vendor.BankName,
+		BankAccountNumber: vendor.BankAccountNumber,
+		BankRoutingNumber: vendor.BankRoutingNumber,
+		SwiftCode:         vendor.SwiftCode,
+		IBAN:              vendor.IBAN,
+	}
+	if !allowed(SnapshotBank) {
+		// Masked values replace the pointers, leaving the loaded vendor as read
+		for _, field := range []struct {
+			name  string
+			value **string
+		}{
+			{"bank_account_number", &bundle.Bank.BankAccountNumber},
+			{"bank_routing_number", &bundle.Bank.BankRoutingNumber},
+			{"iban", &bundle.Bank.IBAN},
+		} {
+			if *field.value != nil {
+				masked := lastFour(**field.value)
+				*field.value = &masked
+				bundle.Masked = append(bundle.Masked, field.name)
+			}
+		}
+	}
+
+	// Like the snapshot, the vendor only carries bank details and notes in
+	// their own sections
+	vendor.BankName, vendor.BankAccountNumber, vendor.BankRoutingNumber, vendor.SwiftCode, vendor.IBAN = nil, nil, nil, nil, nil
+	vendor.Notes = nil
+	bundle.Vendor = vendor
+
+	var actorID *string
+	if userID != "" {
+		actorID = &userID
+	}
+	err = s.vendorRepo.InsertAuditEntry(ctx, &repository.AuditEntry{
+		EntityID: entityID,
+		VendorID: vendor.ID,
+		Action:   AuditActionBundleExported,
+		ActorID:  actorID,
+		Details: map[string]interface{}{
+			"schema_version": bundle.SchemaVersion,
+			"contacts":       len(bundle.Contacts),
+			"documents":      len(bundle.Documents),
+			"masked":         bundle.Masked,
+			"omitted":        bundle.Omitted,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger(ctx).Info().
+		Strs("masked", bundle.Masked).
+		Strs("omitted", bundle.Omitted).
+		Msg("Vendor bundle exported")
+	return bundle, nil
+}
+
+// ImportVendorBundleRequest recreates the vendor of a bundle in an entity.
+// Bundle is kept raw so that its schema version is checked before the rest
+// is decoded.
+type ImportVendorBundleRequest struct {
+	EntityID string          `json:"entity_id"`
+	Bundle   json.RawMessage `json:"bundle"`
+	// VendorCode replaces the vendor code of the bundle, for codes already in
+	// use in the entity
+	VendorCode string `json:"vendor_code,omitempty"`
+	ImportedBy string `json:"-"`
+}
+
+// BundleImportResult reports a bundle import. A vendor is only created when
+// there are no Conflicts; Skipped lists what of the bundle was not recreated.
+type BundleImportResult struct {
+	Imported  bool               `json:"imported"`
+	Vendor    *repository.Vendor `json:"vendor,omitempty"`
+	Conflicts []*BundleConflict  `json:"conflicts,omitempty"`
+	Skipped   []*BundleSkip      `json:"skipped,omitempty"`
+}
+
+// BundleConflict is a bundle value already in use in the target entity
+type BundleConflict struct {
+	Field    string `json:"field"`
+	Value    string `json:"value"`
+	VendorID string `json:"vendor_id"`
+	Message  string `json:"message"`
+}
+
+// BundleSkip is a part of a bundle the import did not recreate, and why
+type BundleSkip struct {
+	Item   string `json:"item"`
+	Reason string `json:"reason"`
+}
+
+// ParseVendorBundle decodes a bundle, rejecting bundles without a schema
+// version or written by a newer server before reading their other fields
+func ParseVendorBundle(raw json.RawMessage) (*VendorBundle, error) {
+	v := &validator{}
+	var header struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	if len(raw) == 0 {
+		v.add("bundle", "bundle is required")
+		return nil, v.err()
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		v.add("bundle", "bundle is not a vendor bundle: "+err.Error())
+		return nil, v.err()
+	}
+	switch {
+	case header.SchemaVersion <= 0:
+		v.add("bundle.schema_version", "schema_version is required")
+	case header.SchemaVersion > VendorBundleSchemaVersion:
+		v.add("bundle.schema_version", fmt.Sprintf("bundle schema version %d is newer than version %d, the newest this server reads; import it into an up-to-date server",
+			header.SchemaVersion, VendorBundleSchemaVersion))
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	bundle := &VendorBundle{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(bundle); err != nil {
+		v.add("bundle", "bundle is not a vendor bundle: "+err.Error())
+		return nil, v.err()
+	}
+	v.check(bundle.Vendor != nil, "bundle.vendor", "vendor is required")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// ImportVendorBundle recreates the vendor of a bundle, with its contacts and
+// document records, in an entity under new IDs. The vendor is created like any
+// other, pending approval and with a zero balance, and runs every create check;
+// a vendor code in use in the entity is reported as a conflict without
+// importing anything. Masked or omitted bank details, the balance, the status
+// and the template of the source vendor are not carried over and are listed
+// as skipped.
+func (s *VendorService) ImportVendorBundle(ctx context.Context, req *ImportVendorBundleRequest) (*BundleImportResult, error) {
+	reqlog.SetEntity(ctx, req.EntityID)
+	v := &validator{}
+	v.check(req.EntityID != "", "entity_id", "entity_id is required")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	bundle, err := ParseVendorBundle(req.Bundle)
+	if err != nil {
+		return nil, err
+	}
+	source := bundle.Vendor
+
+	result := &BundleImportResult{}
+	code := source.VendorCode
+	if req.VendorCode != "" {
+		code = req.VendorCode
+	}
+	if existing, _ := s.vendorRepo.GetByCode(repository.UsePrimary(ctx), code, req.EntityID); existing != nil {
+		result.Conflicts = append(result.Conflicts, &BundleConflict{
+			Field:    "vendor_code",
+			Value:    code,
+			VendorID: existing.ID,
+			Message:  fmt.Sprintf("vendor code %s is in use in the entity; import with another vendor_code", code),
+		})
+		return result, nil
+	}
+
+	skip := func(item, reason string) {
+		result.Skipped = append(result.Skipped, &BundleSkip{Item: item, Reason: reason})
+	}
+	for _, section := range bundle.Omitted {
+		skip(section, "left out of the bundle by its exporter")
+	}
+	if source.Status != "pending_approval" {
+		skip("status", fmt.Sprintf("imported vendors start pending approval, not %s", source.Status))
+	}
+	if source.CurrentBalance != 0 {
+		skip("current_balance", "imported vendors start with a zero balance")
+	}
+	if source.TemplateID != nil {
+		skip("template_id", "templates belong to the source entity")
+	}
+
+	reference := fmt.Sprintf("bundle:%s/%s", bundle.SourceEntityID, source.ID)
+	create := &CreateVendorRequest{
+		EntityID:            req.EntityID,
+		VendorCode:          code,
+		VendorName:          source.VendorName,
+		LegalName:           source.LegalName,
+		DoingBusinessAs:     source.DoingBusinessAs,
+		VendorType:          source.VendorType,
+		TaxID:               source.TaxID,
+		IsTaxExempt:         source.IsTaxExempt,
+		Is1099Vendor:        source.Is1099Vendor,
+		Email:               source.Email,
+		RemittanceEmail:     source.RemittanceEmail,
+		Phone:               source.Phone,
+		Fax:                 source.Fax,
+		Website:             source.Website,
+		AddressLine1:        source.AddressLine1,
+		AddressLine2:        source.AddressLine2,
+		City:                source.City,
+		StateProvince:       source.StateProvince,
+		PostalCode:          source.PostalCode,
+		Country:             source.Country,
+		Locale:              source.Locale,
+		PaymentTerms:        source.PaymentTerms,
+		PaymentMethod:       source.PaymentMethod,
+		Currency:            source.Currency,
+		AcceptedCurrencies:  source.AcceptedCurrencies,
+		CreditLimit:         source.CreditLimit,
+		CreditLimitCurrency: source.CreditLimitCurrency,
+		Notes:               bundle.Notes,
+		Tags:                source.Tags,
+		CreatedBy:           req.ImportedBy,
+		// The bundle is a deliberate copy of a vendor created elsewhere
+		Force:           true,
+		Source:          VendorSourceImport,
+		SourceReference: &reference,
+		DefaultSource:   VendorSourceImport,
+	}
+	// A credit limit in another currency was accepted when the source vendor
+	// was created
+	create.CreditLimitCurrencyOverride = source.CreditLimitCurrency != nil && *source.CreditLimitCurrency != source.Currency
+	if bundle.Bank != nil {
+		if len(bundle.Masked) > 0 {
+			skip(SnapshotBank, "bank details were masked by the exporter")
+		} else {
+			create.BankName = bundle.Bank.BankName
+			create.BankAccountNumber = bundle.Bank.BankAccountNumber
+			create.BankRoutingNumber = bundle.Bank.BankRoutingNumber
+			create.SwiftCode = bundle.Bank.SwiftCode
+			create.IBAN = bundle.Bank.IBAN
+		}
+	}
+	for _, contact := range bundle.Contacts {
+		create.Contacts = append(create.Contacts, &AddContactRequest{
+			ContactType:            contact.ContactType,
+			FirstName:              contact.FirstName,
+			LastName:               contact.LastName,
+			Title:                  contact.Title,
+			Email:                  contact.Email,
+			Phone:                  contact.Phone,
+			Mobile:                 contact.Mobile,
+			IsPrimary:              contact.IsPrimary,
+			ReceivesPurchaseOrders: contact.ReceivesPurchaseOrders,
+			ReceivesRemittance:     contact.ReceivesRemittance,
+			ReceivesStatements:     contact.ReceivesStatements,
+			Notes:                  contact.Notes,
+		})
+	}
+
+	vendor, err := s.CreateVendor(ctx, create)
+	if err != nil {
+		return nil, err
+	}
+	reqlog.SetVendor(ctx, vendor.ID)
+
+	var importedBy *string
+	if req.ImportedBy != "" {
+		importedBy = &req.ImportedBy
+	}
+	err = s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		for _, doc := range bundle.Documents {
+			copied := *doc
+			copied.VendorID = vendor.ID
+			if err := repo.AddDocument(ctx, &copied); err != nil {
+				return err
+			}
+		}
+		return repo.InsertAuditEntry(ctx, &repository.AuditEntry{
+			EntityID: req.EntityID,
+			VendorID: vendor.ID,
+			Action:   AuditActionBundleImported,
+			ActorID:  importedBy,
+			Details: map[string]interface{}{
+				"schema_version":   bundle.SchemaVersion,
+				"source_entity_id": bundle.SourceEntityID,
+				"source_vendor_id": source.ID,
+				"contacts":         len(bundle.Contacts),
+				"documents":        len(bundle.Documents),
+			},
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(bundle.Documents) > 0 {
+		skip("documents.files", "document files stay in the storage of the source environment; only their records were imported")
+	}
+
+	result.Imported = true
+	result.Vendor = vendor
+	s.logger(ctx).Info().
+		Str("source_entity_id", bundle.SourceEntityID).
+		Str("source_vendor_id", source.ID).
+		Int("skipped", len(result.Skipped)).
+		Msg("Vendor bundle imported")
+	return result, nil
+}
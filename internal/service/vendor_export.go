@@ -0,0 +1,466 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/filestore"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// ExportKindVendors is the kind of vendor CSV export jobs
+const ExportKindVendors = "vendors"
+
+const (
+	// vendorExportBatchSize is how many vendors go in each part of an export file
+	vendorExportBatchSize = 5000
+	// vendorExportLease is how long a worker holds a job between checkpoints
+	// before another run may resume it
+	vendorExportLease = 2 * time.Minute
+	// vendorExportRunBudget is how long a run generates before it checkpoints
+	// and yields to the other worker jobs
+	vendorExportRunBudget = 30 * time.Second
+	// vendorExportMaxAttempts is how many failed runs fail a job for good
+	vendorExportMaxAttempts = 3
+)
+
+// ErrVendorExportsDisabled is returned when no file store is configured for
+// vendor exports
+var ErrVendorExportsDisabled = stderrors.New("vendor exports are not enabled")
+
+// VendorExportColumns is the header of vendor export files. Bank details are
+// left out; multi-valued columns are separated by "|".
+var VendorExportColumns = []string{
+	"id", "vendor_code", "vendor_name", "legal_name", "doing_business_as", "vendor_type", "status",
+	"tax_id", "is_tax_exempt", "is_1099_vendor", "email", "remittance_email", "phone", "fax", "website",
+	"address_line1", "address_line2", "city", "state_province", "postal_code", "country", "locale",
+	"payment_terms", "payment_method", "currency", "accepted_currencies", "credit_limit", "credit_limit_currency",
+	"current_balance", "tags", "notes", "created_at", "updated_at",
+}
+
+// ExportLink signs a download link of a vendor export job until Expires (Unix
+// seconds)
+type ExportLink struct {
+	Expires   int64
+	Signature string
+}
+
+// ExportLinkError is returned for a vendor export download without a valid,
+// unexpired link
+type ExportLinkError struct {
+	Reason string
+}
+
+func (e *ExportLinkError) Error() string {
+	return "invalid export download link: " + e.Reason
+}
+
+// ExportFile is the file of a completed export job
+type ExportFile struct {
+	Name        string
+	ContentType string
+	Body        io.ReadCloser
+}
+
+// vendorExportKey returns the file store key of part of the file of a job
+func vendorExportKey(jobID string, part int) string {
+	return fmt.Sprintf("%s/part-%05d.csv", vendorExportPrefix(jobID), part)
+}
+
+// vendorExportPrefix returns the file store prefix of the files of a job
+func vendorExportPrefix(jobID string) string {
+	return "vendor-exports/" + jobID
+}
+
+// IsUUID reports whether id is formatted as a UUID, like the IDs of vendors,
+// entities and stored export jobs; the IDs of in-memory jobs are plain hex
+func IsUUID(id string) bool {
+	if len(id) != 36 {
+		return false
+	}
+	for i, c := range id {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// StartVendorExport queues a CSV export of the live vendors of an entity,
+// generated by the worker; poll the returned job for its progress and link.
+// excludeSuspectedTest leaves out the vendors flagged as probable test
+// vendors.
+func (s *VendorService) StartVendorExport(ctx context.Context, entityID string, excludeSuspectedTest bool, createdBy *string) (*ExportJob, error) {
+	reqlog.SetEntity(ctx, entityID)
+	if entityID == "" {
+		return nil, errors.InvalidInput("entity_id", "entity_id is required")
+	}
+	if s.exportFiles == nil {
+		return nil, ErrVendorExportsDisabled
+	}
+
+	var total int64
+	var err error
+	if excludeSuspectedTest {
+		filter := repository.VendorFilter{EntityID: entityID, ExcludeSuspectedTest: true, Fields: []string{"id"}}
+		_, total, err = s.vendorRepo.List(ctx, filter, 1, 0)
+	} else {
+		total, err = s.vendorRepo.CountLiveVendors(ctx, entityID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	job := &repository.VendorExportJob{
+		EntityID:             entityID,
+		RowsTotal:            total,
+		ExcludeSuspectedTest: excludeSuspectedTest,
+		CreatedBy:            createdBy,
+	}
+	if err := s.vendorRepo.CreateExportJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	s.logger(ctx).Info().
+		Str("export_job_id", job.ID).
+		Int64("vendors", total).
+		Msg("Vendor export queued")
+	return s.vendorExportJob(job), nil
+}
+
+// vendorExportJob presents a vendor export job, with a download link once
+// it completed
+func (s *VendorService) vendorExportJob(job *repository.VendorExportJob) *ExportJob {
+	rowsTotal, rowsWritten := job.RowsTotal, job.RowsWritten
+	out := &ExportJob{
+		ID:          job.ID,
+		EntityID:    job.EntityID,
+		Kind:        ExportKindVendors,
+		Status:      job.Status,
+		CreatedAt:   job.CreatedAt,
+		CompletedAt: job.CompletedAt,
+		ExpiresAt:   job.ExpiresAt,
+		RowsTotal:   &rowsTotal,
+		RowsWritten: &rowsWritten,
+	}
+	if job.Error != nil && job.Status == ExportJobFailed {
+		out.Error = *job.Error
+	}
+	if job.Status == ExportJobCompleted && job.ExpiresAt != nil {
+		expires := time.Now().Add(s.exportLinkTTL)
+		if expires.After(*job.ExpiresAt) {
+			expires = *job.ExpiresAt
+		}
+		out.Link = &ExportLink{Expires: expires.Unix(), Signature: s.signExportLink(job.ID, job.EntityID, expires.Unix())}
+	}
+	return out
+}
+
+// signExportLink returns the signature of a download link of a job
+func (s *VendorService) signExportLink(jobID, entityID string, expires int64) string {
+	mac := hmac.New(sha256.New, s.exportLinkKey)
+	fmt.Fprintf(mac, "%s\n%s\n%d", jobID, entityID, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// checkExportLink verifies the download link of a job
+func (s *VendorService) checkExportLink(jobID, entityID string, link *ExportLink) error {
+	if link == nil || link.Signature == "" {
+		return &ExportLinkError{Reason: "a signed download link is required"}
+	}
+	want := s.signExportLink(jobID, entityID, link.Expires)
+	if !hmac.Equal([]byte(want), []byte(strings.ToLower(link.Signature))) {
+		return &ExportLinkError{Reason: "signature does not match"}
+	}
+	if time.Now().Unix() > link.Expires {
+		return &ExportLinkError{Reason: "link expired"}
+	}
+	return nil
+}
+
+// openVendorExportFile verifies the link of a completed vendor export job and
+// opens its file
+func (s *VendorService) openVendorExportFile(ctx context.Context, id, entityID string, link *ExportLink) (*ExportFile, error) {
+	job, err := s.vendorRepo.GetExportJob(ctx, id, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkExportLink(job.ID, job.EntityID, link); err != nil {
+		return nil, err
+	}
+
+	v := &validator{}
+	v.check(job.Status == ExportJobCompleted, "status", fmt.Sprintf("export job is %s, not completed", job.Status))
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	if s.exportFiles == nil {
+		return nil, ErrVendorExportsDisabled
+	}
+
+	return &ExportFile{
+		Name:        fmt.Sprintf("vendors-%s.csv", job.CreatedAt.UTC().Format("20060102-150405")),
+		ContentType: "text/csv; charset=utf-8",
+		Body:        &partsReader{ctx: ctx, files: s.exportFiles, jobID: job.ID, parts: job.Parts},
+	}, nil
+}
+
+// partsReader reads the parts of an export file one after the other, opening
+// each only once the previous one is read
+type partsReader struct {
+	ctx     context.Context
+	files   filestore.Store
+	jobID   string
+	parts   int
+	next    int
+	current io.ReadCloser
+}
+
+func (p *partsReader) Read(b []byte) (int, error) {
+	for {
+		if p.current == nil {
+			if p.next >= p.parts {
+				return 0, io.EOF
+			}
+			part, err := p.files.Open(p.ctx, vendorExportKey(p.jobID, p.next))
+			if err != nil {
+				return 0, fmt.Errorf("open export part %d: %w", p.next, err)
+			}
+			p.current = part
+			p.next++
+		}
+
+		n, err := p.current.Read(b)
+		if err == io.EOF {
+			p.current.Close()
+			p.current = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (p *partsReader) Close() error {
+	if p.current != nil {
+		return p.current.Close()
+	}
+	return nil
+}
+
+// RunVendorExports generates the queued vendor exports for up to
+// vendorExportRunBudget, resuming interrupted ones from their last part, and
+// returns how many completed. A job still unfinished when the budget runs
+// out is checkpointed and resumed by the next run.
+func (s *VendorService) RunVendorExports(ctx context.Context) (int, error) {
+	if s.exportFiles == nil {
+		return 0, nil
+	}
+
+	deadline := time.Now().Add(vendorExportRunBudget)
+	completed := 0
+	for time.Now().Before(deadline) {
+		job, err := s.vendorRepo.ClaimExportJob(ctx, time.Now().Add(vendorExportLease))
+		if err != nil {
+			return completed, err
+		}
+		if job == nil {
+			return completed, nil
+		}
+
+		done, err := s.generateVendorExport(ctx, job, deadline)
+		if err != nil {
+			return completed, s.failVendorExport(ctx, job, err)
+		}
+		if done {
+			completed++
+		}
+	}
+	return completed, nil
+}
+
+// generateVendorExport writes the parts of a job from its cursor until every
+// vendor is written or deadline passes. done reports whether the job completed.
+func (s *VendorService) generateVendorExport(ctx context.Context, job *repository.VendorExportJob, deadline time.Time) (done bool, err error) {
+	log := s.logger(ctx).With().Str("export_job_id", job.ID).Str("entity_id", job.EntityID).Logger()
+	if job.Parts > 0 {
+		log.Info().Int("parts", job.Parts).Int64("rows_written", job.RowsWritten).Msg("Resuming vendor export")
+	}
+
+	var cursor string
+	if job.CursorID != nil {
+		cursor = *job.CursorID
+	}
+	for {
+		vendors, err := s.vendorRepo.ListVendorBatch(ctx, job.EntityID, cursor, vendorExportBatchSize)
+		if err != nil {
+			return false, err
+		}
+
+		// The cursor moves over the whole batch, suspected test vendors
+		// included, so only the rows written are filtered
+		rows := vendors
+		if job.ExcludeSuspectedTest {
+			rows = slices.DeleteFunc(slices.Clone(vendors), repository.IsSuspectedTestVendor)
+		}
+
+		// An entity without vendors still gets a file with the header
+		if len(vendors) > 0 || job.Parts == 0 {
+			var buf bytes.Buffer
+			if err := WriteVendorsCSV(&buf, rows, job.Parts == 0); err != nil {
+				return false, err
+			}
+			if err := s.exportFiles.Put(ctx, vendorExportKey(job.ID, job.Parts), buf.Bytes()); err != nil {
+				return false, err
+			}
+			if len(vendors) > 0 {
+				cursor = vendors[len(vendors)-1].ID
+				job.CursorID = &cursor
+			}
+			job.Parts++
+			job.RowsWritten += int64(len(rows))
+			if err := s.vendorRepo.CheckpointExportJob(ctx, job, time.Now().Add(vendorExportLease)); err != nil {
+				return false, err
+			}
+		}
+
+		if len(vendors) < vendorExportBatchSize {
+			if err := s.vendorRepo.FinishExportJob(ctx, job.ID, ExportJobCompleted, nil, time.Now().Add(s.exportRetention)); err != nil {
+				return false, err
+			}
+			log.Info().Int64("rows_written", job.RowsWritten).Int("parts", job.Parts).Msg("Vendor export completed")
+			return true, nil
+		}
+		if ctx.Err() != nil || !time.Now().Before(deadline) {
+			return false, s.vendorRepo.ReleaseExportJob(ctx, job.ID, nil)
+		}
+	}
+}
+
+// failVendorExport records a failed run of a job, which fails the job once
+// it ran out of attempts. A run cut short by shutdown does not count.
+func (s *VendorService) failVendorExport(ctx context.Context, job *repository.VendorExportJob, cause error) error {
+	ctx = context.WithoutCancel(ctx)
+	if stderrors.Is(cause, context.Canceled) {
+		return s.vendorRepo.ReleaseExportJob(ctx, job.ID, nil)
+	}
+
+	msg := cause.Error()
+	if job.Attempts+1 >= vendorExportMaxAttempts {
+		if err := s.vendorRepo.FinishExportJob(ctx, job.ID, ExportJobFailed, &msg, time.Now().Add(s.exportRetention)); err != nil {
+			return err
+		}
+		return fmt.Errorf("vendor export %s failed: %w", job.ID, cause)
+	}
+	if err := s.vendorRepo.ReleaseExportJob(ctx, job.ID, &msg); err != nil {
+		return err
+	}
+	return fmt.Errorf("vendor export %s attempt %d failed: %w", job.ID, job.Attempts+1, cause)
+}
+
+// purgeExpiredVendorExports removes the expired vendor export jobs and their
+// files, batchSize at a time
+func (s *VendorService) purgeExpiredVendorExports(ctx context.Context, batchSize int) (int64, error) {
+	return s.purgeInBatches(ctx, "export_jobs", batchSize, func() (int64, error) {
+		jobs, err := s.vendorRepo.ListExpiredExportJobs(ctx, time.Now(), batchSize)
+		if err != nil {
+			return 0, err
+		}
+		for _, job := range jobs {
+			if s.exportFiles != nil {
+				if err := s.exportFiles.DeletePrefix(ctx, vendorExportPrefix(job.ID)); err != nil {
+					return 0, err
+				}
+			}
+			if err := s.vendorRepo.DeleteExportJob(ctx, job.ID); err != nil {
+				return 0, err
+			}
+		}
+		return int64(len(jobs)), nil
+	})
+}
+
+// WriteVendorsCSV writes vendors as CSV rows of VendorExportColumns, preceded
+// by the header when header is set
+func WriteVendorsCSV(w io.Writer, vendors []*repository.Vendor, header bool) error {
+	cw := csv.NewWriter(w)
+	if header {
+		if err := cw.Write(VendorExportColumns); err != nil {
+			return err
+		}
+	}
+
+	value := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	for _, v := range vendors {
+		var creditLimit string
+		if v.CreditLimit != nil {
+			creditLimit = strconv.FormatInt(*v.CreditLimit, 10)
+		}
+		if err := cw.Write([]string{
+			v.ID,
+			v.VendorCode,
+			v.VendorName,
+			value(v.LegalName),
+			value(v.DoingBusinessAs),
+			v.VendorType,
+			v.Status,
+			value(v.TaxID),
+			strconv.FormatBool(v.IsTaxExempt),
+			strconv.FormatBool(v.Is1099Vendor),
+			value(v.Email),
+			value(v.RemittanceEmail),
+			value(v.Phone),
+			value(v.Fax),
+			value(v.Website),
+			value(v.AddressLine1),
+			value(v.AddressLine2),
+			value(v.City),
+			value(v.StateProvince),
+			value(v.PostalCode),
+			v.Country,
+			value(v.Locale),
+			v.PaymentTerms,
+			value(v.PaymentMethod),
+			v.Currency,
+			strings.Join(v.AcceptedCurrencies, "|"),
+			creditLimit,
+			value(v.CreditLimitCurrency),
+			strconv.FormatInt(v.CurrentBalance, 10),
+			strings.Join(v.Tags, "|"),
+			value(v.Notes),
+			v.CreatedAt.UTC().Format(time.RFC3339),
+			v.UpdatedAt.UTC().Format(time.RFC3339),
+		}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
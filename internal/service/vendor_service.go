@@ -2,149 +2,331 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"strings"
+	"time"
 
+	"github.com/pesio-ai/be-ap-vendors/internal/address"
+	"github.com/pesio-ai/be-ap-vendors/internal/bankverify"
+	"github.com/pesio-ai/be-ap-vendors/internal/debounce"
+	"github.com/pesio-ai/be-ap-vendors/internal/docstore"
+	"github.com/pesio-ai/be-ap-vendors/internal/filestore"
+	"github.com/pesio-ai/be-ap-vendors/internal/fx"
+	"github.com/pesio-ai/be-ap-vendors/internal/identity"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-ap-vendors/internal/spend"
+	"github.com/pesio-ai/be-ap-vendors/internal/tinmatch"
 	"github.com/pesio-ai/be-lib-common/errors"
 	"github.com/pesio-ai/be-lib-common/logger"
 	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/rs/zerolog"
 )
 
 // VendorService handles vendor business logic
 type VendorService struct {
-	vendorRepo *repository.VendorRepository
+	vendorRepo repository.Store
 	log        *logger.Logger
+	changes    *changeBroker
+
+	contactMethodRule string
+	addressValidator  address.Validator
+	strictAddresses   bool
+	quotas            QuotaProvider
+	recentCreates     debounce.Store
+	createWindow      time.Duration
+	approvalSLA       time.Duration
+	spend             spend.Provider
+	blocklistPolicy   string
+	strictBank        bool
+	// requireBankVerification fails the validation of ACH and wire vendors
+	// with unverified bank accounts
+	requireBankVerification bool
+	bankVerifier            bankverify.Verifier
+	// flagsCache holds the resolved validation switches of entities
+	flagsCache *entityFlagsCache
+	// estimateTotalsAbove is the live vendor count above which list totals
+	// are estimated by default; 0 always counts exactly
+	estimateTotalsAbove int64
+	entityCounts        *entityCountCache
+	// codeReservationTTL is how long a reserved vendor code is held
+	codeReservationTTL time.Duration
+	// dataQualityRules are the enabled data quality rules, in report order
+	dataQualityRules []string
+	// currencyWindowDays is how far back balance ledger activity blocks
+	// currency changes; 0 only checks the balance
+	currencyWindowDays int
+	// bulkDeleteTokens holds the confirmation tokens of bulk delete dry runs
+	bulkDeleteTokens   debounce.Store
+	bulkDeleteTokenTTL time.Duration
+	// dormantMonths is how long vendors go without activity before they are
+	// flagged dormant
+	dormantMonths  int
+	dormancyEvents bool
+	tinMatcher     tinmatch.Matcher
+	// fxConverter converts foreign credit limits to the vendor currency
+	fxConverter fx.Converter
+	// countryProfileRule is how vendor writes apply country profiles
+	countryProfileRule string
+	// users resolves the user IDs of vendors and activity on request
+	users identity.UserResolver
+	// exportJobs runs the exports generated in the background
+	exportJobs *exportJobs
+
+	// requiredCommunications are the communication types a vendor cannot
+	// lose its last contact receiving
+	requiredCommunications []string
+
+	// Vendor exports: exportFiles stores their files (nil disables them),
+	// exportLinkKey signs their download links, valid for exportLinkTTL, and
+	// finished jobs are purged after exportRetention
+	exportFiles     filestore.Store
+	exportLinkKey   []byte
+	exportLinkTTL   time.Duration
+	exportRetention time.Duration
+
+	// Vendor documents: documents stores their files (nil disables
+	// downloads), which are streamed up to documentStreamMaxBytes and
+	// otherwise linked for documentLinkTTL
+	documents              docstore.Store
+	documentLinkTTL        time.Duration
+	documentStreamMaxBytes int64
 }
 
 // NewVendorService creates a new vendor service
 func NewVendorService(
-	vendorRepo *repository.VendorRepository,
+	vendorRepo repository.Store,
 	log *logger.Logger,
+	opts ...Option,
 ) *VendorService {
-	return &VendorService{
-		vendorRepo: vendorRepo,
-		log:        log,
+	s := &VendorService{
+		vendorRepo:         vendorRepo,
+		log:                log,
+		changes:            newChangeBroker(),
+		contactMethodRule:  ContactMethodRuleWarn,
+		addressValidator:   address.Noop{},
+		quotas:             &StaticQuotaProvider{},
+		spend:              spend.Stub{},
+		blocklistPolicy:    BlocklistPolicyBlock,
+		flagsCache:         newEntityFlagsCache(30 * time.Second),
+		entityCounts:       newEntityCountCache(5 * time.Minute),
+		codeReservationTTL: 30 * time.Minute,
+		dataQualityRules:   AllDataQualityRules(),
+
+		bulkDeleteTokens:   debounce.NewMemory(1000),
+		bulkDeleteTokenTTL: 10 * time.Minute,
+		dormantMonths:      24,
+		exportJobs:         newExportJobs(),
+		exportLinkTTL:      15 * time.Minute,
+		exportRetention:    24 * time.Hour,
+		tinMatcher:         tinmatch.Stub{},
+		bankVerifier:       bankverify.Stub{},
+		currencyWindowDays: 90,
+		fxConverter:        fx.Stub{},
+		users:              identity.Stub{},
+		countryProfileRule: CountryProfileRuleWarn,
+
+		documentLinkTTL:        5 * time.Minute,
+		documentStreamMaxBytes: 1 << 20,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
+}
+
+// logger returns the service logger enriched with the request's entity, vendor
+// and user fields
+func (s *VendorService) logger(ctx context.Context) *zerolog.Logger {
+	return reqlog.Logger(ctx, &s.log.Logger)
 }
 
 // CreateVendorRequest represents a create vendor request
 type CreateVendorRequest struct {
-	EntityID          string   `json:"entity_id"`
-	VendorCode        string   `json:"vendor_code,omitempty"`
-	VendorName        string   `json:"vendor_name"`
-	LegalName         *string  `json:"legal_name,omitempty"`
-	VendorType        string   `json:"vendor_type"`
-	TaxID             *string  `json:"tax_id,omitempty"`
-	IsTaxExempt       bool     `json:"is_tax_exempt"`
-	Is1099Vendor      bool     `json:"is_1099_vendor"`
-	Email             *string  `json:"email,omitempty"`
-	Phone             *string  `json:"phone,omitempty"`
-	Fax               *string  `json:"fax,omitempty"`
-	Website           *string  `json:"website,omitempty"`
-	AddressLine1      *string  `json:"address_line1,omitempty"`
-	AddressLine2      *string  `json:"address_line2,omitempty"`
-	City              *string  `json:"city,omitempty"`
-	StateProvince     *string  `json:"state_province,omitempty"`
-	PostalCode        *string  `json:"postal_code,omitempty"`
-	Country           string   `json:"country"`
-	PaymentTerms      string   `json:"payment_terms"`
-	PaymentMethod     *string  `json:"payment_method,omitempty"`
-	Currency          string   `json:"currency"`
-	CreditLimit       *int64   `json:"credit_limit,omitempty"`
-	BankName          *string  `json:"bank_name,omitempty"`
-	BankAccountNumber *string  `json:"bank_account_number,omitempty"`
-	BankRoutingNumber *string  `json:"bank_routing_number,omitempty"`
-	SwiftCode         *string  `json:"swift_code,omitempty"`
-	IBAN              *string  `json:"iban,omitempty"`
-	Notes             *string  `json:"notes,omitempty"`
-	Tags              []string `json:"tags,omitempty"`
-	CreatedBy         string   `json:"created_by,omitempty"`
+	EntityID           string   `json:"entity_id"`
+	VendorCode         string   `json:"vendor_code,omitempty"`
+	VendorName         string   `json:"vendor_name"`
+	LegalName          *string  `json:"legal_name,omitempty"`
+	DoingBusinessAs    *string  `json:"doing_business_as,omitempty"`
+	VendorType         string   `json:"vendor_type"`
+	TaxID              *string  `json:"tax_id,omitempty"`
+	IsTaxExempt        bool     `json:"is_tax_exempt"`
+	Is1099Vendor       bool     `json:"is_1099_vendor"`
+	Email              *string  `json:"email,omitempty"`
+	RemittanceEmail    *string  `json:"remittance_email,omitempty"`
+	Phone              *string  `json:"phone,omitempty"`
+	Fax                *string  `json:"fax,omitempty"`
+	Website            *string  `json:"website,omitempty"`
+	AddressLine1       *string  `json:"address_line1,omitempty"`
+	AddressLine2       *string  `json:"address_line2,omitempty"`
+	City               *string  `json:"city,omitempty"`
+	StateProvince      *string  `json:"state_province,omitempty"`
+	PostalCode         *string  `json:"postal_code,omitempty"`
+	Country            string   `json:"country"`
+	Locale             *string  `json:"locale,omitempty"`
+	PaymentTerms       string   `json:"payment_terms"`
+	PaymentMethod      *string  `json:"payment_method,omitempty"`
+	Currency           string   `json:"currency"`
+	AcceptedCurrencies []string `json:"accepted_currencies,omitempty"`
+	CreditLimit        *int64   `json:"credit_limit,omitempty"`
+	BankName           *string  `json:"bank_name,omitempty"`
+	BankAccountNumber  *string  `json:"bank_account_number,omitempty"`
+	BankRoutingNumber  *string  `json:"bank_routing_number,omitempty"`
+	SwiftCode          *string  `json:"swift_code,omitempty"`
+	IBAN               *string  `json:"iban,omitempty"`
+	Notes              *string  `json:"notes,omitempty"`
+	Tags               []string `json:"tags,omitempty"`
+	CreatedBy          string   `json:"created_by,omitempty"`
+	// TemplateID names a vendor template whose defaults fill in the fields
+	// left empty, before validation
+	TemplateID string `json:"template_id,omitempty"`
+	// Force skips the check for an identical vendor created moments ago
+	Force bool `json:"force,omitempty"`
+
+	// Contacts are created together with the vendor in the same transaction
+	Contacts []*AddContactRequest `json:"contacts,omitempty"`
+
+	// CreditLimitCurrency is the currency of CreditLimit, the vendor currency
+	// when empty; another currency needs CreditLimitCurrencyOverride
+	CreditLimitCurrency         *string `json:"credit_limit_currency,omitempty"`
+	CreditLimitCurrencyOverride bool    `json:"credit_limit_currency_override,omitempty"`
+
+	// Source declares the creation path for callers creating vendors on behalf
+	// of an import, the onboarding portal or an ERP sync, and SourceReference
+	// what within it, e.g. the import job ID. DefaultSource, the source of the
+	// handler the request came through, is recorded when Source is empty.
+	Source          string  `json:"source,omitempty"`
+	SourceReference *string `json:"source_reference,omitempty"`
+	DefaultSource   string  `json:"-"`
 }
 
-// UpdateVendorRequest represents an update vendor request
+// UpdateVendorRequest represents an update vendor request. Status, the
+// balance and other server-managed fields are not part of it; see
+// CheckWritableFields.
 type UpdateVendorRequest struct {
-	ID                string
-	EntityID          string
-	VendorCode        string
-	VendorName        string
-	LegalName         *string
-	VendorType        string
-	Status            string
-	TaxID             *string
-	IsTaxExempt       bool
-	Is1099Vendor      bool
-	Email             *string
-	Phone             *string
-	Fax               *string
-	Website           *string
-	AddressLine1      *string
-	AddressLine2      *string
-	City              *string
-	StateProvince     *string
-	PostalCode        *string
-	Country           string
-	PaymentTerms      string
-	PaymentMethod     *string
-	Currency          string
-	CreditLimit       *int64
-	BankName          *string
-	BankAccountNumber *string
-	BankRoutingNumber *string
-	SwiftCode         *string
-	IBAN              *string
-	Notes             *string
-	Tags              []string
-	UpdatedBy         string
+	ID                 string   `json:"id"`
+	EntityID           string   `json:"entity_id"`
+	VendorCode         string   `json:"vendor_code"`
+	VendorName         string   `json:"vendor_name"`
+	LegalName          *string  `json:"legal_name,omitempty"`
+	DoingBusinessAs    *string  `json:"doing_business_as,omitempty"`
+	VendorType         string   `json:"vendor_type"`
+	TaxID              *string  `json:"tax_id,omitempty"`
+	IsTaxExempt        bool     `json:"is_tax_exempt"`
+	Is1099Vendor       bool     `json:"is_1099_vendor"`
+	Email              *string  `json:"email,omitempty"`
+	RemittanceEmail    *string  `json:"remittance_email,omitempty"`
+	Phone              *string  `json:"phone,omitempty"`
+	Fax                *string  `json:"fax,omitempty"`
+	Website            *string  `json:"website,omitempty"`
+	AddressLine1       *string  `json:"address_line1,omitempty"`
+	AddressLine2       *string  `json:"address_line2,omitempty"`
+	City               *string  `json:"city,omitempty"`
+	StateProvince      *string  `json:"state_province,omitempty"`
+	PostalCode         *string  `json:"postal_code,omitempty"`
+	Country            string   `json:"country"`
+	Locale             *string  `json:"locale,omitempty"`
+	PaymentTerms       string   `json:"payment_terms"`
+	PaymentMethod      *string  `json:"payment_method,omitempty"`
+	Currency           string   `json:"currency"`
+	AcceptedCurrencies []string `json:"accepted_currencies,omitempty"`
+	CreditLimit        *int64   `json:"credit_limit,omitempty"`
+	BankName           *string  `json:"bank_name,omitempty"`
+	BankAccountNumber  *string  `json:"bank_account_number,omitempty"`
+	BankRoutingNumber  *string  `json:"bank_routing_number,omitempty"`
+	SwiftCode          *string  `json:"swift_code,omitempty"`
+	IBAN               *string  `json:"iban,omitempty"`
+	Notes              *string  `json:"notes,omitempty"`
+	Tags               []string `json:"tags,omitempty"`
+	UpdatedBy          string   `json:"updated_by,omitempty"`
+	// OverrideCodeLock lets an admin change the code of an active vendor in
+	// an entity that locks vendor codes after activation. It is set by the
+	// handlers from the caller's permissions, never from the request body.
+	OverrideCodeLock bool `json:"-"`
+	// CurrencyConversionNote explains how the balance of a vendor is carried
+	// over when an admin changes its currency
+	CurrencyConversionNote string `json:"currency_conversion_note,omitempty"`
+	// OverrideCurrencyLock lets an admin change the currency of a vendor with
+	// a balance or recent ledger activity, given a CurrencyConversionNote. It
+	// is set by the handlers from the caller's permissions.
+	OverrideCurrencyLock bool `json:"-"`
+
+	// CreditLimitCurrency and CreditLimitCurrencyOverride work as for
+	// CreateVendorRequest
+	CreditLimitCurrency         *string `json:"credit_limit_currency,omitempty"`
+	CreditLimitCurrencyOverride bool    `json:"credit_limit_currency_override,omitempty"`
 }
 
 // AddContactRequest represents an add contact request
 type AddContactRequest struct {
-	VendorID    string
-	ContactType string
-	FirstName   string
-	LastName    string
-	Title       *string
-	Email       *string
-	Phone       *string
-	Mobile      *string
-	IsPrimary   bool
-	Notes       *string
+	VendorID               string  `json:"vendor_id"`
+	ContactType            string  `json:"contact_type"`
+	FirstName              string  `json:"first_name"`
+	LastName               string  `json:"last_name"`
+	Title                  *string `json:"title,omitempty"`
+	Email                  *string `json:"email,omitempty"`
+	Phone                  *string `json:"phone,omitempty"`
+	Mobile                 *string `json:"mobile,omitempty"`
+	IsPrimary              bool    `json:"is_primary"`
+	ReceivesPurchaseOrders bool    `json:"receives_purchase_orders"`
+	ReceivesRemittance     bool    `json:"receives_remittance"`
+	ReceivesStatements     bool    `json:"receives_statements"`
+	Notes                  *string `json:"notes,omitempty"`
+	// Upsert overwrites the vendor's contact with the same email instead of
+	// failing with a DuplicateContactError
+	Upsert bool `json:"upsert,omitempty"`
 }
 
 // CreateVendor creates a new vendor
 func (s *VendorService) CreateVendor(ctx context.Context, req *CreateVendorRequest) (*repository.Vendor, error) {
+	reqlog.SetEntity(ctx, req.EntityID)
+	ctx = repository.UsePrimary(ctx)
+	if err := s.checkEntityWritable(ctx, req.EntityID); err != nil {
+		return nil, err
+	}
+
 	// Validate vendor code is unique for entity
-	existing, _ := s.vendorRepo.GetByCode(ctx, req.VendorCode, req.EntityID)
+	code := normalizeVendorCode(req.VendorCode)
+	existing, _ := s.vendorRepo.GetByCode(ctx, code, req.EntityID)
 	if existing != nil {
-		return nil, errors.AlreadyExists("vendor", req.VendorCode)
+		return nil, errors.AlreadyExists("vendor", code)
 	}
 
-	// Validate vendor type
-	validTypes := map[string]bool{
-		"supplier":         true,
-		"contractor":       true,
-		"service_provider": true,
-		"consultant":       true,
-		"utility":          true,
+	// Collect every validation failure so they can be reported together
+	v := &validator{}
+
+	var templateID *string
+	if req.TemplateID != "" {
+		var err error
+		if req, err = s.applyVendorTemplate(ctx, v, req); err != nil {
+			return nil, err
+		}
+		templateID = &req.TemplateID
 	}
+
+	// Validate vendor type against the entity's registry
 	vendorType := strings.ToLower(req.VendorType)
-	if !validTypes[vendorType] {
-		return nil, errors.InvalidInput("vendor_type", "invalid vendor type")
+	if err := s.checkVendorType(ctx, v, req.EntityID, vendorType, ""); err != nil {
+		return nil, err
 	}
 
 	// Validate currency
-	if len(req.Currency) != 3 {
-		return nil, errors.InvalidInput("currency", "currency must be 3-letter ISO code")
-	}
+	v.check(len(req.Currency) == 3, "currency", "currency must be 3-letter ISO code")
 
 	// Validate credit limit if set
-	if req.CreditLimit != nil && *req.CreditLimit < 0 {
-		return nil, errors.InvalidInput("credit_limit", "credit limit cannot be negative")
-	}
+	v.check(req.CreditLimit == nil || *req.CreditLimit >= 0, "credit_limit", "credit limit cannot be negative")
 
 	// Validate country code (should be 2-letter ISO)
-	if len(req.Country) != 2 {
-		return nil, errors.InvalidInput("country", "country must be 2-letter ISO code")
+	country := address.NormalizeCountry(req.Country)
+	v.check(len(country) == 2, "country", "country must be 2-letter ISO code")
+
+	// Validate nested contacts up front so nothing is written when one is invalid
+	contacts := make([]*repository.VendorContact, 0, len(req.Contacts))
+	for i, contactReq := range req.Contacts {
+		if contact := newContact(v, contactReq, fmt.Sprintf("contacts[%d].", i)); contact != nil {
+			contacts = append(contacts, contact)
+		}
 	}
+	checkContactRecipients(v, req.Contacts)
 
 	// Create vendor with pending approval status
 	// Convert empty string to NULL for CreatedBy
@@ -154,127 +336,277 @@ func (s *VendorService) CreateVendor(ctx context.Context, req *CreateVendorReque
 	}
 
 	vendor := &repository.Vendor{
-		EntityID:          req.EntityID,
-		VendorCode:        strings.ToUpper(req.VendorCode),
-		VendorName:        req.VendorName,
-		LegalName:         req.LegalName,
-		VendorType:        vendorType,
-		Status:            "pending_approval",
-		TaxID:             req.TaxID,
-		IsTaxExempt:       req.IsTaxExempt,
-		Is1099Vendor:      req.Is1099Vendor,
-		Email:             req.Email,
-		Phone:             req.Phone,
-		Fax:               req.Fax,
-		Website:           req.Website,
-		AddressLine1:      req.AddressLine1,
-		AddressLine2:      req.AddressLine2,
-		City:              req.City,
-		StateProvince:     req.StateProvince,
-		PostalCode:        req.PostalCode,
-		Country:           strings.ToUpper(req.Country),
-		PaymentTerms:      req.PaymentTerms,
-		PaymentMethod:     req.PaymentMethod,
-		Currency:          strings.ToUpper(req.Currency),
-		CreditLimit:       req.CreditLimit,
-		CurrentBalance:    0,
-		BankName:          req.BankName,
-		BankAccountNumber: req.BankAccountNumber,
-		BankRoutingNumber: req.BankRoutingNumber,
-		SwiftCode:         req.SwiftCode,
-		IBAN:              req.IBAN,
-		Notes:             req.Notes,
-		Tags:              req.Tags,
-		CreatedBy:         createdBy,
+		EntityID:           req.EntityID,
+		VendorCode:         code,
+		VendorName:         req.VendorName,
+		LegalName:          req.LegalName,
+		DoingBusinessAs:    req.DoingBusinessAs,
+		VendorType:         vendorType,
+		Status:             "pending_approval",
+		TaxID:              req.TaxID,
+		IsTaxExempt:        req.IsTaxExempt,
+		Is1099Vendor:       req.Is1099Vendor,
+		Email:              req.Email,
+		RemittanceEmail:    req.RemittanceEmail,
+		Phone:              req.Phone,
+		Fax:                req.Fax,
+		Website:            req.Website,
+		AddressLine1:       req.AddressLine1,
+		AddressLine2:       req.AddressLine2,
+		City:               req.City,
+		StateProvince:      normalizeRegion(country, req.StateProvince),
+		PostalCode:         req.PostalCode,
+		Country:            country,
+		Locale:             vendorLocale(v, req.Locale, country),
+		PaymentTerms:       req.PaymentTerms,
+		PaymentMethod:      req.PaymentMethod,
+		Currency:           strings.ToUpper(req.Currency),
+		AcceptedCurrencies: normalizeCurrencies(v, req.AcceptedCurrencies),
+		CreditLimit:        req.CreditLimit,
+		CurrentBalance:     0,
+		BankName:           req.BankName,
+		BankAccountNumber:  req.BankAccountNumber,
+		BankRoutingNumber:  req.BankRoutingNumber,
+		SwiftCode:          req.SwiftCode,
+		IBAN:               req.IBAN,
+		Notes:              req.Notes,
+		Tags:               normalizeTags(v, "tags", req.Tags),
+		TemplateID:         templateID,
+		CreatedBy:          createdBy,
 	}
 
-	if err := s.vendorRepo.Create(ctx, vendor); err != nil {
+	vendor.CreditLimitCurrency = creditLimitCurrency(v, vendor.CreditLimit, vendor.Currency, req.CreditLimitCurrency, req.CreditLimitCurrencyOverride)
+	vendor.Source, vendor.SourceReference = vendorSource(v, req.Source, req.DefaultSource, req.SourceReference)
+
+	checkVendorFieldLengths(v, vendor)
+	checkRemittanceEmail(v, vendor)
+	checkAcceptedCurrencies(v, vendor.Currency, vendor.AcceptedCurrencies)
+	warnings, err := s.checkContactMethod(ctx, v, vendor, contacts)
+	if err != nil {
+		return nil, err
+	}
+	addressWarnings, err := s.checkAddress(ctx, v, nil, vendor)
+	if err != nil {
 		return nil, err
 	}
+	warnings = append(warnings, addressWarnings...)
+	warnings = append(warnings, s.checkCountryProfile(v, nil, vendor)...)
+	bankWarnings, err := s.checkBankDetails(ctx, v, nil, vendor)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, bankWarnings...)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	blocklistWarnings, err := s.checkBlocklist(ctx, vendor, repository.BlocklistStageCreate, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, blocklistWarnings...)
+
+	debounceKey, err := s.claimCreate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		count, err := repo.CountLiveVendors(ctx, vendor.EntityID)
+		if err != nil {
+			return err
+		}
+		if err := s.checkVendorQuota(ctx, vendor.EntityID, count, 1); err != nil {
+			return err
+		}
+		if err := claimReservedCode(ctx, repo, vendor.EntityID, vendor.VendorCode, vendor.CreatedBy); err != nil {
+			return err
+		}
+
+		if err := repo.Create(ctx, vendor); err != nil {
+			return err
+		}
+		if err := s.recordCreditLimitCurrencyOverride(ctx, repo, nil, vendor, vendor.CreatedBy); err != nil {
+			return err
+		}
+
+		for _, contact := range contacts {
+			contact.VendorID = vendor.ID
+			if err := repo.AddContact(ctx, contact); err != nil {
+				return err
+			}
+		}
+
+		return s.rescoreVendor(ctx, repo, vendor)
+	})
+	if err != nil {
+		s.settleCreate(ctx, debounceKey, "")
+		return nil, err
+	}
+	s.settleCreate(ctx, debounceKey, vendor.ID)
+
+	if len(contacts) > 0 {
+		vendor.Contacts = contacts
+	}
+	setWarnings(vendor, append(warnings, s.savedVendorWarnings(ctx, nil, vendor)...))
 
-	s.log.Info().
-		Str("vendor_id", vendor.ID).
+	reqlog.SetVendor(ctx, vendor.ID)
+	s.logger(ctx).Info().
 		Str("vendor_code", vendor.VendorCode).
-		Str("entity_id", req.EntityID).
+		Int("contacts", len(contacts)).
 		Msg("Vendor created")
+	s.logWarnings(ctx, vendor)
 
 	return vendor, nil
 }
 
-// GetVendor retrieves a vendor by ID
-func (s *VendorService) GetVendor(ctx context.Context, id, entityID string) (*repository.Vendor, error) {
-	return s.vendorRepo.GetByID(ctx, id, entityID)
+// Expand options accepted by GetVendor
+const (
+	ExpandExternalRefs = "external_refs"
+)
+
+// GetVendor retrieves a vendor by ID, optionally loading related data named in expand
+func (s *VendorService) GetVendor(ctx context.Context, id, entityID string, expand ...string) (*repository.Vendor, error) {
+	for _, option := range expand {
+		if option != ExpandExternalRefs && option != ExpandDataQuality && option != ExpandUsers {
+			return nil, errors.InvalidInput("expand", fmt.Sprintf("unknown expand option %q", option))
+		}
+	}
+
+	vendor, err := s.vendorRepo.GetByID(ctx, id, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	if vendor.Status == "pending_approval" {
+		if vendor.Approval, err = s.vendorApprovalState(ctx, s.vendorRepo, vendor); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.attachRiskScores(ctx, vendor); err != nil {
+		return nil, err
+	}
+	if err := s.attachTINMatches(ctx, vendor); err != nil {
+		return nil, err
+	}
+	if err := s.attachBankVerifications(ctx, vendor); err != nil {
+		return nil, err
+	}
+
+	for _, option := range expand {
+		switch option {
+		case ExpandExternalRefs:
+			refs, err := s.vendorRepo.GetExternalRefs(ctx, id, entityID)
+			if err != nil {
+				return nil, err
+			}
+			vendor.ExternalRefs = make(map[string]string, len(refs))
+			for _, ref := range refs {
+				vendor.ExternalRefs[ref.System] = ref.ExternalID
+			}
+		case ExpandDataQuality:
+			if err := s.attachDataQuality(ctx, vendor); err != nil {
+				return nil, err
+			}
+		case ExpandUsers:
+			s.attachUsers(ctx, vendor)
+		}
+	}
+
+	return vendor, nil
 }
 
-// GetVendorByCode retrieves a vendor by code
+// GetVendorByCode retrieves a vendor by code, matched in any case
 func (s *VendorService) GetVendorByCode(ctx context.Context, code, entityID string) (*repository.Vendor, error) {
-	return s.vendorRepo.GetByCode(ctx, code, entityID)
+	return s.vendorRepo.GetByCode(ctx, normalizeVendorCode(code), entityID)
+}
+
+// normalizeVendorCode returns a vendor code as stored: trimmed and uppercased
+func normalizeVendorCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
 }
 
 // UpdateVendor updates a vendor
 func (s *VendorService) UpdateVendor(ctx context.Context, req *UpdateVendorRequest) (*repository.Vendor, error) {
+	reqlog.SetEntity(ctx, req.EntityID)
+	reqlog.SetVendor(ctx, req.ID)
+	ctx = repository.UsePrimary(ctx)
+
+	if err := s.checkEntityWritable(ctx, req.EntityID); err != nil {
+		return nil, err
+	}
+
 	// Get existing vendor
 	vendor, err := s.vendorRepo.GetByID(ctx, req.ID, req.EntityID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check if code is being changed and if new code is unique
-	if req.VendorCode != vendor.VendorCode {
-		existing, _ := s.vendorRepo.GetByCode(ctx, req.VendorCode, req.EntityID)
+	// Check if code is being changed and if new code is unique; a change of
+	// case alone is no rename
+	code := normalizeVendorCode(req.VendorCode)
+	if code != vendor.VendorCode {
+		existing, _ := s.vendorRepo.GetByCode(ctx, code, req.EntityID)
 		if existing != nil {
-			return nil, errors.AlreadyExists("vendor", req.VendorCode)
+			return nil, errors.AlreadyExists("vendor", code)
 		}
 	}
 
-	// Validate vendor type
+	// Collect every validation failure so they can be reported together
+	v := &validator{}
+
+	// Validate vendor type against the entity's registry; a deprecated type may be kept
 	vendorType := strings.ToLower(req.VendorType)
-	if vendorType != "supplier" && vendorType != "contractor" && vendorType != "service_provider" &&
-		vendorType != "consultant" && vendorType != "utility" {
-		return nil, errors.InvalidInput("vendor_type", "invalid vendor type")
+	if err := s.checkVendorType(ctx, v, req.EntityID, vendorType, vendor.VendorType); err != nil {
+		return nil, err
 	}
 
-	// Validate status
-	status := strings.ToLower(req.Status)
-	if status != "active" && status != "inactive" && status != "suspended" && status != "pending_approval" {
-		return nil, errors.InvalidInput("status", "invalid vendor status")
-	}
+	// Validate currency and country
+	v.check(len(req.Currency) == 3, "currency", "currency must be 3-letter ISO code")
+	country := address.NormalizeCountry(req.Country)
+	v.check(len(country) == 2, "country", "country must be 2-letter ISO code")
 
 	// Validate credit limit if set
-	if req.CreditLimit != nil && *req.CreditLimit < 0 {
-		return nil, errors.InvalidInput("credit_limit", "credit limit cannot be negative")
-	}
+	v.check(req.CreditLimit == nil || *req.CreditLimit >= 0, "credit_limit", "credit limit cannot be negative")
+
+	// Keep the stored state to detect bank detail changes
+	before := *vendor
 
 	// Update vendor
-	vendor.VendorCode = strings.ToUpper(req.VendorCode)
+	vendor.VendorCode = code
 	vendor.VendorName = req.VendorName
 	vendor.LegalName = req.LegalName
+	vendor.DoingBusinessAs = req.DoingBusinessAs
 	vendor.VendorType = vendorType
-	vendor.Status = status
 	vendor.TaxID = req.TaxID
 	vendor.IsTaxExempt = req.IsTaxExempt
 	vendor.Is1099Vendor = req.Is1099Vendor
 	vendor.Email = req.Email
+	vendor.RemittanceEmail = req.RemittanceEmail
 	vendor.Phone = req.Phone
 	vendor.Fax = req.Fax
 	vendor.Website = req.Website
 	vendor.AddressLine1 = req.AddressLine1
 	vendor.AddressLine2 = req.AddressLine2
 	vendor.City = req.City
-	vendor.StateProvince = req.StateProvince
+	vendor.StateProvince = normalizeRegion(country, req.StateProvince)
 	vendor.PostalCode = req.PostalCode
-	vendor.Country = strings.ToUpper(req.Country)
+	vendor.Country = country
+	vendor.Locale = vendorLocale(v, req.Locale, country)
 	vendor.PaymentTerms = req.PaymentTerms
 	vendor.PaymentMethod = req.PaymentMethod
 	vendor.Currency = strings.ToUpper(req.Currency)
+	vendor.AcceptedCurrencies = normalizeCurrencies(v, req.AcceptedCurrencies)
 	vendor.CreditLimit = req.CreditLimit
+	limitCurrency, limitOverride := keptCreditLimitCurrency(&before, req.CreditLimitCurrency, req.CreditLimitCurrencyOverride)
+	vendor.CreditLimitCurrency = creditLimitCurrency(v, vendor.CreditLimit, vendor.Currency, limitCurrency, limitOverride)
 	vendor.BankName = req.BankName
 	vendor.BankAccountNumber = req.BankAccountNumber
 	vendor.BankRoutingNumber = req.BankRoutingNumber
 	vendor.SwiftCode = req.SwiftCode
 	vendor.IBAN = req.IBAN
 	vendor.Notes = req.Notes
-	vendor.Tags = req.Tags
+	vendor.Tags = normalizeTags(v, "tags", req.Tags)
 
 	// Convert empty string to NULL for UpdatedBy
 	var updatedBy *string
@@ -282,46 +614,602 @@ func (s *VendorService) UpdateVendor(ctx context.Context, req *UpdateVendorReque
 		updatedBy = &req.UpdatedBy
 	}
 	vendor.UpdatedBy = updatedBy
+	keepProtectedFields(&before, vendor)
+	if err := s.checkVendorCodeLock(ctx, &before, vendor, req.OverrideCodeLock); err != nil {
+		return nil, err
+	}
+	currencyOverride, err := s.checkCurrencyChange(ctx, &before, vendor.Currency, req.OverrideCurrencyLock, req.CurrencyConversionNote)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := s.vendorRepo.Update(ctx, vendor); err != nil {
+	contacts, err := s.vendorRepo.GetContacts(ctx, vendor.ID)
+	if err != nil {
+		return nil, err
+	}
+	checkVendorFieldLengths(v, vendor)
+	checkRemittanceEmail(v, vendor)
+	checkAcceptedCurrencies(v, vendor.Currency, vendor.AcceptedCurrencies)
+	warnings, err := s.checkContactMethod(ctx, v, vendor, contacts)
+	if err != nil {
+		return nil, err
+	}
+	addressWarnings, err := s.checkAddress(ctx, v, &before, vendor)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, addressWarnings...)
+	warnings = append(warnings, s.checkCountryProfile(v, &before, vendor)...)
+	bankWarnings, err := s.checkBankDetails(ctx, v, &before, vendor)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, bankWarnings...)
+	if err := v.err(); err != nil {
 		return nil, err
 	}
 
-	s.log.Info().
-		Str("vendor_id", vendor.ID).
+	err = s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		if vendor.VendorCode != before.VendorCode {
+			if err := claimReservedCode(ctx, repo, vendor.EntityID, vendor.VendorCode, updatedBy); err != nil {
+				return err
+			}
+		}
+		if err := repo.Update(ctx, vendor); err != nil {
+			return err
+		}
+		if err := s.recordVendorChanges(ctx, repo, &before, vendor, updatedBy); err != nil {
+			return err
+		}
+		if err := s.recordBankDetailsChange(ctx, repo, &before, vendor, updatedBy); err != nil {
+			return err
+		}
+		if err := s.recordLockedCodeRename(ctx, repo, &before, vendor, updatedBy); err != nil {
+			return err
+		}
+		if err := s.recordCreditLimitCurrencyOverride(ctx, repo, &before, vendor, updatedBy); err != nil {
+			return err
+		}
+		if currencyOverride {
+			if err := s.recordCurrencyOverride(ctx, repo, &before, vendor, req.CurrencyConversionNote, updatedBy); err != nil {
+				return err
+			}
+		}
+		return s.rescoreVendor(ctx, repo, vendor)
+	})
+	if err != nil {
+		return nil, err
+	}
+	setWarnings(vendor, append(warnings, s.savedVendorWarnings(ctx, &before, vendor)...))
+
+	reqlog.SetVendor(ctx, vendor.ID)
+	s.logger(ctx).Info().
 		Str("vendor_code", vendor.VendorCode).
 		Msg("Vendor updated")
+	s.logWarnings(ctx, vendor)
 
 	return vendor, nil
 }
 
-// DeleteVendor deletes a vendor
-func (s *VendorService) DeleteVendor(ctx context.Context, id, entityID string) error {
+// UpsertVendorRequest represents a create-or-update by vendor code request.
+// Nil fields are left untouched when the vendor already exists; vendor_name,
+// vendor_type, country, payment_terms and currency are required when it does not.
+type UpsertVendorRequest struct {
+	EntityID           string   `json:"entity_id"`
+	VendorCode         string   `json:"vendor_code"`
+	VendorName         *string  `json:"vendor_name,omitempty"`
+	LegalName          *string  `json:"legal_name,omitempty"`
+	DoingBusinessAs    *string  `json:"doing_business_as,omitempty"`
+	VendorType         *string  `json:"vendor_type,omitempty"`
+	TaxID              *string  `json:"tax_id,omitempty"`
+	IsTaxExempt        *bool    `json:"is_tax_exempt,omitempty"`
+	Is1099Vendor       *bool    `json:"is_1099_vendor,omitempty"`
+	Email              *string  `json:"email,omitempty"`
+	RemittanceEmail    *string  `json:"remittance_email,omitempty"`
+	Phone              *string  `json:"phone,omitempty"`
+	Fax                *string  `json:"fax,omitempty"`
+	Website            *string  `json:"website,omitempty"`
+	AddressLine1       *string  `json:"address_line1,omitempty"`
+	AddressLine2       *string  `json:"address_line2,omitempty"`
+	City               *string  `json:"city,omitempty"`
+	StateProvince      *string  `json:"state_province,omitempty"`
+	PostalCode         *string  `json:"postal_code,omitempty"`
+	Country            *string  `json:"country,omitempty"`
+	Locale             *string  `json:"locale,omitempty"`
+	PaymentTerms       *string  `json:"payment_terms,omitempty"`
+	PaymentMethod      *string  `json:"payment_method,omitempty"`
+	Currency           *string  `json:"currency,omitempty"`
+	AcceptedCurrencies []string `json:"accepted_currencies,omitempty"`
+	CreditLimit        *int64   `json:"credit_limit,omitempty"`
+	BankName           *string  `json:"bank_name,omitempty"`
+	BankAccountNumber  *string  `json:"bank_account_number,omitempty"`
+	BankRoutingNumber  *string  `json:"bank_routing_number,omitempty"`
+	SwiftCode          *string  `json:"swift_code,omitempty"`
+	IBAN               *string  `json:"iban,omitempty"`
+	Notes              *string  `json:"notes,omitempty"`
+	Tags               []string `json:"tags,omitempty"`
+	UpdatedBy          string   `json:"-"`
+	// CurrencyConversionNote and OverrideCurrencyLock work as for UpdateVendor
+	// when the currency of an existing vendor changes
+	CurrencyConversionNote *string `json:"currency_conversion_note,omitempty"`
+	OverrideCurrencyLock   bool    `json:"-"`
+
+	// CreditLimitCurrency and CreditLimitCurrencyOverride work as for
+	// CreateVendorRequest
+	CreditLimitCurrency         *string `json:"credit_limit_currency,omitempty"`
+	CreditLimitCurrencyOverride bool    `json:"credit_limit_currency_override,omitempty"`
+
+	// Source, SourceReference and DefaultSource work as for
+	// CreateVendorRequest; they are recorded only when the upsert creates the
+	// vendor
+	Source          string  `json:"source,omitempty"`
+	SourceReference *string `json:"source_reference,omitempty"`
+	DefaultSource   string  `json:"-"`
+}
+
+// UpsertVendorByCode creates the vendor when no vendor with the code exists in the
+// entity, and otherwise applies only the provided fields to the existing vendor.
+// Existing vendors keep their status; new vendors start in pending_approval.
+func (s *VendorService) UpsertVendorByCode(ctx context.Context, req *UpsertVendorRequest) (*repository.Vendor, bool, error) {
+	reqlog.SetEntity(ctx, req.EntityID)
+
+	code := normalizeVendorCode(req.VendorCode)
+	if code == "" {
+		return nil, false, errors.InvalidInput("vendor_code", "vendor code is required")
+	}
+	if err := s.checkEntityWritable(ctx, req.EntityID); err != nil {
+		return nil, false, err
+	}
+
+	// Placeholders keep NOT NULL columns satisfied for the insert attempt; if the row
+	// turns out to be new while required fields are missing the transaction is rolled back.
+	vendor := &repository.Vendor{
+		EntityID:          req.EntityID,
+		VendorCode:        code,
+		VendorType:        "supplier",
+		Status:            "pending_approval",
+		LegalName:         req.LegalName,
+		DoingBusinessAs:   req.DoingBusinessAs,
+		TaxID:             req.TaxID,
+		Email:             req.Email,
+		RemittanceEmail:   req.RemittanceEmail,
+		Phone:             req.Phone,
+		Fax:               req.Fax,
+		Website:           req.Website,
+		AddressLine1:      req.AddressLine1,
+		AddressLine2:      req.AddressLine2,
+		City:              req.City,
+		StateProvince:     req.StateProvince,
+		PostalCode:        req.PostalCode,
+		PaymentMethod:     req.PaymentMethod,
+		CreditLimit:       req.CreditLimit,
+		BankName:          req.BankName,
+		BankAccountNumber: req.BankAccountNumber,
+		BankRoutingNumber: req.BankRoutingNumber,
+		SwiftCode:         req.SwiftCode,
+		IBAN:              req.IBAN,
+		Notes:             req.Notes,
+	}
+	if req.UpdatedBy != "" {
+		vendor.CreatedBy = &req.UpdatedBy
+	}
+
+	// Collect every validation failure so they can be reported together
+	v := &validator{}
+	vendor.Tags = normalizeTags(v, "tags", req.Tags)
+	vendor.Source, vendor.SourceReference = vendorSource(v, req.Source, req.DefaultSource, req.SourceReference)
+
+	existing, _ := s.vendorRepo.GetByCode(repository.UsePrimary(ctx), code, req.EntityID)
+
+	var columns, missing []string
+	optional := []struct {
+		column string
+		set    bool
+	}{
+		{"legal_name", req.LegalName != nil},
+		{"doing_business_as", req.DoingBusinessAs != nil},
+		{"tax_id", req.TaxID != nil},
+		{"email", req.Email != nil},
+		{"remittance_email", req.RemittanceEmail != nil},
+		{"phone", req.Phone != nil},
+		{"fax", req.Fax != nil},
+		{"website", req.Website != nil},
+		{"address_line1", req.AddressLine1 != nil},
+		{"address_line2", req.AddressLine2 != nil},
+		{"city", req.City != nil},
+		{"state_province", req.StateProvince != nil},
+		{"postal_code", req.PostalCode != nil},
+		{"payment_method", req.PaymentMethod != nil},
+		{"credit_limit", req.CreditLimit != nil},
+		{"bank_name", req.BankName != nil},
+		{"bank_account_number", req.BankAccountNumber != nil},
+		{"bank_routing_number", req.BankRoutingNumber != nil},
+		{"swift_code", req.SwiftCode != nil},
+		{"iban", req.IBAN != nil},
+		{"notes", req.Notes != nil},
+		{"tags", req.Tags != nil},
+	}
+	for _, field := range optional {
+		if field.set {
+			columns = append(columns, field.column)
+		}
+	}
+
+	if req.VendorName != nil {
+		vendor.VendorName = *req.VendorName
+		columns = append(columns, "vendor_name")
+	} else {
+		missing = append(missing, "vendor_name")
+	}
+
+	if req.VendorType != nil {
+		vendor.VendorType = strings.ToLower(*req.VendorType)
+		// A deprecated type may only be kept by an existing vendor already using it
+		var current string
+		if existing != nil {
+			current = existing.VendorType
+		}
+		if err := s.checkVendorType(ctx, v, req.EntityID, vendor.VendorType, current); err != nil {
+			return nil, false, err
+		}
+		columns = append(columns, "vendor_type")
+	} else {
+		missing = append(missing, "vendor_type")
+	}
+
+	if req.IsTaxExempt != nil {
+		vendor.IsTaxExempt = *req.IsTaxExempt
+		columns = append(columns, "is_tax_exempt")
+	}
+
+	if req.Is1099Vendor != nil {
+		vendor.Is1099Vendor = *req.Is1099Vendor
+		columns = append(columns, "is_1099_vendor")
+	}
+
+	if req.Country != nil {
+		vendor.Country = address.NormalizeCountry(*req.Country)
+		v.check(len(vendor.Country) == 2, "country", "country must be 2-letter ISO code")
+		columns = append(columns, "country")
+	} else {
+		missing = append(missing, "country")
+	}
+
+	if req.PaymentTerms != nil {
+		vendor.PaymentTerms = *req.PaymentTerms
+		columns = append(columns, "payment_terms")
+	} else {
+		missing = append(missing, "payment_terms")
+	}
+
+	if req.Currency != nil {
+		v.check(len(*req.Currency) == 3, "currency", "currency must be 3-letter ISO code")
+		vendor.Currency = strings.ToUpper(*req.Currency)
+		columns = append(columns, "currency")
+	} else {
+		missing = append(missing, "currency")
+	}
+
+	v.check(req.CreditLimit == nil || *req.CreditLimit >= 0, "credit_limit", "credit limit cannot be negative")
+
+	// The accepted currencies the vendor ends up with must include its primary currency
+	if req.AcceptedCurrencies != nil {
+		vendor.AcceptedCurrencies = normalizeCurrencies(v, req.AcceptedCurrencies)
+		columns = append(columns, "accepted_currencies")
+	}
+	primary, accepted := vendor.Currency, vendor.AcceptedCurrencies
+	if existing != nil {
+		if req.Currency == nil {
+			primary = existing.Currency
+		}
+		if req.AcceptedCurrencies == nil {
+			accepted = existing.AcceptedCurrencies
+		}
+	}
+	checkAcceptedCurrencies(v, primary, accepted)
+
+	// The credit limit currency follows the vendor currency unless a limit
+	// in another currency is kept or requested
+	if req.CreditLimit != nil || req.CreditLimitCurrency != nil || req.Currency != nil {
+		limit, requested, override := vendor.CreditLimit, req.CreditLimitCurrency, req.CreditLimitCurrencyOverride
+		if existing != nil {
+			if req.CreditLimit == nil {
+				limit = existing.CreditLimit
+			}
+			requested, override = keptCreditLimitCurrency(existing, requested, override)
+		}
+		vendor.CreditLimitCurrency = creditLimitCurrency(v, limit, primary, requested, override)
+		if limit != nil {
+			columns = append(columns, "credit_limit_currency")
+		}
+	}
+
+	currencyOverride := false
+	if existing != nil && req.Currency != nil {
+		var err error
+		currencyOverride, err = s.checkCurrencyChange(ctx, existing, vendor.Currency, req.OverrideCurrencyLock, deref(req.CurrencyConversionNote))
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	// New vendors get the default locale of their country when none is given
+	vendor.Locale = vendorLocale(v, req.Locale, vendor.Country)
+	if req.Locale != nil {
+		columns = append(columns, "locale")
+	}
+
+	// Normalize the state or province for the country the vendor ends up with
+	if req.StateProvince != nil {
+		country := vendor.Country
+		if req.Country == nil && existing != nil {
+			country = existing.Country
+		}
+		vendor.StateProvince = normalizeRegion(country, req.StateProvince)
+	}
+
+	// Validate the vendor as it ends up, as CreateVendor and UpdateVendor do
+	after := *vendor
+	var contacts []*repository.VendorContact
+	if existing != nil {
+		after = *existing
+		applyAddressColumns(&after, vendor, columns)
+		applyCountryProfileColumns(&after, vendor, columns)
+		applyBankColumns(&after, vendor, columns)
+
+		var err error
+		if contacts, err = s.vendorRepo.GetContacts(ctx, existing.ID); err != nil {
+			return nil, false, err
+		}
+	}
+	checkVendorFieldLengths(v, vendor)
+	checkRemittanceEmail(v, vendor)
+	warnings, err := s.checkContactMethod(ctx, v, &after, contacts)
+	if err != nil {
+		return nil, false, err
+	}
+	addressWarnings, err := s.checkAddress(ctx, v, existing, &after)
+	if err != nil {
+		return nil, false, err
+	}
+	warnings = append(warnings, addressWarnings...)
+	warnings = append(warnings, s.checkCountryProfile(v, existing, &after)...)
+	bankWarnings, err := s.checkBankDetails(ctx, v, existing, &after)
+	if err != nil {
+		return nil, false, err
+	}
+	warnings = append(warnings, bankWarnings...)
+	if err := v.err(); err != nil {
+		return nil, false, err
+	}
+
+	if existing == nil {
+		blocklistWarnings, err := s.checkBlocklist(ctx, vendor, repository.BlocklistStageCreate, vendor.CreatedBy)
+		if err != nil {
+			return nil, false, err
+		}
+		warnings = append(warnings, blocklistWarnings...)
+	}
+
+	var stored *repository.Vendor
+	var created bool
+	err = s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		before, _ := repo.GetByCode(ctx, code, req.EntityID)
+
+		var err error
+		stored, created, err = repo.UpsertByCode(ctx, vendor, columns)
+		if err != nil {
+			return err
+		}
+		if created {
+			before = nil
+		}
+		if err := s.recordCreditLimitCurrencyOverride(ctx, repo, before, stored, vendor.CreatedBy); err != nil {
+			return err
+		}
+
+		if !created && before != nil {
+			if err := s.recordVendorChanges(ctx, repo, before, stored, vendor.CreatedBy); err != nil {
+				return err
+			}
+			if err := s.recordBankDetailsChange(ctx, repo, before, stored, vendor.CreatedBy); err != nil {
+				return err
+			}
+			if currencyOverride && !strings.EqualFold(before.Currency, stored.Currency) {
+				if err := s.recordCurrencyOverride(ctx, repo, before, stored, deref(req.CurrencyConversionNote), vendor.CreatedBy); err != nil {
+					return err
+				}
+			}
+		}
+
+		if created {
+			if err := claimReservedCode(ctx, repo, stored.EntityID, stored.VendorCode, vendor.CreatedBy); err != nil {
+				return err
+			}
+
+			// The count includes the vendor just inserted
+			count, err := repo.CountLiveVendors(ctx, stored.EntityID)
+			if err != nil {
+				return err
+			}
+			if err := s.checkVendorQuota(ctx, stored.EntityID, count-1, 1); err != nil {
+				return err
+			}
+		}
+
+		if created && len(missing) > 0 {
+			required := &validator{}
+			for _, field := range missing {
+				required.add(field, field+" is required when creating a vendor")
+			}
+			return required.err()
+		}
+
+		return s.rescoreVendor(ctx, repo, stored)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	setWarnings(stored, warnings)
+
+	reqlog.SetEntity(ctx, stored.EntityID)
+	reqlog.SetVendor(ctx, stored.ID)
+	s.logger(ctx).Info().
+		Str("vendor_code", stored.VendorCode).
+		Bool("created", created).
+		Strs("fields", columns).
+		Msg("Vendor upserted")
+	s.logWarnings(ctx, stored)
+
+	return stored, created, nil
+}
+
+// DeleteReport lists what deleting a vendor touches
+type DeleteReport struct {
+	VendorID string `json:"vendor_id"`
+	DryRun   bool   `json:"dry_run"`
+	// Contacts is Children.Contacts, kept for clients that predate Children
+	Contacts int `json:"contacts"`
+	// ExternalRefs are the external system mappings that are released
+	ExternalRefs []*repository.VendorExternalRef `json:"external_refs"`
+	// ActiveAPIKeys stop working along with the vendor
+	ActiveAPIKeys int `json:"active_api_keys"`
+
+	// Children are the child records of the vendor, deleted along with it
+	// when ChildrenDeleted and left attached to it otherwise
+	Children        *repository.VendorChildren `json:"children"`
+	ChildrenDeleted bool                       `json:"children_deleted"`
+}
+
+// DeleteVendor soft-deletes a vendor and releases its external system mappings
+// so the external IDs can be mapped to another vendor. A vendor with child
+// records is only deleted when keepChildren says what happens to them: kept
+// attached to the deleted vendor, or deleted in the same transaction;
+// otherwise a VendorHasChildrenError lists them. With dryRun the delete runs
+// in a transaction that is rolled back, returning the report only.
+func (s *VendorService) DeleteVendor(ctx context.Context, id, entityID string, dryRun bool, keepChildren *bool) (*DeleteReport, error) {
 	// TODO: Check if vendor has invoices (when invoice service is implemented)
 
-	if err := s.vendorRepo.Delete(ctx, id, entityID); err != nil {
-		return err
+	ctx = repository.UsePrimary(ctx)
+	report := &DeleteReport{VendorID: id, DryRun: dryRun}
+	if err := s.checkEntityWritable(ctx, entityID); err != nil {
+		return nil, err
 	}
+	err := s.withTx(ctx, dryRun, func(repo repository.Store) error {
+		refs, err := repo.GetExternalRefs(ctx, id, entityID)
+		if err != nil {
+			return err
+		}
+		keys, err := repo.ListVendorAPIKeys(ctx, id, entityID)
+		if err != nil {
+			return err
+		}
 
-	s.log.Info().
-		Str("vendor_id", id).
-		Str("entity_id", entityID).
-		Msg("Vendor deleted")
+		// Deleting first checks the vendor belongs to the entity before its
+		// children are looked at
+		if err := repo.Delete(ctx, id, entityID); err != nil {
+			return err
+		}
+		children, err := repo.CountVendorChildren(ctx, id)
+		if err != nil {
+			return err
+		}
+		switch {
+		case children.Total() > 0 && keepChildren == nil:
+			return &VendorHasChildrenError{VendorID: id, Children: children}
+		case children.Total() > 0 && !*keepChildren:
+			if children, err = repo.DeleteVendorChildren(ctx, id); err != nil {
+				return err
+			}
+			report.ChildrenDeleted = true
+		}
+		if err := repo.DeleteAllExternalRefs(ctx, id, entityID); err != nil {
+			return err
+		}
 
-	return nil
+		report.Children = children
+		report.Contacts = children.Contacts
+		report.ExternalRefs = refs
+		for _, key := range keys {
+			if key.RevokedAt == nil {
+				report.ActiveAPIKeys++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if report.ExternalRefs == nil {
+		report.ExternalRefs = make([]*repository.VendorExternalRef, 0)
+	}
+
+	reqlog.SetEntity(ctx, entityID)
+	reqlog.SetVendor(ctx, id)
+	if dryRun {
+		s.logger(ctx).Info().Bool("children_deleted", report.ChildrenDeleted).Msg("Vendor delete dry run")
+	} else {
+		s.logger(ctx).Info().Bool("children_deleted", report.ChildrenDeleted).Msg("Vendor deleted")
+	}
+
+	return report, nil
 }
 
-// ListVendors lists vendors with filtering and pagination
-func (s *VendorService) ListVendors(ctx context.Context, entityID string, status, vendorType *string, activeOnly bool, page, pageSize int) ([]*repository.Vendor, int64, error) {
+// ListVendors lists the vendors of an entity matching filter. filter.TotalMode
+// picks how the total is computed; empty or TotalModeAuto lets the size of the
+// entity decide. Estimated totals of the last page are exact.
+func (s *VendorService) ListVendors(ctx context.Context, filter repository.VendorFilter, page, pageSize int) (*VendorPage, error) {
+	mode, err := s.resolveTotalMode(ctx, filter.EntityID, filter.TotalMode)
+	if err != nil {
+		return nil, err
+	}
+	filter.TotalMode = mode
+
 	offset := (page - 1) * pageSize
-	return s.vendorRepo.List(ctx, entityID, status, vendorType, activeOnly, pageSize, offset)
+	vendors, total, err := s.vendorRepo.List(ctx, filter, pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+	// Lists restricted to some fields skip what is not stored with the vendor
+	if len(filter.Fields) == 0 {
+		if err := s.attachRiskScores(ctx, vendors...); err != nil {
+			return nil, err
+		}
+		if err := s.attachTINMatches(ctx, vendors...); err != nil {
+			return nil, err
+		}
+		if err := s.attachBankVerifications(ctx, vendors...); err != nil {
+			return nil, err
+		}
+	}
+	if filter.IncludeCounts {
+		if err := s.attachChildCounts(ctx, vendors...); err != nil {
+			return nil, err
+		}
+	}
+	if filter.ResolveUsers {
+		s.attachUsers(ctx, vendors...)
+	}
+
+	result := &VendorPage{Vendors: vendors, Total: total, TotalMode: mode}
+	if mode == repository.TotalEstimate {
+		result.Total, result.TotalMode = settleEstimate(total, len(vendors), offset, pageSize)
+	}
+	return result, nil
 }
 
-// ActivateVendor activates a vendor
-func (s *VendorService) ActivateVendor(ctx context.Context, id, entityID, updatedBy string) error {
+// ActivateVendor activates a vendor and returns it
+func (s *VendorService) ActivateVendor(ctx context.Context, id, entityID, updatedBy string) (*repository.Vendor, error) {
+	ctx = repository.UsePrimary(ctx)
+
+	if err := s.checkEntityWritable(ctx, entityID); err != nil {
+		return nil, err
+	}
+
 	vendor, err := s.vendorRepo.GetByID(ctx, id, entityID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Convert empty string to NULL for UpdatedBy
@@ -330,26 +1218,41 @@ func (s *VendorService) ActivateVendor(ctx context.Context, id, entityID, update
 		updatedByPtr = &updatedBy
 	}
 
+	before := *vendor
 	vendor.Status = "active"
 	vendor.UpdatedBy = updatedByPtr
 
-	if err := s.vendorRepo.Update(ctx, vendor); err != nil {
-		return err
+	err = s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		if err := s.checkApprovalTransition(ctx, repo, &before, vendor); err != nil {
+			return err
+		}
+		if err := repo.Update(ctx, vendor); err != nil {
+			return err
+		}
+		return s.recordVendorChanges(ctx, repo, &before, vendor, updatedByPtr)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	s.log.Info().
-		Str("vendor_id", id).
-		Str("entity_id", entityID).
-		Msg("Vendor activated")
+	reqlog.SetEntity(ctx, entityID)
+	reqlog.SetVendor(ctx, id)
+	s.logger(ctx).Info().Msg("Vendor activated")
 
-	return nil
+	return vendor, nil
 }
 
-// DeactivateVendor deactivates a vendor
-func (s *VendorService) DeactivateVendor(ctx context.Context, id, entityID, updatedBy string) error {
+// DeactivateVendor deactivates a vendor and returns it
+func (s *VendorService) DeactivateVendor(ctx context.Context, id, entityID, updatedBy string) (*repository.Vendor, error) {
+	ctx = repository.UsePrimary(ctx)
+
+	if err := s.checkEntityWritable(ctx, entityID); err != nil {
+		return nil, err
+	}
+
 	vendor, err := s.vendorRepo.GetByID(ctx, id, entityID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// TODO: Check if vendor has pending invoices
@@ -360,64 +1263,208 @@ func (s *VendorService) DeactivateVendor(ctx context.Context, id, entityID, upda
 		updatedByPtr = &updatedBy
 	}
 
+	before := *vendor
 	vendor.Status = "inactive"
 	vendor.UpdatedBy = updatedByPtr
 
-	if err := s.vendorRepo.Update(ctx, vendor); err != nil {
-		return err
+	err = s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		if err := repo.Update(ctx, vendor); err != nil {
+			return err
+		}
+		return s.recordVendorChanges(ctx, repo, &before, vendor, updatedByPtr)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	s.log.Info().
-		Str("vendor_id", id).
-		Str("entity_id", entityID).
-		Msg("Vendor deactivated")
+	reqlog.SetEntity(ctx, entityID)
+	reqlog.SetVendor(ctx, id)
+	s.logger(ctx).Info().Msg("Vendor deactivated")
 
-	return nil
+	return vendor, nil
 }
 
-// GetVendorContacts retrieves all contacts for a vendor
-func (s *VendorService) GetVendorContacts(ctx context.Context, vendorID string) ([]*repository.VendorContact, error) {
-	return s.vendorRepo.GetContacts(ctx, vendorID)
+// SuspendVendor suspends a vendor, e.g. while a dispute is investigated, and
+// returns it
+func (s *VendorService) SuspendVendor(ctx context.Context, id, entityID, updatedBy string) (*repository.Vendor, error) {
+	ctx = repository.UsePrimary(ctx)
+
+	if err := s.checkEntityWritable(ctx, entityID); err != nil {
+		return nil, err
+	}
+
+	vendor, err := s.vendorRepo.GetByID(ctx, id, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert empty string to NULL for UpdatedBy
+	var updatedByPtr *string
+	if updatedBy != "" {
+		updatedByPtr = &updatedBy
+	}
+
+	before := *vendor
+	vendor.Status = "suspended"
+	vendor.UpdatedBy = updatedByPtr
+
+	err = s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		if err := repo.Update(ctx, vendor); err != nil {
+			return err
+		}
+		return s.recordVendorChanges(ctx, repo, &before, vendor, updatedByPtr)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reqlog.SetEntity(ctx, entityID)
+	reqlog.SetVendor(ctx, id)
+	s.logger(ctx).Info().Msg("Vendor suspended")
+
+	return vendor, nil
 }
 
-// AddVendorContact adds a contact to a vendor
-func (s *VendorService) AddVendorContact(ctx context.Context, req *AddContactRequest) (*repository.VendorContact, error) {
-	// Validate contact type
-	validTypes := map[string]bool{
-		"primary":   true,
-		"billing":   true,
-		"shipping":  true,
-		"technical": true,
-		"other":     true,
+// Page sizes of the contact list of a vendor
+const (
+	// DefaultContactPageSize is the number of contacts returned when no page size is requested
+	DefaultContactPageSize = 50
+	// MaxContactPageSize is the largest number of contacts returned at once
+	MaxContactPageSize = 200
+)
+
+// contactTypes are the valid contact types
+var contactTypes = map[string]bool{
+	"primary":   true,
+	"billing":   true,
+	"shipping":  true,
+	"technical": true,
+	"other":     true,
+}
+
+// ContactPage is a page of the contacts of a vendor
+type ContactPage struct {
+	Contacts []*repository.VendorContact `json:"contacts"`
+	Total    int64                       `json:"total"`
+}
+
+// ListVendorContacts retrieves a page of the contacts of a vendor, optionally
+// only those of a contact type or primary status. Pages start at 1 and hold
+// at most MaxContactPageSize contacts.
+func (s *VendorService) ListVendorContacts(ctx context.Context, filter repository.ContactFilter, page, pageSize int) (*ContactPage, error) {
+	v := &validator{}
+	v.check(filter.VendorID != "", "vendor_id", "vendor_id is required")
+	if filter.ContactType != nil {
+		contactType := strings.ToLower(*filter.ContactType)
+		filter.ContactType = &contactType
+		v.check(contactTypes[contactType], "contact_type", "invalid contact type")
 	}
+	v.check(repository.IsValidContactSort(filter.Sort), "sort", "invalid contact sort")
+	v.check(page >= 1, "page", "page must be at least 1")
+	v.check(pageSize >= 1 && pageSize <= MaxContactPageSize, "page_size",
+		fmt.Sprintf("page_size must be between 1 and %d", MaxContactPageSize))
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	contacts, total, err := s.vendorRepo.ListContacts(ctx, filter, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &ContactPage{Contacts: contacts, Total: total}, nil
+}
+
+// newContact validates a contact request and builds the repository model.
+// fieldPrefix qualifies field names in validation errors (e.g. "contacts[2].").
+func newContact(v *validator, req *AddContactRequest, fieldPrefix string) *repository.VendorContact {
+	if req == nil {
+		v.add(strings.TrimSuffix(fieldPrefix, "."), "contact is required")
+		return nil
+	}
+
+	// Validate contact type
 	contactType := strings.ToLower(req.ContactType)
-	if !validTypes[contactType] {
-		return nil, errors.InvalidInput("contact_type", "invalid contact type")
+	if !contactTypes[contactType] {
+		v.add(fieldPrefix+"contact_type", "invalid contact type")
+		return nil
 	}
 
 	contact := &repository.VendorContact{
-		VendorID:    req.VendorID,
-		ContactType: contactType,
-		FirstName:   req.FirstName,
-		LastName:    req.LastName,
-		Title:       req.Title,
-		Email:       req.Email,
-		Phone:       req.Phone,
-		Mobile:      req.Mobile,
-		IsPrimary:   req.IsPrimary,
-		Notes:       req.Notes,
+		VendorID:               req.VendorID,
+		ContactType:            contactType,
+		FirstName:              req.FirstName,
+		LastName:               req.LastName,
+		Title:                  req.Title,
+		Email:                  req.Email,
+		Phone:                  req.Phone,
+		Mobile:                 req.Mobile,
+		IsPrimary:              req.IsPrimary,
+		ReceivesPurchaseOrders: req.ReceivesPurchaseOrders,
+		ReceivesRemittance:     req.ReceivesRemittance,
+		ReceivesStatements:     req.ReceivesStatements,
+		Notes:                  req.Notes,
 	}
+	checkContactFieldLengths(v, contact, fieldPrefix)
+	return contact
+}
 
-	if err := s.vendorRepo.AddContact(ctx, contact); err != nil {
-		return nil, err
+// GetVendorContact retrieves a single contact of a vendor
+func (s *VendorService) GetVendorContact(ctx context.Context, vendorID, contactID string) (*repository.VendorContact, error) {
+	return s.vendorRepo.GetContact(ctx, vendorID, contactID)
+}
+
+// AddVendorContact adds a contact to a vendor and reports whether it was
+// created. A vendor has one contact per email, ignoring case: a contact with
+// the email of an existing one fails with a DuplicateContactError, or with
+// Upsert overwrites the existing contact.
+func (s *VendorService) AddVendorContact(ctx context.Context, req *AddContactRequest) (*repository.VendorContact, bool, error) {
+	v := &validator{}
+	contact := newContact(v, req, "")
+	if err := v.err(); err != nil {
+		return nil, false, err
 	}
 
-	s.log.Info().
-		Str("vendor_id", req.VendorID).
+	created := true
+	err := s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		if err := repo.LockVendorContacts(ctx, contact.VendorID); err != nil {
+			return err
+		}
+		if isSet(contact.Email) {
+			existing, err := repo.FindContactByEmail(ctx, contact.VendorID, *contact.Email)
+			if err != nil {
+				return err
+			}
+			if existing != nil {
+				if !req.Upsert {
+					return &DuplicateContactError{VendorID: contact.VendorID, ContactID: existing.ID, Email: deref(existing.Email)}
+				}
+				contact.ID = existing.ID
+				created = false
+				if err := repo.UpdateContact(ctx, contact); err != nil {
+					return err
+				}
+				return s.checkCommunicationChange(ctx, repo, existing, contact)
+			}
+		}
+		if err := repo.AddContact(ctx, contact); err != nil {
+			return err
+		}
+		return s.checkCommunicationChange(ctx, repo, nil, contact)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	reqlog.SetVendor(ctx, req.VendorID)
+	msg := "Vendor contact added"
+	if !created {
+		msg = "Vendor contact updated"
+	}
+	s.logger(ctx).Info().
 		Str("contact_id", contact.ID).
-		Msg("Vendor contact added")
+		Msg(msg)
 
-	return contact, nil
+	return contact, created, nil
 }
 
 // GetPaymentTerms retrieves all active payment terms
@@ -425,20 +1472,132 @@ func (s *VendorService) GetPaymentTerms(ctx context.Context) ([]*repository.Paym
 	return s.vendorRepo.GetPaymentTerms(ctx)
 }
 
-// ValidateVendor validates if a vendor can be used for invoice creation
-func (s *VendorService) ValidateVendor(ctx context.Context, vendorID, entityID string) (bool, string, error) {
-	return s.vendorRepo.ValidateVendor(ctx, vendorID, entityID)
+// ListPaymentTerms retrieves payment terms, optionally only the active ones
+func (s *VendorService) ListPaymentTerms(ctx context.Context, onlyActive bool) ([]*repository.PaymentTerm, error) {
+	return s.vendorRepo.ListPaymentTerms(ctx, onlyActive)
+}
+
+// GetPaymentTermByCode retrieves a payment term by code
+func (s *VendorService) GetPaymentTermByCode(ctx context.Context, code string, onlyActive bool) (*repository.PaymentTerm, error) {
+	v := &validator{}
+	v.check(strings.TrimSpace(code) != "", "code", "payment term code is required")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return s.vendorRepo.GetPaymentTermByCode(ctx, code, onlyActive)
+}
+
+// VendorValidation is the result of ValidateVendor. Warnings do not make a
+// vendor invalid.
+type VendorValidation struct {
+	Valid    bool     `json:"valid"`
+	Message  string   `json:"message"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ValidateVendor validates if a vendor can be used for invoice creation,
+// warning about missing payment details such as the remittance email, about
+// data the enabled data quality rules find missing, about
+// 1099 tax IDs that do not match IRS records, and about ACH and wire vendors
+// whose bank account is not verified, which makes them invalid in entities
+// requiring bank verification. When an invoice currency is given, a vendor
+// not accepting it is not valid.
+func (s *VendorService) ValidateVendor(ctx context.Context, vendorID, entityID, invoiceCurrency string) (*VendorValidation, error) {
+	invoiceCurrency = strings.ToUpper(strings.TrimSpace(invoiceCurrency))
+	if invoiceCurrency != "" && !isCurrencyCode(invoiceCurrency) {
+		return nil, errors.InvalidInput("invoice_currency", "invoice_currency must be an ISO 4217 currency code")
+	}
+
+	valid, message, err := s.vendorRepo.ValidateVendor(ctx, vendorID, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	vendor, err := s.vendorRepo.GetByID(ctx, vendorID, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachTINMatches(ctx, vendor); err != nil {
+		return nil, err
+	}
+	if err := s.attachBankVerifications(ctx, vendor); err != nil {
+		return nil, err
+	}
+	if err := s.attachDataQuality(ctx, vendor); err != nil {
+		return nil, err
+	}
+
+	if valid && invoiceCurrency != "" && !acceptsCurrency(vendor, invoiceCurrency) {
+		accepted := vendor.AcceptedCurrencies
+		if len(accepted) == 0 {
+			accepted = []string{vendor.Currency}
+		}
+		valid = false
+		message = fmt.Sprintf("vendor does not accept invoices in %s, accepted currencies: %s",
+			invoiceCurrency, strings.Join(accepted, ", "))
+	}
+
+	warnings := append(remittanceWarnings(vendor), tinMatchWarnings(vendor)...)
+	warnings = append(warnings, dataQualityWarnings(vendor)...)
+
+	// The repository only compares limits in the vendor currency
+	over, limitWarning, err := s.checkForeignCreditLimit(ctx, vendor)
+	if err != nil {
+		return nil, err
+	}
+	if over != "" && valid {
+		valid = false
+		message = over
+	}
+	if limitWarning != "" {
+		warnings = append(warnings, limitWarning)
+	}
+	if issue := bankVerificationIssue(vendor); issue != "" {
+		flags, err := s.entityFlags(ctx, entityID)
+		if err != nil {
+			return nil, err
+		}
+		if flags.RequireBankVerification && valid {
+			valid = false
+			message = issue
+		} else {
+			warnings = append(warnings, issue)
+		}
+	}
+
+	return &VendorValidation{
+		Valid:    valid,
+		Message:  message,
+		Warnings: warnings,
+	}, nil
 }
 
 // UpdateBalance updates the vendor's current balance
 func (s *VendorService) UpdateBalance(ctx context.Context, vendorID, entityID string, amount int64) error {
-	if err := s.vendorRepo.UpdateBalance(ctx, vendorID, entityID, amount); err != nil {
+	ctx = repository.UsePrimary(ctx)
+
+	if err := s.checkEntityWritable(ctx, entityID); err != nil {
+		return err
+	}
+
+	err := s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		if err := repo.UpdateBalance(ctx, vendorID, entityID, amount); err != nil {
+			return err
+		}
+		// The balance may have crossed the credit limit
+		vendor, err := repo.GetByID(ctx, vendorID, entityID)
+		if err != nil {
+			return err
+		}
+		return s.rescoreVendor(ctx, repo, vendor)
+	})
+	if err != nil {
 		return err
 	}
 
-	s.log.Info().
-		Str("vendor_id", vendorID).
-		Str("entity_id", entityID).
+	reqlog.SetEntity(ctx, entityID)
+	reqlog.SetVendor(ctx, vendorID)
+	s.logger(ctx).Info().
 		Int64("amount", amount).
 		Msg("Vendor balance updated")
 
@@ -0,0 +1,652 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/bankverify"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository/memory"
+	"github.com/pesio-ai/be-ap-vendors/internal/tinmatch"
+	"github.com/pesio-ai/be-lib-common/logger"
+)
+
+const testEntityID = "11111111-1111-4111-8111-111111111111"
+
+// newTestService returns a vendor service backed by an empty in-memory store
+func newTestService(t *testing.T, opts ...Option) *VendorService {
+	t.Helper()
+	log := logger.New(logger.Config{Level: "error", Environment: "test", ServiceName: "be-ap-vendors-test"})
+	return NewVendorService(memory.New(), log, opts...)
+}
+
+func strPtr(s string) *string { return &s }
+
+func int64Ptr(n int64) *int64 { return &n }
+
+// newCreateRequest returns a valid create request for vendor code
+func newCreateRequest(code string) *CreateVendorRequest {
+	return &CreateVendorRequest{
+		EntityID:     testEntityID,
+		VendorCode:   code,
+		VendorName:   "Northwind Traders",
+		VendorType:   "supplier",
+		Country:      "US",
+		PaymentTerms: "NET30",
+		Currency:     "USD",
+		Email:        strPtr("ap@northwind.com"),
+	}
+}
+
+// createVendor creates a vendor with code, failing the test on error
+func createVendor(t *testing.T, svc *VendorService, code string) *repository.Vendor {
+	t.Helper()
+	vendor, err := svc.CreateVendor(t.Context(), newCreateRequest(code))
+	if err != nil {
+		t.Fatalf("CreateVendor(%q) error = %v", code, err)
+	}
+	return vendor
+}
+
+// violationFields returns the fields named by a validation error, or fails the
+// test when err is no validation error
+func violationFields(t *testing.T, err error) []string {
+	t.Helper()
+	var validationErr *ValidationError
+	if !stderrors.As(err, &validationErr) {
+		t.Fatalf("error = %v, want a validation error", err)
+	}
+	fields := make([]string, len(validationErr.Violations))
+	for i, v := range validationErr.Violations {
+		fields[i] = v.Field
+	}
+	return fields
+}
+
+func TestCreateVendor(t *testing.T) {
+	svc := newTestService(t)
+
+	req := newCreateRequest(" nw-001 ")
+	req.Currency = "usd"
+	req.Country = "us"
+	req.VendorType = "Supplier"
+	vendor, err := svc.CreateVendor(t.Context(), req)
+	if err != nil {
+		t.Fatalf("CreateVendor() error = %v", err)
+	}
+
+	if vendor.ID == "" {
+		t.Error("vendor has no ID")
+	}
+	if vendor.Status != "pending_approval" {
+		t.Errorf("status = %q, want pending_approval", vendor.Status)
+	}
+	if vendor.VendorCode != "NW-001" || vendor.Currency != "USD" || vendor.Country != "US" || vendor.VendorType != "supplier" {
+		t.Errorf("vendor = %s/%s/%s/%s, want the code, currency, country and type normalized",
+			vendor.VendorCode, vendor.Currency, vendor.Country, vendor.VendorType)
+	}
+
+	stored, err := svc.GetVendor(t.Context(), vendor.ID, testEntityID)
+	if err != nil {
+		t.Fatalf("GetVendor() error = %v", err)
+	}
+	if stored.VendorCode != "NW-001" {
+		t.Errorf("stored vendor code = %q, want NW-001", stored.VendorCode)
+	}
+}
+
+func TestCreateVendorValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(req *CreateVendorRequest)
+		want   []string
+	}{
+		{"currency", func(req *CreateVendorRequest) { req.Currency = "US" }, []string{"currency"}},
+		{"country", func(req *CreateVendorRequest) { req.Country = "United States" }, []string{"country"}},
+		{"credit limit", func(req *CreateVendorRequest) { req.CreditLimit = int64Ptr(-1) }, []string{"credit_limit"}},
+		{"vendor type", func(req *CreateVendorRequest) { req.VendorType = "wholesaler" }, []string{"vendor_type"}},
+		{
+			"contact",
+			func(req *CreateVendorRequest) {
+				req.Contacts = []*AddContactRequest{{ContactType: "boss", FirstName: "Ada", LastName: "Lovelace"}}
+			},
+			[]string{"contacts[0].contact_type"},
+		},
+		{
+			"every failure is reported",
+			func(req *CreateVendorRequest) {
+				req.Currency = "US"
+				req.CreditLimit = int64Ptr(-1)
+			},
+			[]string{"currency", "credit_limit"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newTestService(t)
+			req := newCreateRequest("NW-001")
+			tt.modify(req)
+
+			_, err := svc.CreateVendor(t.Context(), req)
+			got := violationFields(t, err)
+			for _, field := range tt.want {
+				if !slices.Contains(got, field) {
+					t.Errorf("violations = %v, want %s", got, field)
+				}
+			}
+
+			// Nothing is written when validation fails
+			if _, err := svc.GetVendorByCode(t.Context(), "NW-001", testEntityID); !isNotFound(err) {
+				t.Errorf("GetVendorByCode() error = %v, want not found", err)
+			}
+		})
+	}
+}
+
+func TestCreateVendorDuplicateCode(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+	}{
+		{"same code", "NW-001"},
+		{"other case", "nw-001"},
+		{"surrounding spaces", " NW-001 "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newTestService(t)
+			createVendor(t, svc, "NW-001")
+
+			_, err := svc.CreateVendor(t.Context(), newCreateRequest(tt.code))
+			if !isAlreadyExists(err) {
+				t.Errorf("CreateVendor(%q) error = %v, want already exists", tt.code, err)
+			}
+		})
+	}
+
+	// Codes are unique per entity only
+	svc := newTestService(t)
+	createVendor(t, svc, "NW-001")
+	req := newCreateRequest("NW-001")
+	req.EntityID = "22222222-2222-4222-8222-222222222222"
+	if _, err := svc.CreateVendor(t.Context(), req); err != nil {
+		t.Errorf("CreateVendor() in another entity error = %v", err)
+	}
+}
+
+func TestVendorStatusTransitions(t *testing.T) {
+	activate := func(svc *VendorService, v *repository.Vendor) (*repository.Vendor, error) {
+		return svc.ActivateVendor(t.Context(), v.ID, testEntityID, "")
+	}
+	deactivate := func(svc *VendorService, v *repository.Vendor) (*repository.Vendor, error) {
+		return svc.DeactivateVendor(t.Context(), v.ID, testEntityID, "")
+	}
+	suspend := func(svc *VendorService, v *repository.Vendor) (*repository.Vendor, error) {
+		return svc.SuspendVendor(t.Context(), v.ID, testEntityID, "")
+	}
+	type transition func(svc *VendorService, v *repository.Vendor) (*repository.Vendor, error)
+
+	tests := []struct {
+		name  string
+		steps []transition
+		want  string
+	}{
+		{"created", nil, "pending_approval"},
+		{"activated", []transition{activate}, "active"},
+		{"deactivated", []transition{activate, deactivate}, "inactive"},
+		{"suspended", []transition{activate, suspend}, "suspended"},
+		{"suspended while pending", []transition{suspend}, "suspended"},
+		{"reactivated", []transition{activate, suspend, activate}, "active"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newTestService(t)
+			vendor := createVendor(t, svc, "NW-001")
+
+			for i, step := range tt.steps {
+				var err error
+				if vendor, err = step(svc, vendor); err != nil {
+					t.Fatalf("step %d error = %v", i, err)
+				}
+			}
+			if vendor.Status != tt.want {
+				t.Errorf("status = %q, want %q", vendor.Status, tt.want)
+			}
+
+			stored, err := svc.GetVendor(t.Context(), vendor.ID, testEntityID)
+			if err != nil {
+				t.Fatalf("GetVendor() error = %v", err)
+			}
+			if stored.Status != tt.want {
+				t.Errorf("stored status = %q, want %q", stored.Status, tt.want)
+			}
+		})
+	}
+}
+
+func TestActivateVendorNeedsApprovals(t *testing.T) {
+	svc := newTestService(t)
+	if err := svc.SetApprovalPolicy(t.Context(), &repository.ApprovalPolicy{EntityID: testEntityID, RequiredApprovals: 2}); err != nil {
+		t.Fatalf("SetApprovalPolicy() error = %v", err)
+	}
+	vendor := createVendor(t, svc, "NW-001")
+
+	_, err := svc.ActivateVendor(t.Context(), vendor.ID, testEntityID, "")
+	if got := violationFields(t, err); !slices.Equal(got, []string{"status"}) {
+		t.Errorf("violations = %v, want status", got)
+	}
+
+	stored, err := svc.GetVendor(t.Context(), vendor.ID, testEntityID)
+	if err != nil {
+		t.Fatalf("GetVendor() error = %v", err)
+	}
+	if stored.Status != "pending_approval" {
+		t.Errorf("status = %q, want pending_approval", stored.Status)
+	}
+}
+
+func TestValidateVendor(t *testing.T) {
+	tests := []struct {
+		name            string
+		activate        bool
+		creditLimit     *int64
+		balance         int64
+		invoiceCurrency string
+		want            bool
+	}{
+		{"pending approval", false, nil, 0, "", false},
+		{"active", true, nil, 0, "", true},
+		{"within credit limit", true, int64Ptr(1000), 999, "", true},
+		{"at credit limit", true, int64Ptr(1000), 1000, "", false},
+		{"over credit limit", true, int64Ptr(1000), 1001, "", false},
+		{"invoice in the vendor currency", true, nil, 0, "usd", true},
+		{"invoice in another currency", true, nil, 0, "EUR", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newTestService(t)
+			req := newCreateRequest("NW-001")
+			req.CreditLimit = tt.creditLimit
+			vendor, err := svc.CreateVendor(t.Context(), req)
+			if err != nil {
+				t.Fatalf("CreateVendor() error = %v", err)
+			}
+			if tt.activate {
+				if _, err := svc.ActivateVendor(t.Context(), vendor.ID, testEntityID, ""); err != nil {
+					t.Fatalf("ActivateVendor() error = %v", err)
+				}
+			}
+			if tt.balance != 0 {
+				if err := svc.UpdateBalance(t.Context(), vendor.ID, testEntityID, tt.balance); err != nil {
+					t.Fatalf("UpdateBalance() error = %v", err)
+				}
+			}
+
+			got, err := svc.ValidateVendor(t.Context(), vendor.ID, testEntityID, tt.invoiceCurrency)
+			if err != nil {
+				t.Fatalf("ValidateVendor() error = %v", err)
+			}
+			if got.Valid != tt.want {
+				t.Errorf("valid = %v (%s), want %v", got.Valid, got.Message, tt.want)
+			}
+			if !got.Valid && got.Message == "" {
+				t.Error("an invalid vendor has no message")
+			}
+		})
+	}
+}
+
+func TestValidateVendorUnknown(t *testing.T) {
+	svc := newTestService(t)
+	if _, err := svc.ValidateVendor(t.Context(), "33333333-3333-4333-8333-333333333333", testEntityID, ""); !isNotFound(err) {
+		t.Errorf("ValidateVendor() error = %v, want not found", err)
+	}
+}
+
+// codeLookupStore counts the vendor code lookups made outside transactions
+type codeLookupStore struct {
+	repository.Store
+	lookups int
+}
+
+func (s *codeLookupStore) GetByCode(ctx context.Context, code, entityID string) (*repository.Vendor, error) {
+	s.lookups++
+	return s.Store.GetByCode(ctx, code, entityID)
+}
+
+// newUpdateRequest returns an update request keeping every field of vendor
+func newUpdateRequest(vendor *repository.Vendor) *UpdateVendorRequest {
+	return &UpdateVendorRequest{
+		ID:                  vendor.ID,
+		EntityID:            vendor.EntityID,
+		VendorCode:          vendor.VendorCode,
+		VendorName:          vendor.VendorName,
+		LegalName:           vendor.LegalName,
+		DoingBusinessAs:     vendor.DoingBusinessAs,
+		VendorType:          vendor.VendorType,
+		TaxID:               vendor.TaxID,
+		IsTaxExempt:         vendor.IsTaxExempt,
+		Is1099Vendor:        vendor.Is1099Vendor,
+		Email:               vendor.Email,
+		RemittanceEmail:     vendor.RemittanceEmail,
+		Phone:               vendor.Phone,
+		Fax:                 vendor.Fax,
+		Website:             vendor.Website,
+		AddressLine1:        vendor.AddressLine1,
+		AddressLine2:        vendor.AddressLine2,
+		City:                vendor.City,
+		StateProvince:       vendor.StateProvince,
+		PostalCode:          vendor.PostalCode,
+		Country:             vendor.Country,
+		Locale:              vendor.Locale,
+		PaymentTerms:        vendor.PaymentTerms,
+		PaymentMethod:       vendor.PaymentMethod,
+		Currency:            vendor.Currency,
+		AcceptedCurrencies:  vendor.AcceptedCurrencies,
+		CreditLimit:         vendor.CreditLimit,
+		BankName:            vendor.BankName,
+		BankAccountNumber:   vendor.BankAccountNumber,
+		BankRoutingNumber:   vendor.BankRoutingNumber,
+		SwiftCode:           vendor.SwiftCode,
+		IBAN:                vendor.IBAN,
+		Notes:               vendor.Notes,
+		Tags:                vendor.Tags,
+		CreditLimitCurrency: vendor.CreditLimitCurrency,
+	}
+}
+
+func TestUpdateVendorCodeCase(t *testing.T) {
+	tests := []struct {
+		name        string
+		code        string
+		want        string
+		wantRename  bool
+		wantLookups int
+	}{
+		{"same code", "NW-001", "NW-001", false, 0},
+		{"lowercase", "nw-001", "NW-001", false, 0},
+		{"mixed case", "Nw-001", "NW-001", false, 0},
+		{"surrounding spaces", " nw-001 ", "NW-001", false, 0},
+		{"rename", "nw-002", "NW-002", true, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := newTestService(t)
+			vendor := createVendor(t, svc, "NW-001")
+			store := &codeLookupStore{Store: svc.vendorRepo}
+			svc.vendorRepo = store
+
+			req := newUpdateRequest(vendor)
+			req.VendorCode = tt.code
+			updated, err := svc.UpdateVendor(t.Context(), req)
+			if err != nil {
+				t.Fatalf("UpdateVendor(%q) error = %v", tt.code, err)
+			}
+			if updated.VendorCode != tt.want {
+				t.Errorf("vendor code = %q, want %q", updated.VendorCode, tt.want)
+			}
+			if store.lookups != tt.wantLookups {
+				t.Errorf("code lookups = %d, want %d", store.lookups, tt.wantLookups)
+			}
+
+			entries, err := svc.vendorRepo.ListVendorAuditEntries(t.Context(), vendor.ID, testEntityID, 100)
+			if err != nil {
+				t.Fatalf("ListVendorAuditEntries() error = %v", err)
+			}
+			renamed := false
+			for _, entry := range entries {
+				if entry.Action != AuditActionVendorUpdated {
+					continue
+				}
+				if !tt.wantRename {
+					t.Errorf("audit entry %v for an update changing nothing", entry.Details)
+				}
+				if changed, _ := entry.Details["changed_fields"].([]string); slices.Contains(changed, "vendor_code") {
+					renamed = true
+				}
+			}
+			if renamed != tt.wantRename {
+				t.Errorf("vendor_code audited as changed = %v, want %v", renamed, tt.wantRename)
+			}
+		})
+	}
+}
+
+func TestUpdateVendorCodeConflict(t *testing.T) {
+	svc := newTestService(t)
+	vendor := createVendor(t, svc, "NW-001")
+	createVendor(t, svc, "NW-002")
+
+	req := newUpdateRequest(vendor)
+	req.VendorCode = "nw-002"
+	if _, err := svc.UpdateVendor(t.Context(), req); !isAlreadyExists(err) {
+		t.Errorf("UpdateVendor(nw-002) error = %v, want already exists", err)
+	}
+}
+
+func TestSetApprovalPolicyTouchesAffectedVendors(t *testing.T) {
+	svc := newTestService(t)
+	small := createVendor(t, svc, "NW-001")
+	large := createVendor(t, svc, "NW-002")
+	req := newUpdateRequest(large)
+	req.CreditLimit = int64Ptr(5_000_000)
+	if _, err := svc.UpdateVendor(t.Context(), req); err != nil {
+		t.Fatalf("UpdateVendor() error = %v", err)
+	}
+
+	changeSeqs := func() (int64, int64) {
+		t.Helper()
+		var seqs [2]int64
+		for i, id := range []string{small.ID, large.ID} {
+			vendor, err := svc.GetVendor(t.Context(), id, testEntityID)
+			if err != nil {
+				t.Fatalf("GetVendor() error = %v", err)
+			}
+			if vendor.Status != "pending_approval" {
+				t.Fatalf("status = %q, want pending_approval", vendor.Status)
+			}
+			seqs[i] = vendor.ChangeSeq
+		}
+		return seqs[0], seqs[1]
+	}
+
+	threshold := &repository.ApprovalPolicy{EntityID: testEntityID, RequiredApprovals: 2, CreditLimitThreshold: int64Ptr(1_000_000)}
+	tests := []struct {
+		name      string
+		policy    *repository.ApprovalPolicy
+		wantSmall bool
+		wantLarge bool
+	}{
+		{"default policy saved", &repository.ApprovalPolicy{EntityID: testEntityID, RequiredApprovals: 1}, false, false},
+		{"threshold below the large vendor", threshold, false, true},
+		{"same policy again", threshold, false, false},
+		{"threshold above both vendors", &repository.ApprovalPolicy{EntityID: testEntityID, RequiredApprovals: 2, CreditLimitThreshold: int64Ptr(9_000_000)}, false, true},
+		{"every vendor", &repository.ApprovalPolicy{EntityID: testEntityID, RequiredApprovals: 3}, true, true},
+	}
+
+	for _, tt := range tests {
+		smallBefore, largeBefore := changeSeqs()
+		policy := *tt.policy
+		if err := svc.SetApprovalPolicy(t.Context(), &policy); err != nil {
+			t.Fatalf("%s: SetApprovalPolicy() error = %v", tt.name, err)
+		}
+		smallAfter, largeAfter := changeSeqs()
+		if got := smallAfter != smallBefore; got != tt.wantSmall {
+			t.Errorf("%s: small vendor touched = %v, want %v", tt.name, got, tt.wantSmall)
+		}
+		if got := largeAfter != largeBefore; got != tt.wantLarge {
+			t.Errorf("%s: large vendor touched = %v, want %v", tt.name, got, tt.wantLarge)
+		}
+	}
+}
+
+func TestDeactivatePaymentTermWithReplacement(t *testing.T) {
+	// Without the entity settings cache every writable check reads the store
+	svc := newTestService(t, WithEntitySettingsCacheTTL(0))
+	vendors := []*repository.Vendor{createVendor(t, svc, "NW-001"), createVendor(t, svc, "NW-002")}
+
+	result, err := svc.DeactivatePaymentTerm(t.Context(), "net30", "NET60", false, strPtr("admin"))
+	if err != nil {
+		t.Fatalf("DeactivatePaymentTerm() error = %v", err)
+	}
+	if result.Code != "NET30" || result.ReplacementCode != "NET60" || result.VendorsReassigned != 2 {
+		t.Errorf("DeactivatePaymentTerm() = %+v, want 2 vendors moved from NET30 to NET60", result)
+	}
+
+	for _, vendor := range vendors {
+		got, err := svc.GetVendor(t.Context(), vendor.ID, testEntityID)
+		if err != nil {
+			t.Fatalf("GetVendor() error = %v", err)
+		}
+		if got.PaymentTerms != "NET60" {
+			t.Errorf("%s payment terms = %q, want NET60", got.VendorCode, got.PaymentTerms)
+		}
+	}
+	usage, err := svc.GetPaymentTermUsage(t.Context(), "NET30")
+	if err != nil {
+		t.Fatalf("GetPaymentTermUsage() error = %v", err)
+	}
+	if usage.IsActive || usage.Vendors != 0 {
+		t.Errorf("NET30 usage = %+v, want inactive and unused", usage)
+	}
+}
+
+// staticMatcher answers every TIN matching request with status
+type staticMatcher struct {
+	status string
+}
+
+func (m *staticMatcher) Match(ctx context.Context, reqs []tinmatch.Request) ([]tinmatch.Result, error) {
+	results := make([]tinmatch.Result, len(reqs))
+	for i := range results {
+		results[i] = tinmatch.Result{Status: m.status}
+	}
+	return results, nil
+}
+
+func TestVerifyVendorTINTouchesOnChange(t *testing.T) {
+	matcher := &staticMatcher{status: tinmatch.StatusMatched}
+	svc := newTestService(t, WithTINMatcher(matcher))
+	req := newCreateRequest("NW-001")
+	req.TaxID = strPtr("12-3456789")
+	req.LegalName = strPtr("Northwind Traders LLC")
+	vendor, err := svc.CreateVendor(t.Context(), req)
+	if err != nil {
+		t.Fatalf("CreateVendor() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		status      string
+		wantTouched bool
+	}{
+		{"first result", tinmatch.StatusMatched, true},
+		{"same result", tinmatch.StatusMatched, false},
+		{"status changed", tinmatch.StatusMismatched, true},
+	}
+
+	changeSeq := vendor.ChangeSeq
+	for _, tt := range tests {
+		matcher.status = tt.status
+		if _, err := svc.VerifyVendorTIN(t.Context(), vendor.ID, testEntityID); err != nil {
+			t.Fatalf("%s: VerifyVendorTIN() error = %v", tt.name, err)
+		}
+		got, err := svc.GetVendor(t.Context(), vendor.ID, testEntityID)
+		if err != nil {
+			t.Fatalf("GetVendor() error = %v", err)
+		}
+		if touched := got.ChangeSeq != changeSeq; touched != tt.wantTouched {
+			t.Errorf("%s: vendor touched = %v, want %v", tt.name, touched, tt.wantTouched)
+		}
+		changeSeq = got.ChangeSeq
+	}
+}
+
+func TestStartBankVerificationTouchesOnChange(t *testing.T) {
+	svc := newTestService(t)
+	req := newCreateRequest("NW-001")
+	req.BankAccountNumber = strPtr("000123456789")
+	req.BankRoutingNumber = strPtr("021000021")
+	vendor, err := svc.CreateVendor(t.Context(), req)
+	if err != nil {
+		t.Fatalf("CreateVendor() error = %v", err)
+	}
+
+	// The default verifier leaves every account unverified
+	tests := []struct {
+		name        string
+		method      string
+		wantTouched bool
+	}{
+		{"first verification", bankverify.MethodMicroDeposits, true},
+		{"restarted with the same outcome", bankverify.MethodMicroDeposits, false},
+		{"method changed", bankverify.MethodInstant, true},
+	}
+
+	changeSeq := vendor.ChangeSeq
+	for _, tt := range tests {
+		if _, err := svc.StartBankVerification(t.Context(), vendor.ID, testEntityID, tt.method); err != nil {
+			t.Fatalf("%s: StartBankVerification() error = %v", tt.name, err)
+		}
+		got, err := svc.GetVendor(t.Context(), vendor.ID, testEntityID)
+		if err != nil {
+			t.Fatalf("GetVendor() error = %v", err)
+		}
+		if touched := got.ChangeSeq != changeSeq; touched != tt.wantTouched {
+			t.Errorf("%s: vendor touched = %v, want %v", tt.name, touched, tt.wantTouched)
+		}
+		changeSeq = got.ChangeSeq
+	}
+}
+
+func TestMarkDormantVendorsIgnoresTouches(t *testing.T) {
+	store := memory.New()
+	log := logger.New(logger.Config{Level: "error", Environment: "test", ServiceName: "be-ap-vendors-test"})
+	svc := NewVendorService(store, log, WithTINMatcher(&staticMatcher{status: tinmatch.StatusMatched}))
+	req := newCreateRequest("NW-001")
+	req.TaxID = strPtr("12-3456789")
+	req.LegalName = strPtr("Northwind Traders LLC")
+	vendor, err := svc.CreateVendor(t.Context(), req)
+	if err != nil {
+		t.Fatalf("CreateVendor() error = %v", err)
+	}
+	cutoff := time.Now().UTC()
+
+	markDormant := func() *repository.DormancyBatchResult {
+		t.Helper()
+		result, err := store.MarkDormantVendors(t.Context(), repository.DormancyBatch{EntityID: testEntityID, Cutoff: cutoff, Limit: 10})
+		if err != nil {
+			t.Fatalf("MarkDormantVendors() error = %v", err)
+		}
+		return result
+	}
+
+	// A recorded TIN match advances change_seq, but is no activity
+	if _, err := svc.VerifyVendorTIN(t.Context(), vendor.ID, testEntityID); err != nil {
+		t.Fatalf("VerifyVendorTIN() error = %v", err)
+	}
+	if result := markDormant(); result.Dormant != 1 || len(result.Flagged) != 1 {
+		t.Fatalf("after a touch: dormant = %d, flagged = %d, want the vendor flagged", result.Dormant, len(result.Flagged))
+	}
+
+	updated, err := svc.GetVendor(t.Context(), vendor.ID, testEntityID)
+	if err != nil {
+		t.Fatalf("GetVendor() error = %v", err)
+	}
+	update := newUpdateRequest(updated)
+	update.VendorName = "Northwind Traders International"
+	if _, err := svc.UpdateVendor(t.Context(), update); err != nil {
+		t.Fatalf("UpdateVendor() error = %v", err)
+	}
+	if result := markDormant(); result.Dormant != 0 || result.Cleared != 1 {
+		t.Errorf("after an update: dormant = %d, cleared = %d, want the vendor unflagged", result.Dormant, result.Cleared)
+	}
+}
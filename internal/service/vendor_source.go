@@ -0,0 +1,70 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sources of vendors: the creation path that added them. Vendors created
+// before sources were recorded have VendorSourceUnknown.
+const (
+	VendorSourceUI      = "ui"
+	VendorSourceAPI     = "api"
+	VendorSourceImport  = "import"
+	VendorSourcePortal  = "portal"
+	VendorSourceERPSync = "erp_sync"
+	VendorSourceSeed    = "seed"
+	VendorSourceUnknown = "unknown"
+)
+
+// maxSourceReferenceLength is the length of vendors.source_reference
+const maxSourceReferenceLength = 255
+
+// IsValidVendorSource reports whether source is a vendor source, for list
+// filters
+func IsValidVendorSource(source string) bool {
+	switch source {
+	case VendorSourceUI, VendorSourceAPI, VendorSourceImport, VendorSourcePortal,
+		VendorSourceERPSync, VendorSourceSeed, VendorSourceUnknown:
+		return true
+	}
+	return false
+}
+
+// isDeclarableVendorSource reports whether callers may name source in a create
+// or upsert request. The web app and seed sources are set only by their own
+// paths, so that no integration can pass its vendors off as entered by hand.
+func isDeclarableVendorSource(source string) bool {
+	switch source {
+	case VendorSourceAPI, VendorSourceImport, VendorSourcePortal, VendorSourceERPSync:
+		return true
+	}
+	return false
+}
+
+// vendorSource returns the source and source reference to record for a new
+// vendor: the source declared in the request, or the source of the creation
+// path when none is. Declaring the path's own source is allowed.
+func vendorSource(v *validator, declared, pathSource string, reference *string) (string, *string) {
+	source := strings.ToLower(strings.TrimSpace(declared))
+	if source == "" || source == pathSource {
+		source = pathSource
+	} else if !isDeclarableVendorSource(source) {
+		v.add("source", fmt.Sprintf("source must be %s, %s, %s or %s, got %q",
+			VendorSourceAPI, VendorSourceImport, VendorSourcePortal, VendorSourceERPSync, declared))
+	}
+	if source == "" {
+		source = VendorSourceUnknown
+	}
+
+	if reference == nil {
+		return source, nil
+	}
+	ref := strings.TrimSpace(*reference)
+	if ref == "" {
+		return source, nil
+	}
+	v.check(len(ref) <= maxSourceReferenceLength, "source_reference",
+		fmt.Sprintf("source_reference must be at most %d characters", maxSourceReferenceLength))
+	return source, &ref
+}
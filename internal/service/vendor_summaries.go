@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+// VendorSummaryPage is a page of vendor summaries, with its total computed as
+// for VendorPage
+type VendorSummaryPage struct {
+	Vendors   []*repository.VendorSummary `json:"vendors"`
+	Total     int64                       `json:"total"`
+	TotalMode string                      `json:"total_mode"`
+}
+
+// ListVendorSummaries retrieves a page of the lightweight vendor summaries
+// shown by pickers and typeaheads, filtered, searched and ordered as
+// ListVendors
+func (s *VendorService) ListVendorSummaries(ctx context.Context, filter repository.VendorFilter, page, pageSize int) (*VendorSummaryPage, error) {
+	mode, err := s.resolveTotalMode(ctx, filter.EntityID, filter.TotalMode)
+	if err != nil {
+		return nil, err
+	}
+	filter.TotalMode = mode
+
+	offset := (page - 1) * pageSize
+	summaries, total, err := s.vendorRepo.ListSummaries(ctx, filter, pageSize, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &VendorSummaryPage{Vendors: summaries, Total: total, TotalMode: mode}
+	if mode == repository.TotalEstimate {
+		result.Total, result.TotalMode = settleEstimate(total, len(summaries), offset, pageSize)
+	}
+	return result, nil
+}
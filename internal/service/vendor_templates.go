@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/address"
+	"github.com/pesio-ai/be-ap-vendors/internal/locale"
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// maxVendorTemplateNameLength matches vendor_templates.name
+const maxVendorTemplateNameLength = 100
+
+// paymentMethods mirrors the payment_method enum of migrations/001_initial_schema.sql
+var paymentMethods = []string{"check", "ach", "wire", "credit_card", "cash"}
+
+// validateVendorTemplate normalizes and validates a vendor template. Defaults
+// are held to the rules of CreateVendor, so that a template can never supply
+// a value a create would reject; violations are reported as defaults.<field>.
+func (s *VendorService) validateVendorTemplate(ctx context.Context, t *repository.VendorTemplate) error {
+	t.Name = strings.TrimSpace(t.Name)
+
+	v := &validator{}
+	v.check(t.EntityID != "", "entity_id", "entity_id is required")
+	v.check(t.Name != "", "name", "name is required")
+	v.check(len(t.Name) <= maxVendorTemplateNameLength, "name",
+		fmt.Sprintf("name must be at most %d characters", maxVendorTemplateNameLength))
+
+	d := &t.Defaults
+	check := &validator{}
+	if d.VendorType != nil {
+		vendorType := strings.ToLower(*d.VendorType)
+		if err := s.checkVendorType(ctx, check, t.EntityID, vendorType, ""); err != nil {
+			return err
+		}
+		d.VendorType = &vendorType
+	}
+	if d.Currency != nil {
+		currency := strings.ToUpper(*d.Currency)
+		check.check(isCurrencyCode(currency), "currency", "currency must be 3-letter ISO code")
+		d.Currency = &currency
+	}
+	d.AcceptedCurrencies = normalizeCurrencies(check, d.AcceptedCurrencies)
+	if d.Currency != nil {
+		checkAcceptedCurrencies(check, *d.Currency, d.AcceptedCurrencies)
+	}
+	if d.Country != nil {
+		country := address.NormalizeCountry(*d.Country)
+		check.check(len(country) == 2, "country", "country must be 2-letter ISO code")
+		d.Country = &country
+	}
+	if d.Locale != nil {
+		if tag, err := locale.Parse(*d.Locale); err != nil {
+			check.add("locale", err.Error())
+		} else {
+			d.Locale = &tag
+		}
+	}
+	if d.PaymentTerms != nil {
+		check.check(*d.PaymentTerms != "", "payment_terms", "payment_terms cannot be empty")
+		checkLength(check, "payment_terms", *d.PaymentTerms, 50)
+	}
+	if d.PaymentMethod != nil {
+		check.check(slices.Contains(paymentMethods, *d.PaymentMethod), "payment_method",
+			fmt.Sprintf("payment_method must be one of %s", strings.Join(paymentMethods, ", ")))
+	}
+	check.check(d.CreditLimit == nil || *d.CreditLimit >= 0, "credit_limit", "credit limit cannot be negative")
+	checkOptionalLength(check, "notes", d.Notes, maxNotesLength)
+	d.Tags = normalizeTags(check, "tags", d.Tags)
+
+	for _, violation := range check.violations {
+		v.add("defaults."+violation.Field, violation.Message)
+	}
+	return v.err()
+}
+
+// ListVendorTemplates retrieves the vendor templates of an entity
+func (s *VendorService) ListVendorTemplates(ctx context.Context, entityID string) ([]*repository.VendorTemplate, error) {
+	if entityID == "" {
+		return nil, errors.InvalidInput("entity_id", "entity_id is required")
+	}
+	return s.vendorRepo.ListVendorTemplates(ctx, entityID)
+}
+
+// GetVendorTemplate retrieves a vendor template of an entity
+func (s *VendorService) GetVendorTemplate(ctx context.Context, id, entityID string) (*repository.VendorTemplate, error) {
+	return s.vendorRepo.GetVendorTemplate(ctx, id, entityID)
+}
+
+// CreateVendorTemplate stores named create defaults of an entity
+func (s *VendorService) CreateVendorTemplate(ctx context.Context, t *repository.VendorTemplate) error {
+	if err := s.validateVendorTemplate(ctx, t); err != nil {
+		return err
+	}
+
+	if err := s.vendorRepo.CreateVendorTemplate(ctx, t); err != nil {
+		return err
+	}
+
+	reqlog.SetEntity(ctx, t.EntityID)
+	s.logger(ctx).Info().Str("template_id", t.ID).Str("name", t.Name).Msg("Vendor template created")
+
+	return nil
+}
+
+// UpdateVendorTemplate replaces the name and defaults of a vendor template.
+// Vendors already created from it are not changed.
+func (s *VendorService) UpdateVendorTemplate(ctx context.Context, t *repository.VendorTemplate) error {
+	if err := s.validateVendorTemplate(ctx, t); err != nil {
+		return err
+	}
+
+	if err := s.vendorRepo.UpdateVendorTemplate(ctx, t); err != nil {
+		return err
+	}
+
+	reqlog.SetEntity(ctx, t.EntityID)
+	s.logger(ctx).Info().Str("template_id", t.ID).Str("name", t.Name).Msg("Vendor template updated")
+
+	return nil
+}
+
+// DeleteVendorTemplate removes a vendor template of an entity
+func (s *VendorService) DeleteVendorTemplate(ctx context.Context, id, entityID string) error {
+	if err := s.vendorRepo.DeleteVendorTemplate(ctx, id, entityID); err != nil {
+		return err
+	}
+
+	reqlog.SetEntity(ctx, entityID)
+	s.logger(ctx).Info().Str("template_id", id).Msg("Vendor template deleted")
+
+	return nil
+}
+
+// applyVendorTemplate returns a copy of req with the defaults of its template
+// filled in where req leaves a field empty. Unknown templates are reported as
+// violations of template_id and req is returned unchanged.
+func (s *VendorService) applyVendorTemplate(ctx context.Context, v *validator, req *CreateVendorRequest) (*CreateVendorRequest, error) {
+	t, err := s.vendorRepo.GetVendorTemplate(ctx, req.TemplateID, req.EntityID)
+	if isNotFound(err) {
+		v.add("template_id", fmt.Sprintf("no vendor template %s", req.TemplateID))
+		return req, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	merged := *req
+	d := t.Defaults
+	if merged.VendorType == "" && d.VendorType != nil {
+		merged.VendorType = *d.VendorType
+	}
+	if merged.Country == "" && d.Country != nil {
+		merged.Country = *d.Country
+	}
+	if merged.Locale == nil {
+		merged.Locale = d.Locale
+	}
+	if merged.PaymentTerms == "" && d.PaymentTerms != nil {
+		merged.PaymentTerms = *d.PaymentTerms
+	}
+	if merged.PaymentMethod == nil {
+		merged.PaymentMethod = d.PaymentMethod
+	}
+	if merged.Currency == "" && d.Currency != nil {
+		merged.Currency = *d.Currency
+	}
+	if len(merged.AcceptedCurrencies) == 0 {
+		merged.AcceptedCurrencies = d.AcceptedCurrencies
+	}
+	if merged.CreditLimit == nil {
+		merged.CreditLimit = d.CreditLimit
+	}
+	if merged.Notes == nil {
+		merged.Notes = d.Notes
+	}
+	if len(merged.Tags) == 0 {
+		merged.Tags = d.Tags
+	}
+	return &merged, nil
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-ap-vendors/internal/reqlog"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// vendorTypeCodePattern restricts vendor type codes to lowercase identifiers
+var vendorTypeCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// VendorTypeInUseError is returned when deleting a vendor type that vendors still use
+type VendorTypeInUseError struct {
+	Code    string
+	Vendors int64
+}
+
+func (e *VendorTypeInUseError) Error() string {
+	return fmt.Sprintf("vendor type %s is used by %d vendors; deprecate it instead", e.Code, e.Vendors)
+}
+
+// checkVendorType validates vendorType against the registry of the entity.
+// Deprecated types are only accepted when equal to current, so existing
+// vendors keep working while new ones cannot pick them.
+func (s *VendorService) checkVendorType(ctx context.Context, v *validator, entityID, vendorType, current string) error {
+	types, err := s.vendorRepo.ListVendorTypes(ctx, entityID)
+	if err != nil {
+		return err
+	}
+
+	valid := make([]string, 0, len(types))
+	for _, vt := range types {
+		if !vt.IsDeprecated {
+			valid = append(valid, vt.Code)
+		}
+		if vt.Code != vendorType {
+			continue
+		}
+		v.check(!vt.IsDeprecated || vendorType == current, "vendor_type", "vendor type is deprecated")
+		return nil
+	}
+
+	v.add("vendor_type", fmt.Sprintf("invalid vendor type %q, valid types: %s", vendorType, strings.Join(valid, ", ")))
+	return nil
+}
+
+// ListVendorTypes retrieves the vendor types of an entity
+func (s *VendorService) ListVendorTypes(ctx context.Context, entityID string, includeDeprecated bool) ([]*repository.VendorType, error) {
+	types, err := s.vendorRepo.ListVendorTypes(ctx, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if includeDeprecated {
+		return types, nil
+	}
+
+	active := make([]*repository.VendorType, 0, len(types))
+	for _, vt := range types {
+		if !vt.IsDeprecated {
+			active = append(active, vt)
+		}
+	}
+	return active, nil
+}
+
+// validateVendorType normalizes and validates a vendor type registry entry
+func validateVendorType(vt *repository.VendorType) error {
+	vt.Code = strings.ToLower(strings.TrimSpace(vt.Code))
+	vt.Label = strings.TrimSpace(vt.Label)
+
+	v := &validator{}
+	v.check(vt.EntityID != "", "entity_id", "entity_id is required")
+	v.check(vendorTypeCodePattern.MatchString(vt.Code), "code",
+		"code must start with a letter and contain only lowercase letters, digits and underscores (max 50)")
+	v.check(vt.Label != "" && len(vt.Label) <= 100, "label", "label must be between 1 and 100 characters")
+	return v.err()
+}
+
+// CreateVendorType adds a vendor type to the registry of an entity
+func (s *VendorService) CreateVendorType(ctx context.Context, vt *repository.VendorType) error {
+	if err := validateVendorType(vt); err != nil {
+		return err
+	}
+
+	if err := s.vendorRepo.CreateVendorType(ctx, vt); err != nil {
+		return err
+	}
+
+	reqlog.SetEntity(ctx, vt.EntityID)
+	s.logger(ctx).Info().Str("vendor_type", vt.Code).Msg("Vendor type created")
+
+	return nil
+}
+
+// UpdateVendorType changes the label and deprecation of a vendor type
+func (s *VendorService) UpdateVendorType(ctx context.Context, vt *repository.VendorType) error {
+	if err := validateVendorType(vt); err != nil {
+		return err
+	}
+
+	if err := s.vendorRepo.UpdateVendorType(ctx, vt); err != nil {
+		return err
+	}
+
+	reqlog.SetEntity(ctx, vt.EntityID)
+	s.logger(ctx).Info().
+		Str("vendor_type", vt.Code).
+		Bool("is_deprecated", vt.IsDeprecated).
+		Msg("Vendor type updated")
+
+	return nil
+}
+
+// DeleteVendorType removes a vendor type from the registry of an entity. Types
+// used by any non-deleted vendor cannot be removed and should be deprecated.
+func (s *VendorService) DeleteVendorType(ctx context.Context, entityID, code string) error {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if entityID == "" {
+		return errors.InvalidInput("entity_id", "entity_id is required")
+	}
+	if code == "" {
+		return errors.InvalidInput("code", "code is required")
+	}
+
+	err := s.vendorRepo.WithTx(ctx, func(repo repository.Store) error {
+		count, err := repo.CountVendorsByType(ctx, entityID, code)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return &VendorTypeInUseError{Code: code, Vendors: count}
+		}
+		return repo.DeleteVendorType(ctx, entityID, code)
+	})
+	if err != nil {
+		return err
+	}
+
+	reqlog.SetEntity(ctx, entityID)
+	s.logger(ctx).Info().Str("vendor_type", code).Msg("Vendor type deleted")
+
+	return nil
+}
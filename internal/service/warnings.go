@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+)
+
+// Codes of the validation warnings returned by vendor writes
+const (
+	WarningNoContactMethod        = "no_contact_method"
+	WarningInvalidAddress         = "invalid_address"
+	WarningAddressUnverified      = "address_unverified"
+	WarningInvalidBankDetails     = "invalid_bank_details"
+	WarningBlocklisted            = "blocklisted"
+	WarningSimilarVendorName      = "similar_vendor_name"
+	WarningMissingRemittanceEmail = "missing_remittance_email"
+	WarningUnverifiedBankAccount  = "unverified_bank_account"
+	WarningIBANCountryMismatch    = "iban_country_mismatch"
+	WarningCountryProfile         = "country_profile"
+)
+
+const (
+	// similarNameThreshold is the name similarity from which another vendor
+	// is reported as similar
+	similarNameThreshold = 0.7
+	// maxSimilarNameWarnings caps the similar vendors reported
+	maxSimilarNameWarnings = 3
+)
+
+// setWarnings sets the validation warnings of a vendor, and their messages
+// for clients reading the plain warnings list
+func setWarnings(vendor *repository.Vendor, warnings []*repository.ValidationWarning) {
+	vendor.ValidationWarnings = warnings
+	vendor.Warnings = nil
+	for _, w := range warnings {
+		message := w.Message
+		if w.Field != "" {
+			message = w.Field + ": " + w.Message
+		}
+		vendor.Warnings = append(vendor.Warnings, message)
+	}
+}
+
+// savedVendorWarnings runs the checks that only warn about a vendor just
+// created (before is nil) or updated: a name similar to another vendor's, a
+// missing remittance email, an unverified bank account and an IBAN of another
+// country than the vendor's. A check that fails is logged and skipped, since
+// the vendor is already saved.
+func (s *VendorService) savedVendorWarnings(ctx context.Context, before, vendor *repository.Vendor) []*repository.ValidationWarning {
+	var warnings []*repository.ValidationWarning
+
+	if before == nil || !strings.EqualFold(before.VendorName, vendor.VendorName) {
+		candidates, err := s.vendorRepo.FindVendorMatchCandidates(repository.UsePrimary(ctx), repository.VendorMatchQuery{
+			EntityID:      vendor.EntityID,
+			Name:          vendor.VendorName,
+			MinSimilarity: similarNameThreshold,
+			Limit:         maxSimilarNameWarnings + 1,
+		})
+		if err != nil {
+			s.logger(ctx).Warn().Err(err).Str("vendor_id", vendor.ID).Msg("Failed to check for similar vendor names")
+		}
+		similar := 0
+		for _, c := range candidates {
+			if c.Vendor.ID == vendor.ID || c.NameSimilarity < similarNameThreshold || similar == maxSimilarNameWarnings {
+				continue
+			}
+			similar++
+			warnings = append(warnings, &repository.ValidationWarning{
+				Code:  WarningSimilarVendorName,
+				Field: "vendor_name",
+				Message: fmt.Sprintf("vendor name is similar to %q, the %s of vendor %s (%s)",
+					c.MatchedName, strings.ReplaceAll(c.MatchedOn, "_", " "), c.Vendor.VendorCode, c.Vendor.VendorName),
+			})
+		}
+	}
+
+	for _, message := range remittanceWarnings(vendor) {
+		warnings = append(warnings, &repository.ValidationWarning{Code: WarningMissingRemittanceEmail, Field: "remittance_email", Message: message})
+	}
+
+	// Verifications are loaded into a copy, so that the response does not
+	// include them
+	verified := *vendor
+	if err := s.attachBankVerifications(repository.UsePrimary(ctx), &verified); err != nil {
+		s.logger(ctx).Warn().Err(err).Str("vendor_id", vendor.ID).Msg("Failed to check the bank verification")
+	} else if issue := bankVerificationIssue(&verified); issue != "" {
+		warnings = append(warnings, &repository.ValidationWarning{Code: WarningUnverifiedBankAccount, Field: "bank_account_number", Message: issue})
+	}
+
+	if vendor.IBAN != nil {
+		iban := strings.ToUpper(strings.ReplaceAll(*vendor.IBAN, " ", ""))
+		if ibanIssue(iban) == "" && !strings.EqualFold(iban[:2], vendor.Country) {
+			warnings = append(warnings, &repository.ValidationWarning{
+				Code:    WarningIBANCountryMismatch,
+				Field:   "iban",
+				Message: fmt.Sprintf("IBAN is from %s but the vendor is in %s", iban[:2], vendor.Country),
+			})
+		}
+	}
+
+	return warnings
+}
+
+// logWarnings logs the validation warnings returned with a vendor
+func (s *VendorService) logWarnings(ctx context.Context, vendor *repository.Vendor) {
+	for _, w := range vendor.ValidationWarnings {
+		s.logger(ctx).Info().
+			Str("vendor_id", vendor.ID).
+			Str("code", w.Code).
+			Str("field", w.Field).
+			Str("message", w.Message).
+			Msg("Vendor saved with a validation warning")
+	}
+}
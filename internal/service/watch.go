@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pesio-ai/be-ap-vendors/internal/repository"
+	"github.com/pesio-ai/be-lib-common/errors"
+)
+
+// changeBroker fans vendor change notifications out to watchers of an entity.
+// Signals only wake watchers up; the change feed itself is always read from the
+// database, so dropped or coalesced signals never lose changes.
+type changeBroker struct {
+	mu   sync.Mutex
+	subs map[string]map[chan struct{}]struct{}
+}
+
+func newChangeBroker() *changeBroker {
+	return &changeBroker{subs: make(map[string]map[chan struct{}]struct{})}
+}
+
+// subscribe registers a watcher of an entity and returns its wake-up channel
+func (b *changeBroker) subscribe(entityID string) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	b.mu.Lock()
+	if b.subs[entityID] == nil {
+		b.subs[entityID] = make(map[chan struct{}]struct{})
+	}
+	b.subs[entityID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		delete(b.subs[entityID], ch)
+		if len(b.subs[entityID]) == 0 {
+			delete(b.subs, entityID)
+		}
+		b.mu.Unlock()
+	}
+}
+
+// notify wakes up all watchers of an entity
+func (b *changeBroker) notify(entityID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs[entityID] {
+		signal(ch)
+	}
+}
+
+// notifyAll wakes up every watcher, e.g. after notifications may have been missed
+func (b *changeBroker) notifyAll() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, subs := range b.subs {
+		for ch := range subs {
+			signal(ch)
+		}
+	}
+}
+
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+		// A wake-up is already pending
+	}
+}
+
+// RunChangeListener feeds committed vendor changes to watchers until ctx is
+// cancelled, reconnecting with backoff when the listener connection fails.
+// Without a running listener watchers fall back to polling on every heartbeat.
+func (s *VendorService) RunChangeListener(ctx context.Context, listener *repository.ChangeListener) {
+	backoff := time.Second
+	for {
+		err := listener.Listen(ctx,
+			func() {
+				backoff = time.Second
+				// Changes committed while disconnected were not notified
+				s.changes.notifyAll()
+				s.log.Info().Msg("Vendor change listener started")
+			},
+			func(entityID string, changeSeq int64) {
+				s.changes.notify(entityID)
+			},
+		)
+		if ctx.Err() != nil {
+			return
+		}
+
+		s.log.Error().Err(err).Dur("retry_in", backoff).Msg("Vendor change listener stopped")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+// WatchVendorChanges replays the changes of an entity after the since watermark
+// and then streams new changes as they are committed. emit is called for every
+// change in change_seq order and onHeartbeat every heartbeat interval with the
+// last delivered watermark. It returns when ctx is cancelled or a callback fails.
+func (s *VendorService) WatchVendorChanges(
+	ctx context.Context,
+	entityID string,
+	since int64,
+	heartbeat time.Duration,
+	emit func(change *VendorChange) error,
+	onHeartbeat func(watermark int64) error,
+) error {
+	if since < 0 {
+		return errors.InvalidInput("since", "since must be a non-negative watermark")
+	}
+
+	// Subscribe before replaying so changes committed during the replay wake us up
+	wake, unsubscribe := s.changes.subscribe(entityID)
+	defer unsubscribe()
+
+	catchUp := func() error {
+		for {
+			page, err := s.ListVendorChanges(ctx, entityID, since, MaxChangesLimit)
+			if err != nil {
+				return err
+			}
+			for _, change := range page.Changes {
+				if err := emit(change); err != nil {
+					return err
+				}
+			}
+			since = page.Watermark
+			if !page.HasMore {
+				return nil
+			}
+		}
+	}
+
+	if err := catchUp(); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-wake:
+			if err := catchUp(); err != nil {
+				return err
+			}
+		case <-ticker.C:
+			// Poll as well, in case notifications are unavailable
+			if err := catchUp(); err != nil {
+				return err
+			}
+			if err := onHeartbeat(since); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package spend
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+var _ Provider = (*Cache)(nil)
+
+// Cache reuses the summaries of a Provider for a short time, so that vendor
+// pages loaded repeatedly do not call the payments service every time. Errors
+// are not cached.
+type Cache struct {
+	provider Provider
+	ttl      time.Duration
+	size     int
+
+	mu      sync.Mutex
+	entries map[cacheKey]cacheEntry
+}
+
+type cacheKey struct {
+	vendorID, entityID, period string
+}
+
+type cacheEntry struct {
+	summary   Summary
+	expiresAt time.Time
+}
+
+// NewCache creates a cache over provider keeping at most size summaries for ttl
+func NewCache(provider Provider, ttl time.Duration, size int) *Cache {
+	return &Cache{
+		provider: provider,
+		ttl:      ttl,
+		size:     size,
+		entries:  make(map[cacheKey]cacheEntry),
+	}
+}
+
+// GetVendorSpend returns the cached summary, asking the provider when there is
+// none or it expired
+func (c *Cache) GetVendorSpend(ctx context.Context, vendorID, entityID, period string) (*Summary, error) {
+	key := cacheKey{vendorID: vendorID, entityID: entityID, period: period}
+
+	c.mu.Lock()
+	entry, found := c.entries[key]
+	c.mu.Unlock()
+	if found && time.Now().Before(entry.expiresAt) {
+		summary := entry.summary
+		return &summary, nil
+	}
+
+	summary, err := c.provider.GetVendorSpend(ctx, vendorID, entityID, period)
+	if err != nil {
+		return nil, err
+	}
+	c.store(key, *summary)
+	return summary, nil
+}
+
+func (c *Cache) store(key cacheKey, summary Summary) {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= c.size {
+		for k, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= c.size {
+			c.entries = make(map[cacheKey]cacheEntry)
+		}
+	}
+	c.entries[key] = cacheEntry{summary: summary, expiresAt: now.Add(c.ttl)}
+}
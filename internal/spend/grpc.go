@@ -0,0 +1,59 @@
+package spend
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/pesio-ai/be-lib-proto/gen/go/ap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var _ Provider = (*GRPCProvider)(nil)
+
+// GRPCProvider asks the payments service for vendor spend
+type GRPCProvider struct {
+	client  pb.PaymentsServiceClient
+	timeout time.Duration
+}
+
+// NewGRPCProvider creates a provider calling client. Every call is bounded by
+// timeout.
+func NewGRPCProvider(client pb.PaymentsServiceClient, timeout time.Duration) *GRPCProvider {
+	return &GRPCProvider{client: client, timeout: timeout}
+}
+
+// GetVendorSpend asks the payments service what entityID paid vendorID within period
+func (p *GRPCProvider) GetVendorSpend(ctx context.Context, vendorID, entityID, period string) (*Summary, error) {
+	from, to, err := Bounds(period, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	resp, err := p.client.GetVendorSpend(ctx, &pb.GetVendorSpendRequest{
+		VendorId: vendorID,
+		EntityId: entityID,
+		From:     timestamppb.New(from),
+		To:       timestamppb.New(to),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get vendor spend from payments service: %w", err)
+	}
+
+	summary := &Summary{
+		Period:       period,
+		From:         from,
+		To:           to,
+		Amount:       resp.Amount,
+		Currency:     resp.Currency,
+		PaymentCount: resp.PaymentCount,
+	}
+	if resp.LastPaymentAt != nil {
+		last := resp.LastPaymentAt.AsTime()
+		summary.LastPaymentAt = &last
+	}
+	return summary, nil
+}
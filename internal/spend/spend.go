@@ -0,0 +1,73 @@
+// Package spend reports how much an entity paid its vendors. Payments are owned
+// by the payments service: a Provider asks it for the spend of a vendor, Stub
+// stands in when no payments service is configured and Cache reuses results
+// for a short time.
+package spend
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Periods of a spend summary
+const (
+	// PeriodYTD is the calendar year to date
+	PeriodYTD = "ytd"
+	// PeriodTTM is the trailing twelve months
+	PeriodTTM = "ttm"
+)
+
+// Periods are the supported spend periods
+var Periods = []string{PeriodYTD, PeriodTTM}
+
+// Summary is what an entity paid a vendor within a period
+type Summary struct {
+	Period string    `json:"period"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	// Amount is in minor units of Currency; Currency is empty when nothing was paid
+	Amount        int64      `json:"amount"`
+	Currency      string     `json:"currency,omitempty"`
+	PaymentCount  int64      `json:"payment_count"`
+	LastPaymentAt *time.Time `json:"last_payment_at"`
+}
+
+// Provider supplies vendor spend
+type Provider interface {
+	// GetVendorSpend returns what entityID paid vendorID within period
+	GetVendorSpend(ctx context.Context, vendorID, entityID, period string) (*Summary, error)
+}
+
+// Bounds returns the time range of period ending at now, in UTC
+func Bounds(period string, now time.Time) (from, to time.Time, err error) {
+	to = now.UTC()
+	switch period {
+	case PeriodYTD:
+		from = time.Date(to.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	case PeriodTTM:
+		from = to.AddDate(-1, 0, 0)
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown spend period %q", period)
+	}
+	return from, to, nil
+}
+
+// IsValidPeriod reports whether period is a supported spend period
+func IsValidPeriod(period string) bool {
+	return period == PeriodYTD || period == PeriodTTM
+}
+
+var _ Provider = Stub{}
+
+// Stub is the default Provider, reporting no spend
+type Stub struct{}
+
+// GetVendorSpend returns a zero summary for period
+func (Stub) GetVendorSpend(ctx context.Context, vendorID, entityID, period string) (*Summary, error) {
+	from, to, err := Bounds(period, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &Summary{Period: period, From: from, To: to}, nil
+}
@@ -0,0 +1,160 @@
+package tinmatch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultRetryAfter is how long to back off when the provider throttles
+// without a Retry-After header
+const defaultRetryAfter = time.Minute
+
+// ProviderMatcher verifies combinations with a TIN matching provider over
+// HTTP. Requests are POSTed in batches as
+//
+//	{"requests": [{"tin": "123456789", "name": "ACME LLC"}]}
+//
+// and the provider answers with a result per request, in order:
+//
+//	{"results": [{"status": "matched", "code": "0"}]}
+//
+// Calls are spaced to stay under the provider's rate limit, and a 429 holds
+// back every call of the matcher until its Retry-After has passed.
+type ProviderMatcher struct {
+	url       string
+	apiKey    string
+	client    *http.Client
+	batchSize int
+	interval  time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// NewProviderMatcher creates a matcher calling url, sending apiKey as a bearer
+// token when set. Requests are sent batchSize at a time, at most
+// callsPerMinute calls a minute, each bounded by timeout.
+func NewProviderMatcher(url, apiKey string, batchSize, callsPerMinute int, timeout time.Duration) *ProviderMatcher {
+	return &ProviderMatcher{
+		url:       url,
+		apiKey:    apiKey,
+		client:    &http.Client{Timeout: timeout},
+		batchSize: max(batchSize, 1),
+		interval:  time.Minute / time.Duration(max(callsPerMinute, 1)),
+	}
+}
+
+type providerRequest struct {
+	Requests []Request `json:"requests"`
+}
+
+type providerResponse struct {
+	Results []Result `json:"results"`
+}
+
+// Match verifies reqs in batches, waiting between calls for the rate limit
+func (p *ProviderMatcher) Match(ctx context.Context, reqs []Request) ([]Result, error) {
+	results := make([]Result, 0, len(reqs))
+	for start := 0; start < len(reqs); start += p.batchSize {
+		batch := reqs[start:min(start+p.batchSize, len(reqs))]
+		if err := p.wait(ctx); err != nil {
+			return results, err
+		}
+		batchResults, err := p.call(ctx, batch)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, batchResults...)
+	}
+	return results, nil
+}
+
+// wait blocks until the next call is allowed and reserves its slot
+func (p *ProviderMatcher) wait(ctx context.Context) error {
+	p.mu.Lock()
+	now := time.Now()
+	at := p.next
+	if at.Before(now) {
+		at = now
+	}
+	p.next = at.Add(p.interval)
+	p.mu.Unlock()
+
+	if delay := time.Until(at); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return nil
+}
+
+// backOff holds back every call until retryAfter has passed
+func (p *ProviderMatcher) backOff(retryAfter time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if until := time.Now().Add(retryAfter); until.After(p.next) {
+		p.next = until
+	}
+}
+
+// call sends one batch to the provider
+func (p *ProviderMatcher) call(ctx context.Context, batch []Request) ([]Result, error) {
+	body, err := json.Marshal(providerRequest{Requests: batch})
+	if err != nil {
+		return nil, fmt.Errorf("encode TIN matching request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("build TIN matching request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("call TIN matching provider: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		retryAfter := defaultRetryAfter
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		p.backOff(retryAfter)
+		return nil, &ThrottledError{RetryAfter: retryAfter}
+	}
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("TIN matching provider returned %s", resp.Status)
+	}
+
+	var result providerResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode TIN matching response: %w", err)
+	}
+	if len(result.Results) != len(batch) {
+		return nil, fmt.Errorf("TIN matching provider returned %d results for %d requests", len(result.Results), len(batch))
+	}
+	for _, r := range result.Results {
+		if !IsValidStatus(r.Status) {
+			return nil, fmt.Errorf("TIN matching provider returned unknown status %q", r.Status)
+		}
+	}
+	return result.Results, nil
+}
@@ -0,0 +1,73 @@
+// Package tinmatch verifies vendor tax ID and legal name combinations with the
+// IRS TIN matching service before 1099s are filed. A Matcher checks them; Stub
+// leaves every vendor unverified and ProviderMatcher calls a TIN matching
+// provider.
+package tinmatch
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Verification statuses of a TIN and legal name combination
+const (
+	StatusMatched    = "matched"
+	StatusMismatched = "mismatched"
+	StatusUnverified = "unverified"
+	// StatusPending means the provider accepted the request and answers later;
+	// the combination is checked again on the next run
+	StatusPending = "pending"
+)
+
+// IsValidStatus reports whether status is a known verification status
+func IsValidStatus(status string) bool {
+	switch status {
+	case StatusMatched, StatusMismatched, StatusUnverified, StatusPending:
+		return true
+	}
+	return false
+}
+
+// Request is a TIN and legal name combination to verify
+type Request struct {
+	TIN  string `json:"tin"`
+	Name string `json:"name"`
+}
+
+// Result is the verification of a Request. Code is the provider's own result
+// code, e.g. the IRS TIN matching indicator.
+type Result struct {
+	Status string `json:"status"`
+	Code   string `json:"code,omitempty"`
+}
+
+// Matcher verifies TIN and legal name combinations
+type Matcher interface {
+	// Match verifies reqs, returning one result per request in order. On error
+	// it returns the results of the requests verified before it, so callers
+	// can keep them; a *ThrottledError means the provider asked to slow down.
+	Match(ctx context.Context, reqs []Request) ([]Result, error)
+}
+
+// ThrottledError is returned when the provider rejects calls for a while
+type ThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("TIN matching provider is throttling requests, retry after %s", e.RetryAfter)
+}
+
+// Stub is the default Matcher, used when no provider is configured. It
+// verifies nothing: every combination stays unverified.
+type Stub struct{}
+
+// Match returns an unverified result per request
+func (Stub) Match(ctx context.Context, reqs []Request) ([]Result, error) {
+	results := make([]Result, len(reqs))
+	for i := range results {
+		results[i] = Result{Status: StatusUnverified}
+	}
+	return results, nil
+}
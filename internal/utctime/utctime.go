@@ -0,0 +1,73 @@
+// Package utctime holds the service's convention for timestamps: clients send
+// RFC 3339 timestamps with an explicit offset ("Z" or "+10:00"), the service
+// converts them to UTC before storing or comparing them, and responses carry
+// UTC timestamps with a "Z" suffix. Timestamps without an offset are rejected
+// rather than read in some zone, since a filter read in the wrong zone is off
+// by hours without any error. Dates (YYYY-MM-DD) are UTC days.
+package utctime
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Example is a timestamp in the accepted format, for error messages
+const Example = "2026-01-02T15:04:05Z"
+
+var (
+	// ErrNoOffset is returned for timestamps without a UTC offset
+	ErrNoOffset = errors.New("timestamp has no UTC offset")
+	// ErrInvalid is returned for values that are not RFC 3339 timestamps
+	ErrInvalid = errors.New("not an RFC 3339 timestamp")
+)
+
+// UseUTC makes UTC the process' local time zone, so that time.Now, the
+// timestamps read from Postgres (in the local zone) and the times written to
+// JSON responses and logs are all UTC, whatever TZ the host runs in. The
+// service binaries call it first thing in main.
+func UseUTC() {
+	time.Local = time.UTC
+}
+
+// Parse parses an RFC 3339 timestamp with an offset, with or without
+// fractional seconds, and returns it in UTC. A space before the offset is
+// read as "+": it is what an unescaped "+10:00" in a query string decodes to.
+func Parse(raw string) (time.Time, error) {
+	value := strings.TrimSpace(raw)
+	if i := strings.LastIndexByte(value, ' '); i > 0 && i == len(value)-len("00:00")-1 {
+		value = value[:i] + "+" + value[i+1:]
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err == nil {
+		return t.UTC(), nil
+	}
+	if _, naiveErr := time.Parse("2006-01-02T15:04:05.999999999", value); naiveErr == nil {
+		return time.Time{}, ErrNoOffset
+	}
+	return time.Time{}, ErrInvalid
+}
+
+// ParseTimeOrDate parses an RFC 3339 timestamp like Parse or a date, read as
+// midnight UTC, and returns it in UTC
+func ParseTimeOrDate(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, strings.TrimSpace(raw)); err == nil {
+		return t, nil
+	}
+	return Parse(raw)
+}
+
+// Message describes why a parameter holding raw is not a valid timestamp;
+// dates tells whether dates are accepted too
+func Message(name, raw string, err error, dates bool) string {
+	accepted := "an RFC 3339 timestamp"
+	if dates {
+		accepted += " or a date (YYYY-MM-DD)"
+	}
+	if errors.Is(err, ErrNoOffset) {
+		return fmt.Sprintf("%s must be %s with a UTC offset, e.g. %s or 2026-01-02T15:04:05+10:00; got %q without one", name, accepted, Example, raw)
+	}
+	return fmt.Sprintf("%s must be %s, e.g. %s; got %q", name, accepted, Example, raw)
+}
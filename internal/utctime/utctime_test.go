@@ -0,0 +1,191 @@
+package utctime
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want time.Time
+	}{
+		{"utc", "2026-01-02T15:04:05Z", time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"positive offset", "2026-01-02T15:04:05+10:00", time.Date(2026, 1, 2, 5, 4, 5, 0, time.UTC)},
+		{"negative offset", "2026-01-02T15:04:05-05:30", time.Date(2026, 1, 2, 20, 34, 5, 0, time.UTC)},
+		{"offset crossing the day", "2026-01-01T02:00:00+10:00", time.Date(2025, 12, 31, 16, 0, 0, 0, time.UTC)},
+		{"fractional seconds", "2026-01-02T15:04:05.123456+01:00", time.Date(2026, 1, 2, 14, 4, 5, 123456000, time.UTC)},
+		{"space decoded offset", "2026-01-02T15:04:05 10:00", time.Date(2026, 1, 2, 5, 4, 5, 0, time.UTC)},
+		{"surrounding spaces", " 2026-01-02T15:04:05Z ", time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.raw)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.raw, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Parse(%q) = %v, want %v", tt.raw, got, tt.want)
+			}
+			if got.Location() != time.UTC {
+				t.Errorf("Parse(%q) location = %v, want UTC", tt.raw, got.Location())
+			}
+		})
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want error
+	}{
+		{"no offset", "2026-01-02T15:04:05", ErrNoOffset},
+		{"no offset with fractional seconds", "2026-01-02T15:04:05.5", ErrNoOffset},
+		{"date", "2026-01-02", ErrInvalid},
+		{"space separated", "2026-01-02 15:04:05Z", ErrInvalid},
+		{"zone abbreviation", "2026-01-02T15:04:05 AEST", ErrInvalid},
+		{"offset without colon", "2026-01-02T15:04:05+1000", ErrInvalid},
+		{"not a time", "yesterday", ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.raw); !errors.Is(err, tt.want) {
+				t.Errorf("Parse(%q) error = %v, want %v", tt.raw, err, tt.want)
+			}
+		})
+	}
+}
+
+// Sydney left daylight saving at 03:00 AEDT on 5 April 2026, when clocks went
+// back to 02:00 AEST, so 02:30 happened twice: first at +11:00, then at +10:00
+func TestParseDSTBoundary(t *testing.T) {
+	sydney, err := time.LoadLocation("Australia/Sydney")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		raw  string
+		want time.Time
+	}{
+		{"before the change", "2026-04-05T01:59:59+11:00", time.Date(2026, 4, 4, 14, 59, 59, 0, time.UTC)},
+		{"first 02:30", "2026-04-05T02:30:00+11:00", time.Date(2026, 4, 4, 15, 30, 0, 0, time.UTC)},
+		{"second 02:30", "2026-04-05T02:30:00+10:00", time.Date(2026, 4, 4, 16, 30, 0, 0, time.UTC)},
+		{"after the change", "2026-04-05T03:00:00+10:00", time.Date(2026, 4, 4, 17, 0, 0, 0, time.UTC)},
+		{"daylight saving starts", "2026-10-04T03:00:00+11:00", time.Date(2026, 10, 3, 16, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.raw)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.raw, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Parse(%q) = %v, want %v", tt.raw, got, tt.want)
+			}
+			// The parsed instant is the one the client meant in Sydney
+			if local := got.In(sydney).Format(time.RFC3339); local != strings.TrimSpace(tt.raw) {
+				t.Errorf("Parse(%q) in Sydney = %s", tt.raw, local)
+			}
+		})
+	}
+
+	first, _ := Parse("2026-04-05T02:30:00+11:00")
+	second, _ := Parse("2026-04-05T02:30:00+10:00")
+	if got := second.Sub(first); got != time.Hour {
+		t.Errorf("the repeated 02:30 values are %v apart, want 1h", got)
+	}
+}
+
+func TestParseTimeOrDate(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    time.Time
+		wantErr error
+	}{
+		{raw: "2026-01-02", want: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{raw: " 2026-04-05 ", want: time.Date(2026, 4, 5, 0, 0, 0, 0, time.UTC)},
+		{raw: "2026-01-02T15:04:05+10:00", want: time.Date(2026, 1, 2, 5, 4, 5, 0, time.UTC)},
+		{raw: "2026-01-02T15:04:05", wantErr: ErrNoOffset},
+		{raw: "2026-02-30", wantErr: ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := ParseTimeOrDate(tt.raw)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("ParseTimeOrDate(%q) error = %v, want %v", tt.raw, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTimeOrDate(%q) error = %v", tt.raw, err)
+			}
+			if !got.Equal(tt.want) || got.Location() != time.UTC {
+				t.Errorf("ParseTimeOrDate(%q) = %v, want %v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessage(t *testing.T) {
+	_, err := Parse("2026-01-02T15:04:05")
+	msg := Message("since", "2026-01-02T15:04:05", err, false)
+	for _, want := range []string{"since", "UTC offset", Example, `"2026-01-02T15:04:05"`} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Message() = %q, want it to contain %q", msg, want)
+		}
+	}
+	if strings.Contains(msg, "YYYY-MM-DD") {
+		t.Errorf("Message() = %q, offers dates where they are not accepted", msg)
+	}
+
+	_, err = ParseTimeOrDate("soon")
+	msg = Message("from", "soon", err, true)
+	if strings.Contains(msg, "UTC offset") || !strings.Contains(msg, "YYYY-MM-DD") {
+		t.Errorf("Message() = %q, want the date format and no offset hint", msg)
+	}
+}
+
+func TestUseUTC(t *testing.T) {
+	local := time.Local
+	t.Cleanup(func() { time.Local = local })
+
+	sydney, err := time.LoadLocation("Australia/Sydney")
+	if err != nil {
+		t.Skipf("no time zone data: %v", err)
+	}
+	time.Local = sydney
+
+	UseUTC()
+
+	now := time.Now()
+	if now.Location() != time.Local || time.Local != time.UTC {
+		t.Fatalf("time.Now() location = %v, want UTC", now.Location())
+	}
+	if _, offset := now.Zone(); offset != 0 {
+		t.Errorf("time.Now() offset = %d, want 0", offset)
+	}
+
+	// Parsed values and the process' own times serialize with a Z suffix
+	parsed, _ := Parse("2026-04-05T02:30:00+11:00")
+	for _, v := range []time.Time{now, parsed, time.Unix(1775313000, 0)} {
+		out, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("json.Marshal(%v) error = %v", v, err)
+		}
+		if !strings.HasSuffix(string(out), `Z"`) {
+			t.Errorf("json.Marshal(%v) = %s, want a Z suffix", v, out)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+-- Revert 001_initial_schema.sql
+
+DROP TABLE IF EXISTS payment_terms;
+DROP TABLE IF EXISTS vendor_documents;
+DROP TABLE IF EXISTS vendor_contacts;
+DROP TABLE IF EXISTS vendors;
+
+DROP FUNCTION IF EXISTS update_updated_at_column();
+
+DROP TYPE IF EXISTS contact_type;
+DROP TYPE IF EXISTS payment_method;
+DROP TYPE IF EXISTS vendor_status;
+DROP TYPE IF EXISTS vendor_type;
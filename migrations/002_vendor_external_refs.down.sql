@@ -0,0 +1,3 @@
+-- Revert 002_vendor_external_refs.sql
+
+DROP TABLE IF EXISTS vendor_external_refs;
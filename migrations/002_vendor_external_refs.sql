@@ -0,0 +1,22 @@
+-- Vendor External References (IDs of the vendor in external systems such as QuickBooks or NetSuite)
+CREATE TABLE vendor_external_refs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    vendor_id UUID NOT NULL REFERENCES vendors(id) ON DELETE CASCADE,
+    entity_id UUID NOT NULL,
+    system VARCHAR(50) NOT NULL,
+    external_id VARCHAR(255) NOT NULL,
+    created_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT vendor_external_refs_vendor_system_unique UNIQUE (vendor_id, system),
+    CONSTRAINT vendor_external_refs_external_unique UNIQUE (entity_id, system, external_id)
+);
+
+CREATE INDEX idx_vendor_external_refs_vendor_id ON vendor_external_refs(vendor_id);
+
+CREATE TRIGGER trigger_vendor_external_refs_updated_at
+BEFORE UPDATE ON vendor_external_refs
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE vendor_external_refs IS 'Mapping of vendors to their identifiers in external systems (one ID per system per vendor)';
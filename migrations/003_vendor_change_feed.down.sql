@@ -0,0 +1,17 @@
+-- Revert 003_vendor_change_feed.sql. Soft-deleted vendors are removed, as
+-- their codes may collide with live vendors once codes are unique again.
+
+DROP TRIGGER IF EXISTS trigger_vendors_change_seq ON vendors;
+DROP FUNCTION IF EXISTS assign_vendor_change_seq();
+
+DROP INDEX IF EXISTS idx_vendors_entity_change_seq;
+
+DELETE FROM vendors WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS vendors_entity_code_unique;
+ALTER TABLE vendors ADD CONSTRAINT vendors_entity_code_unique UNIQUE (entity_id, vendor_code);
+
+ALTER TABLE vendors
+    DROP COLUMN IF EXISTS change_seq,
+    DROP COLUMN IF EXISTS deleted_at;
+
+DROP SEQUENCE IF EXISTS vendor_change_seq;
@@ -0,0 +1,45 @@
+-- Soft delete and change sequence for incremental sync
+
+ALTER TABLE vendors
+    ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN change_seq BIGINT;
+
+CREATE SEQUENCE vendor_change_seq;
+
+-- Backfill existing rows in modification order
+UPDATE vendors v
+SET change_seq = s.seq
+FROM (
+    SELECT id, nextval('vendor_change_seq') AS seq
+    FROM (SELECT id FROM vendors ORDER BY updated_at, id) ordered
+) s
+WHERE v.id = s.id;
+
+ALTER TABLE vendors ALTER COLUMN change_seq SET NOT NULL;
+
+-- Vendor codes only need to be unique among live vendors
+ALTER TABLE vendors DROP CONSTRAINT vendors_entity_code_unique;
+CREATE UNIQUE INDEX vendors_entity_code_unique ON vendors(entity_id, vendor_code) WHERE deleted_at IS NULL;
+
+CREATE INDEX idx_vendors_entity_change_seq ON vendors(entity_id, change_seq);
+
+-- Assign a new change_seq on every insert/update (including soft deletes).
+-- The per-entity transaction lock serializes writers within an entity so that
+-- change_seq values become visible in commit order, i.e. a reader that has seen
+-- seq N can never later observe a newly committed change with seq < N.
+CREATE OR REPLACE FUNCTION assign_vendor_change_seq()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('vendor_change_seq'), hashtext(NEW.entity_id::text));
+    NEW.change_seq = nextval('vendor_change_seq');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_vendors_change_seq
+BEFORE INSERT OR UPDATE ON vendors
+FOR EACH ROW
+EXECUTE FUNCTION assign_vendor_change_seq();
+
+COMMENT ON COLUMN vendors.change_seq IS 'Monotonic sequence of the last mutation, used as the incremental sync watermark';
+COMMENT ON COLUMN vendors.deleted_at IS 'Soft delete timestamp; deleted vendors are returned as tombstones by the change feed';
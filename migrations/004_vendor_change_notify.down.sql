@@ -0,0 +1,4 @@
+-- Revert 004_vendor_change_notify.sql
+
+DROP TRIGGER IF EXISTS trigger_vendors_notify_change ON vendors;
+DROP FUNCTION IF EXISTS notify_vendor_change();
@@ -0,0 +1,15 @@
+-- Notify listeners of committed vendor changes (used by the WatchVendors stream)
+
+-- Payload format: "<entity_id>:<change_seq>". Notifications are delivered on commit.
+CREATE OR REPLACE FUNCTION notify_vendor_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('vendor_changes', NEW.entity_id::text || ':' || NEW.change_seq::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_vendors_notify_change
+AFTER INSERT OR UPDATE ON vendors
+FOR EACH ROW
+EXECUTE FUNCTION notify_vendor_change();
@@ -0,0 +1,5 @@
+-- Revert 005_vendor_transfer_audit.sql
+
+DROP TABLE IF EXISTS vendor_change_tombstones;
+DROP TABLE IF EXISTS vendor_events;
+DROP TABLE IF EXISTS vendor_audit_log;
@@ -0,0 +1,56 @@
+-- Audit log, event outbox and change-feed tombstones for cross-entity vendor transfers
+
+-- Vendor Audit Log
+CREATE TABLE vendor_audit_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    entity_id UUID NOT NULL,
+    vendor_id UUID NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    actor_id UUID,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_vendor_audit_log_entity_vendor ON vendor_audit_log(entity_id, vendor_id, created_at);
+
+COMMENT ON TABLE vendor_audit_log IS 'Audit trail of administrative vendor operations, one row per affected entity';
+
+-- Vendor Events (transactional outbox, published by a relay and marked with published_at)
+CREATE TABLE vendor_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    entity_id UUID NOT NULL,
+    vendor_id UUID NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_vendor_events_unpublished ON vendor_events(created_at) WHERE published_at IS NULL;
+
+COMMENT ON TABLE vendor_events IS 'Outbox of vendor domain events written in the same transaction as the change';
+
+-- Change-feed tombstones for vendors that left an entity without being deleted
+CREATE TABLE vendor_change_tombstones (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    entity_id UUID NOT NULL,
+    vendor_id UUID NOT NULL,
+    vendor_code VARCHAR(50) NOT NULL,
+    change_seq BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_vendor_change_tombstones_entity_seq ON vendor_change_tombstones(entity_id, change_seq);
+
+-- Tombstones share the vendor change sequence and notifications
+CREATE TRIGGER trigger_vendor_change_tombstones_change_seq
+BEFORE INSERT ON vendor_change_tombstones
+FOR EACH ROW
+EXECUTE FUNCTION assign_vendor_change_seq();
+
+CREATE TRIGGER trigger_vendor_change_tombstones_notify
+AFTER INSERT ON vendor_change_tombstones
+FOR EACH ROW
+EXECUTE FUNCTION notify_vendor_change();
+
+COMMENT ON TABLE vendor_change_tombstones IS 'Change-feed deletions for vendors transferred to another entity';
@@ -0,0 +1,7 @@
+-- Revert 006_balance_ledger_retention.sql
+
+DROP INDEX IF EXISTS idx_vendor_audit_log_created_at;
+DROP INDEX IF EXISTS idx_vendors_deleted_at;
+
+DROP TABLE IF EXISTS entity_retention_settings;
+DROP TABLE IF EXISTS vendor_balance_transactions;
@@ -0,0 +1,36 @@
+-- Balance ledger and per-entity retention settings
+
+-- Vendor Balance Transactions (one row per balance adjustment)
+CREATE TABLE vendor_balance_transactions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    vendor_id UUID NOT NULL REFERENCES vendors(id) ON DELETE CASCADE,
+    entity_id UUID NOT NULL,
+    amount BIGINT NOT NULL,
+    balance_after BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_vendor_balance_transactions_vendor ON vendor_balance_transactions(vendor_id, created_at);
+
+COMMENT ON TABLE vendor_balance_transactions IS 'Ledger of vendor balance adjustments (amounts in cents)';
+
+-- Entity Retention Settings (entities without a row use the service defaults)
+CREATE TABLE entity_retention_settings (
+    entity_id UUID PRIMARY KEY,
+    deleted_vendor_retention_days INTEGER,
+    audit_log_retention_days INTEGER,
+    updated_by UUID,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT entity_retention_settings_deleted_vendor_check CHECK (deleted_vendor_retention_days IS NULL OR deleted_vendor_retention_days > 0),
+    CONSTRAINT entity_retention_settings_audit_log_check CHECK (audit_log_retention_days IS NULL OR audit_log_retention_days > 0)
+);
+
+CREATE TRIGGER trigger_entity_retention_settings_updated_at
+BEFORE UPDATE ON entity_retention_settings
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX idx_vendors_deleted_at ON vendors(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_vendor_audit_log_created_at ON vendor_audit_log(created_at);
+
+COMMENT ON TABLE entity_retention_settings IS 'Per-entity retention of soft-deleted vendors and audit log rows, in days';
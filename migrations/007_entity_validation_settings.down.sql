@@ -0,0 +1,3 @@
+-- Revert 007_entity_validation_settings.sql
+
+DROP TABLE IF EXISTS entity_validation_settings;
@@ -0,0 +1,17 @@
+-- Per-entity vendor validation settings
+
+CREATE TABLE entity_validation_settings (
+    entity_id UUID PRIMARY KEY,
+    -- Rule requiring email, phone or a primary contact with an email: off, warn or enforce (NULL = default)
+    contact_method_rule VARCHAR(20),
+    updated_by UUID,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT entity_validation_settings_contact_method_rule_check CHECK (contact_method_rule IN ('off', 'warn', 'enforce'))
+);
+
+CREATE TRIGGER trigger_entity_validation_settings_updated_at
+BEFORE UPDATE ON entity_validation_settings
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE entity_validation_settings IS 'Per-entity overrides of vendor validation rules';
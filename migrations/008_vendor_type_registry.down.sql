@@ -0,0 +1,12 @@
+-- Revert 008_vendor_type_registry.sql. Vendors with an entity-specific type
+-- fall back to supplier, the only way to fit them into the enum.
+
+DROP INDEX IF EXISTS idx_vendors_entity_vendor_type;
+DROP TABLE IF EXISTS vendor_types;
+
+CREATE TYPE vendor_type AS ENUM ('supplier', 'contractor', 'service_provider', 'consultant', 'utility');
+
+UPDATE vendors SET vendor_type = 'supplier'
+WHERE vendor_type NOT IN ('supplier', 'contractor', 'service_provider', 'consultant', 'utility');
+
+ALTER TABLE vendors ALTER COLUMN vendor_type TYPE vendor_type USING vendor_type::vendor_type;
@@ -0,0 +1,39 @@
+-- Per-entity vendor type registry replacing the vendor_type enum
+
+-- Vendor types become plain codes validated against the registry
+ALTER TABLE vendors ALTER COLUMN vendor_type TYPE VARCHAR(50) USING vendor_type::text;
+DROP TYPE vendor_type;
+
+-- Vendor Types. Rows without an entity are the defaults used by entities that
+-- never customized their registry; an entity's first change copies them.
+CREATE TABLE vendor_types (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    entity_id UUID,
+    code VARCHAR(50) NOT NULL,
+    label VARCHAR(100) NOT NULL,
+    -- Deprecated types are hidden from pickers but stay valid on existing vendors
+    is_deprecated BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT vendor_types_entity_code_unique UNIQUE (entity_id, code),
+    CONSTRAINT vendor_types_code_check CHECK (code ~ '^[a-z][a-z0-9_]*$')
+);
+
+CREATE UNIQUE INDEX idx_vendor_types_default_code ON vendor_types(code) WHERE entity_id IS NULL;
+
+CREATE TRIGGER trigger_vendor_types_updated_at
+BEFORE UPDATE ON vendor_types
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO vendor_types (entity_id, code, label) VALUES
+    (NULL, 'supplier', 'Supplier'),
+    (NULL, 'contractor', 'Contractor'),
+    (NULL, 'service_provider', 'Service Provider'),
+    (NULL, 'consultant', 'Consultant'),
+    (NULL, 'utility', 'Utility');
+
+CREATE INDEX idx_vendors_entity_vendor_type ON vendors(entity_id, vendor_type) WHERE deleted_at IS NULL;
+
+COMMENT ON TABLE vendor_types IS 'Allowed vendor types per entity; rows without entity_id are the defaults';
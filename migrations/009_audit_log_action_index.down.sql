@@ -0,0 +1,3 @@
+-- Revert 009_audit_log_action_index.sql
+
+DROP INDEX IF EXISTS idx_vendor_audit_log_entity_action;
@@ -0,0 +1,4 @@
+-- Index for listing audit entries of an entity by action, e.g. the bank
+-- detail changes reviewed by Treasury
+
+CREATE INDEX idx_vendor_audit_log_entity_action ON vendor_audit_log(entity_id, action, created_at DESC);
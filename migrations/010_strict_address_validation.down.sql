@@ -0,0 +1,3 @@
+-- Revert 010_strict_address_validation.sql
+
+ALTER TABLE entity_validation_settings DROP COLUMN IF EXISTS strict_address_validation;
@@ -0,0 +1,5 @@
+-- Per-entity switch turning address validation warnings into errors
+
+ALTER TABLE entity_validation_settings ADD COLUMN strict_address_validation BOOLEAN;
+
+COMMENT ON COLUMN entity_validation_settings.strict_address_validation IS 'Reject vendors with invalid addresses instead of warning (NULL = default)';
@@ -0,0 +1,5 @@
+-- Revert 011_approval_queue.sql
+
+DROP TABLE IF EXISTS vendor_approval_sla_breaches;
+
+DROP INDEX IF EXISTS idx_vendors_pending_approval;
@@ -0,0 +1,12 @@
+-- Approval queue: pending vendors by age, and the SLA breaches already announced
+
+CREATE INDEX idx_vendors_pending_approval ON vendors(entity_id, created_at)
+    WHERE status = 'pending_approval' AND deleted_at IS NULL;
+
+CREATE TABLE vendor_approval_sla_breaches (
+    vendor_id UUID PRIMARY KEY REFERENCES vendors(id) ON DELETE CASCADE,
+    entity_id UUID NOT NULL,
+    breached_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE vendor_approval_sla_breaches IS 'Pending vendors whose approval SLA breach was published; cleared on approval or rejection';
@@ -0,0 +1,5 @@
+-- Revert 012_approval_policies.sql
+
+DROP TABLE IF EXISTS vendor_approvals;
+
+DROP TABLE IF EXISTS entity_approval_policies;
@@ -0,0 +1,34 @@
+-- Per-entity approval policies and the approvals given to pending vendors
+
+CREATE TABLE entity_approval_policies (
+    entity_id UUID PRIMARY KEY,
+    -- Approvals needed by vendors matching a condition; others need one
+    required_approvals INTEGER NOT NULL DEFAULT 1,
+    -- Vendors with a credit limit at or above this many cents match (NULL = no condition)
+    credit_limit_threshold BIGINT,
+    -- Vendors with bank details match
+    when_bank_details BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_by UUID,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT entity_approval_policies_required_check CHECK (required_approvals BETWEEN 1 AND 5)
+);
+
+CREATE TRIGGER trigger_entity_approval_policies_updated_at
+BEFORE UPDATE ON entity_approval_policies
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TABLE vendor_approvals (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    vendor_id UUID NOT NULL REFERENCES vendors(id) ON DELETE CASCADE,
+    entity_id UUID NOT NULL,
+    -- NULL for approvals by unidentified callers, only accepted when one approval suffices
+    approver_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT vendor_approvals_vendor_approver_unique UNIQUE (vendor_id, approver_id)
+);
+
+CREATE INDEX idx_vendor_approvals_vendor ON vendor_approvals(vendor_id, created_at);
+
+COMMENT ON TABLE entity_approval_policies IS 'Number of approvals vendors of an entity need before activation';
+COMMENT ON TABLE vendor_approvals IS 'Approvals given to vendors pending approval, one per approver';
@@ -0,0 +1,5 @@
+-- Revert 013_vendor_risk_scores.sql
+
+DROP TABLE IF EXISTS vendor_risk_scores;
+
+DROP TABLE IF EXISTS risk_rule_weights;
@@ -0,0 +1,44 @@
+-- Vendor risk scores and the weights of the rules computing them
+
+-- Risk rule weights. Rows without an entity are the defaults; an entity's rows
+-- override the weights of single factors.
+CREATE TABLE risk_rule_weights (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    entity_id UUID,
+    factor VARCHAR(50) NOT NULL,
+    weight INTEGER NOT NULL,
+    updated_by UUID,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT risk_rule_weights_entity_factor_unique UNIQUE (entity_id, factor),
+    CONSTRAINT risk_rule_weights_weight_check CHECK (weight BETWEEN 0 AND 100)
+);
+
+CREATE UNIQUE INDEX idx_risk_rule_weights_default_factor ON risk_rule_weights(factor) WHERE entity_id IS NULL;
+
+CREATE TRIGGER trigger_risk_rule_weights_updated_at
+BEFORE UPDATE ON risk_rule_weights
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO risk_rule_weights (entity_id, factor, weight) VALUES
+    (NULL, 'missing_tax_id', 25),
+    (NULL, 'foreign_bank_account', 20),
+    (NULL, 'recent_bank_change', 25),
+    (NULL, 'new_vendor', 10),
+    (NULL, 'missing_w9', 15),
+    (NULL, 'over_credit_limit', 20);
+
+-- Stored apart from vendors so that rescoring does not touch the change feed
+CREATE TABLE vendor_risk_scores (
+    vendor_id UUID PRIMARY KEY REFERENCES vendors(id) ON DELETE CASCADE,
+    entity_id UUID NOT NULL,
+    score INTEGER NOT NULL,
+    -- Triggered factors with their weights: [{"factor": "...", "weight": 25}]
+    factors JSONB NOT NULL DEFAULT '[]',
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_vendor_risk_scores_entity_score ON vendor_risk_scores(entity_id, score);
+
+COMMENT ON TABLE risk_rule_weights IS 'Weights of the vendor risk rules; rows without entity_id are the defaults';
+COMMENT ON TABLE vendor_risk_scores IS 'Computed vendor risk scores with their factor breakdown';
@@ -0,0 +1,3 @@
+-- Revert 014_vendor_api_keys.sql
+
+DROP TABLE IF EXISTS vendor_api_keys;
@@ -0,0 +1,24 @@
+-- API keys letting suppliers read their own vendor record through the portal
+
+CREATE TABLE vendor_api_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    vendor_id UUID NOT NULL REFERENCES vendors(id) ON DELETE CASCADE,
+    entity_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    -- First characters of the secret, shown to tell keys apart
+    key_prefix VARCHAR(16) NOT NULL,
+    -- SHA-256 of the secret (hex); the secret itself is only returned on creation
+    key_hash CHAR(64) NOT NULL,
+    scopes TEXT[] NOT NULL,
+    created_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    revoked_by UUID,
+    CONSTRAINT vendor_api_keys_hash_unique UNIQUE (key_hash),
+    CONSTRAINT vendor_api_keys_scopes_check CHECK (cardinality(scopes) > 0)
+);
+
+CREATE INDEX idx_vendor_api_keys_vendor ON vendor_api_keys(vendor_id, created_at);
+
+COMMENT ON TABLE vendor_api_keys IS 'Supplier portal API keys, each scoped to one vendor';
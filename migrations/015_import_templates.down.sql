@@ -0,0 +1,3 @@
+-- Revert 015_import_templates.sql
+
+DROP TABLE IF EXISTS import_templates;
@@ -0,0 +1,25 @@
+-- Named column mappings for recurring CSV imports
+
+CREATE TABLE import_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    entity_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    -- Source column header -> import column
+    columns JSONB NOT NULL,
+    -- Import column -> value used when the file leaves it out or blank
+    defaults JSONB NOT NULL DEFAULT '{}',
+    -- Import column -> transforms applied in order, e.g. ["strip_leading_zeros"]
+    transforms JSONB NOT NULL DEFAULT '{}',
+    created_by UUID,
+    updated_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT import_templates_name_unique UNIQUE (entity_id, name)
+);
+
+CREATE TRIGGER trigger_import_templates_updated_at
+BEFORE UPDATE ON import_templates
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE import_templates IS 'Column mappings of CSV imports, reusable by template id';
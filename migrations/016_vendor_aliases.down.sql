@@ -0,0 +1,5 @@
+-- Revert 016_vendor_aliases.sql
+
+DROP TABLE IF EXISTS vendor_aliases;
+
+ALTER TABLE vendors DROP COLUMN IF EXISTS doing_business_as;
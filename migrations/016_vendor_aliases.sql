@@ -0,0 +1,22 @@
+-- Trade names: a doing-business-as name on the vendor and any number of
+-- alternate names, matched when invoices arrive under a name other than the
+-- vendor name
+
+ALTER TABLE vendors ADD COLUMN doing_business_as VARCHAR(255);
+
+CREATE TABLE vendor_aliases (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    vendor_id UUID NOT NULL REFERENCES vendors(id) ON DELETE CASCADE,
+    alias VARCHAR(255) NOT NULL,
+    -- Lowercased with whitespace collapsed, the form names are matched in
+    normalized_alias VARCHAR(255) NOT NULL,
+    created_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    -- Vendors of an entity may share an alias; a vendor may not repeat one
+    CONSTRAINT vendor_aliases_vendor_alias_unique UNIQUE (vendor_id, normalized_alias)
+);
+
+CREATE INDEX idx_vendor_aliases_normalized ON vendor_aliases(normalized_alias);
+
+COMMENT ON TABLE vendor_aliases IS 'Alternate (trade) names of vendors used for name matching and search';
+COMMENT ON COLUMN vendors.doing_business_as IS 'Trade name the vendor does business under';
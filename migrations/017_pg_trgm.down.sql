@@ -0,0 +1,3 @@
+-- Revert 017_pg_trgm.sql
+
+DROP EXTENSION IF EXISTS pg_trgm;
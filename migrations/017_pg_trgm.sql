@@ -0,0 +1,2 @@
+-- Trigram similarity (similarity()) for fuzzy vendor name matching
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
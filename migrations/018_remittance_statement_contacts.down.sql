@@ -0,0 +1,5 @@
+-- Revert 018_remittance_statement_contacts.sql
+
+ALTER TABLE vendor_contacts DROP COLUMN IF EXISTS receives_statements;
+
+ALTER TABLE vendors DROP COLUMN IF EXISTS remittance_email;
@@ -0,0 +1,13 @@
+-- Where remittance advices and statements go: a remittance email on the
+-- vendor, separate from its general email, and one designated statement
+-- contact per vendor
+
+ALTER TABLE vendors ADD COLUMN remittance_email VARCHAR(255);
+
+ALTER TABLE vendor_contacts ADD COLUMN receives_statements BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE UNIQUE INDEX idx_vendor_contacts_statement_contact
+    ON vendor_contacts(vendor_id) WHERE receives_statements;
+
+COMMENT ON COLUMN vendors.remittance_email IS 'Email remittance advices are sent to instead of the vendor email';
+COMMENT ON COLUMN vendor_contacts.receives_statements IS 'Contact statements are sent to; at most one per vendor';
@@ -0,0 +1,5 @@
+-- Revert 019_vendor_locale.sql
+
+DROP INDEX IF EXISTS idx_vendors_entity_locale;
+
+ALTER TABLE vendors DROP COLUMN IF EXISTS locale;
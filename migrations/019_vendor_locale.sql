@@ -0,0 +1,8 @@
+-- Language purchase orders and remittance emails are written in, as a BCP 47
+-- tag (e.g. fr-CA). Existing vendors get theirs when next updated.
+
+ALTER TABLE vendors ADD COLUMN locale VARCHAR(35);
+
+CREATE INDEX idx_vendors_entity_locale ON vendors(entity_id, locale) WHERE deleted_at IS NULL;
+
+COMMENT ON COLUMN vendors.locale IS 'BCP 47 language tag, defaulting to the most likely language of the country';
@@ -0,0 +1,5 @@
+-- Revert 020_vendor_accepted_currencies.sql
+
+DROP INDEX IF EXISTS idx_vendors_accepted_currencies;
+
+ALTER TABLE vendors DROP COLUMN IF EXISTS accepted_currencies;
@@ -0,0 +1,8 @@
+-- Currencies a vendor can be invoiced and paid in besides its primary
+-- currency. When set the list includes the primary currency.
+
+ALTER TABLE vendors ADD COLUMN accepted_currencies TEXT[];
+
+CREATE INDEX idx_vendors_accepted_currencies ON vendors USING GIN (accepted_currencies) WHERE deleted_at IS NULL;
+
+COMMENT ON COLUMN vendors.accepted_currencies IS 'ISO 4217 codes the vendor accepts, including currency; NULL when only currency is accepted';
@@ -0,0 +1,4 @@
+-- Revert 021_vendor_blocklist.sql
+
+DROP TABLE IF EXISTS vendor_blocklist_matches;
+DROP TABLE IF EXISTS vendor_blocklist;
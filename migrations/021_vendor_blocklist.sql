@@ -0,0 +1,39 @@
+-- Organization-wide blocklist of tax IDs (and optionally bank accounts) that
+-- no entity should onboard, and the record of every vendor found on it
+
+CREATE TABLE vendor_blocklist (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    -- Uppercase letters and digits only, the form tax IDs are compared in
+    tax_id VARCHAR(50) NOT NULL,
+    -- Hex SHA-256 of the normalized bank account number or IBAN
+    bank_account_hash VARCHAR(64),
+    reason TEXT NOT NULL,
+    -- Where the vendor was blocked; only shown to admins
+    entity_id UUID,
+    vendor_id UUID,
+    created_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT vendor_blocklist_tax_id_unique UNIQUE (tax_id)
+);
+
+CREATE INDEX idx_vendor_blocklist_bank_account_hash ON vendor_blocklist(bank_account_hash) WHERE bank_account_hash IS NOT NULL;
+
+-- Matches outlive the entries they matched, so entry_id is not a foreign key
+CREATE TABLE vendor_blocklist_matches (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    entry_id UUID NOT NULL,
+    entity_id UUID NOT NULL,
+    -- NULL for creates, which are checked before the vendor exists
+    vendor_id UUID,
+    vendor_code VARCHAR(50) NOT NULL,
+    stage VARCHAR(20) NOT NULL,
+    outcome VARCHAR(20) NOT NULL,
+    actor_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_vendor_blocklist_matches_created_at ON vendor_blocklist_matches(created_at DESC);
+CREATE INDEX idx_vendor_blocklist_matches_entity ON vendor_blocklist_matches(entity_id, created_at DESC);
+
+COMMENT ON TABLE vendor_blocklist IS 'Tax IDs and bank accounts no entity of the organization should onboard';
+COMMENT ON TABLE vendor_blocklist_matches IS 'Audit trail of vendors found on the blocklist at create or approval';
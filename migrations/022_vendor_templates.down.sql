@@ -0,0 +1,7 @@
+-- Revert 022_vendor_templates.sql
+
+DROP INDEX IF EXISTS idx_vendors_template_id;
+
+ALTER TABLE vendors DROP COLUMN IF EXISTS template_id;
+
+DROP TABLE IF EXISTS vendor_templates;
@@ -0,0 +1,28 @@
+-- Named create defaults of an entity, applied by CreateVendor with template_id
+
+CREATE TABLE vendor_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    entity_id UUID NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    -- Create field -> default value, e.g. {"currency": "GBP", "payment_terms": "NET30"}
+    defaults JSONB NOT NULL DEFAULT '{}',
+    created_by UUID,
+    updated_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT vendor_templates_name_unique UNIQUE (entity_id, name)
+);
+
+CREATE TRIGGER trigger_vendor_templates_updated_at
+BEFORE UPDATE ON vendor_templates
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE vendor_templates IS 'Vendor create defaults of an entity, reusable by template id';
+
+-- Not a foreign key: vendors keep the ID of a template after it is deleted
+ALTER TABLE vendors ADD COLUMN template_id UUID;
+
+CREATE INDEX idx_vendors_template_id ON vendors (template_id) WHERE template_id IS NOT NULL;
+
+COMMENT ON COLUMN vendors.template_id IS 'Vendor template the vendor was created from, for analytics';
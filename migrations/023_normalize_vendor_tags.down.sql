@@ -0,0 +1,4 @@
+-- Revert 023_normalize_vendor_tags.sql
+-- The original spelling of normalized tags is not kept, so only the index is dropped.
+
+DROP INDEX IF EXISTS idx_vendors_tags;
@@ -0,0 +1,25 @@
+-- Tags are normalized on write: trimmed, lowercased and with inner
+-- whitespace collapsed. Bring stored tags to the same form, dropping the
+-- duplicates and empty tags this creates and keeping first-seen order.
+
+WITH normalized AS (
+    SELECT v.id, ARRAY(
+        SELECT tag
+        FROM (
+            SELECT lower(regexp_replace(btrim(t), '\s+', ' ', 'g')) AS tag, MIN(ord) AS first
+            FROM unnest(v.tags) WITH ORDINALITY AS u(t, ord)
+            GROUP BY 1
+        ) n
+        WHERE tag <> ''
+        ORDER BY first
+    ) AS tags
+    FROM vendors v
+    WHERE cardinality(v.tags) > 0
+)
+UPDATE vendors
+SET tags = normalized.tags
+FROM normalized
+WHERE vendors.id = normalized.id AND vendors.tags IS DISTINCT FROM normalized.tags;
+
+-- Tag filters and usage counts look tags up by value
+CREATE INDEX idx_vendors_tags ON vendors USING GIN (tags) WHERE deleted_at IS NULL;
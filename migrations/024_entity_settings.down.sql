@@ -0,0 +1,3 @@
+-- Revert 024_entity_settings.sql
+
+DROP TABLE IF EXISTS entity_settings;
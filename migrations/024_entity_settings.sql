@@ -0,0 +1,16 @@
+-- Per-entity switches of vendor maintenance rules
+
+CREATE TABLE entity_settings (
+    entity_id UUID PRIMARY KEY,
+    -- Reject vendor code changes on active vendors without an admin override (NULL = default)
+    lock_vendor_code_after_activation BOOLEAN,
+    updated_by UUID,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER trigger_entity_settings_updated_at
+BEFORE UPDATE ON entity_settings
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE entity_settings IS 'Per-entity switches of vendor maintenance rules';
@@ -0,0 +1,4 @@
+-- Revert 025_favorite_and_recent_vendors.sql
+
+DROP TABLE IF EXISTS vendor_recent_views;
+DROP TABLE IF EXISTS vendor_favorites;
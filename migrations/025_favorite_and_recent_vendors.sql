@@ -0,0 +1,23 @@
+-- Per-user favorite vendors and recently viewed vendors
+
+CREATE TABLE vendor_favorites (
+    user_id UUID NOT NULL,
+    entity_id UUID NOT NULL,
+    vendor_id UUID NOT NULL REFERENCES vendors(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, entity_id, vendor_id)
+);
+
+-- Capped to the latest views of each user and entity when a view is recorded
+CREATE TABLE vendor_recent_views (
+    user_id UUID NOT NULL,
+    entity_id UUID NOT NULL,
+    vendor_id UUID NOT NULL REFERENCES vendors(id) ON DELETE CASCADE,
+    viewed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, entity_id, vendor_id)
+);
+
+CREATE INDEX idx_vendor_recent_views_user ON vendor_recent_views(user_id, entity_id, viewed_at DESC);
+
+COMMENT ON TABLE vendor_favorites IS 'Vendors pinned by users';
+COMMENT ON TABLE vendor_recent_views IS 'Vendors users viewed last, at most 25 per user and entity';
@@ -0,0 +1,3 @@
+-- Revert 026_scheduled_status_changes.sql
+
+DROP TABLE IF EXISTS scheduled_status_changes;
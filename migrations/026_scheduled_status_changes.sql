@@ -0,0 +1,28 @@
+-- Vendor status changes scheduled for a future date, applied by the worker
+
+CREATE TABLE scheduled_status_changes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    entity_id UUID NOT NULL,
+    vendor_id UUID NOT NULL REFERENCES vendors(id) ON DELETE CASCADE,
+    target_status vendor_status NOT NULL,
+    -- Applied by the first worker run on or after this date (UTC)
+    effective_date DATE NOT NULL,
+    reason TEXT,
+    state VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (state IN ('pending', 'applied', 'failed', 'cancelled')),
+    -- Why a due change could not be applied
+    failure_reason TEXT,
+    requested_by UUID,
+    cancelled_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    processed_at TIMESTAMP WITH TIME ZONE
+);
+
+-- One pending change per vendor and day
+CREATE UNIQUE INDEX idx_scheduled_status_changes_pending_unique
+    ON scheduled_status_changes(vendor_id, effective_date) WHERE state = 'pending';
+CREATE INDEX idx_scheduled_status_changes_due
+    ON scheduled_status_changes(effective_date) WHERE state = 'pending';
+CREATE INDEX idx_scheduled_status_changes_entity ON scheduled_status_changes(entity_id, effective_date);
+
+COMMENT ON TABLE scheduled_status_changes IS 'Vendor status changes scheduled for a future date';
@@ -0,0 +1,3 @@
+-- Revert 027_vendor_dormancy.sql
+
+DROP TABLE IF EXISTS vendor_dormancy;
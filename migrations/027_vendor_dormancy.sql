@@ -0,0 +1,15 @@
+-- Dormant vendors: no balance ledger activity and no updates for a configured
+-- number of months. Flags are kept apart from vendors so that flagging does
+-- not touch updated_at or the change feed.
+
+CREATE TABLE vendor_dormancy (
+    vendor_id UUID PRIMARY KEY REFERENCES vendors(id) ON DELETE CASCADE,
+    entity_id UUID NOT NULL,
+    -- Latest of the vendor's updated_at and its last balance transaction
+    last_activity_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    flagged_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_vendor_dormancy_entity ON vendor_dormancy(entity_id, vendor_id);
+
+COMMENT ON TABLE vendor_dormancy IS 'Vendors flagged dormant by the dormancy job; never changes vendor status';
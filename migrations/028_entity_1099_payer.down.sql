@@ -0,0 +1,3 @@
+-- Revert 028_entity_1099_payer.sql
+
+ALTER TABLE entity_settings DROP COLUMN IF EXISTS payer_1099;
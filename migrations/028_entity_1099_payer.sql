@@ -0,0 +1,5 @@
+-- Payer details of an entity printed on the 1099 forms it files
+
+ALTER TABLE entity_settings ADD COLUMN payer_1099 JSONB;
+
+COMMENT ON COLUMN entity_settings.payer_1099 IS 'Payer name, TIN, address and phone for 1099 exports';
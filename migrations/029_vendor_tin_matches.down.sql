@@ -0,0 +1,3 @@
+-- Revert 029_vendor_tin_matches.sql
+
+DROP TABLE IF EXISTS vendor_tin_matches;
@@ -0,0 +1,18 @@
+-- TIN matching results of vendors
+
+-- Stored apart from vendors so that verification does not touch the change
+-- feed. A result applies to the tax ID and legal name it was checked with,
+-- identified by their fingerprint, and lapses once either changes.
+CREATE TABLE vendor_tin_matches (
+    vendor_id UUID PRIMARY KEY REFERENCES vendors(id) ON DELETE CASCADE,
+    entity_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    -- The provider's own result code
+    code VARCHAR(20),
+    -- SHA-256 of the normalized tax ID and legal name checked
+    fingerprint CHAR(64) NOT NULL,
+    checked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT vendor_tin_matches_status_check CHECK (status IN ('matched', 'mismatched', 'unverified', 'pending'))
+);
+
+CREATE INDEX idx_vendor_tin_matches_entity_status ON vendor_tin_matches(entity_id, status);
@@ -0,0 +1,3 @@
+-- Revert 030_entity_strict_bank_validation.sql
+
+ALTER TABLE entity_settings DROP COLUMN IF EXISTS strict_bank_validation;
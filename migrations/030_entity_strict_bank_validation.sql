@@ -0,0 +1,5 @@
+-- Per-entity switch rejecting invalid bank details instead of warning
+
+ALTER TABLE entity_settings ADD COLUMN strict_bank_validation BOOLEAN;
+
+COMMENT ON COLUMN entity_settings.strict_bank_validation IS 'Reject invalid IBAN and SWIFT codes instead of warning (NULL = service default)';
@@ -0,0 +1,5 @@
+-- Revert 031_entity_lifecycle.sql
+
+DROP TABLE IF EXISTS event_consumer_cursors;
+DROP TABLE IF EXISTS entity_lifecycle_log;
+DROP TABLE IF EXISTS entity_states;
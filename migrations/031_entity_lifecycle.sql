@@ -0,0 +1,36 @@
+-- Entity lifecycle announced by the platform: suspended and deleted entities
+
+-- Entities without a row are active. Suspended and deleted entities are read
+-- only in this service.
+CREATE TABLE entity_states (
+    entity_id UUID PRIMARY KEY,
+    state VARCHAR(20) NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT entity_states_state_check CHECK (state IN ('suspended', 'deleted'))
+);
+
+-- Summary audit of entity lifecycle changes and cleanups; the vendor audit
+-- log only holds entries of single vendors
+CREATE TABLE entity_lifecycle_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    entity_id UUID NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    -- The platform event that caused the change, if any
+    event_id VARCHAR(100),
+    actor_id UUID,
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_entity_lifecycle_log_entity ON entity_lifecycle_log(entity_id, created_at);
+
+-- Position of each consumer of platform events in its stream
+CREATE TABLE event_consumer_cursors (
+    consumer VARCHAR(100) PRIMARY KEY,
+    cursor TEXT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE entity_states IS 'Suspended and deleted entities, read only in this service';
+COMMENT ON TABLE entity_lifecycle_log IS 'Audit of entity lifecycle changes and cleanups';
+COMMENT ON TABLE event_consumer_cursors IS 'Stream positions of platform event consumers';
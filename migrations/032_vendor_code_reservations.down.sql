@@ -0,0 +1,3 @@
+-- Revert 032_vendor_code_reservations.sql
+
+DROP TABLE IF EXISTS vendor_code_reservations;
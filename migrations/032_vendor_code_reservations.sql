@@ -0,0 +1,17 @@
+-- Vendor codes held for a while before the vendor is created
+
+-- A code is reserved for its entity until expires_at; creating the vendor
+-- consumes the reservation and the worker releases the expired ones
+CREATE TABLE vendor_code_reservations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    entity_id UUID NOT NULL,
+    vendor_code VARCHAR(50) NOT NULL,
+    reserved_by UUID,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT vendor_code_reservations_entity_code_unique UNIQUE (entity_id, vendor_code)
+);
+
+CREATE INDEX idx_vendor_code_reservations_expires ON vendor_code_reservations(expires_at);
+
+COMMENT ON TABLE vendor_code_reservations IS 'Vendor codes held for vendors about to be created';
@@ -0,0 +1,4 @@
+-- Revert 033_vendor_bank_verifications.sql
+
+ALTER TABLE entity_settings DROP COLUMN IF EXISTS require_bank_verification;
+DROP TABLE IF EXISTS vendor_bank_verifications;
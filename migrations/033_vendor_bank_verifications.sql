@@ -0,0 +1,29 @@
+-- Bank account verification of vendors before their first payment
+
+-- Stored apart from vendors like TIN matches. A verification applies to the
+-- bank account it was started with, identified by its fingerprint, so the
+-- vendor is unverified again once the account or routing number or the IBAN
+-- changes.
+CREATE TABLE vendor_bank_verifications (
+    vendor_id UUID PRIMARY KEY REFERENCES vendors(id) ON DELETE CASCADE,
+    entity_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    method VARCHAR(20) NOT NULL,
+    -- The verifier's reference of the attempt, needed to confirm it
+    reference VARCHAR(100),
+    -- SHA-256 of the normalized bank numbers verified
+    fingerprint CHAR(64) NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    verified_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT vendor_bank_verifications_status_check CHECK (status IN ('unverified', 'pending', 'verified', 'failed')),
+    CONSTRAINT vendor_bank_verifications_method_check CHECK (method IN ('micro_deposits', 'instant'))
+);
+
+CREATE INDEX idx_vendor_bank_verifications_entity_status ON vendor_bank_verifications(entity_id, status);
+
+-- Per-entity switch making unverified ACH and wire accounts fail vendor validation
+ALTER TABLE entity_settings ADD COLUMN require_bank_verification BOOLEAN;
+
+COMMENT ON TABLE vendor_bank_verifications IS 'Bank account verifications of vendors';
+COMMENT ON COLUMN entity_settings.require_bank_verification IS 'Fail validation of ACH and wire vendors with unverified accounts instead of warning (NULL = service default)';
@@ -0,0 +1,7 @@
+-- Revert 034_vendor_bank_last4.sql
+
+DROP INDEX IF EXISTS idx_vendors_iban_last4;
+DROP INDEX IF EXISTS idx_vendors_bank_account_last4;
+ALTER TABLE vendors
+    DROP COLUMN IF EXISTS iban_last4,
+    DROP COLUMN IF EXISTS bank_account_last4;
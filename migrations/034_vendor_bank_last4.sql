@@ -0,0 +1,26 @@
+-- The last four digits of bank account numbers and IBANs are stored apart
+-- from the full numbers, so vendors can be found by them without reading
+-- the account details. They are written with the vendor; bring existing
+-- vendors to the same form: the last four digits, or NULL with fewer.
+
+ALTER TABLE vendors
+    ADD COLUMN bank_account_last4 VARCHAR(4),
+    ADD COLUMN iban_last4 VARCHAR(4);
+
+WITH digits AS (
+    SELECT id,
+           regexp_replace(coalesce(bank_account_number, ''), '[^0-9]', '', 'g') AS account,
+           regexp_replace(coalesce(iban, ''), '[^0-9]', '', 'g') AS iban
+    FROM vendors
+    WHERE bank_account_number IS NOT NULL OR iban IS NOT NULL
+)
+UPDATE vendors
+SET bank_account_last4 = CASE WHEN length(digits.account) >= 4 THEN right(digits.account, 4) END,
+    iban_last4 = CASE WHEN length(digits.iban) >= 4 THEN right(digits.iban, 4) END
+FROM digits
+WHERE vendors.id = digits.id;
+
+CREATE INDEX idx_vendors_bank_account_last4 ON vendors(entity_id, bank_account_last4)
+    WHERE deleted_at IS NULL AND bank_account_last4 IS NOT NULL;
+CREATE INDEX idx_vendors_iban_last4 ON vendors(entity_id, iban_last4)
+    WHERE deleted_at IS NULL AND iban_last4 IS NOT NULL;
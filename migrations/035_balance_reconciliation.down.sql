@@ -0,0 +1,5 @@
+-- Revert 035_balance_reconciliation.sql
+
+ALTER TABLE vendor_balance_transactions
+    DROP CONSTRAINT IF EXISTS vendor_balance_transactions_kind_check,
+    DROP COLUMN IF EXISTS kind;
@@ -0,0 +1,8 @@
+-- Balance reconciliation: ledger entries written when current_balance is
+-- corrected to the sum of the ledger
+
+ALTER TABLE vendor_balance_transactions
+    ADD COLUMN kind VARCHAR(20) NOT NULL DEFAULT 'adjustment',
+    ADD CONSTRAINT vendor_balance_transactions_kind_check CHECK (kind IN ('adjustment', 'reconciliation'));
+
+COMMENT ON COLUMN vendor_balance_transactions.kind IS 'adjustment (amount moved the balance) or reconciliation (amount 0; balance_after is the corrected current_balance)';
@@ -0,0 +1,3 @@
+-- Revert 036_vendor_credit_limit_currency.sql
+
+ALTER TABLE vendors DROP COLUMN IF EXISTS credit_limit_currency;
@@ -0,0 +1,9 @@
+-- Currency of vendor credit limits, so that limits are not compared with
+-- balances in another currency
+
+ALTER TABLE vendors ADD COLUMN credit_limit_currency VARCHAR(3);
+
+-- Existing limits were entered in the vendor currency
+UPDATE vendors SET credit_limit_currency = currency WHERE credit_limit IS NOT NULL;
+
+COMMENT ON COLUMN vendors.credit_limit_currency IS 'ISO 4217 currency of credit_limit; NULL without a limit';
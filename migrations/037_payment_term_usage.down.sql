@@ -0,0 +1,4 @@
+-- Revert 037_payment_term_usage.sql
+
+DROP INDEX IF EXISTS idx_vendors_payment_terms;
+DROP INDEX IF EXISTS idx_payment_terms_code_upper;
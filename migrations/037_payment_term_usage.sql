@@ -0,0 +1,7 @@
+-- Payment terms are looked up by code regardless of case, so their codes are
+-- unique regardless of case too. Vendors are found by the term they use when
+-- a term is deactivated or its usage is reported.
+
+CREATE UNIQUE INDEX idx_payment_terms_code_upper ON payment_terms(upper(code));
+
+CREATE INDEX idx_vendors_payment_terms ON vendors(upper(payment_terms)) WHERE deleted_at IS NULL;
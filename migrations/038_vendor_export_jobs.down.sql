@@ -0,0 +1,3 @@
+-- Revert 038_vendor_export_jobs.sql
+
+DROP TABLE IF EXISTS vendor_export_jobs;
@@ -0,0 +1,31 @@
+-- Background CSV exports of the vendors of an entity
+
+-- A job is generated by the worker in parts of one batch of vendors each,
+-- written to the export file store. cursor_id and parts checkpoint the
+-- progress after each part, so a job whose worker stopped resumes where it
+-- left off once its lease expires.
+CREATE TABLE vendor_export_jobs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    entity_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    rows_total BIGINT NOT NULL DEFAULT 0,
+    rows_written BIGINT NOT NULL DEFAULT 0,
+    parts INTEGER NOT NULL DEFAULT 0,
+    -- ID of the last vendor written; vendors are exported in ID order
+    cursor_id UUID,
+    -- Failed runs; the job fails for good after a few
+    attempts INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    lease_until TIMESTAMP WITH TIME ZONE,
+    created_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT vendor_export_jobs_status_check CHECK (status IN ('pending', 'running', 'completed', 'failed'))
+);
+
+CREATE INDEX idx_vendor_export_jobs_queue ON vendor_export_jobs(created_at) WHERE status IN ('pending', 'running');
+CREATE INDEX idx_vendor_export_jobs_expires ON vendor_export_jobs(expires_at) WHERE expires_at IS NOT NULL;
+
+COMMENT ON TABLE vendor_export_jobs IS 'Background vendor CSV exports and their progress';
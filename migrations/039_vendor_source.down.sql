@@ -0,0 +1,7 @@
+-- Revert 039_vendor_source.sql
+
+DROP INDEX IF EXISTS idx_vendors_source;
+
+ALTER TABLE vendors DROP CONSTRAINT IF EXISTS vendors_source_check;
+ALTER TABLE vendors DROP COLUMN IF EXISTS source_reference;
+ALTER TABLE vendors DROP COLUMN IF EXISTS source;
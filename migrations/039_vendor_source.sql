@@ -0,0 +1,16 @@
+-- Where vendors come from: the creation path that added them (the web app,
+-- the gRPC API, a bulk import, the onboarding portal, an ERP sync or the seed
+-- command) and, within it, e.g. the import job or sync system. Both are set
+-- when the vendor is created and never change.
+
+ALTER TABLE vendors ADD COLUMN source VARCHAR(20) NOT NULL DEFAULT 'unknown';
+ALTER TABLE vendors ADD COLUMN source_reference VARCHAR(255);
+
+-- Vendors created before sources were recorded keep 'unknown'
+ALTER TABLE vendors ADD CONSTRAINT vendors_source_check
+    CHECK (source IN ('ui', 'api', 'import', 'portal', 'erp_sync', 'seed', 'unknown'));
+
+CREATE INDEX idx_vendors_source ON vendors(entity_id, source) WHERE deleted_at IS NULL;
+
+COMMENT ON COLUMN vendors.source IS 'Creation path of the vendor: ui, api, import, portal, erp_sync, seed or unknown';
+COMMENT ON COLUMN vendors.source_reference IS 'What created the vendor within its source, e.g. an import job ID or sync system name';
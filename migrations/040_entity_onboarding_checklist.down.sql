@@ -0,0 +1,3 @@
+-- Revert 040_entity_onboarding_checklist.sql
+
+ALTER TABLE entity_settings DROP COLUMN IF EXISTS onboarding_checklist;
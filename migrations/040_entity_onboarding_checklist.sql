@@ -0,0 +1,5 @@
+-- Per-entity checklist vendor onboarding is measured against
+
+ALTER TABLE entity_settings ADD COLUMN onboarding_checklist JSONB;
+
+COMMENT ON COLUMN entity_settings.onboarding_checklist IS 'Onboarding checklist items and whether each blocks activation (NULL = service default)';
@@ -0,0 +1,3 @@
+-- Revert 041_export_exclude_suspected_test.sql
+
+ALTER TABLE vendor_export_jobs DROP COLUMN IF EXISTS exclude_suspected_test;
@@ -0,0 +1,6 @@
+-- Vendor exports may leave out the vendors flagged as probable test vendors;
+-- the choice is made when the export is queued and kept for every run.
+
+ALTER TABLE vendor_export_jobs ADD COLUMN exclude_suspected_test BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN vendor_export_jobs.exclude_suspected_test IS 'Whether vendors flagged as probable test vendors are left out of the export';
@@ -0,0 +1,19 @@
+-- Revert 042_contact_communications.sql
+
+-- Keep the oldest statement recipient of each vendor so the one-per-vendor
+-- index can be restored
+UPDATE vendor_contacts c
+SET receives_statements = FALSE
+WHERE c.receives_statements AND EXISTS (
+    SELECT 1 FROM vendor_contacts o
+    WHERE o.vendor_id = c.vendor_id AND o.receives_statements
+      AND (o.created_at, o.id) < (c.created_at, c.id)
+);
+
+CREATE UNIQUE INDEX idx_vendor_contacts_statement_contact
+    ON vendor_contacts(vendor_id) WHERE receives_statements;
+
+COMMENT ON COLUMN vendor_contacts.receives_statements IS 'Contact statements are sent to; at most one per vendor';
+
+ALTER TABLE vendor_contacts DROP COLUMN IF EXISTS receives_remittance;
+ALTER TABLE vendor_contacts DROP COLUMN IF EXISTS receives_purchase_orders;
@@ -0,0 +1,12 @@
+-- Which communication types each contact receives: purchase orders and
+-- remittance advices join statements, and a vendor may have several
+-- recipients of each, bounded by the service
+
+ALTER TABLE vendor_contacts ADD COLUMN receives_purchase_orders BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE vendor_contacts ADD COLUMN receives_remittance BOOLEAN NOT NULL DEFAULT FALSE;
+
+DROP INDEX IF EXISTS idx_vendor_contacts_statement_contact;
+
+COMMENT ON COLUMN vendor_contacts.receives_purchase_orders IS 'Contact purchase orders are sent to';
+COMMENT ON COLUMN vendor_contacts.receives_remittance IS 'Contact remittance advices are sent to';
+COMMENT ON COLUMN vendor_contacts.receives_statements IS 'Contact statements are sent to';
@@ -0,0 +1,6 @@
+-- Revert 043_vendor_last_modified_at.sql
+
+DROP TRIGGER IF EXISTS trigger_vendors_last_modified_at ON vendors;
+DROP FUNCTION IF EXISTS update_vendor_last_modified_at();
+
+ALTER TABLE vendors DROP COLUMN IF EXISTS last_modified_at;
@@ -0,0 +1,30 @@
+-- Time of the last change to the vendor's own fields. Unlike updated_at, it
+-- is not advanced by updates that only touch the vendor to advance its
+-- change_seq, such as a risk rescore or a recorded TIN match, so dormancy
+-- detection can tell user-visible updates from them.
+
+ALTER TABLE vendors ADD COLUMN last_modified_at TIMESTAMP WITH TIME ZONE;
+
+-- Touches were not told apart before, so updated_at is the best estimate
+UPDATE vendors SET last_modified_at = updated_at;
+
+ALTER TABLE vendors ALTER COLUMN last_modified_at SET DEFAULT NOW();
+ALTER TABLE vendors ALTER COLUMN last_modified_at SET NOT NULL;
+
+CREATE OR REPLACE FUNCTION update_vendor_last_modified_at()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF to_jsonb(NEW) - ARRAY['updated_at', 'change_seq', 'last_modified_at']
+        IS DISTINCT FROM to_jsonb(OLD) - ARRAY['updated_at', 'change_seq', 'last_modified_at'] THEN
+        NEW.last_modified_at = NOW();
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_vendors_last_modified_at
+BEFORE UPDATE ON vendors
+FOR EACH ROW
+EXECUTE FUNCTION update_vendor_last_modified_at();
+
+COMMENT ON COLUMN vendors.last_modified_at IS 'Last change to the vendor''s fields, excluding updates that only advance change_seq';
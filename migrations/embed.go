@@ -0,0 +1,12 @@
+// Package migrations embeds the SQL migrations of the vendors database.
+//
+// NNN_name.sql upgrades the schema to version NNN and NNN_name.down.sql
+// reverts it to the previous version.
+package migrations
+
+import "embed"
+
+// FS holds every migration file
+//
+//go:embed *.sql
+var FS embed.FS